@@ -1,6 +1,6 @@
 //	@title			Radif API
 //	@version		1.0
-//	@description	Backend for Radif — social payment platform for Iran.
+//	@description	Backend for Radif — social payment platform for Iran. Error responses carry a stable "code" and an "error" message in the language picked from Accept-Language (fa or en; English when the header is absent), plus a "requestId" to quote when reporting a problem. Every response carries the same ID in the X-Request-ID header; a client may send its own X-Request-ID, of at most 64 letters, digits and hyphens, to have it used instead. Requests that fail validation answer 400 with code "validation_failed" and an "errors" array of {field, code, message}.
 //
 //	@host		localhost:8080
 //	@BasePath	/api/v1
@@ -9,140 +9,213 @@
 //	@in							header
 //	@name						Authorization
 //	@description				JWT Bearer token. Format: **Bearer {token}**
+//
+//	@securityDefinitions.apikey	APIKeyAuth
+//	@in							header
+//	@name						X-API-Key
+//	@description				API key of a partner or internal service, issued through /admin/api-keys.
 
 package main
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
-	"github.com/go-chi/chi/v5"
-	chiMiddleware "github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/cors"
-	httpSwagger "github.com/swaggo/http-swagger/v2"
+	"github.com/jackc/pgx/v5"
 
-	"github.com/radif/service/internal/auth"
-	"github.com/radif/service/internal/config"
+	"github.com/radif/service/internal/bootstrap"
+	"github.com/radif/service/internal/chaos"
 	"github.com/radif/service/internal/db"
-	appMiddleware "github.com/radif/service/internal/middleware"
-	"github.com/radif/service/internal/storage"
-	"github.com/radif/service/internal/user"
+	"github.com/radif/service/internal/events"
+	"github.com/radif/service/internal/health"
+	"github.com/radif/service/internal/metrics"
+	"github.com/radif/service/internal/moderation"
+	"github.com/radif/service/internal/server"
+	"github.com/radif/service/internal/tlsconfig"
+	"github.com/radif/service/internal/webhook"
 
 	_ "github.com/radif/service/docs/swagger"
 )
 
 func main() {
-	cfg := config.Load()
+	cfg, secretStore := bootstrap.LoadConfig()
+	defer bootstrap.Setup(cfg, "radif-api")()
 
-	pool, err := db.Connect(cfg.DatabaseURL)
-	if err != nil {
-		log.Fatalf("database connection failed: %v", err)
+	injector := bootstrap.FaultInjector(cfg)
+	var dbTracer pgx.QueryTracer
+	if injector != nil {
+		dbTracer = chaos.NewTracer(injector)
 	}
+
+	pool, replica := bootstrap.ConnectDatabase(cfg, dbTracer)
 	defer pool.Close()
+	if replica != nil {
+		defer replica.Close()
+	}
 
 	if err := db.Migrate(cfg.DatabaseURL); err != nil {
-		log.Fatalf("database migration failed: %v", err)
-	}
-
-	store, err := storage.NewMinioStorage(
-		cfg.StorageEndpoint,
-		cfg.StorageAccessKey,
-		cfg.StorageSecretKey,
-		cfg.StorageBucket,
-		cfg.StoragePublicBase,
-		cfg.StorageUseSSL,
-	)
-	if err != nil {
-		log.Fatalf("object storage init failed: %v", err)
-	}
-
-	// Wire dependencies: repository → service → handler
-	userRepo := user.NewRepository(pool)
-	userSvc := user.NewService(userRepo)
-	userHandler := user.NewHandler(userSvc, store)
-
-	authRepo := auth.NewRepository(pool)
-	authSvc := auth.NewService(authRepo, userSvc, cfg)
-	authHandler := auth.NewHandler(authSvc)
-
-	// Router
-	r := chi.NewRouter()
-	r.Use(chiMiddleware.RequestID)
-	r.Use(chiMiddleware.RealIP)
-	r.Use(appMiddleware.Logger)
-	r.Use(chiMiddleware.Recoverer)
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-Request-ID"},
-		MaxAge:         300,
-	}))
-
-	// Health check
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"ok"}`))
-	})
+		bootstrap.Fatal("database migration failed", "err", err)
+	}
 
-	// Swagger UI — available at http://localhost:8080/swagger/
-	r.Get("/swagger/*", httpSwagger.Handler(
-		httpSwagger.URL("/swagger/doc.json"),
-	))
-
-	// API v1
-	r.Route("/api/v1", func(r chi.Router) {
-		// Public auth endpoints
-		r.Route("/auth", func(r chi.Router) {
-			r.Post("/otp/send", authHandler.SendOTP)
-			r.Post("/otp/verify", authHandler.VerifyOTP)
-			r.Post("/otp/resend", authHandler.ResendOTP)
-			r.Post("/register", authHandler.Register)
-		})
+	reader := db.NewReader(pool, replica)
+
+	appMetrics := metrics.New()
+	appMetrics.RegisterPool(pool)
 
-		// Protected user endpoints
-		r.Route("/users", func(r chi.Router) {
-			r.Use(appMiddleware.RequireAuth(cfg.JWTSecret))
-			r.Get("/me", userHandler.GetMe)
-			r.Patch("/me", userHandler.UpdateProfile)
-			r.Post("/me/avatar", userHandler.UploadAvatar)
-			r.Get("/username-check", userHandler.CheckUsername)
+	stores := bootstrap.OpenStores(cfg, injector, appMetrics)
+	readiness := health.NewChecker(cfg.ReadinessTimeout)
+	readiness.Add("postgres", true, pool.Ping)
+	if replica != nil {
+		readiness.Add("postgres-replica", false, replica.Ping)
+	}
+	stores.AddReadiness(readiness)
+
+	// Replaced and taken-down images are purged from the CDN in the background.
+	cdnInvalidator := bootstrap.NewCDNInvalidator(cfg)
+
+	// Shared ephemeral state lives in Redis; without it each feature using it
+	// degrades as documented on cache.Cache.
+	redisCache := bootstrap.OpenCache(cfg)
+	defer redisCache.Close()
+	if redisCache != nil {
+		readiness.Add("redis", false, func(ctx context.Context) error {
+			return redisCache.Client().Ping(ctx).Err()
 		})
+	}
+
+	// New avatars are checked in the background; flagged ones go to the
+	// private quarantine bucket for review.
+	var moderationSvc *moderation.Service
+	if cfg.ModerationEnabled {
+		moderationRepo := moderation.NewRepository(pool)
+		checkers := []moderation.Checker{moderation.NewBlocklistChecker(moderationRepo, cfg.ModerationBlocklistDistance)}
+		if cfg.ModerationClassifierURL != "" {
+			checkers = append(checkers, moderation.NewHTTPClassifier(cfg.ModerationClassifierURL, cfg.ModerationClassifierToken, cfg.ModerationClassifierScore))
+		}
+		moderationSvc = moderation.NewService(moderationRepo, stores.Public, stores.Quarantine, bootstrap.CacheInvalidator(cdnInvalidator), checkers...)
+	}
+
+	eventRepo := events.NewRepository(pool)
+	// The outbox is always on: besides the optional event bus, the relay
+	// feeds merchant webhooks from it.
+	outbox := events.NewOutbox(eventRepo)
+	var eventBus events.Publisher
+	if nats := bootstrap.OpenEventBus(cfg); nats != nil {
+		defer nats.Close()
+		eventBus = nats
+	}
+
+	api := server.New(server.Deps{
+		Config:     cfg,
+		Pool:       pool,
+		Reader:     reader,
+		Stores:     stores,
+		Metrics:    appMetrics,
+		Readiness:  readiness,
+		Cache:      redisCache,
+		Outbox:     outbox,
+		Moderation: moderationSvc,
+		CDN:        cdnInvalidator,
+		Injector:   injector,
 	})
 
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
-		Handler:      r,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Handler:      api.Router,
+		ReadTimeout:  cfg.HTTPReadTimeout,
+		WriteTimeout: cfg.HTTPWriteTimeout,
+		IdleTimeout:  cfg.HTTPIdleTimeout,
+	}
+	var redirectSrv *http.Server
+	if cfg.TLSCertFile != "" {
+		tlsCfg, err := tlsconfig.New(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			bootstrap.Fatal("TLS setup failed", "err", err)
+		}
+		srv.TLSConfig = tlsCfg
+		if cfg.TLSRedirectPort != "" {
+			redirectSrv = &http.Server{
+				Addr:         ":" + cfg.TLSRedirectPort,
+				Handler:      tlsconfig.RedirectHandler(cfg.Port),
+				ReadTimeout:  cfg.HTTPReadTimeout,
+				WriteTimeout: cfg.HTTPWriteTimeout,
+				IdleTimeout:  cfg.HTTPIdleTimeout,
+			}
+		}
 	}
 
 	// Start server in goroutine; wait for shutdown signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 
+	// Background workers stop when workerCtx is cancelled during shutdown.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	if secretStore != nil {
+		go secretStore.Run(workerCtx, cfg.SecretsRefreshInterval)
+	}
+	go reader.Run(workerCtx, cfg.DatabaseReadCheckInterval)
+	if cfg.DatabasePoolLogInterval > 0 {
+		go db.LogPoolStats(workerCtx, "primary", pool, cfg.DatabasePoolLogInterval)
+		if replica != nil {
+			go db.LogPoolStats(workerCtx, "replica", replica, cfg.DatabasePoolLogInterval)
+		}
+	}
+	api.Start(workerCtx)
+	// Purges are queued in memory by this process's handlers, so they are
+	// sent from here even when cmd/worker runs the other jobs.
+	if cdnInvalidator != nil {
+		go cdnInvalidator.Run(workerCtx)
+	}
+	if cfg.RunWorkers {
+		if moderationSvc != nil {
+			go moderation.NewWorker(moderationSvc).Run(workerCtx)
+		}
+		webhookSvc := webhook.NewService(webhook.NewRepository(pool), webhook.NewSender(!cfg.IsProduction()), cfg.IsProduction())
+		go events.NewRelay(eventRepo, eventBus, webhookSvc, cfg.EventBusSubjectPrefix).Run(workerCtx)
+	}
+
 	go func() {
-		log.Printf("server listening on :%s (env=%s)", cfg.Port, cfg.AppEnv)
-		log.Printf("swagger UI at http://localhost:%s/swagger/", cfg.Port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
+		scheme := "http"
+		listen := srv.ListenAndServe
+		if srv.TLSConfig != nil {
+			// The certificate comes from TLSConfig.GetCertificate.
+			scheme = "https"
+			listen = func() error { return srv.ListenAndServeTLS("", "") }
+		}
+		slog.Info("server listening", "port", cfg.Port, "tls", srv.TLSConfig != nil, "swagger", scheme+"://localhost:"+cfg.Port+"/swagger/")
+		if err := listen(); err != nil && err != http.ErrServerClosed {
+			bootstrap.Fatal("server error", "err", err)
 		}
 	}()
+	if redirectSrv != nil {
+		go func() {
+			slog.Info("redirecting HTTP to HTTPS", "port", cfg.TLSRedirectPort)
+			if err := redirectSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				bootstrap.Fatal("redirect server error", "err", err)
+			}
+		}()
+	}
 
 	<-quit
-	log.Println("shutting down gracefully...")
+	slog.Info("shutting down gracefully")
+	readiness.Drain()
+	stopWorkers()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 	defer cancel()
 
+	if redirectSrv != nil {
+		redirectSrv.Shutdown(ctx) //nolint:errcheck
+	}
 	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("forced shutdown: %v", err)
+		bootstrap.Fatal("forced shutdown", "err", err)
 	}
 
-	log.Println("server stopped")
+	slog.Info("server stopped")
 }
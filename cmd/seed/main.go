@@ -0,0 +1,382 @@
+// Command seed fills a development database with realistic fake data:
+// personal and business users with Persian names and valid 09xx phones, an
+// admin, pending OTPs to sign in with, groups with shared expenses,
+// contacts, conversations and notifications.
+//
+//	go run ./cmd/seed -users 50
+//
+// Seeded phones are 0990000XXXX; -reset deletes them (and everything that
+// hangs off them) before seeding again. It refuses to run in production.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/radif/service/internal/config"
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/notification"
+)
+
+// phonePrefix marks seeded users; 0990 is an MCI range, so every seeded
+// phone is a valid mobile number.
+const phonePrefix = "0990000"
+
+// otpCode is the code every seeded phone can sign in with.
+const otpCode = "11111"
+
+type name struct{ fa, en string }
+
+var (
+	firstNames = []name{
+		{"علی", "ali"}, {"محمد", "mohammad"}, {"حسین", "hossein"}, {"رضا", "reza"},
+		{"مهدی", "mahdi"}, {"امیر", "amir"}, {"سینا", "sina"}, {"آرش", "arash"},
+		{"فاطمه", "fatemeh"}, {"زهرا", "zahra"}, {"مریم", "maryam"}, {"سارا", "sara"},
+		{"نرگس", "narges"}, {"نیلوفر", "niloufar"}, {"الهام", "elham"}, {"پریسا", "parisa"},
+	}
+	lastNames = []name{
+		{"محمدی", "mohammadi"}, {"حسینی", "hosseini"}, {"احمدی", "ahmadi"}, {"رضایی", "rezaei"},
+		{"کریمی", "karimi"}, {"موسوی", "mousavi"}, {"جعفری", "jafari"}, {"صادقی", "sadeghi"},
+		{"رحیمی", "rahimi"}, {"کاظمی", "kazemi"}, {"نوری", "nouri"}, {"تهرانی", "tehrani"},
+	}
+	businesses = []struct {
+		name     name
+		category string
+		bio      string
+	}{
+		{name{"سوپرمارکت آفتاب", "aftab_market"}, "5411", "خرید روزانه با بهترین قیمت"},
+		{name{"رستوران شاندیز", "shandiz"}, "5812", "کباب و غذای ایرانی"},
+		{name{"کافه نارنج", "narenj_cafe"}, "5814", "قهوه تازه، کیک خانگی"},
+		{name{"داروخانه دکتر امینی", "amini_pharmacy"}, "5912", "شبانه‌روزی"},
+		{name{"پوشاک پارسه", "parseh_wear"}, "5691", "لباس مردانه و زنانه"},
+		{name{"دیجی‌لند", "digiland"}, "5732", "موبایل و لوازم جانبی"},
+		{name{"آرایشگاه گلاره", "gelareh_salon"}, "7230", "کوتاهی، رنگ و میکاپ"},
+		{name{"آموزشگاه زبان پویا", "pouya_lang"}, "8211", "کلاس‌های زبان انگلیسی و آلمانی"},
+	}
+	bios = []string{
+		"عاشق کوه و طبیعت", "برنامه‌نویس", "دانشجوی معماری", "عکاس آماتور",
+		"کتاب‌خوان", "", "", "",
+	}
+	groupNames = []string{"سفر شمال", "همخانه‌ها", "ناهار اداره", "فوتبال پنج‌شنبه", "تولد سارا", "دورهمی خانوادگی"}
+	expenses   = []string{"بنزین", "شام", "خرید سوپرمارکت", "اجاره ویلا", "قبض برق", "کیک و شیرینی", "بلیت سینما", "تاکسی"}
+	messages   = []string{"سلام، خوبی؟", "پول شام رو فرستادم", "مرسی!", "فردا می‌بینمت", "رسید رو بفرست لطفا", "باشه 👍"}
+)
+
+func main() {
+	users := flag.Int("users", 50, "number of personal users to create, besides businesses and the admin")
+	reset := flag.Bool("reset", false, "delete previously seeded data first")
+	seed := flag.Uint64("seed", 1, "random seed; the same seed produces the same data")
+	flag.Parse()
+
+	cfg := config.Load()
+	if cfg.IsProduction() {
+		fatal("refusing to seed a production database")
+	}
+	if *users < 1 || *users > 9000 {
+		fatal("-users must be between 1 and 9000")
+	}
+
+	if err := db.Migrate(cfg.DatabaseURL); err != nil {
+		fatal("migration failed", "err", err)
+	}
+	pool, err := db.Connect(cfg.DatabaseURL, db.PoolOptions{})
+	if err != nil {
+		fatal("database connection failed", "err", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		fatal("begin transaction failed", "err", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	s := &seeder{tx: tx, rnd: rand.New(rand.NewPCG(*seed, *seed))}
+	if *reset {
+		if err := s.reset(ctx); err != nil {
+			fatal("reset failed", "err", err)
+		}
+	}
+	if err := s.run(ctx, *users); err != nil {
+		fatal("seed failed", "err", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		fatal("commit failed", "err", err)
+	}
+
+	fmt.Printf("Seeded %d users. Sign in as any of them with OTP code %s (valid 24h, once per phone):\n", len(s.personal)+len(s.business)+1, otpCode)
+	fmt.Printf("  admin     %s\n", s.admin.phone)
+	for _, u := range s.personal[:min(3, len(s.personal))] {
+		fmt.Printf("  personal  %s  @%s\n", u.phone, u.username)
+	}
+	for _, u := range s.business[:min(3, len(s.business))] {
+		fmt.Printf("  business  %s  @%s\n", u.phone, u.username)
+	}
+}
+
+type seededUser struct {
+	id, phone, username string
+}
+
+type seeder struct {
+	tx  pgx.Tx
+	rnd *rand.Rand
+
+	admin    seededUser
+	personal []seededUser
+	business []seededUser
+	n        int // phones handed out so far
+}
+
+// reset deletes seeded users; nearly everything else cascades from them.
+// Groups only lose their creator, so seeded ones are deleted first.
+func (s *seeder) reset(ctx context.Context) error {
+	_, err := s.tx.Exec(ctx,
+		`DELETE FROM groups WHERE created_by IN (SELECT id FROM users WHERE phone LIKE $1 || '%')`, phonePrefix)
+	if err != nil {
+		return fmt.Errorf("delete groups: %w", err)
+	}
+	tag, err := s.tx.Exec(ctx, `DELETE FROM users WHERE phone LIKE $1 || '%'`, phonePrefix)
+	if err != nil {
+		return fmt.Errorf("delete users: %w", err)
+	}
+	_, err = s.tx.Exec(ctx, `DELETE FROM otps WHERE phone LIKE $1 || '%'`, phonePrefix)
+	if err != nil {
+		return fmt.Errorf("delete otps: %w", err)
+	}
+	slog.Info("seed: deleted previous data", "users", tag.RowsAffected())
+	return nil
+}
+
+func (s *seeder) run(ctx context.Context, users int) error {
+	var exists bool
+	if err := s.tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE phone LIKE $1 || '%')`, phonePrefix).Scan(&exists); err != nil {
+		return fmt.Errorf("check seeded users: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("database is already seeded; run with -reset to start over")
+	}
+
+	var err error
+	if s.admin, err = s.user(ctx, "personal", "پشتیبانی ردیف", "radif_admin", "", "", "admin"); err != nil {
+		return err
+	}
+	for i := 0; i < users; i++ {
+		first, last := pick(s.rnd, firstNames), pick(s.rnd, lastNames)
+		username := fmt.Sprintf("%s_%s%d", first.en, last.en, i+1)
+		u, err := s.user(ctx, "personal", first.fa+" "+last.fa, username, pick(s.rnd, bios), "", "user")
+		if err != nil {
+			return err
+		}
+		s.personal = append(s.personal, u)
+	}
+	for _, b := range businesses {
+		u, err := s.user(ctx, "business", b.name.fa, b.name.en, b.bio, b.category, "user")
+		if err != nil {
+			return err
+		}
+		s.business = append(s.business, u)
+	}
+
+	for _, step := range []func(context.Context) error{s.otps, s.groups, s.contacts, s.conversations, s.notifications} {
+		if err := step(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// user inserts a user with the next seeded phone.
+func (s *seeder) user(ctx context.Context, accountType, fullName, username, bio, category, role string) (seededUser, error) {
+	u := seededUser{phone: fmt.Sprintf("%s%04d", phonePrefix, s.n), username: strings.ToLower(username)}
+	s.n++
+	err := s.tx.QueryRow(ctx,
+		`INSERT INTO users (phone, account_type, full_name, username, bio, business_category, role, created_at)
+		 VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8)
+		 RETURNING id`,
+		u.phone, accountType, fullName, u.username, bio, category, role, s.past(90*24*time.Hour),
+	).Scan(&u.id)
+	if err != nil {
+		return u, fmt.Errorf("insert user %s: %w", u.phone, err)
+	}
+	return u, nil
+}
+
+// otps gives every seeded phone a pending code, so developers can sign in
+// without an SMS provider.
+func (s *seeder) otps(ctx context.Context) error {
+	_, err := s.tx.Exec(ctx,
+		`INSERT INTO otps (phone, code, expires_at)
+		 SELECT phone, $2, NOW() + INTERVAL '24 hours' FROM users WHERE phone LIKE $1 || '%'`,
+		phonePrefix, otpCode,
+	)
+	if err != nil {
+		return fmt.Errorf("insert otps: %w", err)
+	}
+	return nil
+}
+
+// groups creates groups of 3-6 personal users, each with expenses split
+// equally between its members.
+func (s *seeder) groups(ctx context.Context) error {
+	for _, name := range groupNames {
+		members := s.sample(s.personal, 3+s.rnd.IntN(4))
+		var groupID string
+		err := s.tx.QueryRow(ctx,
+			`INSERT INTO groups (name, created_by, created_at) VALUES ($1, $2, $3) RETURNING id`,
+			name, members[0].id, s.past(60*24*time.Hour),
+		).Scan(&groupID)
+		if err != nil {
+			return fmt.Errorf("insert group: %w", err)
+		}
+		for i, m := range members {
+			role := "member"
+			if i == 0 {
+				role = "owner"
+			}
+			if _, err := s.tx.Exec(ctx,
+				`INSERT INTO group_members (group_id, user_id, role) VALUES ($1, $2, $3)`,
+				groupID, m.id, role); err != nil {
+				return fmt.Errorf("insert group member: %w", err)
+			}
+		}
+
+		for j := 2 + s.rnd.IntN(5); j > 0; j-- {
+			// Whole thousands of tomans, in rials.
+			amount := int64(50+s.rnd.IntN(4950)) * 10_000
+			payer := pick(s.rnd, members)
+			var expenseID string
+			err := s.tx.QueryRow(ctx,
+				`INSERT INTO group_expenses (group_id, paid_by, amount, description, created_by, created_at)
+				 VALUES ($1, $2, $3, $4, $2, $5) RETURNING id`,
+				groupID, payer.id, amount, pick(s.rnd, expenses), s.past(30*24*time.Hour),
+			).Scan(&expenseID)
+			if err != nil {
+				return fmt.Errorf("insert expense: %w", err)
+			}
+			share, rest := amount/int64(len(members)), amount%int64(len(members))
+			for i, m := range members {
+				a := share
+				if i == 0 {
+					a += rest
+				}
+				if _, err := s.tx.Exec(ctx,
+					`INSERT INTO group_expense_shares (expense_id, user_id, amount) VALUES ($1, $2, $3)`,
+					expenseID, m.id, a); err != nil {
+					return fmt.Errorf("insert expense share: %w", err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// contacts gives each personal user a handful of matched contacts.
+func (s *seeder) contacts(ctx context.Context) error {
+	all := append(append([]seededUser{}, s.personal...), s.business...)
+	for _, u := range s.personal {
+		for _, c := range s.sample(all, min(len(all), 5+s.rnd.IntN(10))) {
+			if c.id == u.id {
+				continue
+			}
+			if _, err := s.tx.Exec(ctx,
+				`INSERT INTO contacts (owner_id, contact_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+				u.id, c.id); err != nil {
+				return fmt.Errorf("insert contact: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// conversations starts a few 1:1 threads with short message histories.
+func (s *seeder) conversations(ctx context.Context) error {
+	for i := 0; i < len(s.personal)/2; i++ {
+		pair := s.sample(s.personal, 2)
+		a, b := pair[0], pair[1]
+		if a.id > b.id {
+			a, b = b, a
+		}
+		var id string
+		err := s.tx.QueryRow(ctx,
+			`INSERT INTO conversations (user_a, user_b) VALUES ($1, $2)
+			 ON CONFLICT (user_a, user_b) DO NOTHING RETURNING id`,
+			a.id, b.id,
+		).Scan(&id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("insert conversation: %w", err)
+		}
+
+		at := s.past(14 * 24 * time.Hour)
+		for j := 1 + s.rnd.IntN(6); j > 0; j-- {
+			at = at.Add(time.Duration(1+s.rnd.IntN(180)) * time.Minute)
+			if _, err := s.tx.Exec(ctx,
+				`INSERT INTO conversation_messages (conversation_id, sender_id, body, created_at) VALUES ($1, $2, $3, $4)`,
+				id, pick(s.rnd, pair).id, pick(s.rnd, messages), at); err != nil {
+				return fmt.Errorf("insert message: %w", err)
+			}
+		}
+		if _, err := s.tx.Exec(ctx,
+			`UPDATE conversations SET last_message_at = $2 WHERE id = $1`, id, at); err != nil {
+			return fmt.Errorf("update conversation: %w", err)
+		}
+	}
+	return nil
+}
+
+// notifications leaves two sign-in alerts, one read and one unread, in each
+// personal user's inbox.
+func (s *seeder) notifications(ctx context.Context) error {
+	for _, u := range s.personal {
+		_, err := s.tx.Exec(ctx,
+			`INSERT INTO notifications (user_id, type, title, body, read_at, created_at) VALUES
+			 ($1, $2, $3, $4, NOW(), $5),
+			 ($1, $2, $3, $4, NULL, $6)`,
+			u.id, notification.TypeNewLogin, "ورود جدید به حساب",
+			"یک ورود جدید به حساب ردیف شما ثبت شد. اگر این شما نبودید، با پشتیبانی تماس بگیرید.",
+			s.past(60*24*time.Hour), s.past(24*time.Hour),
+		)
+		if err != nil {
+			return fmt.Errorf("insert notifications: %w", err)
+		}
+	}
+	return nil
+}
+
+// past returns a random time within window before now.
+func (s *seeder) past(window time.Duration) time.Time {
+	return time.Now().Add(-time.Duration(s.rnd.Int64N(int64(window))))
+}
+
+// sample returns n distinct users from users.
+func (s *seeder) sample(users []seededUser, n int) []seededUser {
+	idx := s.rnd.Perm(len(users))[:min(n, len(users))]
+	out := make([]seededUser, len(idx))
+	for i, j := range idx {
+		out[i] = users[j]
+	}
+	return out
+}
+
+func pick[T any](rnd *rand.Rand, items []T) T {
+	return items[rnd.IntN(len(items))]
+}
+
+// fatal logs msg at error level and exits, like log.Fatal.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,240 @@
+// Command worker runs Radif's queue consumers and background jobs apart
+// from the API: webhook delivery, OTP retries, the event relay, avatar
+// moderation, search reindexing, storage garbage collection, CDN purges and
+// the cron jobs.
+// It shares the API's configuration and database, and serves only health
+// and metrics endpoints, on WORKER_PORT.
+//
+// Set RUN_WORKERS=false on the API once this runs, so the jobs are not run
+// by both. Overlap during a rollout is harmless: queue consumers claim their
+// rows in the database and search reindexing is idempotent, which is what
+// already lets several API replicas run them.
+package main
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/radif/service/internal/audit"
+	"github.com/radif/service/internal/auth"
+	"github.com/radif/service/internal/bootstrap"
+	"github.com/radif/service/internal/chaos"
+	"github.com/radif/service/internal/contact"
+	"github.com/radif/service/internal/cron"
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/device"
+	"github.com/radif/service/internal/events"
+	"github.com/radif/service/internal/health"
+	"github.com/radif/service/internal/idempotency"
+	"github.com/radif/service/internal/metrics"
+	"github.com/radif/service/internal/moderation"
+	"github.com/radif/service/internal/notification"
+	"github.com/radif/service/internal/realtime"
+	"github.com/radif/service/internal/referral"
+	"github.com/radif/service/internal/search"
+	"github.com/radif/service/internal/storagegc"
+	"github.com/radif/service/internal/user"
+	"github.com/radif/service/internal/webhook"
+)
+
+func main() {
+	cfg, secretStore := bootstrap.LoadConfig()
+	defer bootstrap.Setup(cfg, "radif-worker")()
+
+	injector := bootstrap.FaultInjector(cfg)
+	var dbTracer pgx.QueryTracer
+	if injector != nil {
+		dbTracer = chaos.NewTracer(injector)
+	}
+
+	// Migrations are left to the API, which is deployed first.
+	pool, replica := bootstrap.ConnectDatabase(cfg, dbTracer)
+	defer pool.Close()
+	if replica != nil {
+		defer replica.Close()
+	}
+	reader := db.NewReader(pool, replica)
+	txm := db.NewTxManager(pool)
+
+	appMetrics := metrics.New()
+	appMetrics.RegisterPool(pool)
+
+	stores := bootstrap.OpenStores(cfg, injector, appMetrics)
+	readiness := health.NewChecker(cfg.ReadinessTimeout)
+	readiness.Add("postgres", true, pool.Ping)
+	if replica != nil {
+		readiness.Add("postgres-replica", false, replica.Ping)
+	}
+	stores.AddReadiness(readiness)
+
+	cdnInvalidator := bootstrap.NewCDNInvalidator(cfg)
+
+	redisCache := bootstrap.OpenCache(cfg)
+	defer redisCache.Close()
+	if redisCache != nil {
+		readiness.Add("redis", false, func(ctx context.Context) error {
+			return redisCache.Client().Ping(ctx).Err()
+		})
+	}
+
+	var moderationSvc *moderation.Service
+	if cfg.ModerationEnabled {
+		moderationRepo := moderation.NewRepository(pool)
+		checkers := []moderation.Checker{moderation.NewBlocklistChecker(moderationRepo, cfg.ModerationBlocklistDistance)}
+		if cfg.ModerationClassifierURL != "" {
+			checkers = append(checkers, moderation.NewHTTPClassifier(cfg.ModerationClassifierURL, cfg.ModerationClassifierToken, cfg.ModerationClassifierScore))
+		}
+		moderationSvc = moderation.NewService(moderationRepo, stores.Public, stores.Quarantine, bootstrap.CacheInvalidator(cdnInvalidator), checkers...)
+	}
+
+	eventRepo := events.NewRepository(pool)
+	// The outbox is always on: besides the optional event bus, the relay
+	// feeds merchant webhooks from it.
+	outbox := events.NewOutbox(eventRepo)
+	var eventBus events.Publisher
+	if nats := bootstrap.OpenEventBus(cfg); nats != nil {
+		defer nats.Close()
+		eventBus = nats
+	}
+	auditLog := audit.NewLog(audit.NewRepository(pool))
+	userSvc := user.NewService(user.NewRepository(pool, reader), txm, redisCache, outbox, auditLog)
+
+	// The hub is only published to: events reach the API's connected
+	// clients through Redis. Without Redis they are dropped, and clients
+	// catch up from the inbox.
+	realtimeHub := realtime.NewHub(redisCache.Client())
+	pusher := device.NewPusher(device.NewRepository(pool), nil)
+	notificationSvc := notification.NewService(notification.NewRepository(pool), pusher, nil, realtimeHub)
+
+	referralSvc := referral.NewService(referral.NewRepository(pool))
+	authSvc := auth.NewService(auth.NewRepository(pool), txm, userSvc, notificationSvc, referralSvc, bootstrap.SMSDispatcher(injector), redisCache, appMetrics, outbox, auditLog, cfg)
+	webhookSvc := webhook.NewService(webhook.NewRepository(pool), webhook.NewSender(!cfg.IsProduction()), cfg.IsProduction())
+
+	idempotencyRepo := idempotency.NewRepository(pool)
+	// Periodic cleanup runs on one instance at a time; see package cron.
+	scheduler := cron.NewScheduler(pool,
+		cron.Job{Name: "otp-purge", Interval: time.Hour, Run: authSvc.PurgeExpiredOTPs},
+		cron.Job{Name: "contact-lookups-purge", Interval: 24 * time.Hour, Run: contact.NewService(contact.NewRepository(pool)).PurgeLookups},
+		cron.Job{Name: "idempotency-purge", Interval: time.Hour, Run: func(ctx context.Context) error {
+			_, err := idempotencyRepo.DeleteExpired(ctx)
+			return err
+		}},
+	)
+
+	var searchReindexer *search.Reindexer
+	switch cfg.SearchDriver {
+	case search.DriverPostgres:
+	case search.DriverMeilisearch:
+		meili := search.NewMeilisearchIndex(cfg.MeilisearchURL, cfg.MeilisearchKey)
+		if err := meili.EnsureSettings(context.Background()); err != nil {
+			slog.Error("meilisearch settings failed", "err", err)
+		}
+		searchReindexer = search.NewReindexer(search.NewRepository(reader), meili)
+	default:
+		bootstrap.Fatal("unknown SEARCH_DRIVER", "value", cfg.SearchDriver)
+	}
+
+	var storageGC *storagegc.Collector
+	if cfg.StorageGCEnabled {
+		storageGC = storagegc.NewCollector(storagegc.NewRepository(pool), stores.GCTargets, storagegc.Options{
+			MinAge:   cfg.StorageGCMinAge,
+			Interval: cfg.StorageGCInterval,
+			DryRun:   cfg.StorageGCDryRun,
+		})
+	}
+
+	r := chi.NewRouter()
+	r.Get("/healthz", readiness.Live)
+	r.Get("/readyz", readiness.Ready)
+	r.Handle("/metrics", appMetrics.Handler(cfg.MetricsToken))
+	srv := &http.Server{
+		Addr:         ":" + cfg.WorkerPort,
+		Handler:      r,
+		ReadTimeout:  cfg.HTTPReadTimeout,
+		WriteTimeout: cfg.HTTPWriteTimeout,
+		IdleTimeout:  cfg.HTTPIdleTimeout,
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+
+	// Each job stops when workerCtx is cancelled; shutdown waits for the
+	// ones in progress to return.
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var wg sync.WaitGroup
+	run := func(job func(context.Context)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job(workerCtx)
+		}()
+	}
+
+	if secretStore != nil {
+		run(func(ctx context.Context) { secretStore.Run(ctx, cfg.SecretsRefreshInterval) })
+	}
+	run(func(ctx context.Context) { reader.Run(ctx, cfg.DatabaseReadCheckInterval) })
+	if cfg.DatabasePoolLogInterval > 0 {
+		run(func(ctx context.Context) { db.LogPoolStats(ctx, "primary", pool, cfg.DatabasePoolLogInterval) })
+		if replica != nil {
+			run(func(ctx context.Context) { db.LogPoolStats(ctx, "replica", replica, cfg.DatabasePoolLogInterval) })
+		}
+	}
+	run(scheduler.Run)
+	run(webhook.NewWorker(webhookSvc).Run)
+	run(auth.NewWorker(authSvc).Run)
+	if searchReindexer != nil {
+		run(searchReindexer.Run)
+	}
+	if storageGC != nil {
+		run(storageGC.Run)
+	}
+	if moderationSvc != nil {
+		run(moderation.NewWorker(moderationSvc).Run)
+	}
+	if cdnInvalidator != nil {
+		run(cdnInvalidator.Run)
+	}
+	run(events.NewRelay(eventRepo, eventBus, webhookSvc, cfg.EventBusSubjectPrefix).Run)
+
+	go func() {
+		slog.Info("worker listening", "port", cfg.WorkerPort)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			bootstrap.Fatal("server error", "err", err)
+		}
+	}()
+
+	<-quit
+	slog.Info("shutting down gracefully")
+	readiness.Drain()
+	stopWorkers()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("jobs still running at shutdown timeout")
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		bootstrap.Fatal("forced shutdown", "err", err)
+	}
+
+	slog.Info("worker stopped")
+}
@@ -0,0 +1,174 @@
+package apikey
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+	"github.com/radif/service/internal/validate"
+)
+
+// Handler holds HTTP handlers for API key management.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new apikey Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type createKeyRequest struct {
+	Name   string   `json:"name"   example:"accounting-sync"  validate:"required,max=100"`
+	Scopes []string `json:"scopes" example:"maintenance:write" validate:"required,min=1"`
+	// TTLDays makes the key expire; omit for a key that lasts until revoked.
+	TTLDays int `json:"ttlDays" example:"365" validate:"omitempty,min=1,max=730"`
+}
+
+type rotateKeyRequest struct {
+	// OverlapSeconds is how long the previous key stays valid. Omit for the
+	// 24h default; 0 revokes it immediately. Maximum 7 days.
+	OverlapSeconds *int64 `json:"overlapSeconds" example:"86400"`
+}
+
+// List godoc
+//
+//	@Summary		List API keys
+//	@Description	Returns every server-to-server API key, revoked ones included, newest first. Keys themselves are never shown again after creation. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]APIKey}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/admin/api-keys [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.svc.List(r.Context())
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, keys)
+}
+
+// Create godoc
+//
+//	@Summary		Create API key
+//	@Description	Issue a key for a partner or internal service, sent in the X-API-Key header. Known scopes: maintenance:write (the /integrations/maintenance-windows routes). The key is shown only once. Admin only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		createKeyRequest	true	"Name, scopes and lifetime"
+//	@Success		201		{object}	response.Envelope{data=CreatedKey}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/api-keys [post]
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || adminID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req createKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if errs := validate.Struct(req); errs != nil {
+		response.ValidationFailed(w, errs)
+		return
+	}
+
+	k, err := h.svc.Create(r.Context(), adminID, req.Name, req.Scopes, time.Duration(req.TTLDays)*24*time.Hour)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Created(w, k)
+}
+
+// Rotate godoc
+//
+//	@Summary		Rotate API key
+//	@Description	Issue a new key in place of an existing one. The previous key remains valid for the overlap window (default 24h, max 7 days) so the caller can redeploy. The new key is shown only once. Admin only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"API key ID"
+//	@Param			request	body		rotateKeyRequest	false	"Overlap window"
+//	@Success		201		{object}	response.Envelope{data=CreatedKey}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/api-keys/{id}/rotate [post]
+func (h *Handler) Rotate(w http.ResponseWriter, r *http.Request) {
+	var req rotateKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "invalid request body")
+			return
+		}
+	}
+
+	var overlap time.Duration
+	immediate := false
+	if req.OverlapSeconds != nil {
+		overlap = time.Duration(*req.OverlapSeconds) * time.Second
+		immediate = *req.OverlapSeconds == 0
+	}
+
+	k, err := h.svc.Rotate(r.Context(), chi.URLParam(r, "id"), overlap, immediate)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Created(w, k)
+}
+
+// Revoke godoc
+//
+//	@Summary		Revoke API key
+//	@Description	Disable a key immediately, along with a previous key still in its rotation overlap. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"API key ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/admin/api-keys/{id} [delete]
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Revoke(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+	response.OK(w, map[string]bool{"success": true})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "API key not found")
+	case errors.Is(err, ErrInvalidScopes):
+		response.BadRequest(w, "scopes must be a non-empty list of known scopes")
+	case errors.Is(err, ErrInvalidOverlap):
+		response.BadRequest(w, "overlapSeconds must be between 0 and 604800")
+	default:
+		response.InternalError(w)
+	}
+}
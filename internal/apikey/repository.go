@@ -0,0 +1,157 @@
+// Package apikey manages API keys for partner and internal services that call
+// the API server to server, instead of borrowing a user's token.
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// APIKey is a server-to-server credential. The key itself is never stored;
+// Hint identifies it in listings.
+type APIKey struct {
+	ID                string     `json:"id"`
+	Name              string     `json:"name"                        example:"accounting-sync"`
+	Hint              string     `json:"hint"                        example:"rdfk_…9f3a"`
+	Scopes            []string   `json:"scopes"                      example:"maintenance:write"`
+	PreviousExpiresAt *time.Time `json:"previousExpiresAt,omitempty"`
+	CreatedBy         *string    `json:"createdBy,omitempty"`
+	LastUsedAt        *time.Time `json:"lastUsedAt,omitempty"`
+	ExpiresAt         *time.Time `json:"expiresAt,omitempty"`
+	RevokedAt         *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+// ErrNotFound is returned when a key does not exist or has been revoked.
+var ErrNotFound = errors.New("API key not found")
+
+// Repository handles API key persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new apikey Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const keyCols = `id, name, hint, scopes, previous_expires_at, created_by, last_used_at, expires_at, revoked_at, created_at`
+
+func scanKey(row pgx.Row, k *APIKey) error {
+	return row.Scan(
+		&k.ID, &k.Name, &k.Hint, &k.Scopes, &k.PreviousExpiresAt, &k.CreatedBy,
+		&k.LastUsedAt, &k.ExpiresAt, &k.RevokedAt, &k.CreatedAt,
+	)
+}
+
+// Create inserts a key stored under hash.
+func (r *Repository) Create(ctx context.Context, name string, scopes []string, hash []byte, hint, createdBy string, expiresAt *time.Time) (*APIKey, error) {
+	k := &APIKey{}
+	err := scanKey(r.db.QueryRow(ctx,
+		`INSERT INTO api_keys (name, scopes, key_hash, hint, created_by, expires_at)
+		 VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, $6)
+		 RETURNING `+keyCols,
+		name, scopes, hash, hint, createdBy, expiresAt,
+	), k)
+	if err != nil {
+		return nil, fmt.Errorf("insert api key: %w", err)
+	}
+	return k, nil
+}
+
+// List returns every key, revoked ones included, newest first.
+func (r *Repository) List(ctx context.Context) ([]*APIKey, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+keyCols+` FROM api_keys ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*APIKey{}
+	for rows.Next() {
+		k := &APIKey{}
+		if err := scanKey(rows, k); err != nil {
+			return nil, fmt.Errorf("scan api key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// Rotate replaces an active key's hash, keeping the old one valid until
+// previousExpiresAt.
+func (r *Repository) Rotate(ctx context.Context, id string, hash []byte, hint string, previousExpiresAt time.Time) (*APIKey, error) {
+	k := &APIKey{}
+	err := scanKey(r.db.QueryRow(ctx,
+		`UPDATE api_keys
+		 SET previous_key_hash = key_hash, previous_expires_at = $4, key_hash = $2, hint = $3
+		 WHERE id = $1 AND revoked_at IS NULL
+		 RETURNING `+keyCols,
+		id, hash, hint, previousExpiresAt,
+	), k)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rotate api key: %w", err)
+	}
+	return k, nil
+}
+
+// Revoke disables a key, and any previous key still in its overlap window,
+// immediately.
+func (r *Repository) Revoke(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if isInvalidID(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("revoke api key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FindActive returns the usable key stored under hash, current or previous,
+// or ErrNotFound. Its last use is refreshed at most once a minute, so busy
+// integrations do not write on every request.
+func (r *Repository) FindActive(ctx context.Context, hash []byte) (*APIKey, error) {
+	k := &APIKey{}
+	err := scanKey(r.db.QueryRow(ctx,
+		`WITH found AS (
+		     SELECT id FROM api_keys
+		     WHERE (key_hash = $1 OR (previous_key_hash = $1 AND previous_expires_at > NOW()))
+		       AND revoked_at IS NULL
+		       AND (expires_at IS NULL OR expires_at > NOW())
+		 ), touched AS (
+		     UPDATE api_keys SET last_used_at = NOW()
+		     WHERE id IN (SELECT id FROM found)
+		       AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+		 )
+		 SELECT `+keyCols+` FROM api_keys WHERE id IN (SELECT id FROM found)`,
+		hash,
+	), k)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find api key: %w", err)
+	}
+	return k, nil
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// as raised for a malformed UUID.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
@@ -0,0 +1,151 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/radif/service/internal/middleware"
+)
+
+const (
+	keyPrefix         = "rdfk_"
+	defaultKeyOverlap = 24 * time.Hour
+	maxKeyOverlap     = 7 * 24 * time.Hour
+)
+
+// ErrInvalidScopes is returned when a key is requested with no or unknown scopes.
+var ErrInvalidScopes = errors.New("invalid scopes")
+
+// ErrInvalidOverlap is returned when a rotation overlap window is out of range.
+var ErrInvalidOverlap = errors.New("invalid overlap window")
+
+// CreatedKey is returned when a key is created or rotated; it is the only
+// time the key itself is revealed.
+type CreatedKey struct {
+	*APIKey
+	Key string `json:"key" example:"rdfk_5f0c…"`
+}
+
+// Service contains business logic for API keys.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new apikey Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Create issues a key named name, limited to scopes and, when ttl is
+// positive, expiring after it.
+func (s *Service) Create(ctx context.Context, createdBy, name string, scopes []string, ttl time.Duration) (*CreatedKey, error) {
+	scopes, err := checkScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := time.Now().Add(ttl)
+		expiresAt = &t
+	}
+
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	k, err := s.repo.Create(ctx, name, scopes, hashKey(key), keyHint(key), createdBy, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &CreatedKey{APIKey: k, Key: key}, nil
+}
+
+// List returns every key with the keys themselves hidden.
+func (s *Service) List(ctx context.Context) ([]*APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+// Rotate issues a new key in place of key id. The previous one remains
+// valid for overlap (default 24h, max 7 days; zero ends it immediately when
+// immediate is true) so the caller can redeploy without failed requests.
+func (s *Service) Rotate(ctx context.Context, id string, overlap time.Duration, immediate bool) (*CreatedKey, error) {
+	if overlap < 0 || overlap > maxKeyOverlap {
+		return nil, ErrInvalidOverlap
+	}
+	if overlap == 0 && !immediate {
+		overlap = defaultKeyOverlap
+	}
+
+	key, err := generateKey()
+	if err != nil {
+		return nil, err
+	}
+	k, err := s.repo.Rotate(ctx, id, hashKey(key), keyHint(key), time.Now().Add(overlap))
+	if err != nil {
+		return nil, err
+	}
+	return &CreatedKey{APIKey: k, Key: key}, nil
+}
+
+// Revoke disables key id immediately.
+func (s *Service) Revoke(ctx context.Context, id string) error {
+	return s.repo.Revoke(ctx, id)
+}
+
+// VerifyAPIKey implements middleware.APIKeyVerifier.
+func (s *Service) VerifyAPIKey(ctx context.Context, key string) (string, []string, error) {
+	k, err := s.repo.FindActive(ctx, hashKey(key))
+	if errors.Is(err, ErrNotFound) {
+		return "", nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return k.ID, k.Scopes, nil
+}
+
+// checkScopes rejects an empty list or unknown scopes and drops duplicates.
+// Keys never get ScopeAll: a service is granted exactly what it needs.
+func checkScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScopes
+	}
+	seen := make(map[string]bool, len(scopes))
+	unique := make([]string, 0, len(scopes))
+	for _, sc := range scopes {
+		if !middleware.APIKeyScopes[sc] {
+			return nil, ErrInvalidScopes
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			unique = append(unique, sc)
+		}
+	}
+	return unique, nil
+}
+
+// hashKey is what is stored for a key. Keys are long and random, so a plain
+// SHA-256 is enough; there is nothing to brute-force.
+func hashKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+// keyHint returns a recognizable, non-sensitive suffix of the key.
+func keyHint(key string) string {
+	return keyPrefix + "…" + key[len(key)-4:]
+}
+
+// generateKey creates a new random API key.
+func generateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate api key: %w", err)
+	}
+	return keyPrefix + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,181 @@
+// Package audit keeps an append-only record of sensitive actions: who did
+// what to which record, from which IP, and which fields changed. Entries are
+// written after the action succeeds, or before it when the action must not
+// happen unrecorded, and are never updated or deleted.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/radif/service/internal/middleware"
+)
+
+// Actions recorded.
+const (
+	ActionProfileUpdated = "user.profile_updated"
+	ActionAvatarUpdated  = "user.avatar_updated"
+	ActionCoverUpdated   = "user.cover_updated"
+	ActionUserDeleted    = "user.deleted"
+	ActionUserRestored   = "user.restored"
+	// ActionAdminRequest is a state-changing request made through /admin.
+	ActionAdminRequest = "admin.request"
+	// ActionImpersonationStarted is an impersonation token being issued.
+	ActionImpersonationStarted = "admin.impersonation_started"
+	// ActionImpersonatedRequest is any request made with an impersonation token.
+	ActionImpersonatedRequest = "admin.impersonated_request"
+)
+
+// TargetUser is the target type of actions on a user account.
+const TargetUser = "user"
+
+// Entry is one audit log record. Before and After hold only the fields
+// that changed, so an update reads as a diff; a creation has no Before and
+// a deletion no After.
+type Entry struct {
+	ID         string          `json:"id"`
+	Action     string          `json:"action"                example:"user.profile_updated"`
+	ActorID    *string         `json:"actorId,omitempty"`
+	ActorRole  *string         `json:"actorRole,omitempty"   example:"admin"`
+	TargetType *string         `json:"targetType,omitempty"  example:"user"`
+	TargetID   *string         `json:"targetId,omitempty"`
+	IP         *string         `json:"ip,omitempty"          example:"203.0.113.7"`
+	RequestID  *string         `json:"requestId,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"      swaggertype:"object"`
+	After      json.RawMessage `json:"after,omitempty"       swaggertype:"object"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"    swaggertype:"object"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+// Log writes audit entries. A nil Log discards them.
+type Log struct {
+	repo *Repository
+}
+
+// NewLog creates a Log writing to repo.
+func NewLog(repo *Repository) *Log {
+	return &Log{repo: repo}
+}
+
+// WithTx returns a Log that writes on tx, so an entry is stored only if the
+// action it records commits.
+func (l *Log) WithTx(tx pgx.Tx) *Log {
+	if l == nil {
+		return nil
+	}
+	return &Log{repo: l.repo.WithTx(tx)}
+}
+
+// Record stores an action on target, taking the actor, client IP and request
+// ID from ctx. Under an impersonation token the actor is the admin, not the
+// user they are acting as. before and after are diffed field by field; either may be nil.
+// The action has already happened, so a failed write is logged rather than
+// returned.
+func (l *Log) Record(ctx context.Context, action, targetType, targetID string, before, after any) {
+	l.record(ctx, action, targetType, targetID, before, after, nil)
+}
+
+func (l *Log) record(ctx context.Context, action, targetType, targetID string, before, after, metadata any) {
+	if err := l.write(ctx, action, targetType, targetID, before, after, metadata); err != nil {
+		slog.ErrorContext(ctx, "audit: record failed", "action", action, "target_type", targetType, "target_id", targetID, "err", err)
+	}
+}
+
+// Write stores an action that must not go unrecorded, such as issuing an
+// impersonation token. It is called before the action, and unlike Record
+// it returns a failed write so the caller can refuse to go ahead. metadata
+// may be nil.
+func (l *Log) Write(ctx context.Context, action, targetType, targetID string, metadata any) error {
+	return l.write(ctx, action, targetType, targetID, nil, nil, metadata)
+}
+
+func (l *Log) write(ctx context.Context, action, targetType, targetID string, before, after, metadata any) error {
+	if l == nil {
+		return nil
+	}
+	actorID, actorRole := fromContext(ctx, middleware.UserIDKey), fromContext(ctx, middleware.UserRoleKey)
+	if id := fromContext(ctx, middleware.ImpersonatorIDKey); id != nil {
+		actorID, actorRole = id, optional(middleware.RoleAdmin)
+	}
+	e := &Entry{
+		Action:     action,
+		ActorID:    actorID,
+		ActorRole:  actorRole,
+		TargetType: optional(targetType),
+		TargetID:   optional(targetID),
+		IP:         fromContext(ctx, middleware.ClientIPKey),
+		RequestID:  optional(chiMiddleware.GetReqID(ctx)),
+	}
+	var err error
+	if e.Before, e.After, err = diff(before, after); err != nil {
+		return fmt.Errorf("encode audit diff: %w", err)
+	}
+	if metadata != nil {
+		if e.Metadata, err = json.Marshal(metadata); err != nil {
+			return fmt.Errorf("encode audit metadata: %w", err)
+		}
+	}
+	return l.repo.Insert(ctx, e)
+}
+
+// diff encodes the top-level JSON fields of before and after that differ.
+// A value that is not a JSON object is kept whole.
+func diff(before, after any) (json.RawMessage, json.RawMessage, error) {
+	b, err := encode(before)
+	if err != nil {
+		return nil, nil, err
+	}
+	a, err := encode(after)
+	if err != nil {
+		return nil, nil, err
+	}
+	var bm, am map[string]json.RawMessage
+	if b == nil || a == nil || json.Unmarshal(b, &bm) != nil || json.Unmarshal(a, &am) != nil {
+		return b, a, nil
+	}
+	for k, v := range bm {
+		if w, ok := am[k]; ok && bytes.Equal(v, w) {
+			delete(bm, k)
+			delete(am, k)
+		}
+	}
+	if b, err = json.Marshal(bm); err != nil {
+		return nil, nil, err
+	}
+	if a, err = json.Marshal(am); err != nil {
+		return nil, nil, err
+	}
+	return b, a, nil
+}
+
+// encode marshals v, leaving nil as nil.
+func encode(v any) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if raw, ok := v.(json.RawMessage); ok {
+		return raw, nil
+	}
+	return json.Marshal(v)
+}
+
+// fromContext returns the string value of key in ctx, or nil.
+func fromContext(ctx context.Context, key any) *string {
+	s, _ := ctx.Value(key).(string)
+	return optional(s)
+}
+
+// optional maps "" to nil.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,76 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler serves the admin audit log API.
+type Handler struct {
+	repo *Repository
+}
+
+// NewHandler creates a new audit Handler.
+func NewHandler(repo *Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// List godoc
+//
+//	@Summary		Query the audit log
+//	@Description	Audit log entries, newest first, optionally filtered by actor, action and target. before and after hold only the fields that changed. Pass nextCursor from the previous page as cursor to continue. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			actorId		query		string	false	"User ID of the actor"
+//	@Param			action		query		string	false	"Action (e.g. user.profile_updated, admin.request)"
+//	@Param			targetType	query		string	false	"Target type (e.g. user, kyc, categories)"
+//	@Param			targetId	query		string	false	"Target ID"
+//	@Param			cursor		query		string	false	"Cursor from the previous page"
+//	@Param			limit		query		int		false	"Page size (1-100, default 50)"
+//	@Success		200			{object}	response.Envelope{data=response.Page{items=[]Entry}}
+//	@Failure		400			{object}	response.Envelope
+//	@Failure		401			{object}	response.Envelope
+//	@Failure		403			{object}	response.Envelope
+//	@Failure		500			{object}	response.Envelope
+//	@Router			/admin/audit-logs [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 50
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	cur, err := db.DecodeCursor(q.Get("cursor"))
+	if err != nil {
+		response.BadRequest(w, "invalid cursor")
+		return
+	}
+
+	f := Filter{
+		ActorID:    q.Get("actorId"),
+		Action:     q.Get("action"),
+		TargetType: q.Get("targetType"),
+		TargetID:   q.Get("targetId"),
+	}
+	entries, err := h.repo.List(r.Context(), f, cur, limit)
+	if err != nil {
+		if errors.Is(err, ErrInvalidFilter) {
+			response.BadRequest(w, "invalid actorId or cursor")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, response.NewPage(entries, db.NextCursor(entries, limit, func(e *Entry) db.Cursor {
+		return db.Cursor{CreatedAt: e.CreatedAt, ID: e.ID}
+	})))
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+)
+
+// maxRecordedBody is the largest request body kept with an admin entry.
+const maxRecordedBody = 64 << 10
+
+// request is the metadata of an ActionAdminRequest or
+// ActionImpersonatedRequest entry.
+type request struct {
+	Method string            `json:"method"`
+	Route  string            `json:"route"`
+	Params map[string]string `json:"params,omitempty"`
+	Status int               `json:"status"`
+}
+
+// Admin records every state-changing request under the route it wraps,
+// whatever its outcome, with its JSON body as After. The target is the
+// record named by the route's {userId}, {id} or {code} parameter.
+func (l *Log) Admin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		l.serve(w, r, next, ActionAdminRequest, func(meta request) (string, string) {
+			for _, k := range []string{"userId", "id", "code"} {
+				if v := meta.Params[k]; v != "" {
+					return adminTarget(meta.Route), v
+				}
+			}
+			return adminTarget(meta.Route), ""
+		})
+	})
+}
+
+// Impersonated records every request made with an impersonation token,
+// reads included, against the impersonated user. It must be mounted after
+// middleware.RequireAuth; other requests pass through untouched.
+func (l *Log) Impersonated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, _ := r.Context().Value(middleware.ImpersonatorIDKey).(string); id == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+		l.serve(w, r, next, ActionImpersonatedRequest, func(request) (string, string) {
+			return TargetUser, userID
+		})
+	})
+}
+
+// serve runs next and records the request as action, with its JSON body as
+// After and the target picked by target once the route is known.
+func (l *Log) serve(w http.ResponseWriter, r *http.Request, next http.Handler, action string, target func(request) (string, string)) {
+	var body json.RawMessage
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		buf, err := io.ReadAll(io.LimitReader(r.Body, maxRecordedBody+1))
+		if err == nil && len(buf) <= maxRecordedBody && json.Valid(buf) {
+			body = buf
+		}
+		r.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(buf), r.Body), r.Body}
+	}
+
+	sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(sw, r)
+
+	meta := request{Method: r.Method, Status: sw.status}
+	if rctx := chi.RouteContext(r.Context()); rctx != nil {
+		meta.Route = rctx.RoutePattern()
+		meta.Params = make(map[string]string, len(rctx.URLParams.Keys))
+		for i, k := range rctx.URLParams.Keys {
+			meta.Params[k] = rctx.URLParams.Values[i]
+		}
+	}
+	targetType, targetID := target(meta)
+	// Recorded even when the request ran out of time or was abandoned.
+	l.record(context.WithoutCancel(r.Context()), action, targetType, targetID, nil, body, meta)
+}
+
+// adminTarget names the resource of an admin route, e.g. "kyc" for
+// /api/v1/admin/kyc/{userId}/approve.
+func adminTarget(route string) string {
+	_, rest, ok := strings.Cut(route, "/admin/")
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(rest, "/")
+	return name
+}
+
+// statusWriter captures the response status.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (sw *statusWriter) WriteHeader(status int) {
+	if !sw.wroteHeader {
+		sw.wroteHeader = true
+		sw.status = status
+	}
+	sw.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
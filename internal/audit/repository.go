@@ -0,0 +1,97 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/radif/service/internal/db"
+)
+
+// Repository handles audit log persistence. It only ever inserts and reads.
+type Repository struct {
+	db db.Querier
+}
+
+// NewRepository creates a new audit Repository.
+func NewRepository(q db.Querier) *Repository {
+	return &Repository{db: q}
+}
+
+// WithTx returns a copy of the repository that runs its queries on tx, so
+// they commit or roll back together with the caller's other work.
+func (r *Repository) WithTx(tx pgx.Tx) *Repository {
+	return &Repository{db: tx}
+}
+
+// Filter narrows a listing; empty fields match everything.
+type Filter struct {
+	ActorID    string
+	Action     string
+	TargetType string
+	TargetID   string
+}
+
+// Insert appends an entry.
+func (r *Repository) Insert(ctx context.Context, e *Entry) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO audit_logs (action, actor_id, actor_role, target_type, target_id, ip, request_id, before, after, metadata)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		e.Action, e.ActorID, e.ActorRole, e.TargetType, e.TargetID, e.IP, e.RequestID, e.Before, e.After, e.Metadata,
+	)
+	if err != nil {
+		return fmt.Errorf("insert audit log: %w", err)
+	}
+	return nil
+}
+
+// List returns up to limit entries matching f strictly older than the
+// cursor, newest first. A nil cursor starts at the latest entry.
+func (r *Repository) List(ctx context.Context, f Filter, cur *db.Cursor, limit int) ([]*Entry, error) {
+	before, beforeID := db.CursorArgs(cur)
+	rows, err := r.db.Query(ctx,
+		`SELECT id, action, actor_id, actor_role, target_type, target_id, ip, request_id, before, after, metadata, created_at
+		 FROM audit_logs
+		 WHERE ($1 = '' OR actor_id = NULLIF($1, '')::uuid)
+		   AND ($2 = '' OR action = $2)
+		   AND ($3 = '' OR target_type = $3)
+		   AND ($4 = '' OR target_id = $4)
+		   AND ($5::timestamptz IS NULL OR (created_at, id) < ($5, $6::uuid))
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $7`,
+		f.ActorID, f.Action, f.TargetType, f.TargetID, before, beforeID, limit,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return nil, ErrInvalidFilter
+		}
+		return nil, fmt.Errorf("list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Entry{}
+	for rows.Next() {
+		e := &Entry{}
+		if err := rows.Scan(
+			&e.ID, &e.Action, &e.ActorID, &e.ActorRole, &e.TargetType, &e.TargetID,
+			&e.IP, &e.RequestID, &e.Before, &e.After, &e.Metadata, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan audit log: %w", err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// ErrInvalidFilter is returned when the actor ID or cursor is not a UUID.
+var ErrInvalidFilter = errors.New("invalid actorId or cursor")
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// as raised for a malformed UUID.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
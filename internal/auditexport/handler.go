@@ -0,0 +1,158 @@
+package auditexport
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+	"github.com/radif/service/internal/validate"
+)
+
+// Handler holds HTTP handlers for audit trail exports.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new auditexport Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type createExportRequest struct {
+	// UserID limits the export to entries the user performed or was the
+	// target of. Omit to export every entry in the period.
+	UserID string `json:"userId" validate:"omitempty,uuid"`
+	// From is required when userId is omitted.
+	From *time.Time `json:"from" example:"2025-01-01T00:00:00Z"`
+	// To defaults to now.
+	To     *time.Time `json:"to"     example:"2025-07-01T00:00:00Z"`
+	Reason string     `json:"reason" example:"Court order 1405/123" validate:"required,max=500"`
+}
+
+// Create godoc
+//
+//	@Summary		Export the audit trail
+//	@Description	Build a signed, tamper-evident archive of the audit entries a user performed or was the target of, or of every entry in a period. The zip holds records.jsonl, whose lines are hash-chained (each hash is SHA-256 of the previous hash followed by the entry), manifest.json with the chain's head and the filter, and manifest.sig, the base64 Ed25519 signature of manifest.json verifiable with publicKey. At most 100000 entries per export; narrow the period for more. Admin only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		createExportRequest	true	"Filter and the request it answers"
+//	@Success		201		{object}	response.Envelope{data=Download}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		413		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/audit-exports [post]
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || adminID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req createExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if errs := validate.Struct(req); errs != nil {
+		response.ValidationFailed(w, errs)
+		return
+	}
+
+	d, err := h.svc.Create(r.Context(), adminID, Request{UserID: req.UserID, From: req.From, To: req.To, Reason: req.Reason})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Created(w, d)
+}
+
+// List godoc
+//
+//	@Summary		List audit trail exports
+//	@Description	Exports generated so far, newest first, with the head hash and signature of each archive. Pass nextCursor from the previous page as cursor to continue. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			cursor	query		string	false	"Cursor from the previous page"
+//	@Param			limit	query		int		false	"Page size (1-100, default 50)"
+//	@Success		200		{object}	response.Envelope{data=response.Page{items=[]Export}}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/audit-exports [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 50
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	cur, err := db.DecodeCursor(q.Get("cursor"))
+	if err != nil {
+		response.BadRequest(w, "invalid cursor")
+		return
+	}
+
+	exports, err := h.svc.List(r.Context(), cur, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.OK(w, response.NewPage(exports, db.NextCursor(exports, limit, func(e *Export) db.Cursor {
+		return db.Cursor{CreatedAt: e.CreatedAt, ID: e.ID}
+	})))
+}
+
+// Download godoc
+//
+//	@Summary		Download an audit trail export
+//	@Description	Returns a signed URL for an export's archive, valid for 15 minutes. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Export ID"
+//	@Success		200	{object}	response.Envelope{data=Download}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/admin/audit-exports/{id}/download [get]
+func (h *Handler) Download(w http.ResponseWriter, r *http.Request) {
+	d, err := h.svc.Download(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.OK(w, d)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "audit export not found")
+	case errors.Is(err, ErrInvalidRequest):
+		response.BadRequest(w, "reason is required, from is required without userId, and from must be before to")
+	case errors.Is(err, ErrInvalidFilter):
+		response.BadRequest(w, "invalid userId or cursor")
+	case errors.Is(err, ErrTooLarge):
+		response.Error(w, http.StatusRequestEntityTooLarge, "more than 100000 audit entries match; narrow the period")
+	default:
+		response.InternalError(w)
+	}
+}
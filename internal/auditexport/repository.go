@@ -0,0 +1,194 @@
+// Package auditexport assembles a user's or a period's audit trail into a
+// signed, tamper-evident archive for judicial and regulatory requests.
+// Records are hash-chained, so removing, reordering or editing one breaks
+// every later link, and the manifest carrying the chain's head is signed
+// with Ed25519. Archives are kept in a private bucket and handed out
+// through short-lived signed URLs.
+package auditexport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/radif/service/internal/audit"
+	"github.com/radif/service/internal/db"
+)
+
+// Export is one generated archive.
+type Export struct {
+	ID          string     `json:"id"`
+	RequestedBy *string    `json:"requestedBy,omitempty"`
+	UserID      *string    `json:"userId,omitempty"`
+	From        *time.Time `json:"from,omitempty"`
+	To          time.Time  `json:"to"`
+	Reason      string     `json:"reason"                example:"Court order 1405/123"`
+	ObjectKey   string     `json:"-"`
+	RecordCount int        `json:"recordCount"`
+	HeadHash    string     `json:"headHash"              example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	Signature   string     `json:"signature"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// Filter selects the audit entries of an export: those with UserID as
+// actor or target, when set, created in [From, To).
+type Filter struct {
+	UserID string
+	From   *time.Time
+	To     time.Time
+}
+
+// ErrNotFound is returned when an export does not exist.
+var ErrNotFound = errors.New("audit export not found")
+
+// Repository handles audit export persistence.
+type Repository struct {
+	db db.Querier
+}
+
+// NewRepository creates a new auditexport Repository.
+func NewRepository(q db.Querier) *Repository {
+	return &Repository{db: q}
+}
+
+// WithTx returns a copy of the repository that runs its queries on tx, so
+// they commit or roll back together with the caller's other work.
+func (r *Repository) WithTx(tx pgx.Tx) *Repository {
+	return &Repository{db: tx}
+}
+
+const selectCols = `id, requested_by, user_id, from_at, to_at, reason, object_key,
+	record_count, head_hash, signature, created_at`
+
+func scanExport(row pgx.Row, e *Export) error {
+	return row.Scan(
+		&e.ID, &e.RequestedBy, &e.UserID, &e.From, &e.To, &e.Reason, &e.ObjectKey,
+		&e.RecordCount, &e.HeadHash, &e.Signature, &e.CreatedAt,
+	)
+}
+
+// Create inserts a new export before its archive is built, assigning its ID
+// and creation time.
+func (r *Repository) Create(ctx context.Context, e *Export) error {
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO audit_exports (requested_by, user_id, from_at, to_at, reason)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING id, created_at`,
+		e.RequestedBy, e.UserID, e.From, e.To, e.Reason,
+	).Scan(&e.ID, &e.CreatedAt)
+	if isInvalidID(err) {
+		return ErrInvalidFilter
+	}
+	if err != nil {
+		return fmt.Errorf("create audit export: %w", err)
+	}
+	return nil
+}
+
+// Complete records the stored archive of export e.
+func (r *Repository) Complete(ctx context.Context, e *Export) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE audit_exports
+		 SET object_key = $2, record_count = $3, head_hash = $4, signature = $5
+		 WHERE id = $1`,
+		e.ID, e.ObjectKey, e.RecordCount, e.HeadHash, e.Signature,
+	)
+	if err != nil {
+		return fmt.Errorf("complete audit export: %w", err)
+	}
+	return nil
+}
+
+// Get returns an export by ID.
+func (r *Repository) Get(ctx context.Context, id string) (*Export, error) {
+	e := &Export{}
+	err := scanExport(r.db.QueryRow(ctx, `SELECT `+selectCols+` FROM audit_exports WHERE id = $1`, id), e)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get audit export: %w", err)
+	}
+	return e, nil
+}
+
+// List returns up to limit exports strictly older than the cursor, newest
+// first. A nil cursor starts at the latest export.
+func (r *Repository) List(ctx context.Context, cur *db.Cursor, limit int) ([]*Export, error) {
+	before, beforeID := db.CursorArgs(cur)
+	rows, err := r.db.Query(ctx,
+		`SELECT `+selectCols+` FROM audit_exports
+		 WHERE $1::timestamptz IS NULL OR (created_at, id) < ($1, $2::uuid)
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $3`,
+		before, beforeID, limit,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return nil, ErrInvalidFilter
+		}
+		return nil, fmt.Errorf("list audit exports: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Export{}
+	for rows.Next() {
+		e := &Export{}
+		if err := scanExport(rows, e); err != nil {
+			return nil, fmt.Errorf("scan audit export: %w", err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// Entries returns up to limit audit entries matching f strictly after the
+// cursor, oldest first. A nil cursor starts at the first entry.
+func (r *Repository) Entries(ctx context.Context, f Filter, after *db.Cursor, limit int) ([]*audit.Entry, error) {
+	afterAt, afterID := db.CursorArgs(after)
+	rows, err := r.db.Query(ctx,
+		`SELECT id, action, actor_id, actor_role, target_type, target_id, ip, request_id, before, after, metadata, created_at
+		 FROM audit_logs
+		 WHERE ($1 = '' OR actor_id = NULLIF($1, '')::uuid OR (target_type = 'user' AND target_id = $1))
+		   AND ($2::timestamptz IS NULL OR created_at >= $2)
+		   AND created_at < $3
+		   AND ($4::timestamptz IS NULL OR (created_at, id) > ($4, $5::uuid))
+		 ORDER BY created_at, id
+		 LIMIT $6`,
+		f.UserID, f.From, f.To, afterAt, afterID, limit,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return nil, ErrInvalidFilter
+		}
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*audit.Entry{}
+	for rows.Next() {
+		e := &audit.Entry{}
+		if err := rows.Scan(
+			&e.ID, &e.Action, &e.ActorID, &e.ActorRole, &e.TargetType, &e.TargetID,
+			&e.IP, &e.RequestID, &e.Before, &e.After, &e.Metadata, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// ErrInvalidFilter is returned when the user ID or cursor is not a UUID.
+var ErrInvalidFilter = errors.New("invalid userId or cursor")
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// as raised for a malformed UUID.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
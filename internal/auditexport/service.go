@@ -0,0 +1,297 @@
+package auditexport
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/radif/service/internal/audit"
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/storage"
+)
+
+const (
+	// maxRecords bounds one archive; narrower filters split larger requests.
+	maxRecords = 100_000
+	// entryBatchSize is how many audit entries are read per query.
+	entryBatchSize = 1000
+	// maxReasonLength bounds the free-text reference to the request.
+	maxReasonLength = 500
+	// downloadURLTTL is how long a signed archive link stays valid.
+	downloadURLTTL = 15 * time.Minute
+	// archiveContentType is the media type archives are stored with.
+	archiveContentType = "application/zip"
+)
+
+// ErrInvalidRequest is returned when an export has no reason, an empty or
+// reversed period, or neither a user nor a start.
+var ErrInvalidRequest = errors.New("invalid audit export request")
+
+// ErrTooLarge is returned when the filter matches more than maxRecords entries.
+var ErrTooLarge = errors.New("audit export too large")
+
+// Request describes the export an admin asks for. To defaults to now. A
+// request for all users must set From.
+type Request struct {
+	UserID string
+	From   *time.Time
+	To     *time.Time
+	Reason string
+}
+
+// Download is a signed link to an export's archive.
+type Download struct {
+	*Export
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	PublicKey string    `json:"publicKey" example:"11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo="`
+}
+
+// Record is one line of records.jsonl in an archive. Hash is the SHA-256
+// of PrevHash's bytes followed by Entry exactly as serialized on the line.
+type Record struct {
+	Seq      int             `json:"seq"`
+	PrevHash string          `json:"prevHash"`
+	Hash     string          `json:"hash"`
+	Entry    json.RawMessage `json:"entry"`
+}
+
+// Manifest is manifest.json in an archive; manifest.sig holds the base64
+// Ed25519 signature of its bytes. GenesisHash is the PrevHash of the first
+// record, derived from the export ID, and HeadHash the Hash of the last.
+type Manifest struct {
+	ExportID      string     `json:"exportId"`
+	RequestedBy   *string    `json:"requestedBy,omitempty"`
+	UserID        *string    `json:"userId,omitempty"`
+	From          *time.Time `json:"from,omitempty"`
+	To            time.Time  `json:"to"`
+	Reason        string     `json:"reason"`
+	RecordCount   int        `json:"recordCount"`
+	GenesisHash   string     `json:"genesisHash"`
+	HeadHash      string     `json:"headHash"`
+	RecordsSHA256 string     `json:"recordsSha256"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	Algorithm     string     `json:"algorithm"`
+	PublicKey     string     `json:"publicKey"`
+}
+
+// Service contains business logic for audit exports.
+type Service struct {
+	repo  *Repository
+	txm   db.Transactor
+	store storage.Storage
+	key   ed25519.PrivateKey
+}
+
+// NewService creates a new auditexport Service storing archives in store
+// and signing them with key.
+func NewService(repo *Repository, txm db.Transactor, store storage.Storage, key ed25519.PrivateKey) *Service {
+	return &Service{repo: repo, txm: txm, store: store, key: key}
+}
+
+// PublicKey returns the base64 Ed25519 public key that verifies archive
+// signatures.
+func (s *Service) PublicKey() string {
+	return base64.StdEncoding.EncodeToString(s.key.Public().(ed25519.PublicKey))
+}
+
+// Create builds, signs and stores the archive of the audit entries
+// matching req, on behalf of admin requestedBy, and returns a link to it.
+// The export row commits only once its archive is stored.
+func (s *Service) Create(ctx context.Context, requestedBy string, req Request) (*Download, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	to := time.Now()
+	if req.To != nil {
+		to = *req.To
+	}
+	switch {
+	case req.Reason == "", len([]rune(req.Reason)) > maxReasonLength,
+		req.UserID == "" && req.From == nil,
+		req.From != nil && !req.From.Before(to):
+		return nil, ErrInvalidRequest
+	}
+
+	e := &Export{RequestedBy: optional(requestedBy), UserID: optional(req.UserID), From: req.From, To: to, Reason: req.Reason}
+	err := s.txm.WithTx(ctx, func(tx pgx.Tx) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.Create(ctx, e); err != nil {
+			return err
+		}
+		if err := s.build(ctx, repo, e); err != nil {
+			return err
+		}
+		return repo.Complete(ctx, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.download(ctx, e)
+}
+
+// List returns up to limit exports older than the cursor, newest first.
+func (s *Service) List(ctx context.Context, cur *db.Cursor, limit int) ([]*Export, error) {
+	return s.repo.List(ctx, cur, limit)
+}
+
+// Download returns a fresh link to the archive of export id.
+func (s *Service) Download(ctx context.Context, id string) (*Download, error) {
+	e, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.download(ctx, e)
+}
+
+func (s *Service) download(ctx context.Context, e *Export) (*Download, error) {
+	url, err := s.store.SignedURL(ctx, e.ObjectKey, downloadURLTTL)
+	if err != nil {
+		return nil, fmt.Errorf("sign audit export url: %w", err)
+	}
+	return &Download{Export: e, URL: url, ExpiresAt: time.Now().Add(downloadURLTTL), PublicKey: s.PublicKey()}, nil
+}
+
+// build writes e's archive to a temporary file, uploads it and fills in
+// e's object key, record count, head hash and signature.
+func (s *Service) build(ctx context.Context, repo *Repository, e *Export) error {
+	f, err := os.CreateTemp("", "audit-export-*.zip")
+	if err != nil {
+		return fmt.Errorf("create archive file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	records, err := zw.Create("records.jsonl")
+	if err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	digest := sha256.New()
+	genesis := genesisHash(e.ID)
+	head, err := s.writeRecords(ctx, repo, e, io.MultiWriter(records, digest), genesis)
+	if err != nil {
+		return err
+	}
+	e.HeadHash = hex.EncodeToString(head)
+
+	manifest, err := json.MarshalIndent(Manifest{
+		ExportID:      e.ID,
+		RequestedBy:   e.RequestedBy,
+		UserID:        e.UserID,
+		From:          e.From,
+		To:            e.To,
+		Reason:        e.Reason,
+		RecordCount:   e.RecordCount,
+		GenesisHash:   hex.EncodeToString(genesis),
+		HeadHash:      e.HeadHash,
+		RecordsSHA256: hex.EncodeToString(digest.Sum(nil)),
+		CreatedAt:     e.CreatedAt,
+		Algorithm:     "Ed25519",
+		PublicKey:     s.PublicKey(),
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	e.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, manifest))
+	for name, data := range map[string][]byte{"manifest.json": manifest, "manifest.sig": []byte(e.Signature + "\n")} {
+		w, err := zw.Create(name)
+		if err != nil {
+			return fmt.Errorf("write archive: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("write archive: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("size archive: %w", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("rewind archive: %w", err)
+	}
+	e.ObjectKey = "exports/" + e.ID + ".zip"
+	if err := s.store.Upload(ctx, e.ObjectKey, f, size, archiveContentType); err != nil {
+		return fmt.Errorf("upload audit export: %w", err)
+	}
+	return nil
+}
+
+// writeRecords writes the hash-chained entries of e to w, oldest first,
+// starting from prev, and returns the chain's head.
+func (s *Service) writeRecords(ctx context.Context, repo *Repository, e *Export, w io.Writer, prev []byte) ([]byte, error) {
+	f := Filter{From: e.From, To: e.To}
+	if e.UserID != nil {
+		f.UserID = *e.UserID
+	}
+	var after *db.Cursor
+	for {
+		entries, err := repo.Entries(ctx, f, after, entryBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if e.RecordCount == maxRecords {
+				return nil, ErrTooLarge
+			}
+			if prev, err = writeRecord(w, e.RecordCount+1, prev, entry); err != nil {
+				return nil, err
+			}
+			e.RecordCount++
+		}
+		if len(entries) < entryBatchSize {
+			return prev, nil
+		}
+		last := entries[len(entries)-1]
+		after = &db.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
+
+// writeRecord writes entry as record seq chained to prev and returns its hash.
+func writeRecord(w io.Writer, seq int, prev []byte, entry *audit.Entry) ([]byte, error) {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("encode audit entry: %w", err)
+	}
+	h := sha256.New()
+	h.Write(prev)
+	h.Write(raw)
+	sum := h.Sum(nil)
+	line, err := json.Marshal(Record{Seq: seq, PrevHash: hex.EncodeToString(prev), Hash: hex.EncodeToString(sum), Entry: raw})
+	if err != nil {
+		return nil, fmt.Errorf("encode audit record: %w", err)
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("write archive: %w", err)
+	}
+	return sum, nil
+}
+
+// genesisHash starts the chain of export id, so records cannot be moved
+// between archives.
+func genesisHash(id string) []byte {
+	sum := sha256.Sum256([]byte("radif audit export " + id))
+	return sum[:]
+}
+
+// optional maps "" to nil.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -2,15 +2,19 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	"regexp"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/referral"
 	"github.com/radif/service/internal/response"
+	"github.com/radif/service/internal/user"
+	"github.com/radif/service/internal/validate"
 )
 
-// iranPhoneRegex matches valid Iranian mobile numbers (09XXXXXXXXX).
-var iranPhoneRegex = regexp.MustCompile(`^09[0-9]{9}$`)
-
 // Handler holds HTTP handlers for auth endpoints.
 type Handler struct {
 	svc *Service
@@ -22,26 +26,32 @@ func NewHandler(svc *Service) *Handler {
 }
 
 type sendOTPRequest struct {
-	Phone string `json:"phone" example:"09121234567"`
+	Phone string `json:"phone" example:"09121234567" validate:"required,iranphone"`
 }
 
 type verifyOTPRequest struct {
-	Phone string `json:"phone" example:"09121234567"`
-	Code  string `json:"code"  example:"12345"`
+	Phone string `json:"phone" example:"09121234567" validate:"required,iranphone"`
+	Code  string `json:"code"  example:"12345"       validate:"required,len=5,numeric"`
 }
 
 type registerRequest struct {
-	Phone       string `json:"phone"       example:"09121234567"`
-	AccountType string `json:"accountType" example:"personal"`
+	Phone             string `json:"phone"             example:"09121234567" validate:"required,iranphone"`
+	AccountType       string `json:"accountType"       example:"personal"    validate:"required,oneof=personal children business"`
+	ReferralCode      string `json:"referralCode"      example:"K7M2QX9P"`
+	RegistrationToken string `json:"registrationToken" example:"eyJhbGci..." validate:"required"`
 }
 
 type otpSuccessData struct {
 	Success bool `json:"success" example:"true"`
+	// DeliveryDelayed is true when SMS delivery is degraded and the code was
+	// queued; clients should tell the user it may take a moment to arrive.
+	DeliveryDelayed bool `json:"deliveryDelayed" example:"false"`
 }
 
 type verifyOTPData struct {
-	IsNewUser bool   `json:"isNewUser" example:"true"`
-	Token     string `json:"token,omitempty" example:"eyJhbGci..."`
+	IsNewUser         bool   `json:"isNewUser"                   example:"true"`
+	Token             string `json:"token,omitempty"             example:"eyJhbGci..."`
+	RegistrationToken string `json:"registrationToken,omitempty" example:"eyJhbGci..."`
 }
 
 type registerData struct {
@@ -60,13 +70,14 @@ type userBody struct {
 // SendOTP godoc
 //
 //	@Summary		Send OTP
-//	@Description	Generate and send a 5-digit OTP to the given Iranian mobile number. In development the code is printed to server logs.
+//	@Description	Generate and send a 5-digit OTP to the given Iranian mobile number. A phone is sent at most 5 codes an hour. In development the code is printed to server logs. When SMS delivery is degraded the code is queued for retry and deliveryDelayed is true.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		sendOTPRequest					true	"Phone number"
 //	@Success		200		{object}	response.Envelope{data=otpSuccessData}
 //	@Failure		400		{object}	response.Envelope
+//	@Failure		429		{object}	response.Envelope
 //	@Failure		500		{object}	response.Envelope
 //	@Router			/auth/otp/send [post]
 func (h *Handler) SendOTP(w http.ResponseWriter, r *http.Request) {
@@ -75,29 +86,35 @@ func (h *Handler) SendOTP(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "invalid request body")
 		return
 	}
-	if !iranPhoneRegex.MatchString(req.Phone) {
-		response.BadRequest(w, "invalid phone number format")
+	if errs := validate.Struct(req); errs != nil {
+		response.ValidationFailed(w, errs)
 		return
 	}
 
-	if err := h.svc.SendOTP(r.Context(), req.Phone); err != nil {
+	res, err := h.svc.SendOTP(r.Context(), req.Phone)
+	if errors.Is(err, ErrTooManyOTPs) {
+		response.Error(w, http.StatusTooManyRequests, "too many codes sent to this phone, try again later")
+		return
+	}
+	if err != nil {
 		response.InternalError(w)
 		return
 	}
 
-	response.OK(w, map[string]bool{"success": true})
+	response.OK(w, otpSuccessData{Success: true, DeliveryDelayed: res.DeliveryDelayed})
 }
 
 // VerifyOTP godoc
 //
 //	@Summary		Verify OTP
-//	@Description	Validate the OTP code. Returns isNewUser=true for first-time users (no token yet). Returns a JWT token for existing users immediately.
+//	@Description	Validate the OTP code. Returns isNewUser=true and a registrationToken, valid for 10 minutes, for first-time users; pass it to /auth/register. Returns a JWT token for existing users immediately. A phone gets 5 attempts every 10 minutes.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		verifyOTPRequest				true	"Phone and OTP code"
 //	@Success		200		{object}	response.Envelope{data=verifyOTPData}
 //	@Failure		400		{object}	response.Envelope
+//	@Failure		429		{object}	response.Envelope
 //	@Failure		500		{object}	response.Envelope
 //	@Router			/auth/otp/verify [post]
 func (h *Handler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
@@ -106,12 +123,8 @@ func (h *Handler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "invalid request body")
 		return
 	}
-	if !iranPhoneRegex.MatchString(req.Phone) {
-		response.BadRequest(w, "invalid phone number format")
-		return
-	}
-	if len(req.Code) != 5 {
-		response.BadRequest(w, "OTP code must be exactly 5 digits")
+	if errs := validate.Struct(req); errs != nil {
+		response.ValidationFailed(w, errs)
 		return
 	}
 
@@ -120,6 +133,10 @@ func (h *Handler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "invalid or expired OTP")
 		return
 	}
+	if errors.Is(err, ErrTooManyAttempts) {
+		response.Error(w, http.StatusTooManyRequests, "too many attempts, try again later")
+		return
+	}
 	if err != nil {
 		response.InternalError(w)
 		return
@@ -131,6 +148,9 @@ func (h *Handler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 	if result.Token != "" {
 		data["token"] = result.Token
 	}
+	if result.RegistrationToken != "" {
+		data["registrationToken"] = result.RegistrationToken
+	}
 	response.OK(w, data)
 }
 
@@ -144,6 +164,7 @@ func (h *Handler) VerifyOTP(w http.ResponseWriter, r *http.Request) {
 //	@Param			request	body		sendOTPRequest					true	"Phone number"
 //	@Success		200		{object}	response.Envelope{data=otpSuccessData}
 //	@Failure		400		{object}	response.Envelope
+//	@Failure		429		{object}	response.Envelope
 //	@Failure		500		{object}	response.Envelope
 //	@Router			/auth/otp/resend [post]
 func (h *Handler) ResendOTP(w http.ResponseWriter, r *http.Request) {
@@ -152,29 +173,35 @@ func (h *Handler) ResendOTP(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "invalid request body")
 		return
 	}
-	if !iranPhoneRegex.MatchString(req.Phone) {
-		response.BadRequest(w, "invalid phone number format")
+	if errs := validate.Struct(req); errs != nil {
+		response.ValidationFailed(w, errs)
 		return
 	}
 
-	if err := h.svc.SendOTP(r.Context(), req.Phone); err != nil {
+	res, err := h.svc.SendOTP(r.Context(), req.Phone)
+	if errors.Is(err, ErrTooManyOTPs) {
+		response.Error(w, http.StatusTooManyRequests, "too many codes sent to this phone, try again later")
+		return
+	}
+	if err != nil {
 		response.InternalError(w)
 		return
 	}
 
-	response.OK(w, map[string]bool{"success": true})
+	response.OK(w, otpSuccessData{Success: true, DeliveryDelayed: res.DeliveryDelayed})
 }
 
 // Register godoc
 //
 //	@Summary		Register new user
-//	@Description	Create a new user account with the specified account type. Issues a JWT token on success. Idempotent: calling again with the same phone returns a fresh token.
+//	@Description	Create a new user account with the specified account type. Requires the registrationToken returned by /auth/otp/verify for the same phone; each one can be used once. Issues a JWT token on success. If the phone already has an account, signs in to it instead. An optional referralCode attributes the new user to the inviting user.
 //	@Tags			auth
 //	@Accept			json
 //	@Produce		json
 //	@Param			request	body		registerRequest					true	"Registration details"
 //	@Success		201		{object}	response.Envelope{data=registerData}
 //	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
 //	@Failure		500		{object}	response.Envelope
 //	@Router			/auth/register [post]
 func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
@@ -183,23 +210,21 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		response.BadRequest(w, "invalid request body")
 		return
 	}
-	if !iranPhoneRegex.MatchString(req.Phone) {
-		response.BadRequest(w, "invalid phone number format")
-		return
-	}
-
-	validTypes := map[string]bool{
-		"personal": true,
-		"children": true,
-		"business": true,
-	}
-	if !validTypes[req.AccountType] {
-		response.BadRequest(w, "accountType must be one of: personal, children, business")
+	if errs := validate.Struct(req); errs != nil {
+		response.ValidationFailed(w, errs)
 		return
 	}
 
-	token, u, err := h.svc.Register(r.Context(), req.Phone, req.AccountType)
+	token, u, err := h.svc.Register(r.Context(), req.RegistrationToken, req.Phone, req.AccountType, req.ReferralCode)
 	if err != nil {
+		if errors.Is(err, ErrInvalidRegistrationToken) {
+			response.Unauthorized(w, "invalid or expired registration token")
+			return
+		}
+		if errors.Is(err, referral.ErrUnknownCode) {
+			response.BadRequest(w, "unknown referral code")
+			return
+		}
 		response.InternalError(w)
 		return
 	}
@@ -209,3 +234,154 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		"user":  u,
 	})
 }
+
+type scopedTokenRequest struct {
+	Scopes     []string `json:"scopes"     example:"profile:read,groups" validate:"required,min=1"`
+	TTLSeconds int      `json:"ttlSeconds" example:"900"                 validate:"omitempty,min=1,max=604800"`
+}
+
+// IssueScopedToken godoc
+//
+//	@Summary		Issue limited-capability token
+//	@Description	Mint a token restricted to the given scopes, e.g. for the web checkout widget or a delegation. Requires a full-access session token. Known scopes: profile:read, profile:write, bank_accounts:read, bank_accounts:write, contacts, notifications, webhooks, groups, messages. ttlSeconds defaults to 3600 and may be at most 7 days.
+//	@Tags			auth
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		scopedTokenRequest	true	"Scopes and lifetime"
+//	@Success		201		{object}	response.Envelope{data=ScopedToken}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/auth/tokens [post]
+func (h *Handler) IssueScopedToken(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req scopedTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if errs := validate.Struct(req); errs != nil {
+		response.ValidationFailed(w, errs)
+		return
+	}
+	ttl := time.Hour
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	tok, err := h.svc.IssueScopedToken(r.Context(), userID, req.Scopes, ttl)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidScopes):
+			response.BadRequest(w, "scopes must be a non-empty list of known scopes")
+		case errors.Is(err, ErrInvalidTTL):
+			response.BadRequest(w, "ttlSeconds must be between 1 and 604800")
+		default:
+			response.InternalError(w)
+		}
+		return
+	}
+	response.Created(w, tok)
+}
+
+type impersonateRequest struct {
+	Reason     string `json:"reason"     example:"ticket #4812: balance looks wrong" validate:"required,max=500"`
+	TTLSeconds int    `json:"ttlSeconds" example:"900"                               validate:"omitempty,min=1,max=3600"`
+}
+
+// Impersonate godoc
+//
+//	@Summary		Impersonate user
+//	@Description	Mint a token that acts as the user, so support can see what they see. The token names the admin in its act claim; every request made with it is recorded in the audit log, and it is refused on money-movement endpoints, on admin routes and when minting further tokens, and stops working if the admin loses the admin role. Admin accounts cannot be impersonated. ttlSeconds defaults to 900 and may be at most 3600. Admin only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"User ID"
+//	@Param			request	body		impersonateRequest	true	"Reason and lifetime"
+//	@Success		201		{object}	response.Envelope{data=ImpersonationToken}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/users/{id}/impersonate [post]
+func (h *Handler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || adminID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req impersonateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if errs := validate.Struct(req); errs != nil {
+		response.ValidationFailed(w, errs)
+		return
+	}
+	ttl := 15 * time.Minute
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+
+	tok, err := h.svc.Impersonate(r.Context(), adminID, chi.URLParam(r, "id"), req.Reason, ttl)
+	if err != nil {
+		switch {
+		case errors.Is(err, user.ErrNotFound):
+			response.NotFound(w, "user not found")
+		case errors.Is(err, ErrNotAdmin):
+			response.Forbidden(w, "insufficient permissions")
+		case errors.Is(err, ErrCannotImpersonate):
+			response.Forbidden(w, "admin accounts cannot be impersonated")
+		case errors.Is(err, ErrInvalidImpersonationTTL):
+			response.BadRequest(w, "ttlSeconds must be between 1 and 3600")
+		default:
+			response.InternalError(w)
+		}
+		return
+	}
+	response.Created(w, tok)
+}
+
+// Logout godoc
+//
+//	@Summary		Log out
+//	@Description	Revoke the token used for this request, so it is rejected from now on even though it has not expired. Other tokens of the user stay valid.
+//	@Tags			auth
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope
+//	@Failure		400	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		503	{object}	response.Envelope
+//	@Router			/auth/logout [post]
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	tokenID, _ := r.Context().Value(middleware.TokenIDKey).(string)
+	expiresAt, _ := r.Context().Value(middleware.TokenExpiryKey).(time.Time)
+	if tokenID == "" || expiresAt.IsZero() {
+		response.BadRequest(w, "this token cannot be revoked; sign in again for a new one")
+		return
+	}
+
+	if err := h.svc.Revoke(r.Context(), userID, tokenID, expiresAt); err != nil {
+		if errors.Is(err, ErrRevocationUnavailable) {
+			response.Error(w, http.StatusServiceUnavailable, "logout is temporarily unavailable, try again later")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
@@ -8,11 +8,12 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/radif/service/internal/db"
 )
 
-// otp is the internal representation of a one-time password record.
-type otp struct {
+// OTP is a one-time password record.
+type OTP struct {
 	ID        string
 	Phone     string
 	Code      string
@@ -21,21 +22,37 @@ type otp struct {
 	CreatedAt time.Time
 }
 
+var _ Repo = (*Repository)(nil)
+
 // Repository handles OTP persistence.
 type Repository struct {
-	db *pgxpool.Pool
+	db db.Querier
 }
 
 // NewRepository creates a new auth Repository.
-func NewRepository(db *pgxpool.Pool) *Repository {
-	return &Repository{db: db}
+func NewRepository(q db.Querier) *Repository {
+	return &Repository{db: q}
+}
+
+// WithTx returns a copy of the repository that runs its queries on tx, so
+// they commit or roll back together with the caller's other work.
+func (r *Repository) WithTx(tx pgx.Tx) Repo {
+	return &Repository{db: tx}
+}
+
+// QueuedOTP is an OTP message waiting in the SMS retry queue.
+type QueuedOTP struct {
+	ID    string
+	Phone string
+	Code  string
 }
 
-// UpsertOTP invalidates all active OTPs for the phone and inserts a fresh one.
-func (r *Repository) UpsertOTP(ctx context.Context, phone, code string, expiresAt time.Time) error {
+// UpsertOTP invalidates all active OTPs for the phone, inserts a fresh one and
+// returns its ID.
+func (r *Repository) UpsertOTP(ctx context.Context, phone, code string, expiresAt time.Time) (string, error) {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("begin tx: %w", err)
+		return "", fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback(ctx) //nolint:errcheck
 
@@ -45,23 +62,27 @@ func (r *Repository) UpsertOTP(ctx context.Context, phone, code string, expiresA
 		phone,
 	)
 	if err != nil {
-		return fmt.Errorf("invalidate old otps: %w", err)
+		return "", fmt.Errorf("invalidate old otps: %w", err)
 	}
 
-	_, err = tx.Exec(ctx,
-		`INSERT INTO otps (phone, code, expires_at) VALUES ($1, $2, $3)`,
+	var id string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO otps (phone, code, expires_at) VALUES ($1, $2, $3) RETURNING id`,
 		phone, code, expiresAt,
-	)
+	).Scan(&id)
 	if err != nil {
-		return fmt.Errorf("insert otp: %w", err)
+		return "", fmt.Errorf("insert otp: %w", err)
 	}
 
-	return tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("commit: %w", err)
+	}
+	return id, nil
 }
 
 // GetActiveOTP returns the most recent unused, non-expired OTP for the phone.
-func (r *Repository) GetActiveOTP(ctx context.Context, phone string) (*otp, error) {
-	o := &otp{}
+func (r *Repository) GetActiveOTP(ctx context.Context, phone string) (*OTP, error) {
+	o := &OTP{}
 	err := r.db.QueryRow(ctx,
 		`SELECT id, phone, code, expires_at, used_at, created_at
 		 FROM otps
@@ -79,21 +100,114 @@ func (r *Repository) GetActiveOTP(ctx context.Context, phone string) (*otp, erro
 	return o, nil
 }
 
-// MarkOTPUsed marks the OTP record as consumed.
+// MarkOTPUsed marks the OTP record as consumed. It fails with ErrOTPNotFound
+// when the OTP was already used or replaced, so a code is consumed once even
+// under concurrent requests.
 func (r *Repository) MarkOTPUsed(ctx context.Context, id string) error {
-	_, err := r.db.Exec(ctx,
-		`UPDATE otps SET used_at = NOW() WHERE id = $1`,
+	tag, err := r.db.Exec(ctx,
+		`UPDATE otps SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`,
 		id,
 	)
-	return err
+	if err != nil {
+		return fmt.Errorf("mark otp used: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrOTPNotFound
+	}
+	return nil
 }
 
-// UserExists returns true if a user with the given phone already exists.
+// UserExists returns true if a live user with the given phone exists. The
+// phone of a deleted account can register again.
 func (r *Repository) UserExists(ctx context.Context, phone string) (bool, error) {
 	var exists bool
 	err := r.db.QueryRow(ctx,
-		`SELECT EXISTS(SELECT 1 FROM users WHERE phone = $1)`,
+		`SELECT EXISTS(SELECT 1 FROM users WHERE phone = $1 AND deleted_at IS NULL)`,
 		phone,
 	).Scan(&exists)
 	return exists, err
 }
+
+// EnqueueOTP queues an OTP for SMS delivery until expiresAt.
+func (r *Repository) EnqueueOTP(ctx context.Context, otpID string, expiresAt time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO otp_sms_queue (otp_id, expires_at) VALUES ($1, $2)`,
+		otpID, expiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("enqueue otp: %w", err)
+	}
+	return nil
+}
+
+// CancelStaleOTPs cancels queued messages that expired or whose OTP was used,
+// replaced or expired, and returns how many were cancelled.
+func (r *Repository) CancelStaleOTPs(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE otp_sms_queue q SET status = 'cancelled'
+		 FROM otps o
+		 WHERE q.otp_id = o.id AND q.status = 'pending'
+		   AND (q.expires_at <= NOW() OR o.used_at IS NOT NULL OR o.expires_at <= NOW())`,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("cancel stale otps: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ClaimQueuedOTPs locks up to limit due messages and pushes their next attempt
+// back by retryAfter, so a crashed worker's claim is retried automatically.
+func (r *Repository) ClaimQueuedOTPs(ctx context.Context, limit int, retryAfter time.Duration) ([]*QueuedOTP, error) {
+	rows, err := r.db.Query(ctx,
+		`UPDATE otp_sms_queue q SET
+		     attempts        = q.attempts + 1,
+		     next_attempt_at = NOW() + $2::interval
+		 FROM otps o
+		 WHERE q.otp_id = o.id
+		   AND q.id IN (
+		       SELECT id FROM otp_sms_queue
+		       WHERE status = 'pending' AND next_attempt_at <= NOW() AND expires_at > NOW()
+		       ORDER BY next_attempt_at
+		       LIMIT $1
+		       FOR UPDATE SKIP LOCKED
+		   )
+		 RETURNING q.id, o.phone, o.code`,
+		limit, retryAfter,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim queued otps: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*QueuedOTP
+	for rows.Next() {
+		q := &QueuedOTP{}
+		if err := rows.Scan(&q.ID, &q.Phone, &q.Code); err != nil {
+			return nil, fmt.Errorf("scan queued otp: %w", err)
+		}
+		out = append(out, q)
+	}
+	return out, rows.Err()
+}
+
+// MarkOTPSent marks a queued message as delivered.
+func (r *Repository) MarkOTPSent(ctx context.Context, id string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE otp_sms_queue SET status = 'sent' WHERE id = $1`,
+		id,
+	)
+	if err != nil {
+		return fmt.Errorf("mark otp sent: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredOTPs deletes OTPs that expired before the given time, with
+// their queued messages, and returns how many were deleted.
+func (r *Repository) DeleteExpiredOTPs(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM otps WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired otps: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
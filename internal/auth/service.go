@@ -3,80 +3,255 @@ package auth
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"math/big"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/radif/service/internal/audit"
+	"github.com/radif/service/internal/cache"
 	"github.com/radif/service/internal/config"
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/events"
+	"github.com/radif/service/internal/metrics"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/notification"
+	"github.com/radif/service/internal/referral"
+	"github.com/radif/service/internal/sms"
 	"github.com/radif/service/internal/user"
 )
 
 const otpTTL = 2 * time.Minute
 
+// otpQueueTTL bounds how long an OTP is retried while SMS providers are down.
+// A code that arrives with little of its lifetime left only frustrates the
+// user, so queued messages give up well before the OTP itself expires.
+const otpQueueTTL = 90 * time.Second
+
+// Per-phone OTP limits. The per-IP limit on the auth routes does not stop a
+// botnet from flooding one number with SMS or guessing its code from many
+// addresses, so sends and wrong guesses are also counted per phone.
+const (
+	maxOTPSends      = 5
+	otpSendWindow    = time.Hour
+	maxOTPAttempts   = 5
+	otpAttemptWindow = 10 * time.Minute
+)
+
+// degradedAlertInterval throttles the ops alert raised while SMS is down.
+const degradedAlertInterval = 5 * time.Minute
+
 // ErrOTPNotFound is returned when no active OTP exists for the phone.
 var ErrOTPNotFound = errors.New("OTP not found or expired")
 
+// ErrTooManyOTPs is returned when a phone has been sent too many OTPs recently.
+var ErrTooManyOTPs = errors.New("too many OTP requests")
+
+// ErrTooManyAttempts is returned when too many wrong codes were entered for a phone.
+var ErrTooManyAttempts = errors.New("too many OTP attempts")
+
+// ErrRevocationUnavailable is returned when a token cannot be revoked because
+// the revocation list is unreachable.
+var ErrRevocationUnavailable = errors.New("token revocation unavailable")
+
+// ErrInvalidScopes is returned when a limited token is requested with no or unknown scopes.
+var ErrInvalidScopes = errors.New("invalid scopes")
+
+// ErrInvalidTTL is returned when a limited token's lifetime is out of range.
+var ErrInvalidTTL = errors.New("invalid token lifetime")
+
+// ErrNotAdmin is returned when someone without the admin role tries to
+// impersonate a user.
+var ErrNotAdmin = errors.New("not an admin")
+
+// ErrCannotImpersonate is returned when an admin tries to impersonate another admin.
+var ErrCannotImpersonate = errors.New("admin accounts cannot be impersonated")
+
+// ErrInvalidImpersonationTTL is returned when an impersonation token's lifetime is out of range.
+var ErrInvalidImpersonationTTL = errors.New("invalid impersonation lifetime")
+
+// ErrInvalidRegistrationToken is returned when Register is called without a
+// valid registration token for the phone.
+var ErrInvalidRegistrationToken = errors.New("invalid registration token")
+
+// registrationTTL is how long a new user has after verifying their phone to
+// finish signing up.
+const registrationTTL = 10 * time.Minute
+
+// registrationPurpose is the purpose claim of registration tokens.
+const registrationPurpose = "register"
+
+// sessionTTL is the lifetime of full-access tokens issued at login.
+const sessionTTL = 30 * 24 * time.Hour
+
+// otpRetention is how long expired OTPs are kept before the cron job
+// deletes them, so support can still see recent sign-in attempts.
+const otpRetention = 24 * time.Hour
+
+// maxScopedTTL caps limited tokens; they are meant for widgets and delegations,
+// not long-lived sessions.
+const maxScopedTTL = 7 * 24 * time.Hour
+
+// ScopedToken is a limited-capability token.
+type ScopedToken struct {
+	Token     string    `json:"token"     example:"eyJhbGci..."`
+	Scopes    []string  `json:"scopes"    example:"profile:read,groups"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// maxImpersonationTTL caps impersonation tokens; a support session that needs
+// longer should start over and leave a fresh audit entry.
+const maxImpersonationTTL = time.Hour
+
+// ImpersonationToken lets an admin act as a user. It carries the admin in
+// its act claim, so every request made with it is logged against them, and
+// it is refused on money-movement endpoints.
+type ImpersonationToken struct {
+	Token     string    `json:"token"     example:"eyJhbGci..."`
+	UserID    string    `json:"userId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
 // ErrInvalidOTP is returned when the provided code does not match.
 var ErrInvalidOTP = errors.New("invalid OTP code")
 
+// SendResult reports how an OTP was dispatched.
+type SendResult struct {
+	// DeliveryDelayed is true when every SMS provider was down and the code
+	// was queued for retry. It may arrive late, or not at all.
+	DeliveryDelayed bool
+}
+
 // VerifyResult holds the result of a successful OTP verification.
 type VerifyResult struct {
 	IsNewUser bool
 	Token     string
 	UserID    string
+	// RegistrationToken is set for new users. It proves to Register that
+	// the phone was verified.
+	RegistrationToken string
+}
+
+//go:generate mockgen -source=service.go -destination=mock_repo_test.go -package=auth -self_package=github.com/radif/service/internal/auth
+//go:generate mockgen -source=../user/service.go -destination=mock_user_repo_test.go -package=auth -mock_names=Repo=MockUserRepo
+
+// Repo is the OTP persistence Service and Worker depend on. It is satisfied
+// by *Repository; tests use the generated MockRepo.
+type Repo interface {
+	UpsertOTP(ctx context.Context, phone, code string, expiresAt time.Time) (string, error)
+	GetActiveOTP(ctx context.Context, phone string) (*OTP, error)
+	MarkOTPUsed(ctx context.Context, id string) error
+	UserExists(ctx context.Context, phone string) (bool, error)
+	EnqueueOTP(ctx context.Context, otpID string, expiresAt time.Time) error
+	CancelStaleOTPs(ctx context.Context) (int64, error)
+	ClaimQueuedOTPs(ctx context.Context, limit int, retryAfter time.Duration) ([]*QueuedOTP, error)
+	MarkOTPSent(ctx context.Context, id string) error
+	DeleteExpiredOTPs(ctx context.Context, before time.Time) (int64, error)
+	WithTx(tx pgx.Tx) Repo
 }
 
 // Service contains the business logic for phone-based authentication.
 type Service struct {
-	repo    *Repository
-	userSvc *user.Service
-	cfg     *config.Config
+	repo      Repo
+	txm       db.Transactor
+	userSvc   *user.Service
+	notifier  *notification.Service
+	referrals *referral.Service
+	sms       *sms.Dispatcher
+	cache     *cache.Cache
+	metrics   *metrics.Metrics
+	events    *events.Outbox
+	audit     *audit.Log
+	cfg       *config.Config
+
+	// lastDegradedAlert is the Unix time of the last SMS-down ops alert.
+	lastDegradedAlert atomic.Int64
 }
 
-// NewService creates a new auth Service.
-func NewService(repo *Repository, userSvc *user.Service, cfg *config.Config) *Service {
-	return &Service{repo: repo, userSvc: userSvc, cfg: cfg}
+// NewService creates a new auth Service. c holds the per-phone OTP counters
+// and the token revocation list; with a nil or unreachable cache OTPs are
+// limited per IP only and tokens cannot be revoked. outbox may be nil, in
+// which case no sign-up or sign-in events are published. Impersonations are
+// recorded in auditLog. Sign-ups consume their OTP and create the user in one
+// transaction of txm.
+func NewService(repo Repo, txm db.Transactor, userSvc *user.Service, notifier *notification.Service, referrals *referral.Service, sender *sms.Dispatcher, c *cache.Cache, m *metrics.Metrics, outbox *events.Outbox, auditLog *audit.Log, cfg *config.Config) *Service {
+	return &Service{repo: repo, txm: txm, userSvc: userSvc, notifier: notifier, referrals: referrals, sms: sender, cache: c, metrics: m, events: outbox, audit: auditLog, cfg: cfg}
 }
 
-// SendOTP generates a 5-digit OTP, persists it, and "sends" it (logged in dev).
-func (s *Service) SendOTP(ctx context.Context, phone string) error {
+// SendOTP generates a 5-digit OTP, persists it, and sends it by SMS (it is
+// also logged in dev). When every SMS provider is down the message is queued
+// for retry and the result reports DeliveryDelayed instead of failing.
+func (s *Service) SendOTP(ctx context.Context, phone string) (*SendResult, error) {
+	res, outcome, err := s.sendOTP(ctx, phone)
+	s.metrics.OTPSend(outcome)
+	return res, err
+}
+
+// sendOTP implements SendOTP, also returning the metrics.OTP* outcome.
+func (s *Service) sendOTP(ctx context.Context, phone string) (*SendResult, string, error) {
+	if s.overLimit(ctx, "otp:send:"+phone, maxOTPSends, otpSendWindow) {
+		return nil, metrics.OTPRateLimited, ErrTooManyOTPs
+	}
+
 	code, err := generateOTP()
 	if err != nil {
-		return fmt.Errorf("generate otp: %w", err)
+		return nil, metrics.OTPFailed, fmt.Errorf("generate otp: %w", err)
 	}
 
 	expiresAt := time.Now().Add(otpTTL)
-	if err := s.repo.UpsertOTP(ctx, phone, code, expiresAt); err != nil {
-		return fmt.Errorf("store otp: %w", err)
+	otpID, err := s.repo.UpsertOTP(ctx, phone, code, expiresAt)
+	if err != nil {
+		return nil, metrics.OTPFailed, fmt.Errorf("store otp: %w", err)
 	}
 
 	if !s.cfg.IsProduction() {
-		log.Printf("[OTP] phone=%s code=%s", phone, code)
+		slog.InfoContext(ctx, "auth: otp issued", "phone", phone, "code", code)
 	} else {
-		log.Printf("[OTP] sent to phone=%s", phone)
-		// TODO: integrate SMS provider (e.g. Kavenegar, SMS.ir)
+		slog.InfoContext(ctx, "auth: otp issued", "phone", phone)
 	}
 
-	return nil
-}
+	if !s.sms.Enabled() {
+		return &SendResult{}, metrics.OTPLogged, nil
+	}
 
-// VerifyOTP validates the OTP code and returns user status.
-// For existing users it also issues a JWT token immediately.
-func (s *Service) VerifyOTP(ctx context.Context, phone, code string) (*VerifyResult, error) {
-	activeOTP, err := s.repo.GetActiveOTP(ctx, phone)
-	if err != nil {
-		return nil, ErrInvalidOTP
+	if err := s.sms.Send(ctx, phone, otpText(code)); err != nil {
+		s.alertDegraded(err)
+		if err := s.repo.EnqueueOTP(ctx, otpID, time.Now().Add(otpQueueTTL)); err != nil {
+			return nil, metrics.OTPFailed, fmt.Errorf("queue otp: %w", err)
+		}
+		return &SendResult{DeliveryDelayed: true}, metrics.OTPQueued, nil
 	}
 
-	if activeOTP.Code != code {
-		return nil, ErrInvalidOTP
+	return &SendResult{}, metrics.OTPSent, nil
+}
+
+// alertDegraded raises an ops alert that OTP delivery is degraded, at most
+// once per degradedAlertInterval.
+func (s *Service) alertDegraded(cause error) {
+	now := time.Now().Unix()
+	last := s.lastDegradedAlert.Load()
+	if now-last < int64(degradedAlertInterval/time.Second) || !s.lastDegradedAlert.CompareAndSwap(last, now) {
+		return
 	}
+	slog.Error("auth: otp delivery degraded, queueing messages for retry", "alert", true, "err", cause)
+}
 
-	if err := s.repo.MarkOTPUsed(ctx, activeOTP.ID); err != nil {
-		return nil, fmt.Errorf("mark otp used: %w", err)
+// VerifyOTP validates the OTP code and returns user status.
+// For existing users it consumes the OTP and issues a JWT token immediately.
+// New users get a registration token instead, and the OTP is consumed when
+// Register creates their account.
+func (s *Service) VerifyOTP(ctx context.Context, phone, code string) (*VerifyResult, error) {
+	activeOTP, err := s.checkOTP(ctx, phone, code)
+	if err != nil {
+		return nil, err
 	}
 
 	exists, err := s.repo.UserExists(ctx, phone)
@@ -86,41 +261,153 @@ func (s *Service) VerifyOTP(ctx context.Context, phone, code string) (*VerifyRes
 
 	result := &VerifyResult{IsNewUser: !exists}
 
-	if exists {
-		u, err := s.userSvc.GetByPhone(ctx, phone)
+	if !exists {
+		token, err := s.issueRegistrationToken(phone, activeOTP.ID)
 		if err != nil {
-			return nil, fmt.Errorf("get existing user: %w", err)
+			return nil, fmt.Errorf("issue registration token: %w", err)
 		}
-		token, err := s.issueToken(u.ID, u.Phone, u.AccountType)
-		if err != nil {
-			return nil, fmt.Errorf("issue token: %w", err)
-		}
-		result.Token = token
-		result.UserID = u.ID
+		result.RegistrationToken = token
+		s.cache.Delete(ctx, otpAttemptsKey(phone))
+		return result, nil
+	}
+
+	if err := s.consumeOTP(ctx, phone, activeOTP.ID); err != nil {
+		return nil, err
+	}
+
+	u, err := s.userSvc.GetByPhone(ctx, phone)
+	if err != nil {
+		return nil, fmt.Errorf("get existing user: %w", err)
+	}
+	token, err := s.issueToken(u)
+	if err != nil {
+		return nil, fmt.Errorf("issue token: %w", err)
+	}
+	result.Token = token
+	result.UserID = u.ID
+	s.emit(ctx, events.AuthLoggedInV1{UserID: u.ID, Method: "otp"})
+
+	// A sign-in the user doesn't recognise is the first sign of a
+	// SIM-swap or leaked OTP; failing to record it must not block login.
+	if _, err := s.notifier.Notify(ctx, u.ID, notification.Message{
+		Type:  notification.TypeNewLogin,
+		Title: "ورود جدید به حساب",
+		Body:  "یک ورود جدید به حساب ردیف شما ثبت شد. اگر این شما نبودید، با پشتیبانی تماس بگیرید.",
+	}); err != nil {
+		slog.ErrorContext(ctx, "auth: notify new login failed", "user_id", u.ID, "err", err)
 	}
 
 	return result, nil
 }
 
-// Register creates a new user account and issues a JWT token.
-// If the user already exists (idempotent re-registration), a new token is issued.
-func (s *Service) Register(ctx context.Context, phone, accountType string) (string, *user.User, error) {
+// ConfirmOTP consumes the active OTP for phone if code matches, without
+// signing anyone in. Flows that need fresh proof of phone possession from a
+// signed-in user (such as accepting a family link) call it.
+// A phone gets maxOTPAttempts tries per otpAttemptWindow; a correct code
+// resets the count.
+func (s *Service) ConfirmOTP(ctx context.Context, phone, code string) error {
+	activeOTP, err := s.checkOTP(ctx, phone, code)
+	if err != nil {
+		return err
+	}
+	return s.consumeOTP(ctx, phone, activeOTP.ID)
+}
+
+// checkOTP returns the active OTP for phone if code matches, counting the
+// attempt against the phone's limit. It does not consume the OTP.
+func (s *Service) checkOTP(ctx context.Context, phone, code string) (*OTP, error) {
+	if s.overLimit(ctx, otpAttemptsKey(phone), maxOTPAttempts, otpAttemptWindow) {
+		return nil, ErrTooManyAttempts
+	}
+
+	activeOTP, err := s.repo.GetActiveOTP(ctx, phone)
+	if err != nil || activeOTP.Code != code {
+		return nil, ErrInvalidOTP
+	}
+	return activeOTP, nil
+}
+
+// consumeOTP marks the OTP with id used and resets phone's attempt count. A
+// concurrent request that consumed it first makes it fail with ErrInvalidOTP.
+func (s *Service) consumeOTP(ctx context.Context, phone, id string) error {
+	err := s.repo.MarkOTPUsed(ctx, id)
+	if errors.Is(err, ErrOTPNotFound) {
+		return ErrInvalidOTP
+	}
+	if err != nil {
+		return fmt.Errorf("mark otp used: %w", err)
+	}
+	s.cache.Delete(ctx, otpAttemptsKey(phone))
+	return nil
+}
+
+// overLimit counts an event at key and reports whether more than limit have
+// happened within window. Without the cache nothing is counted and it
+// reports false; config.Validate requires the cache in production.
+func (s *Service) overLimit(ctx context.Context, key string, limit int64, window time.Duration) bool {
+	n, err := s.cache.Incr(ctx, key, window)
+	return err == nil && n > limit
+}
+
+// Register creates a new user account and issues a JWT token. It requires
+// the registration token VerifyOTP issued for phone, failing with
+// ErrInvalidRegistrationToken otherwise. The token is single-use: the OTP it
+// was issued for is consumed in the same transaction that creates the user.
+// If the user already exists (idempotent re-registration), a new token is issued
+// and referralCode is ignored; a user can only be referred when they sign up.
+func (s *Service) Register(ctx context.Context, registrationToken, phone, accountType, referralCode string) (string, *user.User, error) {
+	verified, otpID, err := s.parseRegistrationToken(registrationToken)
+	if err != nil || verified != phone {
+		return "", nil, ErrInvalidRegistrationToken
+	}
+
 	// Idempotent: return existing user if already registered.
 	existing, err := s.userSvc.GetByPhone(ctx, phone)
 	if err == nil {
-		token, err := s.issueToken(existing.ID, existing.Phone, existing.AccountType)
+		if err := s.markRegistrationUsed(ctx, s.repo, otpID); err != nil {
+			return "", nil, err
+		}
+		token, err := s.issueToken(existing)
 		if err != nil {
 			return "", nil, fmt.Errorf("issue token for existing user: %w", err)
 		}
+		s.emit(ctx, events.AuthLoggedInV1{UserID: existing.ID, Method: "otp"})
 		return token, existing, nil
 	}
 
-	u, err := s.userSvc.Create(ctx, phone, accountType)
+	var referrerID string
+	if referralCode != "" {
+		referrerID, err = s.referrals.Resolve(ctx, referralCode)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	var u *user.User
+	err = s.txm.WithTx(ctx, func(tx pgx.Tx) error {
+		if err := s.markRegistrationUsed(ctx, s.repo.WithTx(tx), otpID); err != nil {
+			return err
+		}
+		var err error
+		if u, err = s.userSvc.WithTx(tx).Create(ctx, phone, accountType); err != nil {
+			return fmt.Errorf("create user: %w", err)
+		}
+		registered := events.UserRegisteredV1{UserID: u.ID, AccountType: u.AccountType, Referred: referrerID != ""}
+		return s.events.WithTx(tx).Emit(ctx, registered)
+	})
 	if err != nil {
-		return "", nil, fmt.Errorf("create user: %w", err)
+		return "", nil, err
 	}
 
-	token, err := s.issueToken(u.ID, u.Phone, u.AccountType)
+	// The account already exists at this point; a lost attribution must not
+	// fail the signup.
+	if referrerID != "" {
+		if err := s.referrals.Record(ctx, referrerID, u.ID); err != nil {
+			slog.ErrorContext(ctx, "auth: record referral failed", "user_id", u.ID, "referrer_id", referrerID, "err", err)
+		}
+	}
+
+	token, err := s.issueToken(u)
 	if err != nil {
 		return "", nil, fmt.Errorf("issue token: %w", err)
 	}
@@ -128,17 +415,251 @@ func (s *Service) Register(ctx context.Context, phone, accountType string) (stri
 	return token, u, nil
 }
 
-// issueToken creates a signed JWT for the given user.
-func (s *Service) issueToken(userID, phone, accountType string) (string, error) {
+// markRegistrationUsed consumes the OTP a registration token was issued for
+// through repo, failing with ErrInvalidRegistrationToken when the token was
+// already used or a newer code was requested since.
+func (s *Service) markRegistrationUsed(ctx context.Context, repo Repo, otpID string) error {
+	err := repo.MarkOTPUsed(ctx, otpID)
+	if errors.Is(err, ErrOTPNotFound) {
+		return ErrInvalidRegistrationToken
+	}
+	if err != nil {
+		return fmt.Errorf("mark otp used: %w", err)
+	}
+	return nil
+}
+
+// IssueScopedToken issues a token for userID restricted to scopes and valid
+// for ttl. Callers must already hold a full-access token, so a limited token
+// can never be used to mint a broader one.
+func (s *Service) IssueScopedToken(ctx context.Context, userID string, scopes []string, ttl time.Duration) (*ScopedToken, error) {
+	if len(scopes) == 0 {
+		return nil, ErrInvalidScopes
+	}
+	seen := make(map[string]bool, len(scopes))
+	unique := make([]string, 0, len(scopes))
+	for _, sc := range scopes {
+		if !middleware.KnownScopes[sc] {
+			return nil, ErrInvalidScopes
+		}
+		if !seen[sc] {
+			seen[sc] = true
+			unique = append(unique, sc)
+		}
+	}
+	if ttl <= 0 || ttl > maxScopedTTL {
+		return nil, ErrInvalidTTL
+	}
+
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	token, err := s.signToken(u, strings.Join(unique, " "), expiresAt, "")
+	if err != nil {
+		return nil, fmt.Errorf("issue token: %w", err)
+	}
+	return &ScopedToken{Token: token, Scopes: unique, ExpiresAt: expiresAt}, nil
+}
+
+// Impersonate issues adminID a token acting as userID for ttl, recording
+// why in the audit log; if the entry cannot be written, no token is issued.
+// adminID must currently hold the admin role, even though the route already
+// checks it. Other admins cannot be impersonated, so the token never carries
+// staff privileges.
+func (s *Service) Impersonate(ctx context.Context, adminID, userID, reason string, ttl time.Duration) (*ImpersonationToken, error) {
+	if ttl <= 0 || ttl > maxImpersonationTTL {
+		return nil, ErrInvalidImpersonationTTL
+	}
+
+	role, err := s.userSvc.Role(ctx, adminID)
+	if err != nil {
+		return nil, fmt.Errorf("get admin role: %w", err)
+	}
+	if role != middleware.RoleAdmin {
+		return nil, ErrNotAdmin
+	}
+
+	u, err := s.userSvc.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	if u.Role == middleware.RoleAdmin {
+		return nil, ErrCannotImpersonate
+	}
+
+	// The audit entry is written first, in a transaction that commits only
+	// once the token is signed: no token is handed out unrecorded.
+	expiresAt := time.Now().Add(ttl)
+	var token string
+	err = s.txm.WithTx(ctx, func(tx pgx.Tx) error {
+		err := s.audit.WithTx(tx).Write(ctx, audit.ActionImpersonationStarted, audit.TargetUser, userID, map[string]any{
+			"reason":    reason,
+			"expiresAt": expiresAt,
+		})
+		if err != nil {
+			return fmt.Errorf("record impersonation: %w", err)
+		}
+		if token, err = s.signToken(u, middleware.ScopeAll, expiresAt, adminID); err != nil {
+			return fmt.Errorf("issue token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ImpersonationToken{Token: token, UserID: u.ID, ExpiresAt: expiresAt}, nil
+}
+
+// issueToken creates a full-access session JWT for the given user.
+func (s *Service) issueToken(u *user.User) (string, error) {
+	return s.signToken(u, middleware.ScopeAll, time.Now().Add(sessionTTL), "")
+}
+
+// signToken creates a signed JWT carrying the user's claims and the given
+// space-delimited scope. The role is deliberately left out: it is looked up
+// per request, so granting or revoking admin takes effect at once. A
+// non-empty impersonatorID is set as the act claim (RFC 8693), marking the
+// token as an admin acting as the user.
+func (s *Service) signToken(u *user.User, scope string, expiresAt time.Time, impersonatorID string) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", fmt.Errorf("generate token id: %w", err)
+	}
 	claims := jwt.MapClaims{
-		"sub":         userID,
-		"phone":       phone,
-		"accountType": accountType,
+		"jti":         jti,
+		"sub":         u.ID,
+		"phone":       u.Phone,
+		"accountType": u.AccountType,
+		"scope":       scope,
 		"iat":         time.Now().Unix(),
-		"exp":         time.Now().Add(30 * 24 * time.Hour).Unix(),
+		"exp":         expiresAt.Unix(),
+	}
+	if impersonatorID != "" {
+		claims["act"] = map[string]string{"sub": impersonatorID}
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.cfg.JWTSecret))
+	return token.SignedString([]byte(s.cfg.CurrentJWTSecret()))
+}
+
+// issueRegistrationToken creates a token proving that phone was verified
+// with the OTP otpID, valid for registrationTTL. It is signed with a key
+// derived from the JWT secret, so it can never pass for a session token.
+func (s *Service) issueRegistrationToken(phone, otpID string) (string, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return "", fmt.Errorf("generate token id: %w", err)
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"jti":     jti,
+		"phone":   phone,
+		"otp":     otpID,
+		"purpose": registrationPurpose,
+		"iat":     now.Unix(),
+		"exp":     now.Add(registrationTTL).Unix(),
+	})
+	return token.SignedString(registrationKey(s.cfg.CurrentJWTSecret()))
+}
+
+// parseRegistrationToken validates a registration token and returns the
+// phone and OTP it was issued for. Tokens signed before a secret rotation
+// stay valid.
+func (s *Service) parseRegistrationToken(raw string) (phone, otpID string, err error) {
+	token, err := jwt.Parse(raw, func(t *jwt.Token) (interface{}, error) {
+		var set jwt.VerificationKeySet
+		for _, k := range s.cfg.JWTVerificationKeys() {
+			set.Keys = append(set.Keys, registrationKey(k))
+		}
+		return set, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return "", "", err
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	phone, _ = claims["phone"].(string)
+	otpID, _ = claims["otp"].(string)
+	if purpose, _ := claims["purpose"].(string); purpose != registrationPurpose || phone == "" || otpID == "" {
+		return "", "", ErrInvalidRegistrationToken
+	}
+	return phone, otpID, nil
+}
+
+// Revoke adds userID's token with ID jti to the revocation list until it
+// expires.
+func (s *Service) Revoke(ctx context.Context, userID, jti string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	if err := s.cache.SetFlag(ctx, revokedKey(jti), ttl); err != nil {
+		return ErrRevocationUnavailable
+	}
+	s.emit(ctx, events.AuthLoggedOutV1{UserID: userID, TokenID: jti})
+	return nil
+}
+
+// IsRevoked reports whether the token with ID jti has been revoked; it
+// implements middleware.RevocationList. While the list is unreachable it
+// reports false, so a Redis outage does not sign everyone out. Redis itself
+// is required in production; see config.Validate.
+func (s *Service) IsRevoked(ctx context.Context, jti string) bool {
+	revoked, _ := s.cache.HasFlag(ctx, revokedKey(jti))
+	return revoked
+}
+
+// PurgeExpiredOTPs deletes OTPs that expired more than otpRetention ago.
+// It is run by the cron scheduler.
+func (s *Service) PurgeExpiredOTPs(ctx context.Context) error {
+	n, err := s.repo.DeleteExpiredOTPs(ctx, time.Now().Add(-otpRetention))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		slog.InfoContext(ctx, "auth: purged expired OTPs", "count", n)
+	}
+	return nil
+}
+
+// emit publishes an auth event. The sign-in or sign-up has already happened,
+// so a lost event is logged rather than failing it.
+func (s *Service) emit(ctx context.Context, p events.Payload) {
+	if err := s.events.Emit(ctx, p); err != nil {
+		slog.ErrorContext(ctx, "auth: emit event failed", "event_type", p.EventType(), "user_id", p.SubjectID(), "err", err)
+	}
+}
+
+// registrationKey derives the registration token signing key from a JWT
+// secret.
+func registrationKey(secret string) []byte {
+	sum := sha256.Sum256([]byte("radif registration token:" + secret))
+	return sum[:]
+}
+
+// otpAttemptsKey is the cache key counting wrong codes entered for phone.
+func otpAttemptsKey(phone string) string {
+	return "otp:attempts:" + phone
+}
+
+// revokedKey is the cache key marking a token as revoked.
+func revokedKey(jti string) string {
+	return "revoked:" + jti
+}
+
+// newTokenID returns a random token ID for the jti claim.
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// otpText is the SMS body carrying an OTP code.
+func otpText(code string) string {
+	return fmt.Sprintf("کد ورود ردیف: %s\nاین کد را در اختیار دیگران قرار ندهید.", code)
 }
 
 // generateOTP generates a cryptographically secure 5-digit code.
@@ -0,0 +1,213 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/mock/gomock"
+
+	"github.com/radif/service/internal/config"
+	"github.com/radif/service/internal/metrics"
+	"github.com/radif/service/internal/sms"
+	"github.com/radif/service/internal/user"
+)
+
+const testPhone = "09121234567"
+
+// fakeTx runs transactional work directly, without a database. Mocks
+// expect WithTx(nil) and return themselves.
+type fakeTx struct {
+	calls int
+}
+
+func (f *fakeTx) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	f.calls++
+	return fn(nil)
+}
+
+// newTestService returns a Service on mocks, with no cache, SMS providers,
+// notifier or event bus.
+func newTestService(t *testing.T) (*Service, *MockRepo, *MockUserRepo, *fakeTx) {
+	t.Helper()
+	ctrl := gomock.NewController(t)
+	repo := NewMockRepo(ctrl)
+	users := NewMockUserRepo(ctrl)
+	txm := &fakeTx{}
+	cfg := &config.Config{AppEnv: "test", JWTSecret: "test-secret"}
+	svc := NewService(repo, txm, user.NewService(users, txm, nil, nil, nil), nil, nil, sms.NewDispatcher(), nil, metrics.New(), nil, nil, cfg)
+	return svc, repo, users, txm
+}
+
+func TestSendOTPStoresCode(t *testing.T) {
+	svc, repo, _, _ := newTestService(t)
+	ctx := context.Background()
+
+	repo.EXPECT().UpsertOTP(ctx, testPhone, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _, code string, expiresAt time.Time) (string, error) {
+			if !regexp.MustCompile(`^\d{5}$`).MatchString(code) {
+				t.Errorf("code = %q, want 5 digits", code)
+			}
+			if ttl := time.Until(expiresAt); ttl <= 0 || ttl > otpTTL {
+				t.Errorf("expires in %v, want within %v", ttl, otpTTL)
+			}
+			return "otp-1", nil
+		})
+
+	res, err := svc.SendOTP(ctx, testPhone)
+	if err != nil {
+		t.Fatalf("SendOTP: %v", err)
+	}
+	if res.DeliveryDelayed {
+		t.Error("DeliveryDelayed = true with SMS disabled")
+	}
+}
+
+func TestSendOTPStoreFails(t *testing.T) {
+	svc, repo, _, _ := newTestService(t)
+	ctx := context.Background()
+	dbErr := errors.New("connection refused")
+
+	repo.EXPECT().UpsertOTP(ctx, testPhone, gomock.Any(), gomock.Any()).Return("", dbErr)
+
+	if _, err := svc.SendOTP(ctx, testPhone); !errors.Is(err, dbErr) {
+		t.Fatalf("SendOTP error = %v, want %v", err, dbErr)
+	}
+}
+
+func TestVerifyOTPWrongCode(t *testing.T) {
+	svc, repo, _, _ := newTestService(t)
+	ctx := context.Background()
+
+	repo.EXPECT().GetActiveOTP(ctx, testPhone).Return(&OTP{ID: "otp-1", Phone: testPhone, Code: "12345"}, nil)
+
+	if _, err := svc.VerifyOTP(ctx, testPhone, "54321"); !errors.Is(err, ErrInvalidOTP) {
+		t.Fatalf("VerifyOTP error = %v, want ErrInvalidOTP", err)
+	}
+}
+
+func TestVerifyOTPNewUser(t *testing.T) {
+	svc, repo, _, _ := newTestService(t)
+	ctx := context.Background()
+
+	// The OTP is left unused: Register consumes it.
+	repo.EXPECT().GetActiveOTP(ctx, testPhone).Return(&OTP{ID: "otp-1", Phone: testPhone, Code: "12345"}, nil)
+	repo.EXPECT().UserExists(ctx, testPhone).Return(false, nil)
+
+	res, err := svc.VerifyOTP(ctx, testPhone, "12345")
+	if err != nil {
+		t.Fatalf("VerifyOTP: %v", err)
+	}
+	if !res.IsNewUser || res.Token != "" || res.RegistrationToken == "" {
+		t.Fatalf("result = %+v, want a new user with only a registration token", res)
+	}
+	phone, otpID, err := svc.parseRegistrationToken(res.RegistrationToken)
+	if err != nil || phone != testPhone || otpID != "otp-1" {
+		t.Fatalf("registration token = (%q, %q, %v), want (%q, %q, nil)", phone, otpID, err, testPhone, "otp-1")
+	}
+}
+
+func TestConfirmOTPAlreadyConsumed(t *testing.T) {
+	svc, repo, _, _ := newTestService(t)
+	ctx := context.Background()
+
+	repo.EXPECT().GetActiveOTP(ctx, testPhone).Return(&OTP{ID: "otp-1", Phone: testPhone, Code: "12345"}, nil)
+	repo.EXPECT().MarkOTPUsed(ctx, "otp-1").Return(ErrOTPNotFound)
+
+	if err := svc.ConfirmOTP(ctx, testPhone, "12345"); !errors.Is(err, ErrInvalidOTP) {
+		t.Fatalf("ConfirmOTP error = %v, want ErrInvalidOTP", err)
+	}
+}
+
+func TestRegisterRejectsInvalidToken(t *testing.T) {
+	svc, _, _, _ := newTestService(t)
+	ctx := context.Background()
+
+	otherPhone, err := svc.issueRegistrationToken("09350000000", "otp-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := svc.issueToken(&user.User{ID: "user-1", Phone: testPhone})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"empty":         "",
+		"malformed":     "not-a-token",
+		"other phone":   otherPhone,
+		"session token": session,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := svc.Register(ctx, token, testPhone, "personal", "")
+			if !errors.Is(err, ErrInvalidRegistrationToken) {
+				t.Fatalf("Register error = %v, want ErrInvalidRegistrationToken", err)
+			}
+		})
+	}
+}
+
+func TestRegisterCreatesUserInTransaction(t *testing.T) {
+	svc, repo, users, txm := newTestService(t)
+	ctx := context.Background()
+	token, err := svc.issueRegistrationToken(testPhone, "otp-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users.EXPECT().GetByPhone(ctx, testPhone).Return(nil, user.ErrNotFound)
+	repo.EXPECT().WithTx(gomock.Nil()).Return(repo)
+	repo.EXPECT().MarkOTPUsed(ctx, "otp-1").Return(nil)
+	users.EXPECT().WithTx(gomock.Nil()).Return(users)
+	users.EXPECT().Create(ctx, testPhone, "personal").
+		Return(&user.User{ID: "user-1", Phone: testPhone, AccountType: "personal"}, nil)
+
+	session, u, err := svc.Register(ctx, token, testPhone, "personal", "")
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if session == "" || u.ID != "user-1" {
+		t.Fatalf("Register = (%q, %+v), want a token for user-1", session, u)
+	}
+	if txm.calls != 1 {
+		t.Fatalf("transactions = %d, want 1", txm.calls)
+	}
+}
+
+func TestRegisterTokenIsSingleUse(t *testing.T) {
+	svc, repo, users, _ := newTestService(t)
+	ctx := context.Background()
+	token, err := svc.issueRegistrationToken(testPhone, "otp-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The OTP was consumed by an earlier Register; no user is created.
+	users.EXPECT().GetByPhone(ctx, testPhone).Return(nil, user.ErrNotFound)
+	repo.EXPECT().WithTx(gomock.Nil()).Return(repo)
+	repo.EXPECT().MarkOTPUsed(ctx, "otp-1").Return(ErrOTPNotFound)
+
+	if _, _, err := svc.Register(ctx, token, testPhone, "personal", ""); !errors.Is(err, ErrInvalidRegistrationToken) {
+		t.Fatalf("Register error = %v, want ErrInvalidRegistrationToken", err)
+	}
+}
+
+func TestPurgeExpiredOTPs(t *testing.T) {
+	svc, repo, _, _ := newTestService(t)
+	ctx := context.Background()
+
+	repo.EXPECT().DeleteExpiredOTPs(ctx, gomock.Any()).
+		DoAndReturn(func(_ context.Context, before time.Time) (int64, error) {
+			if age := time.Since(before); age < otpRetention-time.Minute || age > otpRetention+time.Minute {
+				t.Errorf("purging OTPs expired before %v ago, want %v", age, otpRetention)
+			}
+			return 3, nil
+		})
+
+	if err := svc.PurgeExpiredOTPs(ctx); err != nil {
+		t.Fatalf("PurgeExpiredOTPs: %v", err)
+	}
+}
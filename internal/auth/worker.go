@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	otpPollInterval = 3 * time.Second
+	otpClaimBatch   = 50
+	otpRetryAfter   = 10 * time.Second
+)
+
+// Worker retries OTP messages queued while SMS providers were down, and
+// cancels those that can no longer be used.
+type Worker struct {
+	svc *Service
+}
+
+// NewWorker creates a new OTP retry Worker.
+func NewWorker(svc *Service) *Worker {
+	return &Worker{svc: svc}
+}
+
+// Run polls the retry queue until ctx is cancelled. It returns immediately
+// when no SMS provider is configured, since nothing is ever queued then.
+func (w *Worker) Run(ctx context.Context) {
+	if !w.svc.sms.Enabled() {
+		return
+	}
+	slog.Info("otp retry worker started")
+	ticker := time.NewTicker(otpPollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.tick(ctx)
+		select {
+		case <-ctx.Done():
+			slog.Info("otp retry worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick cancels stale messages, then attempts every due one once.
+func (w *Worker) tick(ctx context.Context) {
+	n, err := w.svc.repo.CancelStaleOTPs(ctx)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.ErrorContext(ctx, "otp retry worker: cancel stale failed", "err", err)
+		}
+		return
+	}
+	if n > 0 {
+		slog.InfoContext(ctx, "otp retry worker: cancelled stale queued OTPs", "count", n)
+	}
+
+	queued, err := w.svc.repo.ClaimQueuedOTPs(ctx, otpClaimBatch, otpRetryAfter)
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.ErrorContext(ctx, "otp retry worker: claim failed", "err", err)
+		}
+		return
+	}
+	for _, q := range queued {
+		// On failure the claim already scheduled the next attempt.
+		if err := w.svc.sms.Send(ctx, q.Phone, otpText(q.Code)); err != nil {
+			continue
+		}
+		if err := w.svc.repo.MarkOTPSent(ctx, q.ID); err != nil {
+			slog.ErrorContext(ctx, "otp retry worker: mark sent failed", "otp_id", q.ID, "err", err)
+		}
+	}
+}
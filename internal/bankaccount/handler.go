@@ -0,0 +1,182 @@
+package bankaccount
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for bank account endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new bank account Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type createRequest struct {
+	Kind   string `json:"kind"   example:"card"`
+	Number string `json:"number" example:"6037991234567890"`
+}
+
+// Create godoc
+//
+//	@Summary		Add bank account
+//	@Description	Register a destination bank card (16 digits) or IBAN (IR + 24 digits). The first account becomes the default. Numbers are masked in all responses.
+//	@Tags			bank-accounts
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		createRequest	true	"Card or IBAN"
+//	@Success		201		{object}	response.Envelope{data=Account}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/bank-accounts [post]
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req createRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if req.Kind != KindCard && req.Kind != KindIBAN {
+		response.BadRequest(w, "kind must be one of: card, iban")
+		return
+	}
+
+	a, err := h.svc.Add(r.Context(), userID, req.Kind, req.Number)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidNumber):
+			response.BadRequest(w, "invalid card number or IBAN")
+		case errors.Is(err, ErrLimitReached):
+			response.BadRequest(w, "maximum number of bank accounts reached")
+		case errors.Is(err, ErrAlreadyExists):
+			response.Conflict(w, "bank account already registered")
+		case errors.Is(err, ErrDefaultConflict):
+			response.Conflict(w, "default bank account was changed by another request, please retry")
+		default:
+			response.InternalError(w)
+		}
+		return
+	}
+
+	response.Created(w, a)
+}
+
+// List godoc
+//
+//	@Summary		List bank accounts
+//	@Description	Returns the authenticated user's registered cards and IBANs (masked), default first.
+//	@Tags			bank-accounts
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			fields	query		string	false	"Comma-separated fields to include (e.g. id,maskedNumber,isDefault)"
+//	@Param			casing	query		string	false	"Key casing"	Enums(camel, snake)
+//	@Success		200		{object}	response.Envelope{data=[]Account}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/bank-accounts [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	accounts, err := h.svc.List(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	response.OKProjected(w, r, accounts)
+}
+
+// SetDefault godoc
+//
+//	@Summary		Set default bank account
+//	@Description	Mark a bank account as the default withdrawal destination.
+//	@Tags			bank-accounts
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Bank account ID"
+//	@Success		200	{object}	response.Envelope{data=Account}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		409	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/bank-accounts/{id}/default [post]
+func (h *Handler) SetDefault(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	a, err := h.svc.SetDefault(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNotFound):
+			response.NotFound(w, "bank account not found")
+		case errors.Is(err, ErrDefaultConflict):
+			response.Conflict(w, "default bank account was changed by another request, please retry")
+		default:
+			response.InternalError(w)
+		}
+		return
+	}
+
+	response.OK(w, a)
+}
+
+// Delete godoc
+//
+//	@Summary		Delete bank account
+//	@Description	Remove a registered card or IBAN. If it was the default, the most recently added remaining account becomes the default.
+//	@Tags			bank-accounts
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Bank account ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		409	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/bank-accounts/{id} [delete]
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		switch {
+		case errors.Is(err, ErrNotFound):
+			response.NotFound(w, "bank account not found")
+		case errors.Is(err, ErrDefaultConflict):
+			response.Conflict(w, "default bank account was changed by another request, please retry")
+		default:
+			response.InternalError(w)
+		}
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
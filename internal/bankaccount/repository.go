@@ -0,0 +1,272 @@
+// Package bankaccount manages users' destination bank cards and IBANs.
+package bankaccount
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Kind values for a bank account.
+const (
+	KindCard = "card"
+	KindIBAN = "iban"
+)
+
+// Account is a destination card or IBAN registered by a user.
+// Number is stored encrypted in NumberEnc, with NumberIndex as its blind
+// index; it is never serialized and responses expose MaskedNumber only.
+type Account struct {
+	ID           string    `json:"id"`
+	UserID       string    `json:"-"`
+	Kind         string    `json:"kind"`
+	Number       string    `json:"-"`
+	NumberEnc    []byte    `json:"-"`
+	NumberIndex  []byte    `json:"-"`
+	MaskedNumber string    `json:"maskedNumber"`
+	BankName     *string   `json:"bankName,omitempty"`
+	OwnerName    *string   `json:"ownerName,omitempty"`
+	IsDefault    bool      `json:"isDefault"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// ErrNotFound is returned when a bank account does not exist for the user.
+var ErrNotFound = errors.New("bank account not found")
+
+// ErrAlreadyExists is returned when the user has already registered the number.
+var ErrAlreadyExists = errors.New("bank account already exists")
+
+// ErrLimitReached is returned when the user already has the maximum number of accounts.
+var ErrLimitReached = errors.New("bank account limit reached")
+
+// ErrDefaultConflict is returned when a concurrent request changed the
+// user's default account at the same time.
+var ErrDefaultConflict = errors.New("default bank account changed concurrently")
+
+// defaultIndex is the unique index allowing one default account per user.
+const defaultIndex = "idx_bank_accounts_user_default"
+
+// Repository handles bank account persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new bank account Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const selectCols = `id, user_id, kind, number_enc, number_index, number_masked,
+	bank_name, owner_name, is_default, created_at`
+
+// scanAccount scans a full bank_accounts row into an Account value.
+func scanAccount(row pgx.Row, a *Account) error {
+	return row.Scan(
+		&a.ID, &a.UserID, &a.Kind, &a.NumberEnc, &a.NumberIndex, &a.MaskedNumber,
+		&a.BankName, &a.OwnerName, &a.IsDefault, &a.CreatedAt,
+	)
+}
+
+// Create inserts a new bank account unless the user already has limit
+// accounts, in which case it returns ErrLimitReached. When the user has no
+// default yet, the new account becomes the default.
+func (r *Repository) Create(ctx context.Context, a *Account, limit int) (*Account, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// Concurrent adds for the same user queue on the user row, so the count
+	// below stays accurate until this transaction commits.
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM users WHERE id = $1 FOR NO KEY UPDATE`, a.UserID); err != nil {
+		return nil, fmt.Errorf("lock user: %w", err)
+	}
+	var n int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM bank_accounts WHERE user_id = $1`, a.UserID).Scan(&n); err != nil {
+		return nil, fmt.Errorf("count bank accounts: %w", err)
+	}
+	if n >= limit {
+		return nil, ErrLimitReached
+	}
+
+	out := &Account{}
+	err = scanAccount(tx.QueryRow(ctx,
+		`INSERT INTO bank_accounts
+		     (user_id, kind, number_enc, number_index, number_masked, bank_name, owner_name, is_default)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7,
+		         NOT EXISTS(SELECT 1 FROM bank_accounts WHERE user_id = $1 AND is_default))
+		 RETURNING `+selectCols,
+		a.UserID, a.Kind, a.NumberEnc, a.NumberIndex, a.MaskedNumber, a.BankName, a.OwnerName,
+	), out)
+	if err != nil {
+		return nil, uniqueError(err, ErrAlreadyExists, "create bank account")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return out, nil
+}
+
+// ListByUser returns all bank accounts of the user, default first.
+func (r *Repository) ListByUser(ctx context.Context, userID string) ([]*Account, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+selectCols+` FROM bank_accounts
+		 WHERE user_id = $1
+		 ORDER BY is_default DESC, created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list bank accounts: %w", err)
+	}
+	defer rows.Close()
+
+	accounts := []*Account{}
+	for rows.Next() {
+		a := &Account{}
+		if err := scanAccount(rows, a); err != nil {
+			return nil, fmt.Errorf("scan bank account: %w", err)
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+// Get returns one of the user's bank accounts.
+func (r *Repository) Get(ctx context.Context, userID, id string) (*Account, error) {
+	a := &Account{}
+	err := scanAccount(r.db.QueryRow(ctx,
+		`SELECT `+selectCols+` FROM bank_accounts WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	), a)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get bank account: %w", err)
+	}
+	return a, nil
+}
+
+// GetDefault returns the user's default bank account.
+func (r *Repository) GetDefault(ctx context.Context, userID string) (*Account, error) {
+	a := &Account{}
+	err := scanAccount(r.db.QueryRow(ctx,
+		`SELECT `+selectCols+` FROM bank_accounts WHERE user_id = $1 AND is_default`,
+		userID,
+	), a)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get default bank account: %w", err)
+	}
+	return a, nil
+}
+
+// SetDefault marks the account as the user's default and clears the flag on all others.
+func (r *Repository) SetDefault(ctx context.Context, userID, id string) (*Account, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx,
+		`UPDATE bank_accounts SET is_default = FALSE
+		 WHERE user_id = $1 AND is_default AND id <> $2`,
+		userID, id,
+	)
+	if isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("clear default: %w", err)
+	}
+
+	a := &Account{}
+	err = scanAccount(tx.QueryRow(ctx,
+		`UPDATE bank_accounts SET is_default = TRUE
+		 WHERE user_id = $1 AND id = $2
+		 RETURNING `+selectCols,
+		userID, id,
+	), a)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, uniqueError(err, nil, "set default")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return a, nil
+}
+
+// Delete removes the account. If it was the default, the most recently added
+// remaining account is promoted to default.
+func (r *Repository) Delete(ctx context.Context, userID, id string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var wasDefault bool
+	err = tx.QueryRow(ctx,
+		`DELETE FROM bank_accounts WHERE user_id = $1 AND id = $2 RETURNING is_default`,
+		userID, id,
+	).Scan(&wasDefault)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("delete bank account: %w", err)
+	}
+
+	if wasDefault {
+		_, err = tx.Exec(ctx,
+			`UPDATE bank_accounts SET is_default = TRUE
+			 WHERE id = (
+			     SELECT id FROM bank_accounts WHERE user_id = $1
+			     ORDER BY created_at DESC LIMIT 1
+			 )`,
+			userID,
+		)
+		if err != nil {
+			return uniqueError(err, nil, "promote default")
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// uniqueError maps a unique_violation (code 23505) on the default-account
+// index to ErrDefaultConflict and any other one to other, when set. Other
+// errors are wrapped with action.
+func uniqueError(err, other error, action string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		if pgErr.ConstraintName == defaultIndex {
+			return ErrDefaultConflict
+		}
+		if other != nil {
+			return other
+		}
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
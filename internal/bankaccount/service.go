@@ -0,0 +1,224 @@
+package bankaccount
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strings"
+
+	"github.com/radif/service/internal/secretbox"
+)
+
+const maxAccountsPerUser = 10
+
+// ErrInvalidNumber is returned when a card number or IBAN fails validation.
+var ErrInvalidNumber = errors.New("invalid card number or IBAN")
+
+// OwnerInquirer looks up the registered owner name of a card or IBAN
+// (e.g. via a bank inquiry provider). It is optional; when nil, owner names
+// are not resolved.
+type OwnerInquirer interface {
+	InquireOwner(ctx context.Context, kind, number string) (string, error)
+}
+
+// cardBINs maps the first six digits of Iranian debit cards to the issuing bank.
+var cardBINs = map[string]string{
+	"603799": "Melli",
+	"589210": "Sepah",
+	"627961": "Sanat va Madan",
+	"603770": "Keshavarzi",
+	"628023": "Maskan",
+	"627760": "Post Bank",
+	"502908": "Tosee Taavon",
+	"627412": "Eghtesad Novin",
+	"622106": "Parsian",
+	"502229": "Pasargad",
+	"627488": "Karafarin",
+	"621986": "Saman",
+	"639346": "Sina",
+	"639607": "Sarmayeh",
+	"636214": "Ayandeh",
+	"502806": "Shahr",
+	"502938": "Day",
+	"603769": "Saderat",
+	"610433": "Mellat",
+	"627353": "Tejarat",
+	"589463": "Refah",
+	"627381": "Ansar",
+	"639370": "Mehr Eghtesad",
+}
+
+// Service contains business logic for bank account management.
+type Service struct {
+	repo     *Repository
+	box      *secretbox.Box
+	inquirer OwnerInquirer
+}
+
+// NewService creates a new bank account Service. Numbers are encrypted
+// with box. inquirer may be nil.
+func NewService(repo *Repository, box *secretbox.Box, inquirer OwnerInquirer) *Service {
+	return &Service{repo: repo, box: box, inquirer: inquirer}
+}
+
+// Add validates and registers a new card or IBAN for the user.
+func (s *Service) Add(ctx context.Context, userID, kind, number string) (*Account, error) {
+	number = normalizeNumber(number)
+
+	a := &Account{UserID: userID, Kind: kind, Number: number}
+	switch kind {
+	case KindCard:
+		if !validCard(number) {
+			return nil, ErrInvalidNumber
+		}
+		if bank, ok := cardBINs[number[:6]]; ok {
+			a.BankName = &bank
+		}
+	case KindIBAN:
+		if !validIBAN(number) {
+			return nil, ErrInvalidNumber
+		}
+	default:
+		return nil, ErrInvalidNumber
+	}
+
+	if s.inquirer != nil {
+		owner, err := s.inquirer.InquireOwner(ctx, kind, number)
+		if err != nil {
+			// Inquiry is best-effort: the account is still usable without an owner name.
+			slog.WarnContext(ctx, "bankaccount: owner inquiry failed", "err", err)
+		} else if owner != "" {
+			a.OwnerName = &owner
+		}
+	}
+
+	if err := s.seal(a); err != nil {
+		return nil, err
+	}
+	created, err := s.repo.Create(ctx, a, maxAccountsPerUser)
+	if err != nil {
+		return nil, fmt.Errorf("add bank account: %w", err)
+	}
+	return created, nil
+}
+
+// List returns all bank accounts of the user with masked numbers.
+func (s *Service) List(ctx context.Context, userID string) ([]*Account, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Get returns one of the user's bank accounts. The returned account carries
+// the full unmasked Number.
+func (s *Service) Get(ctx context.Context, userID, id string) (*Account, error) {
+	a, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(a)
+}
+
+// GetDefault returns the user's default destination, used by the withdrawal flow.
+// The returned account carries the full unmasked Number.
+func (s *Service) GetDefault(ctx context.Context, userID string) (*Account, error) {
+	a, err := s.repo.GetDefault(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(a)
+}
+
+// SetDefault makes the account the user's default destination.
+func (s *Service) SetDefault(ctx context.Context, userID, id string) (*Account, error) {
+	return s.repo.SetDefault(ctx, userID, id)
+}
+
+// Delete removes a bank account belonging to the user.
+func (s *Service) Delete(ctx context.Context, userID, id string) error {
+	return s.repo.Delete(ctx, userID, id)
+}
+
+// seal encrypts a.Number, bound to the user, and fills in its blind index
+// and mask.
+func (s *Service) seal(a *Account) error {
+	enc, err := s.box.Seal([]byte(a.Number), []byte(a.UserID))
+	if err != nil {
+		return fmt.Errorf("encrypt bank account number: %w", err)
+	}
+	a.NumberEnc = enc
+	a.NumberIndex = s.box.Index([]byte(a.Number))
+	a.MaskedNumber = mask(a.Kind, a.Number)
+	return nil
+}
+
+// open decrypts a.NumberEnc into a.Number.
+func (s *Service) open(a *Account) (*Account, error) {
+	plain, err := s.box.Open(a.NumberEnc, []byte(a.UserID))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt bank account number: %w", err)
+	}
+	a.Number = string(plain)
+	return a, nil
+}
+
+// mask hides all but the identifying edges of a number:
+// cards keep the first 6 and last 4 digits, IBANs keep the country/check digits and last 4.
+func mask(kind, number string) string {
+	keepHead := 6
+	if kind == KindIBAN {
+		keepHead = 4
+	}
+	if len(number) <= keepHead+4 {
+		return number
+	}
+	return number[:keepHead] + strings.Repeat("*", len(number)-keepHead-4) + number[len(number)-4:]
+}
+
+// normalizeNumber strips separators and upper-cases the IBAN country prefix.
+func normalizeNumber(number string) string {
+	number = strings.NewReplacer(" ", "", "-", "").Replace(number)
+	return strings.ToUpper(number)
+}
+
+// validCard reports whether number is a 16-digit card number with a valid Luhn checksum.
+func validCard(number string) bool {
+	if len(number) != 16 || !isDigits(number) {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 16; i++ {
+		d := int(number[i] - '0')
+		if i%2 == 0 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// validIBAN reports whether number is an Iranian IBAN (IR + 24 digits) with a valid ISO 13616 checksum.
+func validIBAN(number string) bool {
+	if len(number) != 26 || !strings.HasPrefix(number, "IR") || !isDigits(number[2:]) {
+		return false
+	}
+	// Move the country code and check digits to the end; "IR" becomes "1827".
+	rearranged := number[4:] + "1827" + number[2:4]
+	n, ok := new(big.Int).SetString(rearranged, 10)
+	if !ok {
+		return false
+	}
+	return new(big.Int).Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,113 @@
+package block
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for block endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new block Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// Block godoc
+//
+//	@Summary		Block user
+//	@Description	Block a user. They can no longer send you transfers, payment requests or messages, and you stop appearing in their contact matches. Blocking is silent and idempotent.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"User ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		400	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/{id}/block [post]
+func (h *Handler) Block(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.Block(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		switch {
+		case errors.Is(err, ErrSelfBlock):
+			response.BadRequest(w, "you cannot block yourself")
+		case errors.Is(err, ErrUserNotFound):
+			response.NotFound(w, "user not found")
+		default:
+			response.InternalError(w)
+		}
+		return
+	}
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// Unblock godoc
+//
+//	@Summary		Unblock user
+//	@Description	Lift a block on a user.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"User ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/{id}/block [delete]
+func (h *Handler) Unblock(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.Unblock(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			response.NotFound(w, "user is not blocked")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// List godoc
+//
+//	@Summary		List blocked users
+//	@Description	Returns the users the authenticated user has blocked, newest first.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]Block}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/blocks [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	blocks, err := h.svc.List(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, blocks)
+}
@@ -0,0 +1,148 @@
+// Package block manages user-to-user blocks. Services that let one user
+// reach another (transfers, payment requests, messages, discovery) must
+// consult it before acting.
+package block
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Block is a user blocked by the caller.
+type Block struct {
+	UserID    string    `json:"userId"`
+	Username  *string   `json:"username,omitempty"`
+	FullName  *string   `json:"fullName,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ErrUserNotFound is returned when the user to block does not exist.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrNotFound is returned when unblocking a user who is not blocked.
+var ErrNotFound = errors.New("block not found")
+
+// Repository handles block persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new block Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Create blocks blockedID for blockerID. Blocking twice is a no-op.
+func (r *Repository) Create(ctx context.Context, blockerID, blockedID string) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO user_blocks (blocker_id, blocked_id) VALUES ($1, $2)
+		 ON CONFLICT (blocker_id, blocked_id) DO NOTHING`,
+		blockerID, blockedID,
+	)
+	if err != nil {
+		if isForeignKeyViolation(err) || isInvalidID(err) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("create block: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a block.
+func (r *Repository) Delete(ctx context.Context, blockerID, blockedID string) error {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`,
+		blockerID, blockedID,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("delete block: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListByBlocker returns the users blockerID has blocked, newest first.
+func (r *Repository) ListByBlocker(ctx context.Context, blockerID string) ([]*Block, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT u.id, u.username, u.full_name, b.created_at
+		 FROM user_blocks b JOIN users u ON u.id = b.blocked_id AND u.deleted_at IS NULL
+		 WHERE b.blocker_id = $1
+		 ORDER BY b.created_at DESC`,
+		blockerID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list blocks: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Block{}
+	for rows.Next() {
+		b := &Block{}
+		if err := rows.Scan(&b.UserID, &b.Username, &b.FullName, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan block: %w", err)
+		}
+		out = append(out, b)
+	}
+	return out, rows.Err()
+}
+
+// Exists reports whether blockerID has blocked blockedID.
+func (r *Repository) Exists(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2)`,
+		blockerID, blockedID,
+	).Scan(&exists)
+	if err != nil {
+		if isInvalidID(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("check block exists: %w", err)
+	}
+	return exists, nil
+}
+
+// BlockersAmong returns which of candidateIDs have blocked blockedID.
+func (r *Repository) BlockersAmong(ctx context.Context, blockedID string, candidateIDs []string) (map[string]bool, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT blocker_id FROM user_blocks WHERE blocked_id = $1 AND blocker_id = ANY($2::uuid[])`,
+		blockedID, candidateIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list blockers: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan blocker: %w", err)
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
+
+// isForeignKeyViolation checks whether an error is a PostgreSQL foreign_key_violation (code 23503).
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
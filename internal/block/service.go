@@ -0,0 +1,64 @@
+package block
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrSelfBlock is returned when a user tries to block themselves.
+var ErrSelfBlock = errors.New("cannot block yourself")
+
+// ErrBlocked is returned by other services when the recipient has blocked
+// the actor.
+var ErrBlocked = errors.New("recipient has blocked this user")
+
+// Service contains business logic for user blocking.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new block Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Block stops blockedID from reaching blockerID.
+func (s *Service) Block(ctx context.Context, blockerID, blockedID string) error {
+	if blockerID == blockedID {
+		return ErrSelfBlock
+	}
+	return s.repo.Create(ctx, blockerID, blockedID)
+}
+
+// Unblock lifts a block.
+func (s *Service) Unblock(ctx context.Context, blockerID, blockedID string) error {
+	return s.repo.Delete(ctx, blockerID, blockedID)
+}
+
+// List returns the users blockerID has blocked.
+func (s *Service) List(ctx context.Context, blockerID string) ([]*Block, error) {
+	return s.repo.ListByBlocker(ctx, blockerID)
+}
+
+// CheckReach returns ErrBlocked when recipientID has blocked actorID. Transfer,
+// payment-request and messaging services call this before acting on behalf of
+// actorID towards recipientID.
+func (s *Service) CheckReach(ctx context.Context, actorID, recipientID string) error {
+	blocked, err := s.repo.Exists(ctx, recipientID, actorID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+	return nil
+}
+
+// BlockersAmong returns which of candidateIDs have blocked userID. Listing
+// services (search, discovery) use it to drop those users from results.
+func (s *Service) BlockersAmong(ctx context.Context, userID string, candidateIDs []string) (map[string]bool, error) {
+	if len(candidateIDs) == 0 {
+		return map[string]bool{}, nil
+	}
+	return s.repo.BlockersAmong(ctx, userID, candidateIDs)
+}
@@ -0,0 +1,314 @@
+// Package bootstrap sets up what every Radif binary needs before its own
+// wiring: configuration and secrets, logging, error reporting and tracing,
+// and clients for Postgres, Redis, object storage and the event bus. It is
+// shared by cmd/api and cmd/worker so both start the same way.
+//
+// Like main, it treats misconfiguration as fatal and exits instead of
+// returning errors.
+package bootstrap
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/radif/service/internal/cache"
+	"github.com/radif/service/internal/cdn"
+	"github.com/radif/service/internal/chaos"
+	"github.com/radif/service/internal/config"
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/events"
+	"github.com/radif/service/internal/logfile"
+	"github.com/radif/service/internal/logging"
+	"github.com/radif/service/internal/moderation"
+	"github.com/radif/service/internal/secrets"
+	"github.com/radif/service/internal/sms"
+	"github.com/radif/service/internal/tracing"
+)
+
+// Fatal logs msg at error level and exits, like log.Fatal.
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// LoadConfig loads the configuration, with secrets from SECRETS_BACKEND
+// when one is set. Invalid configuration exits in production; elsewhere
+// Setup logs it as a warning. The secrets store is nil without a backend.
+func LoadConfig() (*config.Config, *secrets.Store) {
+	cfg := config.Load()
+	secretStore, err := openSecrets(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "secrets backend: %v\n", err)
+		os.Exit(1)
+	}
+	if secretStore != nil {
+		cfg = config.LoadWithSecrets(secretStore.Lookup)
+	}
+	if err := cfg.Validate(); err != nil && cfg.IsProduction() {
+		// Logging is not set up yet, and may be what is misconfigured.
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(1)
+	}
+	return cfg, secretStore
+}
+
+// openSecrets fetches secrets from SECRETS_BACKEND, or returns nil when it
+// is not set.
+func openSecrets(cfg *config.Config) (*secrets.Store, error) {
+	var src secrets.Source
+	switch cfg.SecretsBackend {
+	case "":
+		return nil, nil
+	case "vault":
+		src = secrets.NewVault(cfg.VaultAddr, cfg.VaultToken, cfg.VaultSecretPath)
+	case "sops":
+		src = secrets.NewSOPS(cfg.SOPSFile)
+	default:
+		return nil, fmt.Errorf("unknown SECRETS_BACKEND %q (want vault or sops)", cfg.SecretsBackend)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return secrets.Open(ctx, src)
+}
+
+// Setup configures logging, error reporting and tracing for service, and
+// returns a function that flushes them on exit.
+func Setup(cfg *config.Config, service string) (shutdown func()) {
+	var closers []func()
+	shutdown = func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+
+	var logFile io.Writer
+	if cfg.LogFile != "" {
+		f, err := logfile.OpenRotating(cfg.LogFile, logfile.RotateOptions{
+			MaxSize:    int64(cfg.LogFileMaxSizeMB) << 20,
+			MaxAge:     cfg.LogFileMaxAge,
+			Retention:  cfg.LogFileRetention,
+			MaxBackups: cfg.LogFileMaxBackups,
+		})
+		if err != nil {
+			Fatal("log file setup failed", "err", err)
+		}
+		closers = append(closers, func() { f.Close() })
+		logFile = f
+	}
+	if err := logging.Setup(logging.Options{
+		Level:   cfg.LogLevel,
+		Format:  cfg.LogFormat,
+		Service: service,
+		Env:     cfg.AppEnv,
+		File:    logFile,
+	}); err != nil {
+		Fatal("logging setup failed", "err", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		slog.Warn("config: invalid settings, continuing outside production", "err", err)
+	}
+
+	if cfg.SentryDSN != "" {
+		if err := sentry.Init(sentry.ClientOptions{
+			Dsn:              cfg.SentryDSN,
+			Environment:      cfg.AppEnv,
+			SampleRate:       cfg.SentrySampleRate,
+			AttachStacktrace: true,
+		}); err != nil {
+			Fatal("error reporting setup failed", "err", err)
+		}
+		closers = append(closers, func() { sentry.Flush(5 * time.Second) })
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Endpoint:    cfg.OTLPEndpoint,
+		ServiceName: service,
+		Environment: cfg.AppEnv,
+		SampleRatio: cfg.TraceSampleRatio,
+	})
+	if err != nil {
+		Fatal("tracing setup failed", "err", err)
+	}
+	closers = append(closers, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("tracing shutdown failed", "err", err)
+		}
+	})
+	return shutdown
+}
+
+// ConnectDatabase opens the primary pool and, with DATABASE_READ_URL, the
+// replica pool, both traced and with dbTracer after the span. The replica is
+// optional: if it is down at startup, replica is nil and reads go to the
+// primary until the process restarts.
+func ConnectDatabase(cfg *config.Config, dbTracer pgx.QueryTracer) (primary, replica *pgxpool.Pool) {
+	// The tracing span goes first so it covers injected faults too.
+	primary, err := db.Connect(cfg.DatabaseURL, PoolOptions(cfg, cfg.CurrentDatabasePassword), tracing.NewDBTracer(), dbTracer)
+	if err != nil {
+		Fatal("database connection failed", "err", err)
+	}
+	if cfg.DatabaseReadURL != "" {
+		replica, err = db.Connect(cfg.DatabaseReadURL, PoolOptions(cfg, cfg.CurrentDatabaseReadPassword), tracing.NewDBTracer(), dbTracer)
+		if err != nil {
+			slog.Warn("read replica connection failed, reading from primary", "err", err)
+			replica = nil
+		}
+	}
+	return primary, replica
+}
+
+// PoolOptions returns the configured pool tuning, authenticating new
+// connections with password.
+func PoolOptions(cfg *config.Config, password func() string) db.PoolOptions {
+	return db.PoolOptions{
+		MaxConns:          int32(cfg.DatabaseMaxConns),
+		MinConns:          int32(cfg.DatabaseMinConns),
+		MaxConnLifetime:   cfg.DatabaseMaxConnLifetime,
+		MaxConnIdleTime:   cfg.DatabaseMaxConnIdleTime,
+		HealthCheckPeriod: cfg.DatabaseHealthCheckPeriod,
+		StatementTimeout:  cfg.DatabaseStatementTimeout,
+		Password:          password,
+	}
+}
+
+// DataEncryptionKey decodes DATA_ENCRYPTION_KEY. Outside production a missing
+// key is derived from the JWT secret so local setups work without extra config.
+func DataEncryptionKey(cfg *config.Config) []byte {
+	if cfg.DataEncryptionKey == "" {
+		if cfg.IsProduction() {
+			Fatal("DATA_ENCRYPTION_KEY is required in production")
+		}
+		slog.Warn("DATA_ENCRYPTION_KEY not set, deriving a development key")
+		sum := sha256.Sum256([]byte(cfg.JWTSecret))
+		return sum[:]
+	}
+	key, err := base64.StdEncoding.DecodeString(cfg.DataEncryptionKey)
+	if err != nil {
+		Fatal("DATA_ENCRYPTION_KEY must be base64", "err", err)
+	}
+	return key
+}
+
+// AuditSigningKey decodes AUDIT_SIGNING_KEY into the key that signs audit
+// exports. Outside production a missing key is derived from the JWT secret,
+// like the development data encryption key.
+func AuditSigningKey(cfg *config.Config) ed25519.PrivateKey {
+	if cfg.AuditSigningKey == "" {
+		if cfg.IsProduction() {
+			Fatal("AUDIT_SIGNING_KEY is required in production")
+		}
+		slog.Warn("AUDIT_SIGNING_KEY not set, deriving a development key")
+		seed := sha256.Sum256([]byte("audit-signing:" + cfg.JWTSecret))
+		return ed25519.NewKeyFromSeed(seed[:])
+	}
+	seed, err := base64.StdEncoding.DecodeString(cfg.AuditSigningKey)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		Fatal("AUDIT_SIGNING_KEY must be a base64-encoded 32-byte seed")
+	}
+	return ed25519.NewKeyFromSeed(seed)
+}
+
+// OpenCache connects to REDIS_URL, or returns nil when it is not set.
+func OpenCache(cfg *config.Config) *cache.Cache {
+	if cfg.RedisURL == "" {
+		if cfg.IsProduction() {
+			slog.Warn("REDIS_URL not set; caching, per-phone OTP limits and token revocation are disabled (development only)")
+		}
+		return nil
+	}
+	c, err := cache.Open(cfg.RedisURL, "radif:")
+	if err != nil {
+		Fatal("invalid REDIS_URL", "err", err)
+	}
+	return c
+}
+
+// OpenEventBus connects to EVENT_BUS_URL, or returns nil when it is not set.
+func OpenEventBus(cfg *config.Config) *events.NATSPublisher {
+	if cfg.EventBusURL == "" {
+		return nil
+	}
+	pub, err := events.NewNATSPublisher(cfg.EventBusURL, cfg.EventBusSubjectPrefix)
+	if err != nil {
+		Fatal("invalid EVENT_BUS_URL", "err", err)
+	}
+	return pub
+}
+
+// NewCDNInvalidator returns the CDN purge queue, or nil when no CDN or purge
+// provider is configured.
+func NewCDNInvalidator(cfg *config.Config) *cdn.Invalidator {
+	if cfg.CDNPurgeProvider == "" {
+		return nil
+	}
+	if cfg.StorageCDNBase == "" {
+		slog.Warn("CDN_PURGE_PROVIDER ignored: STORAGE_CDN_BASE is not set", "value", cfg.CDNPurgeProvider)
+		return nil
+	}
+	switch cfg.CDNPurgeProvider {
+	case "arvancloud":
+		if cfg.ArvanCloudAPIKey == "" || cfg.ArvanCloudDomain == "" {
+			Fatal("CDN_PURGE_PROVIDER=arvancloud requires ARVANCLOUD_API_KEY and ARVANCLOUD_DOMAIN")
+		}
+		purger := cdn.NewArvanCloud(cfg.ArvanCloudAPIURL, cfg.ArvanCloudAPIKey, cfg.ArvanCloudDomain)
+		return cdn.NewInvalidator(purger, cfg.StorageCDNBase)
+	default:
+		Fatal("unknown CDN_PURGE_PROVIDER (want arvancloud)", "value", cfg.CDNPurgeProvider)
+		return nil
+	}
+}
+
+// CacheInvalidator returns inv as an interface, keeping a nil queue a nil
+// interface. The result satisfies the handlers' CacheInvalidator types too.
+func CacheInvalidator(inv *cdn.Invalidator) moderation.CacheInvalidator {
+	if inv == nil {
+		return nil
+	}
+	return inv
+}
+
+// FaultInjector builds the fault injector when CHAOS_ENABLED is set. It
+// returns nil otherwise, and refuses to start in production.
+func FaultInjector(cfg *config.Config) *chaos.Injector {
+	if !cfg.ChaosEnabled {
+		return nil
+	}
+	if cfg.IsProduction() {
+		Fatal("CHAOS_ENABLED must not be set in production")
+	}
+	faults, err := chaos.Parse(cfg.ChaosFaults)
+	if err != nil {
+		Fatal("invalid CHAOS_FAULTS", "err", err)
+	}
+	inj, err := chaos.New(faults)
+	if err != nil {
+		Fatal("invalid CHAOS_FAULTS", "err", err)
+	}
+	slog.Warn("fault injection enabled", "faults", chaos.Describe(faults))
+	return inj
+}
+
+// SMSDispatcher returns the SMS dispatcher, with injected faults when
+// injector is not nil.
+func SMSDispatcher(injector *chaos.Injector) *sms.Dispatcher {
+	// No SMS providers are integrated yet; OTPs are only logged.
+	var providers []sms.Provider
+	if injector != nil {
+		providers = chaos.WrapSMS(injector, providers...)
+	}
+	providers = tracing.WrapSMS(providers...)
+	return sms.NewDispatcher(providers...)
+}
@@ -0,0 +1,188 @@
+package bootstrap
+
+import (
+	"crypto/sha256"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/chaos"
+	"github.com/radif/service/internal/config"
+	"github.com/radif/service/internal/health"
+	"github.com/radif/service/internal/metrics"
+	"github.com/radif/service/internal/storage"
+	"github.com/radif/service/internal/storagegc"
+	"github.com/radif/service/internal/tracing"
+)
+
+// Stores holds the configured buckets.
+type Stores struct {
+	// Public holds avatars and other world-readable images. Sensitive
+	// documents live in private buckets, one per purpose, and are only
+	// shared with reviewers through short-lived signed URLs. Quarantine is
+	// nil unless moderation is enabled. Audit holds audit trail exports.
+	Public, KYC, Business, Quarantine, Audit storage.Storage
+
+	// GCTargets lists every bucket with the references that keep its
+	// objects alive, for the storage garbage collector. Its stores are the
+	// bare ones, without fault injection, metrics or tracing.
+	GCTargets []storagegc.Target
+
+	opener *storeOpener
+}
+
+// OpenStores opens the buckets with the configured storage driver. The
+// returned stores inject faults when injector is not nil and report to m.
+func OpenStores(cfg *config.Config, injector *chaos.Injector, m *metrics.Metrics) *Stores {
+	o := newStoreOpener(cfg)
+	s := &Stores{opener: o}
+	s.Public = o.public(cfg.StorageBucket)
+	s.KYC = o.private(cfg.StorageKYCBucket)
+	s.Business = s.KYC
+	if cfg.StorageBusinessBucket != cfg.StorageKYCBucket {
+		s.Business = o.private(cfg.StorageBusinessBucket)
+	}
+	if cfg.ModerationEnabled {
+		s.Quarantine = o.private(cfg.StorageQuarantineBucket)
+	}
+	s.Audit = o.private(cfg.StorageAuditBucket)
+	s.GCTargets = []storagegc.Target{
+		{Name: cfg.StorageBucket, Store: s.Public, Refs: []storagegc.Ref{storagegc.RefUserAvatar, storagegc.RefGroupAvatar, storagegc.RefUserCover, storagegc.RefGalleryImage}, Owners: storagegc.AvatarOwners},
+		{Name: cfg.StorageKYCBucket, Store: s.KYC, Refs: []storagegc.Ref{storagegc.RefKYCDocument}},
+	}
+	if cfg.StorageBusinessBucket != cfg.StorageKYCBucket {
+		s.GCTargets = append(s.GCTargets, storagegc.Target{Name: cfg.StorageBusinessBucket, Store: s.Business, Refs: []storagegc.Ref{storagegc.RefBusinessDocument}})
+	} else {
+		s.GCTargets[1].Refs = append(s.GCTargets[1].Refs, storagegc.RefBusinessDocument)
+	}
+	s.GCTargets = append(s.GCTargets, storagegc.Target{Name: cfg.StorageAuditBucket, Store: s.Audit, Refs: []storagegc.Ref{storagegc.RefAuditExport}})
+	if s.Quarantine != nil {
+		s.GCTargets = append(s.GCTargets, storagegc.Target{Name: cfg.StorageQuarantineBucket, Store: s.Quarantine, Refs: []storagegc.Ref{storagegc.RefQuarantined}})
+	}
+
+	wrap := func(bucket string, st storage.Storage) storage.Storage {
+		if st == nil {
+			return nil
+		}
+		if injector != nil {
+			st = chaos.WrapStorage(injector, st)
+		}
+		return tracing.WrapStorage(bucket, m.WrapStorage(bucket, st))
+	}
+	s.Public = wrap(cfg.StorageBucket, s.Public)
+	s.KYC = wrap(cfg.StorageKYCBucket, s.KYC)
+	s.Business = wrap(cfg.StorageBusinessBucket, s.Business)
+	s.Quarantine = wrap(cfg.StorageQuarantineBucket, s.Quarantine)
+	s.Audit = wrap(cfg.StorageAuditBucket, s.Audit)
+	return s
+}
+
+// AddReadiness adds a non-critical check per bucket to c. It pings the bare
+// stores, so probes neither trip injected faults nor show up in storage
+// metrics.
+func (s *Stores) AddReadiness(c *health.Checker) {
+	for _, t := range s.GCTargets {
+		c.Add("storage:"+t.Name, false, health.StorageCheck(t.Store))
+	}
+}
+
+// Mount serves the local buckets on r. It does nothing for MinIO.
+func (s *Stores) Mount(r chi.Router) {
+	for path, st := range s.opener.local {
+		r.Mount(path, http.StripPrefix(path, st))
+	}
+}
+
+// storeOpener opens buckets with the configured storage driver and keeps the
+// local ones so the router can serve them.
+type storeOpener struct {
+	cfg   *config.Config
+	local map[string]*storage.LocalFS // URL path → bucket
+}
+
+func newStoreOpener(cfg *config.Config) *storeOpener {
+	switch cfg.StorageDriver {
+	case "minio":
+	case "local":
+		if cfg.IsProduction() {
+			Fatal("STORAGE_DRIVER=local must not be used in production")
+		}
+		slog.Info("storage: using local directory", "dir", cfg.StorageLocalDir)
+	default:
+		Fatal("unknown STORAGE_DRIVER", "value", cfg.StorageDriver)
+	}
+	return &storeOpener{cfg: cfg, local: map[string]*storage.LocalFS{}}
+}
+
+// public opens a world-readable bucket. On MinIO its URLs follow the CDN
+// rollout settings.
+func (o *storeOpener) public(bucket string) storage.Storage {
+	cfg := o.cfg
+	if cfg.StorageDriver == "local" {
+		return o.openLocal(bucket, true)
+	}
+	s, err := storage.NewMinioStorage(
+		cfg.StorageEndpoint,
+		cfg.StorageAccessKey,
+		cfg.StorageSecretKey,
+		bucket,
+		cfg.StoragePublicBase,
+		cfg.StorageUseSSL,
+	)
+	if err != nil {
+		Fatal("object storage init failed", "err", err)
+	}
+	return storage.WithURLStrategy(s, storage.NewURLStrategy(
+		cfg.StoragePublicBase,
+		cfg.StorageCDNBase,
+		cfg.StorageCDNPercent,
+		cfg.StorageCDNSpaces,
+	))
+}
+
+// private opens a bucket for sensitive documents.
+func (o *storeOpener) private(bucket string) storage.Storage {
+	cfg := o.cfg
+	if cfg.StorageDriver == "local" {
+		return o.openLocal(bucket, false)
+	}
+	s, err := storage.NewPrivateMinioStorage(
+		cfg.StorageEndpoint,
+		cfg.StorageAccessKey,
+		cfg.StorageSecretKey,
+		bucket,
+		cfg.StorageUseSSL,
+	)
+	if err != nil {
+		Fatal("private object storage init failed", "bucket", bucket, "err", err)
+	}
+	return s
+}
+
+// openLocal opens bucket as a directory under STORAGE_LOCAL_DIR, served at
+// STORAGE_LOCAL_BASE_URL/{bucket}. Signed URLs use a key derived from the JWT
+// secret.
+func (o *storeOpener) openLocal(bucket string, public bool) storage.Storage {
+	dir := filepath.Join(o.cfg.StorageLocalDir, bucket)
+	base := strings.TrimRight(o.cfg.StorageLocalBaseURL, "/") + "/" + bucket
+	u, err := url.Parse(base)
+	if err != nil {
+		Fatal("invalid STORAGE_LOCAL_BASE_URL", "err", err)
+	}
+	secret := sha256.Sum256([]byte("local-storage:" + o.cfg.JWTSecret))
+
+	open := storage.NewPrivateLocalFS
+	if public {
+		open = storage.NewLocalFS
+	}
+	s, err := open(dir, base, secret[:])
+	if err != nil {
+		Fatal("local storage init failed", "bucket", bucket, "err", err)
+	}
+	o.local[u.Path] = s
+	return s
+}
@@ -0,0 +1,408 @@
+package branch
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/block"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+const (
+	maxNameLength    = 100
+	maxAddressLength = 255
+)
+
+var settlementTagRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// Handler holds HTTP handlers for branch endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new branch Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type createRequest struct {
+	Name          string  `json:"name"          example:"Tajrish"`
+	Address       *string `json:"address"       example:"Tajrish Sq., Tehran"`
+	SettlementTag *string `json:"settlementTag" example:"TJR-01"`
+}
+
+type updateRequest struct {
+	Name          *string `json:"name"`
+	Address       *string `json:"address"`
+	SettlementTag *string `json:"settlementTag"`
+}
+
+type assignRequest struct {
+	UserID string `json:"userId" example:"e7eedc79-0707-4fe4-8734-526b7ef13a7b"`
+}
+
+// Create godoc
+//
+//	@Summary		Create branch
+//	@Description	Add a branch to the business (max 50). The optional settlement tag (1-32 letters, digits, "-" or "_") must be unique among the business's branches and is used to attribute payments to the branch. Business accounts only.
+//	@Tags			branches
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		createRequest	true	"Branch details"
+//	@Success		201		{object}	response.Envelope{data=Branch}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/branches [post]
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	var req createRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		response.BadRequest(w, "name is required")
+		return
+	}
+	if msg := validateFields(&req.Name, req.Address, req.SettlementTag); msg != "" {
+		response.BadRequest(w, msg)
+		return
+	}
+
+	b, err := h.svc.Create(r.Context(), businessID, req.Name, req.Address, req.SettlementTag)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Created(w, b)
+}
+
+// List godoc
+//
+//	@Summary		List branches
+//	@Description	Returns the business's branches, oldest first. Business accounts only.
+//	@Tags			branches
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]Branch}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/branches [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	branches, err := h.svc.List(r.Context(), businessID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, branches)
+}
+
+// Get godoc
+//
+//	@Summary		Get branch
+//	@Description	Returns one of the business's branches. Business accounts only.
+//	@Tags			branches
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Branch ID"
+//	@Success		200	{object}	response.Envelope{data=Branch}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/branches/{id} [get]
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	b, err := h.svc.Get(r.Context(), businessID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, b)
+}
+
+// Update godoc
+//
+//	@Summary		Update branch
+//	@Description	Change a branch's name, address or settlement tag. Omitted fields are left unchanged. Business accounts only.
+//	@Tags			branches
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string			true	"Branch ID"
+//	@Param			request	body		updateRequest	true	"Fields to update"
+//	@Success		200		{object}	response.Envelope{data=Branch}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/branches/{id} [patch]
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if req.Name != nil {
+		trimmed := strings.TrimSpace(*req.Name)
+		if trimmed == "" {
+			response.BadRequest(w, "name must not be empty")
+			return
+		}
+		req.Name = &trimmed
+	}
+	if msg := validateFields(req.Name, req.Address, req.SettlementTag); msg != "" {
+		response.BadRequest(w, msg)
+		return
+	}
+
+	b, err := h.svc.Update(r.Context(), businessID, chi.URLParam(r, "id"), req.Name, req.Address, req.SettlementTag)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, b)
+}
+
+// Delete godoc
+//
+//	@Summary		Delete branch
+//	@Description	Delete a branch and unassign its staff. Business accounts only.
+//	@Tags			branches
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Branch ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/branches/{id} [delete]
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), businessID, chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// Staff godoc
+//
+//	@Summary		List branch staff
+//	@Description	Returns the users assigned to the branch. Business accounts only.
+//	@Tags			branches
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Branch ID"
+//	@Success		200	{object}	response.Envelope{data=[]Staff}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/branches/{id}/staff [get]
+func (h *Handler) Staff(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	staff, err := h.svc.Staff(r.Context(), businessID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, staff)
+}
+
+// AssignStaff godoc
+//
+//	@Summary		Assign branch staff
+//	@Description	Assign a user to work at the branch (max 50 per branch). Users who have blocked the business cannot be assigned. Business accounts only.
+//	@Tags			branches
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string			true	"Branch ID"
+//	@Param			request	body		assignRequest	true	"User to assign"
+//	@Success		201		{object}	response.Envelope
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/branches/{id}/staff [post]
+func (h *Handler) AssignStaff(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	var req assignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		response.BadRequest(w, "userId is required")
+		return
+	}
+
+	if err := h.svc.AssignStaff(r.Context(), businessID, chi.URLParam(r, "id"), req.UserID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Created(w, map[string]bool{"success": true})
+}
+
+// UnassignStaff godoc
+//
+//	@Summary		Unassign branch staff
+//	@Description	Remove a user from the branch's staff. Business accounts only.
+//	@Tags			branches
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Branch ID"
+//	@Param			userId	path		string	true	"User ID"
+//	@Success		200		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/branches/{id}/staff/{userId} [delete]
+func (h *Handler) UnassignStaff(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.UnassignStaff(r.Context(), businessID, chi.URLParam(r, "id"), chi.URLParam(r, "userId")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// Assignments godoc
+//
+//	@Summary		List my branch assignments
+//	@Description	Returns the business branches the caller is assigned to as staff, most recent first.
+//	@Tags			branches
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]Assignment}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/branch-assignments [get]
+func (h *Handler) Assignments(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	assignments, err := h.svc.Assignments(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, assignments)
+}
+
+// businessAccount returns the authenticated user ID, writing an error
+// response when the caller is not authenticated or not a business account.
+func businessAccount(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return "", false
+	}
+	if accountType, _ := r.Context().Value(middleware.UserAccountTypeKey).(string); accountType != "business" {
+		response.Forbidden(w, "branches are available to business accounts only")
+		return "", false
+	}
+	return userID, true
+}
+
+// writeError maps branch service errors to responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "branch not found")
+	case errors.Is(err, ErrUserNotFound):
+		response.NotFound(w, "user not found")
+	case errors.Is(err, ErrNotAssigned):
+		response.NotFound(w, "user is not assigned to this branch")
+	case errors.Is(err, block.ErrBlocked):
+		response.Forbidden(w, "this user cannot be assigned")
+	case errors.Is(err, ErrTagInUse):
+		response.Conflict(w, "settlement tag is already used by another branch")
+	case errors.Is(err, ErrAlreadyAssigned):
+		response.Conflict(w, "user is already assigned to this branch")
+	case errors.Is(err, ErrLimitReached):
+		response.BadRequest(w, "maximum of 50 branches reached")
+	case errors.Is(err, ErrBranchFull):
+		response.BadRequest(w, "branch has reached the maximum of 50 staff")
+	case errors.Is(err, ErrSelfAssign):
+		response.BadRequest(w, "the business account cannot be assigned as staff")
+	default:
+		response.InternalError(w)
+	}
+}
+
+// validateFields checks name, address and settlement tag; nil fields are
+// skipped.
+func validateFields(name, address, tag *string) string {
+	if name != nil && len([]rune(*name)) > maxNameLength {
+		return "name must be 100 characters or fewer"
+	}
+	if address != nil && len([]rune(*address)) > maxAddressLength {
+		return "address must be 255 characters or fewer"
+	}
+	if tag != nil && !settlementTagRegex.MatchString(*tag) {
+		return "settlementTag must be 1-32 letters, digits, \"-\" or \"_\""
+	}
+	return ""
+}
@@ -0,0 +1,301 @@
+// Package branch manages the branches of a business account: their names,
+// addresses and settlement tags, and which users are assigned to staff them.
+package branch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Branch is a branch of a business account.
+type Branch struct {
+	ID            string    `json:"id"`
+	BusinessID    string    `json:"-"`
+	Name          string    `json:"name"                    example:"Tajrish"`
+	Address       *string   `json:"address,omitempty"       example:"Tajrish Sq., Tehran"`
+	SettlementTag *string   `json:"settlementTag,omitempty" example:"TJR-01"`
+	StaffCount    int       `json:"staffCount"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Staff is a user assigned to a branch.
+type Staff struct {
+	UserID     string    `json:"userId"`
+	Username   *string   `json:"username,omitempty"`
+	FullName   *string   `json:"fullName,omitempty"`
+	AssignedAt time.Time `json:"assignedAt"`
+}
+
+// Assignment is a branch the caller staffs, with the business it belongs to.
+type Assignment struct {
+	BranchID         string    `json:"branchId"`
+	BranchName       string    `json:"branchName"`
+	BusinessID       string    `json:"businessId"`
+	BusinessUsername *string   `json:"businessUsername,omitempty"`
+	BusinessName     *string   `json:"businessName,omitempty"`
+	AssignedAt       time.Time `json:"assignedAt"`
+}
+
+// ErrNotFound is returned when a branch does not exist for the business.
+var ErrNotFound = errors.New("branch not found")
+
+// ErrTagInUse is returned when another branch of the business has the same
+// settlement tag.
+var ErrTagInUse = errors.New("settlement tag already used by another branch")
+
+// ErrUserNotFound is returned when a user to assign does not exist.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrAlreadyAssigned is returned when the user already staffs the branch.
+var ErrAlreadyAssigned = errors.New("user is already assigned to this branch")
+
+// ErrNotAssigned is returned when the user does not staff the branch.
+var ErrNotAssigned = errors.New("user is not assigned to this branch")
+
+// Repository handles branch persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new branch Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const selectCols = `b.id, b.business_id, b.name, b.address, b.settlement_tag,
+	(SELECT COUNT(*) FROM branch_staff s WHERE s.branch_id = b.id), b.created_at, b.updated_at`
+
+// scanBranch scans a selectCols row into a Branch value.
+func scanBranch(row pgx.Row, b *Branch) error {
+	return row.Scan(
+		&b.ID, &b.BusinessID, &b.Name, &b.Address, &b.SettlementTag,
+		&b.StaffCount, &b.CreatedAt, &b.UpdatedAt,
+	)
+}
+
+// Create inserts a new branch and returns its ID.
+func (r *Repository) Create(ctx context.Context, businessID, name string, address, tag *string) (string, error) {
+	var id string
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO branches (business_id, name, address, settlement_tag)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id`,
+		businessID, name, address, tag,
+	).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return "", ErrTagInUse
+		}
+		return "", fmt.Errorf("create branch: %w", err)
+	}
+	return id, nil
+}
+
+// CountByBusiness returns how many branches the business has.
+func (r *Repository) CountByBusiness(ctx context.Context, businessID string) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM branches WHERE business_id = $1`, businessID,
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count branches: %w", err)
+	}
+	return n, nil
+}
+
+// ListByBusiness returns the business's branches, oldest first.
+func (r *Repository) ListByBusiness(ctx context.Context, businessID string) ([]*Branch, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+selectCols+` FROM branches b
+		 WHERE b.business_id = $1
+		 ORDER BY b.created_at, b.id`,
+		businessID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list branches: %w", err)
+	}
+	defer rows.Close()
+
+	branches := []*Branch{}
+	for rows.Next() {
+		b := &Branch{}
+		if err := scanBranch(rows, b); err != nil {
+			return nil, fmt.Errorf("scan branch: %w", err)
+		}
+		branches = append(branches, b)
+	}
+	return branches, rows.Err()
+}
+
+// Get returns one of the business's branches.
+func (r *Repository) Get(ctx context.Context, businessID, id string) (*Branch, error) {
+	b := &Branch{}
+	err := scanBranch(r.db.QueryRow(ctx,
+		`SELECT `+selectCols+` FROM branches b WHERE b.id = $1 AND b.business_id = $2`,
+		id, businessID,
+	), b)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get branch: %w", err)
+	}
+	return b, nil
+}
+
+// Update changes the branch's name, address and settlement tag; nil fields
+// are left unchanged.
+func (r *Repository) Update(ctx context.Context, businessID, id string, name, address, tag *string) error {
+	res, err := r.db.Exec(ctx,
+		`UPDATE branches SET
+		    name           = COALESCE($3, name),
+		    address        = COALESCE($4, address),
+		    settlement_tag = COALESCE($5, settlement_tag)
+		 WHERE id = $1 AND business_id = $2`,
+		id, businessID, name, address, tag,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotFound
+		}
+		if isUniqueViolation(err) {
+			return ErrTagInUse
+		}
+		return fmt.Errorf("update branch: %w", err)
+	}
+	if res.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Delete removes a branch and its staff assignments.
+func (r *Repository) Delete(ctx context.Context, businessID, id string) error {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM branches WHERE id = $1 AND business_id = $2`, id, businessID,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("delete branch: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListStaff returns the users assigned to a branch, earliest assignment first.
+func (r *Repository) ListStaff(ctx context.Context, branchID string) ([]*Staff, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT u.id, u.username, u.full_name, s.assigned_at
+		 FROM branch_staff s JOIN users u ON u.id = s.user_id AND u.deleted_at IS NULL
+		 WHERE s.branch_id = $1
+		 ORDER BY s.assigned_at, u.id`,
+		branchID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list branch staff: %w", err)
+	}
+	defer rows.Close()
+
+	staff := []*Staff{}
+	for rows.Next() {
+		s := &Staff{}
+		if err := rows.Scan(&s.UserID, &s.Username, &s.FullName, &s.AssignedAt); err != nil {
+			return nil, fmt.Errorf("scan branch staff: %w", err)
+		}
+		staff = append(staff, s)
+	}
+	return staff, rows.Err()
+}
+
+// AddStaff assigns userID to the branch.
+func (r *Repository) AddStaff(ctx context.Context, branchID, userID string) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO branch_staff (branch_id, user_id) VALUES ($1, $2)`,
+		branchID, userID,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyAssigned
+		}
+		if isForeignKeyViolation(err) || isInvalidID(err) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("add branch staff: %w", err)
+	}
+	return nil
+}
+
+// RemoveStaff unassigns userID from the branch.
+func (r *Repository) RemoveStaff(ctx context.Context, branchID, userID string) error {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM branch_staff WHERE branch_id = $1 AND user_id = $2`,
+		branchID, userID,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotAssigned
+		}
+		return fmt.Errorf("remove branch staff: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotAssigned
+	}
+	return nil
+}
+
+// ListAssignments returns the branches userID staffs, most recent first.
+func (r *Repository) ListAssignments(ctx context.Context, userID string) ([]*Assignment, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT b.id, b.name, u.id, u.username, u.full_name, s.assigned_at
+		 FROM branch_staff s
+		 JOIN branches b ON b.id = s.branch_id
+		 JOIN users u ON u.id = b.business_id AND u.deleted_at IS NULL
+		 WHERE s.user_id = $1
+		 ORDER BY s.assigned_at DESC, b.id`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list branch assignments: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Assignment{}
+	for rows.Next() {
+		a := &Assignment{}
+		if err := rows.Scan(&a.BranchID, &a.BranchName, &a.BusinessID, &a.BusinessUsername, &a.BusinessName, &a.AssignedAt); err != nil {
+			return nil, fmt.Errorf("scan branch assignment: %w", err)
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+// isUniqueViolation checks whether an error is a PostgreSQL unique_violation (code 23505).
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isForeignKeyViolation checks whether an error is a PostgreSQL foreign_key_violation (code 23503).
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
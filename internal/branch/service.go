@@ -0,0 +1,121 @@
+package branch
+
+import (
+	"context"
+	"errors"
+)
+
+// Limits on branches per business and staff per branch.
+const (
+	MaxBranches = 50
+	MaxStaff    = 50
+)
+
+// ErrLimitReached is returned when the business already has MaxBranches.
+var ErrLimitReached = errors.New("branch limit reached")
+
+// ErrBranchFull is returned when the branch already has MaxStaff.
+var ErrBranchFull = errors.New("branch staff limit reached")
+
+// ErrSelfAssign is returned when a business assigns itself as staff.
+var ErrSelfAssign = errors.New("cannot assign the business account as staff")
+
+// ReachChecker reports whether actorID may reach recipientID. It is satisfied
+// by block.Service.
+type ReachChecker interface {
+	CheckReach(ctx context.Context, actorID, recipientID string) error
+}
+
+// Service contains business logic for branches.
+type Service struct {
+	repo  *Repository
+	reach ReachChecker
+}
+
+// NewService creates a new branch Service. reach may be nil, in which case
+// blocks are not checked when assigning staff.
+func NewService(repo *Repository, reach ReachChecker) *Service {
+	return &Service{repo: repo, reach: reach}
+}
+
+// Create adds a branch to the business.
+func (s *Service) Create(ctx context.Context, businessID, name string, address, tag *string) (*Branch, error) {
+	n, err := s.repo.CountByBusiness(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if n >= MaxBranches {
+		return nil, ErrLimitReached
+	}
+
+	id, err := s.repo.Create(ctx, businessID, name, address, tag)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, businessID, id)
+}
+
+// List returns the business's branches.
+func (s *Service) List(ctx context.Context, businessID string) ([]*Branch, error) {
+	return s.repo.ListByBusiness(ctx, businessID)
+}
+
+// Get returns one of the business's branches.
+func (s *Service) Get(ctx context.Context, businessID, id string) (*Branch, error) {
+	return s.repo.Get(ctx, businessID, id)
+}
+
+// Update changes a branch; nil fields are left unchanged.
+func (s *Service) Update(ctx context.Context, businessID, id string, name, address, tag *string) (*Branch, error) {
+	if err := s.repo.Update(ctx, businessID, id, name, address, tag); err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, businessID, id)
+}
+
+// Delete removes a branch and unassigns its staff.
+func (s *Service) Delete(ctx context.Context, businessID, id string) error {
+	return s.repo.Delete(ctx, businessID, id)
+}
+
+// Staff lists the users assigned to one of the business's branches.
+func (s *Service) Staff(ctx context.Context, businessID, id string) ([]*Staff, error) {
+	if _, err := s.repo.Get(ctx, businessID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListStaff(ctx, id)
+}
+
+// AssignStaff assigns userID to one of the business's branches. Users who
+// have blocked the business cannot be assigned.
+func (s *Service) AssignStaff(ctx context.Context, businessID, id, userID string) error {
+	if userID == businessID {
+		return ErrSelfAssign
+	}
+	b, err := s.repo.Get(ctx, businessID, id)
+	if err != nil {
+		return err
+	}
+	if b.StaffCount >= MaxStaff {
+		return ErrBranchFull
+	}
+	if s.reach != nil {
+		if err := s.reach.CheckReach(ctx, businessID, userID); err != nil {
+			return err
+		}
+	}
+	return s.repo.AddStaff(ctx, id, userID)
+}
+
+// UnassignStaff removes userID from one of the business's branches.
+func (s *Service) UnassignStaff(ctx context.Context, businessID, id, userID string) error {
+	if _, err := s.repo.Get(ctx, businessID, id); err != nil {
+		return err
+	}
+	return s.repo.RemoveStaff(ctx, id, userID)
+}
+
+// Assignments lists the branches userID staffs.
+func (s *Service) Assignments(ctx context.Context, userID string) ([]*Assignment, error) {
+	return s.repo.ListAssignments(ctx, userID)
+}
@@ -0,0 +1,399 @@
+package business
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+	"github.com/radif/service/internal/storage"
+)
+
+const (
+	maxDocumentBytes    = 5 << 20 // 5 MB
+	maxLegalNameLength  = 200
+	maxLicenseNumberLen = 50
+)
+
+// allowedDocumentTypes maps accepted document MIME types to file extensions.
+var allowedDocumentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// Handler holds HTTP handlers for business verification endpoints.
+type Handler struct {
+	svc   *Service
+	store storage.Storage
+}
+
+// NewHandler creates a new business verification Handler. store must be a
+// private bucket.
+func NewHandler(svc *Service, store storage.Storage) *Handler {
+	return &Handler{svc: svc, store: store}
+}
+
+// Get godoc
+//
+//	@Summary		Get business verification
+//	@Description	Returns the caller's business verification status. Business accounts only.
+//	@Tags			business
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=Verification}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/business-verification [get]
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := businessID(w, r)
+	if !ok {
+		return
+	}
+
+	v, err := h.svc.Get(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, v)
+}
+
+type submitRequest struct {
+	LegalName     string `json:"legalName"     example:"Radif Pardaz Co."`
+	LicenseNumber string `json:"licenseNumber" example:"14001234567"`
+}
+
+// Submit godoc
+//
+//	@Summary		Submit business verification
+//	@Description	Submit the registered legal name and business license (or company national ID) number for staff review. Approval adds a verified badge to the public profile. Resubmitting replaces a pending or rejected submission. Business accounts only.
+//	@Tags			business
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		submitRequest	true	"Business details"
+//	@Success		200		{object}	response.Envelope{data=Verification}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/business-verification [post]
+func (h *Handler) Submit(w http.ResponseWriter, r *http.Request) {
+	userID, ok := businessID(w, r)
+	if !ok {
+		return
+	}
+
+	var req submitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	req.LegalName = strings.TrimSpace(req.LegalName)
+	req.LicenseNumber = strings.TrimSpace(req.LicenseNumber)
+	if req.LegalName == "" || len([]rune(req.LegalName)) > maxLegalNameLength {
+		response.BadRequest(w, "legalName is required and must be 200 characters or fewer")
+		return
+	}
+	if req.LicenseNumber == "" || len([]rune(req.LicenseNumber)) > maxLicenseNumberLen {
+		response.BadRequest(w, "licenseNumber is required and must be 50 characters or fewer")
+		return
+	}
+
+	v, err := h.svc.Submit(r.Context(), userID, req.LegalName, req.LicenseNumber)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, v)
+}
+
+// UploadDocument godoc
+//
+//	@Summary		Upload business license
+//	@Description	Upload a scan of the business license (JPEG, PNG or PDF, max 5 MB) after submitting business details. Documents are stored in a private bucket. Uploading again replaces the previous document and re-queues the submission. Business accounts only.
+//	@Tags			business
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			document	formData	file	true	"License document"
+//	@Success		200			{object}	response.Envelope{data=Verification}
+//	@Failure		400			{object}	response.Envelope
+//	@Failure		401			{object}	response.Envelope
+//	@Failure		403			{object}	response.Envelope
+//	@Failure		404			{object}	response.Envelope
+//	@Failure		409			{object}	response.Envelope
+//	@Failure		500			{object}	response.Envelope
+//	@Router			/users/me/business-verification/document [post]
+func (h *Handler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	userID, ok := businessID(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxDocumentBytes+1024)
+	if err := r.ParseMultipartForm(maxDocumentBytes); err != nil {
+		response.BadRequest(w, "file too large or invalid multipart form (max 5 MB)")
+		return
+	}
+
+	file, _, err := r.FormFile("document")
+	if err != nil {
+		response.Localized(w, http.StatusBadRequest, "field_required", "document")
+		return
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, err := file.Read(buf)
+	if err != nil && err != io.EOF {
+		response.InternalError(w)
+		return
+	}
+	contentType := http.DetectContentType(buf[:n])
+	ext, allowed := allowedDocumentTypes[contentType]
+	if !allowed {
+		response.BadRequest(w, "only JPEG, PNG, and PDF documents are allowed")
+		return
+	}
+
+	if _, err := h.svc.Get(r.Context(), userID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	key, err := documentKey(userID, ext)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	if err := h.store.Upload(r.Context(), key, io.MultiReader(bytes.NewReader(buf[:n]), file), -1, contentType); err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	v, previous, err := h.svc.SetDocument(r.Context(), userID, key)
+	if err != nil {
+		if delErr := h.store.Delete(r.Context(), key); delErr != nil {
+			slog.WarnContext(r.Context(), "business: delete orphaned document failed", "key", key, "err", delErr)
+		}
+		writeError(w, err)
+		return
+	}
+	if previous != nil {
+		if err := h.store.Delete(r.Context(), *previous); err != nil {
+			slog.WarnContext(r.Context(), "business: delete replaced document failed", "key", *previous, "err", err)
+		}
+	}
+
+	response.OK(w, v)
+}
+
+// documentURLTTL is how long a signed document link in the review queue stays valid.
+const documentURLTTL = 15 * time.Minute
+
+// reviewItem is a verification as shown to staff, with a short-lived link to
+// the license document.
+type reviewItem struct {
+	*Verification
+	DocumentURL *string `json:"documentUrl,omitempty"`
+}
+
+// Queue godoc
+//
+//	@Summary		List business verifications
+//	@Description	Staff review queue: business verifications with the given status (default pending), oldest submission first, with license document links valid for 15 minutes. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status	query		string	false	"Status"	Enums(pending, approved, rejected)
+//	@Param			limit	query		int		false	"Page size (1-100, default 50)"
+//	@Param			offset	query		int		false	"Offset (default 0)"
+//	@Success		200		{object}	response.Envelope{data=[]reviewItem}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/business-verifications [get]
+func (h *Handler) Queue(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	if status == "" {
+		status = StatusPending
+	}
+	limit, offset := 50, 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			response.BadRequest(w, "offset must be a non-negative integer")
+			return
+		}
+		offset = n
+	}
+
+	list, err := h.svc.Queue(r.Context(), status, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	out := make([]reviewItem, 0, len(list))
+	for _, v := range list {
+		item := reviewItem{Verification: v}
+		if v.DocumentKey != nil {
+			if u, err := h.store.SignedURL(r.Context(), *v.DocumentKey, documentURLTTL); err != nil {
+				slog.ErrorContext(r.Context(), "business: sign document url failed", "user_id", v.UserID, "err", err)
+			} else {
+				item.DocumentURL = &u
+			}
+		}
+		out = append(out, item)
+	}
+	response.OK(w, out)
+}
+
+// Approve godoc
+//
+//	@Summary		Approve business verification
+//	@Description	Approve a pending business verification, grant the verified badge and notify the business. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			userId	path		string	true	"User ID"
+//	@Success		200		{object}	response.Envelope{data=Verification}
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/business-verifications/{userId}/approve [post]
+func (h *Handler) Approve(w http.ResponseWriter, r *http.Request) {
+	reviewerID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || reviewerID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	v, err := h.svc.Approve(r.Context(), reviewerID, chi.URLParam(r, "userId"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, v)
+}
+
+type rejectRequest struct {
+	Reason string `json:"reason" example:"License number does not match the legal name"`
+}
+
+// Reject godoc
+//
+//	@Summary		Reject business verification
+//	@Description	Reject a pending business verification with a reason shown to the business, and notify them. The business may resubmit. Admin only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			userId	path		string			true	"User ID"
+//	@Param			request	body		rejectRequest	true	"Reason"
+//	@Success		200		{object}	response.Envelope{data=Verification}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/business-verifications/{userId}/reject [post]
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	reviewerID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || reviewerID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req rejectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || len([]rune(req.Reason)) > 255 {
+		response.BadRequest(w, "reason is required and must be 255 characters or fewer")
+		return
+	}
+
+	v, err := h.svc.Reject(r.Context(), reviewerID, chi.URLParam(r, "userId"), req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, v)
+}
+
+// businessID returns the authenticated user ID, writing an error response when
+// the caller is not authenticated or not a business account.
+func businessID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return "", false
+	}
+	if accountType, _ := r.Context().Value(middleware.UserAccountTypeKey).(string); accountType != "business" {
+		response.Forbidden(w, "business verification is available to business accounts only")
+		return "", false
+	}
+	return userID, true
+}
+
+// writeError maps business verification errors to responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "business verification not found; submit your business details first")
+	case errors.Is(err, ErrInvalidStatus):
+		response.BadRequest(w, "status must be one of: pending, approved, rejected")
+	case errors.Is(err, ErrAlreadyApproved):
+		response.Conflict(w, "business already verified")
+	case errors.Is(err, ErrNotPending):
+		response.Conflict(w, "verification is not pending review")
+	default:
+		response.InternalError(w)
+	}
+}
+
+// documentKey creates a collision-resistant object key for a license document.
+// Format: "business/{userID}/{16-byte-hex}{ext}"
+func documentKey(userID, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate key: %w", err)
+	}
+	return fmt.Sprintf("business/%s/%x%s", userID, b, ext), nil
+}
@@ -0,0 +1,233 @@
+// Package business runs the verification flow for business accounts: the
+// business submits its legal name and license, staff review it, and approval
+// grants the verified badge shown on public profiles.
+package business
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Verification statuses.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// Verification is a business account's verification record.
+type Verification struct {
+	UserID          string     `json:"userId"`
+	LegalName       string     `json:"legalName"     example:"Radif Pardaz Co."`
+	LicenseNumber   string     `json:"licenseNumber" example:"14001234567"`
+	Status          string     `json:"status"        example:"pending"`
+	DocumentKey     *string    `json:"-"`
+	HasDocument     bool       `json:"hasDocument"`
+	RejectionReason *string    `json:"rejectionReason,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewedAt,omitempty"`
+	SubmittedAt     time.Time  `json:"submittedAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// ErrNotFound is returned when the business has not submitted verification.
+var ErrNotFound = errors.New("business verification not found")
+
+// ErrAlreadyApproved is returned when changing an approved verification.
+var ErrAlreadyApproved = errors.New("business already verified")
+
+// ErrNotPending is returned when reviewing a verification that is not pending.
+var ErrNotPending = errors.New("business verification is not pending review")
+
+// Repository handles business verification persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new business verification Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const selectCols = `user_id, legal_name, license_number, status, document_key,
+	rejection_reason, reviewed_at, submitted_at, updated_at`
+
+// scanVerification scans a selectCols row into a Verification value.
+func scanVerification(row pgx.Row, v *Verification) error {
+	if err := row.Scan(
+		&v.UserID, &v.LegalName, &v.LicenseNumber, &v.Status, &v.DocumentKey,
+		&v.RejectionReason, &v.ReviewedAt, &v.SubmittedAt, &v.UpdatedAt,
+	); err != nil {
+		return err
+	}
+	v.HasDocument = v.DocumentKey != nil
+	return nil
+}
+
+// Get returns the business's verification.
+func (r *Repository) Get(ctx context.Context, userID string) (*Verification, error) {
+	v := &Verification{}
+	err := scanVerification(r.db.QueryRow(ctx,
+		`SELECT `+selectCols+` FROM business_verifications WHERE user_id = $1`, userID,
+	), v)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get business verification: %w", err)
+	}
+	return v, nil
+}
+
+// Submit creates or replaces the business's submission and puts it back in
+// the review queue. An uploaded document is kept. Approved verifications
+// cannot be replaced.
+func (r *Repository) Submit(ctx context.Context, userID, legalName, licenseNumber string) (*Verification, error) {
+	v := &Verification{}
+	err := scanVerification(r.db.QueryRow(ctx,
+		`INSERT INTO business_verifications (user_id, legal_name, license_number)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id) DO UPDATE SET
+		     legal_name       = EXCLUDED.legal_name,
+		     license_number   = EXCLUDED.license_number,
+		     status           = 'pending',
+		     rejection_reason = NULL,
+		     reviewed_by      = NULL,
+		     reviewed_at      = NULL,
+		     submitted_at     = NOW()
+		 WHERE business_verifications.status <> 'approved'
+		 RETURNING `+selectCols,
+		userID, legalName, licenseNumber,
+	), v)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAlreadyApproved
+		}
+		return nil, fmt.Errorf("submit business verification: %w", err)
+	}
+	return v, nil
+}
+
+// SetDocument attaches a license document and re-queues the submission for
+// review. It returns the replaced document key, if any, so the caller can
+// delete it.
+func (r *Repository) SetDocument(ctx context.Context, userID, key string) (*Verification, *string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var status string
+	var previous *string
+	err = tx.QueryRow(ctx,
+		`SELECT status, document_key FROM business_verifications WHERE user_id = $1 FOR UPDATE`, userID,
+	).Scan(&status, &previous)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("lock business verification: %w", err)
+	}
+	if status == StatusApproved {
+		return nil, nil, ErrAlreadyApproved
+	}
+
+	v := &Verification{}
+	err = scanVerification(tx.QueryRow(ctx,
+		`UPDATE business_verifications SET
+		     document_key = $2, status = 'pending', rejection_reason = NULL,
+		     reviewed_by = NULL, reviewed_at = NULL, submitted_at = NOW()
+		 WHERE user_id = $1
+		 RETURNING `+selectCols,
+		userID, key,
+	), v)
+	if err != nil {
+		return nil, nil, fmt.Errorf("set business document: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return v, previous, nil
+}
+
+// ListByStatus returns verifications with the given status, oldest submission
+// first.
+func (r *Repository) ListByStatus(ctx context.Context, status string, limit, offset int) ([]*Verification, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+selectCols+` FROM business_verifications
+		 WHERE status = $1
+		 ORDER BY submitted_at, user_id
+		 LIMIT $2 OFFSET $3`,
+		status, limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list business verifications: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Verification{}
+	for rows.Next() {
+		v := &Verification{}
+		if err := scanVerification(rows, v); err != nil {
+			return nil, fmt.Errorf("scan business verification: %w", err)
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// Review records a staff decision on a pending verification. Approval also
+// sets the user's verified badge, in the same transaction.
+func (r *Repository) Review(ctx context.Context, userID, reviewerID, status string, reason *string) (*Verification, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	v := &Verification{}
+	err = scanVerification(tx.QueryRow(ctx,
+		`UPDATE business_verifications SET
+		     status = $3, rejection_reason = $4, reviewed_by = $2, reviewed_at = NOW()
+		 WHERE user_id = $1 AND status = 'pending'
+		 RETURNING `+selectCols,
+		userID, reviewerID, status, reason,
+	), v)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, getErr := r.Get(ctx, userID); getErr != nil {
+				return nil, getErr
+			}
+			return nil, ErrNotPending
+		}
+		if isInvalidID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("review business verification: %w", err)
+	}
+
+	if status == StatusApproved {
+		if _, err := tx.Exec(ctx, `UPDATE users SET verified_at = NOW() WHERE id = $1`, userID); err != nil {
+			return nil, fmt.Errorf("set verified badge: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return v, nil
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
@@ -0,0 +1,81 @@
+package business
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/radif/service/internal/notification"
+)
+
+// ErrInvalidStatus is returned for unknown verification statuses.
+var ErrInvalidStatus = errors.New("invalid status")
+
+// Service contains business logic for business verification.
+type Service struct {
+	repo     *Repository
+	notifier *notification.Service
+}
+
+// NewService creates a new business verification Service.
+func NewService(repo *Repository, notifier *notification.Service) *Service {
+	return &Service{repo: repo, notifier: notifier}
+}
+
+// Get returns the business's verification.
+func (s *Service) Get(ctx context.Context, userID string) (*Verification, error) {
+	return s.repo.Get(ctx, userID)
+}
+
+// Submit stores the business's legal name and license number and queues them
+// for review.
+func (s *Service) Submit(ctx context.Context, userID, legalName, licenseNumber string) (*Verification, error) {
+	return s.repo.Submit(ctx, userID, legalName, licenseNumber)
+}
+
+// SetDocument records an uploaded license document and re-queues the
+// submission. It returns the key of the document it replaced, if any.
+func (s *Service) SetDocument(ctx context.Context, userID, key string) (*Verification, *string, error) {
+	return s.repo.SetDocument(ctx, userID, key)
+}
+
+// Queue lists verifications by status for staff, oldest first.
+func (s *Service) Queue(ctx context.Context, status string, limit, offset int) ([]*Verification, error) {
+	if status != StatusPending && status != StatusApproved && status != StatusRejected {
+		return nil, ErrInvalidStatus
+	}
+	return s.repo.ListByStatus(ctx, status, limit, offset)
+}
+
+// Approve marks a pending verification approved, grants the verified badge
+// and notifies the business.
+func (s *Service) Approve(ctx context.Context, reviewerID, userID string) (*Verification, error) {
+	v, err := s.repo.Review(ctx, userID, reviewerID, StatusApproved, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(ctx, userID, "کسب‌وکار شما تأیید شد", "نشان تأیید اکنون روی پروفایل کسب‌وکار شما نمایش داده می‌شود.")
+	return v, nil
+}
+
+// Reject marks a pending verification rejected with a reason shown to the
+// business, and notifies them.
+func (s *Service) Reject(ctx context.Context, reviewerID, userID, reason string) (*Verification, error) {
+	v, err := s.repo.Review(ctx, userID, reviewerID, StatusRejected, &reason)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(ctx, userID, "تأیید کسب‌وکار رد شد", "درخواست تأیید کسب‌وکار شما رد شد: "+reason)
+	return v, nil
+}
+
+// notify sends the review outcome; failures are logged, not returned.
+func (s *Service) notify(ctx context.Context, userID, title, body string) {
+	if _, err := s.notifier.Notify(ctx, userID, notification.Message{
+		Type:  notification.TypeBusinessReviewed,
+		Title: title,
+		Body:  body,
+	}); err != nil {
+		slog.ErrorContext(ctx, "business: notify review failed", "user_id", userID, "err", err)
+	}
+}
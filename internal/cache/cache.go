@@ -0,0 +1,212 @@
+// Package cache keeps short-lived state that every replica must share —
+// cached reads, counters and flags — in Redis. Redis is never the source of
+// truth: when it is unreachable lookups miss, writes are dropped and callers
+// carry on against the database.
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// opTimeout bounds each call so a slow Redis costs a request little more
+	// than a miss.
+	opTimeout = 250 * time.Millisecond
+	// bypassFor is how long Redis is skipped after a failed call, so an
+	// outage does not add a timeout to every request.
+	bypassFor = 5 * time.Second
+)
+
+// ErrUnavailable is returned when Redis is not configured or not reachable.
+var ErrUnavailable = errors.New("cache unavailable")
+
+// Cache is a Redis-backed cache. A nil *Cache is valid and always
+// unavailable, so callers need no separate path for deployments without Redis.
+type Cache struct {
+	client *redis.Client
+	prefix string
+
+	// downUntil is the Unix nano time until which Redis is bypassed.
+	downUntil atomic.Int64
+}
+
+// Open connects to the Redis server at url (redis://[:password@]host:port/db).
+// Keys are namespaced with prefix. An unreachable server is not an error: the
+// cache starts bypassed and is retried as calls come in.
+func Open(url, prefix string) (*Cache, error) {
+	opts, err := redis.ParseURL(url)
+	if err != nil {
+		return nil, fmt.Errorf("parse redis url: %w", err)
+	}
+	c := &Cache{client: redis.NewClient(opts), prefix: prefix}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.client.Ping(ctx).Err(); err != nil {
+		c.fail(err)
+	}
+	return c, nil
+}
+
+// Client returns the underlying Redis client, for components that talk to
+// Redis directly, or nil when the cache is nil.
+func (c *Cache) Client() *redis.Client {
+	if c == nil {
+		return nil
+	}
+	return c.client
+}
+
+// Close closes the Redis connection pool.
+func (c *Cache) Close() error {
+	if c == nil {
+		return nil
+	}
+	return c.client.Close()
+}
+
+// Get decodes the value at key into dst and reports whether it was found.
+// Misses, outages and undecodable values all report false.
+func (c *Cache) Get(ctx context.Context, key string, dst any) bool {
+	if !c.available() {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	b, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false
+	}
+	if err != nil {
+		c.fail(err)
+		return false
+	}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(dst); err != nil {
+		// Left over from an older shape of the value; it expires on its own.
+		slog.WarnContext(ctx, "cache: decode failed", "key", key, "err", err)
+		return false
+	}
+	return true
+}
+
+// Set stores v at key for ttl. Values are gob-encoded, so fields hidden from
+// JSON survive the round trip. Failures are logged and otherwise ignored.
+func (c *Cache) Set(ctx context.Context, key string, v any, ttl time.Duration) {
+	if !c.available() {
+		return
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		slog.ErrorContext(ctx, "cache: encode failed", "key", key, "err", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, c.prefix+key, buf.Bytes(), ttl).Err(); err != nil {
+		c.fail(err)
+	}
+}
+
+// Delete removes keys. Callers invalidating entries should also keep their
+// TTLs short, since a delete is lost while Redis is unreachable.
+func (c *Cache) Delete(ctx context.Context, keys ...string) {
+	if len(keys) == 0 || !c.available() {
+		return
+	}
+	full := make([]string, len(keys))
+	for i, k := range keys {
+		full[i] = c.prefix + k
+	}
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	if err := c.client.Del(ctx, full...).Err(); err != nil {
+		c.fail(err)
+	}
+}
+
+// Incr increments the counter at key and returns its new value. The counter
+// is created with a lifetime of window, so it counts events in a fixed window
+// starting at the first one.
+func (c *Cache) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	if !c.available() {
+		return 0, ErrUnavailable
+	}
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	n, err := incrScript.Run(ctx, c.client, []string{c.prefix + key}, window.Milliseconds()).Int64()
+	if err != nil {
+		c.fail(err)
+		return 0, ErrUnavailable
+	}
+	return n, nil
+}
+
+// incrScript increments KEYS[1], setting a TTL of ARGV[1] milliseconds when
+// it creates the counter.
+var incrScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// SetFlag marks key as present for ttl.
+func (c *Cache) SetFlag(ctx context.Context, key string, ttl time.Duration) error {
+	if !c.available() {
+		return ErrUnavailable
+	}
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	if err := c.client.Set(ctx, c.prefix+key, 1, ttl).Err(); err != nil {
+		c.fail(err)
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// HasFlag reports whether key is present.
+func (c *Cache) HasFlag(ctx context.Context, key string) (bool, error) {
+	if !c.available() {
+		return false, ErrUnavailable
+	}
+	ctx, cancel := context.WithTimeout(ctx, opTimeout)
+	defer cancel()
+
+	n, err := c.client.Exists(ctx, c.prefix+key).Result()
+	if err != nil {
+		c.fail(err)
+		return false, ErrUnavailable
+	}
+	return n > 0, nil
+}
+
+// available reports whether Redis should be tried.
+func (c *Cache) available() bool {
+	return c != nil && time.Now().UnixNano() >= c.downUntil.Load()
+}
+
+// fail bypasses Redis for bypassFor, logging only when it was up, so an
+// outage logs once per interval rather than once per call.
+func (c *Cache) fail(err error) {
+	now := time.Now().UnixNano()
+	until := c.downUntil.Load()
+	if now < until || !c.downUntil.CompareAndSwap(until, now+int64(bypassFor)) {
+		return
+	}
+	slog.Warn("cache: redis unavailable, bypassing", "for", bypassFor, "err", err)
+}
@@ -0,0 +1,194 @@
+package category
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/i18n"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for category endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new category Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// List godoc
+//
+//	@Summary		List business categories
+//	@Description	Returns active business categories. The name field is localized from Accept-Language (fa or en; default fa).
+//	@Tags			categories
+//	@Produce		json
+//	@Param			Accept-Language	header		string	false	"Preferred language (fa, en)"
+//	@Success		200				{object}	response.Envelope{data=[]Category}
+//	@Failure		500				{object}	response.Envelope
+//	@Router			/categories [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	cats, err := h.svc.ListActive(r.Context(), i18n.PreferredLanguage(r.Header.Get("Accept-Language")))
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, cats)
+}
+
+// AdminList godoc
+//
+//	@Summary		List all business categories (admin)
+//	@Description	Returns every business category, including inactive ones.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]Category}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/admin/categories [get]
+func (h *Handler) AdminList(w http.ResponseWriter, r *http.Request) {
+	cats, err := h.svc.ListAll(r.Context(), i18n.PreferredLanguage(r.Header.Get("Accept-Language")))
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, cats)
+}
+
+type createRequest struct {
+	Code       string  `json:"code"       example:"5812"`
+	ParentCode *string `json:"parentCode"`
+	NameFa     string  `json:"nameFa"     example:"رستوران"`
+	NameEn     string  `json:"nameEn"     example:"Restaurants"`
+	IsActive   *bool   `json:"isActive"`
+	SortOrder  int     `json:"sortOrder"`
+}
+
+// Create godoc
+//
+//	@Summary		Create business category (admin)
+//	@Description	Add a category. Codes are 2-10 characters of digits, uppercase letters or underscores; use the ISO 18245 MCC where one exists.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		createRequest	true	"Category"
+//	@Success		201		{object}	response.Envelope{data=Category}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/categories [post]
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	c := &Category{
+		Code:       req.Code,
+		ParentCode: req.ParentCode,
+		NameFa:     req.NameFa,
+		NameEn:     req.NameEn,
+		IsActive:   req.IsActive == nil || *req.IsActive,
+		SortOrder:  req.SortOrder,
+	}
+	out, err := h.svc.Create(r.Context(), c, i18n.PreferredLanguage(r.Header.Get("Accept-Language")))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.Created(w, out)
+}
+
+type updateRequest struct {
+	ParentCode *string `json:"parentCode"`
+	NameFa     *string `json:"nameFa"`
+	NameEn     *string `json:"nameEn"`
+	IsActive   *bool   `json:"isActive"`
+	SortOrder  *int    `json:"sortOrder"`
+}
+
+// Update godoc
+//
+//	@Summary		Update business category (admin)
+//	@Description	Partially update a category. Deactivating hides it from GET /categories without detaching existing businesses.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			code	path		string			true	"Category code"
+//	@Param			request	body		updateRequest	true	"Fields to update"
+//	@Success		200		{object}	response.Envelope{data=Category}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/categories/{code} [patch]
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	var req updateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	out, err := h.svc.Update(r.Context(), chi.URLParam(r, "code"), UpdateParams{
+		ParentCode: req.ParentCode,
+		NameFa:     req.NameFa,
+		NameEn:     req.NameEn,
+		IsActive:   req.IsActive,
+		SortOrder:  req.SortOrder,
+	}, i18n.PreferredLanguage(r.Header.Get("Accept-Language")))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	response.OK(w, out)
+}
+
+// Delete godoc
+//
+//	@Summary		Delete business category (admin)
+//	@Description	Remove a category. Businesses and subcategories that referenced it are left uncategorized.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			code	path		string	true	"Category code"
+//	@Success		200		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/categories/{code} [delete]
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Delete(r.Context(), chi.URLParam(r, "code")); err != nil {
+		writeError(w, err)
+		return
+	}
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// writeError maps service errors to HTTP responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidCategory):
+		response.BadRequest(w, "invalid category: code must be 2-10 of [0-9A-Z_] and names 1-100 characters")
+	case errors.Is(err, ErrUnknownParent):
+		response.BadRequest(w, "unknown parent category")
+	case errors.Is(err, ErrAlreadyExists):
+		response.Conflict(w, "category code already exists")
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "category not found")
+	default:
+		response.InternalError(w)
+	}
+}
@@ -0,0 +1,178 @@
+// Package category manages the business category taxonomy (MCC-like codes).
+package category
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Category is a node in the business category taxonomy.
+// Name is the localized display name, filled in by the service.
+type Category struct {
+	Code       string    `json:"code"`
+	ParentCode *string   `json:"parentCode,omitempty"`
+	Name       string    `json:"name"`
+	NameFa     string    `json:"nameFa"`
+	NameEn     string    `json:"nameEn"`
+	IsActive   bool      `json:"isActive"`
+	SortOrder  int       `json:"sortOrder"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// UpdateParams holds optional fields for a category update.
+// Nil pointer means "do not change".
+type UpdateParams struct {
+	ParentCode *string
+	NameFa     *string
+	NameEn     *string
+	IsActive   *bool
+	SortOrder  *int
+}
+
+// ErrNotFound is returned when a category does not exist.
+var ErrNotFound = errors.New("category not found")
+
+// ErrAlreadyExists is returned when a category code is already taken.
+var ErrAlreadyExists = errors.New("category already exists")
+
+// ErrUnknownParent is returned when parentCode does not reference an existing category.
+var ErrUnknownParent = errors.New("unknown parent category")
+
+// Repository handles category persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new category Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const selectCols = `code, parent_code, name_fa, name_en, is_active, sort_order, created_at, updated_at`
+
+// scanCategory scans a full business_categories row into a Category value.
+func scanCategory(row pgx.Row, c *Category) error {
+	return row.Scan(
+		&c.Code, &c.ParentCode, &c.NameFa, &c.NameEn,
+		&c.IsActive, &c.SortOrder, &c.CreatedAt, &c.UpdatedAt,
+	)
+}
+
+// List returns categories ordered for display. When activeOnly is true,
+// deactivated categories are omitted.
+func (r *Repository) List(ctx context.Context, activeOnly bool) ([]*Category, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+selectCols+` FROM business_categories
+		 WHERE is_active OR NOT $1
+		 ORDER BY sort_order, code`,
+		activeOnly,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list categories: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Category{}
+	for rows.Next() {
+		c := &Category{}
+		if err := scanCategory(rows, c); err != nil {
+			return nil, fmt.Errorf("scan category: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// Get returns a category by code.
+func (r *Repository) Get(ctx context.Context, code string) (*Category, error) {
+	c := &Category{}
+	err := scanCategory(r.db.QueryRow(ctx,
+		`SELECT `+selectCols+` FROM business_categories WHERE code = $1`, code,
+	), c)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get category: %w", err)
+	}
+	return c, nil
+}
+
+// Create inserts a new category.
+func (r *Repository) Create(ctx context.Context, c *Category) (*Category, error) {
+	out := &Category{}
+	err := scanCategory(r.db.QueryRow(ctx,
+		`INSERT INTO business_categories (code, parent_code, name_fa, name_en, is_active, sort_order)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING `+selectCols,
+		c.Code, c.ParentCode, c.NameFa, c.NameEn, c.IsActive, c.SortOrder,
+	), out)
+	if err != nil {
+		switch {
+		case isUniqueViolation(err):
+			return nil, ErrAlreadyExists
+		case isForeignKeyViolation(err):
+			return nil, ErrUnknownParent
+		}
+		return nil, fmt.Errorf("create category: %w", err)
+	}
+	return out, nil
+}
+
+// Update applies non-nil fields in p to the category.
+func (r *Repository) Update(ctx context.Context, code string, p UpdateParams) (*Category, error) {
+	out := &Category{}
+	err := scanCategory(r.db.QueryRow(ctx,
+		`UPDATE business_categories SET
+		     parent_code = COALESCE($2, parent_code),
+		     name_fa     = COALESCE($3, name_fa),
+		     name_en     = COALESCE($4, name_en),
+		     is_active   = COALESCE($5, is_active),
+		     sort_order  = COALESCE($6, sort_order)
+		 WHERE code = $1
+		 RETURNING `+selectCols,
+		code, p.ParentCode, p.NameFa, p.NameEn, p.IsActive, p.SortOrder,
+	), out)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if isForeignKeyViolation(err) {
+			return nil, ErrUnknownParent
+		}
+		return nil, fmt.Errorf("update category: %w", err)
+	}
+	return out, nil
+}
+
+// Delete removes a category. Businesses and child categories referencing it
+// are detached (ON DELETE SET NULL).
+func (r *Repository) Delete(ctx context.Context, code string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM business_categories WHERE code = $1`, code)
+	if err != nil {
+		return fmt.Errorf("delete category: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// isUniqueViolation checks whether an error is a PostgreSQL unique_violation (code 23505).
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isForeignKeyViolation checks whether an error is a PostgreSQL foreign_key_violation (code 23503).
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
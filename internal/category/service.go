@@ -0,0 +1,116 @@
+package category
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/radif/service/internal/i18n"
+)
+
+var codeRegex = regexp.MustCompile(`^[0-9A-Z_]{2,10}$`)
+
+// ErrInvalidCategory is returned when category fields fail validation.
+var ErrInvalidCategory = errors.New("invalid category")
+
+// Service contains business logic for the category taxonomy.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new category Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// ListActive returns the active categories localized for lang.
+func (s *Service) ListActive(ctx context.Context, lang string) ([]*Category, error) {
+	cats, err := s.repo.List(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cats {
+		localize(c, lang)
+	}
+	return cats, nil
+}
+
+// ListAll returns every category, including inactive ones, localized for lang.
+func (s *Service) ListAll(ctx context.Context, lang string) ([]*Category, error) {
+	cats, err := s.repo.List(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range cats {
+		localize(c, lang)
+	}
+	return cats, nil
+}
+
+// Create validates and stores a new category.
+func (s *Service) Create(ctx context.Context, c *Category, lang string) (*Category, error) {
+	c.NameFa = strings.TrimSpace(c.NameFa)
+	c.NameEn = strings.TrimSpace(c.NameEn)
+	if !codeRegex.MatchString(c.Code) || !validName(c.NameFa) || !validName(c.NameEn) {
+		return nil, ErrInvalidCategory
+	}
+	if c.ParentCode != nil && *c.ParentCode == c.Code {
+		return nil, ErrInvalidCategory
+	}
+
+	out, err := s.repo.Create(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	localize(out, lang)
+	return out, nil
+}
+
+// Update validates and applies a partial category update.
+func (s *Service) Update(ctx context.Context, code string, p UpdateParams, lang string) (*Category, error) {
+	if p.NameFa != nil {
+		v := strings.TrimSpace(*p.NameFa)
+		if !validName(v) {
+			return nil, ErrInvalidCategory
+		}
+		p.NameFa = &v
+	}
+	if p.NameEn != nil {
+		v := strings.TrimSpace(*p.NameEn)
+		if !validName(v) {
+			return nil, ErrInvalidCategory
+		}
+		p.NameEn = &v
+	}
+	if p.ParentCode != nil && *p.ParentCode == code {
+		return nil, ErrInvalidCategory
+	}
+
+	out, err := s.repo.Update(ctx, code, p)
+	if err != nil {
+		return nil, err
+	}
+	localize(out, lang)
+	return out, nil
+}
+
+// Delete removes a category.
+func (s *Service) Delete(ctx context.Context, code string) error {
+	return s.repo.Delete(ctx, code)
+}
+
+// validName reports whether a display name is non-empty and at most 100 characters.
+func validName(s string) bool {
+	n := len([]rune(s))
+	return n > 0 && n <= 100
+}
+
+// localize sets Name from the language preference; Persian is the default.
+func localize(c *Category, lang string) {
+	if lang == i18n.LangEn {
+		c.Name = c.NameEn
+		return
+	}
+	c.Name = c.NameFa
+}
@@ -0,0 +1,76 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	arvanDefaultAPI = "https://napi.arvancloud.ir/cdn/4.0"
+	arvanTimeout    = 10 * time.Second
+)
+
+// ArvanCloud purges URLs through the ArvanCloud CDN API.
+type ArvanCloud struct {
+	apiBase string
+	apiKey  string
+	domain  string
+	client  *http.Client
+}
+
+// NewArvanCloud creates an ArvanCloud purger for the CDN domain (e.g.
+// "radif.ir"). apiKey is a machine user API key, with or without its
+// "Apikey " prefix. An empty apiBase uses the public API.
+func NewArvanCloud(apiBase, apiKey, domain string) *ArvanCloud {
+	if apiBase == "" {
+		apiBase = arvanDefaultAPI
+	}
+	if !strings.HasPrefix(apiKey, "Apikey ") {
+		apiKey = "Apikey " + apiKey
+	}
+	return &ArvanCloud{
+		apiBase: strings.TrimRight(apiBase, "/"),
+		apiKey:  apiKey,
+		domain:  domain,
+		client:  &http.Client{Timeout: arvanTimeout},
+	}
+}
+
+type arvanPurgeRequest struct {
+	Purge     string   `json:"purge"`
+	PurgeURLs []string `json:"purge_urls"`
+}
+
+// Purge implements Purger.
+func (a *ArvanCloud) Purge(ctx context.Context, urls []string) error {
+	body, err := json.Marshal(arvanPurgeRequest{Purge: "individual", PurgeURLs: urls})
+	if err != nil {
+		return fmt.Errorf("encode purge request: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/domains/%s/caching/purge", a.apiBase, url.PathEscape(a.domain))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build purge request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", a.apiKey)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("call arvancloud: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("arvancloud returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
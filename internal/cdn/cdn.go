@@ -0,0 +1,116 @@
+// Package cdn drops replaced objects from the CDN cache so clients stop
+// seeing stale images once an avatar changes or is taken down.
+package cdn
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	flushInterval = 5 * time.Second
+	// maxPurgeBatch is how many URLs are sent per purge request.
+	maxPurgeBatch = 50
+	// maxAttempts is how many times a URL is purged before it is dropped.
+	maxAttempts = 3
+	// maxPendingURLs caps memory when the provider is down; invalidations
+	// beyond it are dropped and those objects expire from the cache by TTL.
+	maxPendingURLs = 10000
+)
+
+// Purger removes URLs from a CDN cache.
+type Purger interface {
+	Purge(ctx context.Context, urls []string) error
+}
+
+// Invalidator collects object keys to purge and sends them to a Purger in
+// batches, so callers add no provider round-trip to the request path.
+type Invalidator struct {
+	purger  Purger
+	cdnBase string
+
+	mu      sync.Mutex
+	pending map[string]int // URL → failed attempts
+}
+
+// NewInvalidator creates an Invalidator for objects served under cdnBase.
+func NewInvalidator(purger Purger, cdnBase string) *Invalidator {
+	return &Invalidator{
+		purger:  purger,
+		cdnBase: strings.TrimRight(cdnBase, "/"),
+		pending: make(map[string]int),
+	}
+}
+
+// Invalidate queues the CDN URLs of keys for purging. Every key is purged,
+// whatever its rollout status, since it may have been served from the CDN
+// before the rollout changed.
+func (inv *Invalidator) Invalidate(keys ...string) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	for _, key := range keys {
+		if key == "" || len(inv.pending) >= maxPendingURLs {
+			continue
+		}
+		inv.pending[inv.cdnBase+"/"+key] = 0
+	}
+}
+
+// Run purges queued URLs every flushInterval until ctx is cancelled, then
+// makes a final attempt.
+func (inv *Invalidator) Run(ctx context.Context) {
+	slog.Info("cdn invalidator started")
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Use a fresh context so the final purge survives shutdown.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			inv.flush(flushCtx)
+			cancel()
+			slog.Info("cdn invalidator stopped")
+			return
+		case <-ticker.C:
+			inv.flush(ctx)
+		}
+	}
+}
+
+// flush swaps out the pending URLs and purges them, re-queueing failed
+// batches until they reach maxAttempts.
+func (inv *Invalidator) flush(ctx context.Context) {
+	inv.mu.Lock()
+	batch := inv.pending
+	inv.pending = make(map[string]int)
+	inv.mu.Unlock()
+
+	urls := make([]string, 0, len(batch))
+	for u := range batch {
+		urls = append(urls, u)
+	}
+	for len(urls) > 0 {
+		n := min(len(urls), maxPurgeBatch)
+		chunk := urls[:n]
+		urls = urls[n:]
+
+		err := inv.purger.Purge(ctx, chunk)
+		if err == nil {
+			continue
+		}
+		slog.WarnContext(ctx, "cdn invalidator: purge failed", "urls", len(chunk), "err", err)
+		inv.mu.Lock()
+		for _, u := range chunk {
+			if attempts := batch[u] + 1; attempts < maxAttempts {
+				if _, queued := inv.pending[u]; !queued && len(inv.pending) < maxPendingURLs {
+					inv.pending[u] = attempts
+				}
+			}
+		}
+		inv.mu.Unlock()
+	}
+}
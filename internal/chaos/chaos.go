@@ -0,0 +1,188 @@
+// Package chaos injects latency and errors into calls to external
+// dependencies (database, object storage, SMS) so retries, failover and
+// idempotency can be exercised before a real outage does it. It is for
+// development and staging only; main refuses to enable it in production.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Dependency targets faults can be configured for.
+const (
+	TargetDB      = "db"
+	TargetStorage = "storage"
+	TargetSMS     = "sms"
+)
+
+// targets lists every valid target.
+var targets = map[string]bool{TargetDB: true, TargetStorage: true, TargetSMS: true}
+
+// ErrInjected is returned by calls failed on purpose.
+var ErrInjected = errors.New("chaos: injected fault")
+
+// ErrInvalidFault is returned for unknown targets or out-of-range values.
+var ErrInvalidFault = errors.New("invalid fault")
+
+// Fault describes what happens to calls to one target. Each call is delayed
+// by LatencyMs with probability LatencyPercent, then failed with probability
+// ErrorPercent.
+type Fault struct {
+	LatencyMs      int `json:"latencyMs"      example:"500"`
+	LatencyPercent int `json:"latencyPercent" example:"20"`
+	ErrorPercent   int `json:"errorPercent"   example:"5"`
+}
+
+// validate checks that percentages are 0-100 and latency is non-negative.
+func (f Fault) validate() error {
+	if f.LatencyMs < 0 || f.LatencyPercent < 0 || f.LatencyPercent > 100 ||
+		f.ErrorPercent < 0 || f.ErrorPercent > 100 {
+		return ErrInvalidFault
+	}
+	return nil
+}
+
+// Injector holds the active faults. It is safe for concurrent use, and faults
+// can be replaced at runtime.
+type Injector struct {
+	mu     sync.RWMutex
+	faults map[string]Fault
+}
+
+// New creates an Injector with the given initial faults.
+func New(faults map[string]Fault) (*Injector, error) {
+	inj := &Injector{}
+	if err := inj.Set(faults); err != nil {
+		return nil, err
+	}
+	return inj, nil
+}
+
+// Faults returns a copy of the active faults.
+func (i *Injector) Faults() map[string]Fault {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	out := make(map[string]Fault, len(i.faults))
+	for t, f := range i.faults {
+		out[t] = f
+	}
+	return out
+}
+
+// Set replaces all active faults. Targets left out run normally.
+func (i *Injector) Set(faults map[string]Fault) error {
+	next := make(map[string]Fault, len(faults))
+	for t, f := range faults {
+		if !targets[t] {
+			return fmt.Errorf("%w: unknown target %q", ErrInvalidFault, t)
+		}
+		if err := f.validate(); err != nil {
+			return fmt.Errorf("%w for %s", err, t)
+		}
+		next[t] = f
+	}
+	i.mu.Lock()
+	i.faults = next
+	i.mu.Unlock()
+	return nil
+}
+
+// Inject applies target's fault to one call: it may sleep (returning early if
+// ctx ends) and may return ErrInjected. A nil Injector does nothing.
+func (i *Injector) Inject(ctx context.Context, target string) error {
+	if i == nil {
+		return nil
+	}
+	i.mu.RLock()
+	f, ok := i.faults[target]
+	i.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+
+	if f.LatencyMs > 0 && roll(f.LatencyPercent) {
+		t := time.NewTimer(time.Duration(f.LatencyMs) * time.Millisecond)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if roll(f.ErrorPercent) {
+		return fmt.Errorf("%w (%s)", ErrInjected, target)
+	}
+	return nil
+}
+
+// roll returns true with the given percent probability.
+func roll(percent int) bool {
+	return percent > 0 && rand.IntN(100) < percent
+}
+
+// Parse reads a fault spec of the form
+//
+//	db:latency_ms=200,latency_pct=10,error_pct=1;storage:error_pct=5
+//
+// as used by the CHAOS_FAULTS environment variable.
+func Parse(spec string) (map[string]Fault, error) {
+	out := make(map[string]Fault)
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		target, params, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("%w: %q is not target:key=value", ErrInvalidFault, entry)
+		}
+		var f Fault
+		for _, kv := range strings.Split(params, ",") {
+			k, v, ok := strings.Cut(strings.TrimSpace(kv), "=")
+			if !ok {
+				return nil, fmt.Errorf("%w: %q is not key=value", ErrInvalidFault, kv)
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be an integer", ErrInvalidFault, k)
+			}
+			switch k {
+			case "latency_ms":
+				f.LatencyMs = n
+			case "latency_pct":
+				f.LatencyPercent = n
+			case "error_pct":
+				f.ErrorPercent = n
+			default:
+				return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidFault, k)
+			}
+		}
+		out[strings.TrimSpace(target)] = f
+	}
+	return out, nil
+}
+
+// Describe renders faults in the Parse format, for startup logging.
+func Describe(faults map[string]Fault) string {
+	names := make([]string, 0, len(faults))
+	for t := range faults {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, t := range names {
+		f := faults[t]
+		parts = append(parts, fmt.Sprintf("%s:latency_ms=%d,latency_pct=%d,error_pct=%d",
+			t, f.LatencyMs, f.LatencyPercent, f.ErrorPercent))
+	}
+	return strings.Join(parts, ";")
+}
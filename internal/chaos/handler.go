@@ -0,0 +1,60 @@
+package chaos
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for the fault injection admin endpoints.
+type Handler struct {
+	inj *Injector
+}
+
+// NewHandler creates a new chaos Handler.
+func NewHandler(inj *Injector) *Handler {
+	return &Handler{inj: inj}
+}
+
+// Get godoc
+//
+//	@Summary		Get injected faults
+//	@Description	Returns the active fault injection settings per dependency (db, storage, sms). Only mounted outside production when CHAOS_ENABLED is set. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=map[string]Fault}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Router			/admin/chaos [get]
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, h.inj.Faults())
+}
+
+// Set godoc
+//
+//	@Summary		Set injected faults
+//	@Description	Replaces the active fault injection settings. Dependencies left out run normally; send {} to stop injecting. Admin only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		map[string]Fault	true	"Faults by dependency"
+//	@Success		200		{object}	response.Envelope{data=map[string]Fault}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Router			/admin/chaos [put]
+func (h *Handler) Set(w http.ResponseWriter, r *http.Request) {
+	var faults map[string]Fault
+	if err := json.NewDecoder(r.Body).Decode(&faults); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if err := h.inj.Set(faults); err != nil {
+		response.BadRequest(w, "targets must be db, storage or sms; percentages 0-100; latency non-negative")
+		return
+	}
+	response.OK(w, h.inj.Faults())
+}
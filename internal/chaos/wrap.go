@@ -0,0 +1,147 @@
+package chaos
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/radif/service/internal/sms"
+	"github.com/radif/service/internal/storage"
+)
+
+// Tracer is a pgx tracer that applies the db fault to every query and batch.
+// pgx offers no hook that can return an error, so a failed call gets an
+// already-cancelled context instead. pgx rejects it before anything is sent,
+// so the caller sees a context error and the connection stays usable.
+type Tracer struct {
+	inj *Injector
+}
+
+// NewTracer returns a Tracer for inj.
+func NewTracer(inj *Injector) *Tracer {
+	return &Tracer{inj: inj}
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *Tracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return t.apply(ctx)
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *Tracer) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+// TraceBatchStart implements pgx.BatchTracer.
+func (t *Tracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	return t.apply(ctx)
+}
+
+// TraceBatchQuery implements pgx.BatchTracer.
+func (t *Tracer) TraceBatchQuery(context.Context, *pgx.Conn, pgx.TraceBatchQueryData) {}
+
+// TraceBatchEnd implements pgx.BatchTracer.
+func (t *Tracer) TraceBatchEnd(context.Context, *pgx.Conn, pgx.TraceBatchEndData) {}
+
+// apply runs the db fault and cancels the returned context if it fires.
+func (t *Tracer) apply(ctx context.Context) context.Context {
+	err := t.inj.Inject(ctx, TargetDB)
+	if err == nil {
+		return ctx
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	cancel(err)
+	return ctx
+}
+
+// faultyStorage applies the storage fault before each storage call.
+type faultyStorage struct {
+	storage.Storage
+	inj *Injector
+}
+
+// WrapStorage returns s with the storage fault applied to every call except
+// PublicURL, which does not reach the store.
+func WrapStorage(inj *Injector, s storage.Storage) storage.Storage {
+	return &faultyStorage{Storage: s, inj: inj}
+}
+
+// Upload implements storage.Storage.
+func (s *faultyStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	if err := s.inj.Inject(ctx, TargetStorage); err != nil {
+		return err
+	}
+	return s.Storage.Upload(ctx, key, reader, size, contentType)
+}
+
+// Delete implements storage.Storage.
+func (s *faultyStorage) Delete(ctx context.Context, key string) error {
+	if err := s.inj.Inject(ctx, TargetStorage); err != nil {
+		return err
+	}
+	return s.Storage.Delete(ctx, key)
+}
+
+// PresignPut implements storage.Storage.
+func (s *faultyStorage) PresignPut(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if err := s.inj.Inject(ctx, TargetStorage); err != nil {
+		return "", err
+	}
+	return s.Storage.PresignPut(ctx, key, expiry)
+}
+
+// SignedURL implements storage.Storage.
+func (s *faultyStorage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := s.inj.Inject(ctx, TargetStorage); err != nil {
+		return "", err
+	}
+	return s.Storage.SignedURL(ctx, key, ttl)
+}
+
+// Get implements storage.Storage.
+func (s *faultyStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := s.inj.Inject(ctx, TargetStorage); err != nil {
+		return nil, err
+	}
+	return s.Storage.Get(ctx, key)
+}
+
+// Stat implements storage.Storage.
+func (s *faultyStorage) Stat(ctx context.Context, key string) (*storage.ObjectInfo, error) {
+	if err := s.inj.Inject(ctx, TargetStorage); err != nil {
+		return nil, err
+	}
+	return s.Storage.Stat(ctx, key)
+}
+
+// List implements storage.Storage. The fault applies once, before listing.
+func (s *faultyStorage) List(ctx context.Context, prefix string, fn func(*storage.ObjectInfo) error) error {
+	if err := s.inj.Inject(ctx, TargetStorage); err != nil {
+		return err
+	}
+	return s.Storage.List(ctx, prefix, fn)
+}
+
+// faultyProvider applies the sms fault before each send.
+type faultyProvider struct {
+	sms.Provider
+	inj *Injector
+}
+
+// WrapSMS returns each provider with the sms fault applied to Send, so
+// dispatcher failover can be observed.
+func WrapSMS(inj *Injector, providers ...sms.Provider) []sms.Provider {
+	out := make([]sms.Provider, len(providers))
+	for i, p := range providers {
+		out[i] = &faultyProvider{Provider: p, inj: inj}
+	}
+	return out
+}
+
+// Send implements sms.Provider.
+func (p *faultyProvider) Send(ctx context.Context, phone, text string) error {
+	if err := p.inj.Inject(ctx, TargetSMS); err != nil {
+		return err
+	}
+	return p.Provider.Send(ctx, phone, text)
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+)
+
+const (
+	// minJWTSecretLength is 256 bits, the minimum HS256 key size.
+	minJWTSecretLength    = 32
+	dataEncryptionKeySize = 32
+	auditSigningKeySize   = 32
+)
+
+// Validate checks the configuration and reports every problem at once, one
+// per line, so a bad deploy is fixed in a single pass. Malformed values are
+// problems everywhere; in production so are development defaults and
+// missing credentials.
+func (c *Config) Validate() error {
+	v := &validator{problems: append([]error(nil), c.loadErrors...)}
+
+	v.url("DATABASE_URL", c.DatabaseURL, "postgres", "postgresql")
+	v.check(c.DatabaseMaxConns > 0, "DATABASE_MAX_CONNS must be positive")
+	v.check(c.DatabaseMinConns >= 0 && c.DatabaseMinConns <= c.DatabaseMaxConns, "DATABASE_MIN_CONNS must be between 0 and DATABASE_MAX_CONNS (%d)", c.DatabaseMaxConns)
+	v.check(c.DatabaseMaxConnLifetime > 0, "DATABASE_MAX_CONN_LIFETIME must be positive")
+	v.check(c.DatabaseMaxConnIdleTime > 0, "DATABASE_MAX_CONN_IDLE_TIME must be positive")
+	v.check(c.DatabaseHealthCheckPeriod > 0, "DATABASE_HEALTH_CHECK_PERIOD must be positive")
+	v.check(c.DatabaseStatementTimeout >= 0, "DATABASE_STATEMENT_TIMEOUT must not be negative")
+	v.check(c.DatabasePoolLogInterval >= 0, "DATABASE_POOL_LOG_INTERVAL must not be negative")
+	if c.DatabaseReadURL != "" {
+		v.url("DATABASE_READ_URL", c.DatabaseReadURL, "postgres", "postgresql")
+		v.check(c.DatabaseReadCheckInterval > 0, "DATABASE_READ_CHECK_INTERVAL must be positive")
+	}
+	v.oneOf("STORAGE_DRIVER", c.StorageDriver, "minio", "local")
+	if c.StorageDriver == "local" {
+		v.url("STORAGE_LOCAL_BASE_URL", c.StorageLocalBaseURL, "http", "https")
+	}
+	v.url("STORAGE_PUBLIC_BASE", c.StoragePublicBase, "http", "https")
+	if c.StorageCDNBase != "" {
+		v.url("STORAGE_CDN_BASE", c.StorageCDNBase, "http", "https")
+	}
+	v.check(c.StorageCDNPercent >= 0 && c.StorageCDNPercent <= 100, "STORAGE_CDN_PERCENT must be between 0 and 100")
+	v.oneOf("CDN_PURGE_PROVIDER", c.CDNPurgeProvider, "", "arvancloud")
+	if c.CDNPurgeProvider == "arvancloud" {
+		v.url("ARVANCLOUD_API_URL", c.ArvanCloudAPIURL, "https")
+		v.required("ARVANCLOUD_API_KEY", c.ArvanCloudAPIKey)
+		v.required("ARVANCLOUD_DOMAIN", c.ArvanCloudDomain)
+	}
+	if c.DataEncryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(c.DataEncryptionKey)
+		v.check(err == nil && len(key) == dataEncryptionKeySize, "DATA_ENCRYPTION_KEY must be %d bytes, base64-encoded", dataEncryptionKeySize)
+	}
+	if c.AuditSigningKey != "" {
+		key, err := base64.StdEncoding.DecodeString(c.AuditSigningKey)
+		v.check(err == nil && len(key) == auditSigningKeySize, "AUDIT_SIGNING_KEY must be %d bytes, base64-encoded", auditSigningKeySize)
+	}
+	v.oneOf("SEARCH_DRIVER", c.SearchDriver, "postgres", "meilisearch")
+	if c.SearchDriver == "meilisearch" {
+		v.url("MEILISEARCH_URL", c.MeilisearchURL, "http", "https")
+	}
+	if c.ModerationClassifierURL != "" {
+		v.url("MODERATION_CLASSIFIER_URL", c.ModerationClassifierURL, "http", "https")
+	}
+	v.check(c.ModerationClassifierScore >= 0 && c.ModerationClassifierScore <= 1, "MODERATION_CLASSIFIER_THRESHOLD must be between 0 and 1")
+	var level slog.Level
+	v.check(level.UnmarshalText([]byte(c.LogLevel)) == nil, "LOG_LEVEL must be debug, info, warn or error, got %q", c.LogLevel)
+	v.oneOf("LOG_FORMAT", c.LogFormat, "json", "text")
+	v.check(c.HTTPReadTimeout > 0, "HTTP_READ_TIMEOUT must be positive")
+	v.check(c.HTTPWriteTimeout > 0, "HTTP_WRITE_TIMEOUT must be positive")
+	v.check(c.HTTPIdleTimeout > 0, "HTTP_IDLE_TIMEOUT must be positive")
+	v.check(c.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+	v.check(c.WorkerPort != c.Port, "WORKER_PORT must differ from PORT")
+	v.check(c.RequestTimeout >= 0 && c.RequestTimeout < c.HTTPWriteTimeout, "REQUEST_TIMEOUT must be below HTTP_WRITE_TIMEOUT (%s)", c.HTTPWriteTimeout)
+	v.check(c.CompressionLevel >= 0 && c.CompressionLevel <= 9, "COMPRESSION_LEVEL must be between 0 and 9")
+	if c.OTLPEndpoint != "" {
+		v.url("OTEL_EXPORTER_OTLP_ENDPOINT", c.OTLPEndpoint, "http", "https")
+	}
+	v.check(c.TraceSampleRatio >= 0 && c.TraceSampleRatio <= 1, "OTEL_TRACES_SAMPLE_RATIO must be between 0 and 1")
+	if c.SentryDSN != "" {
+		v.url("SENTRY_DSN", c.SentryDSN, "http", "https")
+	}
+	v.check(c.SentrySampleRate >= 0 && c.SentrySampleRate <= 1, "SENTRY_SAMPLE_RATE must be between 0 and 1")
+	if c.RedisURL != "" {
+		v.url("REDIS_URL", c.RedisURL, "redis", "rediss")
+	}
+	v.oneOf("RATE_LIMIT_STORE", c.RateLimitStore, "memory", "redis")
+	v.check(c.RateLimitStore != "redis" || c.RedisURL != "", "RATE_LIMIT_STORE=redis requires REDIS_URL")
+	if c.EventBusURL != "" {
+		v.url("EVENT_BUS_URL", c.EventBusURL, "nats", "tls")
+	}
+
+	v.check((c.TLSCertFile == "") == (c.TLSKeyFile == ""), "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	if c.TLSRedirectPort != "" {
+		v.check(c.TLSCertFile != "", "TLS_REDIRECT_PORT requires TLS_CERT_FILE and TLS_KEY_FILE")
+		v.check(c.TLSRedirectPort != c.Port, "TLS_REDIRECT_PORT must differ from PORT")
+	}
+
+	v.oneOf("SECRETS_BACKEND", c.SecretsBackend, "", "vault", "sops")
+	switch c.SecretsBackend {
+	case "vault":
+		v.url("VAULT_ADDR", c.VaultAddr, "http", "https")
+		v.required("VAULT_TOKEN", c.VaultToken)
+		v.required("VAULT_SECRET_PATH", c.VaultSecretPath)
+	case "sops":
+		v.required("SOPS_FILE", c.SOPSFile)
+	}
+	if c.SecretsBackend != "" {
+		v.check(c.SecretsRefreshInterval > 0, "SECRETS_REFRESH_INTERVAL must be positive")
+	}
+
+	if c.IsProduction() {
+		v.check(c.JWTSecret != defaultJWTSecret, "JWT_SECRET is the development default")
+		v.check(len(c.JWTSecret) >= minJWTSecretLength, "JWT_SECRET must be at least %d characters", minJWTSecretLength)
+		v.check(c.DatabaseURL != defaultDatabaseURL, "DATABASE_URL is the development default")
+		v.required("DATA_ENCRYPTION_KEY", c.DataEncryptionKey)
+		v.required("AUDIT_SIGNING_KEY", c.AuditSigningKey)
+		// Without Redis, per-phone OTP attempt limits and token revocation
+		// are off, leaving OTPs open to guessing and logouts ineffective.
+		v.required("REDIS_URL", c.RedisURL)
+		v.check(c.StorageDriver != "local", "STORAGE_DRIVER=local is for development and CI only")
+		if c.StorageDriver == "minio" {
+			v.check(c.StorageAccessKey != defaultStorageKey && c.StorageSecretKey != defaultStorageKey, "STORAGE_ACCESS_KEY and STORAGE_SECRET_KEY are the development defaults")
+		}
+		v.url("STORAGE_PUBLIC_BASE", c.StoragePublicBase, "https")
+		if c.StorageCDNBase != "" {
+			v.url("STORAGE_CDN_BASE", c.StorageCDNBase, "https")
+		}
+		if c.SearchDriver == "meilisearch" {
+			v.required("MEILISEARCH_KEY", c.MeilisearchKey)
+		}
+		if c.SecretsBackend == "vault" {
+			v.url("VAULT_ADDR", c.VaultAddr, "https")
+		}
+		v.check(!c.ChaosEnabled, "CHAOS_ENABLED must not be set in production")
+	}
+
+	return errors.Join(v.problems...)
+}
+
+// validator collects configuration problems.
+type validator struct {
+	problems []error
+}
+
+func (v *validator) check(ok bool, format string, args ...any) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Errorf(format, args...))
+	}
+}
+
+func (v *validator) required(key, value string) {
+	v.check(value != "", "%s is required", key)
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	quoted := make([]string, len(allowed))
+	for i, a := range allowed {
+		quoted[i] = fmt.Sprintf("%q", a)
+	}
+	v.check(false, "%s must be one of %s, got %q", key, strings.Join(quoted, ", "), value)
+}
+
+// url requires value to be an absolute URL with a host and one of schemes.
+// A key already reported is not reported twice.
+func (v *validator) url(key, value string, schemes ...string) {
+	for _, p := range v.problems {
+		if strings.HasPrefix(p.Error(), key+" ") {
+			return
+		}
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" {
+		v.check(false, "%s must be an absolute URL, got %q", key, redact(value))
+		return
+	}
+	for _, s := range schemes {
+		if u.Scheme == s {
+			return
+		}
+	}
+	v.check(false, "%s must use %s, got %q", key, strings.Join(schemes, " or "), redact(value))
+}
+
+// redact hides the password of a URL, so the report can be logged.
+func redact(value string) string {
+	if u, err := url.Parse(value); err == nil {
+		return u.Redacted()
+	}
+	return "<unparsable>"
+}
@@ -0,0 +1,133 @@
+package contact
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+	"github.com/radif/service/internal/storage"
+)
+
+// Handler holds HTTP handlers for contact endpoints.
+type Handler struct {
+	svc   *Service
+	store storage.Storage
+}
+
+// NewHandler creates a new contact Handler.
+func NewHandler(svc *Service, store storage.Storage) *Handler {
+	return &Handler{svc: svc, store: store}
+}
+
+type syncRequest struct {
+	Hashes []string `json:"hashes" example:"5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8"`
+}
+
+// Sync godoc
+//
+//	@Summary		Sync address book
+//	@Description	Upload SHA-256 hex hashes of address-book phone numbers, normalized to 09XXXXXXXXX before hashing (max 1000 per call, 5000 per day, 10 calls an hour). Returns the Radif users among them, excluding users who turned discoverability off. Matches are remembered for recipient suggestions.
+//	@Tags			contacts
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		syncRequest	true	"Phone hashes"
+//	@Success		200		{object}	response.Envelope{data=[]Contact}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		429		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/contacts/sync [post]
+func (h *Handler) Sync(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req syncRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	contacts, err := h.svc.Sync(r.Context(), userID, req.Hashes)
+	if err != nil {
+		if errors.Is(err, ErrInvalidHashes) {
+			response.BadRequest(w, "hashes must be 1-1000 lowercase hex SHA-256 digests")
+			return
+		}
+		if errors.Is(err, ErrDailyLimit) {
+			response.Error(w, http.StatusTooManyRequests, "daily contact sync limit reached, try again tomorrow")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+
+	h.populateAvatars(contacts)
+	response.OK(w, contacts)
+}
+
+// List godoc
+//
+//	@Summary		List contacts
+//	@Description	Returns previously matched contacts who are still discoverable, for recipient suggestions.
+//	@Tags			contacts
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit	query		int	false	"Page size (1-100, default 50)"
+//	@Param			offset	query		int	false	"Offset (default 0)"
+//	@Success		200		{object}	response.Envelope{data=[]Contact}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/contacts [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	q := r.URL.Query()
+	limit, offset := 50, 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			response.BadRequest(w, "offset must be a non-negative integer")
+			return
+		}
+		offset = n
+	}
+
+	contacts, err := h.svc.List(r.Context(), userID, limit, offset)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	h.populateAvatars(contacts)
+	response.OK(w, contacts)
+}
+
+// populateAvatars resolves avatar keys to public URLs.
+func (h *Handler) populateAvatars(contacts []*Contact) {
+	for _, c := range contacts {
+		if c.AvatarKey != nil && *c.AvatarKey != "" {
+			url := h.store.PublicURL(*c.AvatarKey)
+			c.AvatarURL = &url
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package contact matches device address books against registered users and
+// keeps the resulting social graph for recipient suggestions.
+package contact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Contact is a Radif user found in the caller's address book.
+type Contact struct {
+	PhoneHash   string    `json:"phoneHash"`
+	UserID      string    `json:"userId"`
+	AccountType string    `json:"accountType"`
+	Username    *string   `json:"username,omitempty"`
+	FullName    *string   `json:"fullName,omitempty"`
+	AvatarKey   *string   `json:"-"`
+	AvatarURL   *string   `json:"avatarUrl,omitempty"`
+	SyncedAt    time.Time `json:"syncedAt"`
+}
+
+// Repository handles contact persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new contact Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Sync matches hashes against discoverable users, records the matches for
+// ownerID and returns them. The owner never matches themselves or anyone who
+// has blocked them.
+func (r *Repository) Sync(ctx context.Context, ownerID string, hashes []string) ([]*Contact, error) {
+	rows, err := r.db.Query(ctx,
+		`WITH matched AS (
+		     SELECT id, phone_hash, account_type, username, full_name, avatar_key
+		     FROM users
+		     WHERE phone_hash = ANY($2) AND discoverable AND deleted_at IS NULL AND id <> $1
+		       AND NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.blocker_id = users.id AND b.blocked_id = $1)
+		 ), saved AS (
+		     INSERT INTO contacts (owner_id, contact_id)
+		     SELECT $1, id FROM matched
+		     ON CONFLICT (owner_id, contact_id) DO UPDATE SET synced_at = NOW()
+		     RETURNING contact_id, synced_at
+		 )
+		 SELECT m.phone_hash, m.id, m.account_type, m.username, m.full_name, m.avatar_key, s.synced_at
+		 FROM matched m JOIN saved s ON s.contact_id = m.id
+		 ORDER BY m.full_name NULLS LAST, m.id`,
+		ownerID, hashes,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("sync contacts: %w", err)
+	}
+	return scanContacts(rows)
+}
+
+// ReserveLookups counts n more hashes against ownerID's lookups today and
+// reports whether the total stays within limit. Nothing is counted when it
+// would not.
+func (r *Repository) ReserveLookups(ctx context.Context, ownerID string, n, limit int) (bool, error) {
+	var total int
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO contact_lookups_daily (user_id, day, hashes)
+		 SELECT $1, CURRENT_DATE, $2 WHERE $2 <= $3
+		 ON CONFLICT (user_id, day) DO UPDATE SET hashes = contact_lookups_daily.hashes + EXCLUDED.hashes
+		 WHERE contact_lookups_daily.hashes + EXCLUDED.hashes <= $3
+		 RETURNING hashes`,
+		ownerID, n, limit,
+	).Scan(&total)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("reserve contact lookups: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteLookupsBefore deletes lookup counts for days before day and returns
+// how many were deleted.
+func (r *Repository) DeleteLookupsBefore(ctx context.Context, day time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM contact_lookups_daily WHERE day < $1`, day)
+	if err != nil {
+		return 0, fmt.Errorf("delete contact lookups: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// List returns the owner's stored contacts that are still discoverable and
+// have not blocked the owner.
+func (r *Repository) List(ctx context.Context, ownerID string, limit, offset int) ([]*Contact, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT u.phone_hash, u.id, u.account_type, u.username, u.full_name, u.avatar_key, c.synced_at
+		 FROM contacts c JOIN users u ON u.id = c.contact_id AND u.deleted_at IS NULL
+		 WHERE c.owner_id = $1 AND u.discoverable
+		   AND NOT EXISTS (SELECT 1 FROM user_blocks b WHERE b.blocker_id = u.id AND b.blocked_id = $1)
+		 ORDER BY u.full_name NULLS LAST, u.id
+		 LIMIT $2 OFFSET $3`,
+		ownerID, limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list contacts: %w", err)
+	}
+	return scanContacts(rows)
+}
+
+// scanContacts reads all contact rows and closes rows.
+func scanContacts(rows pgx.Rows) ([]*Contact, error) {
+	defer rows.Close()
+	out := []*Contact{}
+	for rows.Next() {
+		c := &Contact{}
+		if err := rows.Scan(&c.PhoneHash, &c.UserID, &c.AccountType, &c.Username, &c.FullName, &c.AvatarKey, &c.SyncedAt); err != nil {
+			return nil, fmt.Errorf("scan contact: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
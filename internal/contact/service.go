@@ -0,0 +1,90 @@
+package contact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// maxHashesPerSync bounds a single upload; larger address books sync in pages.
+const maxHashesPerSync = 1000
+
+// maxHashesPerDay caps how many hashes a user may look up in a day, counting
+// the distinct hashes of each upload. Iranian mobile numbers are few enough to enumerate, so
+// without a cap any account could map every number to a user. It leaves
+// room for a large address book and a few resyncs.
+const maxHashesPerDay = 5000
+
+// lookupRetention is how long daily lookup counts are kept.
+const lookupRetention = 7 * 24 * time.Hour
+
+var hashRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ErrInvalidHashes is returned when the upload is empty, too large or malformed.
+var ErrInvalidHashes = errors.New("invalid phone hashes")
+
+// ErrDailyLimit is returned when an upload would take the user past
+// maxHashesPerDay.
+var ErrDailyLimit = errors.New("daily contact lookup limit reached")
+
+// Service contains business logic for contact sync.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new contact Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Sync validates and de-duplicates the uploaded hashes and returns the
+// discoverable Radif users among them. Uploads past the daily quota fail
+// with ErrDailyLimit.
+func (s *Service) Sync(ctx context.Context, ownerID string, hashes []string) ([]*Contact, error) {
+	if len(hashes) == 0 || len(hashes) > maxHashesPerSync {
+		return nil, ErrInvalidHashes
+	}
+	seen := make(map[string]struct{}, len(hashes))
+	unique := make([]string, 0, len(hashes))
+	for _, h := range hashes {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if !hashRegex.MatchString(h) {
+			return nil, ErrInvalidHashes
+		}
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		unique = append(unique, h)
+	}
+	ok, err := s.repo.ReserveLookups(ctx, ownerID, len(unique), maxHashesPerDay)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrDailyLimit
+	}
+	return s.repo.Sync(ctx, ownerID, unique)
+}
+
+// PurgeLookups deletes daily lookup counts older than lookupRetention. It is
+// run by the cron scheduler.
+func (s *Service) PurgeLookups(ctx context.Context) error {
+	n, err := s.repo.DeleteLookupsBefore(ctx, time.Now().Add(-lookupRetention))
+	if err != nil {
+		return fmt.Errorf("purge contact lookups: %w", err)
+	}
+	if n > 0 {
+		slog.InfoContext(ctx, "contact: purged lookup counts", "count", n)
+	}
+	return nil
+}
+
+// List returns the owner's matched contacts.
+func (s *Service) List(ctx context.Context, ownerID string, limit, offset int) ([]*Contact, error) {
+	return s.repo.List(ctx, ownerID, limit, offset)
+}
@@ -0,0 +1,237 @@
+// Package contentfilter sanitizes free text that one user shows another,
+// such as profile bios and display names, and later payment notes and
+// payment-request messages. Such text attracts spam (links, gambling ads)
+// and phishing (asking for card numbers); Sanitize must run before it is
+// persisted.
+package contentfilter
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxLength is the longest note accepted after sanitization, in characters.
+const MaxLength = 255
+
+// Flag records a rewrite applied by Sanitize.
+type Flag string
+
+// Flags reported by Sanitize.
+const (
+	FlagURLRemoved      Flag = "url_removed"
+	FlagRepeatCollapsed Flag = "repeat_collapsed"
+	FlagPhoneRedacted   Flag = "phone_redacted"
+	FlagCardRedacted    Flag = "card_redacted"
+)
+
+// ErrBannedContent is returned when the text contains a banned term. Such text
+// is rejected outright rather than rewritten.
+var ErrBannedContent = errors.New("text contains banned content")
+
+// ErrTooLong is returned when the sanitized text exceeds MaxLength.
+var ErrTooLong = errors.New("text too long")
+
+// redacted replaces detected phone and card numbers.
+const redacted = "***"
+
+var (
+	urlRegex = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+\.(?:ir|com|net|org|me|io|xyz|info|link)(?:/\S*)?\b|\bt\.me/\S+`)
+	// cardRegex matches 16 digits, optionally grouped by spaces or dashes.
+	cardRegex = regexp.MustCompile(`\b\d{4}[ -]?\d{4}[ -]?\d{4}[ -]?\d{4}\b`)
+	// phoneRegex matches Iranian mobile numbers in 09…, 989… and +989… forms.
+	phoneRegex = regexp.MustCompile(`(?:\+?98|0)9\d{9}\b`)
+	spaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// maxRepeat is how many identical consecutive characters are kept.
+const maxRepeat = 3
+
+// bannedTerms are matched after normalization (see normalizeTerm), so they
+// are stored lowercase and without spaces. They cover the gambling and
+// betting ads that dominate note spam.
+var bannedTerms = []string{
+	"کازینو",
+	"شرطبندی",
+	"بتفوروارد",
+	"پیشبینیفوتبال",
+	"casino",
+	"betting",
+}
+
+// Result is sanitized text together with the rewrites applied.
+type Result struct {
+	Text  string
+	Flags []Flag
+}
+
+// Sanitize rejects banned terms, strips URLs, redacts phone and card
+// numbers, collapses character floods and normalizes whitespace. Detection
+// runs on a copy with ASCII digits; the returned text keeps the writer's
+// own digits everywhere it was not redacted.
+func Sanitize(text string) (Result, error) {
+	var res Result
+	normalized := normalizeTerm(normalizeDigits(text))
+	for _, term := range bannedTerms {
+		if strings.Contains(normalized, term) {
+			return Result{}, ErrBannedContent
+		}
+	}
+
+	t := newDualText(text)
+	if t.replace(urlRegex, func(string) (string, bool) { return "", true }) {
+		res.Flags = append(res.Flags, FlagURLRemoved)
+	}
+	if t.replace(cardRegex, func(m string) (string, bool) { return redacted, luhn(stripSeparators(m)) }) {
+		res.Flags = append(res.Flags, FlagCardRedacted)
+	}
+	if t.replace(phoneRegex, func(string) (string, bool) { return redacted, true }) {
+		res.Flags = append(res.Flags, FlagPhoneRedacted)
+	}
+
+	s := t.orig
+	if collapsed, changed := collapseRepeats(s); changed {
+		s = collapsed
+		res.Flags = append(res.Flags, FlagRepeatCollapsed)
+	}
+
+	s = strings.TrimSpace(spaceRegex.ReplaceAllString(s, " "))
+	if len([]rune(s)) > MaxLength {
+		return Result{}, ErrTooLong
+	}
+	res.Text = s
+	return res, nil
+}
+
+// dualText is text being sanitized, kept next to a copy with ASCII digits
+// that patterns are matched against. normalizeDigits maps rune to rune, so
+// the nth rune of one is the nth rune of the other.
+type dualText struct {
+	orig, norm string
+}
+
+func newDualText(s string) *dualText {
+	return &dualText{orig: s, norm: normalizeDigits(s)}
+}
+
+// replace finds re in the normalized copy and replaces each match for
+// which repl returns true, in both copies, with the returned ASCII text.
+// It reports whether anything was replaced.
+func (t *dualText) replace(re *regexp.Regexp, repl func(match string) (string, bool)) bool {
+	matches := re.FindAllStringIndex(t.norm, -1)
+	if matches == nil {
+		return false
+	}
+	// origAt maps each byte offset of norm that starts a rune, and its end,
+	// to the same position in orig.
+	origAt := make([]int, len(t.norm)+1)
+	o := 0
+	for i := range t.norm {
+		origAt[i] = o
+		_, size := utf8.DecodeRuneInString(t.orig[o:])
+		o += size
+	}
+	origAt[len(t.norm)] = len(t.orig)
+
+	var orig, norm strings.Builder
+	prevNorm, prevOrig, changed := 0, 0, false
+	for _, m := range matches {
+		with, ok := repl(t.norm[m[0]:m[1]])
+		if !ok {
+			continue
+		}
+		norm.WriteString(t.norm[prevNorm:m[0]])
+		norm.WriteString(with)
+		orig.WriteString(t.orig[prevOrig:origAt[m[0]]])
+		orig.WriteString(with)
+		prevNorm, prevOrig, changed = m[1], origAt[m[1]], true
+	}
+	if !changed {
+		return false
+	}
+	norm.WriteString(t.norm[prevNorm:])
+	orig.WriteString(t.orig[prevOrig:])
+	t.orig, t.norm = orig.String(), norm.String()
+	return true
+}
+
+// normalizeDigits maps Persian (۰-۹) and Arabic-Indic (٠-٩) digits to ASCII
+// so number detection cannot be evaded by switching keyboards.
+func normalizeDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '۰' && r <= '۹':
+			return '0' + (r - '۰')
+		case r >= '٠' && r <= '٩':
+			return '0' + (r - '٠')
+		}
+		return r
+	}, s)
+}
+
+// normalizeTerm lowercases, unifies Arabic/Persian letter variants and drops
+// zero-width joiners, tatweel, whitespace and punctuation, so "شرط‌بندی",
+// "شرط بندی" and "شرطـبندی" all match "شرطبندی".
+func normalizeTerm(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch r {
+		case 'ي':
+			r = 'ی'
+		case 'ك':
+			r = 'ک'
+		case '‌', '‍', 'ـ':
+			continue
+		}
+		if unicode.IsSpace(r) || unicode.IsPunct(r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// collapseRepeats limits runs of the same character to maxRepeat.
+func collapseRepeats(s string) (string, bool) {
+	var b strings.Builder
+	var prev rune
+	run, changed := 0, false
+	for _, r := range s {
+		if r == prev {
+			run++
+		} else {
+			prev, run = r, 1
+		}
+		if run > maxRepeat {
+			changed = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), changed
+}
+
+// stripSeparators removes spaces and dashes from a grouped card number.
+func stripSeparators(s string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(s)
+}
+
+// luhn validates a digit string with the Luhn checksum.
+func luhn(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
@@ -0,0 +1,66 @@
+package contentfilter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSanitizeBannedTerms(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+	}{
+		{name: "joined", text: "شرطبندی آنلاین"},
+		{name: "space", text: "پیش بینی فوتبال با ما"},
+		{name: "zwnj", text: "بت‌فوروارد"},
+		{name: "zwnj and space", text: "پیش‌بینی فوتبال"},
+		{name: "tatweel", text: "شرطـبندی"},
+		{name: "arabic letters", text: "كازينو"},
+		{name: "punctuation", text: "c.a.s.i.n.o"},
+		{name: "uppercase", text: "Best BETTING tips"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Sanitize(tt.text); !errors.Is(err, ErrBannedContent) {
+				t.Fatalf("Sanitize(%q) error = %v, want ErrBannedContent", tt.text, err)
+			}
+		})
+	}
+}
+
+func TestSanitizeAllowsOrdinaryText(t *testing.T) {
+	for _, text := range []string{"فوتبال دوست دارم", "پیش‌بینی هوا", "Coffee & books"} {
+		if _, err := Sanitize(text); err != nil {
+			t.Errorf("Sanitize(%q) error = %v, want nil", text, err)
+		}
+	}
+}
+
+func TestSanitizeKeepsDigits(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		want  string
+		flags []Flag
+	}{
+		{name: "persian year", text: "عضو از سال ۱۴۰۲", want: "عضو از سال ۱۴۰۲"},
+		{name: "arabic-indic digits", text: "طبقه ٣", want: "طبقه ٣"},
+		{name: "persian phone", text: "تماس: ۰۹۱۲۱۲۳۴۵۶۷ ساعت ۹ تا ۱۷", want: "تماس: *** ساعت ۹ تا ۱۷", flags: []Flag{FlagPhoneRedacted}},
+		{name: "persian card", text: "کارت ۶۰۳۷-۹۹۱۸-۰۰۰۰-۰۰۰۶ لطفا", want: "کارت *** لطفا", flags: []Flag{FlagCardRedacted}},
+		{name: "url then digits", text: "www.spam.ir سال ۱۴۰۲", want: "سال ۱۴۰۲", flags: []Flag{FlagURLRemoved}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Sanitize(tt.text)
+			if err != nil {
+				t.Fatalf("Sanitize(%q): %v", tt.text, err)
+			}
+			if res.Text != tt.want {
+				t.Errorf("Text = %q, want %q", res.Text, tt.want)
+			}
+			if len(res.Flags) != len(tt.flags) || (len(tt.flags) > 0 && res.Flags[0] != tt.flags[0]) {
+				t.Errorf("Flags = %v, want %v", res.Flags, tt.flags)
+			}
+		})
+	}
+}
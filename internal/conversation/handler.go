@@ -0,0 +1,313 @@
+package conversation
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/block"
+	"github.com/radif/service/internal/contentfilter"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for conversation endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new conversation Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type startRequest struct {
+	UserID string `json:"userId"`
+}
+
+// Start godoc
+//
+//	@Summary		Start conversation
+//	@Description	Returns the caller's 1:1 conversation with a user, creating it if needed.
+//	@Tags			conversations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		startRequest	true	"Other participant"
+//	@Success		200		{object}	response.Envelope{data=Conversation}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/conversations [post]
+func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req startRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		response.BadRequest(w, "userId is required")
+		return
+	}
+
+	c, err := h.svc.Start(r.Context(), userID, req.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, c)
+}
+
+// List godoc
+//
+//	@Summary		List conversations
+//	@Description	Returns the caller's conversations with the latest message and unread count, most recently active first.
+//	@Tags			conversations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			limit	query		int	false	"Page size (1-100, default 20)"
+//	@Param			offset	query		int	false	"Offset (default 0)"
+//	@Success		200		{object}	response.Envelope{data=[]Conversation}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/conversations [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	q := r.URL.Query()
+	limit, offset := 20, 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			response.BadRequest(w, "offset must be a non-negative integer")
+			return
+		}
+		offset = n
+	}
+
+	conversations, err := h.svc.List(r.Context(), userID, limit, offset)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, conversations)
+}
+
+type unreadCountResponse struct {
+	Count int `json:"count" example:"3"`
+}
+
+// UnreadCount godoc
+//
+//	@Summary		Count unread messages
+//	@Description	Returns the number of unread messages across all conversations, for the messages badge.
+//	@Tags			conversations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=unreadCountResponse}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/conversations/unread-count [get]
+func (h *Handler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	n, err := h.svc.UnreadCount(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, unreadCountResponse{Count: n})
+}
+
+// Get godoc
+//
+//	@Summary		Get conversation
+//	@Description	Returns one of the caller's conversations.
+//	@Tags			conversations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Conversation ID"
+//	@Success		200	{object}	response.Envelope{data=Conversation}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/conversations/{id} [get]
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	c, err := h.svc.Get(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, c)
+}
+
+// Messages godoc
+//
+//	@Summary		List messages
+//	@Description	Returns a conversation's messages, newest first. Pass nextCursor from the previous page as cursor to continue.
+//	@Tags			conversations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Conversation ID"
+//	@Param			cursor	query		string	false	"Cursor from the previous page"
+//	@Param			limit	query		int		false	"Page size (1-100, default 50)"
+//	@Success		200		{object}	response.Envelope{data=response.Page{items=[]Message}}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/conversations/{id}/messages [get]
+func (h *Handler) Messages(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	page, err := h.svc.Messages(r.Context(), userID, chi.URLParam(r, "id"), r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, page)
+}
+
+type sendRequest struct {
+	Body string `json:"body" example:"Thanks for dinner!"`
+}
+
+// Send godoc
+//
+//	@Summary		Send message
+//	@Description	Post a message to a conversation and notify the other participant. Links are stripped and phone and card numbers redacted; the stored body is returned.
+//	@Tags			conversations
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string		true	"Conversation ID"
+//	@Param			request	body		sendRequest	true	"Message"
+//	@Success		201		{object}	response.Envelope{data=Message}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/conversations/{id}/messages [post]
+func (h *Handler) Send(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req sendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	m, err := h.svc.Send(r.Context(), userID, chi.URLParam(r, "id"), req.Body)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Created(w, m)
+}
+
+// MarkRead godoc
+//
+//	@Summary		Mark conversation read
+//	@Description	Marks every message in the conversation as read for the caller.
+//	@Tags			conversations
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Conversation ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/conversations/{id}/read [post]
+func (h *Handler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.MarkRead(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// writeError maps conversation service errors to responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "conversation not found")
+	case errors.Is(err, ErrUserNotFound):
+		response.NotFound(w, "user not found")
+	case errors.Is(err, block.ErrBlocked):
+		response.Forbidden(w, "you cannot message this user")
+	case errors.Is(err, ErrSelfConversation):
+		response.BadRequest(w, "cannot start a conversation with yourself")
+	case errors.Is(err, ErrEmptyMessage):
+		response.BadRequest(w, "body is required")
+	case errors.Is(err, ErrInvalidCursor):
+		response.BadRequest(w, "invalid cursor")
+	case errors.Is(err, contentfilter.ErrBannedContent):
+		response.BadRequest(w, "message contains prohibited content")
+	case errors.Is(err, contentfilter.ErrTooLong):
+		response.BadRequest(w, "message must be 255 characters or fewer")
+	default:
+		response.InternalError(w)
+	}
+}
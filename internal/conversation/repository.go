@@ -0,0 +1,282 @@
+// Package conversation provides lightweight 1:1 message threads between
+// users, with per-participant read markers for unread counts.
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/radif/service/internal/db"
+)
+
+// Peer is the other participant of a conversation.
+type Peer struct {
+	ID       string  `json:"id"`
+	Username *string `json:"username,omitempty"`
+	FullName *string `json:"fullName,omitempty"`
+}
+
+// Message is one message in a conversation.
+type Message struct {
+	ID             string    `json:"id"`
+	ConversationID string    `json:"conversationId"`
+	SenderID       string    `json:"senderId"`
+	Body           string    `json:"body"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// Conversation is a conversation as seen by one of its participants.
+type Conversation struct {
+	ID            string     `json:"id"`
+	Peer          Peer       `json:"peer"`
+	LastMessage   *Message   `json:"lastMessage,omitempty"`
+	UnreadCount   int        `json:"unreadCount" example:"2"`
+	LastMessageAt *time.Time `json:"lastMessageAt,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// ErrNotFound is returned when a conversation does not exist or the caller is
+// not a participant.
+var ErrNotFound = errors.New("conversation not found")
+
+// ErrUserNotFound is returned when starting a conversation with an unknown user.
+var ErrUserNotFound = errors.New("user not found")
+
+// Repository handles conversation persistence.
+type Repository struct {
+	db     *pgxpool.Pool
+	reader db.Querier
+}
+
+// NewRepository creates a new conversation Repository. Message history is
+// read through reader, which may be a db.Reader on a replica.
+func NewRepository(pool *pgxpool.Pool, reader db.Querier) *Repository {
+	return &Repository{db: pool, reader: reader}
+}
+
+// selectConversation selects conversations as seen by the user in $1, with
+// the peer's profile, the latest message and the caller's unread count.
+const selectConversation = `
+	SELECT c.id, u.id, u.username, u.full_name,
+	       m.id, m.sender_id, m.body, m.created_at,
+	       (SELECT COUNT(*) FROM conversation_messages um
+	        WHERE um.conversation_id = c.id AND um.sender_id <> $1
+	          AND um.created_at > CASE WHEN c.user_a = $1 THEN c.user_a_read_at ELSE c.user_b_read_at END),
+	       c.last_message_at, c.created_at
+	FROM conversations c
+	JOIN users u ON u.id = CASE WHEN c.user_a = $1 THEN c.user_b ELSE c.user_a END AND u.deleted_at IS NULL
+	LEFT JOIN LATERAL (
+	    SELECT id, sender_id, body, created_at FROM conversation_messages
+	    WHERE conversation_id = c.id
+	    ORDER BY created_at DESC, id DESC
+	    LIMIT 1
+	) m ON TRUE
+	WHERE (c.user_a = $1 OR c.user_b = $1)`
+
+// scanConversation scans a selectConversation row.
+func scanConversation(row pgx.Row) (*Conversation, error) {
+	c := &Conversation{}
+	var msgID, senderID, body *string
+	var sentAt *time.Time
+	if err := row.Scan(
+		&c.ID, &c.Peer.ID, &c.Peer.Username, &c.Peer.FullName,
+		&msgID, &senderID, &body, &sentAt,
+		&c.UnreadCount, &c.LastMessageAt, &c.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if msgID != nil {
+		c.LastMessage = &Message{ID: *msgID, ConversationID: c.ID, SenderID: *senderID, Body: *body, CreatedAt: *sentAt}
+	}
+	return c, nil
+}
+
+// GetOrCreate returns the id of the conversation between two users, creating
+// it if needed.
+func (r *Repository) GetOrCreate(ctx context.Context, userID, peerID string) (string, error) {
+	var id string
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO conversations (user_a, user_b)
+		 VALUES (LEAST($1::uuid, $2::uuid), GREATEST($1::uuid, $2::uuid))
+		 ON CONFLICT (user_a, user_b) DO UPDATE SET user_a = EXCLUDED.user_a
+		 RETURNING id`,
+		userID, peerID,
+	).Scan(&id)
+	if err != nil {
+		if isForeignKeyViolation(err) || isInvalidID(err) {
+			return "", ErrUserNotFound
+		}
+		return "", fmt.Errorf("get or create conversation: %w", err)
+	}
+	return id, nil
+}
+
+// Get returns a conversation the user participates in.
+func (r *Repository) Get(ctx context.Context, userID, id string) (*Conversation, error) {
+	c, err := scanConversation(r.db.QueryRow(ctx, selectConversation+` AND c.id = $2`, userID, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get conversation: %w", err)
+	}
+	return c, nil
+}
+
+// ListByUser returns the user's conversations, most recently active first.
+func (r *Repository) ListByUser(ctx context.Context, userID string, limit, offset int) ([]*Conversation, error) {
+	rows, err := r.db.Query(ctx,
+		selectConversation+`
+		 ORDER BY COALESCE(c.last_message_at, c.created_at) DESC, c.id DESC
+		 LIMIT $2 OFFSET $3`,
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list conversations: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Conversation{}
+	for rows.Next() {
+		c, err := scanConversation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan conversation: %w", err)
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+// PeerOf returns the other participant of a conversation the user is part of.
+func (r *Repository) PeerOf(ctx context.Context, userID, id string) (string, error) {
+	var peerID string
+	err := r.db.QueryRow(ctx,
+		`SELECT CASE WHEN user_a = $1 THEN user_b ELSE user_a END
+		 FROM conversations WHERE id = $2 AND (user_a = $1 OR user_b = $1)`,
+		userID, id,
+	).Scan(&peerID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("get conversation peer: %w", err)
+	}
+	return peerID, nil
+}
+
+// CreateMessage stores a message, bumps the conversation's activity time and
+// marks the conversation read for the sender.
+func (r *Repository) CreateMessage(ctx context.Context, id, senderID, body string) (*Message, error) {
+	m := &Message{}
+	err := r.db.QueryRow(ctx,
+		`WITH msg AS (
+		     INSERT INTO conversation_messages (conversation_id, sender_id, body)
+		     VALUES ($1, $2, $3)
+		     RETURNING id, conversation_id, sender_id, body, created_at
+		 ), touched AS (
+		     UPDATE conversations SET
+		         last_message_at = NOW(),
+		         user_a_read_at  = CASE WHEN user_a = $2 THEN NOW() ELSE user_a_read_at END,
+		         user_b_read_at  = CASE WHEN user_b = $2 THEN NOW() ELSE user_b_read_at END
+		     WHERE id = $1
+		 )
+		 SELECT id, conversation_id, sender_id, body, created_at FROM msg`,
+		id, senderID, body,
+	).Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.Body, &m.CreatedAt)
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("create message: %w", err)
+	}
+	return m, nil
+}
+
+// ListMessagesBefore returns up to limit messages of a conversation strictly
+// older than the (createdAt, id) cursor, newest first. A nil cursor starts at
+// the latest message.
+func (r *Repository) ListMessagesBefore(ctx context.Context, id string, cur *db.Cursor, limit int) ([]*Message, error) {
+	before, beforeID := db.CursorArgs(cur)
+	rows, err := r.reader.Query(ctx,
+		`SELECT id, conversation_id, sender_id, body, created_at FROM conversation_messages
+		 WHERE conversation_id = $1
+		   AND ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3::uuid))
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $4`,
+		id, before, beforeID, limit,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return nil, ErrInvalidCursor
+		}
+		return nil, fmt.Errorf("list messages: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Message{}
+	for rows.Next() {
+		m := &Message{}
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.Body, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan message: %w", err)
+		}
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+// MarkRead moves the user's read marker to now.
+func (r *Repository) MarkRead(ctx context.Context, userID, id string) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE conversations SET
+		     user_a_read_at = CASE WHEN user_a = $1 THEN NOW() ELSE user_a_read_at END,
+		     user_b_read_at = CASE WHEN user_b = $1 THEN NOW() ELSE user_b_read_at END
+		 WHERE id = $2 AND (user_a = $1 OR user_b = $1)`,
+		userID, id,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("mark conversation read: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// CountUnread returns the number of unread messages across all of the user's
+// conversations.
+func (r *Repository) CountUnread(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM conversations c
+		 JOIN conversation_messages m ON m.conversation_id = c.id
+		 WHERE (c.user_a = $1 OR c.user_b = $1) AND m.sender_id <> $1
+		   AND m.created_at > CASE WHEN c.user_a = $1 THEN c.user_a_read_at ELSE c.user_b_read_at END`,
+		userID,
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count unread messages: %w", err)
+	}
+	return n, nil
+}
+
+// isForeignKeyViolation checks whether an error is a PostgreSQL foreign_key_violation (code 23503).
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
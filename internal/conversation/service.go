@@ -0,0 +1,158 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/radif/service/internal/contentfilter"
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/deeplink"
+	"github.com/radif/service/internal/notification"
+	"github.com/radif/service/internal/response"
+)
+
+// ErrSelfConversation is returned when a user tries to message themselves.
+var ErrSelfConversation = errors.New("cannot start a conversation with yourself")
+
+// ErrEmptyMessage is returned when a message body is blank.
+var ErrEmptyMessage = errors.New("message body is required")
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = db.ErrInvalidCursor
+
+// ReachChecker reports whether actorID may reach recipientID. It is satisfied
+// by block.Service.
+type ReachChecker interface {
+	CheckReach(ctx context.Context, actorID, recipientID string) error
+}
+
+// Service contains business logic for conversations.
+type Service struct {
+	repo     *Repository
+	reach    ReachChecker
+	notifier *notification.Service
+}
+
+// NewService creates a new conversation Service. reach may be nil, in which
+// case blocks are not checked.
+func NewService(repo *Repository, reach ReachChecker, notifier *notification.Service) *Service {
+	return &Service{repo: repo, reach: reach, notifier: notifier}
+}
+
+// Start returns the caller's conversation with peerID, creating it if needed.
+func (s *Service) Start(ctx context.Context, userID, peerID string) (*Conversation, error) {
+	if userID == peerID {
+		return nil, ErrSelfConversation
+	}
+	if err := s.checkReach(ctx, userID, peerID); err != nil {
+		return nil, err
+	}
+	id, err := s.repo.GetOrCreate(ctx, userID, peerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.Get(ctx, userID, id)
+}
+
+// Get returns one of the caller's conversations.
+func (s *Service) Get(ctx context.Context, userID, id string) (*Conversation, error) {
+	return s.repo.Get(ctx, userID, id)
+}
+
+// List returns the caller's conversations, most recently active first.
+func (s *Service) List(ctx context.Context, userID string, limit, offset int) ([]*Conversation, error) {
+	return s.repo.ListByUser(ctx, userID, limit, offset)
+}
+
+// Messages returns a page of a conversation's messages, newest first. after
+// is the NextCursor of the previous page, or empty for the first page.
+func (s *Service) Messages(ctx context.Context, userID, id, after string, limit int) (*response.Page, error) {
+	if _, err := s.repo.PeerOf(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	cur, err := db.DecodeCursor(after)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.ListMessagesBefore(ctx, id, cur, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.NewPage(items, db.NextCursor(items, limit, func(x *Message) db.Cursor {
+		return db.Cursor{CreatedAt: x.CreatedAt, ID: x.ID}
+	})), nil
+}
+
+// Send posts a message to a conversation and notifies the other participant.
+// The body goes through contentfilter, so it may come back rewritten.
+func (s *Service) Send(ctx context.Context, userID, id, body string) (*Message, error) {
+	peerID, err := s.repo.PeerOf(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkReach(ctx, userID, peerID); err != nil {
+		return nil, err
+	}
+
+	res, err := contentfilter.Sanitize(body)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(res.Text) == "" {
+		return nil, ErrEmptyMessage
+	}
+
+	m, err := s.repo.CreateMessage(ctx, id, userID, res.Text)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(ctx, peerID, m)
+	return m, nil
+}
+
+// MarkRead marks every message in the conversation as read for the caller.
+func (s *Service) MarkRead(ctx context.Context, userID, id string) error {
+	return s.repo.MarkRead(ctx, userID, id)
+}
+
+// UnreadCount returns the caller's unread messages across all conversations.
+func (s *Service) UnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+// notify tells the recipient about a new message. Failures are logged; the
+// message is already stored and shows up in the recipient's unread count.
+func (s *Service) notify(ctx context.Context, recipientID string, m *Message) {
+	title := "پیام جدید"
+	if c, err := s.repo.Get(ctx, recipientID, m.ConversationID); err == nil {
+		switch {
+		case c.Peer.FullName != nil:
+			title = *c.Peer.FullName
+		case c.Peer.Username != nil:
+			title = "@" + *c.Peer.Username
+		}
+	}
+
+	link := deeplink.Conversation(m.ConversationID)
+	if _, err := s.notifier.Notify(ctx, recipientID, notification.Message{
+		Type:     notification.TypeMessageReceived,
+		Title:    title,
+		Body:     m.Body,
+		DeepLink: &link,
+	}); err != nil {
+		slog.ErrorContext(ctx, "conversation: notify failed", "message_id", m.ID, "user_id", recipientID, "err", err)
+	}
+}
+
+// checkReach applies the recipient's blocks, if a checker is configured.
+func (s *Service) checkReach(ctx context.Context, actorID, recipientID string) error {
+	if s.reach == nil {
+		return nil
+	}
+	return s.reach.CheckReach(ctx, actorID, recipientID)
+}
@@ -0,0 +1,138 @@
+// Package cron runs periodic maintenance jobs, such as purging expired
+// rows. Every instance of the service runs a Scheduler, but only the leader
+// runs jobs: the one holding a PostgreSQL advisory lock. When the leader
+// stops or loses its database connection, the lock is released and another
+// instance takes over within a retry interval.
+package cron
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// leaderLockID is the PostgreSQL advisory lock held by the leader.
+const leaderLockID = 0x5261_6469_6643_726f // "RadifCro"
+
+// retryInterval is how often followers try to become leader, and how often
+// the leader checks it still holds the lock.
+const retryInterval = 30 * time.Second
+
+// Job is a task run every Interval by the leader. Jobs run once as soon as
+// an instance becomes leader, so they must be safe to repeat.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Scheduler runs jobs on whichever instance is leader.
+type Scheduler struct {
+	pool *pgxpool.Pool
+	jobs []Job
+}
+
+// NewScheduler creates a Scheduler for jobs, electing a leader through pool.
+func NewScheduler(pool *pgxpool.Pool, jobs ...Job) *Scheduler {
+	return &Scheduler{pool: pool, jobs: jobs}
+}
+
+// Run competes for leadership and, while leader, runs the jobs until ctx is
+// cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	slog.Info("cron scheduler started", "jobs", len(s.jobs))
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+	for {
+		if err := s.lead(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "cron: leader election failed", "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			slog.Info("cron scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// lead takes the leader lock if it is free and runs the jobs until ctx is
+// cancelled or the lock's connection fails. It returns at once when another
+// instance is leader.
+func (s *Scheduler) lead(ctx context.Context) error {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, leaderLockID).Scan(&locked); err != nil {
+		return fmt.Errorf("acquire leader lock: %w", err)
+	}
+	if !locked {
+		return nil
+	}
+	defer func() {
+		// A cancelled ctx would leave the lock held on a pooled connection.
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, leaderLockID); err != nil {
+			conn.Conn().Close(context.Background())
+		}
+	}()
+	slog.InfoContext(ctx, "cron: elected leader")
+
+	jobCtx, stop := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runEvery(jobCtx, job)
+		}()
+	}
+	defer wg.Wait()
+	defer stop()
+
+	// The lock lives as long as its session, so losing the connection means
+	// another instance may already lead.
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		if err := conn.Ping(ctx); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("leader connection lost: %w", err)
+		}
+	}
+}
+
+// runEvery runs job now and then every job.Interval until ctx is cancelled.
+func runEvery(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		if err := job.Run(ctx); err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "cron: job failed", "job", job.Name, "err", err)
+			}
+		} else {
+			slog.DebugContext(ctx, "cron: job done", "job", job.Name, "duration", time.Since(start))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
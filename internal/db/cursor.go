@@ -0,0 +1,66 @@
+package db
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is a keyset position in a list ordered by (created_at, id)
+// descending: the last row of the previous page. Repositories continue
+// with rows where (created_at, id) < (CreatedAt, ID).
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Encode returns the cursor as an opaque string for clients.
+func (c Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor produced by Encode. An empty string is the
+// first page and decodes to nil.
+func DecodeCursor(s string) (*Cursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &Cursor{CreatedAt: t, ID: id}, nil
+}
+
+// NextCursor returns the cursor after the last of items, or "" when a short
+// page shows there are no more. key gives the position of an item.
+func NextCursor[T any](items []T, limit int, key func(T) Cursor) string {
+	if len(items) == 0 || len(items) < limit {
+		return ""
+	}
+	return key(items[len(items)-1]).Encode()
+}
+
+// CursorArgs returns the created_at and id query arguments for c, both nil
+// for the first page, to use as
+//
+//	($n::timestamptz IS NULL OR (created_at, id) < ($n, $m::uuid))
+func CursorArgs(c *Cursor) (*time.Time, *string) {
+	if c == nil {
+		return nil, nil
+	}
+	return &c.CreatedAt, &c.ID
+}
@@ -5,30 +5,108 @@ import (
 	"context"
 	"embed"
 	"fmt"
-	"log"
+	"log/slog"
+	"strconv"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 //go:embed migrations
 var migrationsFS embed.FS
 
-// Connect creates and validates a pgx connection pool.
-func Connect(databaseURL string) (*pgxpool.Pool, error) {
-	pool, err := pgxpool.New(context.Background(), databaseURL)
+// PoolOptions tunes a connection pool. Zero fields keep the value from the
+// database URL, or pgx's default.
+type PoolOptions struct {
+	MaxConns          int32
+	MinConns          int32
+	MaxConnLifetime   time.Duration
+	MaxConnIdleTime   time.Duration
+	HealthCheckPeriod time.Duration
+	// StatementTimeout aborts any statement running longer, server side.
+	StatementTimeout time.Duration
+	// Password, when not nil, is called for each new connection, so a
+	// rotated database password is picked up without a restart.
+	Password func() string
+}
+
+// Connect creates and validates a pgx connection pool. Queries pass
+// through tracers in order; nil tracers are skipped.
+func Connect(databaseURL string, opts PoolOptions, tracers ...pgx.QueryTracer) (*pgxpool.Pool, error) {
+	cfg, err := pgxpool.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse database url: %w", err)
+	}
+	cfg.ConnConfig.Tracer = chainTracers(tracers)
+	if opts.MaxConns > 0 {
+		cfg.MaxConns = opts.MaxConns
+	}
+	if opts.MinConns > 0 {
+		cfg.MinConns = opts.MinConns
+	}
+	if opts.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = opts.MaxConnLifetime
+	}
+	if opts.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = opts.MaxConnIdleTime
+	}
+	if opts.HealthCheckPeriod > 0 {
+		cfg.HealthCheckPeriod = opts.HealthCheckPeriod
+	}
+	if opts.StatementTimeout > 0 {
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(opts.StatementTimeout.Milliseconds(), 10)
+	}
+	if password := opts.Password; password != nil {
+		cfg.BeforeConnect = func(_ context.Context, cc *pgx.ConnConfig) error {
+			if p := password(); p != "" {
+				cc.Password = p
+			}
+			return nil
+		}
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
 	if err != nil {
 		return nil, fmt.Errorf("create pool: %w", err)
 	}
 	if err := pool.Ping(context.Background()); err != nil {
 		return nil, fmt.Errorf("ping database: %w", err)
 	}
-	log.Println("connected to database")
+	slog.Info("connected to database", "max_conns", cfg.MaxConns, "min_conns", cfg.MinConns)
 	return pool, nil
 }
 
+// LogPoolStats logs the statistics of pool every interval until ctx is
+// cancelled, so saturation shows up in logs without a metrics stack. name
+// tells pools apart.
+func LogPoolStats(ctx context.Context, name string, pool *pgxpool.Pool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		st := pool.Stat()
+		slog.Info("db: pool stats",
+			"pool", name,
+			"total_conns", st.TotalConns(),
+			"acquired_conns", st.AcquiredConns(),
+			"idle_conns", st.IdleConns(),
+			"max_conns", st.MaxConns(),
+			"acquire_count", st.AcquireCount(),
+			"empty_acquire_count", st.EmptyAcquireCount(),
+			"canceled_acquire_count", st.CanceledAcquireCount(),
+			"acquire_duration_ms", st.AcquireDuration().Milliseconds(),
+		)
+	}
+}
+
 // Migrate runs all pending up migrations embedded in the binary.
 func Migrate(databaseURL string) error {
 	src, err := iofs.New(migrationsFS, "migrations")
@@ -46,6 +124,6 @@ func Migrate(databaseURL string) error {
 		return fmt.Errorf("apply migrations: %w", err)
 	}
 
-	log.Println("database migrations applied")
+	slog.Info("database migrations applied")
 	return nil
 }
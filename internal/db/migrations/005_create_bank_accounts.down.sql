@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bank_accounts_user_default;
+DROP INDEX IF EXISTS idx_bank_accounts_user;
+DROP TABLE IF EXISTS bank_accounts;
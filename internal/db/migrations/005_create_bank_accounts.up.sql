@@ -0,0 +1,24 @@
+-- Card numbers and IBANs are stored AES-GCM encrypted like national IDs.
+-- number_index is their keyed hash, so a user cannot add the same number
+-- twice; number_masked is what lists show, so they need no decryption.
+CREATE TABLE IF NOT EXISTS bank_accounts (
+    id             UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id        UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind           VARCHAR(10)  NOT NULL CHECK (kind IN ('card', 'iban')),
+    number_enc     BYTEA        NOT NULL,
+    number_index   BYTEA        NOT NULL,
+    number_masked  VARCHAR(26)  NOT NULL,
+    bank_name      VARCHAR(100),
+    owner_name     VARCHAR(255),
+    is_default     BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, number_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bank_accounts_user
+    ON bank_accounts (user_id);
+
+-- At most one default destination per user.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bank_accounts_user_default
+    ON bank_accounts (user_id)
+    WHERE is_default;
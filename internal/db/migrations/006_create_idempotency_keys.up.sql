@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope         TEXT         NOT NULL,
+    key           VARCHAR(255) NOT NULL,
+    request_hash  VARCHAR(64)  NOT NULL,
+    completed     BOOLEAN      NOT NULL DEFAULT FALSE,
+    status_code   INTEGER,
+    content_type  TEXT,
+    body          BYTEA,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    expires_at    TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at
+    ON idempotency_keys (expires_at);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_webhook_signing_keys_endpoint;
+DROP TABLE IF EXISTS webhook_signing_keys;
+DROP TRIGGER IF EXISTS webhook_endpoints_set_updated_at ON webhook_endpoints;
+DROP INDEX IF EXISTS idx_webhook_endpoints_user;
+DROP TABLE IF EXISTS webhook_endpoints;
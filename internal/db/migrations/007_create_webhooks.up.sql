@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS webhook_endpoints (
+    id          UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url         TEXT         NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_endpoints_user
+    ON webhook_endpoints (user_id);
+
+CREATE TRIGGER webhook_endpoints_set_updated_at
+    BEFORE UPDATE ON webhook_endpoints
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+
+-- Signing secrets. A key is active while expires_at is NULL or in the future;
+-- during rotation the previous key keeps a short expiry so both verify.
+CREATE TABLE IF NOT EXISTS webhook_signing_keys (
+    id           UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    endpoint_id  UUID         NOT NULL REFERENCES webhook_endpoints (id) ON DELETE CASCADE,
+    secret       VARCHAR(80)  NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_signing_keys_endpoint
+    ON webhook_signing_keys (endpoint_id, created_at DESC);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_webhook_delivery_attempts_delivery;
+DROP TABLE IF EXISTS webhook_delivery_attempts;
+DROP TRIGGER IF EXISTS webhook_deliveries_set_updated_at ON webhook_deliveries;
+DROP INDEX IF EXISTS idx_webhook_deliveries_endpoint;
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+DROP TABLE IF EXISTS webhook_deliveries;
+ALTER TABLE webhook_endpoints DROP COLUMN IF EXISTS events;
@@ -0,0 +1,49 @@
+-- Event types an endpoint subscribes to; an empty array means all events.
+ALTER TABLE webhook_endpoints
+    ADD COLUMN IF NOT EXISTS events TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id               UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    endpoint_id      UUID         NOT NULL REFERENCES webhook_endpoints (id) ON DELETE CASCADE,
+    event_id         VARCHAR(40)  NOT NULL,
+    event_type       VARCHAR(100) NOT NULL,
+    payload          JSONB        NOT NULL,
+    status           VARCHAR(20)  NOT NULL DEFAULT 'pending'
+                                  CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts         INTEGER      NOT NULL DEFAULT 0,
+    next_attempt_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_status_code INTEGER,
+    last_error       TEXT,
+    delivered_at     TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+    ON webhook_deliveries (next_attempt_at)
+    WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_endpoint
+    ON webhook_deliveries (endpoint_id, created_at DESC);
+
+-- Lets the event relay skip events it already queued for an endpoint.
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_event
+    ON webhook_deliveries (endpoint_id, event_id);
+
+CREATE TRIGGER webhook_deliveries_set_updated_at
+    BEFORE UPDATE ON webhook_deliveries
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id            UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    delivery_id   UUID         NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+    attempt       INTEGER      NOT NULL,
+    status_code   INTEGER,
+    error         TEXT,
+    response_body TEXT,
+    duration_ms   BIGINT       NOT NULL,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery
+    ON webhook_delivery_attempts (delivery_id, attempt);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_business_category;
+ALTER TABLE users DROP COLUMN IF EXISTS business_category;
+DROP TRIGGER IF EXISTS business_categories_set_updated_at ON business_categories;
+DROP TABLE IF EXISTS business_categories;
@@ -0,0 +1,40 @@
+-- Managed taxonomy for business accounts. Codes follow ISO 18245 merchant
+-- category codes (MCC) where one exists so analytics can map to card-network data.
+CREATE TABLE IF NOT EXISTS business_categories (
+    code        VARCHAR(10)  PRIMARY KEY,
+    parent_code VARCHAR(10)  REFERENCES business_categories (code) ON UPDATE CASCADE ON DELETE SET NULL,
+    name_fa     VARCHAR(100) NOT NULL,
+    name_en     VARCHAR(100) NOT NULL,
+    is_active   BOOLEAN      NOT NULL DEFAULT TRUE,
+    sort_order  INTEGER      NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER business_categories_set_updated_at
+    BEFORE UPDATE ON business_categories
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+
+INSERT INTO business_categories (code, name_fa, name_en, sort_order) VALUES
+    ('5411', 'سوپرمارکت و خواربار',    'Grocery & Supermarkets', 10),
+    ('5812', 'رستوران',                 'Restaurants',            20),
+    ('5814', 'فست‌فود و کافه',           'Fast Food & Cafés',      30),
+    ('5912', 'داروخانه',                'Pharmacies',             40),
+    ('5691', 'پوشاک',                   'Clothing',               50),
+    ('5732', 'لوازم الکترونیکی',         'Electronics',            60),
+    ('5541', 'جایگاه سوخت',              'Fuel Stations',          70),
+    ('4121', 'تاکسی و حمل‌ونقل',          'Taxi & Ride Sharing',    80),
+    ('7230', 'آرایشگاه و زیبایی',         'Beauty & Barber',        90),
+    ('8211', 'آموزش',                   'Education',              100),
+    ('8062', 'درمان و سلامت',            'Healthcare',             110),
+    ('7299', 'خدمات',                   'Services',               120),
+    ('5999', 'سایر فروشگاه‌ها',           'Other Retail',           130)
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS business_category VARCHAR(10)
+        REFERENCES business_categories (code) ON UPDATE CASCADE ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_business_category
+    ON users (business_category)
+    WHERE account_type = 'business';
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS contacts;
+
+DROP INDEX IF EXISTS idx_users_phone_hash;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS discoverable,
+    DROP COLUMN IF EXISTS phone_hash;
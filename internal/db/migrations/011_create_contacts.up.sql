@@ -0,0 +1,20 @@
+-- Devices upload SHA-256 hashes of address-book numbers (normalized to the
+-- stored 09XXXXXXXXX form), never the numbers themselves.
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS phone_hash CHAR(64)
+        GENERATED ALWAYS AS (encode(sha256(decode(phone, 'escape')), 'hex')) STORED,
+    ADD COLUMN IF NOT EXISTS discoverable BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_phone_hash ON users (phone_hash);
+
+-- Matched address-book contacts, kept so recipients can be suggested later.
+CREATE TABLE IF NOT EXISTS contacts (
+    owner_id   UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    contact_id UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    synced_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (owner_id, contact_id),
+    CHECK (owner_id <> contact_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contacts_contact_id ON contacts (contact_id);
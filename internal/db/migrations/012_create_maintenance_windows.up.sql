@@ -0,0 +1,15 @@
+-- Announced PSP/bank maintenance windows. provider is the PSP or bank the
+-- outage affects; 'all' covers every money-movement provider.
+CREATE TABLE IF NOT EXISTS maintenance_windows (
+    id         UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider   VARCHAR(50)  NOT NULL,
+    starts_at  TIMESTAMPTZ  NOT NULL,
+    ends_at    TIMESTAMPTZ  NOT NULL,
+    message_fa VARCHAR(500) NOT NULL,
+    message_en VARCHAR(500) NOT NULL,
+    created_by UUID         REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_windows_ends_at ON maintenance_windows (ends_at);
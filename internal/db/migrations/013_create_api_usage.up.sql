@@ -0,0 +1,15 @@
+-- Daily per-user API usage aggregates, keyed by route pattern rather than raw
+-- path. Rows older than the retention period are pruned by the usage recorder.
+CREATE TABLE IF NOT EXISTS api_usage_daily (
+    user_id       UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    day           DATE         NOT NULL,
+    method        VARCHAR(10)  NOT NULL,
+    route         VARCHAR(255) NOT NULL,
+    request_count BIGINT       NOT NULL DEFAULT 0,
+    error_count   BIGINT       NOT NULL DEFAULT 0,
+    last_used_at  TIMESTAMPTZ  NOT NULL,
+    last_ip       VARCHAR(45)  NOT NULL,
+    PRIMARY KEY (user_id, day, method, route)
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_usage_daily_day ON api_usage_daily (day);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id         UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type       VARCHAR(50)  NOT NULL,
+    title      VARCHAR(200) NOT NULL,
+    body       VARCHAR(1000) NOT NULL,
+    deep_link  JSONB,
+    read_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- Feed pagination walks (created_at, id) backwards per user.
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created
+    ON notifications (user_id, created_at DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread
+    ON notifications (user_id)
+    WHERE read_at IS NULL;
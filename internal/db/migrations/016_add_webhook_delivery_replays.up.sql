@@ -0,0 +1,4 @@
+-- A replay is a new delivery of the same event; replay_of points at the
+-- delivery it was copied from so both attempt logs are kept.
+ALTER TABLE webhook_deliveries
+    ADD COLUMN IF NOT EXISTS replay_of UUID REFERENCES webhook_deliveries (id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+-- Only deviations from the defaults in the notification catalogue are stored.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    channel    VARCHAR(10) NOT NULL CHECK (channel IN ('in_app', 'push', 'sms')),
+    enabled    BOOLEAN     NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, event_type, channel)
+);
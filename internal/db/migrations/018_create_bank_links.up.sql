@@ -0,0 +1,23 @@
+-- Read-only open-banking links between a registered bank account and an
+-- inquiry provider. The provider access token is stored AES-GCM encrypted.
+CREATE TABLE IF NOT EXISTS bank_links (
+    id                   UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id              UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    bank_account_id      UUID         NOT NULL REFERENCES bank_accounts (id) ON DELETE CASCADE,
+    provider             VARCHAR(50)  NOT NULL,
+    consent_scope        VARCHAR(100) NOT NULL,
+    consent_text_version VARCHAR(20)  NOT NULL,
+    consent_ip           VARCHAR(45)  NOT NULL,
+    consented_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    expires_at           TIMESTAMPTZ  NOT NULL,
+    revoked_at           TIMESTAMPTZ,
+    access_token_enc     BYTEA,
+    created_at           TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- At most one live link per bank account; revoked links are kept as the consent record.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bank_links_account_active
+    ON bank_links (bank_account_id)
+    WHERE revoked_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_bank_links_user ON bank_links (user_id, created_at DESC);
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS referral_codes (
+    user_id    UUID        PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    code       VARCHAR(12) NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One row per referred user. status moves pending → converted once the
+-- referee completes their first transaction.
+CREATE TABLE IF NOT EXISTS referrals (
+    referee_id   UUID        PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    referrer_id  UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending'
+                 CHECK (status IN ('pending', 'converted')),
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    converted_at TIMESTAMPTZ,
+    CHECK (referee_id <> referrer_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_referrals_referrer_id ON referrals (referrer_id);
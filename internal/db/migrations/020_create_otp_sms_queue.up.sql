@@ -0,0 +1,16 @@
+-- OTP messages waiting for an SMS provider to recover. The code itself stays
+-- in otps; a queued message is sent only while that OTP is still usable.
+CREATE TABLE IF NOT EXISTS otp_sms_queue (
+    id              UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    otp_id          UUID        NOT NULL REFERENCES otps (id) ON DELETE CASCADE,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending'
+                    CHECK (status IN ('pending', 'sent', 'cancelled')),
+    attempts        INT         NOT NULL DEFAULT 0,
+    expires_at      TIMESTAMPTZ NOT NULL,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_otp_sms_queue_pending
+    ON otp_sms_queue (next_attempt_at)
+    WHERE status = 'pending';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS group_members;
+DROP TRIGGER IF EXISTS groups_set_updated_at ON groups;
+DROP TABLE IF EXISTS groups;
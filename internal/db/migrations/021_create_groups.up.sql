@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS groups (
+    id          UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    name        VARCHAR(100) NOT NULL,
+    description VARCHAR(255),
+    avatar_key  TEXT,
+    -- Invite link code; NULL when no link is active.
+    invite_code VARCHAR(32)  UNIQUE,
+    created_by  UUID         REFERENCES users (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER groups_set_updated_at
+    BEFORE UPDATE ON groups
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+
+-- Exactly one owner per group; admins manage members and settings.
+CREATE TABLE IF NOT EXISTS group_members (
+    group_id  UUID        NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    user_id   UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role      VARCHAR(20) NOT NULL DEFAULT 'member'
+              CHECK (role IN ('owner', 'admin', 'member')),
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (group_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_members_user_id ON group_members (user_id);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_group_members_owner
+    ON group_members (group_id)
+    WHERE role = 'owner';
@@ -0,0 +1,14 @@
+-- App installations. A push token identifies one installation, so it can only
+-- belong to one user at a time; re-registering it under another user moves it.
+CREATE TABLE IF NOT EXISTS devices (
+    id           UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    push_token   TEXT         NOT NULL UNIQUE,
+    platform     VARCHAR(10)  NOT NULL CHECK (platform IN ('android', 'ios', 'web')),
+    app_version  VARCHAR(20),
+    model        VARCHAR(100),
+    last_seen_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_devices_user_last_seen ON devices (user_id, last_seen_at DESC);
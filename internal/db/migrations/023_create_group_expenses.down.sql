@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS group_expense_shares;
+DROP TABLE IF EXISTS group_expenses;
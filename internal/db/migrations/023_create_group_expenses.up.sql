@@ -0,0 +1,20 @@
+-- Shared expenses logged inside a group. Amounts are in rials.
+CREATE TABLE IF NOT EXISTS group_expenses (
+    id          UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    group_id    UUID         NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    paid_by     UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    amount      BIGINT       NOT NULL CHECK (amount > 0),
+    description VARCHAR(255) NOT NULL,
+    created_by  UUID         REFERENCES users (id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_expenses_group ON group_expenses (group_id, created_at DESC);
+
+-- Each participant's share of an expense; shares sum to the expense amount.
+CREATE TABLE IF NOT EXISTS group_expense_shares (
+    expense_id UUID   NOT NULL REFERENCES group_expenses (id) ON DELETE CASCADE,
+    user_id    UUID   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    amount     BIGINT NOT NULL CHECK (amount >= 0),
+    PRIMARY KEY (expense_id, user_id)
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_updated_at;
+DROP INDEX IF EXISTS idx_users_full_name_trgm;
+DROP INDEX IF EXISTS idx_users_username_trgm;
@@ -0,0 +1,12 @@
+-- Trigram indexes back the default Postgres search driver's fuzzy matching
+-- on usernames and names.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm
+    ON users USING GIN (username gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_users_full_name_trgm
+    ON users USING GIN (full_name gin_trgm_ops);
+
+-- Incremental reindexing for external search drivers walks users by updated_at.
+CREATE INDEX IF NOT EXISTS idx_users_updated_at ON users (updated_at, id);
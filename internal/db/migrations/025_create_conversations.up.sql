@@ -0,0 +1,26 @@
+-- 1:1 conversations. The pair is stored in canonical order (user_a < user_b)
+-- so each pair of users has at most one conversation.
+CREATE TABLE IF NOT EXISTS conversations (
+    id              UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_a          UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_b          UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_a_read_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    user_b_read_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_message_at TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (user_a < user_b),
+    UNIQUE (user_a, user_b)
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversations_user_b ON conversations (user_b);
+
+CREATE TABLE IF NOT EXISTS conversation_messages (
+    id              UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    conversation_id UUID         NOT NULL REFERENCES conversations (id) ON DELETE CASCADE,
+    sender_id       UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    body            VARCHAR(255) NOT NULL,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_messages_conversation
+    ON conversation_messages (conversation_id, created_at DESC, id DESC);
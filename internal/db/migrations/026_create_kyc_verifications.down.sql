@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS kyc_verifications_set_updated_at ON kyc_verifications;
+DROP TABLE IF EXISTS kyc_verifications;
@@ -0,0 +1,26 @@
+-- Identity verification (KYC), one row per user. The national ID is stored
+-- AES-GCM encrypted; national_id_index is its keyed hash, so one national ID
+-- can verify at most one account.
+CREATE TABLE IF NOT EXISTS kyc_verifications (
+    user_id           UUID         PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    national_id_enc   BYTEA        NOT NULL,
+    national_id_index BYTEA        NOT NULL UNIQUE,
+    national_id_last4 CHAR(4)      NOT NULL,
+    birth_date        DATE         NOT NULL,
+    status            VARCHAR(20)  NOT NULL DEFAULT 'pending'
+                                   CHECK (status IN ('pending', 'approved', 'rejected')),
+    phone_matched     BOOLEAN,
+    document_key      TEXT,
+    rejection_reason  VARCHAR(255),
+    reviewed_by       UUID         REFERENCES users (id) ON DELETE SET NULL,
+    reviewed_at       TIMESTAMPTZ,
+    submitted_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- Review queue: pending submissions, oldest first.
+CREATE INDEX IF NOT EXISTS idx_kyc_verifications_status ON kyc_verifications (status, submitted_at);
+
+CREATE TRIGGER kyc_verifications_set_updated_at
+    BEFORE UPDATE ON kyc_verifications
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS business_verifications_set_updated_at ON business_verifications;
+DROP TABLE IF EXISTS business_verifications;
+ALTER TABLE users DROP COLUMN IF EXISTS verified_at;
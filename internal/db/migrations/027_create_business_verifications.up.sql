@@ -0,0 +1,25 @@
+-- Verified badge for business accounts, set when staff approve their
+-- business verification.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS verified_at TIMESTAMPTZ;
+
+-- Business verification submissions, one row per business account.
+CREATE TABLE IF NOT EXISTS business_verifications (
+    user_id          UUID         PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    legal_name       VARCHAR(200) NOT NULL,
+    license_number   VARCHAR(50)  NOT NULL,
+    status           VARCHAR(20)  NOT NULL DEFAULT 'pending'
+                                  CHECK (status IN ('pending', 'approved', 'rejected')),
+    document_key     TEXT,
+    rejection_reason VARCHAR(255),
+    reviewed_by      UUID         REFERENCES users (id) ON DELETE SET NULL,
+    reviewed_at      TIMESTAMPTZ,
+    submitted_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- Review queue: submissions by status, oldest first.
+CREATE INDEX IF NOT EXISTS idx_business_verifications_status ON business_verifications (status, submitted_at);
+
+CREATE TRIGGER business_verifications_set_updated_at
+    BEFORE UPDATE ON business_verifications
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
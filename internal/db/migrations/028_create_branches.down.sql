@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS branch_staff;
+DROP TRIGGER IF EXISTS branches_set_updated_at ON branches;
+DROP TABLE IF EXISTS branches;
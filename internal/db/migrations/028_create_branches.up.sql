@@ -0,0 +1,28 @@
+-- Branches of a business account. settlement_tag is a short code the business
+-- chooses to attribute payments to the branch in settlement reports.
+CREATE TABLE IF NOT EXISTS branches (
+    id             UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    business_id    UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name           VARCHAR(100) NOT NULL,
+    address        VARCHAR(255),
+    settlement_tag VARCHAR(32),
+    created_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (business_id, settlement_tag)
+);
+
+CREATE INDEX IF NOT EXISTS idx_branches_business ON branches (business_id, created_at);
+
+CREATE TRIGGER branches_set_updated_at
+    BEFORE UPDATE ON branches
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
+
+-- Users assigned to work at a branch.
+CREATE TABLE IF NOT EXISTS branch_staff (
+    branch_id   UUID        NOT NULL REFERENCES branches (id) ON DELETE CASCADE,
+    user_id     UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (branch_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_branch_staff_user ON branch_staff (user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS family_link_events;
+DROP TABLE IF EXISTS family_links;
+DROP TABLE IF EXISTS family_invitations;
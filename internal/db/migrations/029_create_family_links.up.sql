@@ -0,0 +1,47 @@
+-- Invitations from a parent to a child's phone number. The child may not have
+-- an account yet; they see the invitation once they sign up with that phone.
+CREATE TABLE IF NOT EXISTS family_invitations (
+    id           UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    parent_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    child_phone  VARCHAR(20) NOT NULL,
+    role         VARCHAR(20) NOT NULL CHECK (role IN ('parent', 'guardian')),
+    status       VARCHAR(20) NOT NULL DEFAULT 'pending'
+                             CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled')),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    responded_at TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- One open invitation per parent and phone.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_family_invitations_pending
+    ON family_invitations (parent_id, child_phone) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_family_invitations_phone
+    ON family_invitations (child_phone) WHERE status = 'pending';
+
+-- Accepted parent–child relationships.
+CREATE TABLE IF NOT EXISTS family_links (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    parent_id  UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    child_id   UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role       VARCHAR(20) NOT NULL CHECK (role IN ('parent', 'guardian')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (parent_id <> child_id),
+    UNIQUE (parent_id, child_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_family_links_child ON family_links (child_id);
+
+-- Audit trail of links and unlinks. Rows carry no foreign keys so the trail
+-- outlives the link it describes.
+CREATE TABLE IF NOT EXISTS family_link_events (
+    id         UUID        PRIMARY KEY DEFAULT gen_random_uuid(),
+    parent_id  UUID        NOT NULL,
+    child_id   UUID        NOT NULL,
+    role       VARCHAR(20) NOT NULL,
+    action     VARCHAR(20) NOT NULL CHECK (action IN ('linked', 'unlinked')),
+    actor_id   UUID        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_family_link_events_child ON family_link_events (child_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_family_link_events_parent ON family_link_events (parent_id, created_at);
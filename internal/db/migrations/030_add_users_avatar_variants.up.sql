@@ -0,0 +1,3 @@
+-- Whether resized avatar variants exist next to the original. Avatars
+-- uploaded before resizing have only the original.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_variants BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS pay_pages_set_updated_at ON pay_pages;
+DROP TABLE IF EXISTS pay_pages;
@@ -0,0 +1,20 @@
+-- Hosted payment page settings of a business account. A verified business
+-- without a row still gets a page with the defaults below.
+CREATE TABLE IF NOT EXISTS pay_pages (
+    business_id           UUID         PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    enabled               BOOLEAN      NOT NULL DEFAULT TRUE,
+    headline              VARCHAR(100),
+    description           VARCHAR(500),
+    accent_color          CHAR(7),
+    amount_presets        BIGINT[]     NOT NULL DEFAULT '{}',
+    allow_custom_amount   BOOLEAN      NOT NULL DEFAULT TRUE,
+    order_reference       VARCHAR(10)  NOT NULL DEFAULT 'off'
+                          CHECK (order_reference IN ('off', 'optional', 'required')),
+    order_reference_label VARCHAR(50),
+    created_at            TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at            TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER pay_pages_set_updated_at
+    BEFORE UPDATE ON pay_pages
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_business_verifications_document_key;
+DROP INDEX IF EXISTS idx_kyc_verifications_document_key;
+DROP INDEX IF EXISTS idx_groups_avatar_key;
+DROP INDEX IF EXISTS idx_users_avatar_key;
@@ -0,0 +1,8 @@
+-- Lookups by object key, used by the storage garbage collector to find which
+-- listed objects are still referenced.
+CREATE INDEX IF NOT EXISTS idx_users_avatar_key ON users (avatar_key) WHERE avatar_key IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_groups_avatar_key ON groups (avatar_key) WHERE avatar_key IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_kyc_verifications_document_key
+    ON kyc_verifications (document_key) WHERE document_key IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_business_verifications_document_key
+    ON business_verifications (document_key) WHERE document_key IS NOT NULL;
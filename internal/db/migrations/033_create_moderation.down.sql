@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS moderation_items_set_updated_at ON moderation_items;
+DROP TABLE IF EXISTS moderation_items;
+DROP TABLE IF EXISTS image_blocklist;
@@ -0,0 +1,40 @@
+-- Perceptual hashes (64-bit dHash) of images rejected by moderators. Uploads
+-- within a few bits of an entry are held for review.
+CREATE TABLE IF NOT EXISTS image_blocklist (
+    hash       BIGINT       PRIMARY KEY,
+    reason     VARCHAR(255) NOT NULL,
+    added_by   UUID         REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+-- One moderation check of an uploaded avatar. previous_key and
+-- previous_variants hold the avatar it replaced, restored if it is flagged.
+-- Flagged objects are moved to the quarantine bucket until reviewed.
+CREATE TABLE IF NOT EXISTS moderation_items (
+    id                UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    subject_type      VARCHAR(20)  NOT NULL CHECK (subject_type IN ('user_avatar', 'group_avatar')),
+    subject_id        UUID         NOT NULL,
+    object_key        TEXT         NOT NULL,
+    variants          BOOLEAN      NOT NULL DEFAULT FALSE,
+    previous_key      TEXT,
+    previous_variants BOOLEAN      NOT NULL DEFAULT FALSE,
+    status            VARCHAR(10)  NOT NULL DEFAULT 'pending'
+                      CHECK (status IN ('pending', 'clear', 'flagged', 'approved', 'rejected')),
+    reason            TEXT,
+    attempts          INT          NOT NULL DEFAULT 0,
+    next_check_at     TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    checked_at        TIMESTAMPTZ,
+    reviewed_by       UUID         REFERENCES users (id) ON DELETE SET NULL,
+    reviewed_at       TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_moderation_items_due
+    ON moderation_items (next_check_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_moderation_items_status ON moderation_items (status, created_at);
+CREATE INDEX IF NOT EXISTS idx_moderation_items_object_key ON moderation_items (object_key);
+
+CREATE TRIGGER moderation_items_set_updated_at
+    BEFORE UPDATE ON moderation_items
+    FOR EACH ROW EXECUTE FUNCTION trigger_set_updated_at();
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_cover_key;
+DROP TABLE IF EXISTS user_gallery_images;
+ALTER TABLE users DROP COLUMN IF EXISTS cover_key;
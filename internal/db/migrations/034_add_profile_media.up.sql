@@ -0,0 +1,16 @@
+-- Cover photo shown behind the avatar on profiles.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS cover_key TEXT;
+
+-- Gallery images of business profiles, shown in upload order.
+CREATE TABLE IF NOT EXISTS user_gallery_images (
+    id         UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id    UUID         NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    object_key TEXT         NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_gallery_images_user ON user_gallery_images (user_id, created_at);
+
+-- Lookups by object key for the storage garbage collector.
+CREATE INDEX IF NOT EXISTS idx_users_cover_key ON users (cover_key) WHERE cover_key IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_user_gallery_images_object_key ON user_gallery_images (object_key);
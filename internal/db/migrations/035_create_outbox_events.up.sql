@@ -0,0 +1,21 @@
+-- Domain events waiting to be published to the event bus. The relay claims
+-- due rows, publishes them and stamps published_at; failed publishes are
+-- retried from next_attempt_at. Published rows are pruned after a retention
+-- period.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id              UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_type      VARCHAR(100) NOT NULL,
+    event_version   INT          NOT NULL,
+    subject_id      TEXT         NOT NULL,
+    payload         JSONB        NOT NULL,
+    attempts        INT          NOT NULL DEFAULT 0,
+    last_error      TEXT,
+    next_attempt_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    published_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_due
+    ON outbox_events (next_attempt_at) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_published
+    ON outbox_events (published_at) WHERE published_at IS NOT NULL;
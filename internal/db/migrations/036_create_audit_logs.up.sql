@@ -0,0 +1,39 @@
+-- Append-only record of sensitive actions: who (actor) did what (action) to
+-- which record (target), from where, and which fields changed. Rows are
+-- never updated or deleted; the trigger below rejects any attempt.
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id          UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    action      VARCHAR(100) NOT NULL,
+    actor_id    UUID,
+    actor_role  VARCHAR(20),
+    target_type VARCHAR(50),
+    target_id   TEXT,
+    ip          TEXT,
+    request_id  TEXT,
+    before      JSONB,
+    after       JSONB,
+    metadata    JSONB,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created
+    ON audit_logs (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor
+    ON audit_logs (actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_target
+    ON audit_logs (target_type, target_id, created_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_logs_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_no_update_delete
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+CREATE TRIGGER audit_logs_no_truncate
+    BEFORE TRUNCATE ON audit_logs
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_logs_append_only();
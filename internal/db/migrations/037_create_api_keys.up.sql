@@ -0,0 +1,21 @@
+-- Keys for partner and internal services calling the API server to server.
+-- Only a SHA-256 of each key is stored. During rotation the previous key
+-- keeps working until previous_expires_at so callers can redeploy.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id                  UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    name                VARCHAR(100) NOT NULL,
+    key_hash            BYTEA        NOT NULL UNIQUE,
+    hint                VARCHAR(20)  NOT NULL,
+    scopes              TEXT[]       NOT NULL,
+    previous_key_hash   BYTEA,
+    previous_expires_at TIMESTAMPTZ,
+    created_by          UUID         REFERENCES users (id) ON DELETE SET NULL,
+    last_used_at        TIMESTAMPTZ,
+    expires_at          TIMESTAMPTZ,
+    revoked_at          TIMESTAMPTZ,
+    created_at          TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_previous_hash
+    ON api_keys (previous_key_hash)
+    WHERE previous_key_hash IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Fails if a deleted account's phone or username was claimed again; purge
+-- such rows first.
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS users_username_live_key;
+DROP INDEX IF EXISTS users_phone_live_key;
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+ALTER TABLE users ADD CONSTRAINT users_phone_key UNIQUE (phone);
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_at;
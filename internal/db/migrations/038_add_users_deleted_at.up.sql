@@ -0,0 +1,11 @@
+-- Soft delete: a deleted account keeps its row, and everything hanging off
+-- it, until it is restored or purged. Phone and username stay unique among
+-- live accounts only, so both can be claimed again after a deletion.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_phone_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_live_key ON users (phone) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_live_key ON users (username) WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users (deleted_at) WHERE deleted_at IS NOT NULL;
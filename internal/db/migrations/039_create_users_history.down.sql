@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS users_record_history ON users;
+DROP FUNCTION IF EXISTS users_record_history();
+DROP TABLE IF EXISTS users_history;
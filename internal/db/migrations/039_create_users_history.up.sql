@@ -0,0 +1,41 @@
+-- Every change to a user row, however it is made — API, admin tooling or a
+-- manual fix in psql — leaves the previous values of the changed columns
+-- here. updated_at is kept current by users_set_updated_at; valid_from is
+-- the old row's updated_at, so each entry covers [valid_from, created_at).
+-- Who made a change is in audit_logs; this table knows only what changed.
+CREATE TABLE IF NOT EXISTS users_history (
+    id          UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    -- Not a foreign key: history outlives a hard-deleted user.
+    user_id     UUID         NOT NULL,
+    operation   VARCHAR(10)  NOT NULL CHECK (operation IN ('update', 'delete')),
+    previous    JSONB        NOT NULL,
+    valid_from  TIMESTAMPTZ  NOT NULL,
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_users_history_user
+    ON users_history (user_id, created_at DESC, id DESC);
+
+CREATE OR REPLACE FUNCTION users_record_history()
+RETURNS TRIGGER AS $$
+DECLARE
+    old_row  JSONB := to_jsonb(OLD) - 'updated_at';
+    new_row  JSONB := CASE WHEN TG_OP = 'UPDATE' THEN to_jsonb(NEW) - 'updated_at' ELSE '{}' END;
+    previous JSONB;
+BEGIN
+    SELECT jsonb_object_agg(o.key, o.value) INTO previous
+    FROM jsonb_each(old_row) o
+    WHERE TG_OP = 'DELETE' OR new_row -> o.key IS DISTINCT FROM o.value;
+
+    -- An update that changed nothing but updated_at is not history.
+    IF previous IS NOT NULL THEN
+        INSERT INTO users_history (user_id, operation, previous, valid_from)
+        VALUES (OLD.id, lower(TG_OP), previous, OLD.updated_at);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_record_history
+    AFTER UPDATE OR DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION users_record_history();
@@ -0,0 +1,11 @@
+-- Hashes looked up through contact sync per user and day. The phone number
+-- space is small enough to enumerate, so discovery is capped per day; old
+-- rows are purged by the cron scheduler.
+CREATE TABLE IF NOT EXISTS contact_lookups_daily (
+    user_id UUID    NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    day     DATE    NOT NULL,
+    hashes  INTEGER NOT NULL,
+    PRIMARY KEY (user_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_lookups_daily_day ON contact_lookups_daily (day);
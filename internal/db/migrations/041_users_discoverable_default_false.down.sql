@@ -0,0 +1 @@
+ALTER TABLE users ALTER COLUMN discoverable SET DEFAULT TRUE;
@@ -0,0 +1,3 @@
+-- New accounts stay out of contact-sync matches until they opt in. Existing
+-- accounts keep their current setting.
+ALTER TABLE users ALTER COLUMN discoverable SET DEFAULT FALSE;
@@ -0,0 +1,22 @@
+-- Audit trail exports handed to regulators and courts. The archive itself
+-- lives in the private audit bucket under object_key; head_hash is the last
+-- link of its hash chain and signature the Ed25519 signature of its
+-- manifest, kept here so a returned archive can be checked against them.
+CREATE TABLE IF NOT EXISTS audit_exports (
+    id           UUID         PRIMARY KEY DEFAULT gen_random_uuid(),
+    requested_by UUID         REFERENCES users (id) ON DELETE SET NULL,
+    user_id      UUID,
+    from_at      TIMESTAMPTZ,
+    to_at        TIMESTAMPTZ  NOT NULL,
+    reason       TEXT         NOT NULL,
+    object_key   TEXT         NOT NULL DEFAULT '',
+    record_count INT          NOT NULL DEFAULT 0,
+    head_hash    TEXT         NOT NULL DEFAULT '',
+    signature    TEXT         NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_exports_created
+    ON audit_exports (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_exports_object_key
+    ON audit_exports (object_key);
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Reader sends read-only queries to a replica, falling back to the primary
+// while the replica is unreachable. Replicas lag, so only reads that can
+// tolerate a few seconds of staleness belong here; anything that reads its
+// own writes stays on the primary.
+type Reader struct {
+	primary *pgxpool.Pool
+	replica *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+var _ Querier = (*Reader)(nil)
+
+// NewReader creates a Reader over replica. With a nil replica every query
+// goes to primary.
+func NewReader(primary, replica *pgxpool.Pool) *Reader {
+	r := &Reader{primary: primary, replica: replica}
+	r.healthy.Store(replica != nil)
+	return r
+}
+
+// pool returns the pool to query: the replica while it is healthy.
+func (r *Reader) pool() *pgxpool.Pool {
+	if r.healthy.Load() {
+		return r.replica
+	}
+	return r.primary
+}
+
+// Begin starts a read-only transaction. It does not fall back: a failure
+// half way through a transaction cannot be retried elsewhere.
+func (r *Reader) Begin(ctx context.Context) (pgx.Tx, error) {
+	return r.pool().BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+}
+
+// Exec runs a statement that does not return rows, such as SET.
+func (r *Reader) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	p := r.pool()
+	tag, err := p.Exec(ctx, sql, args...)
+	if r.failover(ctx, p, err) {
+		return r.primary.Exec(ctx, sql, args...)
+	}
+	return tag, err
+}
+
+// Query runs a query, on the primary if the replica cannot be reached.
+func (r *Reader) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	p := r.pool()
+	rows, err := p.Query(ctx, sql, args...)
+	if r.failover(ctx, p, err) {
+		return r.primary.Query(ctx, sql, args...)
+	}
+	return rows, err
+}
+
+// QueryRow runs a query expected to return at most one row. Errors surface
+// on Scan, so that is where it falls back.
+func (r *Reader) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return &readerRow{r: r, ctx: ctx, sql: sql, args: args}
+}
+
+type readerRow struct {
+	r    *Reader
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+func (row *readerRow) Scan(dest ...any) error {
+	p := row.r.pool()
+	err := p.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+	if row.r.failover(row.ctx, p, err) {
+		return row.r.primary.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+	}
+	return err
+}
+
+// failover reports whether a query on p should be retried on the primary:
+// p is the replica and err means it could not be reached. Errors Postgres
+// itself returned, and those caused by the caller's context, are final.
+// The replica is taken out of rotation until Run sees it answer again.
+func (r *Reader) failover(ctx context.Context, p *pgxpool.Pool, err error) bool {
+	if err == nil || p != r.replica || ctx.Err() != nil || errors.Is(err, pgx.ErrNoRows) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return false
+	}
+	if r.healthy.CompareAndSwap(true, false) {
+		slog.Warn("db: read replica unavailable, reading from primary", "err", err)
+	}
+	return true
+}
+
+// Run pings the replica every interval and puts it back into rotation once
+// it answers, until ctx is cancelled. It returns at once without a replica.
+func (r *Reader) Run(ctx context.Context, interval time.Duration) {
+	if r.replica == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, interval)
+		err := r.replica.Ping(pingCtx)
+		cancel()
+		switch {
+		case err == nil && r.healthy.CompareAndSwap(false, true):
+			slog.Info("db: read replica available again")
+		case err != nil && ctx.Err() == nil && r.healthy.CompareAndSwap(true, false):
+			slog.Warn("db: read replica unavailable, reading from primary", "err", err)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// tracerChain runs several pgx tracers as one. Start hooks run in order,
+// each receiving the context returned by the previous one, and end hooks in
+// reverse.
+type tracerChain []pgx.QueryTracer
+
+// chainTracers returns the non-nil tracers as one, or nil if there are none.
+func chainTracers(tracers []pgx.QueryTracer) pgx.QueryTracer {
+	var chain tracerChain
+	for _, t := range tracers {
+		if t != nil {
+			chain = append(chain, t)
+		}
+	}
+	switch len(chain) {
+	case 0:
+		return nil
+	case 1:
+		return chain[0]
+	}
+	return chain
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (c tracerChain) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	for _, t := range c {
+		ctx = t.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (c tracerChain) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	for i := len(c) - 1; i >= 0; i-- {
+		c[i].TraceQueryEnd(ctx, conn, data)
+	}
+}
+
+// TraceBatchStart implements pgx.BatchTracer for the tracers that support it.
+func (c tracerChain) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	for _, t := range c {
+		if bt, ok := t.(pgx.BatchTracer); ok {
+			ctx = bt.TraceBatchStart(ctx, conn, data)
+		}
+	}
+	return ctx
+}
+
+// TraceBatchQuery implements pgx.BatchTracer.
+func (c tracerChain) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	for _, t := range c {
+		if bt, ok := t.(pgx.BatchTracer); ok {
+			bt.TraceBatchQuery(ctx, conn, data)
+		}
+	}
+}
+
+// TraceBatchEnd implements pgx.BatchTracer.
+func (c tracerChain) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	for i := len(c) - 1; i >= 0; i-- {
+		if bt, ok := c[i].(pgx.BatchTracer); ok {
+			bt.TraceBatchEnd(ctx, conn, data)
+		}
+	}
+}
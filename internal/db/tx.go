@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Querier is what repositories run queries on. Both *pgxpool.Pool and
+// pgx.Tx satisfy it; on a transaction Begin starts a savepoint, so a
+// repository method that needs its own transaction still works inside an
+// outer one.
+type Querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+var (
+	_ Querier = (*pgxpool.Pool)(nil)
+	_ Querier = pgx.Tx(nil)
+)
+
+// Transactor runs fn in one transaction, as TxManager does. Services
+// depend on it so their unit tests can run fn without a database.
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error
+}
+
+var _ Transactor = (*TxManager)(nil)
+
+// TxManager runs work spanning several repositories in one transaction.
+type TxManager struct {
+	pool *pgxpool.Pool
+}
+
+// NewTxManager creates a TxManager on pool.
+func NewTxManager(pool *pgxpool.Pool) *TxManager {
+	return &TxManager{pool: pool}
+}
+
+// WithTx begins a transaction and calls fn with it. Repositories join the
+// transaction through their WithTx method:
+//
+//	err := txm.WithTx(ctx, func(tx pgx.Tx) error {
+//		if err := otps.WithTx(tx).MarkOTPUsed(ctx, otpID); err != nil {
+//			return err
+//		}
+//		_, err := users.WithTx(tx).Create(ctx, phone, "personal")
+//		return err
+//	})
+//
+// The transaction commits if fn returns nil and rolls back otherwise,
+// including when fn panics.
+func (m *TxManager) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+// Package deeplink builds the typed in-app navigation targets attached to
+// notifications and push payloads, so clients can open the right screen
+// without an extra API call.
+//
+// Links are generated here and nowhere else; clients switch on Route and read
+// Params. SchemaVersion is bumped whenever a route's params change shape, and
+// clients ignore links whose version they do not understand.
+package deeplink
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SchemaVersion is the current deep-link payload schema version.
+const SchemaVersion = 1
+
+// Scheme is the app URL scheme used for the Path form of a link.
+const Scheme = "radif"
+
+// Route identifies an app screen.
+type Route string
+
+// Known routes.
+const (
+	RouteTransaction  Route = "transaction"
+	RouteRequest      Route = "request"
+	RouteProfile      Route = "profile"
+	RouteGroupInvite  Route = "group-invite"
+	RouteConversation Route = "conversation"
+)
+
+// routeParams lists the required params for each route, in path order.
+var routeParams = map[Route][]string{
+	RouteTransaction:  {"id"},
+	RouteRequest:      {"id"},
+	RouteProfile:      {"id"},
+	RouteGroupInvite:  {"code"},
+	RouteConversation: {"id"},
+}
+
+// Link is a versioned deep-link payload.
+type Link struct {
+	Version int               `json:"v"`
+	Route   Route             `json:"route"`
+	Params  map[string]string `json:"params"`
+	URL     string            `json:"url"`
+}
+
+// Transaction links to a transaction's detail screen.
+func Transaction(id string) Link { return mustBuild(RouteTransaction, id) }
+
+// Request links to a payment request's detail screen.
+func Request(id string) Link { return mustBuild(RouteRequest, id) }
+
+// Profile links to a user's public profile.
+func Profile(userID string) Link { return mustBuild(RouteProfile, userID) }
+
+// GroupInvite links to the join screen of a group invite link.
+func GroupInvite(code string) Link { return mustBuild(RouteGroupInvite, code) }
+
+// Conversation links to a 1:1 message thread.
+func Conversation(id string) Link { return mustBuild(RouteConversation, id) }
+
+// mustBuild builds a link for a route whose params are positional.
+func mustBuild(route Route, values ...string) Link {
+	names := routeParams[route]
+	params := make(map[string]string, len(names))
+	for i, name := range names {
+		params[name] = values[i]
+	}
+	l, err := Build(route, params)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}
+
+// Build validates params against the route and returns a link at the
+// current schema version.
+func Build(route Route, params map[string]string) (Link, error) {
+	names, ok := routeParams[route]
+	if !ok {
+		return Link{}, fmt.Errorf("deeplink: unknown route %q", route)
+	}
+	segments := []string{string(route)}
+	for _, name := range names {
+		v := params[name]
+		if v == "" {
+			return Link{}, fmt.Errorf("deeplink: route %q requires param %q", route, name)
+		}
+		segments = append(segments, url.PathEscape(v))
+	}
+	if len(params) != len(names) {
+		return Link{}, fmt.Errorf("deeplink: route %q got unexpected params", route)
+	}
+	return Link{
+		Version: SchemaVersion,
+		Route:   route,
+		Params:  params,
+		URL:     Scheme + "://" + strings.Join(segments, "/"),
+	}, nil
+}
+
+// Data flattens the link into string key/values for push providers (FCM/APNs
+// data payloads) that only accept flat string maps.
+func (l Link) Data() map[string]string {
+	return map[string]string{
+		"deeplink_v":     fmt.Sprint(l.Version),
+		"deeplink_route": string(l.Route),
+		"deeplink_url":   l.URL,
+	}
+}
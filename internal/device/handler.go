@@ -0,0 +1,141 @@
+package device
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for device endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new device Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type registerRequest struct {
+	PushToken  string  `json:"pushToken"  example:"fcm-token"`
+	Platform   string  `json:"platform"   example:"android"`
+	AppVersion *string `json:"appVersion" example:"1.4.2"`
+	Model      *string `json:"model"      example:"Samsung SM-A546E"`
+}
+
+// Register godoc
+//
+//	@Summary		Register device
+//	@Description	Register or refresh this installation's push token. Call on every app start and whenever the token rotates. A user keeps at most 10 devices; the least recently seen are dropped.
+//	@Tags			devices
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		registerRequest	true	"Device details"
+//	@Success		200		{object}	response.Envelope{data=Device}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/devices [put]
+func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req registerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if req.PushToken == "" || len(req.PushToken) > 4096 {
+		response.BadRequest(w, "pushToken is required")
+		return
+	}
+	if (req.AppVersion != nil && len(*req.AppVersion) > 20) || (req.Model != nil && len(*req.Model) > 100) {
+		response.BadRequest(w, "appVersion or model is too long")
+		return
+	}
+
+	d, err := h.svc.Register(r.Context(), userID, RegisterParams{
+		PushToken:  req.PushToken,
+		Platform:   req.Platform,
+		AppVersion: req.AppVersion,
+		Model:      req.Model,
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvalidPlatform) {
+			response.BadRequest(w, "platform must be one of: android, ios, web")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, d)
+}
+
+// List godoc
+//
+//	@Summary		List devices
+//	@Description	Returns the authenticated user's registered devices, most recently seen first.
+//	@Tags			devices
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]Device}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/devices [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	devices, err := h.svc.List(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, devices)
+}
+
+// Remove godoc
+//
+//	@Summary		Remove device
+//	@Description	Unregister a device so it stops receiving pushes (e.g. on logout).
+//	@Tags			devices
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Device ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/devices/{id} [delete]
+func (h *Handler) Remove(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.Remove(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			response.NotFound(w, "device not found")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
@@ -0,0 +1,104 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/radif/service/internal/notification"
+)
+
+// ErrInvalidToken is returned by a Provider when the token is no longer
+// registered (FCM UNREGISTERED / INVALID_ARGUMENT). The device is pruned.
+var ErrInvalidToken = errors.New("push token is invalid")
+
+// Payload is a push message as handed to a Provider.
+type Payload struct {
+	Title string
+	Body  string
+	// Data carries the flattened deep link and the notification type.
+	Data map[string]string
+}
+
+// Provider is a push gateway integration (FCM, APNs).
+type Provider interface {
+	Send(ctx context.Context, platform, token string, p Payload) error
+}
+
+// activeOnlyPrefixes lists notification type prefixes delivered only to the
+// user's most recently active device, so a chat-style event doesn't buzz
+// every phone at once. Everything else, including security alerts such as
+// auth.new_login, goes to all devices.
+var activeOnlyPrefixes = []string{"message."}
+
+// Pusher fans notifications out to a user's devices. It implements
+// notification.Sender.
+type Pusher struct {
+	repo     *Repository
+	provider Provider
+}
+
+// NewPusher creates a Pusher. provider may be nil, in which case pushes are
+// only logged.
+func NewPusher(repo *Repository, provider Provider) *Pusher {
+	return &Pusher{repo: repo, provider: provider}
+}
+
+// Send pushes m to the user's devices according to the fan-out rules. Tokens
+// the provider rejects as invalid are pruned; other per-device failures are
+// logged and only fail the call when no device received the message.
+func (p *Pusher) Send(ctx context.Context, userID string, m notification.Message) error {
+	devices, err := p.repo.ListByUser(ctx, userID, time.Now().Add(-staleAfter))
+	if err != nil {
+		return err
+	}
+	if len(devices) == 0 {
+		return nil
+	}
+	if activeOnly(m.Type) {
+		devices = devices[:1] // ordered by last_seen_at DESC
+	}
+
+	payload := Payload{Title: m.Title, Body: m.Body, Data: map[string]string{"type": m.Type}}
+	if m.DeepLink != nil {
+		for k, v := range m.DeepLink.Data() {
+			payload.Data[k] = v
+		}
+	}
+
+	var failed int
+	for _, d := range devices {
+		if p.provider == nil {
+			slog.InfoContext(ctx, "device: push logged", "user_id", userID, "device_id", d.ID, "type", m.Type, "title", m.Title)
+			continue
+		}
+		err := p.provider.Send(ctx, d.Platform, d.PushToken, payload)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrInvalidToken):
+			if err := p.repo.DeleteByToken(ctx, d.PushToken); err != nil {
+				slog.ErrorContext(ctx, "device: prune failed", "device_id", d.ID, "err", err)
+			}
+		default:
+			failed++
+			slog.WarnContext(ctx, "device: push failed", "device_id", d.ID, "err", err)
+		}
+	}
+	if failed == len(devices) {
+		return fmt.Errorf("push failed on all %d devices", failed)
+	}
+	return nil
+}
+
+// activeOnly reports whether a notification type goes to the active device only.
+func activeOnly(notificationType string) bool {
+	for _, prefix := range activeOnlyPrefixes {
+		if strings.HasPrefix(notificationType, prefix) {
+			return true
+		}
+	}
+	return false
+}
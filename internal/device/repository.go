@@ -0,0 +1,153 @@
+// Package device tracks users' app installations and their push tokens, and
+// fans push notifications out to them.
+package device
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Platform values for a device.
+const (
+	PlatformAndroid = "android"
+	PlatformIOS     = "ios"
+	PlatformWeb     = "web"
+)
+
+// Device is a registered app installation.
+type Device struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"-"`
+	PushToken  string    `json:"-"`
+	Platform   string    `json:"platform"`
+	AppVersion *string   `json:"appVersion,omitempty"`
+	Model      *string   `json:"model,omitempty"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// ErrNotFound is returned when a device does not exist for the user.
+var ErrNotFound = errors.New("device not found")
+
+// Repository handles device persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new device Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const selectCols = `id, user_id, push_token, platform, app_version, model, last_seen_at, created_at`
+
+// scanDevice scans a full devices row into a Device value.
+func scanDevice(row pgx.Row, d *Device) error {
+	return row.Scan(&d.ID, &d.UserID, &d.PushToken, &d.Platform, &d.AppVersion, &d.Model, &d.LastSeenAt, &d.CreatedAt)
+}
+
+// Upsert registers a push token for the user, or refreshes it. A token
+// previously registered by another user moves to this one, since the
+// installation has changed hands (logout and login as someone else).
+func (r *Repository) Upsert(ctx context.Context, d *Device) (*Device, error) {
+	out := &Device{}
+	err := scanDevice(r.db.QueryRow(ctx,
+		`INSERT INTO devices (user_id, push_token, platform, app_version, model)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (push_token) DO UPDATE SET
+		     user_id      = EXCLUDED.user_id,
+		     platform     = EXCLUDED.platform,
+		     app_version  = EXCLUDED.app_version,
+		     model        = EXCLUDED.model,
+		     last_seen_at = NOW()
+		 RETURNING `+selectCols,
+		d.UserID, d.PushToken, d.Platform, d.AppVersion, d.Model,
+	), out)
+	if err != nil {
+		return nil, fmt.Errorf("upsert device: %w", err)
+	}
+	return out, nil
+}
+
+// ListByUser returns the user's devices, most recently seen first. When
+// seenSince is non-zero, devices not seen since then are left out.
+func (r *Repository) ListByUser(ctx context.Context, userID string, seenSince time.Time) ([]*Device, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+selectCols+` FROM devices
+		 WHERE user_id = $1 AND last_seen_at >= $2
+		 ORDER BY last_seen_at DESC`,
+		userID, seenSince,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	defer rows.Close()
+
+	devices := []*Device{}
+	for rows.Next() {
+		d := &Device{}
+		if err := scanDevice(rows, d); err != nil {
+			return nil, fmt.Errorf("scan device: %w", err)
+		}
+		devices = append(devices, d)
+	}
+	return devices, rows.Err()
+}
+
+// Delete removes one of the user's devices.
+func (r *Repository) Delete(ctx context.Context, userID, id string) error {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM devices WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("delete device: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteByToken removes the device holding a push token the provider has
+// reported as invalid.
+func (r *Repository) DeleteByToken(ctx context.Context, token string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM devices WHERE push_token = $1`, token)
+	if err != nil {
+		return fmt.Errorf("delete device by token: %w", err)
+	}
+	return nil
+}
+
+// TrimToNewest keeps only the user's keep most recently seen devices.
+func (r *Repository) TrimToNewest(ctx context.Context, userID string, keep int) error {
+	_, err := r.db.Exec(ctx,
+		`DELETE FROM devices
+		 WHERE user_id = $1 AND id NOT IN (
+		     SELECT id FROM devices WHERE user_id = $1
+		     ORDER BY last_seen_at DESC
+		     LIMIT $2
+		 )`,
+		userID, keep,
+	)
+	if err != nil {
+		return fmt.Errorf("trim devices: %w", err)
+	}
+	return nil
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
@@ -0,0 +1,69 @@
+package device
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// MaxDevices caps registered devices per user; registering beyond it drops
+// the least recently seen ones.
+const MaxDevices = 10
+
+// staleAfter is how long a device may go unseen before pushes skip it.
+const staleAfter = 60 * 24 * time.Hour
+
+// ErrInvalidPlatform is returned for a platform other than android, ios or web.
+var ErrInvalidPlatform = errors.New("invalid platform")
+
+// RegisterParams holds the fields a client reports when registering.
+type RegisterParams struct {
+	PushToken  string
+	Platform   string
+	AppVersion *string
+	Model      *string
+}
+
+// Service contains business logic for devices.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new device Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Register records or refreshes the caller's device. Clients call it on every
+// app start and whenever the push provider rotates the token.
+func (s *Service) Register(ctx context.Context, userID string, p RegisterParams) (*Device, error) {
+	switch p.Platform {
+	case PlatformAndroid, PlatformIOS, PlatformWeb:
+	default:
+		return nil, ErrInvalidPlatform
+	}
+	d, err := s.repo.Upsert(ctx, &Device{
+		UserID:     userID,
+		PushToken:  p.PushToken,
+		Platform:   p.Platform,
+		AppVersion: p.AppVersion,
+		Model:      p.Model,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.TrimToNewest(ctx, userID, MaxDevices); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// List returns all of the caller's devices.
+func (s *Service) List(ctx context.Context, userID string) ([]*Device, error) {
+	return s.repo.ListByUser(ctx, userID, time.Time{})
+}
+
+// Remove unregisters one of the caller's devices, e.g. on logout.
+func (s *Service) Remove(ctx context.Context, userID, id string) error {
+	return s.repo.Delete(ctx, userID, id)
+}
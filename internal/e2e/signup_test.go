@@ -0,0 +1,199 @@
+// Package e2e tests whole user journeys through the HTTP API, against the
+// PostgreSQL and MinIO containers of package testutil.
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/radif/service/internal/testutil"
+)
+
+const phone = "09121234567"
+
+// envelope is the API response envelope, with data left raw.
+type envelope struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+	Code    string          `json:"code"`
+}
+
+// client calls srv as one user, sending token once it is set.
+type client struct {
+	t     *testing.T
+	base  string
+	token string
+}
+
+// do sends a request with body and decodes the response envelope's data
+// into out when non-nil. It fails the test unless the status is want.
+func (c *client) do(method, path, contentType string, body []byte, want int, out any) envelope {
+	c.t.Helper()
+	req, err := http.NewRequest(method, c.base+path, bytes.NewReader(body))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		c.t.Fatalf("%s %s: decode response: %v", method, path, err)
+	}
+	if resp.StatusCode != want {
+		c.t.Fatalf("%s %s: status %d (code %q), want %d", method, path, resp.StatusCode, env.Code, want)
+	}
+	if out != nil {
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			c.t.Fatalf("%s %s: decode data: %v", method, path, err)
+		}
+	}
+	return env
+}
+
+// json sends v as a JSON body.
+func (c *client) json(method, path string, v any, want int, out any) envelope {
+	c.t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	return c.do(method, path, "application/json", body, want, out)
+}
+
+// verifyResponse is the data of POST /auth/otp/verify.
+type verifyResponse struct {
+	IsNewUser         bool   `json:"isNewUser"`
+	Token             string `json:"token"`
+	RegistrationToken string `json:"registrationToken"`
+}
+
+// profile is the part of a user's profile the tests check.
+type profile struct {
+	ID        string  `json:"id"`
+	Phone     string  `json:"phone"`
+	FullName  *string `json:"fullName"`
+	AvatarURL *string `json:"avatarUrl"`
+}
+
+func TestSignUpAndProfile(t *testing.T) {
+	env := testutil.NewEnv(t)
+	srv := testutil.NewServer(t, env)
+	c := &client{t: t, base: srv.URL + "/api/v1"}
+
+	// Sign up: a new phone gets a registration token, not a session.
+	c.json(http.MethodPost, "/auth/otp/send", map[string]string{"phone": phone}, http.StatusOK, nil)
+	var verified verifyResponse
+	c.json(http.MethodPost, "/auth/otp/verify", map[string]string{"phone": phone, "code": env.LatestOTP(t, phone)}, http.StatusOK, &verified)
+	if !verified.IsNewUser || verified.Token != "" || verified.RegistrationToken == "" {
+		t.Fatalf("verify = %+v, want a new user with a registration token", verified)
+	}
+
+	register := map[string]string{"phone": phone, "accountType": "personal", "registrationToken": verified.RegistrationToken}
+	var registered struct {
+		Token string  `json:"token"`
+		User  profile `json:"user"`
+	}
+	c.json(http.MethodPost, "/auth/register", register, http.StatusCreated, &registered)
+	if registered.Token == "" || registered.User.ID == "" {
+		t.Fatalf("register = %+v, want a token and user", registered)
+	}
+
+	// The registration token was spent creating the account.
+	c.json(http.MethodPost, "/auth/register", register, http.StatusUnauthorized, nil)
+
+	c.token = registered.Token
+	var me profile
+	c.do(http.MethodGet, "/users/me", "", nil, http.StatusOK, &me)
+	if me.ID != registered.User.ID || me.Phone != phone {
+		t.Fatalf("GET /users/me = %+v, want user %s with phone %s", me, registered.User.ID, phone)
+	}
+
+	c.json(http.MethodPatch, "/users/me", map[string]string{"fullName": "Sara Ahmadi"}, http.StatusOK, &me)
+	if me.FullName == nil || *me.FullName != "Sara Ahmadi" {
+		t.Fatalf("PATCH /users/me fullName = %v, want %q", me.FullName, "Sara Ahmadi")
+	}
+
+	// The avatar is stored in MinIO and served from its public URL.
+	body, contentType := avatarForm(t)
+	var avatar struct {
+		AvatarURL string `json:"avatarUrl"`
+	}
+	c.do(http.MethodPost, "/users/me/avatar", contentType, body, http.StatusOK, &avatar)
+	if avatar.AvatarURL == "" {
+		t.Fatal("POST /users/me/avatar returned no avatarUrl")
+	}
+	resp, err := http.Get(avatar.AvatarURL)
+	if err != nil {
+		t.Fatalf("GET avatar: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET avatar: status %d, want 200", resp.StatusCode)
+	}
+
+	c.do(http.MethodGet, "/users/me", "", nil, http.StatusOK, &me)
+	if me.AvatarURL == nil || *me.AvatarURL != avatar.AvatarURL {
+		t.Fatalf("GET /users/me avatarUrl = %v, want %q", me.AvatarURL, avatar.AvatarURL)
+	}
+
+	// Signing in again with a fresh code returns a session directly.
+	c.token = ""
+	c.json(http.MethodPost, "/auth/otp/send", map[string]string{"phone": phone}, http.StatusOK, nil)
+	c.json(http.MethodPost, "/auth/otp/verify", map[string]string{"phone": phone, "code": env.LatestOTP(t, phone)}, http.StatusOK, &verified)
+	if verified.IsNewUser || verified.Token == "" {
+		t.Fatalf("second verify = %+v, want an existing user with a token", verified)
+	}
+}
+
+func TestProfileRequiresAuth(t *testing.T) {
+	env := testutil.NewEnv(t)
+	srv := testutil.NewServer(t, env)
+	c := &client{t: t, base: srv.URL + "/api/v1"}
+
+	c.do(http.MethodGet, "/users/me", "", nil, http.StatusUnauthorized, nil)
+
+	c.token = "not-a-token"
+	c.do(http.MethodGet, "/users/me", "", nil, http.StatusUnauthorized, nil)
+}
+
+// avatarForm returns a multipart form carrying a small PNG as the avatar
+// field, and its content type.
+func avatarForm(t *testing.T) ([]byte, string) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 256, 256))
+	for x := 0; x < 256; x++ {
+		for y := 0; y < 256; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	part, err := w.CreateFormFile("avatar", "avatar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(part, img); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), w.FormDataContentType()
+}
@@ -0,0 +1,130 @@
+// Package events publishes domain events — sign-ups, sign-ins, profile
+// changes, payments — to an event bus for analytics, fraud and notification
+// services, and merchant events to the merchant's webhooks. Services write
+// events to an outbox table; a relay publishes them in the background and
+// retries until the bus and the webhook queue accept them, so an outage
+// never fails a request.
+package events
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// source identifies this service in the event envelope.
+const source = "radif"
+
+// Event types. A breaking change to a payload ships as a new version
+// published next to the old one until every consumer has moved over.
+const (
+	TypeUserRegistered     = "user.registered"
+	TypeUserProfileUpdated = "user.profile_updated"
+	TypeUserDeleted        = "user.deleted"
+	TypeUserRestored       = "user.restored"
+	TypeAuthLoggedIn       = "auth.logged_in"
+	TypeAuthLoggedOut      = "auth.logged_out"
+	TypePaymentReceived    = "payment.received"
+)
+
+// merchantEvents maps the event types delivered to merchant webhooks to the
+// version merchants receive. The merchant is the event's SubjectID.
+var merchantEvents = map[string]int{
+	TypePaymentReceived: 1,
+}
+
+// Event is the envelope every event is published in. ID is unique per event
+// and stable across redeliveries, so consumers can deduplicate on it.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	Source     string          `json:"source"`
+	SubjectID  string          `json:"subjectId"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// Payload is the data of one event type at one version. SubjectID is the
+// entity the event is about, usually a user ID.
+type Payload interface {
+	EventType() string
+	EventVersion() int
+	SubjectID() string
+}
+
+// UserRegisteredV1 is published when an account is created.
+type UserRegisteredV1 struct {
+	UserID      string `json:"userId"`
+	AccountType string `json:"accountType"`
+	Referred    bool   `json:"referred"`
+}
+
+func (UserRegisteredV1) EventType() string   { return TypeUserRegistered }
+func (UserRegisteredV1) EventVersion() int   { return 1 }
+func (p UserRegisteredV1) SubjectID() string { return p.UserID }
+
+// UserProfileUpdatedV1 is published when a user changes their profile.
+// Fields names what changed, not the new values; consumers that need them
+// read the profile from the API.
+type UserProfileUpdatedV1 struct {
+	UserID string   `json:"userId"`
+	Fields []string `json:"fields"`
+}
+
+func (UserProfileUpdatedV1) EventType() string   { return TypeUserProfileUpdated }
+func (UserProfileUpdatedV1) EventVersion() int   { return 1 }
+func (p UserProfileUpdatedV1) SubjectID() string { return p.UserID }
+
+// UserDeletedV1 is published when an account is soft-deleted, by its owner
+// or an admin. It can be restored until RestorableUntil.
+type UserDeletedV1 struct {
+	UserID          string    `json:"userId"`
+	RestorableUntil time.Time `json:"restorableUntil"`
+}
+
+func (UserDeletedV1) EventType() string   { return TypeUserDeleted }
+func (UserDeletedV1) EventVersion() int   { return 1 }
+func (p UserDeletedV1) SubjectID() string { return p.UserID }
+
+// UserRestoredV1 is published when an admin restores a deleted account.
+type UserRestoredV1 struct {
+	UserID string `json:"userId"`
+}
+
+func (UserRestoredV1) EventType() string   { return TypeUserRestored }
+func (UserRestoredV1) EventVersion() int   { return 1 }
+func (p UserRestoredV1) SubjectID() string { return p.UserID }
+
+// AuthLoggedInV1 is published when an existing user signs in.
+type AuthLoggedInV1 struct {
+	UserID string `json:"userId"`
+	Method string `json:"method"`
+}
+
+func (AuthLoggedInV1) EventType() string   { return TypeAuthLoggedIn }
+func (AuthLoggedInV1) EventVersion() int   { return 1 }
+func (p AuthLoggedInV1) SubjectID() string { return p.UserID }
+
+// AuthLoggedOutV1 is published when a user revokes their token.
+type AuthLoggedOutV1 struct {
+	UserID  string `json:"userId"`
+	TokenID string `json:"tokenId"`
+}
+
+func (AuthLoggedOutV1) EventType() string   { return TypeAuthLoggedOut }
+func (AuthLoggedOutV1) EventVersion() int   { return 1 }
+func (p AuthLoggedOutV1) SubjectID() string { return p.UserID }
+
+// PaymentReceivedV1 is published when a business receives a payment.
+// Amount is in the smallest unit of Currency.
+type PaymentReceivedV1 struct {
+	PaymentID  string `json:"paymentId"`
+	MerchantID string `json:"merchantId"`
+	PayerID    string `json:"payerId,omitempty"`
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency"`
+}
+
+func (PaymentReceivedV1) EventType() string   { return TypePaymentReceived }
+func (PaymentReceivedV1) EventVersion() int   { return 1 }
+func (p PaymentReceivedV1) SubjectID() string { return p.MerchantID }
@@ -0,0 +1,86 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// duplicateWindow is how long JetStream remembers event IDs to drop
+// redelivered copies, e.g. when the relay dies before marking an event
+// published.
+const duplicateWindow = 10 * time.Minute
+
+// NATSPublisher publishes events to a NATS JetStream stream, so events are
+// persisted by the bus and consumers can replay them.
+type NATSPublisher struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	stream jetstream.StreamConfig
+	ready  atomic.Bool
+}
+
+// NewNATSPublisher connects to the NATS server at url. The connection is
+// retried in the background, so an unreachable bus delays events instead of
+// stopping the service from starting.
+func NewNATSPublisher(url, prefix string) (*NATSPublisher, error) {
+	conn, err := nats.Connect(url,
+		nats.Name("radif-api"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("open jetstream: %w", err)
+	}
+	return &NATSPublisher{
+		conn: conn,
+		js:   js,
+		stream: jetstream.StreamConfig{
+			Name:       strings.ToUpper(prefix) + "_EVENTS",
+			Subjects:   []string{prefix + ".>"},
+			Storage:    jetstream.FileStorage,
+			Duplicates: duplicateWindow,
+		},
+	}, nil
+}
+
+// Publish implements Publisher. It returns once the stream has stored the
+// message.
+func (p *NATSPublisher) Publish(ctx context.Context, subject, id string, body []byte) error {
+	if err := p.ensureStream(ctx); err != nil {
+		return err
+	}
+	_, err := p.js.Publish(ctx, subject, body, jetstream.WithMsgID(id))
+	return err
+}
+
+// ensureStream creates the stream capturing every event subject the first
+// time it is needed. An existing stream is left as configured, so operators
+// own its retention and replication settings.
+func (p *NATSPublisher) ensureStream(ctx context.Context) error {
+	if p.ready.Load() {
+		return nil
+	}
+	_, err := p.js.CreateStream(ctx, p.stream)
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return fmt.Errorf("create stream: %w", err)
+	}
+	p.ready.Store(true)
+	return nil
+}
+
+// Close flushes pending messages and closes the connection.
+func (p *NATSPublisher) Close() error {
+	return p.conn.Drain()
+}
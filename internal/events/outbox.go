@@ -0,0 +1,41 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Outbox queues events for the relay. A nil Outbox discards them, so
+// services and tests need no checks.
+type Outbox struct {
+	repo *Repository
+}
+
+// NewOutbox creates an Outbox writing to repo.
+func NewOutbox(repo *Repository) *Outbox {
+	return &Outbox{repo: repo}
+}
+
+// WithTx returns an Outbox that writes on tx, so an event is queued only if
+// the change it announces commits.
+func (o *Outbox) WithTx(tx pgx.Tx) *Outbox {
+	if o == nil {
+		return nil
+	}
+	return &Outbox{repo: o.repo.WithTx(tx)}
+}
+
+// Emit queues p for publishing.
+func (o *Outbox) Emit(ctx context.Context, p Payload) error {
+	if o == nil {
+		return nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("marshal %s: %w", p.EventType(), err)
+	}
+	return o.repo.Insert(ctx, p.EventType(), p.EventVersion(), p.SubjectID(), data)
+}
@@ -0,0 +1,154 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/rand/v2"
+	"strconv"
+	"time"
+)
+
+const (
+	pollInterval     = 2 * time.Second
+	claimBatchSize   = 100
+	claimLease       = time.Minute
+	baseRetryBackoff = 5 * time.Second
+	maxRetryBackoff  = 10 * time.Minute
+	pruneInterval    = time.Hour
+	retention        = 7 * 24 * time.Hour
+)
+
+// Publisher delivers one encoded event to the bus. id is the event ID, for
+// brokers that deduplicate redelivered messages.
+type Publisher interface {
+	Publish(ctx context.Context, subject, id string, body []byte) error
+}
+
+// Webhooks queues an event for the webhooks of the merchant it is about;
+// *webhook.Service implements it. Queuing the same eventID again must not
+// deliver it twice.
+type Webhooks interface {
+	Publish(ctx context.Context, eventID, merchantID, eventType string, data any) error
+}
+
+// Relay publishes outbox events in the background. Events are retried with
+// backoff until the bus accepts them and are never dropped. Delivery is at
+// least once and roughly in creation order; consumers deduplicate on the
+// event ID.
+type Relay struct {
+	repo      *Repository
+	pub       Publisher
+	webhooks  Webhooks
+	prefix    string
+	lastPrune time.Time
+}
+
+// NewRelay creates a Relay publishing to pub under subjects of the form
+// "<prefix>.<type>.v<version>", e.g. "radif.user.registered.v1", and
+// queuing merchant events for webhooks. pub is nil when no event bus is
+// configured; events are then only forwarded to webhooks.
+func NewRelay(repo *Repository, pub Publisher, webhooks Webhooks, prefix string) *Relay {
+	return &Relay{repo: repo, pub: pub, webhooks: webhooks, prefix: prefix}
+}
+
+// Run polls the outbox until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	slog.Info("event relay started")
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		r.drain(ctx)
+		r.prune(ctx)
+		select {
+		case <-ctx.Done():
+			slog.Info("event relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain publishes batches until no due events remain.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		batch, err := r.repo.ClaimDue(ctx, claimBatchSize, claimLease)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "event relay: claim failed", "err", err)
+			}
+			return
+		}
+		for _, p := range batch {
+			r.publish(ctx, p)
+		}
+		if len(batch) < claimBatchSize {
+			return
+		}
+	}
+}
+
+// publish sends one event and records the outcome. A retry after a bus
+// failure queues the webhooks again; Webhooks ignores the repeat.
+func (r *Relay) publish(ctx context.Context, p *pending) {
+	err := r.forward(ctx, p)
+	if err == nil && r.pub != nil {
+		var body []byte
+		body, err = json.Marshal(&p.Event)
+		if err == nil {
+			err = r.pub.Publish(ctx, r.subject(&p.Event), p.ID, body)
+		}
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "event relay: publish failed", "event_id", p.ID, "event_type", p.Type, "attempts", p.Attempts+1, "err", err)
+		if err := r.repo.MarkFailed(ctx, p.ID, err.Error(), time.Now().Add(retryBackoff(p.Attempts+1))); err != nil {
+			slog.ErrorContext(ctx, "event relay: record failure failed", "event_id", p.ID, "err", err)
+		}
+		return
+	}
+	if err := r.repo.MarkPublished(ctx, p.ID); err != nil {
+		slog.ErrorContext(ctx, "event relay: mark published failed", "event_id", p.ID, "err", err)
+	}
+}
+
+// forward queues a merchant event for the merchant's webhooks.
+func (r *Relay) forward(ctx context.Context, p *pending) error {
+	if r.webhooks == nil || merchantEvents[p.Type] != p.Version {
+		return nil
+	}
+	return r.webhooks.Publish(ctx, p.ID, p.SubjectID, p.Type, p.Data)
+}
+
+// prune deletes published events older than retention, at most once per
+// pruneInterval.
+func (r *Relay) prune(ctx context.Context) {
+	if ctx.Err() != nil || time.Since(r.lastPrune) < pruneInterval {
+		return
+	}
+	r.lastPrune = time.Now()
+	n, err := r.repo.DeletePublished(ctx, time.Now().Add(-retention))
+	if err != nil {
+		slog.ErrorContext(ctx, "event relay: prune failed", "err", err)
+		return
+	}
+	if n > 0 {
+		slog.InfoContext(ctx, "event relay: pruned published events", "count", n)
+	}
+}
+
+// subject returns the bus subject ev is published under.
+func (r *Relay) subject(ev *Event) string {
+	return r.prefix + "." + ev.Type + ".v" + strconv.Itoa(ev.Version)
+}
+
+// retryBackoff returns the delay before the next attempt after attempt
+// failures: 5s, 10s, 20s, ... capped at 10m, with ±20% jitter.
+func retryBackoff(attempt int) time.Duration {
+	d := baseRetryBackoff << (attempt - 1)
+	if d <= 0 || d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	jitter := 0.8 + rand.Float64()*0.4
+	return time.Duration(float64(d) * jitter)
+}
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/radif/service/internal/db"
+)
+
+// Repository handles database operations on the outbox.
+type Repository struct {
+	db db.Querier
+}
+
+// NewRepository creates a new outbox Repository.
+func NewRepository(q db.Querier) *Repository {
+	return &Repository{db: q}
+}
+
+// WithTx returns a copy of the repository that runs its queries on tx, so
+// they commit or roll back together with the caller's other work.
+func (r *Repository) WithTx(tx pgx.Tx) *Repository {
+	return &Repository{db: tx}
+}
+
+// pending is an outbox row not yet published.
+type pending struct {
+	Event
+	Attempts int
+}
+
+// Insert adds an event to the outbox.
+func (r *Repository) Insert(ctx context.Context, eventType string, version int, subjectID string, data []byte) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO outbox_events (event_type, event_version, subject_id, payload)
+		 VALUES ($1, $2, $3, $4)`,
+		eventType, version, subjectID, data,
+	)
+	if err != nil {
+		return fmt.Errorf("insert outbox event: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue leases up to limit unpublished events, oldest first, by pushing
+// their next_attempt_at forward by lease. SKIP LOCKED lets several relays
+// poll concurrently; an expired lease makes a crashed relay's rows due again.
+func (r *Repository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*pending, error) {
+	rows, err := r.db.Query(ctx,
+		`UPDATE outbox_events SET next_attempt_at = NOW() + $2::interval
+		 WHERE id IN (
+		     SELECT id FROM outbox_events
+		     WHERE published_at IS NULL AND next_attempt_at <= NOW()
+		     ORDER BY created_at
+		     LIMIT $1
+		     FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING id, event_type, event_version, subject_id, payload, attempts, created_at`,
+		limit, lease,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var out []*pending
+	for rows.Next() {
+		p := &pending{Event: Event{Source: source}}
+		var data []byte
+		if err := rows.Scan(&p.ID, &p.Type, &p.Version, &p.SubjectID, &data, &p.Attempts, &p.OccurredAt); err != nil {
+			return nil, fmt.Errorf("scan outbox event: %w", err)
+		}
+		p.Data = json.RawMessage(data)
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim outbox events: %w", err)
+	}
+	return out, nil
+}
+
+// MarkPublished records that the event reached the bus.
+func (r *Repository) MarkPublished(ctx context.Context, id string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE outbox_events SET published_at = NOW(), attempts = attempts + 1, last_error = NULL
+		 WHERE id = $1`, id,
+	)
+	if err != nil {
+		return fmt.Errorf("mark outbox event published: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed publish and schedules the next attempt.
+func (r *Repository) MarkFailed(ctx context.Context, id, lastError string, next time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		 WHERE id = $1`, id, lastError, next,
+	)
+	if err != nil {
+		return fmt.Errorf("mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+// DeletePublished removes events published before cutoff.
+func (r *Repository) DeletePublished(ctx context.Context, cutoff time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM outbox_events WHERE published_at < $1`, cutoff,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete published outbox events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,212 @@
+package expense
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/group"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for group expense endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new expense Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type addRequest struct {
+	Amount       int64    `json:"amount"       example:"4500000"`
+	Description  string   `json:"description"  example:"Dinner"`
+	PaidBy       string   `json:"paidBy"`
+	Participants []string `json:"participants"`
+	Shares       []Share  `json:"shares"`
+}
+
+// Add godoc
+//
+//	@Summary		Log group expense
+//	@Description	Log a shared expense in rials. paidBy defaults to the caller. Give explicit shares that sum to the amount, or participants to split equally among (default: all members).
+//	@Tags			groups
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string		true	"Group ID"
+//	@Param			request	body		addRequest	true	"Expense"
+//	@Success		201		{object}	response.Envelope{data=Expense}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/groups/{id}/expenses [post]
+func (h *Handler) Add(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req addRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	req.Description = strings.TrimSpace(req.Description)
+	if req.Description == "" || len([]rune(req.Description)) > 255 {
+		response.BadRequest(w, "description is required and must be 255 characters or fewer")
+		return
+	}
+
+	e, err := h.svc.Add(r.Context(), userID, chi.URLParam(r, "id"), AddParams{
+		PaidBy:       req.PaidBy,
+		Amount:       req.Amount,
+		Description:  req.Description,
+		Participants: req.Participants,
+		Shares:       req.Shares,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Created(w, e)
+}
+
+// List godoc
+//
+//	@Summary		List group expenses
+//	@Description	Returns the group's expenses with their shares, newest first.
+//	@Tags			groups
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Group ID"
+//	@Param			limit	query		int		false	"Page size (1-100, default 50)"
+//	@Param			offset	query		int		false	"Offset (default 0)"
+//	@Success		200		{object}	response.Envelope{data=[]Expense}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/groups/{id}/expenses [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	q := r.URL.Query()
+	limit, offset := 50, 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			response.BadRequest(w, "offset must be a non-negative integer")
+			return
+		}
+		offset = n
+	}
+
+	expenses, err := h.svc.List(r.Context(), userID, chi.URLParam(r, "id"), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, expenses)
+}
+
+// Delete godoc
+//
+//	@Summary		Delete group expense
+//	@Description	Delete an expense. Allowed for its creator and group admins.
+//	@Tags			groups
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id			path		string	true	"Group ID"
+//	@Param			expenseId	path		string	true	"Expense ID"
+//	@Success		200			{object}	response.Envelope
+//	@Failure		401			{object}	response.Envelope
+//	@Failure		403			{object}	response.Envelope
+//	@Failure		404			{object}	response.Envelope
+//	@Failure		500			{object}	response.Envelope
+//	@Router			/groups/{id}/expenses/{expenseId} [delete]
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), userID, chi.URLParam(r, "id"), chi.URLParam(r, "expenseId")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// Balances godoc
+//
+//	@Summary		Group balances
+//	@Description	Returns each member's net balance (positive: is owed, negative: owes) and a short list of transfers that settles everyone up.
+//	@Tags			groups
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Group ID"
+//	@Success		200	{object}	response.Envelope{data=Summary}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/groups/{id}/balances [get]
+func (h *Handler) Balances(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	s, err := h.svc.Summary(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, s)
+}
+
+// writeError maps expense service errors to responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, group.ErrNotFound):
+		response.NotFound(w, "group not found")
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "expense not found")
+	case errors.Is(err, ErrForbidden):
+		response.Forbidden(w, "only the creator or a group admin can delete this expense")
+	case errors.Is(err, ErrInvalidAmount):
+		response.BadRequest(w, "amount must be a positive number of rials")
+	case errors.Is(err, ErrInvalidShares):
+		response.BadRequest(w, "shares must be non-negative, list each user once and sum to the amount")
+	case errors.Is(err, ErrNotMember):
+		response.BadRequest(w, "the payer and all participants must be group members")
+	default:
+		response.InternalError(w)
+	}
+}
@@ -0,0 +1,273 @@
+// Package expense tracks shared expenses inside a group and works out who
+// owes whom.
+package expense
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Expense is a shared expense paid by one member and split among several.
+type Expense struct {
+	ID          string    `json:"id"`
+	GroupID     string    `json:"groupId"`
+	PaidBy      string    `json:"paidBy"`
+	Amount      int64     `json:"amount" example:"4500000"`
+	Description string    `json:"description" example:"Dinner"`
+	CreatedBy   *string   `json:"createdBy,omitempty"`
+	Shares      []Share   `json:"shares"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Share is one participant's portion of an expense, in rials.
+type Share struct {
+	UserID string `json:"userId"`
+	Amount int64  `json:"amount" example:"1500000"`
+}
+
+// Balance is a member's net position in the group: positive when the group
+// owes them, negative when they owe the group.
+type Balance struct {
+	UserID   string  `json:"userId"`
+	Username *string `json:"username,omitempty"`
+	FullName *string `json:"fullName,omitempty"`
+	Net      int64   `json:"net" example:"-1500000"`
+}
+
+// ErrNotFound is returned when an expense does not exist in the group.
+var ErrNotFound = errors.New("expense not found")
+
+// ErrNotMember is returned when the payer or a participant is not in the group.
+var ErrNotMember = errors.New("participant is not a group member")
+
+// Repository handles expense persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new expense Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const selectCols = `id, group_id, paid_by, amount, description, created_by, created_at`
+
+// Create inserts an expense with its shares. The payer and every participant
+// must currently be members of the group.
+func (r *Repository) Create(ctx context.Context, e *Expense) (*Expense, error) {
+	users := map[string]bool{e.PaidBy: true}
+	for _, s := range e.Shares {
+		users[s.UserID] = true
+	}
+	ids := make([]string, 0, len(users))
+	for id := range users {
+		ids = append(ids, id)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var members int
+	err = tx.QueryRow(ctx,
+		`SELECT COUNT(*) FROM group_members WHERE group_id = $1 AND user_id = ANY($2::uuid[])`,
+		e.GroupID, ids,
+	).Scan(&members)
+	if err != nil {
+		if isInvalidID(err) {
+			return nil, ErrNotMember
+		}
+		return nil, fmt.Errorf("check participants: %w", err)
+	}
+	if members != len(ids) {
+		return nil, ErrNotMember
+	}
+
+	out := &Expense{}
+	err = tx.QueryRow(ctx,
+		`INSERT INTO group_expenses (group_id, paid_by, amount, description, created_by)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING `+selectCols,
+		e.GroupID, e.PaidBy, e.Amount, e.Description, e.CreatedBy,
+	).Scan(&out.ID, &out.GroupID, &out.PaidBy, &out.Amount, &out.Description, &out.CreatedBy, &out.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert expense: %w", err)
+	}
+
+	batch := &pgx.Batch{}
+	for _, s := range e.Shares {
+		batch.Queue(
+			`INSERT INTO group_expense_shares (expense_id, user_id, amount) VALUES ($1, $2, $3)`,
+			out.ID, s.UserID, s.Amount,
+		)
+	}
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		return nil, fmt.Errorf("insert expense shares: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	out.Shares = e.Shares
+	return out, nil
+}
+
+// Get returns an expense of the group, without its shares.
+func (r *Repository) Get(ctx context.Context, groupID, id string) (*Expense, error) {
+	e := &Expense{}
+	err := r.db.QueryRow(ctx,
+		`SELECT `+selectCols+` FROM group_expenses WHERE id = $1 AND group_id = $2`,
+		id, groupID,
+	).Scan(&e.ID, &e.GroupID, &e.PaidBy, &e.Amount, &e.Description, &e.CreatedBy, &e.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get expense: %w", err)
+	}
+	return e, nil
+}
+
+// List returns the group's expenses with their shares, newest first.
+func (r *Repository) List(ctx context.Context, groupID string, limit, offset int) ([]*Expense, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+selectCols+` FROM group_expenses
+		 WHERE group_id = $1
+		 ORDER BY created_at DESC, id
+		 LIMIT $2 OFFSET $3`,
+		groupID, limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list expenses: %w", err)
+	}
+	defer rows.Close()
+
+	expenses := []*Expense{}
+	byID := make(map[string]*Expense)
+	ids := []string{}
+	for rows.Next() {
+		e := &Expense{Shares: []Share{}}
+		if err := rows.Scan(&e.ID, &e.GroupID, &e.PaidBy, &e.Amount, &e.Description, &e.CreatedBy, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan expense: %w", err)
+		}
+		expenses = append(expenses, e)
+		byID[e.ID] = e
+		ids = append(ids, e.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return expenses, nil
+	}
+
+	shareRows, err := r.db.Query(ctx,
+		`SELECT expense_id, user_id, amount FROM group_expense_shares
+		 WHERE expense_id = ANY($1::uuid[])
+		 ORDER BY amount DESC, user_id`,
+		ids,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list expense shares: %w", err)
+	}
+	defer shareRows.Close()
+
+	for shareRows.Next() {
+		var expenseID string
+		var s Share
+		if err := shareRows.Scan(&expenseID, &s.UserID, &s.Amount); err != nil {
+			return nil, fmt.Errorf("scan expense share: %w", err)
+		}
+		byID[expenseID].Shares = append(byID[expenseID].Shares, s)
+	}
+	return expenses, shareRows.Err()
+}
+
+// Delete removes an expense and its shares.
+func (r *Repository) Delete(ctx context.Context, groupID, id string) error {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM group_expenses WHERE id = $1 AND group_id = $2`,
+		id, groupID,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("delete expense: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// MemberIDs returns the IDs of the group's current members.
+func (r *Repository) MemberIDs(ctx context.Context, groupID string) ([]string, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT user_id FROM group_members WHERE group_id = $1 ORDER BY joined_at`,
+		groupID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list member ids: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan member id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// Balances returns every non-zero net position in the group, creditors first.
+func (r *Repository) Balances(ctx context.Context, groupID string) ([]*Balance, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT t.user_id, u.username, u.full_name, SUM(t.delta)::BIGINT AS net
+		 FROM (
+		     SELECT paid_by AS user_id, amount AS delta
+		     FROM group_expenses WHERE group_id = $1
+		     UNION ALL
+		     SELECT s.user_id, -s.amount
+		     FROM group_expense_shares s JOIN group_expenses e ON e.id = s.expense_id
+		     WHERE e.group_id = $1
+		 ) t
+		 JOIN users u ON u.id = t.user_id
+		 GROUP BY t.user_id, u.username, u.full_name
+		 HAVING SUM(t.delta) <> 0
+		 ORDER BY net DESC, t.user_id`,
+		groupID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("group balances: %w", err)
+	}
+	defer rows.Close()
+
+	balances := []*Balance{}
+	for rows.Next() {
+		b := &Balance{}
+		if err := rows.Scan(&b.UserID, &b.Username, &b.FullName, &b.Net); err != nil {
+			return nil, fmt.Errorf("scan balance: %w", err)
+		}
+		balances = append(balances, b)
+	}
+	return balances, rows.Err()
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
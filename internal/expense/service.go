@@ -0,0 +1,217 @@
+package expense
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/radif/service/internal/group"
+)
+
+// maxAmount caps a single expense (100 billion rials).
+const maxAmount = 100_000_000_000
+
+// ErrInvalidAmount is returned for a non-positive or oversized amount.
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ErrInvalidShares is returned when explicit shares are negative, repeated,
+// or do not add up to the expense amount.
+var ErrInvalidShares = errors.New("shares must be non-negative, unique and sum to the amount")
+
+// ErrForbidden is returned when deleting an expense the caller neither
+// created nor administers.
+var ErrForbidden = errors.New("only the creator or a group admin can delete this expense")
+
+// AddParams describes a new expense. When Shares is empty the amount is split
+// equally among Participants, or among all current members when that is
+// empty too.
+type AddParams struct {
+	PaidBy       string
+	Amount       int64
+	Description  string
+	Participants []string
+	Shares       []Share
+}
+
+// Transfer is a suggested payment that settles part of the group's debts.
+type Transfer struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount int64  `json:"amount" example:"1500000"`
+}
+
+// Summary is the group's balance sheet and how to settle it.
+type Summary struct {
+	Balances    []*Balance `json:"balances"`
+	Settlements []Transfer `json:"settlements"`
+}
+
+// Service contains business logic for group expenses.
+type Service struct {
+	repo   *Repository
+	groups *group.Service
+}
+
+// NewService creates a new expense Service.
+func NewService(repo *Repository, groups *group.Service) *Service {
+	return &Service{repo: repo, groups: groups}
+}
+
+// Add logs an expense in a group the caller belongs to.
+func (s *Service) Add(ctx context.Context, userID, groupID string, p AddParams) (*Expense, error) {
+	if _, err := s.groups.Role(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+	if p.Amount <= 0 || p.Amount > maxAmount {
+		return nil, ErrInvalidAmount
+	}
+	if p.PaidBy == "" {
+		p.PaidBy = userID
+	}
+
+	shares := p.Shares
+	if len(shares) > 0 {
+		if err := validateShares(shares, p.Amount); err != nil {
+			return nil, err
+		}
+	} else {
+		participants := p.Participants
+		if len(participants) == 0 {
+			ids, err := s.repo.MemberIDs(ctx, groupID)
+			if err != nil {
+				return nil, err
+			}
+			participants = ids
+		}
+		shares = splitEqually(p.Amount, participants)
+	}
+
+	return s.repo.Create(ctx, &Expense{
+		GroupID:     groupID,
+		PaidBy:      p.PaidBy,
+		Amount:      p.Amount,
+		Description: p.Description,
+		CreatedBy:   &userID,
+		Shares:      shares,
+	})
+}
+
+// List returns the group's expenses. Any member may view them.
+func (s *Service) List(ctx context.Context, userID, groupID string, limit, offset int) ([]*Expense, error) {
+	if _, err := s.groups.Role(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.List(ctx, groupID, limit, offset)
+}
+
+// Delete removes an expense. Its creator and group admins may delete it.
+func (s *Service) Delete(ctx context.Context, userID, groupID, id string) error {
+	role, err := s.groups.Role(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	e, err := s.repo.Get(ctx, groupID, id)
+	if err != nil {
+		return err
+	}
+	isCreator := e.CreatedBy != nil && *e.CreatedBy == userID
+	if !isCreator && role == group.RoleMember {
+		return ErrForbidden
+	}
+	return s.repo.Delete(ctx, groupID, id)
+}
+
+// Summary returns each member's net balance and the transfers that settle them.
+func (s *Service) Summary(ctx context.Context, userID, groupID string) (*Summary, error) {
+	if _, err := s.groups.Role(ctx, groupID, userID); err != nil {
+		return nil, err
+	}
+	balances, err := s.repo.Balances(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return &Summary{Balances: balances, Settlements: settle(balances)}, nil
+}
+
+// validateShares checks explicit shares against the expense amount.
+func validateShares(shares []Share, amount int64) error {
+	seen := make(map[string]bool, len(shares))
+	var sum int64
+	for _, sh := range shares {
+		if sh.UserID == "" || sh.Amount < 0 || seen[sh.UserID] {
+			return ErrInvalidShares
+		}
+		seen[sh.UserID] = true
+		sum += sh.Amount
+	}
+	if sum != amount {
+		return ErrInvalidShares
+	}
+	return nil
+}
+
+// splitEqually divides amount among participants. The rials that don't divide
+// evenly go one each to the first participants, so shares sum exactly.
+func splitEqually(amount int64, participants []string) []Share {
+	unique := make([]string, 0, len(participants))
+	seen := make(map[string]bool, len(participants))
+	for _, id := range participants {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	n := int64(len(unique))
+	shares := make([]Share, len(unique))
+	for i, id := range unique {
+		shares[i] = Share{UserID: id, Amount: amount / n}
+		if int64(i) < amount%n {
+			shares[i].Amount++
+		}
+	}
+	return shares
+}
+
+// settle pairs the largest debtor with the largest creditor until every
+// balance is zero. It needs at most n-1 transfers for n non-zero balances;
+// finding the true minimum is NP-hard and rarely saves more than one.
+func settle(balances []*Balance) []Transfer {
+	type position struct {
+		userID string
+		amount int64
+	}
+	var creditors, debtors []position
+	for _, b := range balances {
+		switch {
+		case b.Net > 0:
+			creditors = append(creditors, position{b.UserID, b.Net})
+		case b.Net < 0:
+			debtors = append(debtors, position{b.UserID, -b.Net})
+		}
+	}
+	byAmount := func(p []position) func(i, j int) bool {
+		return func(i, j int) bool {
+			if p[i].amount != p[j].amount {
+				return p[i].amount > p[j].amount
+			}
+			return p[i].userID < p[j].userID
+		}
+	}
+	sort.Slice(creditors, byAmount(creditors))
+	sort.Slice(debtors, byAmount(debtors))
+
+	transfers := []Transfer{}
+	for i, j := 0, 0; i < len(debtors) && j < len(creditors); {
+		amount := min(debtors[i].amount, creditors[j].amount)
+		transfers = append(transfers, Transfer{From: debtors[i].userID, To: creditors[j].userID, Amount: amount})
+		debtors[i].amount -= amount
+		creditors[j].amount -= amount
+		if debtors[i].amount == 0 {
+			i++
+		}
+		if creditors[j].amount == 0 {
+			j++
+		}
+	}
+	return transfers
+}
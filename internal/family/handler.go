@@ -0,0 +1,300 @@
+package family
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/auth"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// iranPhoneRegex matches valid Iranian mobile numbers (09XXXXXXXXX).
+var iranPhoneRegex = regexp.MustCompile(`^09[0-9]{9}$`)
+
+// Handler holds HTTP handlers for family link endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new family Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type inviteRequest struct {
+	Phone string `json:"phone" example:"09121234567"`
+	Role  string `json:"role"  example:"parent"`
+}
+
+type acceptRequest struct {
+	Code string `json:"code" example:"12345"`
+}
+
+// Invite godoc
+//
+//	@Summary		Invite a child
+//	@Description	Invite a child's phone number to link to the caller as parent or guardian. The invitation is open for 7 days; if the phone already has an account, its owner is notified. Personal accounts only.
+//	@Tags			family
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		inviteRequest	true	"Child's phone and your role"
+//	@Success		201		{object}	response.Envelope{data=Invitation}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/family/invitations [post]
+func (h *Handler) Invite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+	if accountType, _ := r.Context().Value(middleware.UserAccountTypeKey).(string); accountType != "personal" {
+		response.Forbidden(w, "only personal accounts can invite a child")
+		return
+	}
+	phone, _ := r.Context().Value(middleware.UserPhoneKey).(string)
+
+	var req inviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	req.Phone = strings.TrimSpace(req.Phone)
+	if !iranPhoneRegex.MatchString(req.Phone) {
+		response.BadRequest(w, "invalid phone number format")
+		return
+	}
+	if req.Role == "" {
+		req.Role = RoleParent
+	}
+
+	inv, err := h.svc.Invite(r.Context(), userID, phone, req.Phone, req.Role)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Created(w, inv)
+}
+
+// Invitations godoc
+//
+//	@Summary		List family invitations
+//	@Description	Returns the caller's open invitations: those they sent as a parent and those addressed to their phone number.
+//	@Tags			family
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=Invitations}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/family/invitations [get]
+func (h *Handler) Invitations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+	phone, _ := r.Context().Value(middleware.UserPhoneKey).(string)
+
+	invs, err := h.svc.Invitations(r.Context(), userID, phone)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, invs)
+}
+
+// Cancel godoc
+//
+//	@Summary		Cancel family invitation
+//	@Description	Withdraw a pending invitation the caller sent.
+//	@Tags			family
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Invitation ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/family/invitations/{id} [delete]
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.Cancel(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// Accept godoc
+//
+//	@Summary		Accept family invitation
+//	@Description	Accept an invitation addressed to the caller's phone and link to the parent. Request a code for your own phone with POST /auth/otp/send first and pass it here to confirm consent. A child can have at most 2 parents or guardians. Children accounts only.
+//	@Tags			family
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string			true	"Invitation ID"
+//	@Param			request	body		acceptRequest	true	"OTP code"
+//	@Success		200		{object}	response.Envelope{data=Link}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/family/invitations/{id}/accept [post]
+func (h *Handler) Accept(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+	if accountType, _ := r.Context().Value(middleware.UserAccountTypeKey).(string); accountType != "children" {
+		response.Forbidden(w, "only children accounts can accept a family invitation")
+		return
+	}
+	phone, _ := r.Context().Value(middleware.UserPhoneKey).(string)
+
+	var req acceptRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		response.BadRequest(w, "code is required")
+		return
+	}
+
+	l, err := h.svc.Accept(r.Context(), userID, phone, chi.URLParam(r, "id"), req.Code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, l)
+}
+
+// Decline godoc
+//
+//	@Summary		Decline family invitation
+//	@Description	Turn down an invitation addressed to the caller's phone.
+//	@Tags			family
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Invitation ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		409	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/family/invitations/{id}/decline [post]
+func (h *Handler) Decline(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+	phone, _ := r.Context().Value(middleware.UserPhoneKey).(string)
+
+	if err := h.svc.Decline(r.Context(), phone, chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// Links godoc
+//
+//	@Summary		List family links
+//	@Description	Returns the caller's family links, as parent or as child, oldest first.
+//	@Tags			family
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]Link}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/family/links [get]
+func (h *Handler) Links(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	links, err := h.svc.Links(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, links)
+}
+
+// Unlink godoc
+//
+//	@Summary		Unlink family account
+//	@Description	Remove a family link. Either the parent or the child may unlink; the other side is notified and the change is recorded in the link audit trail.
+//	@Tags			family
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Link ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/family/links/{id} [delete]
+func (h *Handler) Unlink(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.Unlink(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// writeError maps family service errors to responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "not found")
+	case errors.Is(err, ErrInvalidRole):
+		response.BadRequest(w, "role must be one of: parent, guardian")
+	case errors.Is(err, ErrSelfInvite):
+		response.BadRequest(w, "you cannot invite your own phone number")
+	case errors.Is(err, auth.ErrInvalidOTP):
+		response.BadRequest(w, "invalid or expired OTP code")
+	case errors.Is(err, auth.ErrTooManyAttempts):
+		response.Error(w, http.StatusTooManyRequests, "too many attempts, try again later")
+	case errors.Is(err, ErrTooManyParents):
+		response.Conflict(w, "this account is already linked to the maximum of 2 parents")
+	case errors.Is(err, ErrAlreadyInvited):
+		response.Conflict(w, "an invitation to this phone is already pending")
+	case errors.Is(err, ErrAlreadyLinked):
+		response.Conflict(w, "accounts are already linked")
+	case errors.Is(err, ErrNotPending):
+		response.Conflict(w, "invitation is no longer pending")
+	default:
+		response.InternalError(w)
+	}
+}
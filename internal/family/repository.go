@@ -0,0 +1,439 @@
+// Package family links child accounts to their parents or guardians. A parent
+// invites the child's phone number, the child accepts after confirming an
+// OTP, and either side can unlink. Every link and unlink is recorded in an
+// audit trail.
+package family
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Roles a parent can hold in a link.
+const (
+	RoleParent   = "parent"
+	RoleGuardian = "guardian"
+)
+
+// Invitation statuses.
+const (
+	StatusPending   = "pending"
+	StatusAccepted  = "accepted"
+	StatusDeclined  = "declined"
+	StatusCancelled = "cancelled"
+)
+
+// Audit actions.
+const (
+	actionLinked   = "linked"
+	actionUnlinked = "unlinked"
+)
+
+// Person is the other side of an invitation or link.
+type Person struct {
+	ID       string  `json:"id"`
+	Username *string `json:"username,omitempty"`
+	FullName *string `json:"fullName,omitempty"`
+}
+
+// Invitation is a parent's invitation to a child's phone number.
+type Invitation struct {
+	ID         string    `json:"id"`
+	Parent     Person    `json:"parent"`
+	ChildPhone string    `json:"childPhone" example:"09121234567"`
+	Role       string    `json:"role"       example:"parent"`
+	Status     string    `json:"status"     example:"pending"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// Link is an accepted parent–child relationship.
+type Link struct {
+	ID        string    `json:"id"`
+	Parent    Person    `json:"parent"`
+	Child     Person    `json:"child"`
+	Role      string    `json:"role" example:"parent"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ErrNotFound is returned when an invitation or link does not exist for the caller.
+var ErrNotFound = errors.New("not found")
+
+// ErrAlreadyInvited is returned when the parent already has a pending
+// invitation to the phone.
+var ErrAlreadyInvited = errors.New("invitation already pending")
+
+// ErrAlreadyLinked is returned when the parent and child are already linked.
+var ErrAlreadyLinked = errors.New("already linked")
+
+// ErrNotPending is returned when responding to an invitation that is no
+// longer pending or has expired.
+var ErrNotPending = errors.New("invitation is not pending")
+
+// Repository handles family persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new family Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const invitationCols = `i.id, p.id, p.username, p.full_name, i.child_phone, i.role, i.status,
+	i.expires_at, i.created_at`
+
+const invitationFrom = ` FROM family_invitations i JOIN users p ON p.id = i.parent_id AND p.deleted_at IS NULL`
+
+// scanInvitation scans an invitationCols row into an Invitation value.
+func scanInvitation(row pgx.Row, inv *Invitation) error {
+	return row.Scan(
+		&inv.ID, &inv.Parent.ID, &inv.Parent.Username, &inv.Parent.FullName,
+		&inv.ChildPhone, &inv.Role, &inv.Status, &inv.ExpiresAt, &inv.CreatedAt,
+	)
+}
+
+const linkCols = `l.id, p.id, p.username, p.full_name, c.id, c.username, c.full_name, l.role, l.created_at`
+
+const linkFrom = ` FROM family_links l
+	JOIN users p ON p.id = l.parent_id AND p.deleted_at IS NULL
+	JOIN users c ON c.id = l.child_id AND c.deleted_at IS NULL`
+
+// scanLink scans a linkCols row into a Link value.
+func scanLink(row pgx.Row, l *Link) error {
+	return row.Scan(
+		&l.ID, &l.Parent.ID, &l.Parent.Username, &l.Parent.FullName,
+		&l.Child.ID, &l.Child.Username, &l.Child.FullName, &l.Role, &l.CreatedAt,
+	)
+}
+
+// UserIDByPhone returns the ID of the account registered to phone, or "" if
+// there is none.
+func (r *Repository) UserIDByPhone(ctx context.Context, phone string) (string, error) {
+	var id string
+	err := r.db.QueryRow(ctx, `SELECT id FROM users WHERE phone = $1 AND deleted_at IS NULL`, phone).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get user by phone: %w", err)
+	}
+	return id, nil
+}
+
+// Linked reports whether parentID is already linked to the account with phone.
+func (r *Repository) Linked(ctx context.Context, parentID, childPhone string) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS(
+		     SELECT 1 FROM family_links l JOIN users c ON c.id = l.child_id AND c.deleted_at IS NULL
+		     WHERE l.parent_id = $1 AND c.phone = $2
+		 )`,
+		parentID, childPhone,
+	).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("check family link: %w", err)
+	}
+	return ok, nil
+}
+
+// CountParents returns how many parents childID is linked to.
+func (r *Repository) CountParents(ctx context.Context, childID string) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM family_links WHERE child_id = $1`, childID,
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count parents: %w", err)
+	}
+	return n, nil
+}
+
+// CreateInvitation stores a pending invitation. Expired pending invitations
+// from the same parent to the same phone are closed first so they do not
+// block a fresh one.
+func (r *Repository) CreateInvitation(ctx context.Context, parentID, childPhone, role string, expiresAt time.Time) (*Invitation, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx,
+		`UPDATE family_invitations SET status = 'cancelled', responded_at = NOW()
+		 WHERE parent_id = $1 AND child_phone = $2 AND status = 'pending' AND expires_at <= NOW()`,
+		parentID, childPhone,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("close expired invitations: %w", err)
+	}
+
+	var id string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO family_invitations (parent_id, child_phone, role, expires_at)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING id`,
+		parentID, childPhone, role, expiresAt,
+	).Scan(&id)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrAlreadyInvited
+		}
+		return nil, fmt.Errorf("create invitation: %w", err)
+	}
+
+	inv := &Invitation{}
+	if err := scanInvitation(tx.QueryRow(ctx,
+		`SELECT `+invitationCols+invitationFrom+` WHERE i.id = $1`, id,
+	), inv); err != nil {
+		return nil, fmt.Errorf("get invitation: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return inv, nil
+}
+
+// ListSent returns the parent's open invitations, newest first.
+func (r *Repository) ListSent(ctx context.Context, parentID string) ([]*Invitation, error) {
+	return r.listInvitations(ctx,
+		`SELECT `+invitationCols+invitationFrom+`
+		 WHERE i.parent_id = $1 AND i.status = 'pending' AND i.expires_at > NOW()
+		 ORDER BY i.created_at DESC`,
+		parentID,
+	)
+}
+
+// ListReceived returns open invitations to phone, newest first.
+func (r *Repository) ListReceived(ctx context.Context, phone string) ([]*Invitation, error) {
+	return r.listInvitations(ctx,
+		`SELECT `+invitationCols+invitationFrom+`
+		 WHERE i.child_phone = $1 AND i.status = 'pending' AND i.expires_at > NOW()
+		 ORDER BY i.created_at DESC`,
+		phone,
+	)
+}
+
+func (r *Repository) listInvitations(ctx context.Context, query string, arg string) ([]*Invitation, error) {
+	rows, err := r.db.Query(ctx, query, arg)
+	if err != nil {
+		return nil, fmt.Errorf("list invitations: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Invitation{}
+	for rows.Next() {
+		inv := &Invitation{}
+		if err := scanInvitation(rows, inv); err != nil {
+			return nil, fmt.Errorf("scan invitation: %w", err)
+		}
+		out = append(out, inv)
+	}
+	return out, rows.Err()
+}
+
+// GetReceived returns an invitation addressed to phone.
+func (r *Repository) GetReceived(ctx context.Context, id, phone string) (*Invitation, error) {
+	inv := &Invitation{}
+	err := scanInvitation(r.db.QueryRow(ctx,
+		`SELECT `+invitationCols+invitationFrom+` WHERE i.id = $1 AND i.child_phone = $2`,
+		id, phone,
+	), inv)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get invitation: %w", err)
+	}
+	return inv, nil
+}
+
+// Cancel withdraws one of the parent's pending invitations.
+func (r *Repository) Cancel(ctx context.Context, parentID, id string) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE family_invitations SET status = 'cancelled', responded_at = NOW()
+		 WHERE id = $1 AND parent_id = $2 AND status = 'pending'`,
+		id, parentID,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("cancel invitation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Decline closes a pending invitation addressed to phone.
+func (r *Repository) Decline(ctx context.Context, id, phone string) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE family_invitations SET status = 'declined', responded_at = NOW()
+		 WHERE id = $1 AND child_phone = $2 AND status = 'pending' AND expires_at > NOW()`,
+		id, phone,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("decline invitation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := r.GetReceived(ctx, id, phone); err != nil {
+			return err
+		}
+		return ErrNotPending
+	}
+	return nil
+}
+
+// Accept closes a pending invitation addressed to the child's phone, creates
+// the link and records it in the audit trail, in one transaction.
+func (r *Repository) Accept(ctx context.Context, id, childID, phone string) (*Link, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var parentID, role string
+	err = tx.QueryRow(ctx,
+		`UPDATE family_invitations SET status = 'accepted', responded_at = NOW()
+		 WHERE id = $1 AND child_phone = $2 AND status = 'pending' AND expires_at > NOW()
+		 RETURNING parent_id, role`,
+		id, phone,
+	).Scan(&parentID, &role)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, getErr := r.GetReceived(ctx, id, phone); getErr != nil {
+				return nil, getErr
+			}
+			return nil, ErrNotPending
+		}
+		if isInvalidID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("accept invitation: %w", err)
+	}
+
+	var linkID string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO family_links (parent_id, child_id, role) VALUES ($1, $2, $3) RETURNING id`,
+		parentID, childID, role,
+	).Scan(&linkID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrAlreadyLinked
+		}
+		return nil, fmt.Errorf("create family link: %w", err)
+	}
+
+	if err := recordEvent(ctx, tx, parentID, childID, role, actionLinked, childID); err != nil {
+		return nil, err
+	}
+
+	l := &Link{}
+	if err := scanLink(tx.QueryRow(ctx, `SELECT `+linkCols+linkFrom+` WHERE l.id = $1`, linkID), l); err != nil {
+		return nil, fmt.Errorf("get family link: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return l, nil
+}
+
+// ListLinks returns the links userID is part of, as parent or child.
+func (r *Repository) ListLinks(ctx context.Context, userID string) ([]*Link, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+linkCols+linkFrom+`
+		 WHERE l.parent_id = $1 OR l.child_id = $1
+		 ORDER BY l.created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list family links: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Link{}
+	for rows.Next() {
+		l := &Link{}
+		if err := scanLink(rows, l); err != nil {
+			return nil, fmt.Errorf("scan family link: %w", err)
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+// Unlink removes a link userID is part of and records who removed it. It
+// returns the removed link.
+func (r *Repository) Unlink(ctx context.Context, userID, id string) (*Link, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	l := &Link{}
+	err = scanLink(tx.QueryRow(ctx,
+		`SELECT `+linkCols+linkFrom+`
+		 WHERE l.id = $1 AND (l.parent_id = $2 OR l.child_id = $2)
+		 FOR UPDATE OF l`,
+		id, userID,
+	), l)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get family link: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM family_links WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("delete family link: %w", err)
+	}
+	if err := recordEvent(ctx, tx, l.Parent.ID, l.Child.ID, l.Role, actionUnlinked, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return l, nil
+}
+
+// recordEvent appends to the link audit trail.
+func recordEvent(ctx context.Context, tx pgx.Tx, parentID, childID, role, action, actorID string) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO family_link_events (parent_id, child_id, role, action, actor_id)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		parentID, childID, role, action, actorID,
+	)
+	if err != nil {
+		return fmt.Errorf("record family link event: %w", err)
+	}
+	return nil
+}
+
+// isUniqueViolation checks whether an error is a PostgreSQL unique_violation (code 23505).
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
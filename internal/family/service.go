@@ -0,0 +1,180 @@
+package family
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/radif/service/internal/notification"
+)
+
+// invitationTTL is how long a child has to accept an invitation.
+const invitationTTL = 7 * 24 * time.Hour
+
+// MaxParents caps how many parents or guardians a child can be linked to.
+const MaxParents = 2
+
+// ErrSelfInvite is returned when a parent invites their own phone.
+var ErrSelfInvite = errors.New("cannot invite yourself")
+
+// ErrInvalidRole is returned for roles other than parent or guardian.
+var ErrInvalidRole = errors.New("invalid role")
+
+// ErrTooManyParents is returned when the child already has MaxParents.
+var ErrTooManyParents = errors.New("child already has the maximum number of parents")
+
+// OTPConfirmer consumes a one-time code sent to a phone. It is satisfied by
+// auth.Service.
+type OTPConfirmer interface {
+	ConfirmOTP(ctx context.Context, phone, code string) error
+}
+
+// Invitations are the caller's open invitations in both directions.
+type Invitations struct {
+	Sent     []*Invitation `json:"sent"`
+	Received []*Invitation `json:"received"`
+}
+
+// Service contains business logic for family links.
+type Service struct {
+	repo     *Repository
+	otp      OTPConfirmer
+	notifier *notification.Service
+}
+
+// NewService creates a new family Service.
+func NewService(repo *Repository, otp OTPConfirmer, notifier *notification.Service) *Service {
+	return &Service{repo: repo, otp: otp, notifier: notifier}
+}
+
+// Invite sends an invitation from parentID to childPhone. If the phone
+// already has an account, its owner is notified.
+func (s *Service) Invite(ctx context.Context, parentID, parentPhone, childPhone, role string) (*Invitation, error) {
+	if role != RoleParent && role != RoleGuardian {
+		return nil, ErrInvalidRole
+	}
+	if childPhone == parentPhone {
+		return nil, ErrSelfInvite
+	}
+	linked, err := s.repo.Linked(ctx, parentID, childPhone)
+	if err != nil {
+		return nil, err
+	}
+	if linked {
+		return nil, ErrAlreadyLinked
+	}
+
+	inv, err := s.repo.CreateInvitation(ctx, parentID, childPhone, role, time.Now().Add(invitationTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	childID, err := s.repo.UserIDByPhone(ctx, childPhone)
+	if err != nil {
+		slog.ErrorContext(ctx, "family: look up invited phone failed", "err", err)
+	} else if childID != "" {
+		s.notify(ctx, childID, notification.TypeFamilyInvitation,
+			"دعوت به اتصال خانوادگی",
+			"از طرف "+displayName(inv.Parent)+" برای اتصال حساب شما به حساب والد دعوت شده‌اید.")
+	}
+	return inv, nil
+}
+
+// Invitations returns the caller's open sent and received invitations.
+func (s *Service) Invitations(ctx context.Context, userID, phone string) (*Invitations, error) {
+	sent, err := s.repo.ListSent(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	received, err := s.repo.ListReceived(ctx, phone)
+	if err != nil {
+		return nil, err
+	}
+	return &Invitations{Sent: sent, Received: received}, nil
+}
+
+// Cancel withdraws one of the parent's pending invitations.
+func (s *Service) Cancel(ctx context.Context, parentID, id string) error {
+	return s.repo.Cancel(ctx, parentID, id)
+}
+
+// Decline turns down an invitation addressed to the caller's phone.
+func (s *Service) Decline(ctx context.Context, phone, id string) error {
+	return s.repo.Decline(ctx, id, phone)
+}
+
+// Accept links the child to the inviting parent. code is an OTP sent to the
+// child's phone, confirming consent from the phone the parent invited.
+func (s *Service) Accept(ctx context.Context, childID, phone, id, code string) (*Link, error) {
+	inv, err := s.repo.GetReceived(ctx, id, phone)
+	if err != nil {
+		return nil, err
+	}
+	if inv.Status != StatusPending || !inv.ExpiresAt.After(time.Now()) {
+		return nil, ErrNotPending
+	}
+	n, err := s.repo.CountParents(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if n >= MaxParents {
+		return nil, ErrTooManyParents
+	}
+
+	if err := s.otp.ConfirmOTP(ctx, phone, code); err != nil {
+		return nil, err
+	}
+
+	l, err := s.repo.Accept(ctx, id, childID, phone)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(ctx, l.Parent.ID, notification.TypeFamilyLinkChanged,
+		"اتصال خانوادگی برقرار شد",
+		displayName(l.Child)+" دعوت شما را پذیرفت و حسابش به حساب شما متصل شد.")
+	return l, nil
+}
+
+// Links returns the caller's links, as parent or child.
+func (s *Service) Links(ctx context.Context, userID string) ([]*Link, error) {
+	return s.repo.ListLinks(ctx, userID)
+}
+
+// Unlink removes a link the caller is part of and notifies the other side.
+func (s *Service) Unlink(ctx context.Context, userID, id string) error {
+	l, err := s.repo.Unlink(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	other, actor := l.Child, l.Parent
+	if userID == l.Child.ID {
+		other, actor = l.Parent, l.Child
+	}
+	s.notify(ctx, other.ID, notification.TypeFamilyLinkChanged,
+		"اتصال خانوادگی قطع شد",
+		displayName(actor)+" اتصال خانوادگی حساب‌های شما را قطع کرد.")
+	return nil
+}
+
+// notify sends a family notification; failures are logged, not returned.
+func (s *Service) notify(ctx context.Context, userID, typ, title, body string) {
+	if _, err := s.notifier.Notify(ctx, userID, notification.Message{
+		Type:  typ,
+		Title: title,
+		Body:  body,
+	}); err != nil {
+		slog.ErrorContext(ctx, "family: notify failed", "type", typ, "user_id", userID, "err", err)
+	}
+}
+
+// displayName is how a person is named in notifications.
+func displayName(p Person) string {
+	switch {
+	case p.FullName != nil && *p.FullName != "":
+		return *p.FullName
+	case p.Username != nil && *p.Username != "":
+		return "@" + *p.Username
+	}
+	return "یک کاربر ردیف"
+}
@@ -0,0 +1,669 @@
+package group
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/block"
+	"github.com/radif/service/internal/imaging"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+	"github.com/radif/service/internal/storage"
+)
+
+const (
+	maxAvatarBytes    = 5 << 20 // 5 MB
+	maxNameLength     = 100
+	maxDescriptionLen = 255
+)
+
+var allowedImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+// AvatarModerator queues new avatars for a content check. It is satisfied by
+// moderation.Service and is optional.
+type AvatarModerator interface {
+	SubmitGroupAvatar(ctx context.Context, groupID, key string) error
+}
+
+// CacheInvalidator drops replaced images from the CDN cache. It is satisfied
+// by cdn.Invalidator and is optional.
+type CacheInvalidator interface {
+	Invalidate(keys ...string)
+}
+
+// Handler holds HTTP handlers for group endpoints.
+type Handler struct {
+	svc       *Service
+	store     storage.Storage
+	moderator AvatarModerator
+	cache     CacheInvalidator
+}
+
+// NewHandler creates a new group Handler. moderator and cache may be nil.
+func NewHandler(svc *Service, store storage.Storage, moderator AvatarModerator, cache CacheInvalidator) *Handler {
+	return &Handler{svc: svc, store: store, moderator: moderator, cache: cache}
+}
+
+type createRequest struct {
+	Name        string   `json:"name"        example:"Kish trip"`
+	Description *string  `json:"description" example:"Shared costs for the Nowruz trip"`
+	MemberIDs   []string `json:"memberIds"`
+}
+
+type updateRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+type addMemberRequest struct {
+	UserID string `json:"userId" example:"e7eedc79-0707-4fe4-8734-526b7ef13a7b"`
+}
+
+type setRoleRequest struct {
+	Role string `json:"role" example:"admin"`
+}
+
+type joinRequest struct {
+	Code string `json:"code" example:"hR3kq9Xw2LmP0vZa"`
+}
+
+// Create godoc
+//
+//	@Summary		Create group
+//	@Description	Create a group owned by the caller, optionally with initial members (max 200 including the owner).
+//	@Tags			groups
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		createRequest	true	"Group details"
+//	@Success		201		{object}	response.Envelope{data=Group}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/groups [post]
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req createRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if msg := validateFields(&req.Name, req.Description); msg != "" {
+		response.BadRequest(w, msg)
+		return
+	}
+	if req.Name == "" {
+		response.BadRequest(w, "name is required")
+		return
+	}
+
+	g, err := h.svc.Create(r.Context(), userID, req.Name, req.Description, req.MemberIDs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.populateAvatarURL(g)
+	response.Created(w, g)
+}
+
+// List godoc
+//
+//	@Summary		List groups
+//	@Description	Returns the groups the caller belongs to, most recently joined first.
+//	@Tags			groups
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]Group}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/groups [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	groups, err := h.svc.List(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	for _, g := range groups {
+		h.populateAvatarURL(g)
+	}
+	response.OK(w, groups)
+}
+
+// Get godoc
+//
+//	@Summary		Get group
+//	@Description	Returns a group the caller belongs to. inviteCode is only included for admins.
+//	@Tags			groups
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Group ID"
+//	@Success		200	{object}	response.Envelope{data=Group}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/groups/{id} [get]
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	g, err := h.svc.Get(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.populateAvatarURL(g)
+	response.OK(w, g)
+}
+
+// Update godoc
+//
+//	@Summary		Update group
+//	@Description	Partially update the group's name or description. Admins only.
+//	@Tags			groups
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string			true	"Group ID"
+//	@Param			request	body		updateRequest	true	"Fields to update"
+//	@Success		200		{object}	response.Envelope{data=Group}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/groups/{id} [patch]
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req updateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if req.Name != nil {
+		trimmed := strings.TrimSpace(*req.Name)
+		if trimmed == "" {
+			response.BadRequest(w, "name must not be empty")
+			return
+		}
+		req.Name = &trimmed
+	}
+	if msg := validateFields(req.Name, req.Description); msg != "" {
+		response.BadRequest(w, msg)
+		return
+	}
+
+	g, err := h.svc.Update(r.Context(), userID, chi.URLParam(r, "id"), req.Name, req.Description)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.populateAvatarURL(g)
+	response.OK(w, g)
+}
+
+// Delete godoc
+//
+//	@Summary		Delete group
+//	@Description	Delete the group and all memberships. Owner only.
+//	@Tags			groups
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Group ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/groups/{id} [delete]
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// UploadAvatar godoc
+//
+//	@Summary		Upload group avatar
+//	@Description	Upload the group picture (JPEG/PNG/WebP/GIF, max 5 MB, max 40 megapixels). The image is re-encoded without metadata (EXIF, including GPS); JPEGs stay JPEG and other formats become PNG. New avatars are checked for objectionable content shortly after upload; a flagged one is removed and the previous avatar restored. Admins only.
+//	@Tags			groups
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Group ID"
+//	@Param			avatar	formData	file	true	"Image file"
+//	@Success		200		{object}	response.Envelope{data=Group}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/groups/{id}/avatar [post]
+func (h *Handler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+	groupID := chi.URLParam(r, "id")
+
+	// Authorize before reading the upload.
+	if err := h.svc.CanManage(r.Context(), userID, groupID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAvatarBytes+1024)
+	if err := r.ParseMultipartForm(maxAvatarBytes); err != nil {
+		response.BadRequest(w, "file too large or invalid multipart form (max 5 MB)")
+		return
+	}
+
+	file, _, err := r.FormFile("avatar")
+	if err != nil {
+		response.Localized(w, http.StatusBadRequest, "field_required", "avatar")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	if _, allowed := allowedImageTypes[http.DetectContentType(data)]; !allowed {
+		response.BadRequest(w, "only JPEG, PNG, WebP, and GIF images are allowed")
+		return
+	}
+	// Store a re-encoded copy so EXIF metadata such as GPS never reaches storage.
+	img, err := imaging.Sanitize(data)
+	if err != nil {
+		response.BadRequest(w, "image could not be read or is too large")
+		return
+	}
+
+	key, err := generateStorageKey(groupID, img.Ext)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	if err := h.store.Upload(r.Context(), key, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType); err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	if h.moderator != nil {
+		if err := h.moderator.SubmitGroupAvatar(r.Context(), groupID, key); err != nil {
+			response.InternalError(w)
+			return
+		}
+	}
+
+	var prevKey *string
+	if h.cache != nil {
+		if prev, err := h.svc.Get(r.Context(), userID, groupID); err == nil {
+			prevKey = prev.AvatarKey
+		}
+	}
+
+	g, err := h.svc.SetAvatar(r.Context(), userID, groupID, key)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if prevKey != nil {
+		h.cache.Invalidate(*prevKey)
+	}
+
+	h.populateAvatarURL(g)
+	response.OK(w, g)
+}
+
+// Members godoc
+//
+//	@Summary		List group members
+//	@Description	Returns the group's members, owner and admins first.
+//	@Tags			groups
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Group ID"
+//	@Success		200	{object}	response.Envelope{data=[]Member}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/groups/{id}/members [get]
+func (h *Handler) Members(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	members, err := h.svc.Members(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	for _, m := range members {
+		if m.AvatarKey != nil && *m.AvatarKey != "" {
+			url := h.store.PublicURL(*m.AvatarKey)
+			m.AvatarURL = &url
+		}
+	}
+	response.OK(w, members)
+}
+
+// AddMember godoc
+//
+//	@Summary		Add group member
+//	@Description	Add a user to the group. Admins only. Users who have blocked the caller cannot be added.
+//	@Tags			groups
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"Group ID"
+//	@Param			request	body		addMemberRequest	true	"User to add"
+//	@Success		201		{object}	response.Envelope
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/groups/{id}/members [post]
+func (h *Handler) AddMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req addMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == "" {
+		response.BadRequest(w, "userId is required")
+		return
+	}
+
+	if err := h.svc.AddMember(r.Context(), userID, chi.URLParam(r, "id"), req.UserID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Created(w, map[string]bool{"success": true})
+}
+
+// RemoveMember godoc
+//
+//	@Summary		Remove group member
+//	@Description	Remove a member from the group, or leave it by passing your own user ID. Admins may remove members; only the owner may remove admins. The owner cannot leave.
+//	@Tags			groups
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Group ID"
+//	@Param			userId	path		string	true	"User ID"
+//	@Success		200		{object}	response.Envelope
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/groups/{id}/members/{userId} [delete]
+func (h *Handler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.RemoveMember(r.Context(), userID, chi.URLParam(r, "id"), chi.URLParam(r, "userId")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// SetRole godoc
+//
+//	@Summary		Change member role
+//	@Description	Promote a member to admin or demote an admin to member. Owner only.
+//	@Tags			groups
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string			true	"Group ID"
+//	@Param			userId	path		string			true	"User ID"
+//	@Param			request	body		setRoleRequest	true	"New role (admin or member)"
+//	@Success		200		{object}	response.Envelope
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/groups/{id}/members/{userId} [patch]
+func (h *Handler) SetRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req setRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	if err := h.svc.SetRole(r.Context(), userID, chi.URLParam(r, "id"), chi.URLParam(r, "userId"), req.Role); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// RotateInvite godoc
+//
+//	@Summary		Create invite link
+//	@Description	Issue a new invite link for the group. Any previous link stops working. Admins only.
+//	@Tags			groups
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Group ID"
+//	@Success		201	{object}	response.Envelope{data=InviteLink}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/groups/{id}/invite-link [post]
+func (h *Handler) RotateInvite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	link, err := h.svc.RotateInvite(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Created(w, link)
+}
+
+// DisableInvite godoc
+//
+//	@Summary		Disable invite link
+//	@Description	Turn off the group's invite link. Admins only.
+//	@Tags			groups
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Group ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/groups/{id}/invite-link [delete]
+func (h *Handler) DisableInvite(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.DisableInvite(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// Join godoc
+//
+//	@Summary		Join group
+//	@Description	Join a group through its invite link code.
+//	@Tags			groups
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		joinRequest	true	"Invite code"
+//	@Success		200		{object}	response.Envelope{data=Group}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/groups/join [post]
+func (h *Handler) Join(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req joinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		response.BadRequest(w, "code is required")
+		return
+	}
+
+	g, err := h.svc.Join(r.Context(), userID, req.Code)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	h.populateAvatarURL(g)
+	response.OK(w, g)
+}
+
+// writeError maps group service errors to responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "group not found")
+	case errors.Is(err, ErrNotMember):
+		response.NotFound(w, "user is not a member of this group")
+	case errors.Is(err, ErrUserNotFound):
+		response.NotFound(w, "user not found")
+	case errors.Is(err, ErrInvalidInvite):
+		response.NotFound(w, "invite link is invalid or has been disabled")
+	case errors.Is(err, ErrForbidden):
+		response.Forbidden(w, "your role in this group does not allow this action")
+	case errors.Is(err, block.ErrBlocked):
+		response.Forbidden(w, "this user cannot be added")
+	case errors.Is(err, ErrAlreadyMember):
+		response.Conflict(w, "user is already a member of this group")
+	case errors.Is(err, ErrGroupFull):
+		response.BadRequest(w, "group has reached the maximum of 200 members")
+	case errors.Is(err, ErrOwnerCannotLeave):
+		response.BadRequest(w, "the owner cannot leave the group; delete it instead")
+	case errors.Is(err, ErrInvalidRole):
+		response.BadRequest(w, "role must be one of: admin, member")
+	default:
+		response.InternalError(w)
+	}
+}
+
+// validateFields checks name and description lengths; nil fields are skipped.
+func validateFields(name, description *string) string {
+	if name != nil && len([]rune(*name)) > maxNameLength {
+		return "name must be 100 characters or fewer"
+	}
+	if description != nil && len([]rune(*description)) > maxDescriptionLen {
+		return "description must be 255 characters or fewer"
+	}
+	return ""
+}
+
+// populateAvatarURL attaches the public URL when the group has an avatar.
+func (h *Handler) populateAvatarURL(g *Group) {
+	if g.AvatarKey != nil && *g.AvatarKey != "" {
+		url := h.store.PublicURL(*g.AvatarKey)
+		g.AvatarURL = &url
+	}
+}
+
+// generateStorageKey creates a collision-resistant object key for a group avatar.
+// Format: "groups/{groupID}/{16-byte-hex}{ext}"
+func generateStorageKey(groupID, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate key: %w", err)
+	}
+	return fmt.Sprintf("groups/%s/%x%s", groupID, b, ext), nil
+}
@@ -0,0 +1,372 @@
+// Package group manages groups: named sets of users with owner/admin/member
+// roles and optional invite links. Shared expenses, group chats and group
+// payments hang off a group.
+package group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Member roles, from most to least privileged.
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleMember = "member"
+)
+
+// Group is a group as seen by one of its members.
+type Group struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description,omitempty"`
+	AvatarKey   *string `json:"-"`
+	AvatarURL   *string `json:"avatarUrl,omitempty"`
+	// InviteCode is only exposed to admins.
+	InviteCode  *string   `json:"inviteCode,omitempty"`
+	Role        string    `json:"role"`
+	MemberCount int       `json:"memberCount"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Member is a user in a group.
+type Member struct {
+	UserID    string    `json:"userId"`
+	Username  *string   `json:"username,omitempty"`
+	FullName  *string   `json:"fullName,omitempty"`
+	AvatarKey *string   `json:"-"`
+	AvatarURL *string   `json:"avatarUrl,omitempty"`
+	Role      string    `json:"role"`
+	JoinedAt  time.Time `json:"joinedAt"`
+}
+
+// ErrNotFound is returned when a group does not exist or the caller is not a member.
+var ErrNotFound = errors.New("group not found")
+
+// ErrUserNotFound is returned when a user to add does not exist.
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrAlreadyMember is returned when adding a user who is already in the group.
+var ErrAlreadyMember = errors.New("user is already a member")
+
+// ErrNotMember is returned when the target user is not in the group.
+var ErrNotMember = errors.New("user is not a member")
+
+// ErrInvalidInvite is returned when an invite code does not match an active link.
+var ErrInvalidInvite = errors.New("invalid invite code")
+
+// Repository handles group persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new group Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// groupCols selects a group with the caller's role and the member count; the
+// query must join the caller's membership row as m.
+const groupCols = `g.id, g.name, g.description, g.avatar_key, g.invite_code, m.role,
+	(SELECT COUNT(*) FROM group_members c WHERE c.group_id = g.id), g.created_at, g.updated_at`
+
+// scanGroup scans a groupCols row into a Group value.
+func scanGroup(row pgx.Row, g *Group) error {
+	return row.Scan(
+		&g.ID, &g.Name, &g.Description, &g.AvatarKey, &g.InviteCode, &g.Role,
+		&g.MemberCount, &g.CreatedAt, &g.UpdatedAt,
+	)
+}
+
+// Create inserts a group owned by ownerID with the given initial members.
+func (r *Repository) Create(ctx context.Context, ownerID, name string, description *string, memberIDs []string) (string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var id string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO groups (name, description, created_by) VALUES ($1, $2, $3) RETURNING id`,
+		name, description, ownerID,
+	).Scan(&id)
+	if err != nil {
+		return "", fmt.Errorf("insert group: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO group_members (group_id, user_id, role) VALUES ($1, $2, 'owner')`,
+		id, ownerID,
+	)
+	if err != nil {
+		return "", fmt.Errorf("insert group owner: %w", err)
+	}
+
+	if len(memberIDs) > 0 {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO group_members (group_id, user_id)
+			 SELECT $1, unnest($2::uuid[])
+			 ON CONFLICT (group_id, user_id) DO NOTHING`,
+			id, memberIDs,
+		)
+		if err != nil {
+			if isForeignKeyViolation(err) || isInvalidID(err) {
+				return "", ErrUserNotFound
+			}
+			return "", fmt.Errorf("insert group members: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", fmt.Errorf("commit: %w", err)
+	}
+	return id, nil
+}
+
+// Get returns a group if userID is a member of it.
+func (r *Repository) Get(ctx context.Context, userID, id string) (*Group, error) {
+	g := &Group{}
+	err := scanGroup(r.db.QueryRow(ctx,
+		`SELECT `+groupCols+`
+		 FROM groups g JOIN group_members m ON m.group_id = g.id AND m.user_id = $2
+		 WHERE g.id = $1`,
+		id, userID,
+	), g)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get group: %w", err)
+	}
+	return g, nil
+}
+
+// ListByUser returns the groups userID belongs to, most recently joined first.
+func (r *Repository) ListByUser(ctx context.Context, userID string) ([]*Group, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+groupCols+`
+		 FROM groups g JOIN group_members m ON m.group_id = g.id AND m.user_id = $1
+		 ORDER BY m.joined_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list groups: %w", err)
+	}
+	defer rows.Close()
+
+	groups := []*Group{}
+	for rows.Next() {
+		g := &Group{}
+		if err := scanGroup(rows, g); err != nil {
+			return nil, fmt.Errorf("scan group: %w", err)
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// Update applies partial changes. Nil fields are left unchanged.
+func (r *Repository) Update(ctx context.Context, id string, name, description *string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE groups SET
+		    name        = COALESCE($2, name),
+		    description = COALESCE($3, description)
+		 WHERE id = $1`,
+		id, name, description,
+	)
+	if err != nil {
+		return fmt.Errorf("update group: %w", err)
+	}
+	return nil
+}
+
+// UpdateAvatarKey saves a new avatar object key for the group.
+func (r *Repository) UpdateAvatarKey(ctx context.Context, id, key string) error {
+	_, err := r.db.Exec(ctx, `UPDATE groups SET avatar_key = $2 WHERE id = $1`, id, key)
+	if err != nil {
+		return fmt.Errorf("update group avatar key: %w", err)
+	}
+	return nil
+}
+
+// SetInviteCode replaces the group's invite code; nil disables the link.
+func (r *Repository) SetInviteCode(ctx context.Context, id string, code *string) error {
+	_, err := r.db.Exec(ctx, `UPDATE groups SET invite_code = $2 WHERE id = $1`, id, code)
+	if err != nil {
+		return fmt.Errorf("set invite code: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a group and its memberships.
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM groups WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete group: %w", err)
+	}
+	return nil
+}
+
+// IDByInviteCode returns the group with an active invite link for code.
+func (r *Repository) IDByInviteCode(ctx context.Context, code string) (string, error) {
+	var id string
+	err := r.db.QueryRow(ctx, `SELECT id FROM groups WHERE invite_code = $1`, code).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrInvalidInvite
+	}
+	if err != nil {
+		return "", fmt.Errorf("get group by invite code: %w", err)
+	}
+	return id, nil
+}
+
+// MemberRole returns userID's role in the group.
+func (r *Repository) MemberRole(ctx context.Context, groupID, userID string) (string, error) {
+	var role string
+	err := r.db.QueryRow(ctx,
+		`SELECT role FROM group_members WHERE group_id = $1 AND user_id = $2`,
+		groupID, userID,
+	).Scan(&role)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return "", ErrNotMember
+	}
+	if err != nil {
+		return "", fmt.Errorf("get member role: %w", err)
+	}
+	return role, nil
+}
+
+// ListMembers returns the group's members, owner and admins first.
+func (r *Repository) ListMembers(ctx context.Context, groupID string) ([]*Member, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT u.id, u.username, u.full_name, u.avatar_key, m.role, m.joined_at
+		 FROM group_members m JOIN users u ON u.id = m.user_id AND u.deleted_at IS NULL
+		 WHERE m.group_id = $1
+		 ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'admin' THEN 1 ELSE 2 END, m.joined_at`,
+		groupID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list members: %w", err)
+	}
+	defer rows.Close()
+
+	members := []*Member{}
+	for rows.Next() {
+		m := &Member{}
+		if err := rows.Scan(&m.UserID, &m.Username, &m.FullName, &m.AvatarKey, &m.Role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("scan member: %w", err)
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// AddMember adds userID to the group as a plain member, or returns
+// ErrGroupFull when it already has limit members. The group row is locked
+// for the count, so concurrent adds and invite joins cannot overshoot.
+func (r *Repository) AddMember(ctx context.Context, groupID, userID string, limit int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var n int
+	err = tx.QueryRow(ctx,
+		`SELECT (SELECT COUNT(*) FROM group_members WHERE group_id = g.id)
+		 FROM groups g WHERE g.id = $1 FOR UPDATE`,
+		groupID,
+	).Scan(&n)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("lock group: %w", err)
+	}
+	if n >= limit {
+		return ErrGroupFull
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO group_members (group_id, user_id) VALUES ($1, $2)`,
+		groupID, userID,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrAlreadyMember
+		}
+		if isForeignKeyViolation(err) || isInvalidID(err) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("add member: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember removes userID from the group.
+func (r *Repository) RemoveMember(ctx context.Context, groupID, userID string) error {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM group_members WHERE group_id = $1 AND user_id = $2`,
+		groupID, userID,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotMember
+		}
+		return fmt.Errorf("remove member: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotMember
+	}
+	return nil
+}
+
+// SetRole changes a member's role between admin and member.
+func (r *Repository) SetRole(ctx context.Context, groupID, userID, role string) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE group_members SET role = $3
+		 WHERE group_id = $1 AND user_id = $2 AND role <> 'owner'`,
+		groupID, userID, role,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotMember
+		}
+		return fmt.Errorf("set member role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotMember
+	}
+	return nil
+}
+
+// isUniqueViolation checks whether an error is a PostgreSQL unique_violation (code 23505).
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isForeignKeyViolation checks whether an error is a PostgreSQL foreign_key_violation (code 23503).
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
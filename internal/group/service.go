@@ -0,0 +1,279 @@
+package group
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/radif/service/internal/deeplink"
+)
+
+// MaxMembers caps group size, including the owner.
+const MaxMembers = 200
+
+// ErrForbidden is returned when the caller's role does not allow the action.
+var ErrForbidden = errors.New("insufficient group role")
+
+// ErrGroupFull is returned when adding a member would exceed MaxMembers.
+var ErrGroupFull = errors.New("group is full")
+
+// ErrOwnerCannotLeave is returned when the owner tries to leave their group;
+// they must delete it instead.
+var ErrOwnerCannotLeave = errors.New("owner cannot leave the group")
+
+// ErrInvalidRole is returned when assigning a role other than admin or member.
+var ErrInvalidRole = errors.New("invalid role")
+
+// roleRank orders roles by privilege.
+var roleRank = map[string]int{RoleMember: 1, RoleAdmin: 2, RoleOwner: 3}
+
+// ReachChecker reports whether actorID may reach recipientID. It is satisfied
+// by block.Service.
+type ReachChecker interface {
+	CheckReach(ctx context.Context, actorID, recipientID string) error
+}
+
+// InviteLink is an active group invite link.
+type InviteLink struct {
+	Code string        `json:"code" example:"hR3kq9Xw2LmP0vZa"`
+	Link deeplink.Link `json:"link"`
+}
+
+// Service contains business logic for groups.
+type Service struct {
+	repo  *Repository
+	reach ReachChecker
+}
+
+// NewService creates a new group Service. reach may be nil, in which case
+// blocks are not checked when adding members.
+func NewService(repo *Repository, reach ReachChecker) *Service {
+	return &Service{repo: repo, reach: reach}
+}
+
+// Create creates a group owned by ownerID with the given initial members.
+func (s *Service) Create(ctx context.Context, ownerID, name string, description *string, memberIDs []string) (*Group, error) {
+	others := make([]string, 0, len(memberIDs))
+	seen := map[string]bool{ownerID: true}
+	for _, id := range memberIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if err := s.checkReach(ctx, ownerID, id); err != nil {
+			return nil, err
+		}
+		others = append(others, id)
+	}
+	if len(others)+1 > MaxMembers {
+		return nil, ErrGroupFull
+	}
+
+	id, err := s.repo.Create(ctx, ownerID, name, description, others)
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, ownerID, id)
+}
+
+// Get returns a group the caller belongs to. The invite code is hidden from
+// plain members.
+func (s *Service) Get(ctx context.Context, userID, id string) (*Group, error) {
+	g, err := s.repo.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return redact(g), nil
+}
+
+// List returns the caller's groups.
+func (s *Service) List(ctx context.Context, userID string) ([]*Group, error) {
+	groups, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		redact(g)
+	}
+	return groups, nil
+}
+
+// Update changes the group's name or description. Admins only.
+func (s *Service) Update(ctx context.Context, userID, id string, name, description *string) (*Group, error) {
+	if _, err := s.requireRole(ctx, id, userID, RoleAdmin); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(ctx, id, name, description); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+// SetAvatar stores the group's new avatar key. Admins only.
+func (s *Service) SetAvatar(ctx context.Context, userID, id, key string) (*Group, error) {
+	if _, err := s.requireRole(ctx, id, userID, RoleAdmin); err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateAvatarKey(ctx, id, key); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+// CanManage checks that userID is an admin of the group, so handlers can
+// authorize before doing expensive work such as an upload.
+func (s *Service) CanManage(ctx context.Context, userID, id string) error {
+	_, err := s.requireRole(ctx, id, userID, RoleAdmin)
+	return err
+}
+
+// Role returns userID's role in the group, or ErrNotFound when they are not
+// a member. Modules built on groups use it for their own access checks.
+func (s *Service) Role(ctx context.Context, id, userID string) (string, error) {
+	return s.requireRole(ctx, id, userID, RoleMember)
+}
+
+// Delete removes the group. Owner only.
+func (s *Service) Delete(ctx context.Context, userID, id string) error {
+	if _, err := s.requireRole(ctx, id, userID, RoleOwner); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Members lists the group's members. Any member may view them.
+func (s *Service) Members(ctx context.Context, userID, id string) ([]*Member, error) {
+	if _, err := s.requireRole(ctx, id, userID, RoleMember); err != nil {
+		return nil, err
+	}
+	return s.repo.ListMembers(ctx, id)
+}
+
+// AddMember adds targetID to the group. Admins only.
+func (s *Service) AddMember(ctx context.Context, userID, id, targetID string) error {
+	if _, err := s.requireRole(ctx, id, userID, RoleAdmin); err != nil {
+		return err
+	}
+	if err := s.checkReach(ctx, userID, targetID); err != nil {
+		return err
+	}
+	return s.repo.AddMember(ctx, id, targetID, MaxMembers)
+}
+
+// RemoveMember removes targetID from the group. Members may remove
+// themselves (leave); admins may remove members, and only the owner may
+// remove admins.
+func (s *Service) RemoveMember(ctx context.Context, userID, id, targetID string) error {
+	role, err := s.requireRole(ctx, id, userID, RoleMember)
+	if err != nil {
+		return err
+	}
+	if targetID == userID {
+		if role == RoleOwner {
+			return ErrOwnerCannotLeave
+		}
+		return s.repo.RemoveMember(ctx, id, userID)
+	}
+
+	targetRole, err := s.repo.MemberRole(ctx, id, targetID)
+	if err != nil {
+		return err
+	}
+	if roleRank[role] < roleRank[RoleAdmin] || roleRank[role] <= roleRank[targetRole] {
+		return ErrForbidden
+	}
+	return s.repo.RemoveMember(ctx, id, targetID)
+}
+
+// SetRole promotes or demotes a member. Owner only.
+func (s *Service) SetRole(ctx context.Context, userID, id, targetID, role string) error {
+	if role != RoleAdmin && role != RoleMember {
+		return ErrInvalidRole
+	}
+	if _, err := s.requireRole(ctx, id, userID, RoleOwner); err != nil {
+		return err
+	}
+	if targetID == userID {
+		return ErrForbidden
+	}
+	return s.repo.SetRole(ctx, id, targetID, role)
+}
+
+// RotateInvite issues a new invite link, invalidating the previous one.
+// Admins only.
+func (s *Service) RotateInvite(ctx context.Context, userID, id string) (*InviteLink, error) {
+	if _, err := s.requireRole(ctx, id, userID, RoleAdmin); err != nil {
+		return nil, err
+	}
+	code, err := generateInviteCode()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetInviteCode(ctx, id, &code); err != nil {
+		return nil, err
+	}
+	return &InviteLink{Code: code, Link: deeplink.GroupInvite(code)}, nil
+}
+
+// DisableInvite turns the invite link off. Admins only.
+func (s *Service) DisableInvite(ctx context.Context, userID, id string) error {
+	if _, err := s.requireRole(ctx, id, userID, RoleAdmin); err != nil {
+		return err
+	}
+	return s.repo.SetInviteCode(ctx, id, nil)
+}
+
+// Join adds the caller to the group behind an invite code.
+func (s *Service) Join(ctx context.Context, userID, code string) (*Group, error) {
+	id, err := s.repo.IDByInviteCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddMember(ctx, id, userID, MaxMembers); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, id)
+}
+
+// requireRole returns userID's role in the group, ErrNotFound when they are
+// not a member, or ErrForbidden when the role is below min.
+func (s *Service) requireRole(ctx context.Context, id, userID, min string) (string, error) {
+	role, err := s.repo.MemberRole(ctx, id, userID)
+	if errors.Is(err, ErrNotMember) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if roleRank[role] < roleRank[min] {
+		return "", ErrForbidden
+	}
+	return role, nil
+}
+
+// checkReach rejects adding a user who has blocked the actor.
+func (s *Service) checkReach(ctx context.Context, actorID, targetID string) error {
+	if s.reach == nil {
+		return nil
+	}
+	return s.reach.CheckReach(ctx, actorID, targetID)
+}
+
+// redact hides admin-only fields from plain members.
+func redact(g *Group) *Group {
+	if roleRank[g.Role] < roleRank[RoleAdmin] {
+		g.InviteCode = nil
+	}
+	return g
+}
+
+// generateInviteCode returns a random URL-safe invite code.
+func generateInviteCode() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate invite code: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
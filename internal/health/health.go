@@ -0,0 +1,164 @@
+// Package health serves the liveness and readiness probes. Liveness only
+// says the process is serving HTTP; readiness pings each dependency so an
+// orchestrator stops routing traffic to an instance that cannot serve it.
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/radif/service/internal/response"
+	"github.com/radif/service/internal/storage"
+)
+
+// Check reports whether a dependency is reachable.
+type Check func(ctx context.Context) error
+
+// Dependency statuses reported by Ready.
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+	StatusDown        = "down"
+	StatusTimeout     = "timeout"
+)
+
+// probeKey is statted to reach object storage. It never exists, so a
+// reachable store answers ErrObjectNotFound.
+const probeKey = ".readyz-probe"
+
+type dependency struct {
+	name     string
+	check    Check
+	critical bool
+}
+
+// Checker runs the readiness checks.
+type Checker struct {
+	timeout  time.Duration
+	deps     []dependency
+	draining atomic.Bool
+}
+
+// DependencyStatus is the outcome of one check.
+type DependencyStatus struct {
+	Status     string `json:"status" example:"ok"`
+	Critical   bool   `json:"critical"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Report is the readiness response body.
+type Report struct {
+	Status string                      `json:"status" example:"ok"`
+	Checks map[string]DependencyStatus `json:"checks"`
+}
+
+// NewChecker returns a Checker that gives each check timeout to answer.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add registers a check. A failing critical check makes the instance not
+// ready; a failing non-critical one, for a dependency the service degrades
+// without, is only reported.
+func (c *Checker) Add(name string, critical bool, check Check) {
+	c.deps = append(c.deps, dependency{name: name, check: check, critical: critical})
+}
+
+// Drain marks the instance not ready, so it is taken out of rotation while
+// in-flight requests finish during shutdown.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Run runs every check concurrently and summarises them.
+func (c *Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]DependencyStatus, len(c.deps))}
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, d := range c.deps {
+		wg.Add(1)
+		go func(d dependency) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := d.check(ctx)
+			ds := DependencyStatus{Status: StatusOK, Critical: d.critical, DurationMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				ds.Status = StatusDown
+				if errors.Is(err, context.DeadlineExceeded) {
+					ds.Status = StatusTimeout
+				}
+				slog.WarnContext(ctx, "health: dependency check failed", "dependency", d.name, "err", err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[d.name] = ds
+			switch {
+			case ds.Status == StatusOK:
+			case d.critical:
+				report.Status = StatusUnavailable
+			case report.Status == StatusOK:
+				report.Status = StatusDegraded
+			}
+		}(d)
+	}
+	wg.Wait()
+	return report
+}
+
+// Live godoc
+//
+//	@Summary		Liveness probe
+//	@Description	Reports that the process is serving HTTP. It checks no dependencies.
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	map[string]string
+//	@Router			/healthz [get]
+func (c *Checker) Live(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, map[string]string{"status": StatusOK})
+}
+
+// Ready godoc
+//
+//	@Summary		Readiness probe
+//	@Description	Pings Postgres, Redis and object storage. Answers 503 when a critical dependency is down or the instance is shutting down; non-critical failures are reported as "degraded" with a 200.
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	Report
+//	@Failure		503	{object}	Report
+//	@Router			/readyz [get]
+func (c *Checker) Ready(w http.ResponseWriter, r *http.Request) {
+	if c.draining.Load() {
+		response.JSON(w, http.StatusServiceUnavailable, Report{Status: StatusUnavailable, Checks: map[string]DependencyStatus{}})
+		return
+	}
+	report := c.Run(r.Context())
+	status := http.StatusOK
+	if report.Status == StatusUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	response.JSON(w, status, report)
+}
+
+// StorageCheck returns a check that reaches s by statting a key that does
+// not exist; a missing bucket or unreachable endpoint fails it.
+func StorageCheck(s storage.Storage) Check {
+	return func(ctx context.Context) error {
+		_, err := s.Stat(ctx, probeKey)
+		if err == nil || errors.Is(err, storage.ErrObjectNotFound) {
+			return nil
+		}
+		return err
+	}
+}
@@ -0,0 +1,69 @@
+// Package i18n holds language negotiation shared by localized responses and
+// the catalog of localized error messages.
+package i18n
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported languages. Persian is the default.
+const (
+	LangFa = "fa"
+	LangEn = "en"
+)
+
+// PreferredLanguage picks LangEn or LangFa from an Accept-Language header
+// value, honouring the client's order and defaulting to LangFa.
+func PreferredLanguage(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		switch {
+		case strings.HasPrefix(tag, LangFa):
+			return LangFa
+		case strings.HasPrefix(tag, LangEn):
+			return LangEn
+		}
+	}
+	return LangFa
+}
+
+// text is a catalog entry in each supported language.
+type text struct {
+	en, fa string
+}
+
+// byEnglish maps the English text of each entry without arguments back to
+// its code, so handlers can keep writing plain English messages.
+var byEnglish = func() map[string]string {
+	m := make(map[string]string, len(messages))
+	for code, t := range messages {
+		if !strings.Contains(t.en, "%") {
+			m[t.en] = code
+		}
+	}
+	return m
+}()
+
+// Code returns the catalog code of an English message, or "" when the
+// message is not in the catalog.
+func Code(message string) string {
+	return byEnglish[message]
+}
+
+// Message returns the text of code in lang, formatted with args. An unknown
+// code is returned as is.
+func Message(lang, code string, args ...any) string {
+	t, ok := messages[code]
+	if !ok {
+		return code
+	}
+	s := t.en
+	if lang == LangFa {
+		s = t.fa
+	}
+	if len(args) > 0 {
+		s = fmt.Sprintf(s, args...)
+	}
+	return s
+}
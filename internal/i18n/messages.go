@@ -0,0 +1,227 @@
+package i18n
+
+// messages is the error message catalog, keyed by the code sent to clients
+// next to the message. Texts may hold fmt verbs, filled from the arguments
+// given to Message.
+var messages = map[string]text{
+	// Generic
+	"internal_error":            {en: "internal server error", fa: "خطای داخلی سرور رخ داد"},
+	"invalid_request_body":      {en: "invalid request body", fa: "بدنه درخواست نامعتبر است"},
+	"request_body_too_large":    {en: "request body too large", fa: "حجم درخواست بیش از حد مجاز است"},
+	"not_found":                 {en: "not found", fa: "یافت نشد"},
+	"unauthorized":              {en: "unauthorized", fa: "احراز هویت نشده‌اید"},
+	"insufficient_permissions":  {en: "insufficient permissions", fa: "دسترسی کافی ندارید"},
+	"missing_scope":             {en: "token lacks required scope: %s", fa: "توکن دسترسی لازم را ندارد: %s"},
+	"field_required":            {en: "field %q is required", fa: "فیلد «%s» الزامی است"},
+	"validation_failed":         {en: "request validation failed", fa: "برخی از فیلدهای درخواست نامعتبر است"},
+	"field_invalid":             {en: "invalid value", fa: "مقدار نامعتبر است"},
+	"field_not_numeric":         {en: "must contain digits only", fa: "باید فقط شامل رقم باشد"},
+	"field_not_one_of":          {en: "must be one of: %s", fa: "باید یکی از این مقادیر باشد: %s"},
+	"field_wrong_length":        {en: "must be exactly %s characters", fa: "باید دقیقاً %s نویسه باشد"},
+	"field_too_long":            {en: "must be %s characters or fewer", fa: "باید حداکثر %s نویسه باشد"},
+	"field_too_short":           {en: "must be at least %s characters", fa: "باید دست‌کم %s نویسه باشد"},
+	"field_too_large":           {en: "must be at most %s", fa: "باید حداکثر %s باشد"},
+	"field_too_small":           {en: "must be at least %s", fa: "باید دست‌کم %s باشد"},
+	"field_too_many":            {en: "must have at most %s items", fa: "باید حداکثر %s مورد داشته باشد"},
+	"field_too_few":             {en: "must have at least %s items", fa: "باید دست‌کم %s مورد داشته باشد"},
+	"request_timed_out":         {en: "request timed out", fa: "زمان پاسخ‌گویی به درخواست به پایان رسید؛ دوباره تلاش کنید"},
+	"too_many_requests":         {en: "too many requests, try again later", fa: "تعداد درخواست‌ها بیش از حد است؛ کمی بعد دوباره تلاش کنید"},
+	"invalid_actor_or_cursor":   {en: "invalid actorId or cursor", fa: "شناسه کاربر (actorId) یا نشانگر صفحه‌بندی نامعتبر است"},
+	"invalid_cursor":            {en: "invalid cursor", fa: "نشانگر صفحه‌بندی نامعتبر است"},
+	"limit_out_of_range_100":    {en: "limit must be between 1 and 100", fa: "مقدار limit باید بین ۱ تا ۱۰۰ باشد"},
+	"limit_out_of_range_50":     {en: "limit must be between 1 and 50", fa: "مقدار limit باید بین ۱ تا ۵۰ باشد"},
+	"invalid_offset":            {en: "offset must be a non-negative integer", fa: "مقدار offset باید عدد صحیح نامنفی باشد"},
+	"name_required":             {en: "name is required", fa: "نام الزامی است"},
+	"name_empty":                {en: "name must not be empty", fa: "نام نباید خالی باشد"},
+	"name_too_long":             {en: "name must be 100 characters or fewer", fa: "نام باید حداکثر ۱۰۰ نویسه باشد"},
+	"description_too_long":      {en: "description must be 255 characters or fewer", fa: "توضیحات باید حداکثر ۲۵۵ نویسه باشد"},
+	"invalid_fields_or_casing":  {en: "invalid fields or casing query parameter", fa: "پارامتر fields یا casing نامعتبر است"},
+	"user_id_required":          {en: "userId is required", fa: "شناسه کاربر (userId) الزامی است"},
+	"user_not_found":            {en: "user not found", fa: "کاربر یافت نشد"},
+	"file_too_large":            {en: "file too large or invalid multipart form (max 5 MB)", fa: "فایل بیش از حد بزرگ است یا فرم ارسالی نامعتبر است (حداکثر ۵ مگابایت)"},
+	"image_type_not_allowed":    {en: "only JPEG, PNG, WebP, and GIF images are allowed", fa: "فقط تصاویر JPEG، PNG، WebP و GIF مجاز است"},
+	"document_type_not_allowed": {en: "only JPEG, PNG, and PDF documents are allowed", fa: "فقط اسناد JPEG، PNG و PDF مجاز است"},
+	"image_unreadable":          {en: "image could not be read or is too large", fa: "تصویر قابل خواندن نیست یا بیش از حد بزرگ است"},
+	"upload_mismatch":           {en: "uploaded file must be a JPEG, PNG, WebP or GIF image of at most 5 MB matching the presigned content type", fa: "فایل بارگذاری‌شده باید تصویر JPEG، PNG، WebP یا GIF با حداکثر حجم ۵ مگابایت و هم‌نوع با نوع محتوای اعلام‌شده باشد"},
+	"upload_missing":            {en: "no object has been uploaded for this key", fa: "هنوز فایلی برای این کلید بارگذاری نشده است"},
+	"invalid_key":               {en: "invalid key", fa: "کلید نامعتبر است"},
+	"too_many_connections":      {en: "too many open connections", fa: "تعداد اتصال‌های باز بیش از حد مجاز است"},
+
+	// Idempotency
+	"idempotency_key_too_long": {en: "Idempotency-Key must be 255 characters or fewer", fa: "سرآیند Idempotency-Key باید حداکثر ۲۵۵ نویسه باشد"},
+	"idempotency_key_reused":   {en: "Idempotency-Key was already used for a different request", fa: "این Idempotency-Key قبلاً برای درخواست دیگری استفاده شده است"},
+	"idempotency_in_progress":  {en: "a request with this Idempotency-Key is still being processed", fa: "درخواستی با این Idempotency-Key هنوز در حال پردازش است"},
+
+	// Auth
+	"authorization_required":       {en: "authorization header required", fa: "سرآیند Authorization الزامی است"},
+	"invalid_authorization_header": {en: "invalid authorization header format", fa: "قالب سرآیند Authorization نامعتبر است"},
+	"invalid_token":                {en: "invalid or expired token", fa: "توکن نامعتبر یا منقضی شده است"},
+	"invalid_token_claims":         {en: "invalid token claims", fa: "اطلاعات توکن نامعتبر است"},
+	"token_revoked":                {en: "token has been revoked", fa: "این توکن باطل شده است"},
+	"account_deleted":              {en: "account has been deleted", fa: "این حساب حذف شده است"},
+	"token_not_revocable":          {en: "this token cannot be revoked; sign in again for a new one", fa: "این توکن قابل ابطال نیست؛ برای دریافت توکن جدید دوباره وارد شوید"},
+	"logout_unavailable":           {en: "logout is temporarily unavailable, try again later", fa: "خروج از حساب موقتاً در دسترس نیست؛ کمی بعد دوباره تلاش کنید"},
+	"invalid_phone":                {en: "invalid phone number format", fa: "قالب شماره تلفن نامعتبر است"},
+	"invalid_otp_format":           {en: "OTP code must be exactly 5 digits", fa: "کد تأیید باید دقیقاً ۵ رقم باشد"},
+	"invalid_otp":                  {en: "invalid or expired OTP", fa: "کد تأیید نادرست یا منقضی شده است"},
+	"invalid_otp_code":             {en: "invalid or expired OTP code", fa: "کد تأیید نادرست یا منقضی شده است"},
+	"code_required":                {en: "code is required", fa: "کد الزامی است"},
+	"too_many_otps":                {en: "too many codes sent to this phone, try again later", fa: "تعداد کدهای ارسال‌شده به این شماره بیش از حد است؛ کمی بعد دوباره تلاش کنید"},
+	"too_many_attempts":            {en: "too many attempts, try again later", fa: "تعداد تلاش‌ها بیش از حد است؛ کمی بعد دوباره تلاش کنید"},
+	"invalid_account_type":         {en: "accountType must be one of: personal, children, business", fa: "نوع حساب باید یکی از personal، children یا business باشد"},
+	"invalid_registration_token":   {en: "invalid or expired registration token", fa: "توکن ثبت‌نام نامعتبر یا منقضی شده است"},
+	"unknown_referral_code":        {en: "unknown referral code", fa: "کد معرف نامعتبر است"},
+	"invalid_scopes":               {en: "scopes must be a non-empty list of known scopes", fa: "فهرست دسترسی‌ها (scopes) باید غیرخالی و شامل دسترسی‌های معتبر باشد"},
+	"invalid_token_ttl":            {en: "ttlSeconds must be between 1 and 604800", fa: "مقدار ttlSeconds باید بین ۱ تا ۶۰۴۸۰۰ باشد"},
+	"impersonation_denied":         {en: "not allowed while impersonating a user", fa: "این کار هنگام ورود به‌جای کاربر مجاز نیست"},
+	"api_key_required":             {en: "API key required", fa: "کلید API الزامی است"},
+	"invalid_api_key":              {en: "invalid or revoked API key", fa: "کلید API نامعتبر یا باطل شده است"},
+	"api_key_not_found":            {en: "API key not found", fa: "کلید API یافت نشد"},
+	"cannot_impersonate_admin":     {en: "admin accounts cannot be impersonated", fa: "ورود به‌جای حساب‌های مدیر مجاز نیست"},
+	"invalid_impersonation_ttl":    {en: "ttlSeconds must be between 1 and 3600", fa: "مقدار ttlSeconds باید بین ۱ تا ۳۶۰۰ باشد"},
+
+	// Users
+	"username_taken":            {en: "username is already taken", fa: "این نام کاربری قبلاً گرفته شده است"},
+	"username_invalid_chars":    {en: "username may only contain letters, digits, and underscores", fa: "نام کاربری فقط می‌تواند شامل حروف، ارقام و زیرخط باشد"},
+	"username_too_long":         {en: "username must be 50 characters or fewer", fa: "نام کاربری باید حداکثر ۵۰ نویسه باشد"},
+	"username_required":         {en: "username query parameter is required", fa: "پارامتر username الزامی است"},
+	"bio_too_long":              {en: "bio must be 160 characters or fewer", fa: "بیوگرافی باید حداکثر ۱۶۰ نویسه باشد"},
+	"category_business_only":    {en: "businessCategory can only be set on business accounts", fa: "دسته‌بندی کسب‌وکار فقط برای حساب‌های تجاری قابل تنظیم است"},
+	"unknown_business_category": {en: "unknown business category", fa: "دسته‌بندی کسب‌وکار نامعتبر است"},
+	"banned_content":            {en: "text contains banned content", fa: "متن شامل محتوای غیرمجاز است"},
+	"gallery_business_only":     {en: "the gallery is available to business accounts only", fa: "گالری فقط برای حساب‌های تجاری در دسترس است"},
+	"gallery_full":              {en: "gallery is full (max %d images)", fa: "گالری پر است (حداکثر %d تصویر)"},
+	"gallery_image_not_found":   {en: "gallery image not found", fa: "تصویر گالری یافت نشد"},
+	"user_not_restorable":       {en: "no deleted user within the restore window", fa: "کاربر حذف‌شده‌ای در بازه قابل بازیابی یافت نشد"},
+	"invalid_history_query":     {en: "invalid user ID or cursor", fa: "شناسه کاربر یا نشانگر صفحه نامعتبر است"},
+	"user_restore_conflict":     {en: "phone or username is now used by another account", fa: "شماره تلفن یا نام کاربری اکنون متعلق به حساب دیگری است"},
+	"invalid_search_query":      {en: "q must be between 2 and 100 characters", fa: "عبارت جستجو (q) باید بین ۲ تا ۱۰۰ نویسه باشد"},
+	"invalid_profile_type":      {en: "type must be one of: personal, business", fa: "نوع باید یکی از personal یا business باشد"},
+
+	// Devices & notifications
+	"device_not_found":              {en: "device not found", fa: "دستگاه یافت نشد"},
+	"push_token_required":           {en: "pushToken is required", fa: "توکن اعلان (pushToken) الزامی است"},
+	"invalid_platform":              {en: "platform must be one of: android, ios, web", fa: "سکو باید یکی از android، ios یا web باشد"},
+	"device_fields_too_long":        {en: "appVersion or model is too long", fa: "نسخه برنامه یا مدل دستگاه بیش از حد طولانی است"},
+	"notification_not_found":        {en: "notification not found", fa: "اعلان یافت نشد"},
+	"invalid_notification_settings": {en: "settings must list known event types and channels, and mandatory channels cannot be disabled", fa: "تنظیمات باید شامل رویدادها و کانال‌های معتبر باشد و کانال‌های اجباری قابل غیرفعال‌سازی نیستند"},
+
+	// Conversations & blocks
+	"conversation_not_found": {en: "conversation not found", fa: "گفتگو یافت نشد"},
+	"self_conversation":      {en: "cannot start a conversation with yourself", fa: "نمی‌توانید با خودتان گفتگو را آغاز کنید"},
+	"cannot_message_user":    {en: "you cannot message this user", fa: "امکان ارسال پیام به این کاربر وجود ندارد"},
+	"body_required":          {en: "body is required", fa: "متن پیام الزامی است"},
+	"prohibited_content":     {en: "message contains prohibited content", fa: "پیام شامل محتوای غیرمجاز است"},
+	"self_block":             {en: "you cannot block yourself", fa: "نمی‌توانید خودتان را مسدود کنید"},
+	"user_not_blocked":       {en: "user is not blocked", fa: "این کاربر مسدود نیست"},
+
+	// Groups & expenses
+	"group_not_found":             {en: "group not found", fa: "گروه یافت نشد"},
+	"group_full":                  {en: "group has reached the maximum of 200 members", fa: "گروه به سقف ۲۰۰ عضو رسیده است"},
+	"already_group_member":        {en: "user is already a member of this group", fa: "این کاربر قبلاً عضو گروه است"},
+	"not_group_member":            {en: "user is not a member of this group", fa: "این کاربر عضو گروه نیست"},
+	"cannot_add_user":             {en: "this user cannot be added", fa: "امکان افزودن این کاربر وجود ندارد"},
+	"group_role_forbidden":        {en: "your role in this group does not allow this action", fa: "نقش شما در این گروه اجازه این کار را نمی‌دهد"},
+	"owner_cannot_leave":          {en: "the owner cannot leave the group; delete it instead", fa: "مالک نمی‌تواند گروه را ترک کند؛ در عوض گروه را حذف کنید"},
+	"invalid_group_role":          {en: "role must be one of: admin, member", fa: "نقش باید یکی از admin یا member باشد"},
+	"invite_link_invalid":         {en: "invite link is invalid or has been disabled", fa: "لینک دعوت نامعتبر است یا غیرفعال شده است"},
+	"expense_not_found":           {en: "expense not found", fa: "هزینه یافت نشد"},
+	"expense_delete_forbidden":    {en: "only the creator or a group admin can delete this expense", fa: "فقط ثبت‌کننده یا مدیر گروه می‌تواند این هزینه را حذف کند"},
+	"expense_members_only":        {en: "the payer and all participants must be group members", fa: "پرداخت‌کننده و همه شرکت‌کنندگان باید عضو گروه باشند"},
+	"invalid_expense_shares":      {en: "shares must be non-negative, list each user once and sum to the amount", fa: "سهم‌ها باید نامنفی باشند، هر کاربر فقط یک بار آمده باشد و جمع آن‌ها برابر مبلغ باشد"},
+	"invalid_amount":              {en: "amount must be a positive number of rials", fa: "مبلغ باید عددی مثبت به ریال باشد"},
+	"expense_description_invalid": {en: "description is required and must be 255 characters or fewer", fa: "توضیحات الزامی است و باید حداکثر ۲۵۵ نویسه باشد"},
+
+	// Family
+	"self_invitation":         {en: "you cannot invite your own phone number", fa: "نمی‌توانید شماره تلفن خودتان را دعوت کنید"},
+	"invitation_pending":      {en: "an invitation to this phone is already pending", fa: "دعوتی برای این شماره در انتظار پاسخ است"},
+	"invitation_not_pending":  {en: "invitation is no longer pending", fa: "این دعوت دیگر در انتظار پاسخ نیست"},
+	"accounts_already_linked": {en: "accounts are already linked", fa: "این حساب‌ها قبلاً به هم متصل شده‌اند"},
+	"parent_limit":            {en: "this account is already linked to the maximum of 2 parents", fa: "این حساب به سقف ۲ والد متصل شده است"},
+	"children_accept_only":    {en: "only children accounts can accept a family invitation", fa: "فقط حساب‌های کودک می‌توانند دعوت خانواده را بپذیرند"},
+	"personal_invite_only":    {en: "only personal accounts can invite a child", fa: "فقط حساب‌های شخصی می‌توانند کودک را دعوت کنند"},
+	"invalid_family_role":     {en: "role must be one of: parent, guardian", fa: "نقش باید یکی از parent یا guardian باشد"},
+
+	// Kyc & business verification
+	"identity_already_verified":       {en: "identity already verified", fa: "هویت شما قبلاً تأیید شده است"},
+	"identity_verification_not_found": {en: "identity verification not found; submit your details first", fa: "درخواست احراز هویت یافت نشد؛ ابتدا اطلاعات خود را ثبت کنید"},
+	"invalid_national_id":             {en: "national ID must be a valid 10-digit code", fa: "کد ملی باید یک کد ۱۰ رقمی معتبر باشد"},
+	"national_id_taken":               {en: "this national ID is already verified on another account", fa: "این کد ملی قبلاً در حساب دیگری تأیید شده است"},
+	"national_id_phone_mismatch":      {en: "this national ID is not registered to your phone number", fa: "این کد ملی به نام شماره تلفن شما ثبت نشده است"},
+	"invalid_birth_date":              {en: "birthDate must be YYYY-MM-DD and you must be at least 18", fa: "تاریخ تولد باید به قالب YYYY-MM-DD باشد و سن شما حداقل ۱۸ سال باشد"},
+	"verification_not_pending":        {en: "verification is not pending review", fa: "این درخواست در انتظار بررسی نیست"},
+	"business_already_verified":       {en: "business already verified", fa: "کسب‌وکار شما قبلاً تأیید شده است"},
+	"business_verification_not_found": {en: "business verification not found; submit your business details first", fa: "درخواست تأیید کسب‌وکار یافت نشد؛ ابتدا اطلاعات کسب‌وکار خود را ثبت کنید"},
+	"business_verification_only":      {en: "business verification is available to business accounts only", fa: "تأیید کسب‌وکار فقط برای حساب‌های تجاری در دسترس است"},
+	"legal_name_invalid":              {en: "legalName is required and must be 200 characters or fewer", fa: "نام حقوقی الزامی است و باید حداکثر ۲۰۰ نویسه باشد"},
+	"license_number_invalid":          {en: "licenseNumber is required and must be 50 characters or fewer", fa: "شماره مجوز الزامی است و باید حداکثر ۵۰ نویسه باشد"},
+	"invalid_review_status":           {en: "status must be one of: pending, approved, rejected", fa: "وضعیت باید یکی از pending، approved یا rejected باشد"},
+	"review_reason_invalid":           {en: "reason is required and must be 255 characters or fewer", fa: "دلیل الزامی است و باید حداکثر ۲۵۵ نویسه باشد"},
+
+	// Bank accounts & open banking
+	"bank_account_exists":       {en: "bank account already registered", fa: "این حساب بانکی قبلاً ثبت شده است"},
+	"bank_account_not_found":    {en: "bank account not found", fa: "حساب بانکی یافت نشد"},
+	"bank_account_limit":        {en: "maximum number of bank accounts reached", fa: "به سقف تعداد حساب‌های بانکی رسیده‌اید"},
+	"bank_account_default_busy": {en: "default bank account was changed by another request, please retry", fa: "حساب پیش‌فرض هم‌زمان در درخواست دیگری تغییر کرد، لطفاً دوباره تلاش کنید"},
+	"invalid_bank_account":      {en: "invalid card number or IBAN", fa: "شماره کارت یا شبا نامعتبر است"},
+	"invalid_bank_account_kind": {en: "kind must be one of: card, iban", fa: "نوع باید یکی از card یا iban باشد"},
+	"bank_account_linked":       {en: "bank account is already linked", fa: "این حساب بانکی قبلاً متصل شده است"},
+	"bank_link_not_found":       {en: "bank link not found", fa: "اتصال بانکی یافت نشد"},
+	"bank_provider_unavailable": {en: "bank provider is unavailable", fa: "سرویس بانک در دسترس نیست"},
+	"bank_consent_required":     {en: "explicit consent is required to link a bank account", fa: "برای اتصال حساب بانکی رضایت صریح شما لازم است"},
+	"bank_consent_expired":      {en: "consent has expired; link the account again", fa: "رضایت شما منقضی شده است؛ حساب را دوباره متصل کنید"},
+
+	// Branches
+	"branch_not_found":       {en: "branch not found", fa: "شعبه یافت نشد"},
+	"branch_business_only":   {en: "branches are available to business accounts only", fa: "شعبه‌ها فقط برای حساب‌های تجاری در دسترس هستند"},
+	"branch_limit":           {en: "maximum of 50 branches reached", fa: "به سقف ۵۰ شعبه رسیده‌اید"},
+	"branch_staff_limit":     {en: "branch has reached the maximum of 50 staff", fa: "شعبه به سقف ۵۰ کارمند رسیده است"},
+	"already_branch_staff":   {en: "user is already assigned to this branch", fa: "این کاربر قبلاً به این شعبه اختصاص یافته است"},
+	"not_branch_staff":       {en: "user is not assigned to this branch", fa: "این کاربر به این شعبه اختصاص ندارد"},
+	"cannot_assign_user":     {en: "this user cannot be assigned", fa: "امکان اختصاص این کاربر وجود ندارد"},
+	"owner_not_staff":        {en: "the business account cannot be assigned as staff", fa: "حساب کسب‌وکار را نمی‌توان به‌عنوان کارمند اختصاص داد"},
+	"settlement_tag_taken":   {en: "settlement tag is already used by another branch", fa: "این برچسب تسویه قبلاً برای شعبه دیگری استفاده شده است"},
+	"invalid_settlement_tag": {en: "settlementTag must be 1-32 letters, digits, \"-\" or \"_\"", fa: "برچسب تسویه باید ۱ تا ۳۲ نویسه از حروف، ارقام، «-» یا «_» باشد"},
+	"address_too_long":       {en: "address must be 255 characters or fewer", fa: "نشانی باید حداکثر ۲۵۵ نویسه باشد"},
+
+	// Payment pages
+	"payment_page_not_found":         {en: "payment page not found", fa: "صفحه پرداخت یافت نشد"},
+	"payment_page_business_only":     {en: "payment pages are available to business accounts only", fa: "صفحه پرداخت فقط برای حساب‌های تجاری در دسترس است"},
+	"amount_presets_required":        {en: "add amount presets or allow custom amounts", fa: "مبالغ پیش‌فرض اضافه کنید یا ورود مبلغ دلخواه را مجاز کنید"},
+	"invalid_amount_presets":         {en: "amountPresets must be up to %d distinct amounts between 1 and %d rials", fa: "مبالغ پیش‌فرض باید حداکثر %d مبلغ متمایز بین ۱ تا %d ریال باشند"},
+	"headline_too_long":              {en: "headline must be at most %d characters", fa: "عنوان باید حداکثر %d نویسه باشد"},
+	"page_description_too_long":      {en: "description must be at most %d characters", fa: "توضیحات باید حداکثر %d نویسه باشد"},
+	"invalid_accent_color":           {en: "accentColor must be a hex color like #1E88E5", fa: "رنگ باید کد هگز مانند #1E88E5 باشد"},
+	"invalid_order_reference":        {en: "orderReference must be one of: off, optional, required", fa: "شماره سفارش باید یکی از off، optional یا required باشد"},
+	"order_reference_label_too_long": {en: "orderReferenceLabel must be at most %d characters", fa: "عنوان شماره سفارش باید حداکثر %d نویسه باشد"},
+
+	// Webhooks
+	"webhooks_business_only":          {en: "webhooks are available to business accounts only", fa: "وب‌هوک‌ها فقط برای حساب‌های تجاری در دسترس هستند"},
+	"webhook_endpoint_limit":          {en: "maximum number of webhook endpoints reached", fa: "به سقف تعداد نشانی‌های وب‌هوک رسیده‌اید"},
+	"webhook_endpoint_not_found":      {en: "webhook endpoint not found", fa: "نشانی وب‌هوک یافت نشد"},
+	"webhook_delivery_not_found":      {en: "webhook delivery not found", fa: "ارسال وب‌هوک یافت نشد"},
+	"webhook_delivery_not_replayable": {en: "webhook delivery not found or still pending", fa: "ارسال وب‌هوک یافت نشد یا هنوز در انتظار است"},
+	"invalid_webhook_url":             {en: "url must be a valid absolute https URL", fa: "نشانی باید یک URL کامل و معتبر با https باشد"},
+	"unknown_event_type":              {en: "events contains an unknown event type", fa: "فهرست رویدادها شامل نوع رویداد نامعتبر است"},
+	"invalid_overlap":                 {en: "overlapSeconds must be between 0 and 604800", fa: "مقدار overlapSeconds باید بین ۰ تا ۶۰۴۸۰۰ باشد"},
+	"invalid_redelivery_range":        {en: "to must be after from and the range at most 7 days", fa: "زمان پایان باید بعد از زمان شروع و بازه حداکثر ۷ روز باشد"},
+	"invalid_delivery_status":         {en: "status must be one of: pending, succeeded, failed", fa: "وضعیت باید یکی از pending، succeeded یا failed باشد"},
+	"invalid_redelivery_status":       {en: "status must be one of: succeeded, failed", fa: "وضعیت باید یکی از succeeded یا failed باشد"},
+
+	// Categories, maintenance, moderation, admin
+	"category_not_found":             {en: "category not found", fa: "دسته‌بندی یافت نشد"},
+	"category_code_exists":           {en: "category code already exists", fa: "این کد دسته‌بندی قبلاً وجود دارد"},
+	"invalid_category":               {en: "invalid category: code must be 2-10 of [0-9A-Z_] and names 1-100 characters", fa: "دسته‌بندی نامعتبر است: کد باید ۲ تا ۱۰ نویسه از [0-9A-Z_] و نام‌ها ۱ تا ۱۰۰ نویسه باشند"},
+	"unknown_parent_category":        {en: "unknown parent category", fa: "دسته‌بندی والد نامعتبر است"},
+	"maintenance_window_not_found":   {en: "maintenance window not found", fa: "بازه نگهداری یافت نشد"},
+	"invalid_maintenance_window":     {en: "invalid window: check provider, messages (1-500 characters) and times (future end, at most 72h)", fa: "بازه نامعتبر است: ارائه‌دهنده، پیام‌ها (۱ تا ۵۰۰ نویسه) و زمان‌ها (پایان در آینده، حداکثر ۷۲ ساعت) را بررسی کنید"},
+	"invalid_days":                   {en: "days must be between 1 and 90", fa: "تعداد روزها باید بین ۱ تا ۹۰ باشد"},
+	"moderation_item_not_found":      {en: "moderation item not found", fa: "مورد بازبینی یافت نشد"},
+	"moderation_not_awaiting_review": {en: "moderation item is not awaiting review", fa: "این مورد در انتظار بازبینی نیست"},
+	"invalid_moderation_status":      {en: "status must be one of: pending, clear, flagged, approved, rejected", fa: "وضعیت باید یکی از pending، clear، flagged، approved یا rejected باشد"},
+	"block_reason_invalid":           {en: "reason is required with block and must be 255 characters or fewer", fa: "برای مسدودسازی، دلیل الزامی است و باید حداکثر ۲۵۵ نویسه باشد"},
+	"invalid_hashes":                 {en: "hashes must be 1-1000 lowercase hex SHA-256 digests", fa: "هش‌ها باید ۱ تا ۱۰۰۰ مقدار SHA-256 به صورت هگز با حروف کوچک باشند"},
+	"contact_sync_limit":             {en: "daily contact sync limit reached, try again tomorrow", fa: "سقف همگام‌سازی روزانه مخاطبین پر شده است؛ فردا دوباره تلاش کنید"},
+	"message_too_long":               {en: "message must be 255 characters or fewer", fa: "پیام باید حداکثر ۲۵۵ نویسه باشد"},
+	"invalid_chaos_faults":           {en: "targets must be db, storage or sms; percentages 0-100; latency non-negative", fa: "هدف‌ها باید db، storage یا sms باشند؛ درصدها بین ۰ تا ۱۰۰ و تأخیر نامنفی"},
+}
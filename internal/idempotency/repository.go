@@ -0,0 +1,111 @@
+// Package idempotency provides the PostgreSQL-backed store for the
+// idempotency middleware.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/radif/service/internal/middleware"
+)
+
+// Repository persists idempotency records and implements middleware.IdempotencyStore.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new idempotency Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Begin claims (scope, key) unless an unexpired record already exists, in which
+// case that record is returned. Expired records are overwritten in place.
+func (r *Repository) Begin(ctx context.Context, scope, key, requestHash string, ttl time.Duration) (*middleware.IdempotencyRecord, bool, error) {
+	var claimed bool
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO idempotency_keys (scope, key, request_hash, expires_at)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (scope, key) DO UPDATE SET
+		     request_hash = EXCLUDED.request_hash,
+		     completed    = FALSE,
+		     status_code  = NULL,
+		     content_type = NULL,
+		     body         = NULL,
+		     created_at   = NOW(),
+		     expires_at   = EXCLUDED.expires_at
+		 WHERE idempotency_keys.expires_at <= NOW()
+		 RETURNING TRUE`,
+		scope, key, requestHash, time.Now().Add(ttl),
+	).Scan(&claimed)
+	if err == nil {
+		return nil, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, fmt.Errorf("claim idempotency key: %w", err)
+	}
+
+	rec := &middleware.IdempotencyRecord{}
+	var statusCode *int
+	var contentType *string
+	err = r.db.QueryRow(ctx,
+		`SELECT request_hash, completed, status_code, content_type, body
+		 FROM idempotency_keys
+		 WHERE scope = $1 AND key = $2`,
+		scope, key,
+	).Scan(&rec.RequestHash, &rec.Completed, &statusCode, &contentType, &rec.Body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Released between our insert attempt and this read; let the caller retry.
+		return nil, false, fmt.Errorf("idempotency key %q released concurrently", key)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("get idempotency key: %w", err)
+	}
+	if statusCode != nil {
+		rec.StatusCode = *statusCode
+	}
+	if contentType != nil {
+		rec.ContentType = *contentType
+	}
+	return rec, false, nil
+}
+
+// Complete stores the response for a previously claimed key.
+func (r *Repository) Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE idempotency_keys
+		 SET completed = TRUE, status_code = $3, content_type = $4, body = $5
+		 WHERE scope = $1 AND key = $2`,
+		scope, key, statusCode, contentType, body,
+	)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release deletes a claimed key so the request can be retried.
+func (r *Repository) Release(ctx context.Context, scope, key string) error {
+	_, err := r.db.Exec(ctx,
+		`DELETE FROM idempotency_keys WHERE scope = $1 AND key = $2`,
+		scope, key,
+	)
+	if err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes all expired records and returns how many were deleted.
+func (r *Repository) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
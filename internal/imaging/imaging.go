@@ -0,0 +1,152 @@
+// Package imaging decodes uploaded images and renders the square, resized
+// variants served in place of full-size originals.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	// Register decoders for every format the upload endpoints accept.
+	_ "image/gif"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// AvatarSizes are the edge lengths, in pixels, of the square avatar variants.
+var AvatarSizes = []int{64, 128, 512}
+
+// maxPixels bounds the decoded size of an upload, so a small file that
+// declares huge dimensions cannot exhaust memory.
+const maxPixels = 40_000_000
+
+// jpegQuality is the encoding quality of generated variants.
+const jpegQuality = 85
+
+// ErrUnsupported is returned when the data is not a decodable image or is too large.
+var ErrUnsupported = errors.New("unsupported or oversized image")
+
+// Variant is one rendered size of an image.
+type Variant struct {
+	Size int
+	Data []byte
+}
+
+// ContentType is the MIME type of every generated variant.
+const ContentType = "image/jpeg"
+
+// decode decodes a JPEG, PNG, GIF (first frame) or WebP image and returns
+// its format name. Checking the declared dimensions first rejects
+// decompression bombs before any pixels are allocated.
+func decode(data []byte) (image.Image, string, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, "", ErrUnsupported
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrUnsupported
+	}
+	return img, format, nil
+}
+
+// SquareVariants center-crops img to a square and renders it at each size as
+// JPEG. Transparent areas are flattened onto white. Sizes larger than the
+// cropped source are rendered at the source size rather than upscaled.
+func SquareVariants(img image.Image, sizes []int) ([]Variant, error) {
+	b := img.Bounds()
+	edge := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, edge, edge).Add(image.Pt(
+		b.Min.X+(b.Dx()-edge)/2,
+		b.Min.Y+(b.Dy()-edge)/2,
+	))
+
+	out := make([]Variant, 0, len(sizes))
+	for _, size := range sizes {
+		px := min(size, edge)
+		dst := image.NewRGBA(image.Rect(0, 0, px, px))
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.CatmullRom.Scale(dst, dst.Bounds(), img, crop, draw.Over, nil)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, fmt.Errorf("encode %dpx variant: %w", size, err)
+		}
+		out = append(out, Variant{Size: size, Data: buf.Bytes()})
+	}
+	return out, nil
+}
+
+// VariantKey returns the object key of the size variant of the original at
+// key: "u1/abc.png" becomes "u1/abc_64.jpg".
+func VariantKey(key string, size int) string {
+	return fmt.Sprintf("%s_%d.jpg", strings.TrimSuffix(key, path.Ext(key)), size)
+}
+
+// VariantKeys returns the object keys of every avatar size variant of the
+// original at key.
+func VariantKeys(key string) []string {
+	keys := make([]string, len(AvatarSizes))
+	for i, size := range AvatarSizes {
+		keys[i] = VariantKey(key, size)
+	}
+	return keys
+}
+
+// VariantURLs maps each avatar size ("64", "128", "512") to the URL of its
+// variant of the original at key.
+func VariantURLs(key string, publicURL func(key string) string) map[string]string {
+	urls := make(map[string]string, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		urls[strconv.Itoa(size)] = publicURL(VariantKey(key, size))
+	}
+	return urls
+}
+
+// VariantBase reports whether key names a size variant written by
+// VariantKey, returning the original's key without its extension:
+// "u1/abc_64.jpg" gives "u1/abc".
+func VariantBase(key string) (string, bool) {
+	stem, ok := strings.CutSuffix(key, ".jpg")
+	if !ok {
+		return "", false
+	}
+	i := strings.LastIndexByte(stem, '_')
+	if i < 0 {
+		return "", false
+	}
+	size, err := strconv.Atoi(stem[i+1:])
+	if err != nil || !slices.Contains(AvatarSizes, size) {
+		return "", false
+	}
+	return stem[:i], true
+}
+
+// DHash returns a 64-bit difference hash of img: each bit records whether a
+// pixel of a 9x8 grayscale thumbnail is brighter than its right neighbour.
+// Re-encoded, resized or lightly edited copies of an image hash within a few
+// bits of each other, so hashes are compared by Hamming distance.
+func DHash(img image.Image) uint64 {
+	thumb := image.NewGray(image.Rect(0, 0, 9, 8))
+	draw.BiLinear.Scale(thumb, thumb.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	var h uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			h <<= 1
+			if thumb.GrayAt(x, y).Y > thumb.GrayAt(x+1, y).Y {
+				h |= 1
+			}
+		}
+	}
+	return h
+}
@@ -0,0 +1,155 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+)
+
+// originalQuality is the JPEG quality of sanitized originals, higher than
+// the variants' since clients may show the original full screen.
+const originalQuality = 90
+
+// Sanitized is an upload re-encoded from its pixels alone, so EXIF (including
+// GPS position), XMP, ICC profiles and comments are dropped.
+type Sanitized struct {
+	// Image is the decoded upload, turned upright per its EXIF orientation.
+	Image       image.Image
+	Data        []byte
+	ContentType string
+	Ext         string
+}
+
+// Sanitize decodes data under the pixel limit and re-encodes it without
+// metadata. JPEGs stay JPEG; PNG, GIF and WebP become PNG, keeping
+// transparency. GIFs lose all frames but the first.
+func Sanitize(data []byte) (*Sanitized, error) {
+	img, format, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Sanitized{ContentType: "image/png", Ext: ".png"}
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		img = orient(img, jpegOrientation(data))
+		s.ContentType, s.Ext = "image/jpeg", ".jpg"
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: originalQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode sanitized %s: %w", format, err)
+	}
+	s.Image = img
+	s.Data = buf.Bytes()
+	return s, nil
+}
+
+// jpegOrientation returns the EXIF orientation (1-8) of a JPEG, or 1 when it
+// has none or the metadata is malformed.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // image data starts; no EXIF before it
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		seg := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return tiffOrientation(seg[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// tiffOrientation reads the Orientation tag (0x0112) from IFD0 of a TIFF block.
+func tiffOrientation(t []byte) int {
+	if len(t) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(t[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(t[4:]))
+	if ifd < 8 || ifd+2 > len(t) {
+		return 1
+	}
+	n := int(order.Uint16(t[ifd:]))
+	for i := 0; i < n; i++ {
+		e := ifd + 2 + i*12
+		if e+12 > len(t) {
+			return 1
+		}
+		if order.Uint16(t[e:]) == 0x0112 {
+			if v := int(order.Uint16(t[e+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient returns img transformed so that an image with EXIF orientation o
+// displays upright without it.
+func orient(img image.Image, o int) image.Image {
+	if o <= 1 || o > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if o >= 5 {
+		dw, dh = h, w
+	}
+
+	src := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch o {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // rotated 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // needs 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // needs 90° counter-clockwise
+				sx, sy = w-1-y, x
+			}
+			si, di := src.PixOffset(sx, sy), dst.PixOffset(x, y)
+			copy(dst.Pix[di:di+4], src.Pix[si:si+4])
+		}
+	}
+	return dst
+}
@@ -0,0 +1,412 @@
+package kyc
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+	"github.com/radif/service/internal/storage"
+)
+
+const maxDocumentBytes = 5 << 20 // 5 MB
+
+// allowedDocumentTypes maps accepted document MIME types to file extensions.
+var allowedDocumentTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"application/pdf": ".pdf",
+}
+
+// Handler holds HTTP handlers for KYC endpoints.
+type Handler struct {
+	svc   *Service
+	store storage.Storage
+}
+
+// NewHandler creates a new KYC Handler. store must be a private bucket.
+func NewHandler(svc *Service, store storage.Storage) *Handler {
+	return &Handler{svc: svc, store: store}
+}
+
+// Get godoc
+//
+//	@Summary		Get identity verification
+//	@Description	Returns the caller's KYC status. The national ID is masked.
+//	@Tags			kyc
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=Verification}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/kyc [get]
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	v, err := h.svc.Get(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, v)
+}
+
+// Tier godoc
+//
+//	@Summary		Get verification tier
+//	@Description	Returns the caller's verification tier (phone, then id_verified) and the steps left to reach the next one. Step codes: submit_identity, upload_document, await_review, resubmit_identity.
+//	@Tags			kyc
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=TierStatus}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/kyc/tier [get]
+func (h *Handler) Tier(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	ts, err := h.svc.Tier(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, ts)
+}
+
+type submitRequest struct {
+	NationalID string `json:"nationalId" example:"0012345679"`
+	BirthDate  string `json:"birthDate"  example:"1990-05-21"`
+}
+
+// Submit godoc
+//
+//	@Summary		Submit identity verification
+//	@Description	Submit national ID and Gregorian birth date (YYYY-MM-DD) for review. When a phone-ownership verifier is configured, a national ID that does not own the account's phone number is rejected immediately. Resubmitting replaces a pending or rejected submission.
+//	@Tags			kyc
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		submitRequest	true	"Identity details"
+//	@Success		200		{object}	response.Envelope{data=Verification}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/kyc [post]
+func (h *Handler) Submit(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+	phone, _ := r.Context().Value(middleware.UserPhoneKey).(string)
+
+	var req submitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	v, err := h.svc.Submit(r.Context(), userID, phone, req.NationalID, req.BirthDate)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, v)
+}
+
+// UploadDocument godoc
+//
+//	@Summary		Upload identity document
+//	@Description	Upload a scan of the national ID card (JPEG, PNG or PDF, max 5 MB) after submitting identity details. Documents are stored in a private bucket. Uploading again replaces the previous document and re-queues the submission.
+//	@Tags			kyc
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			document	formData	file	true	"Document file"
+//	@Success		200			{object}	response.Envelope{data=Verification}
+//	@Failure		400			{object}	response.Envelope
+//	@Failure		401			{object}	response.Envelope
+//	@Failure		404			{object}	response.Envelope
+//	@Failure		409			{object}	response.Envelope
+//	@Failure		500			{object}	response.Envelope
+//	@Router			/users/me/kyc/document [post]
+func (h *Handler) UploadDocument(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxDocumentBytes+1024)
+	if err := r.ParseMultipartForm(maxDocumentBytes); err != nil {
+		response.BadRequest(w, "file too large or invalid multipart form (max 5 MB)")
+		return
+	}
+
+	file, _, err := r.FormFile("document")
+	if err != nil {
+		response.Localized(w, http.StatusBadRequest, "field_required", "document")
+		return
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, err := file.Read(buf)
+	if err != nil && err != io.EOF {
+		response.InternalError(w)
+		return
+	}
+	contentType := http.DetectContentType(buf[:n])
+	ext, allowed := allowedDocumentTypes[contentType]
+	if !allowed {
+		response.BadRequest(w, "only JPEG, PNG, and PDF documents are allowed")
+		return
+	}
+
+	if _, err := h.svc.Get(r.Context(), userID); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	key, err := documentKey(userID, ext)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	if err := h.store.Upload(r.Context(), key, io.MultiReader(bytes.NewReader(buf[:n]), file), -1, contentType); err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	v, previous, err := h.svc.SetDocument(r.Context(), userID, key)
+	if err != nil {
+		if delErr := h.store.Delete(r.Context(), key); delErr != nil {
+			slog.WarnContext(r.Context(), "kyc: delete orphaned document failed", "key", key, "err", delErr)
+		}
+		writeError(w, err)
+		return
+	}
+	if previous != nil {
+		if err := h.store.Delete(r.Context(), *previous); err != nil {
+			slog.WarnContext(r.Context(), "kyc: delete replaced document failed", "key", *previous, "err", err)
+		}
+	}
+
+	response.OK(w, v)
+}
+
+// documentURLTTL is how long a signed document link in the review queue stays valid.
+const documentURLTTL = 15 * time.Minute
+
+// reviewItem is a verification as shown to staff, with the full national ID
+// and a short-lived link to the document.
+type reviewItem struct {
+	*Verification
+	NationalID  string  `json:"nationalId" example:"0012345679"`
+	DocumentURL *string `json:"documentUrl,omitempty"`
+}
+
+// Queue godoc
+//
+//	@Summary		List KYC submissions
+//	@Description	Staff review queue: verifications with the given status (default pending), oldest submission first, with full national IDs and document links valid for 15 minutes. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status	query		string	false	"Status"	Enums(pending, approved, rejected)
+//	@Param			limit	query		int		false	"Page size (1-100, default 50)"
+//	@Param			offset	query		int		false	"Offset (default 0)"
+//	@Success		200		{object}	response.Envelope{data=[]reviewItem}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/kyc [get]
+func (h *Handler) Queue(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	if status == "" {
+		status = StatusPending
+	}
+	limit, offset := 50, 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			response.BadRequest(w, "offset must be a non-negative integer")
+			return
+		}
+		offset = n
+	}
+
+	list, err := h.svc.Queue(r.Context(), status, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	out := make([]reviewItem, 0, len(list))
+	for _, v := range list {
+		id, err := h.svc.NationalID(v)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "kyc: open national id failed", "user_id", v.UserID, "err", err)
+			response.InternalError(w)
+			return
+		}
+		item := reviewItem{Verification: v, NationalID: id}
+		if v.DocumentKey != nil {
+			if u, err := h.store.SignedURL(r.Context(), *v.DocumentKey, documentURLTTL); err != nil {
+				slog.ErrorContext(r.Context(), "kyc: sign document url failed", "user_id", v.UserID, "err", err)
+			} else {
+				item.DocumentURL = &u
+			}
+		}
+		out = append(out, item)
+	}
+	response.OK(w, out)
+}
+
+// Approve godoc
+//
+//	@Summary		Approve KYC submission
+//	@Description	Approve a pending verification and notify the user. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			userId	path		string	true	"User ID"
+//	@Success		200		{object}	response.Envelope{data=Verification}
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/kyc/{userId}/approve [post]
+func (h *Handler) Approve(w http.ResponseWriter, r *http.Request) {
+	reviewerID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || reviewerID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	v, err := h.svc.Approve(r.Context(), reviewerID, chi.URLParam(r, "userId"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, v)
+}
+
+type rejectRequest struct {
+	Reason string `json:"reason" example:"Document is not legible"`
+}
+
+// Reject godoc
+//
+//	@Summary		Reject KYC submission
+//	@Description	Reject a pending verification with a reason shown to the user, and notify them. The user may resubmit. Admin only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			userId	path		string			true	"User ID"
+//	@Param			request	body		rejectRequest	true	"Reason"
+//	@Success		200		{object}	response.Envelope{data=Verification}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/kyc/{userId}/reject [post]
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	reviewerID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || reviewerID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req rejectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || len([]rune(req.Reason)) > 255 {
+		response.BadRequest(w, "reason is required and must be 255 characters or fewer")
+		return
+	}
+
+	v, err := h.svc.Reject(r.Context(), reviewerID, chi.URLParam(r, "userId"), req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, v)
+}
+
+// writeError maps KYC service errors to responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "identity verification not found; submit your details first")
+	case errors.Is(err, ErrInvalidNationalID):
+		response.BadRequest(w, "national ID must be a valid 10-digit code")
+	case errors.Is(err, ErrInvalidBirthDate):
+		response.BadRequest(w, "birthDate must be YYYY-MM-DD and you must be at least 18")
+	case errors.Is(err, ErrPhoneMismatch):
+		response.BadRequest(w, "this national ID is not registered to your phone number")
+	case errors.Is(err, ErrInvalidStatus):
+		response.BadRequest(w, "status must be one of: pending, approved, rejected")
+	case errors.Is(err, ErrAlreadyApproved):
+		response.Conflict(w, "identity already verified")
+	case errors.Is(err, ErrNationalIDInUse):
+		response.Conflict(w, "this national ID is already verified on another account")
+	case errors.Is(err, ErrNotPending):
+		response.Conflict(w, "verification is not pending review")
+	default:
+		response.InternalError(w)
+	}
+}
+
+// documentKey creates a collision-resistant object key for a KYC document.
+// Format: "kyc/{userID}/{16-byte-hex}{ext}"
+func documentKey(userID, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate key: %w", err)
+	}
+	return fmt.Sprintf("kyc/%s/%x%s", userID, b, ext), nil
+}
@@ -0,0 +1,255 @@
+// Package kyc verifies users' identity: national ID and birth date, an
+// optional phone ownership match, a supporting document, and staff review.
+// Services that gate higher limits on identity ask it whether a user is
+// verified.
+package kyc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Verification statuses.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// Verification is a user's identity verification record.
+type Verification struct {
+	UserID          string     `json:"userId"`
+	NationalID      string     `json:"nationalId"       example:"******1234"`
+	NationalIDEnc   []byte     `json:"-"`
+	NationalIDIndex []byte     `json:"-"`
+	NationalIDLast4 string     `json:"-"`
+	BirthDate       string     `json:"birthDate"        example:"1990-05-21"`
+	Status          string     `json:"status"           example:"pending"`
+	PhoneMatched    *bool      `json:"phoneMatched,omitempty"`
+	DocumentKey     *string    `json:"-"`
+	HasDocument     bool       `json:"hasDocument"`
+	RejectionReason *string    `json:"rejectionReason,omitempty"`
+	ReviewedAt      *time.Time `json:"reviewedAt,omitempty"`
+	SubmittedAt     time.Time  `json:"submittedAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// ErrNotFound is returned when the user has not submitted KYC.
+var ErrNotFound = errors.New("kyc verification not found")
+
+// ErrAlreadyApproved is returned when changing an approved verification.
+var ErrAlreadyApproved = errors.New("identity already verified")
+
+// ErrNationalIDInUse is returned when the national ID is registered to
+// another account.
+var ErrNationalIDInUse = errors.New("national ID already used by another account")
+
+// ErrNotPending is returned when reviewing a verification that is not pending.
+var ErrNotPending = errors.New("kyc verification is not pending review")
+
+// Repository handles KYC persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new KYC Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const selectCols = `user_id, national_id_enc, national_id_index, national_id_last4,
+	to_char(birth_date, 'YYYY-MM-DD'), status, phone_matched, document_key,
+	rejection_reason, reviewed_at, submitted_at, updated_at`
+
+// scanVerification scans a selectCols row into a Verification value.
+func scanVerification(row pgx.Row, v *Verification) error {
+	if err := row.Scan(
+		&v.UserID, &v.NationalIDEnc, &v.NationalIDIndex, &v.NationalIDLast4,
+		&v.BirthDate, &v.Status, &v.PhoneMatched, &v.DocumentKey,
+		&v.RejectionReason, &v.ReviewedAt, &v.SubmittedAt, &v.UpdatedAt,
+	); err != nil {
+		return err
+	}
+	v.NationalID = "******" + v.NationalIDLast4
+	v.HasDocument = v.DocumentKey != nil
+	return nil
+}
+
+// Get returns the user's verification.
+func (r *Repository) Get(ctx context.Context, userID string) (*Verification, error) {
+	v := &Verification{}
+	err := scanVerification(r.db.QueryRow(ctx,
+		`SELECT `+selectCols+` FROM kyc_verifications WHERE user_id = $1`, userID,
+	), v)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("get kyc verification: %w", err)
+	}
+	return v, nil
+}
+
+// Submit creates or replaces the user's submission and puts it back in the
+// review queue. An uploaded document is kept. Approved verifications cannot
+// be replaced.
+func (r *Repository) Submit(ctx context.Context, v *Verification) (*Verification, error) {
+	out := &Verification{}
+	err := scanVerification(r.db.QueryRow(ctx,
+		`INSERT INTO kyc_verifications
+		     (user_id, national_id_enc, national_id_index, national_id_last4, birth_date, phone_matched)
+		 VALUES ($1, $2, $3, $4, $5::date, $6)
+		 ON CONFLICT (user_id) DO UPDATE SET
+		     national_id_enc   = EXCLUDED.national_id_enc,
+		     national_id_index = EXCLUDED.national_id_index,
+		     national_id_last4 = EXCLUDED.national_id_last4,
+		     birth_date        = EXCLUDED.birth_date,
+		     phone_matched     = EXCLUDED.phone_matched,
+		     status            = 'pending',
+		     rejection_reason  = NULL,
+		     reviewed_by       = NULL,
+		     reviewed_at       = NULL,
+		     submitted_at      = NOW()
+		 WHERE kyc_verifications.status <> 'approved'
+		 RETURNING `+selectCols,
+		v.UserID, v.NationalIDEnc, v.NationalIDIndex, v.NationalIDLast4, v.BirthDate, v.PhoneMatched,
+	), out)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAlreadyApproved
+		}
+		if isUniqueViolation(err) {
+			return nil, ErrNationalIDInUse
+		}
+		return nil, fmt.Errorf("submit kyc verification: %w", err)
+	}
+	return out, nil
+}
+
+// SetDocument attaches a document and re-queues the submission for review.
+// It returns the replaced document key, if any, so the caller can delete it.
+func (r *Repository) SetDocument(ctx context.Context, userID, key string) (*Verification, *string, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var status string
+	var previous *string
+	err = tx.QueryRow(ctx,
+		`SELECT status, document_key FROM kyc_verifications WHERE user_id = $1 FOR UPDATE`, userID,
+	).Scan(&status, &previous)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, fmt.Errorf("lock kyc verification: %w", err)
+	}
+	if status == StatusApproved {
+		return nil, nil, ErrAlreadyApproved
+	}
+
+	v := &Verification{}
+	err = scanVerification(tx.QueryRow(ctx,
+		`UPDATE kyc_verifications SET
+		     document_key = $2, status = 'pending', rejection_reason = NULL,
+		     reviewed_by = NULL, reviewed_at = NULL, submitted_at = NOW()
+		 WHERE user_id = $1
+		 RETURNING `+selectCols,
+		userID, key,
+	), v)
+	if err != nil {
+		return nil, nil, fmt.Errorf("set kyc document: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("commit tx: %w", err)
+	}
+	return v, previous, nil
+}
+
+// ListByStatus returns verifications with the given status, oldest submission
+// first.
+func (r *Repository) ListByStatus(ctx context.Context, status string, limit, offset int) ([]*Verification, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+selectCols+` FROM kyc_verifications
+		 WHERE status = $1
+		 ORDER BY submitted_at, user_id
+		 LIMIT $2 OFFSET $3`,
+		status, limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list kyc verifications: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Verification{}
+	for rows.Next() {
+		v := &Verification{}
+		if err := scanVerification(rows, v); err != nil {
+			return nil, fmt.Errorf("scan kyc verification: %w", err)
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+// Review records a staff decision on a pending verification.
+func (r *Repository) Review(ctx context.Context, userID, reviewerID, status string, reason *string) (*Verification, error) {
+	v := &Verification{}
+	err := scanVerification(r.db.QueryRow(ctx,
+		`UPDATE kyc_verifications SET
+		     status = $3, rejection_reason = $4, reviewed_by = $2, reviewed_at = NOW()
+		 WHERE user_id = $1 AND status = 'pending'
+		 RETURNING `+selectCols,
+		userID, reviewerID, status, reason,
+	), v)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if _, getErr := r.Get(ctx, userID); getErr != nil {
+				return nil, getErr
+			}
+			return nil, ErrNotPending
+		}
+		if isInvalidID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("review kyc verification: %w", err)
+	}
+	return v, nil
+}
+
+// IsApproved reports whether the user's identity is verified.
+func (r *Repository) IsApproved(ctx context.Context, userID string) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(ctx,
+		`SELECT EXISTS(SELECT 1 FROM kyc_verifications WHERE user_id = $1 AND status = 'approved')`, userID,
+	).Scan(&ok)
+	if err != nil {
+		if isInvalidID(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("check kyc approved: %w", err)
+	}
+	return ok, nil
+}
+
+// isUniqueViolation checks whether an error is a PostgreSQL unique_violation (code 23505).
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
@@ -0,0 +1,197 @@
+package kyc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/radif/service/internal/notification"
+	"github.com/radif/service/internal/secretbox"
+)
+
+// minAge is the minimum age for identity verification, in years.
+const minAge = 18
+
+// ErrInvalidNationalID is returned when a national ID fails the checksum.
+var ErrInvalidNationalID = errors.New("invalid national ID")
+
+// ErrInvalidBirthDate is returned for malformed dates or users under minAge.
+var ErrInvalidBirthDate = errors.New("invalid birth date")
+
+// ErrPhoneMismatch is returned when the verifier reports that the national ID
+// does not own the account's phone number.
+var ErrPhoneMismatch = errors.New("national ID does not match phone number")
+
+// ErrInvalidStatus is returned for unknown verification statuses.
+var ErrInvalidStatus = errors.New("invalid status")
+
+// Verifier checks that a national ID is the registered owner of a phone
+// number, as Iran's Shahkar service does. It is optional.
+type Verifier interface {
+	MatchPhone(ctx context.Context, nationalID, phone string) (bool, error)
+}
+
+// Service contains business logic for identity verification.
+type Service struct {
+	repo     *Repository
+	box      *secretbox.Box
+	verifier Verifier
+	notifier *notification.Service
+}
+
+// NewService creates a new KYC Service. verifier may be nil, in which case
+// phone ownership is left for staff review.
+func NewService(repo *Repository, box *secretbox.Box, verifier Verifier, notifier *notification.Service) *Service {
+	return &Service{repo: repo, box: box, verifier: verifier, notifier: notifier}
+}
+
+// Get returns the user's verification.
+func (s *Service) Get(ctx context.Context, userID string) (*Verification, error) {
+	return s.repo.Get(ctx, userID)
+}
+
+// Submit validates and stores the user's national ID and birth date (YYYY-MM-DD)
+// and queues them for review. phone is the account's phone number, matched
+// against the national ID when a verifier is configured.
+func (s *Service) Submit(ctx context.Context, userID, phone, nationalID, birthDate string) (*Verification, error) {
+	nationalID = normalizeDigits(strings.TrimSpace(nationalID))
+	if !validNationalID(nationalID) {
+		return nil, ErrInvalidNationalID
+	}
+	born, err := time.Parse(time.DateOnly, strings.TrimSpace(birthDate))
+	if err != nil || born.AddDate(minAge, 0, 0).After(time.Now()) || born.Year() < 1900 {
+		return nil, ErrInvalidBirthDate
+	}
+
+	var matched *bool
+	if s.verifier != nil {
+		ok, err := s.verifier.MatchPhone(ctx, nationalID, phone)
+		switch {
+		case err != nil:
+			// Leave the match for staff rather than blocking submission on a
+			// provider outage.
+			slog.WarnContext(ctx, "kyc: phone match failed", "user_id", userID, "err", err)
+		case !ok:
+			return nil, ErrPhoneMismatch
+		default:
+			matched = &ok
+		}
+	}
+
+	enc, err := s.box.Seal([]byte(nationalID), []byte(userID))
+	if err != nil {
+		return nil, fmt.Errorf("seal national id: %w", err)
+	}
+	return s.repo.Submit(ctx, &Verification{
+		UserID:          userID,
+		NationalIDEnc:   enc,
+		NationalIDIndex: s.box.Index([]byte(nationalID)),
+		NationalIDLast4: nationalID[6:],
+		BirthDate:       born.Format(time.DateOnly),
+		PhoneMatched:    matched,
+	})
+}
+
+// SetDocument records an uploaded identity document and re-queues the
+// submission. It returns the key of the document it replaced, if any.
+func (s *Service) SetDocument(ctx context.Context, userID, key string) (*Verification, *string, error) {
+	return s.repo.SetDocument(ctx, userID, key)
+}
+
+// Queue lists verifications by status for staff, oldest first.
+func (s *Service) Queue(ctx context.Context, status string, limit, offset int) ([]*Verification, error) {
+	if status != StatusPending && status != StatusApproved && status != StatusRejected {
+		return nil, ErrInvalidStatus
+	}
+	return s.repo.ListByStatus(ctx, status, limit, offset)
+}
+
+// NationalID decrypts a user's full national ID for staff review.
+func (s *Service) NationalID(v *Verification) (string, error) {
+	plain, err := s.box.Open(v.NationalIDEnc, []byte(v.UserID))
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// Approve marks a pending verification approved, which raises the user to
+// TierIDVerified, and congratulates them.
+func (s *Service) Approve(ctx context.Context, reviewerID, userID string) (*Verification, error) {
+	v, err := s.repo.Review(ctx, userID, reviewerID, StatusApproved, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(ctx, userID, "تبریک! سطح حساب شما ارتقا یافت", "هویت شما تأیید شد و حساب ردیف شما به سطح «هویت تأییدشده» ارتقا یافت.")
+	return v, nil
+}
+
+// Reject marks a pending verification rejected with a reason shown to the
+// user, and notifies them.
+func (s *Service) Reject(ctx context.Context, reviewerID, userID, reason string) (*Verification, error) {
+	v, err := s.repo.Review(ctx, userID, reviewerID, StatusRejected, &reason)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(ctx, userID, "احراز هویت رد شد", "درخواست احراز هویت شما رد شد: "+reason)
+	return v, nil
+}
+
+// IsVerified reports whether the user's identity is approved. Services that
+// allow higher limits for verified users call this.
+func (s *Service) IsVerified(ctx context.Context, userID string) (bool, error) {
+	return s.repo.IsApproved(ctx, userID)
+}
+
+// notify sends the review outcome; failures are logged, not returned.
+func (s *Service) notify(ctx context.Context, userID, title, body string) {
+	if _, err := s.notifier.Notify(ctx, userID, notification.Message{
+		Type:  notification.TypeKYCReviewed,
+		Title: title,
+		Body:  body,
+	}); err != nil {
+		slog.ErrorContext(ctx, "kyc: notify review failed", "user_id", userID, "err", err)
+	}
+}
+
+// validNationalID reports whether id is a 10-digit Iranian national ID
+// (code melli) with a valid check digit.
+func validNationalID(id string) bool {
+	if len(id) != 10 {
+		return false
+	}
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	if strings.Count(id, id[:1]) == 10 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(id[i]-'0') * (10 - i)
+	}
+	r := sum % 11
+	check := int(id[9] - '0')
+	if r < 2 {
+		return check == r
+	}
+	return check == 11-r
+}
+
+// normalizeDigits maps Persian (۰-۹) and Arabic-Indic (٠-٩) digits to ASCII.
+func normalizeDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '۰' && r <= '۹':
+			return '0' + (r - '۰')
+		case r >= '٠' && r <= '٩':
+			return '0' + (r - '٠')
+		}
+		return r
+	}, s)
+}
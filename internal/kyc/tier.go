@@ -0,0 +1,63 @@
+package kyc
+
+import (
+	"context"
+	"errors"
+)
+
+// Verification tiers, from lowest to highest. Every account starts at
+// TierPhone, since signing up proves ownership of the phone number.
+const (
+	TierPhone      = "phone"
+	TierIDVerified = "id_verified"
+)
+
+// tierLevels ranks tiers for clients that compare them.
+var tierLevels = map[string]int{TierPhone: 1, TierIDVerified: 2}
+
+// Next steps toward the next tier. Clients localize these codes.
+const (
+	StepSubmitIdentity   = "submit_identity"
+	StepUploadDocument   = "upload_document"
+	StepAwaitReview      = "await_review"
+	StepResubmitIdentity = "resubmit_identity"
+)
+
+// TierStatus is the user's current verification tier and what remains to
+// reach the next one.
+type TierStatus struct {
+	Tier     string  `json:"tier"               example:"phone"`
+	Level    int     `json:"level"              example:"1"`
+	NextTier *string `json:"nextTier,omitempty" example:"id_verified"`
+	// NextSteps are step codes, in order: submit_identity, upload_document,
+	// await_review or resubmit_identity. Empty at the highest tier.
+	NextSteps       []string `json:"nextSteps"`
+	RejectionReason *string  `json:"rejectionReason,omitempty"`
+}
+
+// Tier returns the user's verification tier and next steps.
+func (s *Service) Tier(ctx context.Context, userID string) (*TierStatus, error) {
+	v, err := s.repo.Get(ctx, userID)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	if v != nil && v.Status == StatusApproved {
+		return &TierStatus{Tier: TierIDVerified, Level: tierLevels[TierIDVerified], NextSteps: []string{}}, nil
+	}
+
+	next := TierIDVerified
+	ts := &TierStatus{Tier: TierPhone, Level: tierLevels[TierPhone], NextTier: &next}
+	switch {
+	case v == nil:
+		ts.NextSteps = []string{StepSubmitIdentity, StepUploadDocument}
+	case v.Status == StatusRejected:
+		ts.NextSteps = []string{StepResubmitIdentity}
+		ts.RejectionReason = v.RejectionReason
+	case !v.HasDocument:
+		ts.NextSteps = []string{StepUploadDocument}
+	default:
+		ts.NextSteps = []string{StepAwaitReview}
+	}
+	return ts, nil
+}
@@ -0,0 +1,162 @@
+// Package logfile writes logs to a file rotated by size and age, so
+// deployments without a log shipper keep searchable history across container
+// restarts.
+package logfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the timestamp inserted into rotated file names:
+// "radif.log" becomes "radif-20260102T150405.000.log".
+const backupTimeFormat = "20060102T150405.000"
+
+// RotateOptions controls when a RotatingFile rolls over and which rotated
+// files it keeps. Zero values disable the corresponding rule.
+type RotateOptions struct {
+	MaxSize    int64         // rotate before a write would exceed this many bytes
+	MaxAge     time.Duration // rotate once the current file is this old
+	Retention  time.Duration // delete rotated files older than this
+	MaxBackups int           // keep at most this many rotated files
+}
+
+// RotatingFile is an io.WriteCloser that appends to a file and rotates it by
+// size and age. It is safe for concurrent use.
+type RotatingFile struct {
+	path string
+	opts RotateOptions
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// OpenRotating opens (or creates) the file at path for appending, creating
+// its directory if needed.
+func OpenRotating(path string, opts RotateOptions) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	f := &RotatingFile{path: path, opts: opts}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p, rotating first if the size or age limit is reached.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	tooBig := f.opts.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.opts.MaxSize
+	tooOld := f.opts.MaxAge > 0 && time.Since(f.openedAt) >= f.opts.MaxAge
+	if tooBig || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// open opens the log file, picking up the size and age of an existing one so
+// limits carry across restarts.
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	if f.size > 0 {
+		f.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+// rotate renames the current file with a timestamp, opens a fresh one and
+// prunes old backups. Callers must hold f.mu.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+	f.file = nil
+
+	ext := filepath.Ext(f.path)
+	backup := strings.TrimSuffix(f.path, ext) + "-" + time.Now().Format(backupTimeFormat) + ext
+	if err := os.Rename(f.path, backup); err != nil {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.prune()
+	return nil
+}
+
+// prune deletes rotated files beyond the retention and backup-count limits.
+// Failures are ignored: a leftover backup is not worth losing log lines over.
+func (f *RotatingFile) prune() {
+	if f.opts.Retention <= 0 && f.opts.MaxBackups <= 0 {
+		return
+	}
+	ext := filepath.Ext(f.path)
+	matches, err := filepath.Glob(strings.TrimSuffix(f.path, ext) + "-*" + ext)
+	if err != nil {
+		return
+	}
+
+	type backup struct {
+		path    string
+		modTime time.Time
+	}
+	var backups []backup
+	for _, m := range matches {
+		info, err := os.Stat(m)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		backups = append(backups, backup{m, info.ModTime()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].modTime.After(backups[j].modTime) })
+
+	for i, b := range backups {
+		expired := f.opts.Retention > 0 && time.Since(b.modTime) > f.opts.Retention
+		excess := f.opts.MaxBackups > 0 && i >= f.opts.MaxBackups
+		if expired || excess {
+			os.Remove(b.path)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+// Package logging configures log/slog, the service's structured logger.
+// Records logged with a request context carry its request and trace IDs, so
+// every line a request produces can be found from any one of them.
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Options configures Setup.
+type Options struct {
+	// Level is the minimum level logged: debug, info, warn or error.
+	Level string
+	// Format is the console format: json or text.
+	Format  string
+	Service string
+	Env     string
+	// File, when set, also receives every record as JSON, whatever Format is.
+	File io.Writer
+}
+
+// Setup makes a logger built from opts the slog default. Output of the
+// standard log package, as used by dependencies, goes through it too.
+func Setup(opts Options) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+		return fmt.Errorf("invalid log level %q", opts.Level)
+	}
+	handlerOpts := &slog.HandlerOptions{Level: level}
+
+	var h slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "json":
+		h = slog.NewJSONHandler(os.Stderr, handlerOpts)
+	case "text":
+		h = slog.NewTextHandler(os.Stderr, handlerOpts)
+	default:
+		return fmt.Errorf("invalid log format %q (want json or text)", opts.Format)
+	}
+	if opts.File != nil {
+		h = fanout{h, slog.NewJSONHandler(opts.File, handlerOpts)}
+	}
+
+	logger := slog.New(contextHandler{h}).With("service", opts.Service)
+	if opts.Env != "" {
+		logger = logger.With("env", opts.Env)
+	}
+	slog.SetDefault(logger)
+	return nil
+}
+
+// contextHandler adds the request and trace IDs found in the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle implements slog.Handler.
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := chiMiddleware.GetReqID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup implements slog.Handler.
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// fanout sends each record to several handlers.
+type fanout []slog.Handler
+
+// Enabled implements slog.Handler.
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle implements slog.Handler. A failing sink, such as a full disk, does
+// not keep the record from the others.
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range f {
+		if h.Enabled(ctx, r.Level) {
+			if err := h.Handle(ctx, r.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithAttrs implements slog.Handler.
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+// WithGroup implements slog.Handler.
+func (f fanout) WithGroup(name string) slog.Handler {
+	out := make(fanout, len(f))
+	for i, h := range f {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
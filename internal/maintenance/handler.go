@@ -0,0 +1,131 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/i18n"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for maintenance window endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new maintenance Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// List godoc
+//
+//	@Summary		List maintenance windows
+//	@Description	Returns active and upcoming PSP/bank maintenance windows so clients can warn users ahead of time. The message field is localized from Accept-Language (fa or en; default fa).
+//	@Tags			maintenance
+//	@Produce		json
+//	@Param			Accept-Language	header		string	false	"Preferred language (fa, en)"
+//	@Success		200				{object}	response.Envelope{data=[]Window}
+//	@Failure		500				{object}	response.Envelope
+//	@Router			/maintenance-windows [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	windows, err := h.svc.Upcoming(r.Context(), i18n.PreferredLanguage(r.Header.Get("Accept-Language")))
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, windows)
+}
+
+type scheduleRequest struct {
+	Provider  string    `json:"provider"  example:"shaparak"`
+	StartsAt  time.Time `json:"startsAt"  example:"2026-03-20T00:00:00Z"`
+	EndsAt    time.Time `json:"endsAt"    example:"2026-03-20T04:00:00Z"`
+	MessageFa string    `json:"messageFa"`
+	MessageEn string    `json:"messageEn"`
+}
+
+// Schedule godoc
+//
+//	@Summary		Schedule maintenance window (admin or PSP monitor)
+//	@Description	Announce a PSP/bank outage. provider is a lowercase provider slug, or "all". Windows may last at most 72 hours. Admins call the /admin route; status monitors call the /integrations route with an API key holding maintenance:write.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Param			request	body		scheduleRequest	true	"Window"
+//	@Success		201		{object}	response.Envelope{data=Window}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/maintenance-windows [post]
+//	@Router			/integrations/maintenance-windows [post]
+func (h *Handler) Schedule(w http.ResponseWriter, r *http.Request) {
+	// Windows scheduled with an API key have no creating user.
+	var createdBy *string
+	if adminID, _ := r.Context().Value(middleware.UserIDKey).(string); adminID != "" {
+		createdBy = &adminID
+	} else if keyID, _ := r.Context().Value(middleware.APIKeyIDKey).(string); keyID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req scheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	out, err := h.svc.Schedule(r.Context(), &Window{
+		Provider:  req.Provider,
+		StartsAt:  req.StartsAt,
+		EndsAt:    req.EndsAt,
+		MessageFa: req.MessageFa,
+		MessageEn: req.MessageEn,
+		CreatedBy: createdBy,
+	}, i18n.PreferredLanguage(r.Header.Get("Accept-Language")))
+	if err != nil {
+		if errors.Is(err, ErrInvalidWindow) {
+			response.BadRequest(w, "invalid window: check provider, messages (1-500 characters) and times (future end, at most 72h)")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+	response.Created(w, out)
+}
+
+// Cancel godoc
+//
+//	@Summary		Cancel maintenance window (admin or PSP monitor)
+//	@Description	Remove a scheduled or active maintenance window.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Security		APIKeyAuth
+//	@Param			id	path		string	true	"Window ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/admin/maintenance-windows/{id} [delete]
+//	@Router			/integrations/maintenance-windows/{id} [delete]
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Cancel(r.Context(), chi.URLParam(r, "id")); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			response.NotFound(w, "maintenance window not found")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, map[string]bool{"success": true})
+}
@@ -0,0 +1,135 @@
+// Package maintenance manages announced PSP/bank maintenance windows.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ProviderAll is the provider value for a window affecting every provider.
+const ProviderAll = "all"
+
+// Window is a scheduled period during which a provider is unavailable.
+// Message is the localized announcement, filled in by the service.
+type Window struct {
+	ID        string    `json:"id"`
+	Provider  string    `json:"provider"`
+	StartsAt  time.Time `json:"startsAt"`
+	EndsAt    time.Time `json:"endsAt"`
+	Message   string    `json:"message"`
+	MessageFa string    `json:"messageFa"`
+	MessageEn string    `json:"messageEn"`
+	CreatedBy *string   `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ErrNotFound is returned when a maintenance window does not exist.
+var ErrNotFound = errors.New("maintenance window not found")
+
+// Repository handles maintenance window persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new maintenance Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const selectCols = `id, provider, starts_at, ends_at, message_fa, message_en, created_by, created_at`
+
+// scanWindow scans a full maintenance_windows row into a Window value.
+func scanWindow(row pgx.Row, w *Window) error {
+	return row.Scan(
+		&w.ID, &w.Provider, &w.StartsAt, &w.EndsAt,
+		&w.MessageFa, &w.MessageEn, &w.CreatedBy, &w.CreatedAt,
+	)
+}
+
+// Create inserts a new maintenance window.
+func (r *Repository) Create(ctx context.Context, w *Window) (*Window, error) {
+	out := &Window{}
+	err := scanWindow(r.db.QueryRow(ctx,
+		`INSERT INTO maintenance_windows (provider, starts_at, ends_at, message_fa, message_en, created_by)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING `+selectCols,
+		w.Provider, w.StartsAt, w.EndsAt, w.MessageFa, w.MessageEn, w.CreatedBy,
+	), out)
+	if err != nil {
+		return nil, fmt.Errorf("create maintenance window: %w", err)
+	}
+	return out, nil
+}
+
+// ListEndingAfter returns windows that end after t (active or upcoming),
+// soonest first.
+func (r *Repository) ListEndingAfter(ctx context.Context, t time.Time) ([]*Window, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+selectCols+` FROM maintenance_windows
+		 WHERE ends_at > $1
+		 ORDER BY starts_at, id`,
+		t,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list maintenance windows: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Window{}
+	for rows.Next() {
+		w := &Window{}
+		if err := scanWindow(rows, w); err != nil {
+			return nil, fmt.Errorf("scan maintenance window: %w", err)
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// ActiveAt returns the window covering provider at t that ends last, or
+// ErrNotFound when the provider is available.
+func (r *Repository) ActiveAt(ctx context.Context, provider string, t time.Time) (*Window, error) {
+	w := &Window{}
+	err := scanWindow(r.db.QueryRow(ctx,
+		`SELECT `+selectCols+` FROM maintenance_windows
+		 WHERE provider IN ($1, '`+ProviderAll+`') AND starts_at <= $2 AND ends_at > $2
+		 ORDER BY ends_at DESC
+		 LIMIT 1`,
+		provider, t,
+	), w)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get active maintenance window: %w", err)
+	}
+	return w, nil
+}
+
+// Delete removes a maintenance window.
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM maintenance_windows WHERE id = $1`, id)
+	if err != nil {
+		if isInvalidID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("delete maintenance window: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
@@ -0,0 +1,89 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/radif/service/internal/i18n"
+)
+
+// maxWindowDuration bounds a single window so a typo cannot block payments for weeks.
+const maxWindowDuration = 72 * time.Hour
+
+var providerRegex = regexp.MustCompile(`^[a-z0-9_]{2,50}$`)
+
+// ErrInvalidWindow is returned when window fields fail validation.
+var ErrInvalidWindow = errors.New("invalid maintenance window")
+
+// Service contains business logic for maintenance windows.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new maintenance Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Schedule validates and stores a new window.
+func (s *Service) Schedule(ctx context.Context, w *Window, lang string) (*Window, error) {
+	w.Provider = strings.ToLower(strings.TrimSpace(w.Provider))
+	w.MessageFa = strings.TrimSpace(w.MessageFa)
+	w.MessageEn = strings.TrimSpace(w.MessageEn)
+	switch {
+	case !providerRegex.MatchString(w.Provider),
+		w.MessageFa == "", len([]rune(w.MessageFa)) > 500,
+		w.MessageEn == "", len([]rune(w.MessageEn)) > 500,
+		!w.EndsAt.After(w.StartsAt),
+		!w.EndsAt.After(time.Now()),
+		w.EndsAt.Sub(w.StartsAt) > maxWindowDuration:
+		return nil, ErrInvalidWindow
+	}
+
+	out, err := s.repo.Create(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+	localize(out, lang)
+	return out, nil
+}
+
+// Upcoming returns active and future windows, localized for lang.
+func (s *Service) Upcoming(ctx context.Context, lang string) ([]*Window, error) {
+	windows, err := s.repo.ListEndingAfter(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range windows {
+		localize(w, lang)
+	}
+	return windows, nil
+}
+
+// ActiveWindow returns the window currently blocking provider, or nil when it
+// is available. Money-movement flows call this to decide whether to queue a
+// request instead of executing it.
+func (s *Service) ActiveWindow(ctx context.Context, provider string) (*Window, error) {
+	w, err := s.repo.ActiveAt(ctx, provider, time.Now())
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	return w, err
+}
+
+// Cancel removes a window.
+func (s *Service) Cancel(ctx context.Context, id string) error {
+	return s.repo.Delete(ctx, id)
+}
+
+// localize sets Message from the language preference; Persian is the default.
+func localize(w *Window, lang string) {
+	if lang == i18n.LangEn {
+		w.Message = w.MessageEn
+		return
+	}
+	w.Message = w.MessageFa
+}
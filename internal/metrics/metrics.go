@@ -0,0 +1,102 @@
+// Package metrics exposes Prometheus metrics for the API: HTTP traffic,
+// database pool usage, object storage latency and OTP delivery.
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "radif"
+
+// OTP send outcomes.
+const (
+	OTPSent        = "sent"         // handed to an SMS provider
+	OTPQueued      = "queued"       // every provider was down; queued for retry
+	OTPLogged      = "logged"       // no SMS provider configured; only logged
+	OTPRateLimited = "rate_limited" // refused by the per-phone limit
+	OTPFailed      = "failed"
+)
+
+// Metrics holds the service's collectors and the registry serving them.
+type Metrics struct {
+	reg *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	storageOps   *prometheus.HistogramVec
+	otpSends     *prometheus.CounterVec
+}
+
+// New creates the collectors, along with the Go runtime and process ones.
+func New() *Metrics {
+	m := &Metrics{
+		reg: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method and route pattern.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		}, []string{"method", "route"}),
+		storageOps: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "storage_operation_duration_seconds",
+			Help:      "Object storage call latency by bucket, operation and result.",
+			Buckets:   []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		}, []string{"bucket", "operation", "result"}),
+		otpSends: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "otp_sends_total",
+			Help:      "OTP send requests by outcome.",
+		}, []string{"outcome"}),
+	}
+	m.reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.storageOps,
+		m.otpSends,
+	)
+	return m
+}
+
+// Handler serves the metrics in the Prometheus exposition format. When token
+// is set, scrapers must send it as a bearer token.
+func (m *Metrics) Handler(token string) http.Handler {
+	h := promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{})
+	if token == "" {
+		return h
+	}
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// ObserveRequest records a served HTTP request; it implements
+// middleware.RequestObserver.
+func (m *Metrics) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.httpDuration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
+
+// OTPSend counts an OTP send request with one of the OTP* outcomes.
+func (m *Metrics) OTPSend(outcome string) {
+	m.otpSends.WithLabelValues(outcome).Inc()
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reports pgxpool statistics at scrape time.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired       *prometheus.Desc
+	idle           *prometheus.Desc
+	constructing   *prometheus.Desc
+	total          *prometheus.Desc
+	max            *prometheus.Desc
+	acquires       *prometheus.Desc
+	acquireSeconds *prometheus.Desc
+	emptyAcquires  *prometheus.Desc
+	canceled       *prometheus.Desc
+}
+
+// RegisterPool exports the statistics of the database pool.
+func (m *Metrics) RegisterPool(pool *pgxpool.Pool) {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	m.reg.MustRegister(&poolCollector{
+		pool:           pool,
+		acquired:       desc("acquired_conns", "Connections currently checked out."),
+		idle:           desc("idle_conns", "Idle connections in the pool."),
+		constructing:   desc("constructing_conns", "Connections being opened."),
+		total:          desc("total_conns", "All connections in the pool."),
+		max:            desc("max_conns", "Maximum size of the pool."),
+		acquires:       desc("acquires_total", "Successful connection acquisitions."),
+		acquireSeconds: desc("acquire_seconds_total", "Time spent acquiring connections."),
+		emptyAcquires:  desc("empty_acquires_total", "Acquisitions that waited because the pool had no idle connection."),
+		canceled:       desc("canceled_acquires_total", "Acquisitions cancelled by their context."),
+	})
+}
+
+// Describe implements prometheus.Collector.
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.constructing
+	ch <- c.total
+	ch <- c.max
+	ch <- c.acquires
+	ch <- c.acquireSeconds
+	ch <- c.emptyAcquires
+	ch <- c.canceled
+}
+
+// Collect implements prometheus.Collector.
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.constructing, prometheus.GaugeValue, float64(s.ConstructingConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireSeconds, prometheus.CounterValue, s.AcquireDuration().Seconds())
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.canceled, prometheus.CounterValue, float64(s.CanceledAcquireCount()))
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/radif/service/internal/storage"
+)
+
+// timedStorage records the latency of each storage call.
+type timedStorage struct {
+	storage.Storage
+	m      *Metrics
+	bucket string
+}
+
+// WrapStorage returns s with every call except PublicURL, which does not
+// reach the store, timed under the bucket label.
+func (m *Metrics) WrapStorage(bucket string, s storage.Storage) storage.Storage {
+	return &timedStorage{Storage: s, m: m, bucket: bucket}
+}
+
+// observe records a call that started at start and ended with err.
+func (s *timedStorage) observe(op string, start time.Time, err error) {
+	result := "ok"
+	switch {
+	case errors.Is(err, storage.ErrObjectNotFound):
+		result = "not_found"
+	case err != nil:
+		result = "error"
+	}
+	s.m.storageOps.WithLabelValues(s.bucket, op, result).Observe(time.Since(start).Seconds())
+}
+
+// Upload implements storage.Storage.
+func (s *timedStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	start := time.Now()
+	err := s.Storage.Upload(ctx, key, reader, size, contentType)
+	s.observe("upload", start, err)
+	return err
+}
+
+// Delete implements storage.Storage.
+func (s *timedStorage) Delete(ctx context.Context, key string) error {
+	start := time.Now()
+	err := s.Storage.Delete(ctx, key)
+	s.observe("delete", start, err)
+	return err
+}
+
+// PresignPut implements storage.Storage.
+func (s *timedStorage) PresignPut(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	start := time.Now()
+	u, err := s.Storage.PresignPut(ctx, key, expiry)
+	s.observe("presign_put", start, err)
+	return u, err
+}
+
+// SignedURL implements storage.Storage.
+func (s *timedStorage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	start := time.Now()
+	u, err := s.Storage.SignedURL(ctx, key, ttl)
+	s.observe("signed_url", start, err)
+	return u, err
+}
+
+// Get implements storage.Storage. Only opening the object is timed, not
+// reading it.
+func (s *timedStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	start := time.Now()
+	rc, err := s.Storage.Get(ctx, key)
+	s.observe("get", start, err)
+	return rc, err
+}
+
+// Stat implements storage.Storage.
+func (s *timedStorage) Stat(ctx context.Context, key string) (*storage.ObjectInfo, error) {
+	start := time.Now()
+	info, err := s.Storage.Stat(ctx, key)
+	s.observe("stat", start, err)
+	return info, err
+}
+
+// List implements storage.Storage. The whole listing, callbacks included,
+// is timed as one call.
+func (s *timedStorage) List(ctx context.Context, prefix string, fn func(*storage.ObjectInfo) error) error {
+	start := time.Now()
+	err := s.Storage.List(ctx, prefix, fn)
+	s.observe("list", start, err)
+	return err
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -21,9 +22,52 @@ const UserPhoneKey contextKey = "userPhone"
 // UserAccountTypeKey is the context key for the authenticated user's account type.
 const UserAccountTypeKey contextKey = "userAccountType"
 
+// UserRoleKey is the context key for the authenticated user's role ("user" or
+// "admin"). RequireRole sets it from the database; tokens do not carry it.
+const UserRoleKey contextKey = "userRole"
+
+// TokenIDKey is the context key for the token's ID (its jti claim). Tokens
+// issued before IDs were added have none.
+const TokenIDKey contextKey = "tokenID"
+
+// TokenExpiryKey is the context key for the token's expiry time.
+const TokenExpiryKey contextKey = "tokenExpiry"
+
+// ImpersonatorIDKey is the context key for the ID of the admin acting as the
+// user through an impersonation token (its act claim). It is unset for the
+// user's own tokens.
+const ImpersonatorIDKey contextKey = "impersonatorID"
+
+// RoleAdmin is the role granted to Radif staff.
+const RoleAdmin = "admin"
+
+// RoleLookup returns a user's current role, or "" when the user does not
+// exist.
+type RoleLookup interface {
+	Role(ctx context.Context, userID string) (string, error)
+}
+
+// Accounts looks up the current state of user accounts, so tokens lose
+// access as soon as the account changes rather than when they expire.
+type Accounts interface {
+	RoleLookup
+	// Active reports whether the user exists and has not been deleted.
+	Active(ctx context.Context, userID string) (bool, error)
+}
+
+// RevocationList reports whether a token has been revoked before it expired.
+type RevocationList interface {
+	IsRevoked(ctx context.Context, tokenID string) bool
+}
+
 // RequireAuth returns middleware that validates a Bearer JWT and injects
-// user claims into the request context.
-func RequireAuth(jwtSecret string) func(http.Handler) http.Handler {
+// user claims into the request context. A token signed with any of the
+// secrets returned by keys is accepted; keys is called per request so a
+// rotated secret takes effect without a restart. Tokens on revoked are
+// rejected; revoked may be nil. Tokens of users that accounts reports
+// deleted are rejected, and so are impersonation tokens once the admin named
+// in their act claim no longer has the admin role.
+func RequireAuth(keys func() []string, revoked RevocationList, accounts Accounts) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			authHeader := r.Header.Get("Authorization")
@@ -42,7 +86,11 @@ func RequireAuth(jwtSecret string) func(http.Handler) http.Handler {
 				if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
 					return nil, jwt.ErrSignatureInvalid
 				}
-				return []byte(jwtSecret), nil
+				var set jwt.VerificationKeySet
+				for _, k := range keys() {
+					set.Keys = append(set.Keys, []byte(k))
+				}
+				return set, nil
 			})
 			if err != nil || !token.Valid {
 				response.Unauthorized(w, "invalid or expired token")
@@ -55,14 +103,106 @@ func RequireAuth(jwtSecret string) func(http.Handler) http.Handler {
 				return
 			}
 
+			tokenID, _ := claims["jti"].(string)
+			if tokenID != "" && revoked != nil && revoked.IsRevoked(r.Context(), tokenID) {
+				response.Unauthorized(w, "token has been revoked")
+				return
+			}
+
 			userID, _ := claims["sub"].(string)
 			phone, _ := claims["phone"].(string)
 			accountType, _ := claims["accountType"].(string)
+			scopeClaim, hasScope := claims["scope"].(string)
+			var impersonatorID string
+			if act, ok := claims["act"].(map[string]interface{}); ok {
+				impersonatorID, _ = act["sub"].(string)
+			}
+
+			if userID == "" {
+				response.Unauthorized(w, "invalid token claims")
+				return
+			}
+			active, err := accounts.Active(r.Context(), userID)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "middleware: account lookup failed", "user_id", userID, "err", err)
+				response.InternalError(w)
+				return
+			}
+			if !active {
+				response.Unauthorized(w, "account has been deleted")
+				return
+			}
+			if impersonatorID != "" {
+				role, err := accounts.Role(r.Context(), impersonatorID)
+				if err != nil {
+					slog.ErrorContext(r.Context(), "middleware: impersonator role lookup failed", "impersonator_id", impersonatorID, "err", err)
+					response.InternalError(w)
+					return
+				}
+				if role != RoleAdmin {
+					response.Unauthorized(w, "token has been revoked")
+					return
+				}
+			}
 
+			logUser(r.Context(), userID, impersonatorID)
 			ctx := context.WithValue(r.Context(), UserIDKey, userID)
 			ctx = context.WithValue(ctx, UserPhoneKey, phone)
 			ctx = context.WithValue(ctx, UserAccountTypeKey, accountType)
+			ctx = context.WithValue(ctx, UserScopesKey, parseScopes(scopeClaim, hasScope))
+			ctx = context.WithValue(ctx, TokenIDKey, tokenID)
+			if impersonatorID != "" {
+				ctx = context.WithValue(ctx, ImpersonatorIDKey, impersonatorID)
+			}
+			if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+				ctx = context.WithValue(ctx, TokenExpiryKey, exp.Time)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireRole returns middleware that rejects authenticated users without the
+// given role. The role is looked up in roles on every request rather than
+// taken from the token, so a demoted admin loses access at once.
+// Impersonation tokens are always rejected: they act as a user, and must not
+// gain staff access if that user is later made an admin. It must be mounted
+// after RequireAuth.
+func RequireRole(role string, roles RoleLookup) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, _ := r.Context().Value(UserIDKey).(string)
+			impersonatorID, _ := r.Context().Value(ImpersonatorIDKey).(string)
+			if userID == "" || impersonatorID != "" {
+				response.Forbidden(w, "insufficient permissions")
+				return
+			}
+			got, err := roles.Role(r.Context(), userID)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "middleware: role lookup failed", "user_id", userID, "err", err)
+				response.InternalError(w)
+				return
+			}
+			if got != role {
+				response.Forbidden(w, "insufficient permissions")
+				return
+			}
+			ctx := context.WithValue(r.Context(), UserRoleKey, got)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// DenyImpersonation rejects requests made with an impersonation token. Mount
+// it on anything that moves money or mints credentials: staff may look at a
+// user's account, never act on their funds. It must be mounted after
+// RequireAuth.
+func DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, _ := r.Context().Value(ImpersonatorIDKey).(string); id != "" {
+			response.Forbidden(w, "not allowed while impersonating a user")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// CachePolicy is a Cache-Control value declared for a route.
+type CachePolicy string
+
+// CacheNoStore forbids any cache from keeping the response. It is the default
+// for every route, and the right policy for anything touching money or
+// credentials.
+const CacheNoStore CachePolicy = "no-store"
+
+// CacheRevalidate lets only the client's own cache keep the response, and
+// only to revalidate it with If-None-Match before each use. Use it for
+// per-user data served with an ETag.
+const CacheRevalidate CachePolicy = "private, no-cache"
+
+// CachePrivate lets only the client's own cache keep the response for maxAge.
+// Use it for per-user data that tolerates brief staleness.
+func CachePrivate(maxAge time.Duration) CachePolicy {
+	return CachePolicy(fmt.Sprintf("private, max-age=%d", int(maxAge.Seconds())))
+}
+
+// CachePublic lets shared caches (CDNs, proxies) keep the response for maxAge.
+// Only use it on unauthenticated routes whose response is the same for
+// everyone.
+func CachePublic(maxAge time.Duration) CachePolicy {
+	return CachePolicy(fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+}
+
+// DefaultCacheControl marks every response no-store unless a route declares
+// otherwise with Cache. Without it intermediaries may apply heuristic caching
+// to authenticated responses.
+func DefaultCacheControl(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", string(CacheNoStore))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Cache declares a route's cache policy, plus the request headers the
+// response varies by (e.g. Accept-Language for localized content). The policy
+// applies to successful and redirect responses only; errors stay no-store so
+// a transient failure is never cached.
+func Cache(policy CachePolicy, vary ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, h := range vary {
+				w.Header().Add("Vary", h)
+			}
+			next.ServeHTTP(&cacheWriter{ResponseWriter: w, policy: policy}, r)
+		})
+	}
+}
+
+// cacheWriter sets Cache-Control from the status code when headers are sent.
+type cacheWriter struct {
+	http.ResponseWriter
+	policy      CachePolicy
+	wroteHeader bool
+}
+
+func (cw *cacheWriter) WriteHeader(status int) {
+	if !cw.wroteHeader {
+		cw.wroteHeader = true
+		if status < http.StatusBadRequest {
+			cw.Header().Set("Cache-Control", string(cw.policy))
+		} else {
+			cw.Header().Set("Cache-Control", string(CacheNoStore))
+		}
+	}
+	cw.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *cacheWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *cacheWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	return cw.ResponseWriter.Write(b)
+}
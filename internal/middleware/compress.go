@@ -0,0 +1,233 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// compressibleTypes are the media types worth compressing. Event streams are
+// left out: they are flushed a few bytes at a time and must not be held back.
+var compressibleTypes = map[string]bool{
+	"application/json":         true,
+	"application/problem+json": true,
+	"application/javascript":   true,
+	"application/xml":          true,
+	"image/svg+xml":            true,
+	"text/plain":               true,
+	"text/html":                true,
+	"text/css":                 true,
+	"text/csv":                 true,
+}
+
+// encoder is a pooled gzip or zlib writer.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+// Compress gzip- or deflate-encodes responses with a compressible
+// Content-Type once they reach minSize bytes, for clients that accept it.
+// Smaller responses are sent as is: below roughly a kilobyte the encoding
+// overhead outweighs the saving. level is a compress/flate level (1-9);
+// flate.NoCompression turns the middleware into a no-op and other values
+// mean the default level.
+func Compress(level, minSize int) func(http.Handler) http.Handler {
+	if level == flate.NoCompression {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	if level < flate.BestSpeed || level > flate.BestCompression {
+		level = flate.DefaultCompression
+	}
+	pools := map[string]*sync.Pool{
+		"gzip": {New: func() any {
+			zw, _ := gzip.NewWriterLevel(io.Discard, level)
+			return zw
+		}},
+		// HTTP's deflate coding is the zlib format (RFC 9110 §8.4.1.2),
+		// not raw DEFLATE.
+		"deflate": {New: func() any {
+			zw, _ := zlib.NewWriterLevel(io.Discard, level)
+			return zw
+		}},
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, pool: pools[encoding], minSize: minSize}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// acceptedEncoding picks gzip, then deflate, from an Accept-Encoding value,
+// skipping codings the client refuses with q=0.
+func acceptedEncoding(header string) string {
+	accepted := map[string]bool{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		accepted[name] = true
+	}
+	for _, enc := range []string{"gzip", "deflate"} {
+		if accepted[enc] || accepted["*"] {
+			return enc
+		}
+	}
+	return ""
+}
+
+// compressWriter holds back the start of the body until it knows whether
+// the response is large enough to compress.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	pool     *sync.Pool
+	minSize  int
+
+	status      int
+	wroteHeader bool // WriteHeader was called by the handler
+	decided     bool // headers went out, plain or encoded
+	buf         bytes.Buffer
+	enc         encoder
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	// Informational responses, such as a WebSocket's 101, go straight out.
+	if status < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+	if !cw.eligible() {
+		_ = cw.decide(false)
+	}
+}
+
+// eligible reports whether the response may be compressed, from its status
+// and headers.
+func (cw *compressWriter) eligible() bool {
+	h := cw.Header()
+	if cw.status == http.StatusNoContent || cw.status == http.StatusNotModified || h.Get("Content-Encoding") != "" {
+		return false
+	}
+	mediaType, _, _ := strings.Cut(h.Get("Content-Type"), ";")
+	if !compressibleTypes[strings.ToLower(strings.TrimSpace(mediaType))] {
+		return false
+	}
+	if n, err := strconv.Atoi(h.Get("Content-Length")); err == nil && n < cw.minSize {
+		return false
+	}
+	return true
+}
+
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.wroteHeader {
+		if cw.Header().Get("Content-Type") == "" {
+			cw.Header().Set("Content-Type", http.DetectContentType(b))
+		}
+		cw.WriteHeader(http.StatusOK)
+	}
+	switch {
+	case !cw.decided:
+		cw.buf.Write(b)
+		if cw.buf.Len() >= cw.minSize {
+			if err := cw.decide(true); err != nil {
+				return 0, err
+			}
+		}
+		return len(b), nil
+	case cw.enc != nil:
+		return cw.enc.Write(b)
+	default:
+		return cw.ResponseWriter.Write(b)
+	}
+}
+
+// decide sends the headers, encoded or not, followed by any held-back body.
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	if compress {
+		h := cw.Header()
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+		// A strong ETag names the exact bytes, which are now different.
+		if etag := h.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			h.Set("ETag", "W/"+etag)
+		}
+		cw.enc = cw.pool.Get().(encoder)
+		cw.enc.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if cw.buf.Len() == 0 {
+		return nil
+	}
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(cw.buf.Bytes())
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.buf.Bytes())
+	}
+	cw.buf.Reset()
+	return err
+}
+
+// FlushError sends what has been written so far, giving up on compressing a
+// response that is flushed before it reaches minSize.
+func (cw *compressWriter) FlushError() error {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if !cw.decided {
+		if err := cw.decide(false); err != nil {
+			return err
+		}
+	}
+	if cw.enc != nil {
+		if err := cw.enc.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// close finishes the response once the handler returns. A handler that
+// wrote nothing, or hijacked the connection, leaves nothing to finish.
+func (cw *compressWriter) close() {
+	if cw.wroteHeader && !cw.decided {
+		_ = cw.decide(false)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Close()
+		cw.enc.Reset(io.Discard)
+		cw.pool.Put(cw.enc)
+		cw.enc = nil
+	}
+}
@@ -0,0 +1,285 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/radif/service/internal/response"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client-generated key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const (
+	maxIdempotencyKeyLen   = 255
+	maxIdempotentBodyBytes = 8 << 20 // 8 MB — covers avatar uploads plus multipart overhead
+	idempotentReplayHeader = "Idempotent-Replayed"
+	defaultIdempotencyTTL  = 24 * time.Hour
+	idempotencyCleanupWait = 5 * time.Second
+)
+
+// IdempotencyRecord is a stored request/response pair for an idempotency key.
+type IdempotencyRecord struct {
+	RequestHash string
+	Completed   bool
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyStore persists idempotency records.
+type IdempotencyStore interface {
+	// Begin atomically claims (scope, key) for ttl. If the key is already claimed
+	// and not expired, the existing record is returned with claimed=false.
+	Begin(ctx context.Context, scope, key, requestHash string, ttl time.Duration) (existing *IdempotencyRecord, claimed bool, err error)
+	// Complete stores the final response for a claimed key.
+	Complete(ctx context.Context, scope, key string, statusCode int, contentType string, body []byte) error
+	// Release drops a claimed key so the client may retry (used after server errors).
+	Release(ctx context.Context, scope, key string) error
+}
+
+// Idempotency returns middleware that makes unsafe requests (POST, PUT, PATCH,
+// DELETE) carrying an Idempotency-Key header safe to retry. The first request with
+// a key is executed and its response stored for ttl; retries with the same key,
+// user, and route replay the stored response instead of executing the handler
+// again. Safe methods, requests without the header and unauthenticated
+// requests pass through unchanged: without a user there is nothing to keep
+// one client's keys apart from another's. A zero ttl defaults to 24 hours.
+func Idempotency(store IdempotencyStore, ttl time.Duration) func(http.Handler) http.Handler {
+	if ttl <= 0 {
+		ttl = defaultIdempotencyTTL
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			userID, _ := r.Context().Value(UserIDKey).(string)
+			if key == "" || userID == "" || !isUnsafeMethod(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				response.BadRequest(w, "Idempotency-Key must be 255 characters or fewer")
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBodyBytes+1))
+			if err != nil {
+				response.BadRequest(w, "invalid request body")
+				return
+			}
+			if len(body) > maxIdempotentBodyBytes {
+				response.Error(w, http.StatusRequestEntityTooLarge, "request body too large")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scope := idempotencyScope(userID, r)
+			hash := requestHash(r, body)
+
+			existing, claimed, err := store.Begin(r.Context(), scope, key, hash, ttl)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "idempotency: begin failed", "key", key, "err", err)
+				response.InternalError(w)
+				return
+			}
+			if !claimed {
+				replayIdempotent(w, r, existing, hash)
+				return
+			}
+
+			// Bookkeeping after the handler uses a fresh detached context: the
+			// client may already have disconnected, which is exactly the case
+			// idempotency is meant to cover, and the handler may have used up
+			// the request's deadline.
+			cleanupCtx := func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.WithoutCancel(r.Context()), idempotencyCleanupWait)
+			}
+			release := func() {
+				ctx, cancel := cleanupCtx()
+				defer cancel()
+				if err := store.Release(ctx, scope, key); err != nil {
+					slog.ErrorContext(r.Context(), "idempotency: release failed", "key", key, "err", err)
+				}
+			}
+
+			// A panicking handler must not leave the key claimed, or every
+			// retry would get 409 until the claim expires.
+			defer func() {
+				if v := recover(); v != nil {
+					release()
+					panic(v)
+				}
+			}()
+
+			cw := &captureWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(cw, r)
+
+			if cw.statusCode >= http.StatusInternalServerError {
+				release()
+				return
+			}
+			ctx, cancel := cleanupCtx()
+			defer cancel()
+			if err := store.Complete(ctx, scope, key, cw.statusCode, cw.Header().Get("Content-Type"), cw.body.Bytes()); err != nil {
+				slog.ErrorContext(r.Context(), "idempotency: complete failed", "key", key, "err", err)
+			}
+		})
+	}
+}
+
+// isUnsafeMethod reports whether the method may have side effects.
+func isUnsafeMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// replayIdempotent answers a retried request r from the stored record.
+func replayIdempotent(w http.ResponseWriter, r *http.Request, rec *IdempotencyRecord, hash string) {
+	if rec.RequestHash != hash {
+		response.Error(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+		return
+	}
+	if !rec.Completed {
+		response.Conflict(w, "a request with this Idempotency-Key is still being processed")
+		return
+	}
+	if rec.ContentType != "" {
+		w.Header().Set("Content-Type", rec.ContentType)
+	}
+	w.Header().Set(idempotentReplayHeader, "true")
+	w.WriteHeader(rec.StatusCode)
+	body := rec.Body
+	if strings.Contains(rec.ContentType, "json") {
+		body = withRequestID(body, chiMiddleware.GetReqID(r.Context()))
+	}
+	_, _ = w.Write(body)
+}
+
+// withRequestID replaces the requestId of a stored error envelope with id,
+// so a replay reports the request that received it rather than the one
+// that was stored. Other bodies are returned unchanged.
+func withRequestID(body []byte, id string) []byte {
+	var env struct {
+		RequestID string `json:"requestId"`
+	}
+	if id == "" || json.Unmarshal(body, &env) != nil || env.RequestID == "" {
+		return body
+	}
+	oldField, _ := json.Marshal(env.RequestID)
+	newField, _ := json.Marshal(id)
+	oldField = append([]byte(`"requestId":`), oldField...)
+	// requestId is the envelope's last field, after any data.
+	i := bytes.LastIndex(body, oldField)
+	if i < 0 {
+		return body
+	}
+	out := make([]byte, 0, len(body)-len(oldField)+len(newField)+len(`"requestId":`))
+	out = append(out, body[:i]...)
+	out = append(out, `"requestId":`...)
+	out = append(out, newField...)
+	return append(out, body[i+len(oldField):]...)
+}
+
+// idempotencyScope namespaces keys by user and route so that keys generated by
+// different clients or reused across endpoints never collide.
+func idempotencyScope(userID string, r *http.Request) string {
+	return userID + " " + r.Method + " " + r.URL.Path
+}
+
+// requestHash fingerprints the request's query and payload so a key reused
+// with a different request is rejected.
+// Multipart bodies are fingerprinted by their parts rather than their bytes:
+// clients regenerate the random boundary on every retry, so byte-identical
+// retries cannot be expected.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.URL.RawQuery))
+	h.Write([]byte{0})
+	contentType := r.Header.Get("Content-Type")
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil && strings.HasPrefix(mediaType, "multipart/") {
+		if sum, ok := multipartHash(body, params["boundary"]); ok {
+			h.Write([]byte(mediaType))
+			h.Write([]byte{0})
+			h.Write(sum)
+			return hex.EncodeToString(h.Sum(nil))
+		}
+	}
+	h.Write([]byte(contentType))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// multipartHash hashes the headers that identify each part and its content,
+// in order, leaving out the boundary. ok is false for a malformed body,
+// which is then hashed as raw bytes.
+func multipartHash(body []byte, boundary string) (sum []byte, ok bool) {
+	if boundary == "" {
+		return nil, false
+	}
+	h := sha256.New()
+	mr := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := mr.NextRawPart()
+		if err == io.EOF {
+			return h.Sum(nil), true
+		}
+		if err != nil {
+			return nil, false
+		}
+		h.Write([]byte(part.FormName()))
+		h.Write([]byte{0})
+		h.Write([]byte(part.FileName()))
+		h.Write([]byte{0})
+		h.Write([]byte(part.Header.Get("Content-Type")))
+		h.Write([]byte{0})
+		content := sha256.New()
+		if _, err := io.Copy(content, part); err != nil {
+			return nil, false
+		}
+		h.Write(content.Sum(nil))
+	}
+}
+
+// captureWriter tees the response to the client while recording it for storage.
+type captureWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (cw *captureWriter) WriteHeader(code int) {
+	if !cw.wroteHeader {
+		cw.statusCode = code
+		cw.wroteHeader = true
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController and response helpers reach the
+// underlying writer.
+func (cw *captureWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+func (cw *captureWriter) Write(b []byte) (int, error) {
+	cw.wroteHeader = true
+	cw.body.Write(b)
+	return cw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/radif/service/internal/i18n"
+)
+
+// languageWriter carries the negotiated language to the response helpers.
+type languageWriter struct {
+	http.ResponseWriter
+	lang string
+}
+
+// Language implements the interface response.Error looks for.
+func (lw *languageWriter) Language() string {
+	return lw.lang
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (lw *languageWriter) Unwrap() http.ResponseWriter {
+	return lw.ResponseWriter
+}
+
+// Language picks the language of error messages from Accept-Language, so
+// the apps can show Persian errors as is. Requests without the header keep
+// English messages, which existing API integrations match on.
+func Language(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := i18n.LangEn
+		if h := r.Header.Get("Accept-Language"); h != "" {
+			lang = i18n.PreferredLanguage(h)
+		}
+		next.ServeHTTP(&languageWriter{ResponseWriter: w, lang: lang}, r)
+	})
+}
@@ -2,28 +2,97 @@
 package middleware
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/go-chi/chi/v5"
 )
 
-// wrappedWriter captures the status code written by downstream handlers.
+// wrappedWriter captures the status code written by downstream handlers and
+// whether the response has started.
 type wrappedWriter struct {
 	http.ResponseWriter
 	statusCode int
+	started    bool // a final status or body bytes went out
 }
 
 func (rw *wrappedWriter) WriteHeader(code int) {
 	rw.statusCode = code
+	if code >= http.StatusOK {
+		rw.started = true
+	}
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-// Logger logs method, path, status code, and duration for every request.
+func (rw *wrappedWriter) Write(b []byte) (int, error) {
+	rw.started = true
+	return rw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer, e.g. to
+// hijack the connection for a WebSocket.
+func (rw *wrappedWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// requestLog collects fields for the request log line that are only known
+// deeper in the chain, where the context Logger passed down is out of reach.
+type requestLog struct {
+	userID         string
+	impersonatorID string
+}
+
+// requestLogKey is the context key of the request's *requestLog.
+const requestLogKey contextKey = "requestLog"
+
+// Logger logs every request once it has been served, with its method, path,
+// route pattern, status, latency, client IP and, once RequireAuth has run,
+// the user ID and any impersonating admin's ID. Server errors are logged at
+// error level. Mount it after RequestID and Trace so the line carries their
+// IDs.
 func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		rl := &requestLog{}
+		ctx := context.WithValue(r.Context(), requestLogKey, rl)
 		ww := &wrappedWriter{ResponseWriter: w, statusCode: http.StatusOK}
-		next.ServeHTTP(ww, r)
-		log.Printf("%s %s %d %s", r.Method, r.URL.Path, ww.statusCode, time.Since(start))
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		route := unmatchedRoute
+		if rctx := chi.RouteContext(ctx); rctx != nil {
+			if p := rctx.RoutePattern(); p != "" {
+				route = p
+			}
+		}
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("route", route),
+			slog.Int("status", ww.statusCode),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("ip", ClientIP(r)),
+		}
+		if rl.userID != "" {
+			attrs = append(attrs, slog.String("user_id", rl.userID))
+		}
+		if rl.impersonatorID != "" {
+			attrs = append(attrs, slog.String("impersonator_id", rl.impersonatorID))
+		}
+		level := slog.LevelInfo
+		if ww.statusCode >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		slog.LogAttrs(ctx, level, "request", attrs...)
 	})
 }
+
+// logUser records the authenticated user, and the admin impersonating them
+// if any, for the request log line.
+func logUser(ctx context.Context, userID, impersonatorID string) {
+	if rl, ok := ctx.Value(requestLogKey).(*requestLog); ok {
+		rl.userID = userID
+		rl.impersonatorID = impersonatorID
+	}
+}
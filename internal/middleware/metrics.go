@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// RequestObserver receives the outcome of every HTTP request.
+type RequestObserver interface {
+	ObserveRequest(method, route string, status int, elapsed time.Duration)
+}
+
+// unmatchedRoute labels requests that matched no route, so scanners probing
+// random paths cannot create a series per path.
+const unmatchedRoute = "unmatched"
+
+// Instrument returns middleware that reports each request to obs, keyed by
+// the chi route pattern like TrackUsage. Mount it on the root router so the
+// pattern is complete once the request has been served.
+func Instrument(obs RequestObserver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			ww := &wrappedWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(ww, r)
+
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if p := rctx.RoutePattern(); p != "" {
+					route = p
+				}
+			}
+			obs.ObserveRequest(r.Method, route, ww.statusCode, time.Since(start))
+		})
+	}
+}
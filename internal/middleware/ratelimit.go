@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/radif/service/internal/response"
+)
+
+// RateLimitSubject selects whose requests share a bucket.
+type RateLimitSubject int
+
+const (
+	// ByIP keys buckets by client IP; see ClientIP for how it is resolved.
+	ByIP RateLimitSubject = iota
+	// ByUser keys buckets by the authenticated user, falling back to the
+	// client IP when there is none. Mount it after RequireAuth.
+	ByUser
+)
+
+// RateLimitPolicy is a token bucket: it holds up to Burst tokens, refills at
+// Rate tokens per Per, and each request takes one.
+type RateLimitPolicy struct {
+	// Name scopes the buckets, so routes sharing a policy share a budget.
+	Name  string
+	Rate  int
+	Per   time.Duration
+	Burst int // defaults to Rate
+	By    RateLimitSubject
+}
+
+// perSecond returns the refill rate in tokens per second.
+func (p RateLimitPolicy) perSecond() float64 {
+	return float64(p.Rate) / p.Per.Seconds()
+}
+
+// RateLimitResult is the state of a bucket after taking a token.
+type RateLimitResult struct {
+	Allowed bool
+	// Tokens left in the bucket.
+	Remaining float64
+}
+
+// RateLimitStore keeps token buckets. Take removes one token from the bucket
+// at key, refilling it first for the time elapsed since the last request.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, p RateLimitPolicy) (RateLimitResult, error)
+}
+
+// RateLimit returns middleware that enforces p with buckets in store. Every
+// response carries X-RateLimit-Limit (the burst), X-RateLimit-Remaining and
+// X-RateLimit-Reset (seconds until the bucket is full again); rejected
+// requests get 429 with Retry-After. When the store fails, requests are let
+// through so an outage of the store does not take the API down with it.
+func RateLimit(store RateLimitStore, p RateLimitPolicy) func(http.Handler) http.Handler {
+	if p.Burst <= 0 {
+		p.Burst = p.Rate
+	}
+	rate := p.perSecond()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := store.Take(r.Context(), rateLimitKey(r, p), p)
+			if err != nil {
+				slog.ErrorContext(r.Context(), "rate limit: store failed, allowing request", "policy", p.Name, "err", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			h.Set("X-RateLimit-Limit", strconv.Itoa(p.Burst))
+			h.Set("X-RateLimit-Remaining", strconv.Itoa(int(res.Remaining)))
+			h.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds((float64(p.Burst)-res.Remaining)/rate)))
+			if !res.Allowed {
+				h.Set("Retry-After", strconv.Itoa(max(1, ceilSeconds((1-res.Remaining)/rate))))
+				response.Error(w, http.StatusTooManyRequests, "too many requests, try again later")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey returns the bucket key of the request under p.
+func rateLimitKey(r *http.Request, p RateLimitPolicy) string {
+	if p.By == ByUser {
+		if userID, _ := r.Context().Value(UserIDKey).(string); userID != "" {
+			return p.Name + ":u:" + userID
+		}
+	}
+	return p.Name + ":ip:" + ClientIP(r)
+}
+
+// ceilSeconds rounds a non-negative duration in seconds up to whole seconds.
+func ceilSeconds(s float64) int {
+	return int(math.Ceil(max(0, s)))
+}
+
+// bucket is one token bucket of a MemoryRateLimitStore.
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // when the bucket refills completely
+}
+
+// MemoryRateLimitStore keeps buckets in process memory. With several
+// replicas the effective limit is per replica, so it is meant for local
+// development and single-instance deployments.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	swept   time.Time
+}
+
+// memorySweepInterval is how often full buckets are dropped.
+const memorySweepInterval = time.Minute
+
+// NewMemoryRateLimitStore creates an empty MemoryRateLimitStore.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*bucket)}
+}
+
+// Take implements RateLimitStore.
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, p RateLimitPolicy) (RateLimitResult, error) {
+	now := time.Now()
+	rate := p.perSecond()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A full bucket is the same as no bucket, so dropping them keeps the map
+	// from growing with every client ever seen.
+	if now.Sub(s.swept) >= memorySweepInterval {
+		for k, b := range s.buckets {
+			if !now.Before(b.full) {
+				delete(s.buckets, k)
+			}
+		}
+		s.swept = now
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(p.Burst), last: now}
+		s.buckets[key] = b
+	}
+	b.tokens = min(float64(p.Burst), b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+
+	res := RateLimitResult{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	}
+	res.Remaining = b.tokens
+	b.full = now.Add(time.Duration((float64(p.Burst) - b.tokens) / rate * float64(time.Second)))
+	return res, nil
+}
@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIPKey is the context key for the resolved client IP address.
+const ClientIPKey contextKey = "clientIP"
+
+// ClientIPResolver determines the real client IP of a request. Forwarding headers
+// (X-Forwarded-For, X-Real-IP) are only honoured when the direct peer is a
+// trusted proxy, so clients cannot spoof their address by setting headers.
+type ClientIPResolver struct {
+	trusted []*net.IPNet
+}
+
+// NewClientIPResolver parses the trusted proxy list. Entries may be CIDRs
+// ("10.0.0.0/8") or single addresses ("127.0.0.1").
+func NewClientIPResolver(trustedProxies []string) (*ClientIPResolver, error) {
+	res := &ClientIPResolver{}
+	for _, entry := range trustedProxies {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			if ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		res.trusted = append(res.trusted, ipNet)
+	}
+	return res, nil
+}
+
+// Resolve returns the client IP for r.
+//
+// The peer address is used unless it is a trusted proxy. In that case
+// X-Forwarded-For is walked from right to left and the first address that is
+// not itself a trusted proxy is returned. Repeated X-Forwarded-For lines are
+// joined in order first, as a proxy may add its own line rather than extend
+// the client's. If a hop is malformed, or every hop is trusted, the last hop
+// that parsed is returned, or the peer when none did, since anything further
+// left may have been written by the client.
+// X-Real-IP is only consulted when the proxy sent no X-Forwarded-For.
+func (c *ClientIPResolver) Resolve(r *http.Request) string {
+	peer := remoteIP(r.RemoteAddr)
+	if !c.isTrusted(peer) {
+		return peer
+	}
+
+	if xff := strings.Join(r.Header.Values("X-Forwarded-For"), ","); xff != "" {
+		last := peer
+		hops := strings.Split(xff, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				// A malformed hop means the chain can't be trusted past this point.
+				break
+			}
+			if !c.isTrusted(ip.String()) {
+				return ip.String()
+			}
+			last = ip.String()
+		}
+		return last
+	}
+
+	if xrip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); xrip != nil {
+		return xrip.String()
+	}
+
+	return peer
+}
+
+// isTrusted reports whether ip falls inside one of the trusted proxy ranges.
+func (c *ClientIPResolver) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range c.trusted {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// RealIP returns middleware that resolves the client IP with the resolver,
+// stores it in the request context, and rewrites r.RemoteAddr to it.
+func RealIP(resolver *ClientIPResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := resolver.Resolve(r)
+			r.RemoteAddr = ip
+			ctx := context.WithValue(r.Context(), ClientIPKey, ip)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ClientIP returns the resolved client IP of the request. It falls back to the
+// peer address when the RealIP middleware has not run.
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(ClientIPKey).(string); ok && ip != "" {
+		return ip
+	}
+	return remoteIP(r.RemoteAddr)
+}
+
+// remoteIP strips the port from a "host:port" address.
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIPResolverResolve(t *testing.T) {
+	resolver, err := NewClientIPResolver([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("NewClientIPResolver: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		peer   string
+		xff    []string
+		realIP string
+		want   string
+	}{
+		{name: "untrusted peer ignores headers", peer: "198.51.100.9", xff: []string{"203.0.113.7"}, want: "198.51.100.9"},
+		{name: "single line", peer: "10.0.0.2", xff: []string{"203.0.113.7, 10.0.0.3"}, want: "203.0.113.7"},
+		{name: "spoofed hop left of client", peer: "10.0.0.2", xff: []string{"1.2.3.4, 203.0.113.7"}, want: "203.0.113.7"},
+		{name: "proxy adds its own line", peer: "10.0.0.2", xff: []string{"1.2.3.4", "203.0.113.7"}, want: "203.0.113.7"},
+		{name: "malformed hop stops the walk", peer: "10.0.0.2", xff: []string{"203.0.113.7, junk, 10.0.0.3"}, want: "10.0.0.3"},
+		{name: "x-real-ip without xff", peer: "10.0.0.2", realIP: "203.0.113.7", want: "203.0.113.7"},
+		{name: "no headers", peer: "10.0.0.2", want: "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.peer + ":4321"
+			for _, v := range tt.xff {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := resolver.Resolve(r); got != tt.want {
+				t.Fatalf("Resolve = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/go-chi/chi/v5"
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/radif/service/internal/response"
+)
+
+// Recover returns middleware that turns panics into 500 responses, unless
+// the response had already started, and reports them, along with every other
+// 5xx response, to the error reporter set up with sentry.Init. Events carry
+// the request, route, request and trace IDs and the authenticated user;
+// without a client they are dropped. Mount it after Logger so the user ID is
+// known.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hub := sentry.CurrentHub().Clone()
+		hub.Scope().SetRequest(r)
+		ctx := sentry.SetHubOnContext(r.Context(), hub)
+		ww := &wrappedWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		defer func() {
+			v := recover()
+			if v == http.ErrAbortHandler {
+				// Deliberate abort of the response; let net/http handle it.
+				panic(v)
+			}
+			if v == nil && ww.statusCode < http.StatusInternalServerError {
+				return
+			}
+
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(ctx); rctx != nil {
+				if p := rctx.RoutePattern(); p != "" {
+					route = p
+				}
+			}
+			hub.ConfigureScope(func(s *sentry.Scope) {
+				s.SetTag("route", route)
+				if id := chiMiddleware.GetReqID(ctx); id != "" {
+					s.SetTag("request_id", id)
+				}
+				if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+					s.SetTag("trace_id", sc.TraceID().String())
+				}
+				if rl, ok := ctx.Value(requestLogKey).(*requestLog); ok && rl.userID != "" {
+					s.SetUser(sentry.User{ID: rl.userID})
+				}
+			})
+
+			if v != nil {
+				slog.ErrorContext(ctx, "panic", "panic", v, "stack", string(debug.Stack()))
+				hub.RecoverWithContext(ctx, v)
+				// A handler that panicked mid-response has already sent its
+				// status; a 500 envelope would only corrupt the body.
+				if !ww.started {
+					response.InternalError(ww)
+				}
+				return
+			}
+			hub.CaptureMessage(fmt.Sprintf("%d %s %s", ww.statusCode, r.Method, route))
+		}()
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	chiMiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// clientRequestID matches the X-Request-ID values accepted from clients.
+// Anything else is replaced, as the ID ends up in logs and audit entries.
+var clientRequestID = regexp.MustCompile(`^[A-Za-z0-9-]{1,64}$`)
+
+// requestIDWriter carries the request ID to the response helpers.
+type requestIDWriter struct {
+	http.ResponseWriter
+	id string
+}
+
+// RequestID implements the interface response.Error looks for.
+func (rw *requestIDWriter) RequestID() string {
+	return rw.id
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rw *requestIDWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// RequestID gives each request an ID, keeping one sent by the client in
+// X-Request-ID if it is at most 64 letters, digits and hyphens; otherwise a
+// random one is generated. The ID is stored where chi's GetReqID finds it,
+// and echoed in the X-Request-ID header and the requestId field of error
+// responses, so a user reporting a failure can quote it and support can find
+// the request's logs.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(chiMiddleware.RequestIDHeader)
+		if !clientRequestID.MatchString(id) {
+			id = newRequestID()
+			r.Header.Set(chiMiddleware.RequestIDHeader, id)
+		}
+		ctx := context.WithValue(r.Context(), chiMiddleware.RequestIDKey, id)
+		w.Header().Set(chiMiddleware.RequestIDHeader, id)
+		next.ServeHTTP(&requestIDWriter{ResponseWriter: w, id: id}, r.WithContext(ctx))
+	})
+}
+
+// newRequestID returns a random request ID.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/radif/service/internal/response"
+)
+
+// UserScopesKey is the context key for the token's granted scopes (map[string]bool).
+const UserScopesKey contextKey = "userScopes"
+
+// ScopeAll grants every scope. Session tokens from OTP login carry it; tokens
+// issued before scopes existed have no scope claim and are treated the same.
+const ScopeAll = "*"
+
+// OAuth-style scopes that limited-capability tokens (checkout widget,
+// delegations, trusted devices) can be restricted to.
+const (
+	ScopeProfileRead       = "profile:read"
+	ScopeProfileWrite      = "profile:write"
+	ScopeBankAccountsRead  = "bank_accounts:read"
+	ScopeBankAccountsWrite = "bank_accounts:write"
+	ScopeContacts          = "contacts"
+	ScopeNotifications     = "notifications"
+	ScopeWebhooks          = "webhooks"
+	ScopeGroups            = "groups"
+	ScopeMessages          = "messages"
+)
+
+// KnownScopes lists the scopes that may be requested for a limited token.
+var KnownScopes = map[string]bool{
+	ScopeProfileRead:       true,
+	ScopeProfileWrite:      true,
+	ScopeBankAccountsRead:  true,
+	ScopeBankAccountsWrite: true,
+	ScopeContacts:          true,
+	ScopeNotifications:     true,
+	ScopeWebhooks:          true,
+	ScopeGroups:            true,
+	ScopeMessages:          true,
+}
+
+// ScopeMaintenanceWrite lets a PSP status monitor announce and cancel
+// maintenance windows. Only API keys carry it.
+const ScopeMaintenanceWrite = "maintenance:write"
+
+// APIKeyScopes lists the scopes an API key may be issued with: those of the
+// server-to-server routes behind RequireAPIKey.
+var APIKeyScopes = map[string]bool{
+	ScopeMaintenanceWrite: true,
+}
+
+// parseScopes splits a space-delimited OAuth scope claim. A missing claim
+// means full access.
+func parseScopes(claim string, present bool) map[string]bool {
+	if !present {
+		return map[string]bool{ScopeAll: true}
+	}
+	scopes := make(map[string]bool)
+	for _, s := range strings.Fields(claim) {
+		scopes[s] = true
+	}
+	return scopes
+}
+
+// HasScope reports whether the authenticated token grants scope.
+func HasScope(r *http.Request, scope string) bool {
+	scopes, _ := r.Context().Value(UserScopesKey).(map[string]bool)
+	return scopes[ScopeAll] || scopes[scope]
+}
+
+// RequireScope returns middleware that rejects tokens lacking scope. Pass
+// ScopeAll to admit only full-access session tokens. It must be mounted after
+// RequireAuth.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !HasScope(r, scope) {
+				response.Localized(w, http.StatusForbidden, "missing_scope", scope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/radif/service/internal/response"
+)
+
+// Timeout gives each request d to finish by putting a deadline on its
+// context, which cancels the database and storage calls made with it. A
+// request that runs out of time answers 504 instead of the 500 its handler
+// writes for the cancelled call. Paths in exempt, such as long-lived event
+// streams, get no deadline. A d of zero disables the middleware.
+//
+// Keep d below the server's WriteTimeout, or the connection is closed
+// before the 504 can be written.
+func Timeout(d time.Duration, exempt ...string) func(http.Handler) http.Handler {
+	skip := make(map[string]bool, len(exempt))
+	for _, p := range exempt {
+		skip[p] = true
+	}
+	return func(next http.Handler) http.Handler {
+		if d <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+
+			tw := &timeoutWriter{ResponseWriter: w, ctx: ctx}
+			next.ServeHTTP(tw, r.WithContext(ctx))
+			if !tw.wroteHeader && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				tw.WriteHeader(http.StatusGatewayTimeout)
+			}
+		})
+	}
+}
+
+// timeoutWriter replaces a server error written after the deadline with
+// the timeout response.
+type timeoutWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	if status >= http.StatusInternalServerError && errors.Is(tw.ctx.Err(), context.DeadlineExceeded) {
+		tw.timedOut = true
+		response.Error(tw.ResponseWriter, http.StatusGatewayTimeout, "request timed out")
+		return
+	}
+	tw.ResponseWriter.WriteHeader(status)
+}
+
+// Write drops the handler's body once the timeout response was sent.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.timedOut {
+		return len(b), nil
+	}
+	return tw.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
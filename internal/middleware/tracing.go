@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Trace returns middleware that runs each request in a server span, joining
+// the caller's trace when a traceparent header is sent. The span is named
+// after the chi route pattern once the request has been served, so mount it
+// on the root router. The trace ID is echoed in the Trace-ID header.
+func Trace(tracer trace.Tracer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(r.Method),
+					semconv.URLPath(r.URL.Path),
+					semconv.ClientAddress(ClientIP(r)),
+				),
+			)
+			defer span.End()
+
+			if sc := span.SpanContext(); sc.HasTraceID() {
+				w.Header().Set("Trace-ID", sc.TraceID().String())
+			}
+			ww := &wrappedWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(ww, r.WithContext(ctx))
+
+			route := unmatchedRoute
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if p := rctx.RoutePattern(); p != "" {
+					route = p
+				}
+			}
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(ww.statusCode))
+			if ww.statusCode >= 500 {
+				span.SetStatus(codes.Error, http.StatusText(ww.statusCode))
+			}
+		})
+	}
+}
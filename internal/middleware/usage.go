@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// UsageRecorder accumulates per-user API usage. Record is called on the
+// request path and must not block.
+type UsageRecorder interface {
+	Record(userID, method, route string, status int, ip string)
+}
+
+// TrackUsage returns middleware that reports each authenticated request to
+// rec, keyed by the chi route pattern (e.g. "/api/v1/users/me") rather than
+// the raw path so IDs don't fragment the aggregates. It must be mounted
+// after RequireAuth.
+func TrackUsage(rec UsageRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ww := &wrappedWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			next.ServeHTTP(ww, r)
+
+			userID, _ := r.Context().Value(UserIDKey).(string)
+			if userID == "" {
+				return
+			}
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				if p := rctx.RoutePattern(); p != "" {
+					route = p
+				}
+			}
+			rec.Record(userID, r.Method, route, ww.statusCode, ClientIP(r))
+		})
+	}
+}
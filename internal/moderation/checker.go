@@ -0,0 +1,126 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/bits"
+	"net/http"
+	"time"
+)
+
+// Image is an uploaded image under check.
+type Image struct {
+	Data        []byte
+	ContentType string
+	// Hash is the image's imaging.DHash.
+	Hash uint64
+}
+
+// Checker inspects an image. It returns a non-nil reason when the image
+// must be quarantined, and an error when it could not decide.
+type Checker interface {
+	Check(ctx context.Context, img *Image) (reason *string, err error)
+}
+
+// DefaultBlocklistDistance is how many of the 64 hash bits may differ for an
+// image to match a blocklist entry. It tolerates re-encoding and resizing.
+const DefaultBlocklistDistance = 6
+
+// BlocklistChecker flags images whose perceptual hash is close to a blocked one.
+type BlocklistChecker struct {
+	repo     *Repository
+	distance int
+}
+
+// NewBlocklistChecker creates a BlocklistChecker. A distance of zero or less
+// uses DefaultBlocklistDistance.
+func NewBlocklistChecker(repo *Repository, distance int) *BlocklistChecker {
+	if distance <= 0 {
+		distance = DefaultBlocklistDistance
+	}
+	return &BlocklistChecker{repo: repo, distance: distance}
+}
+
+// Check implements Checker.
+func (c *BlocklistChecker) Check(ctx context.Context, img *Image) (*string, error) {
+	blocked, err := c.repo.Blocklist(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range blocked {
+		if bits.OnesCount64(img.Hash^b.Hash) <= c.distance {
+			reason := "blocklist: " + b.Reason
+			return &reason, nil
+		}
+	}
+	return nil, nil
+}
+
+const (
+	classifierTimeout    = 15 * time.Second
+	maxClassifierReply   = 64 << 10
+	defaultNSFWThreshold = 0.8
+)
+
+// HTTPClassifier sends images to an external NSFW classifier. The service
+// receives the raw image as the request body and replies with
+// {"score": <0..1>}; scores at or above the threshold are flagged.
+type HTTPClassifier struct {
+	url       string
+	token     string
+	threshold float64
+	client    *http.Client
+}
+
+// NewHTTPClassifier creates an HTTPClassifier. token is sent as a bearer
+// token when set; a threshold outside (0, 1] uses 0.8.
+func NewHTTPClassifier(url, token string, threshold float64) *HTTPClassifier {
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultNSFWThreshold
+	}
+	return &HTTPClassifier{
+		url:       url,
+		token:     token,
+		threshold: threshold,
+		client:    &http.Client{Timeout: classifierTimeout},
+	}
+}
+
+// Check implements Checker.
+func (c *HTTPClassifier) Check(ctx context.Context, img *Image) (*string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(img.Data))
+	if err != nil {
+		return nil, fmt.Errorf("build classifier request: %w", err)
+	}
+	req.Header.Set("Content-Type", img.ContentType)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("call classifier: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("classifier returned %d", resp.StatusCode)
+	}
+
+	var out struct {
+		Score *float64 `json:"score"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxClassifierReply)).Decode(&out); err != nil {
+		return nil, fmt.Errorf("decode classifier reply: %w", err)
+	}
+	if out.Score == nil {
+		return nil, fmt.Errorf("classifier reply has no score")
+	}
+	if *out.Score >= c.threshold {
+		reason := fmt.Sprintf("classifier: nsfw score %.2f", *out.Score)
+		return &reason, nil
+	}
+	return nil, nil
+}
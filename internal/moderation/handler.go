@@ -0,0 +1,196 @@
+package moderation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler serves the staff review queue.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new moderation Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// reviewItem is an item with a short-lived link to its quarantined image.
+type reviewItem struct {
+	*Item
+	ImageURL *string `json:"imageUrl,omitempty"`
+}
+
+// validStatuses are the statuses the queue can be filtered by.
+var validStatuses = map[string]bool{
+	StatusPending: true, StatusClear: true, StatusFlagged: true, StatusApproved: true, StatusRejected: true,
+}
+
+// Queue godoc
+//
+//	@Summary		List moderation items
+//	@Description	Uploaded avatars by check status, oldest first. Flagged items await review and carry a link to the quarantined image, valid for 15 minutes. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			status	query		string	false	"Status (default flagged)"	Enums(pending, clear, flagged, approved, rejected)
+//	@Param			limit	query		int		false	"Page size (1-100, default 50)"
+//	@Param			offset	query		int		false	"Offset"
+//	@Success		200		{object}	response.Envelope{data=[]reviewItem}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/moderation [get]
+func (h *Handler) Queue(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := q.Get("status")
+	if status == "" {
+		status = StatusFlagged
+	}
+	if !validStatuses[status] {
+		response.BadRequest(w, "status must be one of: pending, clear, flagged, approved, rejected")
+		return
+	}
+	limit, offset := 50, 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			response.BadRequest(w, "offset must be a non-negative integer")
+			return
+		}
+		offset = n
+	}
+
+	list, err := h.svc.List(r.Context(), status, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	out := make([]reviewItem, 0, len(list))
+	for _, it := range list {
+		item := reviewItem{Item: it}
+		if it.Status == StatusFlagged {
+			if u, err := h.svc.ImageURL(r.Context(), it); err != nil {
+				slog.ErrorContext(r.Context(), "moderation: sign image url failed", "item_id", it.ID, "err", err)
+			} else {
+				item.ImageURL = &u
+			}
+		}
+		out = append(out, item)
+	}
+	response.OK(w, out)
+}
+
+// Approve godoc
+//
+//	@Summary		Approve flagged image
+//	@Description	Publish a flagged image again and make it the avatar once more, unless the user or group has set another avatar since. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Moderation item ID"
+//	@Success		200	{object}	response.Envelope{data=Item}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		409	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/admin/moderation/{id}/approve [post]
+func (h *Handler) Approve(w http.ResponseWriter, r *http.Request) {
+	reviewerID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || reviewerID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	it, err := h.svc.Approve(r.Context(), chi.URLParam(r, "id"), reviewerID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, it)
+}
+
+type rejectRequest struct {
+	// Block adds the image's hash to the blocklist so copies are flagged on upload.
+	Block  bool   `json:"block"`
+	Reason string `json:"reason" example:"scam logo"`
+}
+
+// Reject godoc
+//
+//	@Summary		Reject flagged image
+//	@Description	Delete a flagged image for good; the restored previous avatar stays. With block set, the image's perceptual hash is added to the blocklist with the reason, which is then required. The body is optional. Admin only.
+//	@Tags			admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string			true	"Moderation item ID"
+//	@Param			request	body		rejectRequest	false	"Blocklist options"
+//	@Success		200		{object}	response.Envelope{data=Item}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/moderation/{id}/reject [post]
+func (h *Handler) Reject(w http.ResponseWriter, r *http.Request) {
+	reviewerID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || reviewerID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req rejectRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Block && (req.Reason == "" || len([]rune(req.Reason)) > 255) {
+		response.BadRequest(w, "reason is required with block and must be 255 characters or fewer")
+		return
+	}
+
+	it, err := h.svc.Reject(r.Context(), chi.URLParam(r, "id"), reviewerID, req.Block, req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, it)
+}
+
+// writeError maps moderation service errors to responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "moderation item not found")
+	case errors.Is(err, ErrNotFlagged):
+		response.Conflict(w, "moderation item is not awaiting review")
+	default:
+		response.InternalError(w)
+	}
+}
@@ -0,0 +1,346 @@
+// Package moderation checks uploaded avatars after they are stored. Flagged
+// images are moved to a quarantine bucket, the avatar they replaced is
+// restored, and staff approve or reject them from a review queue.
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Subject types.
+const (
+	SubjectUserAvatar  = "user_avatar"
+	SubjectGroupAvatar = "group_avatar"
+)
+
+// Item statuses. Items start pending; the worker marks them clear or
+// flagged, and staff move flagged items to approved or rejected.
+const (
+	StatusPending  = "pending"
+	StatusClear    = "clear"
+	StatusFlagged  = "flagged"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+// Item is one moderation check of an uploaded image.
+type Item struct {
+	ID               string     `json:"id"`
+	SubjectType      string     `json:"subjectType" example:"user_avatar"`
+	SubjectID        string     `json:"subjectId"`
+	ObjectKey        string     `json:"-"`
+	Variants         bool       `json:"-"`
+	PreviousKey      *string    `json:"-"`
+	PreviousVariants bool       `json:"-"`
+	Status           string     `json:"status"           example:"flagged"`
+	Reason           *string    `json:"reason,omitempty" example:"blocklist: scam logo"`
+	Attempts         int        `json:"-"`
+	CheckedAt        *time.Time `json:"checkedAt,omitempty"`
+	ReviewedBy       *string    `json:"reviewedBy,omitempty"`
+	ReviewedAt       *time.Time `json:"reviewedAt,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+// BlockedHash is a blocklist entry.
+type BlockedHash struct {
+	Hash   uint64
+	Reason string
+}
+
+// ErrNotFound is returned when a moderation item does not exist.
+var ErrNotFound = errors.New("moderation item not found")
+
+// ErrNotFlagged is returned when reviewing an item that is not awaiting review.
+var ErrNotFlagged = errors.New("moderation item is not awaiting review")
+
+// Repository handles moderation persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new moderation Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const itemCols = `id, subject_type, subject_id, object_key, variants, previous_key, previous_variants,
+	status, reason, attempts, checked_at, reviewed_by, reviewed_at, created_at`
+
+// scanItem scans an itemCols row into an Item value.
+func scanItem(row pgx.Row, it *Item) error {
+	return row.Scan(
+		&it.ID, &it.SubjectType, &it.SubjectID, &it.ObjectKey, &it.Variants, &it.PreviousKey, &it.PreviousVariants,
+		&it.Status, &it.Reason, &it.Attempts, &it.CheckedAt, &it.ReviewedBy, &it.ReviewedAt, &it.CreatedAt,
+	)
+}
+
+func collectItems(rows pgx.Rows) ([]*Item, error) {
+	defer rows.Close()
+	var out []*Item
+	for rows.Next() {
+		it := &Item{}
+		if err := scanItem(rows, it); err != nil {
+			return nil, fmt.Errorf("scan moderation item: %w", err)
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// SubmitUserAvatar queues key for checking, recording the user's current
+// avatar as the one to restore. Call it before the user row is updated.
+func (r *Repository) SubmitUserAvatar(ctx context.Context, userID, key string, variants bool) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO moderation_items (subject_type, subject_id, object_key, variants, previous_key, previous_variants)
+		 SELECT $1, id, $3, $4, avatar_key, avatar_variants FROM users WHERE id = $2 AND deleted_at IS NULL`,
+		SubjectUserAvatar, userID, key, variants,
+	)
+	if err != nil {
+		return fmt.Errorf("submit user avatar: %w", err)
+	}
+	return nil
+}
+
+// SubmitGroupAvatar queues key for checking, recording the group's current
+// avatar as the one to restore. Call it before the group row is updated.
+func (r *Repository) SubmitGroupAvatar(ctx context.Context, groupID, key string) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO moderation_items (subject_type, subject_id, object_key, previous_key)
+		 SELECT $1, id, $3, avatar_key FROM groups WHERE id = $2`,
+		SubjectGroupAvatar, groupID, key,
+	)
+	if err != nil {
+		return fmt.Errorf("submit group avatar: %w", err)
+	}
+	return nil
+}
+
+// ClaimDue leases up to limit pending items by pushing their next_check_at
+// forward by lease, as webhook deliveries are claimed.
+func (r *Repository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Item, error) {
+	rows, err := r.db.Query(ctx,
+		`UPDATE moderation_items SET next_check_at = NOW() + $2::interval
+		 WHERE id IN (
+		     SELECT id FROM moderation_items
+		     WHERE status = 'pending' AND next_check_at <= NOW()
+		     ORDER BY next_check_at
+		     LIMIT $1
+		     FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING `+itemCols,
+		limit, lease,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim moderation items: %w", err)
+	}
+	return collectItems(rows)
+}
+
+// MarkClear records that the item passed its checks. reason notes why a
+// check was skipped, if one was.
+func (r *Repository) MarkClear(ctx context.Context, id string, reason *string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE moderation_items SET status = 'clear', reason = $2, checked_at = NOW()
+		 WHERE id = $1 AND status = 'pending'`,
+		id, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("mark moderation item clear: %w", err)
+	}
+	return nil
+}
+
+// Retry schedules another check after a checker failed.
+func (r *Repository) Retry(ctx context.Context, id string, next time.Time, lastErr string) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE moderation_items SET attempts = attempts + 1, next_check_at = $2, reason = $3
+		 WHERE id = $1 AND status = 'pending'`,
+		id, next, lastErr,
+	)
+	if err != nil {
+		return fmt.Errorf("retry moderation item: %w", err)
+	}
+	return nil
+}
+
+// Flag marks the item flagged and, if the subject still shows the flagged
+// image, puts its previous avatar back. It reports whether the avatar was
+// restored.
+func (r *Repository) Flag(ctx context.Context, it *Item, reason string) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE moderation_items SET status = 'flagged', reason = $2, checked_at = NOW() WHERE id = $1`,
+		it.ID, reason,
+	); err != nil {
+		return false, fmt.Errorf("flag moderation item: %w", err)
+	}
+	tag, err := setAvatar(ctx, tx, it.SubjectType, it.SubjectID, it.PreviousKey, it.PreviousVariants, &it.ObjectKey)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit flag: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Approve marks a flagged item approved and, if the subject still shows the
+// avatar that was restored, makes the approved image its avatar again. It
+// reports whether the avatar was reinstated.
+func (r *Repository) Approve(ctx context.Context, it *Item, reviewerID string) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := review(ctx, tx, it.ID, StatusApproved, reviewerID); err != nil {
+		return false, err
+	}
+	tag, err := setAvatar(ctx, tx, it.SubjectType, it.SubjectID, &it.ObjectKey, it.Variants, it.PreviousKey)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("commit approval: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Reject marks a flagged item rejected, adding block to the blocklist when
+// it is not nil.
+func (r *Repository) Reject(ctx context.Context, id, reviewerID string, block *BlockedHash) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err := review(ctx, tx, id, StatusRejected, reviewerID); err != nil {
+		return err
+	}
+	if block != nil {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO image_blocklist (hash, reason, added_by) VALUES ($1, $2, $3)
+			 ON CONFLICT (hash) DO NOTHING`,
+			int64(block.Hash), block.Reason, reviewerID,
+		); err != nil {
+			return fmt.Errorf("add to blocklist: %w", err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit rejection: %w", err)
+	}
+	return nil
+}
+
+// review moves a flagged item to status.
+func review(ctx context.Context, tx pgx.Tx, id, status, reviewerID string) error {
+	tag, err := tx.Exec(ctx,
+		`UPDATE moderation_items SET status = $2, reviewed_by = $3, reviewed_at = NOW()
+		 WHERE id = $1 AND status = 'flagged'`,
+		id, status, reviewerID,
+	)
+	if err != nil {
+		return fmt.Errorf("review moderation item: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFlagged
+	}
+	return nil
+}
+
+// setAvatar sets the subject's avatar to key if it currently is expected.
+func setAvatar(ctx context.Context, tx pgx.Tx, subjectType, subjectID string, key *string, variants bool, expected *string) (pgconn.CommandTag, error) {
+	var (
+		tag pgconn.CommandTag
+		err error
+	)
+	switch subjectType {
+	case SubjectUserAvatar:
+		tag, err = tx.Exec(ctx,
+			`UPDATE users SET avatar_key = $2, avatar_variants = $3
+			 WHERE id = $1 AND avatar_key IS NOT DISTINCT FROM $4`,
+			subjectID, key, variants, expected,
+		)
+	case SubjectGroupAvatar:
+		tag, err = tx.Exec(ctx,
+			`UPDATE groups SET avatar_key = $2 WHERE id = $1 AND avatar_key IS NOT DISTINCT FROM $3`,
+			subjectID, key, expected,
+		)
+	default:
+		return tag, fmt.Errorf("unknown moderation subject %q", subjectType)
+	}
+	if err != nil {
+		return tag, fmt.Errorf("set %s: %w", subjectType, err)
+	}
+	return tag, nil
+}
+
+// Get fetches a moderation item.
+func (r *Repository) Get(ctx context.Context, id string) (*Item, error) {
+	it := &Item{}
+	err := scanItem(r.db.QueryRow(ctx, `SELECT `+itemCols+` FROM moderation_items WHERE id = $1`, id), it)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get moderation item: %w", err)
+	}
+	return it, nil
+}
+
+// List returns items with the given status, oldest first.
+func (r *Repository) List(ctx context.Context, status string, limit, offset int) ([]*Item, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+itemCols+` FROM moderation_items
+		 WHERE status = $1
+		 ORDER BY created_at, id
+		 LIMIT $2 OFFSET $3`,
+		status, limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list moderation items: %w", err)
+	}
+	return collectItems(rows)
+}
+
+// Blocklist returns every blocked hash.
+func (r *Repository) Blocklist(ctx context.Context) ([]BlockedHash, error) {
+	rows, err := r.db.Query(ctx, `SELECT hash, reason FROM image_blocklist`)
+	if err != nil {
+		return nil, fmt.Errorf("list blocklist: %w", err)
+	}
+	defer rows.Close()
+
+	var out []BlockedHash
+	for rows.Next() {
+		var (
+			h      int64
+			reason string
+		)
+		if err := rows.Scan(&h, &reason); err != nil {
+			return nil, fmt.Errorf("scan blocklist: %w", err)
+		}
+		out = append(out, BlockedHash{Hash: uint64(h), Reason: reason})
+	}
+	return out, rows.Err()
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid text representation error.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
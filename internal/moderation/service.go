@@ -0,0 +1,224 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/radif/service/internal/imaging"
+	"github.com/radif/service/internal/storage"
+)
+
+// maxObjectBytes bounds how much of a stored image is read for checking.
+// Uploads are limited to 5 MB before they are stored.
+const maxObjectBytes = 8 << 20
+
+// CacheInvalidator drops objects from the CDN cache. It is satisfied by
+// cdn.Invalidator and is optional.
+type CacheInvalidator interface {
+	Invalidate(keys ...string)
+}
+
+// Service checks submitted images and handles staff review.
+type Service struct {
+	repo       *Repository
+	public     storage.Storage
+	quarantine storage.Storage
+	cache      CacheInvalidator
+	checkers   []Checker
+}
+
+// NewService creates a new moderation Service. Images are read from public
+// and flagged ones are moved to quarantine, which should be a private bucket,
+// and purged from the CDN through cache when it is not nil.
+func NewService(repo *Repository, public, quarantine storage.Storage, cache CacheInvalidator, checkers ...Checker) *Service {
+	return &Service{repo: repo, public: public, quarantine: quarantine, cache: cache, checkers: checkers}
+}
+
+// SubmitUserAvatar queues a user's new avatar for checking. Call it before
+// the avatar is saved so the one it replaces can be restored.
+func (s *Service) SubmitUserAvatar(ctx context.Context, userID, key string, variants bool) error {
+	return s.repo.SubmitUserAvatar(ctx, userID, key, variants)
+}
+
+// SubmitGroupAvatar queues a group's new avatar for checking. Call it before
+// the avatar is saved so the one it replaces can be restored.
+func (s *Service) SubmitGroupAvatar(ctx context.Context, groupID, key string) error {
+	return s.repo.SubmitGroupAvatar(ctx, groupID, key)
+}
+
+// check runs every checker over the item's image, returning the first flag reason.
+func (s *Service) check(ctx context.Context, it *Item) (*string, error) {
+	data, err := readObject(ctx, s.public, it.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	// Stored avatars are already sanitized; this only decodes them.
+	img, err := imaging.Sanitize(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", it.ObjectKey, err)
+	}
+	in := &Image{Data: data, ContentType: http.DetectContentType(data), Hash: imaging.DHash(img.Image)}
+
+	for _, c := range s.checkers {
+		reason, err := c.Check(ctx, in)
+		if err != nil || reason != nil {
+			return reason, err
+		}
+	}
+	return nil, nil
+}
+
+// quarantineItem moves the flagged image out of the public bucket and
+// restores the subject's previous avatar.
+func (s *Service) quarantineItem(ctx context.Context, it *Item, reason string) error {
+	data, err := readObject(ctx, s.public, it.ObjectKey)
+	if err != nil {
+		return err
+	}
+	if err := s.quarantine.Upload(ctx, it.ObjectKey, bytes.NewReader(data), int64(len(data)), http.DetectContentType(data)); err != nil {
+		return fmt.Errorf("quarantine %s: %w", it.ObjectKey, err)
+	}
+
+	restored, err := s.repo.Flag(ctx, it, reason)
+	if err != nil {
+		return err
+	}
+	if !restored {
+		slog.InfoContext(ctx, "moderation: avatar changed since upload; left as is", "subject_type", it.SubjectType, "subject_id", it.SubjectID)
+	}
+
+	// The row no longer points at the public copy; anything left behind is
+	// collected as an orphan.
+	keys := []string{it.ObjectKey}
+	if it.Variants {
+		keys = append(keys, imaging.VariantKeys(it.ObjectKey)...)
+	}
+	if s.cache != nil {
+		s.cache.Invalidate(keys...)
+	}
+	for _, k := range keys {
+		if err := s.public.Delete(ctx, k); err != nil {
+			slog.WarnContext(ctx, "moderation: delete public object failed", "key", k, "err", err)
+		}
+	}
+	return nil
+}
+
+// List returns items with the given status, oldest first.
+func (s *Service) List(ctx context.Context, status string, limit, offset int) ([]*Item, error) {
+	return s.repo.List(ctx, status, limit, offset)
+}
+
+// ImageURL returns a short-lived URL of a flagged item's quarantined image.
+func (s *Service) ImageURL(ctx context.Context, it *Item) (string, error) {
+	return s.quarantine.SignedURL(ctx, it.ObjectKey, imageURLTTL)
+}
+
+// Approve publishes a flagged image again and reinstates it as the
+// subject's avatar unless the avatar has changed since.
+func (s *Service) Approve(ctx context.Context, id, reviewerID string) (*Item, error) {
+	it, err := s.flagged(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := readObject(ctx, s.quarantine, it.ObjectKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.public.Upload(ctx, it.ObjectKey, bytes.NewReader(data), int64(len(data)), http.DetectContentType(data)); err != nil {
+		return nil, fmt.Errorf("publish %s: %w", it.ObjectKey, err)
+	}
+	it.Variants = it.Variants && s.publishVariants(ctx, it.ObjectKey, data)
+
+	if _, err := s.repo.Approve(ctx, it, reviewerID); err != nil {
+		return nil, err
+	}
+	if err := s.quarantine.Delete(ctx, it.ObjectKey); err != nil {
+		slog.WarnContext(ctx, "moderation: delete quarantined object failed", "key", it.ObjectKey, "err", err)
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// Reject discards a flagged image. When block is true its hash is added to
+// the blocklist with reason so copies are flagged on upload.
+func (s *Service) Reject(ctx context.Context, id, reviewerID string, block bool, reason string) (*Item, error) {
+	it, err := s.flagged(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry *BlockedHash
+	if block {
+		data, err := readObject(ctx, s.quarantine, it.ObjectKey)
+		if err != nil {
+			return nil, err
+		}
+		img, err := imaging.Sanitize(data)
+		if err != nil {
+			return nil, fmt.Errorf("decode %s: %w", it.ObjectKey, err)
+		}
+		entry = &BlockedHash{Hash: imaging.DHash(img.Image), Reason: reason}
+	}
+
+	if err := s.repo.Reject(ctx, id, reviewerID, entry); err != nil {
+		return nil, err
+	}
+	if err := s.quarantine.Delete(ctx, it.ObjectKey); err != nil {
+		slog.WarnContext(ctx, "moderation: delete quarantined object failed", "key", it.ObjectKey, "err", err)
+	}
+	return s.repo.Get(ctx, id)
+}
+
+// flagged fetches an item, failing unless it awaits review.
+func (s *Service) flagged(ctx context.Context, id string) (*Item, error) {
+	it, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if it.Status != StatusFlagged {
+		return nil, ErrNotFlagged
+	}
+	return it, nil
+}
+
+// publishVariants renders and uploads the avatar size variants of data,
+// reporting whether all were stored.
+func (s *Service) publishVariants(ctx context.Context, key string, data []byte) bool {
+	img, err := imaging.Sanitize(data)
+	if err != nil {
+		slog.ErrorContext(ctx, "moderation: decode failed", "key", key, "err", err)
+		return false
+	}
+	variants, err := imaging.SquareVariants(img.Image, imaging.AvatarSizes)
+	if err != nil {
+		slog.ErrorContext(ctx, "moderation: render variants failed", "key", key, "err", err)
+		return false
+	}
+	for _, v := range variants {
+		vk := imaging.VariantKey(key, v.Size)
+		if err := s.public.Upload(ctx, vk, bytes.NewReader(v.Data), int64(len(v.Data)), imaging.ContentType); err != nil {
+			slog.ErrorContext(ctx, "moderation: upload variant failed", "key", vk, "err", err)
+			return false
+		}
+	}
+	return true
+}
+
+// readObject reads a whole stored object.
+func readObject(ctx context.Context, store storage.Storage, key string) ([]byte, error) {
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("get %s: %w", key, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxObjectBytes))
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", key, err)
+	}
+	return data, nil
+}
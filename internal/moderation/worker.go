@@ -0,0 +1,107 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/radif/service/internal/storage"
+)
+
+const (
+	pollInterval     = 5 * time.Second
+	claimBatchSize   = 20
+	claimLease       = 2 * time.Minute
+	maxAttempts      = 6
+	baseRetryBackoff = 30 * time.Second
+	maxRetryBackoff  = 30 * time.Minute
+	imageURLTTL      = 15 * time.Minute
+)
+
+// Worker checks submitted images in the background. A check that keeps
+// failing is retried with backoff and, after maxAttempts, the image is let
+// through with the error recorded: an unreachable classifier must not hide
+// every new avatar.
+type Worker struct {
+	svc *Service
+}
+
+// NewWorker creates a new moderation Worker.
+func NewWorker(svc *Service) *Worker {
+	return &Worker{svc: svc}
+}
+
+// Run polls for pending items until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	slog.Info("moderation worker started")
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.drain(ctx)
+		select {
+		case <-ctx.Done():
+			slog.Info("moderation worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain processes batches until no due items remain.
+func (w *Worker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		items, err := w.svc.repo.ClaimDue(ctx, claimBatchSize, claimLease)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "moderation worker: claim failed", "err", err)
+			}
+			return
+		}
+		for _, it := range items {
+			w.process(ctx, it)
+		}
+		if len(items) < claimBatchSize {
+			return
+		}
+	}
+}
+
+// process checks one item and records the outcome.
+func (w *Worker) process(ctx context.Context, it *Item) {
+	reason, err := w.svc.check(ctx, it)
+	switch {
+	case errors.Is(err, storage.ErrObjectNotFound):
+		gone := "skipped: object no longer exists"
+		err = w.svc.repo.MarkClear(ctx, it.ID, &gone)
+	case err != nil:
+		slog.WarnContext(ctx, "moderation worker: check failed", "item_id", it.ID, "err", err)
+		if it.Attempts+1 >= maxAttempts {
+			unchecked := "unchecked: " + err.Error()
+			err = w.svc.repo.MarkClear(ctx, it.ID, &unchecked)
+		} else {
+			err = w.svc.repo.Retry(ctx, it.ID, time.Now().Add(retryBackoff(it.Attempts+1)), err.Error())
+		}
+	case reason != nil:
+		slog.InfoContext(ctx, "moderation worker: flagged", "subject_type", it.SubjectType, "subject_id", it.SubjectID, "reason", *reason)
+		err = w.svc.quarantineItem(ctx, it, *reason)
+	default:
+		err = w.svc.repo.MarkClear(ctx, it.ID, nil)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "moderation worker: record item failed", "item_id", it.ID, "err", err)
+	}
+}
+
+// retryBackoff returns the delay before the next check after attempt
+// failures: 30s, 1m, 2m, ... capped at 30m, with ±20% jitter.
+func retryBackoff(attempt int) time.Duration {
+	d := baseRetryBackoff << (attempt - 1)
+	if d <= 0 || d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	jitter := 0.8 + rand.Float64()*0.4
+	return time.Duration(float64(d) * jitter)
+}
@@ -0,0 +1,227 @@
+package notification
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for notification endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new notification Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// List godoc
+//
+//	@Summary		List notifications
+//	@Description	Returns the authenticated user's notifications, newest first. Pass nextCursor from the previous page as cursor to continue.
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			cursor	query		string	false	"Cursor from the previous page"
+//	@Param			limit	query		int		false	"Page size (1-100, default 20)"
+//	@Success		200		{object}	response.Envelope{data=response.Page{items=[]Notification}}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/notifications [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	page, err := h.svc.List(r.Context(), userID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			response.BadRequest(w, "invalid cursor")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, page)
+}
+
+type unreadCountResponse struct {
+	Count int `json:"count" example:"3"`
+}
+
+// UnreadCount godoc
+//
+//	@Summary		Count unread notifications
+//	@Description	Returns the number of unread notifications, for the inbox badge.
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=unreadCountResponse}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/notifications/unread-count [get]
+func (h *Handler) UnreadCount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	n, err := h.svc.UnreadCount(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, unreadCountResponse{Count: n})
+}
+
+// MarkRead godoc
+//
+//	@Summary		Mark notification as read
+//	@Description	Mark a single notification as read. Idempotent.
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Notification ID"
+//	@Success		200	{object}	response.Envelope{data=Notification}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/notifications/{id}/read [post]
+func (h *Handler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	n, err := h.svc.MarkRead(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			response.NotFound(w, "notification not found")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, n)
+}
+
+type markAllReadResponse struct {
+	Updated int64 `json:"updated" example:"3"`
+}
+
+// MarkAllRead godoc
+//
+//	@Summary		Mark all notifications as read
+//	@Description	Mark every unread notification as read.
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=markAllReadResponse}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/notifications/read-all [post]
+func (h *Handler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	n, err := h.svc.MarkAllRead(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, markAllReadResponse{Updated: n})
+}
+
+// Settings godoc
+//
+//	@Summary		Get notification settings
+//	@Description	Returns, for each configurable event type, whether it is delivered in-app, by push, and by SMS. Mandatory channels cannot be turned off.
+//	@Tags			notifications
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]EventSettings}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/notification-settings [get]
+func (h *Handler) Settings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	settings, err := h.svc.Settings(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, settings)
+}
+
+type updateSettingsRequest struct {
+	Settings []PreferenceUpdate `json:"settings"`
+}
+
+// UpdateSettings godoc
+//
+//	@Summary		Update notification settings
+//	@Description	Enable or disable channels (in_app, push, sms) per event type. Only the listed pairs change.
+//	@Tags			notifications
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		updateSettingsRequest	true	"Changes"
+//	@Success		200		{object}	response.Envelope{data=[]EventSettings}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/notification-settings [patch]
+func (h *Handler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req updateSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	settings, err := h.svc.UpdateSettings(r.Context(), userID, req.Settings)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPreference) {
+			response.BadRequest(w, "settings must list known event types and channels, and mandatory channels cannot be disabled")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, settings)
+}
@@ -0,0 +1,147 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Delivery channels.
+const (
+	ChannelInApp = "in_app"
+	ChannelPush  = "push"
+	ChannelSMS   = "sms"
+)
+
+// channels lists every channel in display order.
+var channels = []string{ChannelInApp, ChannelPush, ChannelSMS}
+
+// eventSpec describes a notification type's default channels and the channels
+// users may not turn off (security notices must always reach the inbox).
+type eventSpec struct {
+	defaults  map[string]bool
+	mandatory map[string]bool
+}
+
+// catalogue lists the event types users can configure.
+var catalogue = map[string]eventSpec{
+	TypeNewLogin: {
+		defaults:  map[string]bool{ChannelInApp: true, ChannelPush: true, ChannelSMS: false},
+		mandatory: map[string]bool{ChannelInApp: true},
+	},
+	TypeKYCReviewed: {
+		defaults:  map[string]bool{ChannelInApp: true, ChannelPush: true, ChannelSMS: false},
+		mandatory: map[string]bool{ChannelInApp: true},
+	},
+	TypeBusinessReviewed: {
+		defaults:  map[string]bool{ChannelInApp: true, ChannelPush: true, ChannelSMS: false},
+		mandatory: map[string]bool{ChannelInApp: true},
+	},
+	TypeFamilyInvitation: {
+		defaults: map[string]bool{ChannelInApp: true, ChannelPush: true, ChannelSMS: false},
+	},
+	// A parent link grants oversight of the child's account, so both sides
+	// must always learn when one is made or removed.
+	TypeFamilyLinkChanged: {
+		defaults:  map[string]bool{ChannelInApp: true, ChannelPush: true, ChannelSMS: false},
+		mandatory: map[string]bool{ChannelInApp: true},
+	},
+	// Conversations keep their own unread counts, so messages skip the inbox.
+	TypeMessageReceived: {
+		defaults: map[string]bool{ChannelInApp: false, ChannelPush: true, ChannelSMS: false},
+	},
+}
+
+// ErrInvalidPreference is returned for unknown event types or channels, or an
+// attempt to disable a mandatory channel.
+var ErrInvalidPreference = errors.New("invalid notification preference")
+
+// ChannelSetting is one channel's state for an event type.
+type ChannelSetting struct {
+	Channel   string `json:"channel"   example:"push"`
+	Enabled   bool   `json:"enabled"`
+	Mandatory bool   `json:"mandatory"`
+}
+
+// EventSettings are the channel settings for one event type.
+type EventSettings struct {
+	EventType string           `json:"eventType" example:"auth.new_login"`
+	Channels  []ChannelSetting `json:"channels"`
+}
+
+// PreferenceUpdate enables or disables one channel for one event type.
+type PreferenceUpdate struct {
+	EventType string `json:"eventType" example:"auth.new_login"`
+	Channel   string `json:"channel"   example:"push"`
+	Enabled   bool   `json:"enabled"`
+}
+
+// overrides maps event type → channel → enabled for a user's stored deviations.
+type overrides map[string]map[string]bool
+
+// ListPreferences returns the user's stored preference overrides.
+func (r *Repository) ListPreferences(ctx context.Context, userID string) (overrides, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT event_type, channel, enabled FROM notification_preferences WHERE user_id = $1`, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list notification preferences: %w", err)
+	}
+	defer rows.Close()
+
+	out := overrides{}
+	for rows.Next() {
+		var eventType, channel string
+		var enabled bool
+		if err := rows.Scan(&eventType, &channel, &enabled); err != nil {
+			return nil, fmt.Errorf("scan notification preference: %w", err)
+		}
+		if out[eventType] == nil {
+			out[eventType] = map[string]bool{}
+		}
+		out[eventType][channel] = enabled
+	}
+	return out, rows.Err()
+}
+
+// SetPreferences upserts the given preferences in one transaction.
+func (r *Repository) SetPreferences(ctx context.Context, userID string, updates []PreferenceUpdate) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	for _, u := range updates {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO notification_preferences (user_id, event_type, channel, enabled)
+			 VALUES ($1, $2, $3, $4)
+			 ON CONFLICT (user_id, event_type, channel) DO UPDATE SET
+			     enabled    = EXCLUDED.enabled,
+			     updated_at = NOW()`,
+			userID, u.EventType, u.Channel, u.Enabled,
+		)
+		if err != nil {
+			return fmt.Errorf("set notification preference: %w", err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// enabledChannels resolves which channels an event should go to for a user.
+// Unknown event types are delivered in-app only.
+func enabledChannels(eventType string, o overrides) map[string]bool {
+	spec, ok := catalogue[eventType]
+	if !ok {
+		return map[string]bool{ChannelInApp: true}
+	}
+	out := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		enabled := spec.defaults[ch]
+		if v, set := o[eventType][ch]; set {
+			enabled = v
+		}
+		out[ch] = enabled || spec.mandatory[ch]
+	}
+	return out
+}
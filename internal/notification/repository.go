@@ -0,0 +1,149 @@
+// Package notification stores in-app notifications, dispatches them over the
+// channels each user has enabled, and exposes the API other modules use to
+// emit them.
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/deeplink"
+)
+
+// Notification is an in-app notification shown in the user's inbox.
+type Notification struct {
+	ID        string         `json:"id"`
+	UserID    string         `json:"-"`
+	Type      string         `json:"type"     example:"auth.new_login"`
+	Title     string         `json:"title"`
+	Body      string         `json:"body"`
+	DeepLink  *deeplink.Link `json:"deepLink,omitempty"`
+	ReadAt    *time.Time     `json:"readAt,omitempty"`
+	CreatedAt time.Time      `json:"createdAt"`
+}
+
+// ErrNotFound is returned when a notification does not exist for the user.
+var ErrNotFound = errors.New("notification not found")
+
+// Repository handles notification persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new notification Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const selectCols = `id, user_id, type, title, body, deep_link, read_at, created_at`
+
+// scanNotification scans a full notifications row into a Notification value.
+func scanNotification(row pgx.Row, n *Notification) error {
+	return row.Scan(
+		&n.ID, &n.UserID, &n.Type, &n.Title, &n.Body,
+		&n.DeepLink, &n.ReadAt, &n.CreatedAt,
+	)
+}
+
+// Create inserts a notification.
+func (r *Repository) Create(ctx context.Context, n *Notification) (*Notification, error) {
+	out := &Notification{}
+	err := scanNotification(r.db.QueryRow(ctx,
+		`INSERT INTO notifications (user_id, type, title, body, deep_link)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING `+selectCols,
+		n.UserID, n.Type, n.Title, n.Body, n.DeepLink,
+	), out)
+	if err != nil {
+		return nil, fmt.Errorf("create notification: %w", err)
+	}
+	return out, nil
+}
+
+// ListBefore returns up to limit notifications for the user strictly older
+// than the (createdAt, id) cursor, newest first. A nil cursor starts at the top.
+func (r *Repository) ListBefore(ctx context.Context, userID string, cur *db.Cursor, limit int) ([]*Notification, error) {
+	before, beforeID := db.CursorArgs(cur)
+	rows, err := r.db.Query(ctx,
+		`SELECT `+selectCols+` FROM notifications
+		 WHERE user_id = $1
+		   AND ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3::uuid))
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $4`,
+		userID, before, beforeID, limit,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return nil, ErrInvalidCursor
+		}
+		return nil, fmt.Errorf("list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Notification{}
+	for rows.Next() {
+		n := &Notification{}
+		if err := scanNotification(rows, n); err != nil {
+			return nil, fmt.Errorf("scan notification: %w", err)
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// CountUnread returns the number of unread notifications for the user.
+func (r *Repository) CountUnread(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID,
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count unread notifications: %w", err)
+	}
+	return n, nil
+}
+
+// MarkRead marks one notification as read. Marking an already-read
+// notification keeps its original read time.
+func (r *Repository) MarkRead(ctx context.Context, userID, id string) (*Notification, error) {
+	n := &Notification{}
+	err := scanNotification(r.db.QueryRow(ctx,
+		`UPDATE notifications SET read_at = COALESCE(read_at, NOW())
+		 WHERE id = $1 AND user_id = $2
+		 RETURNING `+selectCols,
+		id, userID,
+	), n)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("mark notification read: %w", err)
+	}
+	return n, nil
+}
+
+// MarkAllRead marks every unread notification for the user as read and
+// returns how many were updated.
+func (r *Repository) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("mark all notifications read: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
@@ -0,0 +1,184 @@
+package notification
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sort"
+
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/deeplink"
+	"github.com/radif/service/internal/realtime"
+	"github.com/radif/service/internal/response"
+)
+
+// Notification types emitted by other modules.
+const (
+	TypeNewLogin          = "auth.new_login"
+	TypeMessageReceived   = "message.received"
+	TypeKYCReviewed       = "kyc.reviewed"
+	TypeBusinessReviewed  = "business.verification_reviewed"
+	TypeFamilyInvitation  = "family.invitation"
+	TypeFamilyLinkChanged = "family.link_changed"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = db.ErrInvalidCursor
+
+// Message is what an emitting module supplies; the service stores it.
+type Message struct {
+	Type     string
+	Title    string
+	Body     string
+	DeepLink *deeplink.Link
+}
+
+// Sender delivers a message over an external channel (push or SMS).
+type Sender interface {
+	Send(ctx context.Context, userID string, m Message) error
+}
+
+// LivePublisher pushes an event to the user's open real-time connections.
+type LivePublisher interface {
+	Publish(ctx context.Context, userID, eventType string, data any) error
+}
+
+// Service contains business logic for notifications.
+type Service struct {
+	repo *Repository
+	push Sender
+	sms  Sender
+	live LivePublisher
+}
+
+// NewService creates a new notification Service. push, sms and live may be
+// nil, in which case those channels are skipped.
+func NewService(repo *Repository, push, sms Sender, live LivePublisher) *Service {
+	return &Service{repo: repo, push: push, sms: sms, live: live}
+}
+
+// Notify dispatches a message to userID over the channels their preferences
+// enable. It returns the stored in-app notification, or nil when the in-app
+// channel is disabled. External channel failures are logged, not returned, so
+// one provider outage doesn't fail the emitting operation.
+func (s *Service) Notify(ctx context.Context, userID string, m Message) (*Notification, error) {
+	prefs, err := s.repo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	enabled := enabledChannels(m.Type, prefs)
+
+	var stored *Notification
+	if enabled[ChannelInApp] {
+		stored, err = s.repo.Create(ctx, &Notification{
+			UserID:   userID,
+			Type:     m.Type,
+			Title:    m.Title,
+			Body:     m.Body,
+			DeepLink: m.DeepLink,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// Open apps show the item at once instead of on their next poll.
+		if s.live != nil {
+			if err := s.live.Publish(ctx, userID, realtime.TypeNotification, stored); err != nil {
+				slog.ErrorContext(ctx, "notification: live publish failed", "type", m.Type, "user_id", userID, "err", err)
+			}
+		}
+	}
+
+	for ch, sender := range map[string]Sender{ChannelPush: s.push, ChannelSMS: s.sms} {
+		if !enabled[ch] || sender == nil {
+			continue
+		}
+		if err := sender.Send(ctx, userID, m); err != nil {
+			slog.ErrorContext(ctx, "notification: send failed", "channel", ch, "type", m.Type, "user_id", userID, "err", err)
+		}
+	}
+	return stored, nil
+}
+
+// Settings returns the user's effective channel settings for every
+// configurable event type.
+func (s *Service) Settings(ctx context.Context, userID string) ([]*EventSettings, error) {
+	prefs, err := s.repo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make([]string, 0, len(catalogue))
+	for t := range catalogue {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	out := make([]*EventSettings, 0, len(types))
+	for _, t := range types {
+		enabled := enabledChannels(t, prefs)
+		es := &EventSettings{EventType: t}
+		for _, ch := range channels {
+			es.Channels = append(es.Channels, ChannelSetting{
+				Channel:   ch,
+				Enabled:   enabled[ch],
+				Mandatory: catalogue[t].mandatory[ch],
+			})
+		}
+		out = append(out, es)
+	}
+	return out, nil
+}
+
+// UpdateSettings validates and stores preference changes, then returns the
+// resulting settings.
+func (s *Service) UpdateSettings(ctx context.Context, userID string, updates []PreferenceUpdate) ([]*EventSettings, error) {
+	if len(updates) == 0 {
+		return nil, ErrInvalidPreference
+	}
+	for _, u := range updates {
+		spec, ok := catalogue[u.EventType]
+		if !ok || !slices.Contains(channels, u.Channel) {
+			return nil, ErrInvalidPreference
+		}
+		if !u.Enabled && spec.mandatory[u.Channel] {
+			return nil, ErrInvalidPreference
+		}
+	}
+	if err := s.repo.SetPreferences(ctx, userID, updates); err != nil {
+		return nil, err
+	}
+	return s.Settings(ctx, userID)
+}
+
+// List returns a page of the user's notifications, newest first. after is the
+// NextCursor of the previous page, or empty for the first page.
+func (s *Service) List(ctx context.Context, userID, after string, limit int) (*response.Page, error) {
+	cur, err := db.DecodeCursor(after)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.repo.ListBefore(ctx, userID, cur, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.NewPage(items, db.NextCursor(items, limit, func(x *Notification) db.Cursor {
+		return db.Cursor{CreatedAt: x.CreatedAt, ID: x.ID}
+	})), nil
+}
+
+// UnreadCount returns the number of unread notifications.
+func (s *Service) UnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.repo.CountUnread(ctx, userID)
+}
+
+// MarkRead marks one notification as read.
+func (s *Service) MarkRead(ctx context.Context, userID, id string) (*Notification, error) {
+	return s.repo.MarkRead(ctx, userID, id)
+}
+
+// MarkAllRead marks all of the user's notifications as read.
+func (s *Service) MarkAllRead(ctx context.Context, userID string) (int64, error) {
+	return s.repo.MarkAllRead(ctx, userID)
+}
@@ -0,0 +1,227 @@
+package openbanking
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/bankaccount"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for open-banking endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new open-banking Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type linkRequest struct {
+	// Consent must be true: the user has read and accepted the read-only access terms.
+	Consent bool `json:"consent" example:"true"`
+}
+
+// Link godoc
+//
+//	@Summary		Link bank account
+//	@Description	Connect a registered card or IBAN to the bank provider for read-only balance and transaction views. Requires explicit consent; the consent (scope, text version, time, IP) is recorded. Links expire after at most 90 days.
+//	@Tags			bank-links
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string		true	"Bank account ID"
+//	@Param			request	body		linkRequest	true	"Consent"
+//	@Success		201		{object}	response.Envelope{data=Link}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Failure		503		{object}	response.Envelope
+//	@Router			/users/me/bank-accounts/{id}/link [post]
+func (h *Handler) Link(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req linkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	l, err := h.svc.Link(r.Context(), userID, chi.URLParam(r, "id"), req.Consent, middleware.ClientIP(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrConsentRequired):
+			response.BadRequest(w, "explicit consent is required to link a bank account")
+		case errors.Is(err, bankaccount.ErrNotFound):
+			response.NotFound(w, "bank account not found")
+		case errors.Is(err, ErrAlreadyLinked):
+			response.Conflict(w, "bank account is already linked")
+		case errors.Is(err, ErrProviderUnavailable):
+			response.Error(w, http.StatusServiceUnavailable, "bank provider is unavailable")
+		default:
+			response.InternalError(w)
+		}
+		return
+	}
+
+	response.Created(w, l)
+}
+
+// List godoc
+//
+//	@Summary		List bank links
+//	@Description	Returns the authenticated user's bank links, including revoked ones, newest first.
+//	@Tags			bank-links
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]Link}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/bank-links [get]
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	links, err := h.svc.List(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, links)
+}
+
+// Balance godoc
+//
+//	@Summary		Get linked balance
+//	@Description	Fetches the live balance of a linked bank account from the provider.
+//	@Tags			bank-links
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Bank link ID"
+//	@Success		200	{object}	response.Envelope{data=Balance}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Failure		503	{object}	response.Envelope
+//	@Router			/users/me/bank-links/{id}/balance [get]
+func (h *Handler) Balance(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	b, err := h.svc.Balance(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeReadError(w, err)
+		return
+	}
+
+	response.OK(w, b)
+}
+
+// Transactions godoc
+//
+//	@Summary		List linked transactions
+//	@Description	Fetches recent transactions of a linked bank account from the provider, newest first.
+//	@Tags			bank-links
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Bank link ID"
+//	@Param			limit	query		int		false	"Number of transactions (1-50, default 50)"
+//	@Success		200		{object}	response.Envelope{data=[]CardTransaction}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Failure		503		{object}	response.Envelope
+//	@Router			/users/me/bank-links/{id}/transactions [get]
+func (h *Handler) Transactions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxTransactions {
+			response.BadRequest(w, "limit must be between 1 and 50")
+			return
+		}
+		limit = n
+	}
+
+	txs, err := h.svc.Transactions(r.Context(), userID, chi.URLParam(r, "id"), limit)
+	if err != nil {
+		writeReadError(w, err)
+		return
+	}
+
+	response.OK(w, txs)
+}
+
+// Revoke godoc
+//
+//	@Summary		Revoke bank link
+//	@Description	Withdraw consent for a bank link. The provider grant is revoked and the stored token erased; the consent record is kept.
+//	@Tags			bank-links
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Bank link ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/bank-links/{id} [delete]
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	if err := h.svc.Revoke(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			response.NotFound(w, "bank link not found")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// writeReadError maps errors from provider-backed reads to responses.
+func writeReadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "bank link not found")
+	case errors.Is(err, ErrConsentExpired):
+		response.Forbidden(w, "consent has expired; link the account again")
+	case errors.Is(err, ErrProviderUnavailable):
+		response.Error(w, http.StatusServiceUnavailable, "bank provider is unavailable")
+	default:
+		response.InternalError(w)
+	}
+}
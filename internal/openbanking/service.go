@@ -0,0 +1,183 @@
+package openbanking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/radif/service/internal/bankaccount"
+	"github.com/radif/service/internal/secretbox"
+)
+
+const (
+	// consentScope is the only access Radif requests: read-only views.
+	consentScope = "balance:read transactions:read"
+	// consentTextVersion identifies the consent wording shown in the app;
+	// bump it whenever the text changes so records show what was agreed to.
+	consentTextVersion = "2026-01"
+	// maxConsentDuration caps a link regardless of the provider's token lifetime.
+	maxConsentDuration = 90 * 24 * time.Hour
+	maxTransactions    = 50
+)
+
+// ErrProviderUnavailable is returned when no provider is configured or it is down.
+var ErrProviderUnavailable = errors.New("open-banking provider unavailable")
+
+// ErrConsentRequired is returned when linking without explicit consent.
+var ErrConsentRequired = errors.New("explicit consent required")
+
+// ErrConsentExpired is returned when a link's consent period has ended.
+var ErrConsentExpired = errors.New("consent expired")
+
+// Grant is a provider access grant for one account.
+type Grant struct {
+	AccessToken string
+	ExpiresAt   time.Time
+}
+
+// Balance is an account balance reported by the provider, in rials.
+type Balance struct {
+	Available int64     `json:"available" example:"125000000"`
+	Ledger    int64     `json:"ledger"    example:"130000000"`
+	AsOf      time.Time `json:"asOf"`
+}
+
+// CardTransaction is a transaction reported by the provider.
+type CardTransaction struct {
+	Amount      int64     `json:"amount"      example:"-450000"`
+	Description string    `json:"description"`
+	OccurredAt  time.Time `json:"occurredAt"`
+}
+
+// Provider is a bank/inquiry provider integration.
+type Provider interface {
+	Name() string
+	// Link obtains a read-only grant for the given card or IBAN.
+	Link(ctx context.Context, kind, number string) (*Grant, error)
+	Balance(ctx context.Context, accessToken string) (*Balance, error)
+	Transactions(ctx context.Context, accessToken string, limit int) ([]*CardTransaction, error)
+	// Revoke invalidates a grant at the provider.
+	Revoke(ctx context.Context, accessToken string) error
+}
+
+// Service contains business logic for open-banking links.
+type Service struct {
+	repo     *Repository
+	accounts *bankaccount.Service
+	provider Provider
+	box      *secretbox.Box
+}
+
+// NewService creates a new open-banking Service. provider may be nil, in which
+// case linking and reads return ErrProviderUnavailable.
+func NewService(repo *Repository, accounts *bankaccount.Service, provider Provider, box *secretbox.Box) *Service {
+	return &Service{repo: repo, accounts: accounts, provider: provider, box: box}
+}
+
+// Link connects one of the user's bank accounts after explicit consent.
+func (s *Service) Link(ctx context.Context, userID, bankAccountID string, consent bool, ip string) (*Link, error) {
+	if !consent {
+		return nil, ErrConsentRequired
+	}
+	if s.provider == nil {
+		return nil, ErrProviderUnavailable
+	}
+
+	a, err := s.accounts.Get(ctx, userID, bankAccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	grant, err := s.provider.Link(ctx, a.Kind, a.Number)
+	if err != nil {
+		slog.ErrorContext(ctx, "openbanking: link failed", "provider", s.provider.Name(), "err", err)
+		return nil, ErrProviderUnavailable
+	}
+
+	expiresAt := grant.ExpiresAt
+	if limit := time.Now().Add(maxConsentDuration); expiresAt.IsZero() || expiresAt.After(limit) {
+		expiresAt = limit
+	}
+
+	return s.repo.Create(ctx, &Link{
+		UserID:             userID,
+		BankAccountID:      a.ID,
+		Provider:           s.provider.Name(),
+		ConsentScope:       consentScope,
+		ConsentTextVersion: consentTextVersion,
+		ConsentIP:          ip,
+		ExpiresAt:          expiresAt,
+	}, func(id string) ([]byte, error) {
+		return s.box.Seal([]byte(grant.AccessToken), []byte(id))
+	})
+}
+
+// List returns the user's links.
+func (s *Service) List(ctx context.Context, userID string) ([]*Link, error) {
+	return s.repo.ListByUser(ctx, userID)
+}
+
+// Balance fetches the live balance of a linked account.
+func (s *Service) Balance(ctx context.Context, userID, linkID string) (*Balance, error) {
+	token, err := s.accessToken(ctx, userID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.provider.Balance(ctx, token)
+	if err != nil {
+		slog.ErrorContext(ctx, "openbanking: balance failed", "provider", s.provider.Name(), "err", err)
+		return nil, ErrProviderUnavailable
+	}
+	return b, nil
+}
+
+// Transactions fetches recent transactions of a linked account.
+func (s *Service) Transactions(ctx context.Context, userID, linkID string, limit int) ([]*CardTransaction, error) {
+	if limit <= 0 || limit > maxTransactions {
+		limit = maxTransactions
+	}
+	token, err := s.accessToken(ctx, userID, linkID)
+	if err != nil {
+		return nil, err
+	}
+	txs, err := s.provider.Transactions(ctx, token, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "openbanking: transactions failed", "provider", s.provider.Name(), "err", err)
+		return nil, ErrProviderUnavailable
+	}
+	return txs, nil
+}
+
+// Revoke withdraws consent. The provider grant is revoked best-effort; the
+// local token is erased regardless.
+func (s *Service) Revoke(ctx context.Context, userID, linkID string) error {
+	if s.provider != nil {
+		if token, err := s.accessToken(ctx, userID, linkID); err == nil {
+			if err := s.provider.Revoke(ctx, token); err != nil {
+				slog.ErrorContext(ctx, "openbanking: revoke failed", "provider", s.provider.Name(), "err", err)
+			}
+		}
+	}
+	return s.repo.Revoke(ctx, userID, linkID)
+}
+
+// accessToken loads and decrypts the token of a live, unexpired link.
+func (s *Service) accessToken(ctx context.Context, userID, linkID string) (string, error) {
+	if s.provider == nil {
+		return "", ErrProviderUnavailable
+	}
+	l, err := s.repo.GetLive(ctx, userID, linkID)
+	if err != nil {
+		return "", err
+	}
+	if time.Now().After(l.ExpiresAt) {
+		return "", ErrConsentExpired
+	}
+	token, err := s.box.Open(l.AccessTokenEnc, []byte(l.ID))
+	if err != nil {
+		return "", fmt.Errorf("open bank link token: %w", err)
+	}
+	return string(token), nil
+}
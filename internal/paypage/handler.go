@@ -0,0 +1,235 @@
+package paypage
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/imaging"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+	"github.com/radif/service/internal/storage"
+)
+
+const (
+	maxHeadlineLength    = 100
+	maxDescriptionLength = 500
+	maxLabelLength       = 50
+)
+
+var accentColorRegex = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// Handler holds HTTP handlers for payment page endpoints.
+type Handler struct {
+	svc   *Service
+	store storage.Storage
+}
+
+// NewHandler creates a new paypage Handler. store resolves avatar URLs.
+func NewHandler(svc *Service, store storage.Storage) *Handler {
+	return &Handler{svc: svc, store: store}
+}
+
+type saveRequest struct {
+	// Enabled defaults to true.
+	Enabled     *bool   `json:"enabled"`
+	Headline    *string `json:"headline"    example:"Pay Cafe Lamiz"`
+	Description *string `json:"description" example:"Thanks for visiting!"`
+	AccentColor *string `json:"accentColor" example:"#1E88E5"`
+	// AmountPresets are suggested amounts in rials, shown in order.
+	AmountPresets []int64 `json:"amountPresets" example:"500000,1000000,2000000"`
+	// AllowCustomAmount defaults to true.
+	AllowCustomAmount *bool `json:"allowCustomAmount"`
+	// OrderReference is off (default), optional or required.
+	OrderReference      string  `json:"orderReference"      example:"optional"`
+	OrderReferenceLabel *string `json:"orderReferenceLabel" example:"Table number"`
+}
+
+// Own godoc
+//
+//	@Summary		Get own payment page
+//	@Description	Returns the business's payment page settings and whether the page is live. Businesses that never saved settings get the defaults: enabled, custom amounts allowed, no presets and no order reference. Business accounts only.
+//	@Tags			pay
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=OwnPage}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/pay-page [get]
+func (h *Handler) Own(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.svc.Own(r.Context(), businessID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, page)
+}
+
+// Save godoc
+//
+//	@Summary		Save payment page
+//	@Description	Replace the business's payment page settings. Up to 6 distinct amount presets of 1 to 1,000,000,000 rials; the page must offer presets, custom amounts or both. The page is served at GET /pay/business/{username} once the business is verified. Business accounts only.
+//	@Tags			pay
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		saveRequest	true	"Page settings"
+//	@Success		200		{object}	response.Envelope{data=OwnPage}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/pay-page [put]
+func (h *Handler) Save(w http.ResponseWriter, r *http.Request) {
+	businessID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	var req saveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	settings := &Settings{
+		Enabled:             req.Enabled == nil || *req.Enabled,
+		Headline:            trimOptional(req.Headline),
+		Description:         trimOptional(req.Description),
+		AccentColor:         trimOptional(req.AccentColor),
+		AmountPresets:       req.AmountPresets,
+		AllowCustomAmount:   req.AllowCustomAmount == nil || *req.AllowCustomAmount,
+		OrderReference:      req.OrderReference,
+		OrderReferenceLabel: trimOptional(req.OrderReferenceLabel),
+	}
+	if settings.AmountPresets == nil {
+		settings.AmountPresets = []int64{}
+	}
+	if settings.OrderReference == "" {
+		settings.OrderReference = ReferenceOff
+	}
+	if code, args := validateSettings(settings); code != "" {
+		response.Localized(w, http.StatusBadRequest, code, args...)
+		return
+	}
+
+	page, err := h.svc.Save(r.Context(), businessID, settings)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, page)
+}
+
+// Public godoc
+//
+//	@Summary		Get business payment page
+//	@Description	Public payload of a verified business's hosted payment page: the business profile with its cover photo and gallery, branding, amount options in rials and the optional order-reference field. No authentication required.
+//	@Tags			pay
+//	@Produce		json
+//	@Param			username	path		string	true	"Business username"
+//	@Success		200			{object}	response.Envelope{data=Page}
+//	@Failure		404			{object}	response.Envelope
+//	@Failure		429			{object}	response.Envelope
+//	@Failure		500			{object}	response.Envelope
+//	@Router			/pay/business/{username} [get]
+func (h *Handler) Public(w http.ResponseWriter, r *http.Request) {
+	page, err := h.svc.Public(r.Context(), chi.URLParam(r, "username"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if b := page.Business; b.AvatarKey != nil && *b.AvatarKey != "" {
+		url := h.store.PublicURL(*b.AvatarKey)
+		b.AvatarURL = &url
+		if b.AvatarVariants {
+			b.AvatarURLs = imaging.VariantURLs(*b.AvatarKey, h.store.PublicURL)
+		}
+	}
+	if b := page.Business; b.CoverKey != nil && *b.CoverKey != "" {
+		url := h.store.PublicURL(*b.CoverKey)
+		b.CoverURL = &url
+	}
+	for _, key := range page.Business.GalleryKeys {
+		page.Business.Gallery = append(page.Business.Gallery, h.store.PublicURL(key))
+	}
+	response.OK(w, page)
+}
+
+// trimOptional trims s and maps an empty result to nil.
+func trimOptional(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	t := strings.TrimSpace(*s)
+	if t == "" {
+		return nil
+	}
+	return &t
+}
+
+// validateSettings checks field formats and lengths, returning the i18n code
+// of the error and its arguments, or "".
+func validateSettings(s *Settings) (code string, args []any) {
+	if s.Headline != nil && utf8.RuneCountInString(*s.Headline) > maxHeadlineLength {
+		return "headline_too_long", []any{maxHeadlineLength}
+	}
+	if s.Description != nil && utf8.RuneCountInString(*s.Description) > maxDescriptionLength {
+		return "page_description_too_long", []any{maxDescriptionLength}
+	}
+	if s.AccentColor != nil && !accentColorRegex.MatchString(*s.AccentColor) {
+		return "invalid_accent_color", nil
+	}
+	switch s.OrderReference {
+	case ReferenceOff, ReferenceOptional, ReferenceRequired:
+	default:
+		return "invalid_order_reference", nil
+	}
+	if s.OrderReferenceLabel != nil && utf8.RuneCountInString(*s.OrderReferenceLabel) > maxLabelLength {
+		return "order_reference_label_too_long", []any{maxLabelLength}
+	}
+	return "", nil
+}
+
+// businessAccount returns the authenticated user ID, writing an error
+// response when the caller is not authenticated or not a business account.
+func businessAccount(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return "", false
+	}
+	if accountType, _ := r.Context().Value(middleware.UserAccountTypeKey).(string); accountType != "business" {
+		response.Forbidden(w, "payment pages are available to business accounts only")
+		return "", false
+	}
+	return userID, true
+}
+
+// writeError maps paypage service errors to responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		response.NotFound(w, "payment page not found")
+	case errors.Is(err, ErrInvalidPresets):
+		response.Localized(w, http.StatusBadRequest, "invalid_amount_presets", MaxPresets, MaxAmount)
+	case errors.Is(err, ErrNoAmount):
+		response.BadRequest(w, "add amount presets or allow custom amounts")
+	default:
+		response.InternalError(w)
+	}
+}
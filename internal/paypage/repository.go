@@ -0,0 +1,177 @@
+// Package paypage manages the hosted payment page of a verified business: a
+// single shareable URL with the business's branding, amount presets and an
+// optional order-reference field.
+package paypage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Order reference modes.
+const (
+	ReferenceOff      = "off"
+	ReferenceOptional = "optional"
+	ReferenceRequired = "required"
+)
+
+// Settings is how a business has configured its payment page.
+type Settings struct {
+	Enabled             bool    `json:"enabled"`
+	Headline            *string `json:"headline,omitempty"            example:"Pay Cafe Lamiz"`
+	Description         *string `json:"description,omitempty"         example:"Thanks for visiting!"`
+	AccentColor         *string `json:"accentColor,omitempty"         example:"#1E88E5"`
+	AmountPresets       []int64 `json:"amountPresets"                 example:"500000,1000000,2000000"`
+	AllowCustomAmount   bool    `json:"allowCustomAmount"`
+	OrderReference      string  `json:"orderReference"                example:"optional"`
+	OrderReferenceLabel *string `json:"orderReferenceLabel,omitempty" example:"Table number"`
+	// UpdatedAt is nil until the business saves its settings.
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+}
+
+// defaultSettings is the page of a business that has not configured one.
+func defaultSettings() *Settings {
+	return &Settings{
+		Enabled:           true,
+		AmountPresets:     []int64{},
+		AllowCustomAmount: true,
+		OrderReference:    ReferenceOff,
+	}
+}
+
+// Business is the public face of the business a page belongs to.
+type Business struct {
+	ID               string  `json:"-"`
+	Username         string  `json:"username"`
+	FullName         *string `json:"fullName,omitempty"`
+	Bio              *string `json:"bio,omitempty"`
+	BusinessCategory *string `json:"businessCategory,omitempty"`
+	Verified         bool    `json:"verified"`
+	AvatarKey        *string `json:"-"`
+	AvatarVariants   bool    `json:"-"`
+	AvatarURL        *string `json:"avatarUrl,omitempty"`
+	// AvatarURLs maps a square size in pixels ("64", "128", "512") to a
+	// resized copy of the avatar.
+	AvatarURLs  map[string]string `json:"avatarUrls,omitempty"`
+	CoverKey    *string           `json:"-"`
+	CoverURL    *string           `json:"coverUrl,omitempty"`
+	GalleryKeys []string          `json:"-"`
+	// Gallery lists the URLs of the profile's gallery images in upload order.
+	Gallery []string `json:"gallery,omitempty"`
+}
+
+// ErrNotFound is returned when no verified business has the username, or its
+// page is disabled.
+var ErrNotFound = errors.New("payment page not found")
+
+// Repository handles payment page persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new paypage Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Get returns the business's settings, or the defaults if it has none.
+func (r *Repository) Get(ctx context.Context, businessID string) (*Settings, error) {
+	s := &Settings{}
+	err := r.db.QueryRow(ctx,
+		`SELECT enabled, headline, description, accent_color, amount_presets,
+		        allow_custom_amount, order_reference, order_reference_label, updated_at
+		 FROM pay_pages WHERE business_id = $1`,
+		businessID,
+	).Scan(
+		&s.Enabled, &s.Headline, &s.Description, &s.AccentColor, &s.AmountPresets,
+		&s.AllowCustomAmount, &s.OrderReference, &s.OrderReferenceLabel, &s.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return defaultSettings(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get pay page: %w", err)
+	}
+	return s, nil
+}
+
+// Save creates or replaces the business's settings.
+func (r *Repository) Save(ctx context.Context, businessID string, s *Settings) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO pay_pages (business_id, enabled, headline, description, accent_color,
+		                        amount_presets, allow_custom_amount, order_reference, order_reference_label)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 ON CONFLICT (business_id) DO UPDATE
+		 SET enabled               = EXCLUDED.enabled,
+		     headline              = EXCLUDED.headline,
+		     description           = EXCLUDED.description,
+		     accent_color          = EXCLUDED.accent_color,
+		     amount_presets        = EXCLUDED.amount_presets,
+		     allow_custom_amount   = EXCLUDED.allow_custom_amount,
+		     order_reference       = EXCLUDED.order_reference,
+		     order_reference_label = EXCLUDED.order_reference_label`,
+		businessID, s.Enabled, s.Headline, s.Description, s.AccentColor,
+		s.AmountPresets, s.AllowCustomAmount, s.OrderReference, s.OrderReferenceLabel,
+	)
+	if err != nil {
+		return fmt.Errorf("save pay page: %w", err)
+	}
+	return nil
+}
+
+// GetBusiness returns the verified business account with the username.
+func (r *Repository) GetBusiness(ctx context.Context, username string) (*Business, error) {
+	b := &Business{Verified: true}
+	err := r.db.QueryRow(ctx,
+		`SELECT id, username, full_name, bio, business_category, avatar_key, avatar_variants, cover_key
+		 FROM users
+		 WHERE username = $1 AND account_type = 'business' AND verified_at IS NOT NULL AND deleted_at IS NULL`,
+		username,
+	).Scan(&b.ID, &b.Username, &b.FullName, &b.Bio, &b.BusinessCategory, &b.AvatarKey, &b.AvatarVariants, &b.CoverKey)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get business: %w", err)
+	}
+	return b, nil
+}
+
+// GalleryKeys returns the object keys of the business's gallery images in upload order.
+func (r *Repository) GalleryKeys(ctx context.Context, businessID string) ([]string, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT object_key FROM user_gallery_images WHERE user_id = $1 ORDER BY created_at, id`,
+		businessID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list gallery keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("scan gallery key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// IsVerified reports whether the business account is verified.
+func (r *Repository) IsVerified(ctx context.Context, businessID string) (bool, error) {
+	var verified bool
+	err := r.db.QueryRow(ctx,
+		`SELECT verified_at IS NOT NULL FROM users WHERE id = $1 AND deleted_at IS NULL`, businessID,
+	).Scan(&verified)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return false, fmt.Errorf("check business verification: %w", err)
+	}
+	return verified, nil
+}
@@ -0,0 +1,146 @@
+package paypage
+
+import (
+	"context"
+	"errors"
+)
+
+// Limits on the amounts a page offers, in rials.
+const (
+	MaxPresets = 6
+	MaxAmount  = 1_000_000_000
+)
+
+// Currency is the currency of every amount on a payment page.
+const Currency = "IRR"
+
+// ErrInvalidPresets is returned when presets exceed MaxPresets, repeat, or
+// fall outside 1..MaxAmount.
+var ErrInvalidPresets = errors.New("invalid amount presets")
+
+// ErrNoAmount is returned when a page offers neither presets nor a custom amount.
+var ErrNoAmount = errors.New("page offers no way to choose an amount")
+
+// Page is the public payload of a business's payment page.
+type Page struct {
+	Business *Business `json:"business"`
+	Branding Branding  `json:"branding"`
+	Amount   Amount    `json:"amount"`
+	// OrderReference is present when the page asks payers for a reference.
+	OrderReference *OrderReference `json:"orderReference,omitempty"`
+}
+
+// Branding is the business's presentation of its page.
+type Branding struct {
+	Headline    *string `json:"headline,omitempty"    example:"Pay Cafe Lamiz"`
+	Description *string `json:"description,omitempty" example:"Thanks for visiting!"`
+	AccentColor *string `json:"accentColor,omitempty" example:"#1E88E5"`
+}
+
+// Amount describes how a payer chooses what to pay.
+type Amount struct {
+	Currency    string  `json:"currency"    example:"IRR"`
+	Presets     []int64 `json:"presets"     example:"500000,1000000,2000000"`
+	AllowCustom bool    `json:"allowCustom"`
+	Max         int64   `json:"max"         example:"1000000000"`
+}
+
+// OrderReference is the free-text field payers fill in to identify an order.
+type OrderReference struct {
+	Required bool    `json:"required"`
+	Label    *string `json:"label,omitempty" example:"Table number"`
+}
+
+// OwnPage is a business's own view of its page settings.
+type OwnPage struct {
+	*Settings
+	// Live reports whether the page is served: the business is verified and
+	// the page is enabled.
+	Live bool `json:"live"`
+}
+
+// Service contains business logic for payment pages.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new paypage Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Own returns the business's page settings and whether the page is live.
+func (s *Service) Own(ctx context.Context, businessID string) (*OwnPage, error) {
+	settings, err := s.repo.Get(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	verified, err := s.repo.IsVerified(ctx, businessID)
+	if err != nil {
+		return nil, err
+	}
+	return &OwnPage{Settings: settings, Live: verified && settings.Enabled}, nil
+}
+
+// Save validates and stores the business's page settings. Unverified
+// businesses may prepare a page; it goes live once they are verified.
+func (s *Service) Save(ctx context.Context, businessID string, settings *Settings) (*OwnPage, error) {
+	if len(settings.AmountPresets) > MaxPresets {
+		return nil, ErrInvalidPresets
+	}
+	seen := make(map[int64]bool, len(settings.AmountPresets))
+	for _, p := range settings.AmountPresets {
+		if p < 1 || p > MaxAmount || seen[p] {
+			return nil, ErrInvalidPresets
+		}
+		seen[p] = true
+	}
+	if len(settings.AmountPresets) == 0 && !settings.AllowCustomAmount {
+		return nil, ErrNoAmount
+	}
+
+	if err := s.repo.Save(ctx, businessID, settings); err != nil {
+		return nil, err
+	}
+	return s.Own(ctx, businessID)
+}
+
+// Public returns the payment page of the verified business with the username.
+func (s *Service) Public(ctx context.Context, username string) (*Page, error) {
+	b, err := s.repo.GetBusiness(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.repo.Get(ctx, b.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, ErrNotFound
+	}
+	if b.GalleryKeys, err = s.repo.GalleryKeys(ctx, b.ID); err != nil {
+		return nil, err
+	}
+
+	page := &Page{
+		Business: b,
+		Branding: Branding{
+			Headline:    settings.Headline,
+			Description: settings.Description,
+			AccentColor: settings.AccentColor,
+		},
+		Amount: Amount{
+			Currency:    Currency,
+			Presets:     settings.AmountPresets,
+			AllowCustom: settings.AllowCustomAmount,
+			Max:         MaxAmount,
+		},
+	}
+	if settings.OrderReference != ReferenceOff {
+		page.OrderReference = &OrderReference{
+			Required: settings.OrderReference == ReferenceRequired,
+			Label:    settings.OrderReferenceLabel,
+		}
+	}
+	return page, nil
+}
@@ -0,0 +1,61 @@
+package realtime
+
+import (
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+const (
+	maxConnsPerUser = 5
+	sendBuffer      = 32
+	writeTimeout    = 10 * time.Second
+	pingInterval    = 30 * time.Second
+	pingTimeout     = 10 * time.Second
+)
+
+// client is one open connection, WebSocket or SSE.
+type client struct {
+	userID string
+	send   chan *Event
+
+	closeOnce sync.Once
+	done      chan struct{}
+	// closeCode and closeReason say why the connection ended, for transports
+	// with a close handshake. They are set before done is closed.
+	closeCode   websocket.StatusCode
+	closeReason string
+}
+
+func newClient(userID string) *client {
+	return &client{userID: userID, send: make(chan *Event, sendBuffer), done: make(chan struct{})}
+}
+
+// enqueue queues e without blocking. A client too slow to drain its buffer
+// is disconnected rather than holding up the hub.
+func (c *client) enqueue(e *Event) {
+	select {
+	case c.send <- e:
+	default:
+		c.close(websocket.StatusPolicyViolation, "client too slow")
+	}
+}
+
+// close ends the connection once.
+func (c *client) close(code websocket.StatusCode, reason string) {
+	c.closeOnce.Do(func() {
+		c.closeCode, c.closeReason = code, reason
+		close(c.done)
+	})
+}
+
+// Handler serves the real-time endpoints.
+type Handler struct {
+	hub *Hub
+}
+
+// NewHandler creates a new realtime Handler.
+func NewHandler(hub *Hub) *Handler {
+	return &Handler{hub: hub}
+}
@@ -0,0 +1,278 @@
+// Package realtime pushes events to signed-in clients over WebSocket, or
+// Server-Sent Events where proxies get in the way. With Redis configured,
+// events are fanned out through Redis pub/sub so a user connected to one
+// replica hears about events raised on another, and recent events are kept
+// in a Redis stream per user so a reconnecting client can resume.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/redis/go-redis/v9"
+)
+
+// Event types pushed to clients.
+const (
+	TypeNotification   = "notification"
+	TypePaymentRequest = "payment_request"
+	TypeTransfer       = "transfer"
+)
+
+// Resume window: clients reconnecting with the ID of the last event they saw
+// get the events they missed, up to historySize per user within historyTTL.
+const (
+	historySize = 100
+	historyTTL  = time.Hour
+)
+
+// Event is the frame sent to clients. ID orders events per user and is what
+// a reconnecting client resumes from.
+type Event struct {
+	ID   string          `json:"id"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// envelope carries an event between replicas.
+type envelope struct {
+	UserID string `json:"userId"`
+	Event  Event  `json:"event"`
+}
+
+// localEvent is an event kept in memory for resumption.
+type localEvent struct {
+	Event
+	at time.Time
+}
+
+// Hub tracks the open connections of each user and delivers events to them.
+type Hub struct {
+	redis   *redis.Client
+	channel string
+
+	mu      sync.Mutex
+	clients map[string]map[*client]struct{}
+	history map[string][]localEvent
+
+	// boot and seq form local event IDs, unique across restarts.
+	boot string
+	seq  atomic.Int64
+}
+
+// NewHub creates a Hub. rdb may be nil, in which case events only reach
+// clients connected to this replica and only its own events can be resumed.
+func NewHub(rdb *redis.Client) *Hub {
+	return &Hub{
+		redis:   rdb,
+		channel: "radif:realtime",
+		clients: map[string]map[*client]struct{}{},
+		history: map[string][]localEvent{},
+		boot:    strconv.FormatInt(time.Now().UnixNano(), 36),
+	}
+}
+
+// Publish sends an event to every open connection of userID and keeps it for
+// resumption. Users without a connection catch up through the REST API.
+func (h *Hub) Publish(ctx context.Context, userID, eventType string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal %s event: %w", eventType, err)
+	}
+	env := envelope{UserID: userID, Event: Event{Type: eventType, Data: raw}}
+	if h.redis != nil {
+		err := h.publishRedis(ctx, &env)
+		if err == nil {
+			return nil
+		}
+		slog.WarnContext(ctx, "realtime: redis publish failed, delivering locally", "err", err)
+	}
+	env.Event.ID = h.boot + "-" + strconv.FormatInt(h.seq.Add(1), 10)
+	h.remember(userID, env.Event)
+	h.deliver(&env)
+	return nil
+}
+
+// publishRedis appends the event to the user's stream, which assigns its ID,
+// and announces it to every replica.
+func (h *Hub) publishRedis(ctx context.Context, env *envelope) error {
+	key := h.streamKey(env.UserID)
+	id, err := h.redis.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: historySize,
+		Approx: true,
+		Values: map[string]any{"type": env.Event.Type, "data": string(env.Event.Data)},
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("append to stream: %w", err)
+	}
+	env.Event.ID = id
+	h.redis.PExpire(ctx, key, historyTTL)
+
+	msg, err := json.Marshal(env)
+	if err != nil {
+		return fmt.Errorf("marshal envelope: %w", err)
+	}
+	return h.redis.Publish(ctx, h.channel, msg).Err()
+}
+
+// Since returns the kept events of userID published after the event with ID
+// lastID, oldest first. When lastID is no longer kept, every kept event is
+// returned and the client deduplicates on ID.
+func (h *Hub) Since(ctx context.Context, userID, lastID string) ([]Event, error) {
+	var events []Event
+	if h.redis != nil {
+		msgs, err := h.redis.XRange(ctx, h.streamKey(userID), "-", "+").Result()
+		if err != nil {
+			return nil, fmt.Errorf("read stream: %w", err)
+		}
+		for _, m := range msgs {
+			typ, _ := m.Values["type"].(string)
+			data, _ := m.Values["data"].(string)
+			events = append(events, Event{ID: m.ID, Type: typ, Data: json.RawMessage(data)})
+		}
+	}
+
+	h.mu.Lock()
+	for _, e := range h.history[userID] {
+		if time.Since(e.at) < historyTTL {
+			events = append(events, e.Event)
+		}
+	}
+	h.mu.Unlock()
+
+	for i, e := range events {
+		if e.ID == lastID {
+			return events[i+1:], nil
+		}
+	}
+	return events, nil
+}
+
+// Run relays events published by other replicas and expires local history
+// until ctx is cancelled, then closes every connection so clients reconnect
+// to a live replica.
+func (h *Hub) Run(ctx context.Context) {
+	slog.Info("realtime hub started")
+	if h.redis != nil {
+		go h.subscribe(ctx)
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			h.closeAll()
+			slog.Info("realtime hub stopped")
+			return
+		case <-ticker.C:
+			h.expireHistory()
+		}
+	}
+}
+
+// subscribe delivers events from the Redis channel to local clients. The
+// client resubscribes by itself after a Redis outage.
+func (h *Hub) subscribe(ctx context.Context) {
+	sub := h.redis.Subscribe(ctx, h.channel)
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			var env envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+				slog.WarnContext(ctx, "realtime: bad message on channel", "err", err)
+				continue
+			}
+			h.deliver(&env)
+		}
+	}
+}
+
+// deliver queues env's event on each local connection of its user.
+func (h *Hub) deliver(env *envelope) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for c := range h.clients[env.UserID] {
+		c.enqueue(&env.Event)
+	}
+}
+
+// remember keeps e in the local history of userID.
+func (h *Hub) remember(userID string, e Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hist := append(h.history[userID], localEvent{Event: e, at: time.Now()})
+	if len(hist) > historySize {
+		hist = hist[len(hist)-historySize:]
+	}
+	h.history[userID] = hist
+}
+
+// expireHistory drops local history of users with no recent events.
+func (h *Hub) expireHistory() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for userID, hist := range h.history {
+		if time.Since(hist[len(hist)-1].at) >= historyTTL {
+			delete(h.history, userID)
+		}
+	}
+}
+
+// closeAll closes every connection.
+func (h *Hub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, conns := range h.clients {
+		for c := range conns {
+			c.close(websocket.StatusGoingAway, "server shutting down")
+		}
+	}
+}
+
+// add registers c unless its user already has maxConnsPerUser connections,
+// and reports whether it did. Checking and registering under one lock keeps
+// concurrent connects from overshooting the cap.
+func (h *Hub) add(c *client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	conns := h.clients[c.userID]
+	if len(conns) >= maxConnsPerUser {
+		return false
+	}
+	if conns == nil {
+		conns = map[*client]struct{}{}
+		h.clients[c.userID] = conns
+	}
+	conns[c] = struct{}{}
+	return true
+}
+
+// remove unregisters c.
+func (h *Hub) remove(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients[c.userID], c)
+	if len(h.clients[c.userID]) == 0 {
+		delete(h.clients, c.userID)
+	}
+}
+
+// streamKey is the Redis stream holding userID's recent events.
+func (h *Hub) streamKey(userID string) string {
+	return h.channel + ":user:" + userID
+}
@@ -0,0 +1,115 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Stream godoc
+//
+//	@Summary		Stream real-time events over SSE
+//	@Description	Server-Sent Events mirror of GET /ws for clients behind proxies that block WebSockets. Each event has the event ID as "id", its type as "event" and its payload as "data". To resume after a disconnect, send the last ID seen in the Last-Event-ID header (or the lastEventId query parameter); events from the last hour, up to 100, are replayed first. A comment line is sent every 30 seconds to keep proxies from timing out, and the stream ends when the token expires. Authenticate with the Authorization header, e.g. through a fetch-based EventSource client.
+//	@Tags			realtime
+//	@Security		BearerAuth
+//	@Produce		text/event-stream
+//	@Param			Last-Event-ID	header	string	false	"ID of the last event received"
+//	@Param			lastEventId		query	string	false	"Same as Last-Event-ID, for clients that cannot set headers"
+//	@Success		200
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		429	{object}	response.Envelope
+//	@Router			/events [get]
+func (h *Handler) Stream(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	expiresAt, _ := r.Context().Value(middleware.TokenExpiryKey).(time.Time)
+	lastID := r.Header.Get("Last-Event-ID")
+	if lastID == "" {
+		lastID = r.URL.Query().Get("lastEventId")
+	}
+
+	// Register before writing headers, so the cap holds however many
+	// connects race. Subscribing before reading the backlog also means
+	// nothing published in between is lost; events seen in both are sent
+	// once.
+	c := newClient(userID)
+	if !h.hub.add(c) {
+		response.Error(w, http.StatusTooManyRequests, "too many open connections")
+		return
+	}
+	defer h.hub.remove(c)
+
+	// The stream outlives the server's write timeout; each write gets its
+	// own deadline instead, so a stalled client is dropped.
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+	var backlog []Event
+	if lastID != "" {
+		var err error
+		if backlog, err = h.hub.Since(r.Context(), userID, lastID); err != nil {
+			slog.WarnContext(r.Context(), "realtime: read backlog failed", "user_id", userID, "err", err)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.WriteString(w, "retry: 3000\n\n")
+
+	sent := make(map[string]bool, len(backlog))
+	for i := range backlog {
+		if writeSSE(w, &backlog[i]) != nil {
+			return
+		}
+		sent[backlog[i].ID] = true
+	}
+	if rc.Flush() != nil {
+		return
+	}
+
+	ctx := r.Context()
+	if !expiresAt.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, expiresAt)
+		defer cancel()
+	}
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		var err error
+		_ = rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+		select {
+		case <-c.done:
+			return
+		case <-ctx.Done():
+			return
+		case e := <-c.send:
+			if sent[e.ID] {
+				delete(sent, e.ID)
+				continue
+			}
+			err = writeSSE(w, e)
+		case <-ticker.C:
+			_, err = io.WriteString(w, ": ping\n\n")
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeSSE writes e as one event. Data is JSON on a single line.
+func writeSSE(w io.Writer, e *Event) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, strings.ReplaceAll(string(e.Data), "\n", ""))
+	return err
+}
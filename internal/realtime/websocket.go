@@ -0,0 +1,115 @@
+package realtime
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Connect godoc
+//
+//	@Summary		Open a real-time event stream
+//	@Description	Upgrades to a WebSocket that pushes JSON frames of the form {"id": "...", "type": "...", "data": {...}} for notification, payment_request and transfer events. The server pings every 30 seconds and closes the connection when the token expires. Events raised while disconnected are not replayed here; fetch them through the REST API or resume with GET /events. At most 5 connections per user across both endpoints.
+//	@Tags			realtime
+//	@Security		BearerAuth
+//	@Success		101
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		429	{object}	response.Envelope
+//	@Router			/ws [get]
+func (h *Handler) Connect(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(string)
+	expiresAt, _ := r.Context().Value(middleware.TokenExpiryKey).(time.Time)
+
+	// The connection outlives the server's read and write timeouts.
+	rc := http.NewResponseController(w)
+	_ = rc.SetReadDeadline(time.Time{})
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	// Register before upgrading, so the cap holds however many connects race.
+	c := newClient(userID)
+	if !h.hub.add(c) {
+		response.Error(w, http.StatusTooManyRequests, "too many open connections")
+		return
+	}
+	defer h.hub.remove(c)
+
+	// Clients authenticate with a bearer token rather than cookies, so a
+	// cross-origin page cannot open a connection on a user's behalf.
+	conn, err := websocket.Accept(hijackWriter{w, rc}, r, &websocket.AcceptOptions{OriginPatterns: []string{"*"}})
+	if err != nil {
+		slog.WarnContext(r.Context(), "realtime: accept failed", "err", err)
+		return
+	}
+
+	// The request context ends when the handler returns, not when the client
+	// goes away; CloseRead's context does both.
+	ctx := conn.CloseRead(context.WithoutCancel(r.Context()))
+	if !expiresAt.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, expiresAt)
+		defer cancel()
+	}
+	runWebSocket(ctx, c, conn)
+}
+
+// runWebSocket writes queued events and heartbeats until the client leaves,
+// the token expires or the hub closes the connection, then closes conn with
+// the reason the client was closed for.
+func runWebSocket(ctx context.Context, c *client, conn *websocket.Conn) {
+	defer func() { _ = conn.Close(c.closeCode, c.closeReason) }()
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ctx.Done():
+			code, reason := websocket.StatusNormalClosure, ""
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				code, reason = websocket.StatusPolicyViolation, "session expired"
+			}
+			c.close(code, reason)
+			return
+		case e := <-c.send:
+			frame, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			wctx, cancel := context.WithTimeout(ctx, writeTimeout)
+			err = conn.Write(wctx, websocket.MessageText, frame)
+			cancel()
+			if err != nil {
+				c.close(websocket.StatusAbnormalClosure, "write failed")
+				return
+			}
+		case <-ticker.C:
+			pctx, cancel := context.WithTimeout(ctx, pingTimeout)
+			err := conn.Ping(pctx)
+			cancel()
+			if err != nil {
+				c.close(websocket.StatusGoingAway, "heartbeat timeout")
+				return
+			}
+		}
+	}
+}
+
+// hijackWriter exposes the connection hijacking that middleware wrappers
+// hide behind Unwrap, which websocket.Accept needs.
+type hijackWriter struct {
+	http.ResponseWriter
+	rc *http.ResponseController
+}
+
+func (w hijackWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.rc.Hijack()
+}
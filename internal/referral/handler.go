@@ -0,0 +1,45 @@
+package referral
+
+import (
+	"net/http"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for referral endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new referral Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// Summary godoc
+//
+//	@Summary		Get referral code
+//	@Description	Returns the authenticated user's referral code (issued on first call) and how many users registered with it and converted.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=Summary}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/referral [get]
+func (h *Handler) Summary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	s, err := h.svc.Summary(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, s)
+}
@@ -0,0 +1,144 @@
+// Package referral manages per-user referral codes and tracks the users
+// who registered with them.
+package referral
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Summary is a user's referral code and how it has performed.
+type Summary struct {
+	Code      string `json:"code"      example:"K7M2QX9P"`
+	Invited   int    `json:"invited"   example:"4"`
+	Converted int    `json:"converted" example:"1"`
+}
+
+// ErrUnknownCode is returned when a referral code does not exist.
+var ErrUnknownCode = errors.New("unknown referral code")
+
+// errCodeTaken is returned when a generated code collides with an existing one.
+var errCodeTaken = errors.New("referral code taken")
+
+// Repository handles referral persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new referral Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// GetCode returns the user's referral code, or "" when none has been issued.
+func (r *Repository) GetCode(ctx context.Context, userID string) (string, error) {
+	var code string
+	err := r.db.QueryRow(ctx,
+		`SELECT code FROM referral_codes WHERE user_id = $1`, userID,
+	).Scan(&code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get referral code: %w", err)
+	}
+	return code, nil
+}
+
+// CreateCode stores code for the user and returns the user's code. If the
+// user already has one (a concurrent request won), the existing code is
+// returned instead.
+func (r *Repository) CreateCode(ctx context.Context, userID, code string) (string, error) {
+	var out string
+	err := r.db.QueryRow(ctx,
+		`WITH ins AS (
+		     INSERT INTO referral_codes (user_id, code) VALUES ($1, $2)
+		     ON CONFLICT (user_id) DO NOTHING
+		     RETURNING code
+		 )
+		 SELECT code FROM ins
+		 UNION ALL
+		 SELECT code FROM referral_codes WHERE user_id = $1
+		 LIMIT 1`,
+		userID, code,
+	).Scan(&out)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return "", errCodeTaken
+		}
+		return "", fmt.Errorf("create referral code: %w", err)
+	}
+	return out, nil
+}
+
+// ReferrerByCode returns the ID of the user who owns code.
+func (r *Repository) ReferrerByCode(ctx context.Context, code string) (string, error) {
+	var userID string
+	err := r.db.QueryRow(ctx,
+		`SELECT user_id FROM referral_codes WHERE code = $1`, code,
+	).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", ErrUnknownCode
+	}
+	if err != nil {
+		return "", fmt.Errorf("get referrer by code: %w", err)
+	}
+	return userID, nil
+}
+
+// Create records that refereeID registered with referrerID's code. A user can
+// only be referred once; later attempts are no-ops.
+func (r *Repository) Create(ctx context.Context, referrerID, refereeID string) error {
+	_, err := r.db.Exec(ctx,
+		`INSERT INTO referrals (referee_id, referrer_id) VALUES ($1, $2)
+		 ON CONFLICT (referee_id) DO NOTHING`,
+		refereeID, referrerID,
+	)
+	if err != nil {
+		return fmt.Errorf("create referral: %w", err)
+	}
+	return nil
+}
+
+// MarkConverted moves refereeID's referral from pending to converted and
+// returns the referrer's ID. ok is false when the user was not referred or
+// has already converted.
+func (r *Repository) MarkConverted(ctx context.Context, refereeID string) (referrerID string, ok bool, err error) {
+	err = r.db.QueryRow(ctx,
+		`UPDATE referrals SET status = 'converted', converted_at = NOW()
+		 WHERE referee_id = $1 AND status = 'pending'
+		 RETURNING referrer_id`,
+		refereeID,
+	).Scan(&referrerID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("mark referral converted: %w", err)
+	}
+	return referrerID, true, nil
+}
+
+// Counts returns how many users referrerID has invited and how many converted.
+func (r *Repository) Counts(ctx context.Context, referrerID string) (invited, converted int, err error) {
+	err = r.db.QueryRow(ctx,
+		`SELECT COUNT(*), COUNT(*) FILTER (WHERE status = 'converted')
+		 FROM referrals WHERE referrer_id = $1`,
+		referrerID,
+	).Scan(&invited, &converted)
+	if err != nil {
+		return 0, 0, fmt.Errorf("count referrals: %w", err)
+	}
+	return invited, converted, nil
+}
+
+// isUniqueViolation checks whether an error is a PostgreSQL unique_violation (code 23505).
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
@@ -0,0 +1,101 @@
+package referral
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// codeAlphabet omits characters that are easy to confuse when a code is read
+// aloud or typed from a screenshot (0/O, 1/I/L).
+const codeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+const (
+	codeLength   = 8
+	codeAttempts = 5
+)
+
+// Service contains business logic for referrals.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new referral Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Summary returns the user's referral code, issuing one on first use, along
+// with their invite counts.
+func (s *Service) Summary(ctx context.Context, userID string) (*Summary, error) {
+	code, err := s.code(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	invited, converted, err := s.repo.Counts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &Summary{Code: code, Invited: invited, Converted: converted}, nil
+}
+
+// Resolve returns the referrer who owns code. Codes are case-insensitive.
+func (s *Service) Resolve(ctx context.Context, code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return "", ErrUnknownCode
+	}
+	return s.repo.ReferrerByCode(ctx, code)
+}
+
+// Record attributes a newly registered user to referrerID.
+func (s *Service) Record(ctx context.Context, referrerID, refereeID string) error {
+	if referrerID == refereeID {
+		return nil
+	}
+	return s.repo.Create(ctx, referrerID, refereeID)
+}
+
+// MarkConverted records that refereeID completed their first transaction and
+// returns the referrer to reward. The transaction flow calls this once the
+// first payment settles; ok is false when there is nothing to convert.
+func (s *Service) MarkConverted(ctx context.Context, refereeID string) (referrerID string, ok bool, err error) {
+	return s.repo.MarkConverted(ctx, refereeID)
+}
+
+// code returns the user's referral code, generating one if needed.
+func (s *Service) code(ctx context.Context, userID string) (string, error) {
+	code, err := s.repo.GetCode(ctx, userID)
+	if err != nil || code != "" {
+		return code, err
+	}
+	for i := 0; i < codeAttempts; i++ {
+		candidate, err := generateCode()
+		if err != nil {
+			return "", err
+		}
+		code, err = s.repo.CreateCode(ctx, userID, candidate)
+		if errors.Is(err, errCodeTaken) {
+			continue
+		}
+		return code, err
+	}
+	return "", fmt.Errorf("generate referral code: %d collisions", codeAttempts)
+}
+
+// generateCode returns a random code drawn from codeAlphabet.
+func generateCode() (string, error) {
+	b := make([]byte, codeLength)
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("generate referral code: %w", err)
+		}
+		b[i] = codeAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
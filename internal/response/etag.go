@@ -0,0 +1,44 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WeakETag returns a weak entity tag for resource id last changed at
+// updatedAt. Weak because the body may differ byte for byte, e.g. by field
+// projection or key casing, while meaning the same. The id keeps resources
+// served at one URL apart, such as /users/me for two accounts on a device.
+func WeakETag(id string, updatedAt time.Time) string {
+	return `W/"` + id + "-" + strconv.FormatInt(updatedAt.UnixMicro(), 36) + `"`
+}
+
+// NotModified sets etag on the response and reports whether the request's
+// If-None-Match already holds it, in which case it has written a 304 and the
+// handler must not write a body.
+func NotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	if !etagMatch(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatch applies the weak comparison of RFC 9110 §13.1.2 to each tag in
+// an If-None-Match value.
+func etagMatch(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package response
+
+// Page is the data of a paginated list response. Pass NextCursor back as
+// the cursor query parameter for the next page; it is empty on the last
+// page. Total is set only by endpoints that count the whole list.
+//
+// Document a page as response.Envelope{data=response.Page{items=[]Item}}.
+type Page struct {
+	Items      interface{} `json:"items"`
+	NextCursor string      `json:"nextCursor,omitempty" example:"MjAyNi0wMi0yN1QxNDo0ODozNFp8ZTdlZWRjNzk"`
+	Total      *int        `json:"total,omitempty" example:"42"`
+}
+
+// NewPage returns a page of items, which must be a slice; a nil slice is
+// sent as [] rather than null.
+func NewPage[T any](items []T, nextCursor string) *Page {
+	if items == nil {
+		items = []T{}
+	}
+	return &Page{Items: items, NextCursor: nextCursor}
+}
+
+// WithTotal sets the total number of items across all pages.
+func (p *Page) WithTotal(total int) *Page {
+	p.Total = &total
+	return p
+}
@@ -0,0 +1,188 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const maxProjectionFields = 50
+
+var fieldNameRegex = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+// ErrInvalidProjection is returned when the fields or casing query parameter is malformed.
+var ErrInvalidProjection = errors.New("invalid fields or casing parameter")
+
+// Projection is a client-requested response shape:
+//
+//	?fields=id,username,avatarUrl      keep only these top-level fields
+//	?fields=items.id,items.amount      dotted paths select nested fields; arrays are projected per element
+//	?casing=snake                      emit snake_case keys instead of the default camelCase
+//
+// Field names always refer to the default camelCase names, regardless of casing.
+type Projection struct {
+	fields fieldTree
+	snake  bool
+}
+
+// fieldTree is a set of selected keys; a nil subtree selects the whole value.
+type fieldTree map[string]fieldTree
+
+// ParseProjection reads the fields and casing query parameters. It returns nil
+// when the request asks for the default shape.
+func ParseProjection(r *http.Request) (*Projection, error) {
+	q := r.URL.Query()
+	p := &Projection{}
+
+	switch q.Get("casing") {
+	case "", "camel":
+	case "snake":
+		p.snake = true
+	default:
+		return nil, ErrInvalidProjection
+	}
+
+	if raw := q.Get("fields"); raw != "" {
+		names := strings.Split(raw, ",")
+		if len(names) > maxProjectionFields {
+			return nil, ErrInvalidProjection
+		}
+		p.fields = fieldTree{}
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			if !fieldNameRegex.MatchString(name) {
+				return nil, ErrInvalidProjection
+			}
+			p.fields.add(strings.Split(name, "."))
+		}
+	}
+
+	if p.fields == nil && !p.snake {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// add inserts a dotted path into the tree. Selecting a parent wholesale wins
+// over selecting some of its children.
+func (t fieldTree) add(path []string) {
+	head := path[0]
+	if len(path) == 1 {
+		t[head] = nil
+		return
+	}
+	sub, exists := t[head]
+	if exists && sub == nil {
+		return
+	}
+	if sub == nil {
+		sub = fieldTree{}
+		t[head] = sub
+	}
+	sub.add(path[1:])
+}
+
+// Apply reshapes data according to the projection. data is round-tripped
+// through JSON so struct tags (omitempty, "-") are respected.
+func (p *Projection) Apply(data interface{}) (interface{}, error) {
+	if p == nil {
+		return data, nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+	if p.fields != nil {
+		generic = selectFields(generic, p.fields)
+	}
+	if p.snake {
+		generic = snakeKeys(generic)
+	}
+	return generic, nil
+}
+
+// selectFields keeps only the keys present in tree, recursing into objects and arrays.
+func selectFields(v interface{}, tree fieldTree) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(tree))
+		for key, sub := range tree {
+			child, ok := val[key]
+			if !ok {
+				continue
+			}
+			if sub == nil {
+				out[key] = child
+			} else {
+				out[key] = selectFields(child, sub)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = selectFields(item, tree)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// snakeKeys converts every object key from camelCase to snake_case.
+func snakeKeys(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for key, child := range val {
+			out[toSnake(key)] = snakeKeys(child)
+		}
+		return out
+	case []interface{}:
+		for i, item := range val {
+			val[i] = snakeKeys(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// toSnake converts "avatarUrl" to "avatar_url".
+func toSnake(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// OKProjected writes a 200 response with data reshaped by the request's
+// fields/casing parameters, or a 400 when they are malformed.
+func OKProjected(w http.ResponseWriter, r *http.Request, data interface{}) {
+	p, err := ParseProjection(r)
+	if err != nil {
+		BadRequest(w, "invalid fields or casing query parameter")
+		return
+	}
+	projected, err := p.Apply(data)
+	if err != nil {
+		InternalError(w)
+		return
+	}
+	OK(w, projected)
+}
@@ -4,13 +4,30 @@ package response
 import (
 	"encoding/json"
 	"net/http"
+
+	"github.com/radif/service/internal/i18n"
 )
 
-// Envelope is the standard API response envelope.
+// Envelope is the standard API response envelope. Error is localized to the
+// client's language; Code identifies it independently of language. Errors
+// lists the failing fields of a request that did not pass validation.
+// RequestID, set on errors, is the ID the request is logged under.
 type Envelope struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success   bool         `json:"success"`
+	Data      interface{}  `json:"data,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	Code      string       `json:"code,omitempty" example:"invalid_request_body"`
+	Errors    []FieldError `json:"errors,omitempty"`
+	RequestID string       `json:"requestId,omitempty" example:"9f1c2b7e4d8a4f6e8b3c5a7d1e2f4a6b"`
+}
+
+// FieldError describes one request field that failed validation. Message
+// is filled in the client's language from Code and Args when written.
+type FieldError struct {
+	Field   string `json:"field"   example:"phone"`
+	Code    string `json:"code"    example:"invalid_phone"`
+	Message string `json:"message" example:"invalid phone number format"`
+	Args    []any  `json:"-"`
 }
 
 // JSON writes a JSON-encoded payload with the given HTTP status code.
@@ -30,9 +47,66 @@ func Created(w http.ResponseWriter, data interface{}) {
 	JSON(w, http.StatusCreated, Envelope{Success: true, Data: data})
 }
 
-// Error writes an error response with the given status and message.
+// Error writes an error response with the given status and English message.
+// Messages in the i18n catalog are sent with their code, in the language
+// negotiated by middleware.Language; others are sent as is.
 func Error(w http.ResponseWriter, status int, message string) {
-	JSON(w, status, Envelope{Success: false, Error: message})
+	code := i18n.Code(message)
+	if code != "" {
+		message = i18n.Message(language(w), code)
+	}
+	JSON(w, status, Envelope{Success: false, Error: message, Code: code, RequestID: requestID(w)})
+}
+
+// Localized writes an error response with the catalog message code,
+// formatted with args, for messages that carry values.
+func Localized(w http.ResponseWriter, status int, code string, args ...any) {
+	JSON(w, status, Envelope{Success: false, Error: i18n.Message(language(w), code, args...), Code: code, RequestID: requestID(w)})
+}
+
+// ValidationFailed writes a 400 response listing the fields in errs.
+func ValidationFailed(w http.ResponseWriter, errs []FieldError) {
+	lang := language(w)
+	for i := range errs {
+		errs[i].Message = i18n.Message(lang, errs[i].Code, errs[i].Args...)
+	}
+	JSON(w, http.StatusBadRequest, Envelope{
+		Success:   false,
+		Error:     i18n.Message(lang, "validation_failed"),
+		Code:      "validation_failed",
+		Errors:    errs,
+		RequestID: requestID(w),
+	})
+}
+
+// language returns the language set on w by middleware.Language, looking
+// through wrapping writers, or English.
+func language(w http.ResponseWriter) string {
+	for {
+		if l, ok := w.(interface{ Language() string }); ok {
+			return l.Language()
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return i18n.LangEn
+		}
+		w = u.Unwrap()
+	}
+}
+
+// requestID returns the request ID set on w by middleware.RequestID,
+// looking through wrapping writers, or "".
+func requestID(w http.ResponseWriter) string {
+	for {
+		if r, ok := w.(interface{ RequestID() string }); ok {
+			return r.RequestID()
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return ""
+		}
+		w = u.Unwrap()
+	}
 }
 
 // BadRequest writes a 400 response.
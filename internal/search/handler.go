@@ -0,0 +1,98 @@
+package search
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/radif/service/internal/imaging"
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+	"github.com/radif/service/internal/storage"
+)
+
+// Handler holds HTTP handlers for search endpoints.
+type Handler struct {
+	svc   *Service
+	store storage.Storage
+}
+
+// NewHandler creates a new search Handler.
+func NewHandler(svc *Service, store storage.Storage) *Handler {
+	return &Handler{svc: svc, store: store}
+}
+
+// Users godoc
+//
+//	@Summary		Search users
+//	@Description	Search discoverable users and businesses by username (prefix) or name. Users who blocked the caller are left out, so a page may be shorter than limit.
+//	@Tags			search
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			q			query		string	true	"Search text (at least 2 characters)"
+//	@Param			type		query		string	false	"Account type filter"	Enums(personal, business)
+//	@Param			category	query		string	false	"Business category code (e.g. 5812)"
+//	@Param			limit		query		int		false	"Page size (1-50, default 20)"
+//	@Param			offset		query		int		false	"Offset (default 0)"
+//	@Success		200			{object}	response.Envelope{data=[]Hit}
+//	@Failure		400			{object}	response.Envelope
+//	@Failure		401			{object}	response.Envelope
+//	@Failure		500			{object}	response.Envelope
+//	@Router			/search/users [get]
+func (h *Handler) Users(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	params := r.URL.Query()
+	q := Query{
+		Text:        strings.TrimSpace(params.Get("q")),
+		AccountType: params.Get("type"),
+		Category:    params.Get("category"),
+		Limit:       20,
+	}
+	if n := utf8.RuneCountInString(q.Text); n < MinQueryLength || n > 100 {
+		response.BadRequest(w, "q must be between 2 and 100 characters")
+		return
+	}
+	if q.AccountType != "" && q.AccountType != "personal" && q.AccountType != "business" {
+		response.BadRequest(w, "type must be one of: personal, business")
+		return
+	}
+	if v := params.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 50 {
+			response.BadRequest(w, "limit must be between 1 and 50")
+			return
+		}
+		q.Limit = n
+	}
+	if v := params.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			response.BadRequest(w, "offset must be a non-negative integer")
+			return
+		}
+		q.Offset = n
+	}
+
+	hits, err := h.svc.Users(r.Context(), userID, q)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	for _, hit := range hits {
+		if hit.AvatarKey != nil && *hit.AvatarKey != "" {
+			url := h.store.PublicURL(*hit.AvatarKey)
+			hit.AvatarURL = &url
+			if hit.AvatarVariants {
+				hit.AvatarURLs = imaging.VariantURLs(*hit.AvatarKey, h.store.PublicURL)
+			}
+		}
+	}
+	response.OK(w, hits)
+}
@@ -0,0 +1,63 @@
+// Package search puts user and business search behind an Index interface so
+// the engine can change without touching callers. Postgres (trigram matching
+// on the users table) is the default; Meilisearch is an optional driver kept
+// in sync by a background Reindexer.
+package search
+
+import (
+	"context"
+	"time"
+)
+
+// Driver names accepted by SEARCH_DRIVER.
+const (
+	DriverPostgres    = "postgres"
+	DriverMeilisearch = "meilisearch"
+)
+
+// Document is a user as stored in an external index.
+type Document struct {
+	ID               string    `json:"id"`
+	AccountType      string    `json:"accountType"`
+	Username         *string   `json:"username"`
+	FullName         *string   `json:"fullName"`
+	BusinessCategory *string   `json:"businessCategory"`
+	AvatarKey        *string   `json:"avatarKey"`
+	AvatarVariants   bool      `json:"avatarVariants"`
+	Discoverable     bool      `json:"discoverable"`
+	Verified         bool      `json:"verified"`
+	UpdatedAt        time.Time `json:"-"`
+}
+
+// Query describes a search. Empty filters match everything.
+type Query struct {
+	Text        string
+	AccountType string
+	Category    string
+	Limit       int
+	Offset      int
+}
+
+// Hit is a search result.
+type Hit struct {
+	ID               string  `json:"id"`
+	AccountType      string  `json:"accountType"`
+	Username         *string `json:"username,omitempty"`
+	FullName         *string `json:"fullName,omitempty"`
+	BusinessCategory *string `json:"businessCategory,omitempty"`
+	Verified         bool    `json:"verified"`
+	AvatarKey        *string `json:"-"`
+	AvatarVariants   bool    `json:"-"`
+	AvatarURL        *string `json:"avatarUrl,omitempty"`
+	// AvatarURLs maps a square size in pixels to a resized copy of the avatar.
+	AvatarURLs map[string]string `json:"avatarUrls,omitempty"`
+}
+
+// Index is a search engine over discoverable users.
+type Index interface {
+	// Search returns discoverable users matching q, best match first.
+	Search(ctx context.Context, q Query) ([]*Hit, error)
+	// Upsert adds or replaces documents. Non-discoverable documents must be
+	// stored too, so that turning discovery off removes a user from results.
+	Upsert(ctx context.Context, docs []*Document) error
+}
@@ -0,0 +1,122 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// meiliIndexUID is the Meilisearch index holding user documents.
+const meiliIndexUID = "users"
+
+// MeilisearchIndex is an Index backed by a Meilisearch server. Writes are
+// asynchronous on the server side, so results lag upserts by a moment.
+type MeilisearchIndex struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewMeilisearchIndex creates a Meilisearch-backed Index.
+func NewMeilisearchIndex(baseURL, apiKey string) *MeilisearchIndex {
+	return &MeilisearchIndex{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// EnsureSettings configures which attributes are searchable and filterable.
+// It is idempotent and runs before the first reindex.
+func (m *MeilisearchIndex) EnsureSettings(ctx context.Context) error {
+	return m.do(ctx, http.MethodPatch, "/indexes/"+meiliIndexUID+"/settings", map[string]any{
+		"searchableAttributes": []string{"username", "fullName"},
+		"filterableAttributes": []string{"discoverable", "accountType", "businessCategory"},
+	}, nil)
+}
+
+// Search queries the index, restricted to discoverable users.
+func (m *MeilisearchIndex) Search(ctx context.Context, q Query) ([]*Hit, error) {
+	filter := []string{"discoverable = true"}
+	if q.AccountType != "" {
+		filter = append(filter, fmt.Sprintf("accountType = %q", q.AccountType))
+	}
+	if q.Category != "" {
+		filter = append(filter, fmt.Sprintf("businessCategory = %q", q.Category))
+	}
+
+	var res struct {
+		Hits []*Document `json:"hits"`
+	}
+	err := m.do(ctx, http.MethodPost, "/indexes/"+meiliIndexUID+"/search", map[string]any{
+		"q":      q.Text,
+		"filter": filter,
+		"limit":  q.Limit,
+		"offset": q.Offset,
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]*Hit, 0, len(res.Hits))
+	for _, d := range res.Hits {
+		hits = append(hits, &Hit{
+			ID:               d.ID,
+			AccountType:      d.AccountType,
+			Username:         d.Username,
+			FullName:         d.FullName,
+			BusinessCategory: d.BusinessCategory,
+			Verified:         d.Verified,
+			AvatarKey:        d.AvatarKey,
+			AvatarVariants:   d.AvatarVariants,
+		})
+	}
+	return hits, nil
+}
+
+// Upsert adds or replaces documents by ID.
+func (m *MeilisearchIndex) Upsert(ctx context.Context, docs []*Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	return m.do(ctx, http.MethodPost, "/indexes/"+meiliIndexUID+"/documents?primaryKey=id", docs, nil)
+}
+
+// do sends a JSON request and decodes the JSON response into out, if non-nil.
+func (m *MeilisearchIndex) do(ctx context.Context, method, path string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("meilisearch: encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("meilisearch: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("meilisearch: %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("meilisearch: %s %s: status %d: %s", method, path, resp.StatusCode, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("meilisearch: decode response: %w", err)
+	}
+	return nil
+}
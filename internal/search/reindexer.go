@@ -0,0 +1,88 @@
+package search
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	reindexInterval  = time.Minute
+	reindexBatchSize = 500
+	// reindexOverlap re-reads recent changes on every pass. updated_at is set
+	// at transaction start, so a slow transaction can commit a row stamped
+	// before the watermark has already moved past it.
+	reindexOverlap = 2 * time.Minute
+)
+
+// zeroUUID sorts before every user ID and starts a full pass.
+const zeroUUID = "00000000-0000-0000-0000-000000000000"
+
+// Reindexer keeps an external Index in sync with the users table. It does a
+// full pass on start, then picks up changes by updated_at.
+type Reindexer struct {
+	repo *Repository
+	idx  Index
+}
+
+// NewReindexer creates a Reindexer feeding idx.
+func NewReindexer(repo *Repository, idx Index) *Reindexer {
+	return &Reindexer{repo: repo, idx: idx}
+}
+
+// Run reindexes until ctx is cancelled.
+func (r *Reindexer) Run(ctx context.Context) {
+	slog.Info("search reindexer started")
+	ticker := time.NewTicker(reindexInterval)
+	defer ticker.Stop()
+
+	var watermark time.Time
+	for {
+		start := watermark.Add(-reindexOverlap)
+		if watermark.IsZero() {
+			start = time.Time{}
+		}
+		if next, err := r.pass(ctx, start); err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "search reindexer: pass failed", "err", err)
+			}
+		} else if next.After(watermark) {
+			watermark = next
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("search reindexer stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pass upserts every user changed since since and returns the newest
+// updated_at seen.
+func (r *Reindexer) pass(ctx context.Context, since time.Time) (time.Time, error) {
+	newest, afterID := since, zeroUUID
+	indexed := 0
+	for ctx.Err() == nil {
+		docs, err := r.repo.ChangedSince(ctx, newest, afterID, reindexBatchSize)
+		if err != nil {
+			return newest, err
+		}
+		if err := r.idx.Upsert(ctx, docs); err != nil {
+			return newest, err
+		}
+		indexed += len(docs)
+		if len(docs) > 0 {
+			last := docs[len(docs)-1]
+			newest, afterID = last.UpdatedAt, last.ID
+		}
+		if len(docs) < reindexBatchSize {
+			break
+		}
+	}
+	if indexed > 0 {
+		slog.InfoContext(ctx, "search reindexer: indexed users", "count", indexed)
+	}
+	return newest, ctx.Err()
+}
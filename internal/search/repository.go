@@ -0,0 +1,101 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/radif/service/internal/db"
+)
+
+// Repository reads searchable users from Postgres. It only reads, so it
+// can run on a read replica.
+type Repository struct {
+	db db.Querier
+}
+
+// NewRepository creates a new search Repository on q, the pool or a
+// db.Reader.
+func NewRepository(q db.Querier) *Repository {
+	return &Repository{db: q}
+}
+
+// ChangedSince returns up to limit users updated at or after since, oldest
+// first, for incremental reindexing. Deleted users come back as not
+// discoverable, so the index drops them.
+func (r *Repository) ChangedSince(ctx context.Context, since time.Time, afterID string, limit int) ([]*Document, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, account_type, username, full_name, business_category, avatar_key, avatar_variants, discoverable AND deleted_at IS NULL, verified_at IS NOT NULL, updated_at
+		 FROM users
+		 WHERE (updated_at, id) > ($1, $2::uuid)
+		 ORDER BY updated_at, id
+		 LIMIT $3`,
+		since, afterID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list changed users: %w", err)
+	}
+	defer rows.Close()
+
+	var docs []*Document
+	for rows.Next() {
+		d := &Document{}
+		if err := rows.Scan(&d.ID, &d.AccountType, &d.Username, &d.FullName, &d.BusinessCategory, &d.AvatarKey, &d.AvatarVariants, &d.Discoverable, &d.Verified, &d.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan changed user: %w", err)
+		}
+		docs = append(docs, d)
+	}
+	return docs, rows.Err()
+}
+
+// PostgresIndex searches the users table directly with trigram matching. It
+// is always current, so Upsert is a no-op.
+type PostgresIndex struct {
+	repo *Repository
+}
+
+// NewPostgresIndex creates the default Postgres-backed Index.
+func NewPostgresIndex(repo *Repository) *PostgresIndex {
+	return &PostgresIndex{repo: repo}
+}
+
+// Search matches usernames by prefix and names by substring, ranked by
+// trigram similarity.
+func (p *PostgresIndex) Search(ctx context.Context, q Query) ([]*Hit, error) {
+	rows, err := p.repo.db.Query(ctx,
+		`SELECT id, account_type, username, full_name, business_category, verified_at IS NOT NULL, avatar_key, avatar_variants
+		 FROM users
+		 WHERE discoverable AND deleted_at IS NULL
+		   AND (username ILIKE $1 || '%' OR full_name ILIKE '%' || $1 || '%')
+		   AND ($2 = '' OR account_type = $2)
+		   AND ($3 = '' OR business_category = $3)
+		 ORDER BY GREATEST(similarity(COALESCE(username, ''), $4), similarity(COALESCE(full_name, ''), $4)) DESC, id
+		 LIMIT $5 OFFSET $6`,
+		escapeLike(q.Text), q.AccountType, q.Category, q.Text, q.Limit, q.Offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("search users: %w", err)
+	}
+	defer rows.Close()
+
+	hits := []*Hit{}
+	for rows.Next() {
+		h := &Hit{}
+		if err := rows.Scan(&h.ID, &h.AccountType, &h.Username, &h.FullName, &h.BusinessCategory, &h.Verified, &h.AvatarKey, &h.AvatarVariants); err != nil {
+			return nil, fmt.Errorf("scan search hit: %w", err)
+		}
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
+
+// Upsert is a no-op: the users table is the index.
+func (p *PostgresIndex) Upsert(context.Context, []*Document) error {
+	return nil
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"context"
+	"strings"
+)
+
+// MinQueryLength is the shortest search text accepted, in characters.
+const MinQueryLength = 2
+
+// BlockChecker reports which candidates have blocked a user. It is satisfied
+// by block.Service.
+type BlockChecker interface {
+	BlockersAmong(ctx context.Context, userID string, candidateIDs []string) (map[string]bool, error)
+}
+
+// Service runs searches on behalf of a user.
+type Service struct {
+	idx    Index
+	blocks BlockChecker
+}
+
+// NewService creates a new search Service.
+func NewService(idx Index, blocks BlockChecker) *Service {
+	return &Service{idx: idx, blocks: blocks}
+}
+
+// Users searches discoverable users for userID, leaving out userID and
+// anyone who has blocked them. Filtering happens after the index query, so a
+// page may hold fewer than q.Limit hits.
+func (s *Service) Users(ctx context.Context, userID string, q Query) ([]*Hit, error) {
+	q.Text = strings.TrimSpace(q.Text)
+	hits, err := s.idx.Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(hits))
+	for _, h := range hits {
+		ids = append(ids, h.ID)
+	}
+	blockers, err := s.blocks.BlockersAmong(ctx, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	out := hits[:0]
+	for _, h := range hits {
+		if h.ID != userID && !blockers[h.ID] {
+			out = append(out, h)
+		}
+	}
+	return out, nil
+}
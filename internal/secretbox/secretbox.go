@@ -0,0 +1,78 @@
+// Package secretbox encrypts small secrets (third-party access tokens,
+// credentials) before they are stored, using AES-256-GCM.
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the required key length in bytes.
+const KeySize = 32
+
+// ErrDecrypt is returned when a ciphertext is malformed or was not sealed with this key.
+var ErrDecrypt = errors.New("secretbox: decryption failed")
+
+// Box seals and opens secrets with a single key.
+type Box struct {
+	aead     cipher.AEAD
+	indexKey []byte
+}
+
+// New creates a Box from a 32-byte key.
+func New(key []byte) (*Box, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secretbox: key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("secretbox: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("secretbox: %w", err)
+	}
+	// The index key is derived so that a leaked index never weakens the
+	// encryption key.
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("secretbox blind index"))
+	return &Box{aead: aead, indexKey: mac.Sum(nil)}, nil
+}
+
+// Seal encrypts plaintext, binding it to aad (e.g. the owning row's ID) so a
+// ciphertext copied to another row fails to open. The nonce is prepended.
+func (b *Box) Seal(plaintext, aad []byte) ([]byte, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("secretbox: nonce: %w", err)
+	}
+	return b.aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// Open decrypts a ciphertext produced by Seal with the same aad.
+func (b *Box) Open(ciphertext, aad []byte) ([]byte, error) {
+	n := b.aead.NonceSize()
+	if len(ciphertext) < n {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := b.aead.Open(nil, ciphertext[:n], ciphertext[n:], aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// Index returns a deterministic keyed hash of plaintext (a blind index), so
+// sealed values can be looked up or constrained unique without decrypting.
+// Unlike a plain hash it cannot be brute-forced for low-entropy values such
+// as national IDs without the key.
+func (b *Box) Index(plaintext []byte) []byte {
+	mac := hmac.New(sha256.New, b.indexKey)
+	mac.Write(plaintext)
+	return mac.Sum(nil)
+}
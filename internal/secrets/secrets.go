@@ -0,0 +1,94 @@
+// Package secrets loads credentials such as the JWT secret, database
+// password and provider API keys from a secret manager (HashiCorp Vault or
+// a SOPS-encrypted file) instead of plain environment variables, and keeps
+// them fresh as they are rotated.
+//
+// Secrets are named like the environment variables they replace, e.g.
+// JWT_SECRET or DATABASE_URL.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Source fetches the current set of secrets.
+type Source interface {
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// Store holds the secrets last fetched from a Source. A nil Store holds none.
+type Store struct {
+	src Source
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+// Open fetches the secrets once, failing if src cannot be read: the service
+// must not start on fallback credentials.
+func Open(ctx context.Context, src Source) (*Store, error) {
+	values, err := src.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetch secrets: %w", err)
+	}
+	return &Store{src: src, values: values}, nil
+}
+
+// Lookup returns the secret named key.
+func (s *Store) Lookup(key string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.values[key]
+	return v, ok && v != ""
+}
+
+// Refresh fetches the secrets again. On failure the previous values are
+// kept.
+func (s *Store) Refresh(ctx context.Context) error {
+	values, err := s.src.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("fetch secrets: %w", err)
+	}
+	s.mu.Lock()
+	s.values = values
+	s.mu.Unlock()
+	return nil
+}
+
+// Run refreshes the secrets every interval until ctx is cancelled, so
+// rotated credentials are picked up without a restart.
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil {
+				slog.ErrorContext(ctx, "secrets: refresh failed", "err", err)
+			}
+		}
+	}
+}
+
+// stringValues converts a decoded JSON object to secrets, rejecting values
+// that are not strings.
+func stringValues(obj map[string]any) (map[string]string, error) {
+	out := make(map[string]string, len(obj))
+	for k, v := range obj {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("secret %s is not a string", k)
+		}
+		out[k] = s
+	}
+	return out, nil
+}
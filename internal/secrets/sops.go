@@ -0,0 +1,39 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// SOPS reads secrets from a file encrypted with SOPS
+// (https://github.com/getsops/sops) by running the sops binary, which
+// resolves the age, PGP or cloud KMS key the file was encrypted with. The
+// file must hold a flat object of strings, in any format sops reads.
+type SOPS struct {
+	path string
+}
+
+// NewSOPS returns a Source decrypting the file at path.
+func NewSOPS(path string) *SOPS {
+	return &SOPS{path: path}
+}
+
+// Fetch implements Source.
+func (s *SOPS) Fetch(ctx context.Context) (map[string]string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sops", "--decrypt", "--output-type", "json", s.path)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("sops: decrypt %s: %w: %s", s.path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var obj map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &obj); err != nil {
+		return nil, fmt.Errorf("sops: decode %s: %w", s.path, err)
+	}
+	return stringValues(obj)
+}
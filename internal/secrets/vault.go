@@ -0,0 +1,64 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Vault reads secrets from one path of a HashiCorp Vault KV secrets engine,
+// version 1 or 2, over its HTTP API.
+type Vault struct {
+	addr   string
+	token  string
+	path   string
+	client *http.Client
+}
+
+// NewVault returns a Source reading path, e.g. "secret/data/radif/api" for
+// KV v2, from the Vault server at addr with token.
+func NewVault(addr, token, path string) *Vault {
+	return &Vault{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		path:   strings.Trim(path, "/"),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Fetch implements Source.
+func (v *Vault) Fetch(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+v.path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("vault: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var out struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("vault: decode response: %w", err)
+	}
+	// KV v2 nests the secret under data.data, next to its metadata.
+	if inner, ok := out.Data["data"].(map[string]any); ok {
+		if _, ok := out.Data["metadata"]; ok {
+			return stringValues(inner)
+		}
+	}
+	return stringValues(out.Data)
+}
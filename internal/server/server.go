@@ -0,0 +1,680 @@
+// Package server wires the API's services together and routes them, so
+// cmd/api and the integration tests serve the same routes through the same
+// middleware chain.
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	httpSwagger "github.com/swaggo/http-swagger/v2"
+
+	"github.com/radif/service/internal/apikey"
+	"github.com/radif/service/internal/audit"
+	"github.com/radif/service/internal/auditexport"
+	"github.com/radif/service/internal/auth"
+	"github.com/radif/service/internal/bankaccount"
+	"github.com/radif/service/internal/block"
+	"github.com/radif/service/internal/bootstrap"
+	"github.com/radif/service/internal/branch"
+	"github.com/radif/service/internal/business"
+	"github.com/radif/service/internal/cache"
+	"github.com/radif/service/internal/category"
+	"github.com/radif/service/internal/cdn"
+	"github.com/radif/service/internal/chaos"
+	"github.com/radif/service/internal/config"
+	"github.com/radif/service/internal/contact"
+	"github.com/radif/service/internal/conversation"
+	"github.com/radif/service/internal/cron"
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/device"
+	"github.com/radif/service/internal/events"
+	"github.com/radif/service/internal/expense"
+	"github.com/radif/service/internal/family"
+	"github.com/radif/service/internal/group"
+	"github.com/radif/service/internal/health"
+	"github.com/radif/service/internal/idempotency"
+	"github.com/radif/service/internal/kyc"
+	"github.com/radif/service/internal/maintenance"
+	"github.com/radif/service/internal/metrics"
+	appMiddleware "github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/moderation"
+	"github.com/radif/service/internal/notification"
+	"github.com/radif/service/internal/openbanking"
+	"github.com/radif/service/internal/paypage"
+	"github.com/radif/service/internal/realtime"
+	"github.com/radif/service/internal/referral"
+	"github.com/radif/service/internal/search"
+	"github.com/radif/service/internal/secretbox"
+	"github.com/radif/service/internal/storagegc"
+	"github.com/radif/service/internal/tracing"
+	"github.com/radif/service/internal/usage"
+	"github.com/radif/service/internal/user"
+	"github.com/radif/service/internal/webhook"
+)
+
+// Deps are the connections and shared services the API is built on.
+// Cache, Outbox, Moderation, CDN and Injector are optional; when nil, the
+// features using them are off.
+type Deps struct {
+	Config     *config.Config
+	Pool       *pgxpool.Pool
+	Reader     *db.Reader
+	Stores     *bootstrap.Stores
+	Metrics    *metrics.Metrics
+	Readiness  *health.Checker
+	Cache      *cache.Cache
+	Outbox     *events.Outbox
+	Moderation *moderation.Service
+	CDN        *cdn.Invalidator
+	Injector   *chaos.Injector
+}
+
+// API is the wired application: its router and the background work its
+// services need.
+type API struct {
+	// Router serves every route, behind the full middleware chain.
+	Router http.Handler
+
+	cfg             *config.Config
+	realtimeHub     *realtime.Hub
+	usageRecorder   *usage.Recorder
+	scheduler       *cron.Scheduler
+	webhookSvc      *webhook.Service
+	authSvc         *auth.Service
+	searchReindexer *search.Reindexer
+	storageGC       *storagegc.Collector
+}
+
+// New wires the API's repositories, services and handlers on d and routes
+// them.
+func New(d Deps) *API {
+	cfg, pool, reader := d.Config, d.Pool, d.Reader
+	txm := db.NewTxManager(pool)
+	appMetrics, readiness, stores := d.Metrics, d.Readiness, d.Stores
+	store, kycStore, businessStore := stores.Public, stores.KYC, stores.Business
+	redisCache, outbox, moderationSvc := d.Cache, d.Outbox, d.Moderation
+	cdnInvalidator, injector := d.CDN, d.Injector
+
+	// Wire dependencies: repository → service → handler
+	userRepo := user.NewRepository(pool, reader)
+	auditRepo := audit.NewRepository(pool)
+	auditLog := audit.NewLog(auditRepo)
+	auditExportHandler := auditexport.NewHandler(auditexport.NewService(auditexport.NewRepository(pool), txm, stores.Audit, bootstrap.AuditSigningKey(cfg)))
+	userSvc := user.NewService(userRepo, txm, redisCache, outbox, auditLog)
+	userHandler := user.NewHandler(userSvc, store, avatarModerator(moderationSvc), bootstrap.CacheInvalidator(cdnInvalidator))
+
+	box, err := secretbox.New(bootstrap.DataEncryptionKey(cfg))
+	if err != nil {
+		bootstrap.Fatal("invalid DATA_ENCRYPTION_KEY", "err", err)
+	}
+
+	bankAccountRepo := bankaccount.NewRepository(pool)
+	bankAccountSvc := bankaccount.NewService(bankAccountRepo, box, nil)
+	bankAccountHandler := bankaccount.NewHandler(bankAccountSvc)
+
+	// No bank provider is integrated yet; link and read endpoints answer 503.
+	openBankingRepo := openbanking.NewRepository(pool)
+	openBankingSvc := openbanking.NewService(openBankingRepo, bankAccountSvc, nil, box)
+	openBankingHandler := openbanking.NewHandler(openBankingSvc)
+
+	categoryRepo := category.NewRepository(pool)
+	categorySvc := category.NewService(categoryRepo)
+	categoryHandler := category.NewHandler(categorySvc)
+
+	deviceRepo := device.NewRepository(pool)
+	deviceSvc := device.NewService(deviceRepo)
+	deviceHandler := device.NewHandler(deviceSvc)
+	// No FCM/APNs provider is integrated yet; pushes are only logged.
+	pusher := device.NewPusher(deviceRepo, nil)
+
+	notificationRepo := notification.NewRepository(pool)
+	realtimeHub := realtime.NewHub(redisCache.Client())
+	realtimeHandler := realtime.NewHandler(realtimeHub)
+	notificationSvc := notification.NewService(notificationRepo, pusher, nil, realtimeHub)
+	notificationHandler := notification.NewHandler(notificationSvc)
+
+	// No Shahkar provider is integrated yet; phone ownership is left to reviewers.
+	kycRepo := kyc.NewRepository(pool)
+	kycSvc := kyc.NewService(kycRepo, box, nil, notificationSvc)
+	kycHandler := kyc.NewHandler(kycSvc, kycStore)
+
+	// Business licenses share the private KYC bucket.
+	businessRepo := business.NewRepository(pool)
+	businessSvc := business.NewService(businessRepo, notificationSvc)
+	businessHandler := business.NewHandler(businessSvc, businessStore)
+
+	blockRepo := block.NewRepository(pool)
+	blockSvc := block.NewService(blockRepo)
+	blockHandler := block.NewHandler(blockSvc)
+
+	branchRepo := branch.NewRepository(pool)
+	branchSvc := branch.NewService(branchRepo, blockSvc)
+	branchHandler := branch.NewHandler(branchSvc)
+
+	payPageRepo := paypage.NewRepository(pool)
+	payPageSvc := paypage.NewService(payPageRepo)
+	payPageHandler := paypage.NewHandler(payPageSvc, store)
+
+	var storageGC *storagegc.Collector
+	if cfg.StorageGCEnabled {
+		storageGC = storagegc.NewCollector(storagegc.NewRepository(pool), stores.GCTargets, storagegc.Options{
+			MinAge:   cfg.StorageGCMinAge,
+			Interval: cfg.StorageGCInterval,
+			DryRun:   cfg.StorageGCDryRun,
+		})
+	}
+
+	groupRepo := group.NewRepository(pool)
+	groupSvc := group.NewService(groupRepo, blockSvc)
+	groupHandler := group.NewHandler(groupSvc, store, groupAvatarModerator(moderationSvc), bootstrap.CacheInvalidator(cdnInvalidator))
+
+	expenseRepo := expense.NewRepository(pool)
+	expenseSvc := expense.NewService(expenseRepo, groupSvc)
+	expenseHandler := expense.NewHandler(expenseSvc)
+
+	conversationRepo := conversation.NewRepository(pool, reader)
+	conversationSvc := conversation.NewService(conversationRepo, blockSvc, notificationSvc)
+	conversationHandler := conversation.NewHandler(conversationSvc)
+
+	searchRepo := search.NewRepository(reader)
+	var searchIndex search.Index = search.NewPostgresIndex(searchRepo)
+	var searchReindexer *search.Reindexer
+	switch cfg.SearchDriver {
+	case search.DriverPostgres:
+	case search.DriverMeilisearch:
+		meili := search.NewMeilisearchIndex(cfg.MeilisearchURL, cfg.MeilisearchKey)
+		if err := meili.EnsureSettings(context.Background()); err != nil {
+			slog.Error("meilisearch settings failed", "err", err)
+		}
+		searchIndex = meili
+		searchReindexer = search.NewReindexer(searchRepo, meili)
+	default:
+		bootstrap.Fatal("unknown SEARCH_DRIVER", "value", cfg.SearchDriver)
+	}
+	searchSvc := search.NewService(searchIndex, blockSvc)
+	searchHandler := search.NewHandler(searchSvc, store)
+
+	contactRepo := contact.NewRepository(pool)
+	contactSvc := contact.NewService(contactRepo)
+	contactHandler := contact.NewHandler(contactSvc, store)
+
+	maintenanceRepo := maintenance.NewRepository(pool)
+	maintenanceSvc := maintenance.NewService(maintenanceRepo)
+	maintenanceHandler := maintenance.NewHandler(maintenanceSvc)
+
+	webhookRepo := webhook.NewRepository(pool)
+	webhookSvc := webhook.NewService(webhookRepo, webhook.NewSender(!cfg.IsProduction()), cfg.IsProduction())
+	webhookHandler := webhook.NewHandler(webhookSvc)
+
+	referralRepo := referral.NewRepository(pool)
+	referralSvc := referral.NewService(referralRepo)
+	referralHandler := referral.NewHandler(referralSvc)
+
+	authRepo := auth.NewRepository(pool)
+	authSvc := auth.NewService(authRepo, txm, userSvc, notificationSvc, referralSvc, bootstrap.SMSDispatcher(injector), redisCache, appMetrics, outbox, auditLog, cfg)
+	authHandler := auth.NewHandler(authSvc)
+
+	// Server-to-server credentials for partner and internal services
+	apiKeySvc := apikey.NewService(apikey.NewRepository(pool))
+	apiKeyHandler := apikey.NewHandler(apiKeySvc)
+
+	familyRepo := family.NewRepository(pool)
+	familySvc := family.NewService(familyRepo, authSvc, notificationSvc)
+	familyHandler := family.NewHandler(familySvc)
+
+	usageRepo := usage.NewRepository(pool)
+	usageRecorder := usage.NewRecorder(usageRepo)
+	usageSvc := usage.NewService(usageRepo)
+	usageHandler := usage.NewHandler(usageSvc)
+	trackUsage := appMiddleware.TrackUsage(usageRecorder)
+
+	// Everything done with an impersonation token lands in the audit log.
+	authenticate := appMiddleware.RequireAuth(cfg.JWTVerificationKeys, authSvc, userSvc)
+	requireAuth := func(next http.Handler) http.Handler {
+		return authenticate(auditLog.Impersonated(next))
+	}
+
+	rateLimitStore := newRateLimitStore(cfg, redisCache)
+	rateLimit := func(p appMiddleware.RateLimitPolicy) func(http.Handler) http.Handler {
+		return appMiddleware.RateLimit(rateLimitStore, p)
+	}
+	// Uploads are the costliest writes: decoding, re-encoding and storage.
+	limitUploads := rateLimit(appMiddleware.RateLimitPolicy{Name: "uploads", Rate: 20, Per: time.Minute, Burst: 5, By: appMiddleware.ByUser})
+
+	idempotencyRepo := idempotency.NewRepository(pool)
+	idempotent := appMiddleware.Idempotency(idempotencyRepo, cfg.IdempotencyTTL)
+	idempotentShort := appMiddleware.Idempotency(idempotencyRepo, cfg.IdempotencyShortTTL)
+
+	// Periodic cleanup runs on one instance at a time; see package cron.
+	scheduler := cron.NewScheduler(pool,
+		cron.Job{Name: "otp-purge", Interval: time.Hour, Run: authSvc.PurgeExpiredOTPs},
+		cron.Job{Name: "contact-lookups-purge", Interval: 24 * time.Hour, Run: contactSvc.PurgeLookups},
+		cron.Job{Name: "idempotency-purge", Interval: time.Hour, Run: func(ctx context.Context) error {
+			_, err := idempotencyRepo.DeleteExpired(ctx)
+			return err
+		}},
+	)
+
+	ipResolver, err := appMiddleware.NewClientIPResolver(cfg.TrustedProxies)
+	if err != nil {
+		bootstrap.Fatal("invalid TRUSTED_PROXIES", "err", err)
+	}
+
+	// Router
+	r := chi.NewRouter()
+	r.Use(appMiddleware.RequestID)
+	r.Use(appMiddleware.RealIP(ipResolver))
+	r.Use(appMiddleware.SecurityHeaders(cfg.HSTSMaxAge))
+	r.Use(appMiddleware.Trace(tracing.Tracer()))
+	r.Use(appMiddleware.Logger)
+	r.Use(appMiddleware.Instrument(appMetrics))
+	r.Use(appMiddleware.Language)
+	r.Use(appMiddleware.Compress(cfg.CompressionLevel, cfg.CompressionMinBytes))
+	r.Use(appMiddleware.DefaultCacheControl)
+	r.Use(appMiddleware.Recover)
+	r.Use(appMiddleware.CORS(cfg.CORSAllowedOrigins))
+	// Event streams stay open for as long as the client listens.
+	r.Use(appMiddleware.Timeout(cfg.RequestTimeout, "/api/v1/ws", "/api/v1/events"))
+
+	// Health checks. /health is kept for existing monitors; orchestrators
+	// should probe /healthz for liveness and /readyz for readiness.
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	})
+	r.Get("/healthz", readiness.Live)
+	r.Get("/readyz", readiness.Ready)
+
+	// Prometheus scrape endpoint, guarded by METRICS_TOKEN when set.
+	r.Handle("/metrics", appMetrics.Handler(cfg.MetricsToken))
+
+	stores.Mount(r)
+
+	// Swagger UI — available at http://localhost:8080/swagger/
+	r.Get("/swagger/*", httpSwagger.Handler(
+		httpSwagger.URL("/swagger/doc.json"),
+	))
+
+	// API v1
+	r.Route("/api/v1", func(r chi.Router) {
+		// Public auth endpoints
+		r.Route("/auth", func(r chi.Router) {
+			// OTPs cost an SMS each and are guessable in bulk, so the public
+			// auth flow shares one budget per IP.
+			r.Use(rateLimit(appMiddleware.RateLimitPolicy{Name: "auth", Rate: 30, Per: time.Minute, Burst: 10, By: appMiddleware.ByIP}))
+			// Idempotency keys are scoped per user, so nothing here takes
+			// them: anonymous clients would share one key space. Routes that
+			// issue tokens would also keep bearer tokens in idempotency_keys.
+			// OTPs and registration tokens are single-use, so a retry fails
+			// instead of double-applying, and resends are rate limited.
+			r.Post("/otp/send", authHandler.SendOTP)
+			r.Post("/otp/verify", authHandler.VerifyOTP)
+			r.Post("/otp/resend", authHandler.ResendOTP)
+			r.Post("/register", authHandler.Register)
+
+			// Onboarding validates handles before the account exists, so this
+			// check is unauthenticated and limited per IP against enumeration.
+			r.With(
+				rateLimit(appMiddleware.RateLimitPolicy{Name: "username-check", Rate: 20, Per: time.Minute, By: appMiddleware.ByIP}),
+				appMiddleware.Cache(appMiddleware.CachePublic(30*time.Second)),
+			).Get("/username-check", userHandler.PublicCheckUsername)
+
+			// Limited-capability tokens can only be minted from a full session,
+			// and never from an impersonated one.
+			r.With(
+				requireAuth,
+				trackUsage,
+				appMiddleware.RequireScope(appMiddleware.ScopeAll),
+				appMiddleware.DenyImpersonation,
+			).Post("/tokens", authHandler.IssueScopedToken)
+
+			// Any token may revoke itself, limited ones included.
+			r.With(requireAuth, trackUsage).Post("/logout", authHandler.Logout)
+		})
+
+		// Hosted payment pages are public so businesses can share the link
+		// with anyone; limited per IP against scraping.
+		r.With(
+			rateLimit(appMiddleware.RateLimitPolicy{Name: "pay-page", Rate: 60, Per: time.Minute, By: appMiddleware.ByIP}),
+			appMiddleware.Cache(appMiddleware.CachePublic(time.Minute)),
+		).Get("/pay/business/{username}", payPageHandler.Public)
+
+		// Protected user endpoints
+		r.Route("/users", func(r chi.Router) {
+			r.Use(requireAuth)
+			r.Use(trackUsage)
+
+			r.Group(func(r chi.Router) {
+				r.Use(appMiddleware.RequireScope(appMiddleware.ScopeProfileRead))
+				r.With(appMiddleware.Cache(appMiddleware.CacheRevalidate)).Get("/me", userHandler.GetMe)
+				r.Get("/me/activity", usageHandler.MyActivity)
+				r.Get("/username-check", userHandler.CheckUsername)
+				r.With(appMiddleware.Cache(appMiddleware.CachePrivate(time.Minute))).Get("/businesses", userHandler.ListBusinesses)
+				r.Get("/me/blocks", blockHandler.List)
+				r.Get("/me/referral", referralHandler.Summary)
+				r.Get("/me/pay-page", payPageHandler.Own)
+				r.Get("/me/gallery", userHandler.Gallery)
+				r.Get("/me/branches", branchHandler.List)
+				r.Get("/me/branches/{id}", branchHandler.Get)
+				r.Get("/me/branches/{id}/staff", branchHandler.Staff)
+				r.Get("/me/branch-assignments", branchHandler.Assignments)
+				r.With(appMiddleware.Cache(appMiddleware.CacheRevalidate)).Get("/{id}", userHandler.GetPublicProfile)
+			})
+
+			r.With(
+				appMiddleware.RequireScope(appMiddleware.ScopeAll),
+				appMiddleware.DenyImpersonation,
+			).Delete("/me", userHandler.DeleteMe)
+
+			r.Group(func(r chi.Router) {
+				r.Use(appMiddleware.RequireScope(appMiddleware.ScopeProfileWrite))
+				r.With(idempotentShort).Patch("/me", userHandler.UpdateProfile)
+				r.With(limitUploads, idempotent).Post("/me/avatar", userHandler.UploadAvatar)
+				r.With(limitUploads).Post("/me/avatar/presign", userHandler.PresignAvatar)
+				r.With(limitUploads, idempotentShort).Post("/me/avatar/confirm", userHandler.ConfirmAvatar)
+				r.With(limitUploads, idempotent).Post("/me/cover", userHandler.UploadCover)
+				r.Delete("/me/cover", userHandler.DeleteCover)
+				r.With(limitUploads, idempotent).Post("/me/gallery", userHandler.AddGalleryImage)
+				r.Delete("/me/gallery/{id}", userHandler.DeleteGalleryImage)
+				r.Post("/{id}/block", blockHandler.Block)
+				r.Delete("/{id}/block", blockHandler.Unblock)
+				r.With(idempotentShort).Put("/me/pay-page", payPageHandler.Save)
+				r.With(idempotentShort).Post("/me/branches", branchHandler.Create)
+				r.With(idempotentShort).Patch("/me/branches/{id}", branchHandler.Update)
+				r.With(idempotentShort).Delete("/me/branches/{id}", branchHandler.Delete)
+				r.With(idempotentShort).Post("/me/branches/{id}/staff", branchHandler.AssignStaff)
+				r.Delete("/me/branches/{id}/staff/{userId}", branchHandler.UnassignStaff)
+			})
+
+			// Identity and business verification need a full session, never a limited token.
+			r.Group(func(r chi.Router) {
+				r.Use(appMiddleware.RequireScope(appMiddleware.ScopeAll))
+				r.Get("/me/kyc", kycHandler.Get)
+				r.Get("/me/kyc/tier", kycHandler.Tier)
+				r.With(idempotentShort).Post("/me/kyc", kycHandler.Submit)
+				r.With(idempotent).Post("/me/kyc/document", kycHandler.UploadDocument)
+				r.Get("/me/business-verification", businessHandler.Get)
+				r.With(idempotentShort).Post("/me/business-verification", businessHandler.Submit)
+				r.With(idempotent).Post("/me/business-verification/document", businessHandler.UploadDocument)
+			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(appMiddleware.RequireScope(appMiddleware.ScopeNotifications))
+				r.Get("/me/notification-settings", notificationHandler.Settings)
+				r.With(idempotentShort).Patch("/me/notification-settings", notificationHandler.UpdateSettings)
+				r.Get("/me/devices", deviceHandler.List)
+				r.Put("/me/devices", deviceHandler.Register)
+				r.Delete("/me/devices/{id}", deviceHandler.Remove)
+			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(appMiddleware.RequireScope(appMiddleware.ScopeBankAccountsRead))
+				r.Get("/me/bank-accounts", bankAccountHandler.List)
+				r.Get("/me/bank-links", openBankingHandler.List)
+				r.Get("/me/bank-links/{id}/balance", openBankingHandler.Balance)
+				r.Get("/me/bank-links/{id}/transactions", openBankingHandler.Transactions)
+			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(appMiddleware.RequireScope(appMiddleware.ScopeBankAccountsWrite))
+				// Payout accounts decide where money goes.
+				r.Use(appMiddleware.DenyImpersonation)
+				r.With(idempotent).Post("/me/bank-accounts", bankAccountHandler.Create)
+				r.With(idempotentShort).Post("/me/bank-accounts/{id}/default", bankAccountHandler.SetDefault)
+				r.With(idempotentShort).Delete("/me/bank-accounts/{id}", bankAccountHandler.Delete)
+				r.With(idempotentShort).Post("/me/bank-accounts/{id}/link", openBankingHandler.Link)
+				r.With(idempotentShort).Delete("/me/bank-links/{id}", openBankingHandler.Revoke)
+			})
+		})
+
+		// Real-time events for open apps, over WebSocket or, where proxies
+		// block it, Server-Sent Events.
+		r.Group(func(r chi.Router) {
+			r.Use(requireAuth)
+			r.Use(appMiddleware.RequireScope(appMiddleware.ScopeNotifications))
+			r.Get("/ws", realtimeHandler.Connect)
+			r.Get("/events", realtimeHandler.Stream)
+		})
+
+		// In-app notification inbox
+		r.Route("/notifications", func(r chi.Router) {
+			r.Use(requireAuth)
+			r.Use(trackUsage)
+			r.Use(appMiddleware.RequireScope(appMiddleware.ScopeNotifications))
+			r.Get("/", notificationHandler.List)
+			r.Get("/unread-count", notificationHandler.UnreadCount)
+			r.Post("/read-all", notificationHandler.MarkAllRead)
+			r.Post("/{id}/read", notificationHandler.MarkRead)
+		})
+
+		// Parent–child account links; granting oversight of an account needs a
+		// full session.
+		r.Route("/family", func(r chi.Router) {
+			r.Use(requireAuth)
+			r.Use(trackUsage)
+			r.Use(appMiddleware.RequireScope(appMiddleware.ScopeAll))
+			r.Get("/invitations", familyHandler.Invitations)
+			r.With(idempotentShort).Post("/invitations", familyHandler.Invite)
+			r.Delete("/invitations/{id}", familyHandler.Cancel)
+			r.With(idempotentShort).Post("/invitations/{id}/accept", familyHandler.Accept)
+			r.Post("/invitations/{id}/decline", familyHandler.Decline)
+			r.Get("/links", familyHandler.Links)
+			r.Delete("/links/{id}", familyHandler.Unlink)
+		})
+
+		// Groups: the container for shared expenses, group chats and group payments
+		r.Route("/groups", func(r chi.Router) {
+			r.Use(requireAuth)
+			r.Use(trackUsage)
+			r.Use(appMiddleware.RequireScope(appMiddleware.ScopeGroups))
+			r.Get("/", groupHandler.List)
+			r.With(idempotentShort).Post("/", groupHandler.Create)
+			r.Post("/join", groupHandler.Join)
+			r.Get("/{id}", groupHandler.Get)
+			r.With(idempotentShort).Patch("/{id}", groupHandler.Update)
+			r.Delete("/{id}", groupHandler.Delete)
+			r.With(limitUploads, idempotent).Post("/{id}/avatar", groupHandler.UploadAvatar)
+			r.Get("/{id}/members", groupHandler.Members)
+			r.Post("/{id}/members", groupHandler.AddMember)
+			r.Patch("/{id}/members/{userId}", groupHandler.SetRole)
+			r.Delete("/{id}/members/{userId}", groupHandler.RemoveMember)
+			r.Post("/{id}/invite-link", groupHandler.RotateInvite)
+			r.Delete("/{id}/invite-link", groupHandler.DisableInvite)
+			r.Get("/{id}/expenses", expenseHandler.List)
+			r.With(idempotent).Post("/{id}/expenses", expenseHandler.Add)
+			r.Delete("/{id}/expenses/{expenseId}", expenseHandler.Delete)
+			r.Get("/{id}/balances", expenseHandler.Balances)
+		})
+
+		// 1:1 message threads
+		r.Route("/conversations", func(r chi.Router) {
+			r.Use(requireAuth)
+			r.Use(trackUsage)
+			r.Use(appMiddleware.RequireScope(appMiddleware.ScopeMessages))
+			r.Get("/", conversationHandler.List)
+			r.Post("/", conversationHandler.Start)
+			r.Get("/unread-count", conversationHandler.UnreadCount)
+			r.Get("/{id}", conversationHandler.Get)
+			r.Get("/{id}/messages", conversationHandler.Messages)
+			r.With(idempotentShort).Post("/{id}/messages", conversationHandler.Send)
+			r.Post("/{id}/read", conversationHandler.MarkRead)
+		})
+
+		// User and business search
+		r.With(
+			requireAuth,
+			trackUsage,
+			appMiddleware.RequireScope(appMiddleware.ScopeProfileRead),
+		).Get("/search/users", searchHandler.Users)
+
+		// Address-book contact sync
+		r.Route("/contacts", func(r chi.Router) {
+			r.Use(requireAuth)
+			r.Use(trackUsage)
+			r.Use(appMiddleware.RequireScope(appMiddleware.ScopeContacts))
+			r.Get("/", contactHandler.List)
+			// Hashes can enumerate the phone number space, so syncing is
+			// limited per user on top of the daily quota in contact.Service.
+			r.With(rateLimit(appMiddleware.RateLimitPolicy{Name: "contacts-sync", Rate: 10, Per: time.Hour, Burst: 5, By: appMiddleware.ByUser})).
+				Post("/sync", contactHandler.Sync)
+		})
+
+		// Public business category taxonomy
+		r.With(appMiddleware.Cache(appMiddleware.CachePublic(5*time.Minute), "Accept-Language")).Get("/categories", categoryHandler.List)
+
+		// Public PSP/bank maintenance announcements
+		r.With(appMiddleware.Cache(appMiddleware.CachePublic(time.Minute), "Accept-Language")).Get("/maintenance-windows", maintenanceHandler.List)
+
+		// Merchant webhooks
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(requireAuth)
+			r.Use(trackUsage)
+			r.Use(appMiddleware.RequireScope(appMiddleware.ScopeWebhooks))
+			r.Get("/endpoints", webhookHandler.ListEndpoints)
+			r.Post("/endpoints", webhookHandler.CreateEndpoint)
+			r.Delete("/endpoints/{id}", webhookHandler.DeleteEndpoint)
+			r.Get("/endpoints/{id}/keys", webhookHandler.ListKeys)
+			r.Post("/endpoints/{id}/keys/rotate", webhookHandler.RotateKey)
+			r.Delete("/endpoints/{id}/keys/{keyId}", webhookHandler.ExpireKey)
+			r.Post("/endpoints/{id}/test", webhookHandler.TestFire)
+			r.Get("/endpoints/{id}/deliveries", webhookHandler.ListDeliveries)
+			r.Post("/endpoints/{id}/redeliver", webhookHandler.Redeliver)
+			r.Get("/deliveries/{id}", webhookHandler.GetDelivery)
+			r.Post("/deliveries/{id}/replay", webhookHandler.ReplayDelivery)
+		})
+
+		// Server-to-server routes for partner and internal services,
+		// authenticated by API key rather than a user token. PSP status
+		// monitors announce outages as they detect them.
+		r.Route("/integrations", func(r chi.Router) {
+			r.Use(rateLimit(appMiddleware.RateLimitPolicy{Name: "integrations", Rate: 60, Per: time.Minute, By: appMiddleware.ByIP}))
+			r.Group(func(r chi.Router) {
+				r.Use(appMiddleware.RequireAPIKey(apiKeySvc, appMiddleware.ScopeMaintenanceWrite))
+				r.Post("/maintenance-windows", maintenanceHandler.Schedule)
+				r.Delete("/maintenance-windows/{id}", maintenanceHandler.Cancel)
+			})
+		})
+
+		// Staff-only administration
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(requireAuth)
+			r.Use(trackUsage)
+			r.Use(appMiddleware.RequireRole(appMiddleware.RoleAdmin, userSvc))
+			r.Use(appMiddleware.RequireScope(appMiddleware.ScopeAll))
+			r.Use(auditLog.Admin)
+			r.Get("/audit-logs", audit.NewHandler(auditRepo).List)
+			r.Get("/audit-exports", auditExportHandler.List)
+			r.Post("/audit-exports", auditExportHandler.Create)
+			r.Get("/audit-exports/{id}/download", auditExportHandler.Download)
+			r.Get("/users/{id}/activity", usageHandler.UserActivity)
+			r.Post("/users/{id}/impersonate", authHandler.Impersonate)
+			r.Delete("/users/{id}", userHandler.AdminDelete)
+			r.Post("/users/{id}/restore", userHandler.Restore)
+			r.Get("/users/{id}/history", userHandler.History)
+			r.Get("/api-keys", apiKeyHandler.List)
+			r.Post("/api-keys", apiKeyHandler.Create)
+			r.Post("/api-keys/{id}/rotate", apiKeyHandler.Rotate)
+			r.Delete("/api-keys/{id}", apiKeyHandler.Revoke)
+			r.Get("/categories", categoryHandler.AdminList)
+			r.Post("/categories", categoryHandler.Create)
+			r.Patch("/categories/{code}", categoryHandler.Update)
+			r.Delete("/categories/{code}", categoryHandler.Delete)
+			r.Post("/maintenance-windows", maintenanceHandler.Schedule)
+			r.Delete("/maintenance-windows/{id}", maintenanceHandler.Cancel)
+			r.Get("/kyc", kycHandler.Queue)
+			r.Post("/kyc/{userId}/approve", kycHandler.Approve)
+			r.Post("/kyc/{userId}/reject", kycHandler.Reject)
+			r.Get("/business-verifications", businessHandler.Queue)
+			r.Post("/business-verifications/{userId}/approve", businessHandler.Approve)
+			r.Post("/business-verifications/{userId}/reject", businessHandler.Reject)
+			if moderationSvc != nil {
+				moderationHandler := moderation.NewHandler(moderationSvc)
+				r.Get("/moderation", moderationHandler.Queue)
+				r.Post("/moderation/{id}/approve", moderationHandler.Approve)
+				r.Post("/moderation/{id}/reject", moderationHandler.Reject)
+			}
+			if storageGC != nil {
+				r.Get("/storage-gc", storagegc.NewHandler(storageGC).Stats)
+			}
+			if injector != nil {
+				chaosHandler := chaos.NewHandler(injector)
+				r.Get("/chaos", chaosHandler.Get)
+				r.Put("/chaos", chaosHandler.Set)
+			}
+		})
+	})
+
+	return &API{
+		Router:          r,
+		cfg:             cfg,
+		realtimeHub:     realtimeHub,
+		usageRecorder:   usageRecorder,
+		scheduler:       scheduler,
+		webhookSvc:      webhookSvc,
+		authSvc:         authSvc,
+		searchReindexer: searchReindexer,
+		storageGC:       storageGC,
+	}
+}
+
+// Start runs the API's background work until ctx is cancelled. Realtime
+// fan-out and usage recording always run; the scheduler and queue workers
+// only with RUN_WORKERS, as cmd/worker runs them otherwise.
+func (a *API) Start(ctx context.Context) {
+	go a.realtimeHub.Run(ctx)
+	go a.usageRecorder.Run(ctx)
+	if !a.cfg.RunWorkers {
+		return
+	}
+	go a.scheduler.Run(ctx)
+	go webhook.NewWorker(a.webhookSvc).Run(ctx)
+	go auth.NewWorker(a.authSvc).Run(ctx)
+	if a.searchReindexer != nil {
+		go a.searchReindexer.Run(ctx)
+	}
+	if a.storageGC != nil {
+		go a.storageGC.Run(ctx)
+	}
+}
+
+// newRateLimitStore opens the configured rate-limit bucket store.
+func newRateLimitStore(cfg *config.Config, c *cache.Cache) appMiddleware.RateLimitStore {
+	switch cfg.RateLimitStore {
+	case "memory":
+		if cfg.IsProduction() {
+			slog.Warn("in-memory rate limits are per replica; set RATE_LIMIT_STORE=redis")
+		}
+		return appMiddleware.NewMemoryRateLimitStore()
+	case "redis":
+		if c == nil {
+			bootstrap.Fatal("RATE_LIMIT_STORE=redis requires REDIS_URL")
+		}
+		return appMiddleware.NewRedisRateLimitStore(c.Client(), "radif:rl:")
+	default:
+		bootstrap.Fatal("unknown RATE_LIMIT_STORE (want memory or redis)", "value", cfg.RateLimitStore)
+		return nil
+	}
+}
+
+// avatarModerator returns svc as a user.AvatarModerator, keeping a nil
+// service a nil interface.
+func avatarModerator(svc *moderation.Service) user.AvatarModerator {
+	if svc == nil {
+		return nil
+	}
+	return svc
+}
+
+// groupAvatarModerator returns svc as a group.AvatarModerator, keeping a nil
+// service a nil interface.
+func groupAvatarModerator(svc *moderation.Service) group.AvatarModerator {
+	if svc == nil {
+		return nil
+	}
+	return svc
+}
@@ -0,0 +1,53 @@
+// Package sms sends text messages through one or more SMS providers,
+// failing over between them in the configured order.
+package sms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// ErrAllProvidersFailed is returned when every configured provider failed to
+// accept a message.
+var ErrAllProvidersFailed = errors.New("sms: all providers failed")
+
+// Provider is an SMS gateway integration (e.g. Kavenegar, SMS.ir).
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, phone, text string) error
+}
+
+// Dispatcher sends through the first provider that accepts the message.
+type Dispatcher struct {
+	providers []Provider
+}
+
+// NewDispatcher creates a Dispatcher trying providers in order.
+func NewDispatcher(providers ...Provider) *Dispatcher {
+	return &Dispatcher{providers: providers}
+}
+
+// Enabled reports whether any provider is configured.
+func (d *Dispatcher) Enabled() bool {
+	return len(d.providers) > 0
+}
+
+// Send delivers text to phone, failing over to the next provider on error.
+// It returns an error wrapping ErrAllProvidersFailed when none succeeded.
+func (d *Dispatcher) Send(ctx context.Context, phone, text string) error {
+	var last error
+	for _, p := range d.providers {
+		err := p.Send(ctx, phone, text)
+		if err == nil {
+			return nil
+		}
+		slog.WarnContext(ctx, "sms: provider failed", "provider", p.Name(), "err", err)
+		last = err
+	}
+	if last == nil {
+		return fmt.Errorf("%w: no providers configured", ErrAllProvidersFailed)
+	}
+	return fmt.Errorf("%w: %v", ErrAllProvidersFailed, last)
+}
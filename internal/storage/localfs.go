@@ -0,0 +1,289 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// uploadTempPrefix names files Upload is still writing; List skips them.
+const uploadTempPrefix = ".upload-"
+
+// maxLocalPutBytes caps a single presigned PUT to a LocalFS bucket.
+const maxLocalPutBytes = 32 << 20
+
+// LocalFS implements Storage on the local filesystem, one directory per
+// bucket, so the service runs without MinIO in development and CI. It is also
+// an http.Handler that serves the bucket: mount it (with the URL prefix
+// stripped) at baseURL. Presigned and signed URLs carry an HMAC checked by
+// that handler.
+type LocalFS struct {
+	dir     string
+	baseURL string
+	public  bool
+	secret  []byte
+}
+
+// NewLocalFS creates the directory if needed and returns a LocalFS whose
+// objects are readable by anyone at baseURL/{key}, like a public-read bucket.
+func NewLocalFS(dir, baseURL string, secret []byte) (*LocalFS, error) {
+	return newLocalFS(dir, baseURL, true, secret)
+}
+
+// NewPrivateLocalFS is like NewLocalFS but only serves objects through
+// SignedURL links. PublicURL returns "" for it.
+func NewPrivateLocalFS(dir, baseURL string, secret []byte) (*LocalFS, error) {
+	return newLocalFS(dir, baseURL, false, secret)
+}
+
+func newLocalFS(dir, baseURL string, public bool, secret []byte) (*LocalFS, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create storage directory %q: %w", dir, err)
+	}
+	return &LocalFS{
+		dir:     dir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		public:  public,
+		secret:  secret,
+	}, nil
+}
+
+// Upload writes reader to the file for key, replacing it atomically.
+func (s *LocalFS) Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("put object %q: %w", key, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), uploadTempPrefix+"*")
+	if err != nil {
+		return fmt.Errorf("put object %q: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, reader); err != nil {
+		tmp.Close()
+		return fmt.Errorf("put object %q: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("put object %q: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("put object %q: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the file for key. Deleting a missing object is not an error.
+func (s *LocalFS) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("remove object %q: %w", key, err)
+	}
+	return nil
+}
+
+// PublicURL returns baseURL/{key}, or "" for a private LocalFS.
+func (s *LocalFS) PublicURL(key string) string {
+	if !s.public {
+		return ""
+	}
+	return s.baseURL + "/" + key
+}
+
+// PresignPut returns a URL that accepts a PUT of key until expiry.
+func (s *LocalFS) PresignPut(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	return s.signedURL(http.MethodPut, key, expiry), nil
+}
+
+// SignedURL returns a URL that serves key until ttl passes.
+func (s *LocalFS) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if _, err := s.path(key); err != nil {
+		return "", err
+	}
+	return s.signedURL(http.MethodGet, key, ttl), nil
+}
+
+// Get opens the file for key.
+func (s *LocalFS) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get object %q: %w", key, err)
+	}
+	return f, nil
+}
+
+// Stat returns the size of the file for key and its content type, sniffed
+// from the first bytes since the filesystem keeps no metadata.
+func (s *LocalFS) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrObjectNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("stat object %q: %w", key, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("stat object %q: %w", key, err)
+	}
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("stat object %q: %w", key, err)
+	}
+	return &ObjectInfo{
+		Key:          key,
+		Size:         info.Size(),
+		ContentType:  http.DetectContentType(buf[:n]),
+		LastModified: info.ModTime(),
+	}, nil
+}
+
+// List walks the bucket directory, skipping uploads still being written.
+func (s *LocalFS) List(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error {
+	err := filepath.WalkDir(s.dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), uploadTempPrefix) {
+			return nil
+		}
+		rel, err := filepath.Rel(s.dir, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil // deleted while walking
+		}
+		if err != nil {
+			return err
+		}
+		return fn(&ObjectInfo{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+	})
+	if err != nil {
+		return fmt.Errorf("list objects: %w", err)
+	}
+	return nil
+}
+
+// ServeHTTP serves GET and HEAD for objects, and PUT for presigned uploads.
+// r.URL.Path must be the object key.
+func (s *LocalFS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	p, err := s.path(key)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if !s.public && !s.validSignature(http.MethodGet, key, r.URL.Query()) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, key, info.ModTime(), f)
+	case http.MethodPut:
+		if !s.validSignature(http.MethodPut, key, r.URL.Query()) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		body := http.MaxBytesReader(w, r.Body, maxLocalPutBytes)
+		if err := s.Upload(r.Context(), key, body, r.ContentLength, r.Header.Get("Content-Type")); err != nil {
+			http.Error(w, "upload failed", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.Header().Set("Allow", "GET, HEAD, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// path maps key to a file under dir, rejecting keys that would escape it.
+func (s *LocalFS) path(key string) (string, error) {
+	if key == "" || !filepath.IsLocal(key) {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// signedURL builds baseURL/{key}?expires=...&signature=... for method.
+func (s *LocalFS) signedURL(method, key string, ttl time.Duration) string {
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	q := url.Values{
+		"expires":   {expires},
+		"signature": {s.sign(method, key, expires)},
+	}
+	return s.baseURL + "/" + key + "?" + q.Encode()
+}
+
+// validSignature reports whether q carries an unexpired signature for method and key.
+func (s *LocalFS) validSignature(method, key string, q url.Values) bool {
+	expires := q.Get("expires")
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > exp {
+		return false
+	}
+	return hmac.Equal([]byte(q.Get("signature")), []byte(s.sign(method, key, expires)))
+}
+
+func (s *LocalFS) sign(method, key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	// The base URL binds the signature to this bucket.
+	mac.Write([]byte(method + "\n" + s.baseURL + "\n" + key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -5,8 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"strings"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -24,6 +25,32 @@ type MinioStorage struct {
 // NewMinioStorage creates a MinIO client, ensures the bucket exists with a public-read
 // policy, and returns a ready-to-use MinioStorage.
 func NewMinioStorage(endpoint, accessKey, secretKey, bucket, publicBase string, useSSL bool) (*MinioStorage, error) {
+	client, err := newMinioBucket(endpoint, accessKey, secretKey, bucket, useSSL, publicReadPolicy(bucket))
+	if err != nil {
+		return nil, err
+	}
+	return &MinioStorage{
+		client:     client,
+		bucket:     bucket,
+		publicBase: strings.TrimRight(publicBase, "/"),
+	}, nil
+}
+
+// NewPrivateMinioStorage is like NewMinioStorage but removes any bucket policy,
+// so objects are only reachable with the service credentials. Use it for
+// sensitive files such as identity documents, and hand out SignedURL links to
+// them. PublicURL returns "" for it.
+func NewPrivateMinioStorage(endpoint, accessKey, secretKey, bucket string, useSSL bool) (*MinioStorage, error) {
+	client, err := newMinioBucket(endpoint, accessKey, secretKey, bucket, useSSL, "")
+	if err != nil {
+		return nil, err
+	}
+	return &MinioStorage{client: client, bucket: bucket}, nil
+}
+
+// newMinioBucket creates a client and ensures bucket exists with the given
+// policy; an empty policy removes any existing one.
+func newMinioBucket(endpoint, accessKey, secretKey, bucket string, useSSL bool, policy string) (*minio.Client, error) {
 	client, err := minio.New(endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(accessKey, secretKey, ""),
 		Secure: useSSL,
@@ -42,18 +69,13 @@ func NewMinioStorage(endpoint, accessKey, secretKey, bucket, publicBase string,
 		if err := client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{}); err != nil {
 			return nil, fmt.Errorf("create bucket %q: %w", bucket, err)
 		}
-		log.Printf("storage: created bucket %q", bucket)
+		slog.Info("storage: created bucket", "bucket", bucket)
 	}
 
-	if err := client.SetBucketPolicy(ctx, bucket, publicReadPolicy(bucket)); err != nil {
+	if err := client.SetBucketPolicy(ctx, bucket, policy); err != nil {
 		return nil, fmt.Errorf("set bucket policy: %w", err)
 	}
-
-	return &MinioStorage{
-		client:     client,
-		bucket:     bucket,
-		publicBase: strings.TrimRight(publicBase, "/"),
-	}, nil
+	return client, nil
 }
 
 // Upload streams reader to MinIO under key. size must be the exact byte count
@@ -73,10 +95,78 @@ func (s *MinioStorage) Delete(ctx context.Context, key string) error {
 	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
 }
 
+// PresignPut returns a presigned PUT URL for key, valid until expiry. The URL
+// points at the storage endpoint, which clients must be able to reach.
+func (s *MinioStorage) PresignPut(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	u, err := s.client.PresignedPutObject(ctx, s.bucket, key, expiry)
+	if err != nil {
+		return "", fmt.Errorf("presign put %q: %w", key, err)
+	}
+	return u.String(), nil
+}
+
+// SignedURL returns a presigned GET URL for key, valid for ttl. Like
+// PresignPut, it points at the storage endpoint.
+func (s *MinioStorage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.bucket, key, ttl, nil)
+	if err != nil {
+		return "", fmt.Errorf("presign get %q: %w", key, err)
+	}
+	return u.String(), nil
+}
+
+// Get opens the object at key. MinIO fetches lazily, so the object is
+// statted first to report a missing key as ErrObjectNotFound.
+func (s *MinioStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get object %q: %w", key, err)
+	}
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("get object %q: %w", key, err)
+	}
+	return obj, nil
+}
+
+// Stat returns the size and content type of the object at key.
+func (s *MinioStorage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("stat object %q: %w", key, err)
+	}
+	return &ObjectInfo{Key: key, Size: info.Size, ContentType: info.ContentType, LastModified: info.LastModified}, nil
+}
+
+// List walks the bucket recursively under prefix.
+func (s *MinioStorage) List(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel() // stops the listing goroutine if fn returns early
+
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return fmt.Errorf("list objects: %w", obj.Err)
+		}
+		if err := fn(&ObjectInfo{Key: obj.Key, Size: obj.Size, LastModified: obj.LastModified}); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
 // PublicURL returns the browser-accessible URL for the given key.
 // For local MinIO: "http://localhost:9000/avatars/user-id/file.jpg"
 // For ArvanCloud CDN: "https://cdn.radif.ir/user-id/file.jpg"
 func (s *MinioStorage) PublicURL(key string) string {
+	if s.publicBase == "" {
+		return ""
+	}
 	return s.publicBase + "/" + key
 }
 
@@ -5,9 +5,22 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"io"
+	"time"
 )
 
+// ErrObjectNotFound is returned by Stat when no object exists at the key.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object. List leaves ContentType empty.
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
 // Storage is the interface for uploading and retrieving objects.
 type Storage interface {
 	// Upload streams data to the store under the given key.
@@ -16,4 +29,19 @@ type Storage interface {
 	Delete(ctx context.Context, key string) error
 	// PublicURL constructs the browser-accessible URL for a given key.
 	PublicURL(key string) string
+	// PresignPut returns a URL that lets a client PUT the object at key
+	// directly to the store until expiry.
+	PresignPut(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// SignedURL returns a URL that grants read access to the object at key
+	// until ttl passes, for buckets that are not world-readable.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// Get opens the object at key for reading, or returns ErrObjectNotFound.
+	// The caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Stat returns the size and content type of the object at key, or
+	// ErrObjectNotFound.
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// List calls fn for every object whose key starts with prefix, stopping
+	// at the first error fn returns.
+	List(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error
 }
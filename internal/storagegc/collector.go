@@ -0,0 +1,251 @@
+package storagegc
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/radif/service/internal/imaging"
+	"github.com/radif/service/internal/storage"
+)
+
+const (
+	// sweepBatchSize is how many candidate objects are checked per query.
+	sweepBatchSize = 500
+	// MinAgeFloor is the smallest accepted minimum age. Uploads are written
+	// to storage before their key is saved, and presigned uploads stay open
+	// for minutes, so younger objects may be referenced soon.
+	MinAgeFloor = 24 * time.Hour
+)
+
+// Target is a bucket to collect and the columns that reference its objects.
+type Target struct {
+	Name  string
+	Store storage.Storage
+	Refs  []Ref
+	// Owners returns the keys whose reference keeps the object at key alive.
+	// Nil means only the key itself.
+	Owners func(key string) []string
+}
+
+// Options configures a Collector.
+type Options struct {
+	// MinAge keeps objects younger than this. Values below MinAgeFloor are raised to it.
+	MinAge time.Duration
+	// Interval is the time between passes.
+	Interval time.Duration
+	// DryRun counts orphans without deleting them.
+	DryRun bool
+}
+
+// BucketStats are the results of the last pass over one bucket.
+type BucketStats struct {
+	Scanned    int64  `json:"scanned"`
+	Candidates int64  `json:"candidates"`
+	Orphaned   int64  `json:"orphaned"`
+	Deleted    int64  `json:"deleted"`
+	Failed     int64  `json:"failed"`
+	BytesFreed int64  `json:"bytesFreed"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Stats summarizes the collector's activity since the process started.
+type Stats struct {
+	DryRun       bool                    `json:"dryRun"`
+	MinAge       string                  `json:"minAge"     example:"168h0m0s"`
+	LastRunAt    *time.Time              `json:"lastRunAt,omitempty"`
+	LastDuration string                  `json:"lastDuration,omitempty" example:"1.2s"`
+	LastRun      map[string]*BucketStats `json:"lastRun"`
+	Runs         int64                   `json:"runs"`
+	TotalDeleted int64                   `json:"totalDeleted"`
+	TotalFreed   int64                   `json:"totalBytesFreed"`
+}
+
+// Collector periodically deletes unreferenced objects older than MinAge.
+type Collector struct {
+	repo    *Repository
+	targets []Target
+	opts    Options
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// NewCollector creates a Collector over targets.
+func NewCollector(repo *Repository, targets []Target, opts Options) *Collector {
+	opts.MinAge = max(opts.MinAge, MinAgeFloor)
+	if opts.Interval <= 0 {
+		opts.Interval = 24 * time.Hour
+	}
+	return &Collector{
+		repo:    repo,
+		targets: targets,
+		opts:    opts,
+		stats: Stats{
+			DryRun:  opts.DryRun,
+			MinAge:  opts.MinAge.String(),
+			LastRun: map[string]*BucketStats{},
+		},
+	}
+}
+
+// Run collects garbage every Interval until ctx is cancelled.
+func (c *Collector) Run(ctx context.Context) {
+	slog.Info("storage gc started", "dry_run", c.opts.DryRun, "min_age", c.opts.MinAge)
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		ran, err := c.repo.WithLock(ctx, func() error {
+			c.pass(ctx)
+			return nil
+		})
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "storage gc: pass failed", "err", err)
+			}
+		case !ran:
+			slog.Info("storage gc: another instance is collecting, skipping")
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("storage gc stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stats returns a snapshot of the collector's results.
+func (c *Collector) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s := c.stats
+	s.LastRun = make(map[string]*BucketStats, len(c.stats.LastRun))
+	for name, b := range c.stats.LastRun {
+		cp := *b
+		s.LastRun[name] = &cp
+	}
+	return s
+}
+
+// pass collects every target once and records the results.
+func (c *Collector) pass(ctx context.Context) {
+	start := time.Now()
+	cutoff := start.Add(-c.opts.MinAge)
+	results := make(map[string]*BucketStats, len(c.targets))
+
+	for _, t := range c.targets {
+		st := &BucketStats{}
+		results[t.Name] = st
+		if err := c.collect(ctx, t, cutoff, st); err != nil {
+			st.Error = err.Error()
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "storage gc: bucket failed", "bucket", t.Name, "err", err)
+			}
+		}
+		slog.InfoContext(ctx, "storage gc: bucket collected", "bucket", t.Name,
+			"scanned", st.Scanned, "orphaned", st.Orphaned, "deleted", st.Deleted, "failed", st.Failed)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.LastRunAt = &start
+	c.stats.LastDuration = time.Since(start).Round(time.Millisecond).String()
+	c.stats.LastRun = results
+	c.stats.Runs++
+	for _, st := range results {
+		c.stats.TotalDeleted += st.Deleted
+		c.stats.TotalFreed += st.BytesFreed
+	}
+}
+
+// collect lists the target's bucket and sweeps objects older than cutoff in
+// batches.
+func (c *Collector) collect(ctx context.Context, t Target, cutoff time.Time, st *BucketStats) error {
+	var batch []*storage.ObjectInfo
+	err := t.Store.List(ctx, "", func(obj *storage.ObjectInfo) error {
+		st.Scanned++
+		if !obj.LastModified.Before(cutoff) {
+			return nil
+		}
+		st.Candidates++
+		batch = append(batch, obj)
+		if len(batch) < sweepBatchSize {
+			return nil
+		}
+		err := c.sweep(ctx, t, batch, st)
+		batch = batch[:0]
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return c.sweep(ctx, t, batch, st)
+}
+
+// sweep deletes the objects in batch that nothing references.
+func (c *Collector) sweep(ctx context.Context, t Target, batch []*storage.ObjectInfo, st *BucketStats) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	owners := make(map[string][]string, len(batch))
+	var keys []string
+	for _, obj := range batch {
+		o := []string{obj.Key}
+		if t.Owners != nil {
+			o = t.Owners(obj.Key)
+		}
+		owners[obj.Key] = o
+		keys = append(keys, o...)
+	}
+	referenced, err := c.repo.Referenced(ctx, t.Refs, keys)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range batch {
+		if isReferenced(owners[obj.Key], referenced) {
+			continue
+		}
+		st.Orphaned++
+		if c.opts.DryRun {
+			slog.InfoContext(ctx, "storage gc: would delete", "bucket", t.Name, "key", obj.Key, "size", obj.Size)
+			continue
+		}
+		if err := t.Store.Delete(ctx, obj.Key); err != nil {
+			st.Failed++
+			slog.WarnContext(ctx, "storage gc: delete failed", "bucket", t.Name, "key", obj.Key, "err", err)
+			continue
+		}
+		st.Deleted++
+		st.BytesFreed += obj.Size
+	}
+	return nil
+}
+
+// AvatarOwners keeps avatar size variants alive while their original is
+// referenced. The original's extension is not part of the variant key, so
+// every accepted upload extension is tried.
+func AvatarOwners(key string) []string {
+	base, ok := imaging.VariantBase(key)
+	if !ok {
+		return []string{key}
+	}
+	return []string{key, base + ".jpg", base + ".png", base + ".webp", base + ".gif"}
+}
+
+// isReferenced reports whether any of keys is in referenced.
+func isReferenced(keys []string, referenced map[string]bool) bool {
+	for _, k := range keys {
+		if referenced[k] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package storagegc
+
+import (
+	"net/http"
+
+	"github.com/radif/service/internal/response"
+)
+
+// Handler exposes the collector's results to staff.
+type Handler struct {
+	c *Collector
+}
+
+// NewHandler creates a new storagegc Handler.
+func NewHandler(c *Collector) *Handler {
+	return &Handler{c: c}
+}
+
+// Stats godoc
+//
+//	@Summary		Get storage GC stats
+//	@Description	Results of the storage garbage collector since the process started: per-bucket counts from the last pass and running totals. In dry-run mode orphans are counted but not deleted. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=Stats}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Router			/admin/storage-gc [get]
+func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
+	response.OK(w, h.c.Stats())
+}
@@ -0,0 +1,98 @@
+// Package storagegc deletes objects that no database row references any
+// more: replaced avatars, documents of deleted accounts and presigned uploads
+// that were never confirmed.
+package storagegc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// lockID is the PostgreSQL advisory lock that keeps collector passes from
+// running on several instances at once.
+const lockID = 0x5261_6469_6647_43 // "RadifGC"
+
+// Ref is a table column that holds object keys.
+type Ref struct {
+	Table  string
+	Column string
+}
+
+// Columns that hold object keys, by bucket content.
+var (
+	RefUserAvatar       = Ref{"users", "avatar_key"}
+	RefGroupAvatar      = Ref{"groups", "avatar_key"}
+	RefUserCover        = Ref{"users", "cover_key"}
+	RefGalleryImage     = Ref{"user_gallery_images", "object_key"}
+	RefKYCDocument      = Ref{"kyc_verifications", "document_key"}
+	RefBusinessDocument = Ref{"business_verifications", "document_key"}
+	RefQuarantined      = Ref{"moderation_items", "object_key"}
+	RefAuditExport      = Ref{"audit_exports", "object_key"}
+)
+
+// Repository looks up object references.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new storagegc Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Referenced returns the subset of keys stored in any of refs.
+func (r *Repository) Referenced(ctx context.Context, refs []Ref, keys []string) (map[string]bool, error) {
+	found := make(map[string]bool)
+	if len(refs) == 0 || len(keys) == 0 {
+		return found, nil
+	}
+
+	// Table and column names come from the Ref values above, never from input.
+	parts := make([]string, len(refs))
+	for i, ref := range refs {
+		parts[i] = fmt.Sprintf(`SELECT %[2]s FROM %[1]s WHERE %[2]s = ANY($1)`, ref.Table, ref.Column)
+	}
+	rows, err := r.db.Query(ctx, strings.Join(parts, " UNION "), keys)
+	if err != nil {
+		return nil, fmt.Errorf("find referenced keys: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("scan referenced key: %w", err)
+		}
+		found[key] = true
+	}
+	return found, rows.Err()
+}
+
+// WithLock runs fn while holding the collector's advisory lock. It reports
+// false without calling fn when another instance holds the lock.
+func (r *Repository) WithLock(ctx context.Context, fn func() error) (bool, error) {
+	conn, err := r.db.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, lockID).Scan(&locked); err != nil {
+		return false, fmt.Errorf("acquire gc lock: %w", err)
+	}
+	if !locked {
+		return false, nil
+	}
+	defer func() {
+		// A cancelled ctx would leave the lock held on a pooled connection.
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, lockID); err != nil {
+			conn.Conn().Close(context.Background())
+		}
+	}()
+
+	return true, fn()
+}
@@ -0,0 +1,40 @@
+package testutil
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/radif/service/internal/bootstrap"
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/health"
+	"github.com/radif/service/internal/metrics"
+	"github.com/radif/service/internal/server"
+)
+
+// NewServer serves the API on env, built by server.New as in cmd/api, so
+// requests pass through the production middleware chain. The optional
+// services — Redis, SMS providers, the event bus, moderation and the CDN —
+// are left out, as in a bare development setup. The server and its
+// background work stop when t finishes.
+func NewServer(t *testing.T, env *Env) *httptest.Server {
+	t.Helper()
+	cfg, pool := env.Config, env.Pool
+	appMetrics := metrics.New()
+
+	api := server.New(server.Deps{
+		Config:    cfg,
+		Pool:      pool,
+		Reader:    db.NewReader(pool, nil),
+		Stores:    bootstrap.OpenStores(cfg, nil, appMetrics),
+		Metrics:   appMetrics,
+		Readiness: health.NewChecker(cfg.ReadinessTimeout),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	api.Start(ctx)
+
+	srv := httptest.NewServer(api.Router)
+	t.Cleanup(srv.Close)
+	return srv
+}
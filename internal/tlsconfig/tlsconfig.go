@@ -0,0 +1,112 @@
+// Package tlsconfig serves HTTPS directly, for deployments without a
+// TLS-terminating reverse proxy: a modern server configuration whose
+// certificate is reloaded from disk when it is renewed, and a plain HTTP
+// handler that redirects to it.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// reloadCheckInterval is how often, at most, the certificate files are
+// checked for a renewal.
+const reloadCheckInterval = time.Minute
+
+// New returns a server configuration for the key pair in certFile and
+// keyFile. It allows TLS 1.2 with forward-secret AEAD suites only, and TLS
+// 1.3. A renewed certificate is picked up within a minute, without a
+// restart; if the new files do not load, the old certificate stays in use.
+func New(certFile, keyFile string) (*tls.Config, error) {
+	c := &certificate{certFile: certFile, keyFile: keyFile}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		// TLS 1.3 suites are not configurable and all fine.
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		GetCertificate: c.get,
+	}, nil
+}
+
+// certificate is a key pair reloaded when its files change.
+type certificate struct {
+	certFile, keyFile string
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func (c *certificate) load() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("load TLS key pair: %w", err)
+	}
+	c.cert = &cert
+	c.modTime = c.latestModTime()
+	return nil
+}
+
+// latestModTime returns when either file last changed, or zero if one
+// cannot be read.
+func (c *certificate) latestModTime() time.Time {
+	var latest time.Time
+	for _, name := range []string{c.certFile, c.keyFile} {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return time.Time{}
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest
+}
+
+func (c *certificate) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.checkedAt) >= reloadCheckInterval {
+		c.checkedAt = time.Now()
+		if mt := c.latestModTime(); !mt.IsZero() && mt.After(c.modTime) {
+			if err := c.load(); err != nil {
+				slog.Error("tlsconfig: reload certificate failed, keeping the current one", "cert_file", c.certFile, "err", err)
+			} else {
+				slog.Info("tlsconfig: certificate reloaded", "cert_file", c.certFile)
+			}
+		}
+	}
+	return c.cert, nil
+}
+
+// RedirectHandler redirects every request to the same URL over HTTPS on
+// httpsPort, with 308 so clients keep the method and body.
+func RedirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DBTracer is a pgx tracer that records a span per query and per batch.
+type DBTracer struct{}
+
+// NewDBTracer returns a DBTracer.
+func NewDBTracer() *DBTracer {
+	return &DBTracer{}
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (DBTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	op := operation(data.SQL)
+	ctx, _ = Tracer().Start(ctx, "db "+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(op), semconv.DBQueryText(data.SQL)),
+	)
+	return ctx
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (DBTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	end(trace.SpanFromContext(ctx), data.Err)
+}
+
+// TraceBatchStart implements pgx.BatchTracer. Queries in the batch are
+// recorded as events on its span.
+func (DBTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	ctx, _ = Tracer().Start(ctx, "db batch",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL),
+	)
+	return ctx
+}
+
+// TraceBatchQuery implements pgx.BatchTracer.
+func (DBTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	span := trace.SpanFromContext(ctx)
+	span.AddEvent("query", trace.WithAttributes(semconv.DBQueryText(data.SQL)))
+	if data.Err != nil {
+		span.RecordError(data.Err)
+	}
+}
+
+// TraceBatchEnd implements pgx.BatchTracer.
+func (DBTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	end(trace.SpanFromContext(ctx), data.Err)
+}
+
+// operation returns the SQL verb of a statement, such as "SELECT".
+func operation(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if i := strings.IndexAny(sql, " \t\n("); i > 0 {
+		sql = sql[:i]
+	}
+	return strings.ToUpper(sql)
+}
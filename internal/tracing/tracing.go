@@ -0,0 +1,83 @@
+// Package tracing sets up OpenTelemetry tracing and instruments the calls a
+// request fans out to — database queries, object storage and SMS — so a slow
+// request can be followed end to end.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation names the tracer that creates this service's spans.
+const instrumentation = "github.com/radif/service"
+
+// Options configures Setup.
+type Options struct {
+	// Endpoint is the base URL of an OTLP/HTTP collector, e.g.
+	// "http://otel-collector:4318"; spans are posted to /v1/traces under it,
+	// as with the standard OTEL_EXPORTER_OTLP_ENDPOINT. Empty disables export.
+	Endpoint    string
+	ServiceName string
+	Environment string
+	// SampleRatio is the share of new traces recorded, from 0 to 1. Requests
+	// arriving with a sampled parent are always recorded.
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and W3C trace-context
+// propagation. It returns a function that flushes pending spans and shuts the
+// exporter down. With no endpoint, spans are created but never exported.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	endpoint, err := url.JoinPath(opts.Endpoint, "v1/traces")
+	if err != nil {
+		return nil, fmt.Errorf("parse otlp endpoint: %w", err)
+	}
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("create otlp exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(opts.ServiceName),
+		semconv.DeploymentEnvironment(opts.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("build resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Tracer returns the tracer for this service's spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// end records err on span, if any, and ends it.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
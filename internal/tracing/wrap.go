@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/radif/service/internal/sms"
+	"github.com/radif/service/internal/storage"
+)
+
+// tracedStorage records a span per storage call.
+type tracedStorage struct {
+	storage.Storage
+	bucket string
+}
+
+// WrapStorage returns s with a span around every call except PublicURL,
+// which does not reach the store.
+func WrapStorage(bucket string, s storage.Storage) storage.Storage {
+	return &tracedStorage{Storage: s, bucket: bucket}
+}
+
+// start opens the span of a storage call.
+func (s *tracedStorage) start(ctx context.Context, op, key string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, "storage "+op,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("storage.bucket", s.bucket), attribute.String("storage.key", key)),
+	)
+}
+
+// end ends the span of a storage call. A missing object is an expected
+// answer, so it is noted rather than marked as an error.
+func (s *tracedStorage) end(span trace.Span, err error) {
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		span.SetAttributes(attribute.Bool("storage.not_found", true))
+		err = nil
+	}
+	end(span, err)
+}
+
+// Upload implements storage.Storage.
+func (s *tracedStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error {
+	ctx, span := s.start(ctx, "upload", key)
+	span.SetAttributes(attribute.Int64("storage.size", size))
+	err := s.Storage.Upload(ctx, key, reader, size, contentType)
+	s.end(span, err)
+	return err
+}
+
+// Delete implements storage.Storage.
+func (s *tracedStorage) Delete(ctx context.Context, key string) error {
+	ctx, span := s.start(ctx, "delete", key)
+	err := s.Storage.Delete(ctx, key)
+	s.end(span, err)
+	return err
+}
+
+// PresignPut implements storage.Storage.
+func (s *tracedStorage) PresignPut(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	ctx, span := s.start(ctx, "presign_put", key)
+	u, err := s.Storage.PresignPut(ctx, key, expiry)
+	s.end(span, err)
+	return u, err
+}
+
+// SignedURL implements storage.Storage.
+func (s *tracedStorage) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	ctx, span := s.start(ctx, "signed_url", key)
+	u, err := s.Storage.SignedURL(ctx, key, ttl)
+	s.end(span, err)
+	return u, err
+}
+
+// Get implements storage.Storage. The span covers opening the object, not
+// reading it.
+func (s *tracedStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	ctx, span := s.start(ctx, "get", key)
+	rc, err := s.Storage.Get(ctx, key)
+	s.end(span, err)
+	return rc, err
+}
+
+// Stat implements storage.Storage.
+func (s *tracedStorage) Stat(ctx context.Context, key string) (*storage.ObjectInfo, error) {
+	ctx, span := s.start(ctx, "stat", key)
+	info, err := s.Storage.Stat(ctx, key)
+	s.end(span, err)
+	return info, err
+}
+
+// List implements storage.Storage.
+func (s *tracedStorage) List(ctx context.Context, prefix string, fn func(*storage.ObjectInfo) error) error {
+	ctx, span := Tracer().Start(ctx, "storage list",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("storage.bucket", s.bucket), attribute.String("storage.prefix", prefix)),
+	)
+	err := s.Storage.List(ctx, prefix, fn)
+	s.end(span, err)
+	return err
+}
+
+// tracedProvider records a span per SMS send.
+type tracedProvider struct {
+	sms.Provider
+}
+
+// WrapSMS returns each provider with a span around Send, so failover
+// between providers shows in the trace.
+func WrapSMS(providers ...sms.Provider) []sms.Provider {
+	out := make([]sms.Provider, len(providers))
+	for i, p := range providers {
+		out[i] = &tracedProvider{Provider: p}
+	}
+	return out
+}
+
+// Send implements sms.Provider. The phone number is left out of the span.
+func (p *tracedProvider) Send(ctx context.Context, phone, text string) error {
+	ctx, span := Tracer().Start(ctx, "sms send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("sms.provider", p.Name())),
+	)
+	err := p.Provider.Send(ctx, phone, text)
+	end(span, err)
+	return err
+}
@@ -0,0 +1,82 @@
+package usage
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for usage endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new usage Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// MyActivity godoc
+//
+//	@Summary		Get my API activity
+//	@Description	Returns the authenticated user's API usage aggregated per day and route, with the last IP seen. Unfamiliar routes or addresses may indicate a compromised token. Figures lag by up to 30 seconds.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			days	query		int	false	"Days to include (1-90, default 7)"
+//	@Success		200		{object}	response.Envelope{data=[]Entry}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/activity [get]
+func (h *Handler) MyActivity(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+	h.writeActivity(w, r, userID)
+}
+
+// UserActivity godoc
+//
+//	@Summary		Get a user's API activity (admin)
+//	@Description	Returns any user's API usage aggregated per day and route.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"User ID"
+//	@Param			days	query		int		false	"Days to include (1-90, default 7)"
+//	@Success		200		{object}	response.Envelope{data=[]Entry}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/users/{id}/activity [get]
+func (h *Handler) UserActivity(w http.ResponseWriter, r *http.Request) {
+	h.writeActivity(w, r, chi.URLParam(r, "id"))
+}
+
+// writeActivity parses the days parameter and writes the user's usage.
+func (h *Handler) writeActivity(w http.ResponseWriter, r *http.Request, userID string) {
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > retentionDays {
+			response.BadRequest(w, "days must be between 1 and 90")
+			return
+		}
+		days = n
+	}
+
+	entries, err := h.svc.Activity(r.Context(), userID, days)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, entries)
+}
@@ -0,0 +1,100 @@
+package usage
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	flushInterval = 30 * time.Second
+	pruneInterval = time.Hour
+	retentionDays = 90
+
+	// maxPendingKeys caps memory between flushes; usage beyond it is dropped
+	// until the next flush rather than growing without bound.
+	maxPendingKeys = 50000
+)
+
+// Recorder aggregates usage in memory and periodically flushes it to the
+// repository, so tracking adds no database round-trip to the request path.
+// It implements middleware.UsageRecorder.
+type Recorder struct {
+	repo *Repository
+
+	mu      sync.Mutex
+	pending map[key]*counter
+}
+
+// NewRecorder creates a new usage Recorder.
+func NewRecorder(repo *Repository) *Recorder {
+	return &Recorder{repo: repo, pending: make(map[key]*counter)}
+}
+
+// Record adds one request to the in-memory aggregate.
+func (rec *Recorder) Record(userID, method, route string, status int, ip string) {
+	now := time.Now().UTC()
+	k := key{userID: userID, day: now.Format(time.DateOnly), method: method, route: route}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	c, ok := rec.pending[k]
+	if !ok {
+		if len(rec.pending) >= maxPendingKeys {
+			return
+		}
+		c = &counter{}
+		rec.pending[k] = c
+	}
+	c.requests++
+	if status >= 400 {
+		c.errors++
+	}
+	c.lastUsedAt = now
+	c.lastIP = ip
+}
+
+// Run flushes aggregates every flushInterval and prunes old rows every
+// pruneInterval until ctx is cancelled, then performs a final flush.
+func (rec *Recorder) Run(ctx context.Context) {
+	slog.Info("usage recorder started")
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+	prune := time.NewTicker(pruneInterval)
+	defer prune.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Use a fresh context so the final flush survives shutdown.
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			rec.flush(flushCtx)
+			cancel()
+			slog.Info("usage recorder stopped")
+			return
+		case <-flush.C:
+			rec.flush(ctx)
+		case <-prune.C:
+			cutoff := time.Now().UTC().AddDate(0, 0, -retentionDays)
+			if _, err := rec.repo.DeleteBefore(ctx, cutoff); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "usage recorder: prune failed", "err", err)
+			}
+		}
+	}
+}
+
+// flush swaps out the pending aggregates and writes them.
+func (rec *Recorder) flush(ctx context.Context) {
+	rec.mu.Lock()
+	batch := rec.pending
+	rec.pending = make(map[key]*counter)
+	rec.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+	if err := rec.repo.Add(ctx, batch); err != nil {
+		slog.ErrorContext(ctx, "usage recorder: flush failed", "aggregates", len(batch), "err", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Package usage tracks per-user API usage so users and admins can spot
+// abnormal patterns, such as a leaked token being used from a new address.
+package usage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Entry is the usage of one route by one user on one day.
+type Entry struct {
+	Day          string    `json:"day"          example:"2026-03-20"`
+	Method       string    `json:"method"`
+	Route        string    `json:"route"`
+	RequestCount int64     `json:"requestCount"`
+	ErrorCount   int64     `json:"errorCount"`
+	LastUsedAt   time.Time `json:"lastUsedAt"`
+	LastIP       string    `json:"lastIp"`
+}
+
+// counter is an in-memory aggregate waiting to be flushed.
+type counter struct {
+	requests   int64
+	errors     int64
+	lastUsedAt time.Time
+	lastIP     string
+}
+
+// key identifies one api_usage_daily row.
+type key struct {
+	userID string
+	day    string
+	method string
+	route  string
+}
+
+// Repository handles usage persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new usage Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+// Add merges in-memory aggregates into the daily rows in a single batch. The
+// batch runs as one implicit transaction, so a failure (e.g. a user deleted
+// mid-interval) drops the whole interval; usage data is best-effort.
+func (r *Repository) Add(ctx context.Context, counts map[key]*counter) error {
+	batch := &pgx.Batch{}
+	for k, c := range counts {
+		batch.Queue(
+			`INSERT INTO api_usage_daily (user_id, day, method, route, request_count, error_count, last_used_at, last_ip)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			 ON CONFLICT (user_id, day, method, route) DO UPDATE SET
+			     request_count = api_usage_daily.request_count + EXCLUDED.request_count,
+			     error_count   = api_usage_daily.error_count + EXCLUDED.error_count,
+			     last_used_at  = GREATEST(api_usage_daily.last_used_at, EXCLUDED.last_used_at),
+			     last_ip       = CASE WHEN EXCLUDED.last_used_at >= api_usage_daily.last_used_at
+			                          THEN EXCLUDED.last_ip ELSE api_usage_daily.last_ip END`,
+			k.userID, k.day, k.method, k.route, c.requests, c.errors, c.lastUsedAt, c.lastIP,
+		)
+	}
+	results := r.db.SendBatch(ctx, batch)
+	defer results.Close()
+	for range counts {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("add api usage: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListByUser returns the user's usage since the given day, most recent first.
+func (r *Repository) ListByUser(ctx context.Context, userID string, since time.Time) ([]*Entry, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT to_char(day, 'YYYY-MM-DD'), method, route, request_count, error_count, last_used_at, last_ip
+		 FROM api_usage_daily
+		 WHERE user_id = $1 AND day >= $2
+		 ORDER BY day DESC, last_used_at DESC`,
+		userID, since,
+	)
+	if err != nil {
+		if isInvalidID(err) {
+			return []*Entry{}, nil
+		}
+		return nil, fmt.Errorf("list api usage: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*Entry{}
+	for rows.Next() {
+		e := &Entry{}
+		if err := rows.Scan(&e.Day, &e.Method, &e.Route, &e.RequestCount, &e.ErrorCount, &e.LastUsedAt, &e.LastIP); err != nil {
+			return nil, fmt.Errorf("scan api usage: %w", err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// DeleteBefore removes aggregates older than the given day.
+func (r *Repository) DeleteBefore(ctx context.Context, day time.Time) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM api_usage_daily WHERE day < $1`, day)
+	if err != nil {
+		return 0, fmt.Errorf("delete old api usage: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
@@ -0,0 +1,23 @@
+package usage
+
+import (
+	"context"
+	"time"
+)
+
+// Service exposes recorded usage.
+type Service struct {
+	repo *Repository
+}
+
+// NewService creates a new usage Service.
+func NewService(repo *Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Activity returns the user's daily usage over the last days days (including
+// today, UTC). Figures lag by up to the recorder's flush interval.
+func (s *Service) Activity(ctx context.Context, userID string, days int) ([]*Entry, error) {
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -(days - 1))
+	return s.repo.ListByUser(ctx, userID, since)
+}
@@ -2,22 +2,33 @@ package user
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"image"
 	"io"
+	"log/slog"
 	"net/http"
+	"path"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/imaging"
 	"github.com/radif/service/internal/middleware"
 	"github.com/radif/service/internal/response"
 	"github.com/radif/service/internal/storage"
+	"github.com/radif/service/internal/validate"
 )
 
 const maxAvatarBytes = 5 << 20 // 5 MB
 
-var usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
-
 var allowedImageTypes = map[string]string{
 	"image/jpeg": ".jpg",
 	"image/png":  ".png",
@@ -25,28 +36,47 @@ var allowedImageTypes = map[string]string{
 	"image/gif":  ".gif",
 }
 
+// AvatarModerator queues new avatars for a content check. It is satisfied by
+// moderation.Service and is optional.
+type AvatarModerator interface {
+	SubmitUserAvatar(ctx context.Context, userID, key string, variants bool) error
+}
+
+// CacheInvalidator drops replaced images from the CDN cache. It is satisfied
+// by cdn.Invalidator and is optional.
+type CacheInvalidator interface {
+	Invalidate(keys ...string)
+}
+
 // Handler holds HTTP handlers for user-related endpoints.
 type Handler struct {
-	svc   *Service
-	store storage.Storage
+	svc       *Service
+	store     storage.Storage
+	moderator AvatarModerator
+	cache     CacheInvalidator
 }
 
-// NewHandler creates a new user Handler.
-func NewHandler(svc *Service, store storage.Storage) *Handler {
-	return &Handler{svc: svc, store: store}
+// NewHandler creates a new user Handler. moderator and cache may be nil.
+func NewHandler(svc *Service, store storage.Storage, moderator AvatarModerator, cache CacheInvalidator) *Handler {
+	return &Handler{svc: svc, store: store, moderator: moderator, cache: cache}
 }
 
 // GetMe godoc
 //
 //	@Summary		Get current user
-//	@Description	Returns the profile of the currently authenticated user.
+//	@Description	Returns the profile of the currently authenticated user. The response carries a weak ETag; send it back in If-None-Match to get a 304 while the profile is unchanged.
 //	@Tags			users
 //	@Produce		json
 //	@Security		BearerAuth
-//	@Success		200	{object}	response.Envelope{data=User}
-//	@Failure		401	{object}	response.Envelope
-//	@Failure		404	{object}	response.Envelope
-//	@Failure		500	{object}	response.Envelope
+//	@Param			fields			query		string	false	"Comma-separated fields to include (e.g. id,username,avatarUrl)"
+//	@Param			casing			query		string	false	"Key casing"	Enums(camel, snake)
+//	@Param			If-None-Match	header		string	false	"ETag of the cached profile"
+//	@Success		200				{object}	response.Envelope{data=User}
+//	@Success		304				"Profile unchanged"
+//	@Failure		400				{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
 //	@Router			/users/me [get]
 func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
 	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
@@ -55,7 +85,12 @@ func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	u, err := h.svc.GetByID(r.Context(), userID)
+	if _, err := response.ParseProjection(r); err != nil {
+		response.BadRequest(w, "invalid fields or casing query parameter")
+		return
+	}
+
+	u, err := h.svc.GetProfile(r.Context(), userID)
 	if err != nil {
 		if h.svc.IsNotFound(err) {
 			response.NotFound(w, "user not found")
@@ -64,20 +99,58 @@ func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
 		response.InternalError(w)
 		return
 	}
+	if response.NotModified(w, r, response.WeakETag(u.ID, u.UpdatedAt)) {
+		return
+	}
 
 	h.populateAvatarURL(u)
-	response.OK(w, u)
+	response.OKProjected(w, r, u)
+}
+
+// GetPublicProfile godoc
+//
+//	@Summary		Get a user's public profile
+//	@Description	Returns the profile another user sees: no phone number, address or settings. The response carries a weak ETag; send it back in If-None-Match to get a 304 while the profile is unchanged.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id				path		string	true	"User ID"
+//	@Param			If-None-Match	header		string	false	"ETag of the cached profile"
+//	@Success		200				{object}	response.Envelope{data=PublicProfile}
+//	@Success		304				"Profile unchanged"
+//	@Failure		401				{object}	response.Envelope
+//	@Failure		404				{object}	response.Envelope
+//	@Failure		500				{object}	response.Envelope
+//	@Router			/users/{id} [get]
+func (h *Handler) GetPublicProfile(w http.ResponseWriter, r *http.Request) {
+	p, err := h.svc.PublicProfile(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		if h.svc.IsNotFound(err) {
+			response.NotFound(w, "user not found")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+	if response.NotModified(w, r, response.WeakETag(p.ID, p.UpdatedAt)) {
+		return
+	}
+
+	h.populatePublicURLs(p)
+	response.OK(w, p)
 }
 
 // UpdateProfile godoc
 //
 //	@Summary		Update profile
-//	@Description	Partially update the authenticated user's profile (username, fullName, bio).
+//	@Description	Partially update the authenticated user's profile (username, fullName, bio). Links, phone numbers and card numbers are removed from fullName and bio, and text with banned (gambling) terms is rejected with code banned_content. Business accounts may also set businessCategory to a category code from GET /categories. New accounts stay out of contact-sync matches until they set discoverable=true.
 //	@Tags			users
 //	@Accept			json
 //	@Produce		json
 //	@Security		BearerAuth
 //	@Param			request	body		updateProfileRequest			true	"Profile fields to update"
+//	@Param			fields	query		string	false	"Comma-separated fields to include (e.g. id,username,avatarUrl)"
+//	@Param			casing	query		string	false	"Key casing"	Enums(camel, snake)
 //	@Success		200		{object}	response.Envelope{data=User}
 //	@Failure		400		{object}	response.Envelope
 //	@Failure		401		{object}	response.Envelope
@@ -91,40 +164,51 @@ func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reject a malformed projection before mutating anything.
+	if _, err := response.ParseProjection(r); err != nil {
+		response.BadRequest(w, "invalid fields or casing query parameter")
+		return
+	}
+
 	var req updateProfileRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.BadRequest(w, "invalid request body")
 		return
 	}
 
-	if req.Username != nil && *req.Username != "" {
-		if !usernameRegex.MatchString(*req.Username) {
-			response.BadRequest(w, "username may only contain letters, digits, and underscores")
-			return
-		}
-		if len(*req.Username) > 50 {
-			response.BadRequest(w, "username must be 50 characters or fewer")
-			return
+	errs := validate.Struct(req)
+	if req.BusinessCategory != nil {
+		if accountType, _ := r.Context().Value(middleware.UserAccountTypeKey).(string); accountType != "business" {
+			errs = append(errs, response.FieldError{Field: "businessCategory", Code: "category_business_only"})
 		}
 	}
-
-	if req.Bio != nil && len(*req.Bio) > 160 {
-		response.BadRequest(w, "bio must be 160 characters or fewer")
+	if errs != nil {
+		response.ValidationFailed(w, errs)
 		return
 	}
 
 	u, err := h.svc.UpdateProfile(r.Context(), userID, UpdateProfileParams{
-		Username:      req.Username,
-		FullName:      req.FullName,
-		Bio:           req.Bio,
-		BusinessPhone: req.BusinessPhone,
-		Address:       req.Address,
+		Username:         req.Username,
+		FullName:         req.FullName,
+		Bio:              req.Bio,
+		BusinessPhone:    req.BusinessPhone,
+		Address:          req.Address,
+		BusinessCategory: req.BusinessCategory,
+		Discoverable:     req.Discoverable,
 	})
 	if err != nil {
 		if h.svc.IsUsernameTaken(err) {
 			response.Conflict(w, "username is already taken")
 			return
 		}
+		if h.svc.IsUnknownCategory(err) {
+			response.ValidationFailed(w, []response.FieldError{{Field: "businessCategory", Code: "unknown_business_category"}})
+			return
+		}
+		if field, ok := h.svc.IsBannedContent(err); ok {
+			response.ValidationFailed(w, []response.FieldError{{Field: field, Code: "banned_content"}})
+			return
+		}
 		if h.svc.IsNotFound(err) {
 			response.NotFound(w, "user not found")
 			return
@@ -134,13 +218,13 @@ func (h *Handler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.populateAvatarURL(u)
-	response.OK(w, u)
+	response.OKProjected(w, r, u)
 }
 
 // UploadAvatar godoc
 //
 //	@Summary		Upload avatar
-//	@Description	Upload a profile picture (JPEG/PNG/WebP/GIF, max 5 MB). Stores the file in object storage and saves the key.
+//	@Description	Upload a profile picture (JPEG/PNG/WebP/GIF, max 5 MB, max 40 megapixels). The image is re-encoded without metadata (EXIF, including GPS) and turned upright per its EXIF orientation; JPEGs stay JPEG and other formats become PNG. Square 64, 128 and 512 px JPEG copies are returned in avatarUrls keyed by size. New avatars are checked for objectionable content shortly after upload; a flagged one is removed and the previous avatar restored.
 //	@Tags			users
 //	@Accept			multipart/form-data
 //	@Produce		json
@@ -158,62 +242,565 @@ func (h *Handler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	data, ok := readImageField(w, r, "avatar")
+	if !ok {
+		return
+	}
+
+	u, err := h.saveAvatar(r.Context(), userID, data)
+	if err != nil {
+		writeAvatarError(w, err)
+		return
+	}
+
+	h.populateAvatarURL(u)
+	response.OK(w, avatarUploadResponse{AvatarURL: *u.AvatarURL, AvatarURLs: u.AvatarURLs})
+}
+
+// errImageType is returned by saveAvatar for data that is not an accepted image type.
+var errImageType = errors.New("unsupported image type")
+
+// saveAvatar re-encodes data without metadata, stores it with its resized
+// variants and makes it the user's avatar.
+func (h *Handler) saveAvatar(ctx context.Context, userID string, data []byte) (*User, error) {
+	key, img, err := h.storeImage(ctx, userID, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Variants are best-effort: without them clients fall back to the original.
+	variants := h.storeAvatarVariants(ctx, key, img.Image)
+
+	// Submitted before the update so the moderator records the avatar to
+	// restore if this one is flagged.
+	if h.moderator != nil {
+		if err := h.moderator.SubmitUserAvatar(ctx, userID, key, variants); err != nil {
+			return nil, err
+		}
+	}
+	prev := h.currentUser(ctx, userID)
+	u, err := h.svc.UpdateAvatarKey(ctx, userID, key, variants)
+	if err != nil {
+		return nil, err
+	}
+	if prev != nil && prev.AvatarKey != nil {
+		h.invalidate(*prev.AvatarKey)
+		if prev.AvatarVariants {
+			h.invalidate(imaging.VariantKeys(*prev.AvatarKey)...)
+		}
+	}
+	return u, nil
+}
+
+// currentUser returns the user before a media change, for purging the
+// replaced images from the CDN. It returns nil without a CDN to purge or when
+// the lookup fails; the replaced images then expire from the cache by TTL.
+func (h *Handler) currentUser(ctx context.Context, userID string) *User {
+	if h.cache == nil {
+		return nil
+	}
+	u, err := h.svc.GetByID(ctx, userID)
+	if err != nil {
+		slog.ErrorContext(ctx, "user: load before media change failed", "user_id", userID, "err", err)
+		return nil
+	}
+	return u
+}
+
+// invalidate purges keys from the CDN cache, if there is one.
+func (h *Handler) invalidate(keys ...string) {
+	if h.cache != nil {
+		h.cache.Invalidate(keys...)
+	}
+}
+
+// storeImage checks that data is an accepted image type, re-encodes it
+// without metadata and uploads it under a new key.
+func (h *Handler) storeImage(ctx context.Context, userID string, data []byte) (string, *imaging.Sanitized, error) {
+	if _, allowed := allowedImageTypes[http.DetectContentType(data)]; !allowed {
+		return "", nil, errImageType
+	}
+	// Originals are never stored as uploaded: EXIF can carry the GPS
+	// position the photo was taken at.
+	img, err := imaging.Sanitize(data)
+	if err != nil {
+		return "", nil, err
+	}
+
+	key, err := generateStorageKey(userID, img.Ext)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := h.store.Upload(ctx, key, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType); err != nil {
+		return "", nil, err
+	}
+	return key, img, nil
+}
+
+// readImageField reads the multipart file in field, writing an error
+// response when the form is invalid, too large or lacks the field.
+func readImageField(w http.ResponseWriter, r *http.Request, field string) ([]byte, bool) {
 	r.Body = http.MaxBytesReader(w, r.Body, maxAvatarBytes+1024)
 	if err := r.ParseMultipartForm(maxAvatarBytes); err != nil {
 		response.BadRequest(w, "file too large or invalid multipart form (max 5 MB)")
-		return
+		return nil, false
 	}
 
-	file, _, err := r.FormFile("avatar")
+	file, _, err := r.FormFile(field)
 	if err != nil {
-		response.BadRequest(w, "field \"avatar\" is required")
-		return
+		response.Localized(w, http.StatusBadRequest, "field_required", field)
+		return nil, false
 	}
 	defer file.Close()
 
-	// Read first 512 bytes to detect the actual content type.
-	buf := make([]byte, 512)
-	n, err := file.Read(buf)
-	if err != nil && err != io.EOF {
+	data, err := io.ReadAll(file)
+	if err != nil {
 		response.InternalError(w)
-		return
+		return nil, false
 	}
+	return data, true
+}
 
-	contentType := http.DetectContentType(buf[:n])
-	ext, allowed := allowedImageTypes[contentType]
-	if !allowed {
+// writeAvatarError maps saveAvatar and storeImage errors to responses.
+func writeAvatarError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errImageType):
 		response.BadRequest(w, "only JPEG, PNG, WebP, and GIF images are allowed")
+	case errors.Is(err, imaging.ErrUnsupported):
+		response.BadRequest(w, "image could not be read or is too large")
+	default:
+		response.InternalError(w)
+	}
+}
+
+// presignedAvatarTTL bounds how long a presigned avatar upload URL is valid.
+const presignedAvatarTTL = 15 * time.Minute
+
+// presignedKeyRegex matches the object part of keys issued by PresignAvatar.
+var presignedKeyRegex = regexp.MustCompile(`^[0-9a-f]{32}\.(jpg|png|webp|gif)$`)
+
+type presignAvatarRequest struct {
+	ContentType string `json:"contentType" example:"image/jpeg"`
+}
+
+type presignAvatarResponse struct {
+	Key       string    `json:"key"`
+	UploadURL string    `json:"uploadUrl"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+type confirmAvatarRequest struct {
+	Key string `json:"key" validate:"required"`
+}
+
+// PresignAvatar godoc
+//
+//	@Summary		Presign avatar upload
+//	@Description	Returns a URL the client can PUT the image to directly, valid for 15 minutes. Send the same Content-Type header with the PUT, then call POST /users/me/avatar/confirm with the returned key. Suited to large files and slow links; the image limits of POST /users/me/avatar apply.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		presignAvatarRequest	true	"Image content type"
+//	@Success		200		{object}	response.Envelope{data=presignAvatarResponse}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/avatar/presign [post]
+func (h *Handler) PresignAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
 		return
 	}
 
-	// Re-assemble the full stream: the 512 bytes we already read + the remainder.
-	fullReader := io.MultiReader(bytes.NewReader(buf[:n]), file)
+	var req presignAvatarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	ext, allowed := allowedImageTypes[req.ContentType]
+	if !allowed {
+		response.ValidationFailed(w, []response.FieldError{{Field: "contentType", Code: "image_type_not_allowed"}})
+		return
+	}
 
 	key, err := generateStorageKey(userID, ext)
 	if err != nil {
 		response.InternalError(w)
 		return
 	}
+	expiresAt := time.Now().Add(presignedAvatarTTL)
+	uploadURL, err := h.store.PresignPut(r.Context(), key, presignedAvatarTTL)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, presignAvatarResponse{Key: key, UploadURL: uploadURL, ExpiresAt: expiresAt})
+}
+
+// ConfirmAvatar godoc
+//
+//	@Summary		Confirm presigned avatar upload
+//	@Description	Sets the object uploaded through POST /users/me/avatar/presign as the caller's avatar. The object must exist, be at most 5 MB and have an allowed image content type matching its key; otherwise it is deleted and 400 is returned. The image is then processed like POST /users/me/avatar, stored under a new key, and the uploaded object is deleted.
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		confirmAvatarRequest	true	"Key returned by the presign endpoint"
+//	@Success		200		{object}	response.Envelope{data=avatarUploadResponse}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/avatar/confirm [post]
+func (h *Handler) ConfirmAvatar(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	var req confirmAvatarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if errs := validate.Struct(req); errs != nil {
+		response.ValidationFailed(w, errs)
+		return
+	}
+	name, own := strings.CutPrefix(req.Key, userID+"/")
+	if !own || !presignedKeyRegex.MatchString(name) {
+		response.BadRequest(w, "invalid key")
+		return
+	}
+
+	info, err := h.store.Stat(r.Context(), req.Key)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		response.BadRequest(w, "no object has been uploaded for this key")
+		return
+	}
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	if info.Size <= 0 || info.Size > maxAvatarBytes || allowedImageTypes[info.ContentType] != path.Ext(req.Key) {
+		h.deleteUpload(r.Context(), req.Key)
+		response.BadRequest(w, "uploaded file must be a JPEG, PNG, WebP or GIF image of at most 5 MB matching the presigned content type")
+		return
+	}
 
-	if err := h.store.Upload(r.Context(), key, fullReader, -1, contentType); err != nil {
+	obj, err := h.store.Get(r.Context(), req.Key)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(obj, maxAvatarBytes+1))
+	obj.Close()
+	if err != nil {
 		response.InternalError(w)
 		return
 	}
+	if len(data) > maxAvatarBytes { // replaced since Stat
+		h.deleteUpload(r.Context(), req.Key)
+		response.BadRequest(w, "uploaded file must be a JPEG, PNG, WebP or GIF image of at most 5 MB matching the presigned content type")
+		return
+	}
 
-	if _, err := h.svc.UpdateAvatarKey(r.Context(), userID, key); err != nil {
+	// The upload is stored again sanitized under a new key, so the raw
+	// object goes either way once it has been read.
+	u, err := h.saveAvatar(r.Context(), userID, data)
+	if err != nil && !errors.Is(err, errImageType) && !errors.Is(err, imaging.ErrUnsupported) {
 		response.InternalError(w)
 		return
 	}
+	h.deleteUpload(r.Context(), req.Key)
+	if err != nil {
+		writeAvatarError(w, err)
+		return
+	}
 
-	avatarURL := h.store.PublicURL(key)
-	response.OK(w, avatarUploadResponse{AvatarURL: avatarURL})
+	h.populateAvatarURL(u)
+	response.OK(w, avatarUploadResponse{AvatarURL: *u.AvatarURL, AvatarURLs: u.AvatarURLs})
 }
 
-// populateAvatarURL attaches the public URL to the user struct when an avatar key is present.
+// deleteUpload removes a presigned upload that was rejected or consumed.
+func (h *Handler) deleteUpload(ctx context.Context, key string) {
+	if err := h.store.Delete(ctx, key); err != nil {
+		slog.WarnContext(ctx, "user: delete presigned avatar upload failed", "key", key, "err", err)
+	}
+}
+
+// UploadCover godoc
+//
+//	@Summary		Upload cover photo
+//	@Description	Upload the profile cover photo shown behind the avatar (JPEG/PNG/WebP/GIF, max 5 MB, max 40 megapixels). The image is re-encoded without metadata like avatars are and replaces any previous cover.
+//	@Tags			users
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			cover	formData	file	true	"Image file"
+//	@Success		200		{object}	response.Envelope{data=coverUploadResponse}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/cover [post]
+func (h *Handler) UploadCover(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	data, ok := readImageField(w, r, "cover")
+	if !ok {
+		return
+	}
+
+	key, _, err := h.storeImage(r.Context(), userID, data)
+	if err != nil {
+		writeAvatarError(w, err)
+		return
+	}
+
+	prev := h.currentUser(r.Context(), userID)
+	u, err := h.svc.UpdateCoverKey(r.Context(), userID, &key)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	if prev != nil && prev.CoverKey != nil {
+		h.invalidate(*prev.CoverKey)
+	}
+
+	h.populateAvatarURL(u)
+	response.OK(w, coverUploadResponse{CoverURL: *u.CoverURL})
+}
+
+// DeleteCover godoc
+//
+//	@Summary		Remove cover photo
+//	@Description	Removes the profile cover photo.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=User}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/cover [delete]
+func (h *Handler) DeleteCover(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+
+	// The object is left to the storage garbage collector, like replaced avatars.
+	prev := h.currentUser(r.Context(), userID)
+	u, err := h.svc.UpdateCoverKey(r.Context(), userID, nil)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	if prev != nil && prev.CoverKey != nil {
+		h.invalidate(*prev.CoverKey)
+	}
+
+	h.populateAvatarURL(u)
+	response.OK(w, u)
+}
+
+// Gallery godoc
+//
+//	@Summary		List gallery images
+//	@Description	Returns the business profile's gallery images in upload order. Business accounts only.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]GalleryImage}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/gallery [get]
+func (h *Handler) Gallery(w http.ResponseWriter, r *http.Request) {
+	userID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	images, err := h.svc.Gallery(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	for _, img := range images {
+		img.URL = h.store.PublicURL(img.Key)
+	}
+	response.OK(w, images)
+}
+
+// AddGalleryImage godoc
+//
+//	@Summary		Add gallery image
+//	@Description	Add an image to the business profile's gallery (JPEG/PNG/WebP/GIF, max 5 MB, max 40 megapixels), re-encoded without metadata like avatars are. A gallery holds up to 8 images; delete one to make room. Business accounts only.
+//	@Tags			users
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			image	formData	file	true	"Image file"
+//	@Success		201		{object}	response.Envelope{data=GalleryImage}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		409		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/users/me/gallery [post]
+func (h *Handler) AddGalleryImage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	// Checked before the upload too, so a full gallery costs no storage.
+	images, err := h.svc.Gallery(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+	if len(images) >= MaxGalleryImages {
+		response.Localized(w, http.StatusConflict, "gallery_full", MaxGalleryImages)
+		return
+	}
+
+	data, ok := readImageField(w, r, "image")
+	if !ok {
+		return
+	}
+
+	key, _, err := h.storeImage(r.Context(), userID, data)
+	if err != nil {
+		writeAvatarError(w, err)
+		return
+	}
+
+	img, err := h.svc.AddGalleryImage(r.Context(), userID, key)
+	if err != nil {
+		if delErr := h.store.Delete(r.Context(), key); delErr != nil {
+			slog.WarnContext(r.Context(), "user: delete unsaved gallery image failed", "key", key, "err", delErr)
+		}
+		if errors.Is(err, ErrGalleryFull) {
+			response.Localized(w, http.StatusConflict, "gallery_full", MaxGalleryImages)
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+
+	img.URL = h.store.PublicURL(img.Key)
+	response.Created(w, img)
+}
+
+// DeleteGalleryImage godoc
+//
+//	@Summary		Delete gallery image
+//	@Description	Removes an image from the business profile's gallery. Business accounts only.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Gallery image ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me/gallery/{id} [delete]
+func (h *Handler) DeleteGalleryImage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := businessAccount(w, r)
+	if !ok {
+		return
+	}
+
+	key, err := h.svc.DeleteGalleryImage(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, ErrImageNotFound) {
+			response.NotFound(w, "gallery image not found")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+
+	// Best-effort: the storage garbage collector removes it otherwise.
+	if err := h.store.Delete(r.Context(), key); err != nil {
+		slog.WarnContext(r.Context(), "user: delete gallery image failed", "key", key, "err", err)
+	}
+	h.invalidate(key)
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// businessAccount returns the authenticated user ID, writing an error response
+// when the caller is not authenticated or not a business account.
+func businessAccount(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return "", false
+	}
+	if accountType, _ := r.Context().Value(middleware.UserAccountTypeKey).(string); accountType != "business" {
+		response.Forbidden(w, "the gallery is available to business accounts only")
+		return "", false
+	}
+	return userID, true
+}
+
+// storeAvatarVariants renders and uploads the resized copies of the avatar at
+// key, reporting whether all of them were stored.
+func (h *Handler) storeAvatarVariants(ctx context.Context, key string, img image.Image) bool {
+	variants, err := imaging.SquareVariants(img, imaging.AvatarSizes)
+	if err != nil {
+		slog.ErrorContext(ctx, "user: render avatar variants failed", "key", key, "err", err)
+		return false
+	}
+	for _, v := range variants {
+		vk := imaging.VariantKey(key, v.Size)
+		if err := h.store.Upload(ctx, vk, bytes.NewReader(v.Data), int64(len(v.Data)), imaging.ContentType); err != nil {
+			slog.ErrorContext(ctx, "user: upload avatar variant failed", "key", vk, "err", err)
+			return false
+		}
+	}
+	return true
+}
+
+// populateAvatarURL attaches the public avatar and cover URLs to the user
+// struct when their keys are present.
 func (h *Handler) populateAvatarURL(u *User) {
 	if u.AvatarKey != nil && *u.AvatarKey != "" {
 		url := h.store.PublicURL(*u.AvatarKey)
 		u.AvatarURL = &url
+		if u.AvatarVariants {
+			u.AvatarURLs = imaging.VariantURLs(*u.AvatarKey, h.store.PublicURL)
+		}
+	}
+	if u.CoverKey != nil && *u.CoverKey != "" {
+		url := h.store.PublicURL(*u.CoverKey)
+		u.CoverURL = &url
+	}
+}
+
+// populatePublicURLs is populateAvatarURL for a PublicProfile.
+func (h *Handler) populatePublicURLs(p *PublicProfile) {
+	if p.AvatarKey != nil && *p.AvatarKey != "" {
+		url := h.store.PublicURL(*p.AvatarKey)
+		p.AvatarURL = &url
+		if p.AvatarVariants {
+			p.AvatarURLs = imaging.VariantURLs(*p.AvatarKey, h.store.PublicURL)
+		}
+	}
+	if p.CoverKey != nil && *p.CoverKey != "" {
+		url := h.store.PublicURL(*p.CoverKey)
+		p.CoverURL = &url
 	}
 }
 
@@ -241,39 +828,238 @@ func generateStorageKey(userID, ext string) (string, error) {
 //	@Failure		500			{object}	response.Envelope
 //	@Router			/users/username-check [get]
 func (h *Handler) CheckUsername(w http.ResponseWriter, r *http.Request) {
+	h.checkUsername(w, r, h.svc.UsernameAvailable)
+}
+
+// PublicCheckUsername godoc
+//
+//	@Summary		Check username availability (public)
+//	@Description	Pre-registration variant of the username check for onboarding, before the user has a token. Rate limited per IP; answers may be up to 30 seconds stale.
+//	@Tags			auth
+//	@Produce		json
+//	@Param			username	query		string	true	"Username to check"
+//	@Success		200			{object}	response.Envelope{data=usernameCheckResponse}
+//	@Failure		400			{object}	response.Envelope
+//	@Failure		429			{object}	response.Envelope
+//	@Failure		500			{object}	response.Envelope
+//	@Router			/auth/username-check [get]
+func (h *Handler) PublicCheckUsername(w http.ResponseWriter, r *http.Request) {
+	h.checkUsername(w, r, h.svc.UsernameAvailableCached)
+}
+
+// checkUsername validates the username query parameter and reports its
+// availability using lookup.
+func (h *Handler) checkUsername(w http.ResponseWriter, r *http.Request, lookup func(context.Context, string) (bool, error)) {
 	username := r.URL.Query().Get("username")
-	if username == "" {
-		response.BadRequest(w, "username query parameter is required")
+	if errs := validate.Var("username", username, "required,max=50,username"); errs != nil {
+		response.ValidationFailed(w, errs)
 		return
 	}
-	if !usernameRegex.MatchString(username) {
-		response.BadRequest(w, "username may only contain letters, digits, and underscores")
+
+	available, err := lookup(r.Context(), username)
+	if err != nil {
+		response.InternalError(w)
 		return
 	}
-	if len(username) > 50 {
-		response.BadRequest(w, "username must be 50 characters or fewer")
-		return
+
+	response.OK(w, usernameCheckResponse{Available: available})
+}
+
+type updateProfileRequest struct {
+	Username         *string `json:"username"         validate:"omitempty,max=50,username"`
+	FullName         *string `json:"fullName"         validate:"omitempty,max=255"`
+	Bio              *string `json:"bio"              validate:"omitempty,max=160"`
+	BusinessPhone    *string `json:"businessPhone"    validate:"omitempty,max=20"`
+	Address          *string `json:"address"`
+	BusinessCategory *string `json:"businessCategory" example:"5812"`
+	Discoverable     *bool   `json:"discoverable"`
+}
+
+// ListBusinesses godoc
+//
+//	@Summary		Discover businesses
+//	@Description	Returns business accounts, optionally filtered by category code, newest first.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			category	query		string	false	"Business category code (e.g. 5812)"
+//	@Param			limit		query		int		false	"Page size (1-50, default 20)"
+//	@Param			offset		query		int		false	"Offset (default 0)"
+//	@Success		200			{object}	response.Envelope{data=[]PublicProfile}
+//	@Failure		400			{object}	response.Envelope
+//	@Failure		401			{object}	response.Envelope
+//	@Failure		500			{object}	response.Envelope
+//	@Router			/users/businesses [get]
+func (h *Handler) ListBusinesses(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, offset := 20, 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 50 {
+			response.BadRequest(w, "limit must be between 1 and 50")
+			return
+		}
+		limit = n
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			response.BadRequest(w, "offset must be a non-negative integer")
+			return
+		}
+		offset = n
 	}
 
-	available, err := h.svc.UsernameAvailable(r.Context(), username)
+	profiles, err := h.svc.ListBusinesses(r.Context(), q.Get("category"), limit, offset)
 	if err != nil {
 		response.InternalError(w)
 		return
 	}
 
-	response.OK(w, usernameCheckResponse{Available: available})
+	for _, p := range profiles {
+		h.populatePublicURLs(p)
+	}
+	response.OK(w, profiles)
 }
 
-type updateProfileRequest struct {
-	Username      *string `json:"username"`
-	FullName      *string `json:"fullName"`
-	Bio           *string `json:"bio"`
-	BusinessPhone *string `json:"businessPhone"`
-	Address       *string `json:"address"`
+// DeleteMe godoc
+//
+//	@Summary		Delete account
+//	@Description	Deletes the caller's account. It disappears from profiles, search, contacts and groups at once, and the phone number can register a new account. Its tokens stop working at once. Support can restore it within 30 days. Requires a full-access token; refused under impersonation.
+//	@Tags			users
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/users/me [delete]
+func (h *Handler) DeleteMe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return
+	}
+	h.delete(w, r, userID)
+}
+
+// AdminDelete godoc
+//
+//	@Summary		Delete user
+//	@Description	Soft-deletes an account, as if its owner had. It can be restored within 30 days. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"User ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/admin/users/{id} [delete]
+func (h *Handler) AdminDelete(w http.ResponseWriter, r *http.Request) {
+	h.delete(w, r, chi.URLParam(r, "id"))
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, id string) {
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		if h.svc.IsNotFound(err) {
+			response.NotFound(w, "user not found")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// Restore godoc
+//
+//	@Summary		Restore deleted user
+//	@Description	Brings back an account deleted less than 30 days ago, with everything attached to it. Fails with 409 when its phone number or username has been claimed by another account since. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"User ID"
+//	@Success		200	{object}	response.Envelope{data=User}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		409	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/admin/users/{id}/restore [post]
+func (h *Handler) Restore(w http.ResponseWriter, r *http.Request) {
+	u, err := h.svc.Restore(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		switch {
+		case h.svc.IsNotFound(err):
+			response.NotFound(w, "no deleted user within the restore window")
+		case errors.Is(err, ErrRestoreConflict):
+			response.Conflict(w, "phone or username is now used by another account")
+		default:
+			response.InternalError(w)
+		}
+		return
+	}
+	h.populateAvatarURL(u)
+	response.OK(w, u)
+}
+
+// History godoc
+//
+//	@Summary		User change history
+//	@Description	Previous values of a user's changed columns, newest first, as recorded by the database on every update or hard delete, however it was made. Keys are column names; soft deletes and restores show up as changes to deleted_at. Pair with the audit log for who made each change. Pass nextCursor from the previous page as cursor to continue. Admin only.
+//	@Tags			admin
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"User ID"
+//	@Param			cursor	query		string	false	"Cursor from the previous page"
+//	@Param			limit	query		int		false	"Page size (1-100, default 50)"
+//	@Success		200		{object}	response.Envelope{data=response.Page{items=[]HistoryEntry}}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/admin/users/{id}/history [get]
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit := 50
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+	cur, err := db.DecodeCursor(q.Get("cursor"))
+	if err != nil {
+		response.BadRequest(w, "invalid cursor")
+		return
+	}
+
+	entries, err := h.svc.History(r.Context(), chi.URLParam(r, "id"), cur, limit)
+	if err != nil {
+		if errors.Is(err, ErrInvalidHistoryQuery) {
+			response.BadRequest(w, "invalid user ID or cursor")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+	response.OK(w, response.NewPage(entries, db.NextCursor(entries, limit, func(e *HistoryEntry) db.Cursor {
+		return db.Cursor{CreatedAt: e.ChangedAt, ID: e.ID}
+	})))
+}
+
+type coverUploadResponse struct {
+	CoverURL string `json:"coverUrl"`
 }
 
 type avatarUploadResponse struct {
-	AvatarURL string `json:"avatarUrl"`
+	AvatarURL  string            `json:"avatarUrl"`
+	AvatarURLs map[string]string `json:"avatarUrls,omitempty"`
 }
 
 type usernameCheckResponse struct {
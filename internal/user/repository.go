@@ -3,40 +3,135 @@ package user
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/radif/service/internal/db"
 )
 
 // User represents a registered Radif user.
 type User struct {
-	ID            string  `json:"id"`
-	Phone         string  `json:"phone"`
-	AccountType   string  `json:"accountType"`
-	Username      *string `json:"username,omitempty"`
-	FullName      *string `json:"fullName,omitempty"`
-	Bio           *string `json:"bio,omitempty"`
-	BusinessPhone *string `json:"businessPhone,omitempty"`
-	Address       *string `json:"address,omitempty"`
-	AvatarKey     *string `json:"-"`
-	AvatarURL     *string `json:"avatarUrl,omitempty"`
+	ID               string  `json:"id"`
+	Phone            string  `json:"phone"`
+	AccountType      string  `json:"accountType"`
+	Role             string  `json:"-"`
+	Username         *string `json:"username,omitempty"`
+	FullName         *string `json:"fullName,omitempty"`
+	Bio              *string `json:"bio,omitempty"`
+	BusinessPhone    *string `json:"businessPhone,omitempty"`
+	Address          *string `json:"address,omitempty"`
+	BusinessCategory *string `json:"businessCategory,omitempty"`
+	Discoverable     bool    `json:"discoverable"`
+	Verified         bool    `json:"verified"`
+	AvatarKey        *string `json:"-"`
+	AvatarVariants   bool    `json:"-"`
+	AvatarURL        *string `json:"avatarUrl,omitempty"`
+	// AvatarURLs maps a square size in pixels ("64", "128", "512") to a
+	// resized copy of the avatar. Absent for avatars uploaded before resizing.
+	AvatarURLs map[string]string `json:"avatarUrls,omitempty"`
+	CoverKey   *string           `json:"-"`
+	CoverURL   *string           `json:"coverUrl,omitempty"`
 
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
 
+// PublicProfile is the subset of a user visible to other users.
+type PublicProfile struct {
+	ID               string  `json:"id"`
+	AccountType      string  `json:"accountType"`
+	Username         *string `json:"username,omitempty"`
+	FullName         *string `json:"fullName,omitempty"`
+	Bio              *string `json:"bio,omitempty"`
+	BusinessCategory *string `json:"businessCategory,omitempty"`
+	Verified         bool    `json:"verified"`
+	AvatarKey        *string `json:"-"`
+	AvatarVariants   bool    `json:"-"`
+	AvatarURL        *string `json:"avatarUrl,omitempty"`
+	// AvatarURLs maps a square size in pixels ("64", "128", "512") to a
+	// resized copy of the avatar. Absent for avatars uploaded before resizing.
+	AvatarURLs map[string]string `json:"avatarUrls,omitempty"`
+	CoverKey   *string           `json:"-"`
+	CoverURL   *string           `json:"coverUrl,omitempty"`
+
+	// UpdatedAt versions the profile for ETags. Unset in business listings.
+	UpdatedAt time.Time `json:"-"`
+}
+
+// Public returns the subset of u visible to other users.
+func (u *User) Public() *PublicProfile {
+	return &PublicProfile{
+		ID:               u.ID,
+		AccountType:      u.AccountType,
+		Username:         u.Username,
+		FullName:         u.FullName,
+		Bio:              u.Bio,
+		BusinessCategory: u.BusinessCategory,
+		Verified:         u.Verified,
+		AvatarKey:        u.AvatarKey,
+		AvatarVariants:   u.AvatarVariants,
+		CoverKey:         u.CoverKey,
+		UpdatedAt:        u.UpdatedAt,
+	}
+}
+
+// GalleryImage is one image of a business profile gallery.
+type GalleryImage struct {
+	ID        string    `json:"id"`
+	Key       string    `json:"-"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
 // UpdateProfileParams holds the fields that can be updated via PATCH /users/me.
 // Nil pointers mean "leave unchanged".
 type UpdateProfileParams struct {
-	Username      *string
-	FullName      *string
-	Bio           *string
-	BusinessPhone *string
-	Address       *string
+	Username         *string
+	FullName         *string
+	Bio              *string
+	BusinessPhone    *string
+	Address          *string
+	BusinessCategory *string
+	Discoverable     *bool
+}
+
+// fields returns the JSON names of the fields p sets.
+func (p UpdateProfileParams) fields() []string {
+	var out []string
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"username", p.Username != nil},
+		{"fullName", p.FullName != nil},
+		{"bio", p.Bio != nil},
+		{"businessPhone", p.BusinessPhone != nil},
+		{"address", p.Address != nil},
+		{"businessCategory", p.BusinessCategory != nil},
+		{"discoverable", p.Discoverable != nil},
+	} {
+		if f.set {
+			out = append(out, f.name)
+		}
+	}
+	return out
+}
+
+// HistoryEntry is one change to a user row, recorded by a database trigger
+// whatever made it. Previous holds the changed columns as they were before,
+// or the whole row for a hard delete; the row had those values from
+// ValidFrom until ChangedAt. Who made the change is in the audit log.
+type HistoryEntry struct {
+	ID        string          `json:"id"`
+	Operation string          `json:"operation" example:"update"`
+	Previous  json.RawMessage `json:"previous"  swaggertype:"object"`
+	ValidFrom time.Time       `json:"validFrom"`
+	ChangedAt time.Time       `json:"changedAt"`
 }
 
 // ErrNotFound is returned when a user does not exist.
@@ -48,27 +143,55 @@ var ErrAlreadyExists = errors.New("user already exists")
 // ErrUsernameTaken is returned when the chosen username is already in use.
 var ErrUsernameTaken = errors.New("username already taken")
 
+// ErrUnknownCategory is returned when a business category code does not exist.
+var ErrUnknownCategory = errors.New("unknown business category")
+
+// ErrGalleryFull is returned when the gallery already holds the maximum number of images.
+var ErrGalleryFull = errors.New("gallery is full")
+
+// ErrImageNotFound is returned when a gallery image does not exist or belongs to another user.
+var ErrImageNotFound = errors.New("gallery image not found")
+
+// ErrInvalidHistoryQuery is returned when the user ID or history cursor is
+// not a UUID.
+var ErrInvalidHistoryQuery = errors.New("invalid user ID or cursor")
+
+// ErrRestoreConflict is returned when a deleted user's phone or username now
+// belongs to another account.
+var ErrRestoreConflict = errors.New("phone or username is now used by another account")
+
+var _ Repo = (*Repository)(nil)
+
 // Repository handles all user database operations.
 type Repository struct {
-	db *pgxpool.Pool
+	db     db.Querier
+	reader db.Querier
+}
+
+// NewRepository creates a new Repository on q, usually the connection pool.
+// Business listings are read through reader, which may be a db.Reader on a
+// replica.
+func NewRepository(q, reader db.Querier) *Repository {
+	return &Repository{db: q, reader: reader}
 }
 
-// NewRepository creates a new Repository with the given connection pool.
-func NewRepository(db *pgxpool.Pool) *Repository {
-	return &Repository{db: db}
+// WithTx returns a copy of the repository that runs its queries on tx, so
+// they commit or roll back together with the caller's other work.
+func (r *Repository) WithTx(tx pgx.Tx) Repo {
+	return &Repository{db: tx, reader: tx}
 }
 
 // scanUser scans a full user row into a User value.
 func scanUser(row pgx.Row, u *User) error {
 	return row.Scan(
-		&u.ID, &u.Phone, &u.AccountType,
+		&u.ID, &u.Phone, &u.AccountType, &u.Role,
 		&u.Username, &u.FullName, &u.Bio,
-		&u.BusinessPhone, &u.Address, &u.AvatarKey,
-		&u.CreatedAt, &u.UpdatedAt,
+		&u.BusinessPhone, &u.Address, &u.BusinessCategory, &u.Discoverable, &u.Verified, &u.AvatarKey, &u.AvatarVariants,
+		&u.CoverKey, &u.CreatedAt, &u.UpdatedAt,
 	)
 }
 
-const selectCols = `id, phone, account_type, username, full_name, bio, business_phone, address, avatar_key, created_at, updated_at`
+const selectCols = `id, phone, account_type, role, username, full_name, bio, business_phone, address, business_category, discoverable, verified_at IS NOT NULL, avatar_key, avatar_variants, cover_key, created_at, updated_at`
 
 // Create inserts a new user and returns the created record.
 func (r *Repository) Create(ctx context.Context, phone, accountType string) (*User, error) {
@@ -92,9 +215,9 @@ func (r *Repository) Create(ctx context.Context, phone, accountType string) (*Us
 func (r *Repository) GetByID(ctx context.Context, id string) (*User, error) {
 	u := &User{}
 	err := scanUser(r.db.QueryRow(ctx,
-		`SELECT `+selectCols+` FROM users WHERE id = $1`, id,
+		`SELECT `+selectCols+` FROM users WHERE id = $1 AND deleted_at IS NULL`, id,
 	), u)
-	if errors.Is(err, pgx.ErrNoRows) {
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidText(err) {
 		return nil, ErrNotFound
 	}
 	if err != nil {
@@ -107,7 +230,7 @@ func (r *Repository) GetByID(ctx context.Context, id string) (*User, error) {
 func (r *Repository) GetByPhone(ctx context.Context, phone string) (*User, error) {
 	u := &User{}
 	err := scanUser(r.db.QueryRow(ctx,
-		`SELECT `+selectCols+` FROM users WHERE phone = $1`, phone,
+		`SELECT `+selectCols+` FROM users WHERE phone = $1 AND deleted_at IS NULL`, phone,
 	), u)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrNotFound
@@ -123,14 +246,16 @@ func (r *Repository) UpdateProfile(ctx context.Context, id string, p UpdateProfi
 	u := &User{}
 	err := scanUser(r.db.QueryRow(ctx,
 		`UPDATE users SET
-		    username       = COALESCE($2, username),
-		    full_name      = COALESCE($3, full_name),
-		    bio            = COALESCE($4, bio),
-		    business_phone = COALESCE($5, business_phone),
-		    address        = COALESCE($6, address)
-		 WHERE id = $1
+		    username          = COALESCE($2, username),
+		    full_name         = COALESCE($3, full_name),
+		    bio               = COALESCE($4, bio),
+		    business_phone    = COALESCE($5, business_phone),
+		    address           = COALESCE($6, address),
+		    business_category = COALESCE($7, business_category),
+		    discoverable      = COALESCE($8, discoverable)
+		 WHERE id = $1 AND deleted_at IS NULL
 		 RETURNING `+selectCols,
-		id, p.Username, p.FullName, p.Bio, p.BusinessPhone, p.Address,
+		id, p.Username, p.FullName, p.Bio, p.BusinessPhone, p.Address, p.BusinessCategory, p.Discoverable,
 	), u)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrNotFound
@@ -139,16 +264,20 @@ func (r *Repository) UpdateProfile(ctx context.Context, id string, p UpdateProfi
 		if isUniqueViolation(err) {
 			return nil, ErrUsernameTaken
 		}
+		if isForeignKeyViolation(err) {
+			return nil, ErrUnknownCategory
+		}
 		return nil, fmt.Errorf("update profile: %w", err)
 	}
 	return u, nil
 }
 
-// UsernameExists returns true when the username is already taken by any user.
+// UsernameExists returns true when the username is already taken by a live
+// user. Deleted users release their usernames.
 func (r *Repository) UsernameExists(ctx context.Context, username string) (bool, error) {
 	var exists bool
 	err := r.db.QueryRow(ctx,
-		`SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`, username,
+		`SELECT EXISTS(SELECT 1 FROM users WHERE username = $1 AND deleted_at IS NULL)`, username,
 	).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("check username exists: %w", err)
@@ -156,12 +285,13 @@ func (r *Repository) UsernameExists(ctx context.Context, username string) (bool,
 	return exists, nil
 }
 
-// UpdateAvatarKey saves a new avatar object key for the user and returns the updated record.
-func (r *Repository) UpdateAvatarKey(ctx context.Context, id, key string) (*User, error) {
+// UpdateAvatarKey saves a new avatar object key for the user, and whether
+// resized variants were stored next to it, and returns the updated record.
+func (r *Repository) UpdateAvatarKey(ctx context.Context, id, key string, variants bool) (*User, error) {
 	u := &User{}
 	err := scanUser(r.db.QueryRow(ctx,
-		`UPDATE users SET avatar_key = $2 WHERE id = $1 RETURNING `+selectCols,
-		id, key,
+		`UPDATE users SET avatar_key = $2, avatar_variants = $3 WHERE id = $1 AND deleted_at IS NULL RETURNING `+selectCols,
+		id, key, variants,
 	), u)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrNotFound
@@ -172,8 +302,221 @@ func (r *Repository) UpdateAvatarKey(ctx context.Context, id, key string) (*User
 	return u, nil
 }
 
+// UpdateCoverKey saves the user's cover photo object key, or clears it when
+// key is nil, and returns the updated record.
+func (r *Repository) UpdateCoverKey(ctx context.Context, id string, key *string) (*User, error) {
+	u := &User{}
+	err := scanUser(r.db.QueryRow(ctx,
+		`UPDATE users SET cover_key = $2 WHERE id = $1 AND deleted_at IS NULL RETURNING `+selectCols,
+		id, key,
+	), u)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("update cover key: %w", err)
+	}
+	return u, nil
+}
+
+// ListGallery returns the user's gallery images in upload order.
+func (r *Repository) ListGallery(ctx context.Context, userID string) ([]*GalleryImage, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, object_key, created_at FROM user_gallery_images
+		 WHERE user_id = $1
+		 ORDER BY created_at, id`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list gallery: %w", err)
+	}
+	defer rows.Close()
+
+	images := []*GalleryImage{}
+	for rows.Next() {
+		img := &GalleryImage{}
+		if err := rows.Scan(&img.ID, &img.Key, &img.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan gallery image: %w", err)
+		}
+		images = append(images, img)
+	}
+	return images, rows.Err()
+}
+
+// AddGalleryImage appends an image to the user's gallery unless it already
+// holds limit images. The user row is locked so concurrent uploads cannot
+// overshoot the limit.
+func (r *Repository) AddGalleryImage(ctx context.Context, userID, key string, limit int) (*GalleryImage, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var count int
+	err = tx.QueryRow(ctx,
+		`SELECT (SELECT COUNT(*) FROM user_gallery_images WHERE user_id = u.id)
+		 FROM users u WHERE u.id = $1 AND u.deleted_at IS NULL
+		 FOR UPDATE`,
+		userID,
+	).Scan(&count)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("count gallery images: %w", err)
+	}
+	if count >= limit {
+		return nil, ErrGalleryFull
+	}
+
+	img := &GalleryImage{Key: key}
+	if err := tx.QueryRow(ctx,
+		`INSERT INTO user_gallery_images (user_id, object_key) VALUES ($1, $2)
+		 RETURNING id, created_at`,
+		userID, key,
+	).Scan(&img.ID, &img.CreatedAt); err != nil {
+		return nil, fmt.Errorf("insert gallery image: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit gallery image: %w", err)
+	}
+	return img, nil
+}
+
+// DeleteGalleryImage removes one of the user's gallery images and returns
+// its object key.
+func (r *Repository) DeleteGalleryImage(ctx context.Context, userID, id string) (string, error) {
+	var key string
+	err := r.db.QueryRow(ctx,
+		`DELETE FROM user_gallery_images WHERE id = $1 AND user_id = $2 RETURNING object_key`,
+		id, userID,
+	).Scan(&key)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidText(err) {
+		return "", ErrImageNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("delete gallery image: %w", err)
+	}
+	return key, nil
+}
+
+// SoftDelete marks a live user deleted. The row and everything that hangs
+// off it are kept so Restore can bring the account back.
+func (r *Repository) SoftDelete(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL`, id,
+	)
+	if isInvalidText(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("soft delete user: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Restore undeletes a user deleted at or after since and returns it. It
+// fails with ErrNotFound when no such deleted user exists, and with
+// ErrRestoreConflict when the phone or username has since been claimed by
+// another account.
+func (r *Repository) Restore(ctx context.Context, id string, since time.Time) (*User, error) {
+	u := &User{}
+	err := scanUser(r.db.QueryRow(ctx,
+		`UPDATE users SET deleted_at = NULL
+		 WHERE id = $1 AND deleted_at >= $2
+		 RETURNING `+selectCols,
+		id, since,
+	), u)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidText(err) {
+		return nil, ErrNotFound
+	}
+	if isUniqueViolation(err) {
+		return nil, ErrRestoreConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("restore user: %w", err)
+	}
+	return u, nil
+}
+
+// History returns the recorded changes to a user, newest first, continuing
+// before cur.
+func (r *Repository) History(ctx context.Context, userID string, cur *db.Cursor, limit int) ([]*HistoryEntry, error) {
+	before, beforeID := db.CursorArgs(cur)
+	rows, err := r.db.Query(ctx,
+		`SELECT id, operation, previous, valid_from, created_at FROM users_history
+		 WHERE user_id = $1
+		   AND ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3::uuid))
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $4`,
+		userID, before, beforeID, limit,
+	)
+	if err != nil {
+		if isInvalidText(err) {
+			return nil, ErrInvalidHistoryQuery
+		}
+		return nil, fmt.Errorf("list user history: %w", err)
+	}
+	defer rows.Close()
+
+	out := []*HistoryEntry{}
+	for rows.Next() {
+		e := &HistoryEntry{}
+		if err := rows.Scan(&e.ID, &e.Operation, &e.Previous, &e.ValidFrom, &e.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scan user history: %w", err)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// ListBusinesses returns business accounts for discovery, optionally filtered
+// by category code, ordered by most recently joined.
+func (r *Repository) ListBusinesses(ctx context.Context, category string, limit, offset int) ([]*PublicProfile, error) {
+	rows, err := r.reader.Query(ctx,
+		`SELECT id, account_type, username, full_name, bio, business_category, verified_at IS NOT NULL, avatar_key, avatar_variants, cover_key
+		 FROM users
+		 WHERE account_type = 'business' AND deleted_at IS NULL
+		   AND ($1 = '' OR business_category = $1)
+		 ORDER BY created_at DESC
+		 LIMIT $2 OFFSET $3`,
+		category, limit, offset,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list businesses: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := []*PublicProfile{}
+	for rows.Next() {
+		p := &PublicProfile{}
+		if err := rows.Scan(&p.ID, &p.AccountType, &p.Username, &p.FullName, &p.Bio, &p.BusinessCategory, &p.Verified, &p.AvatarKey, &p.AvatarVariants, &p.CoverKey); err != nil {
+			return nil, fmt.Errorf("scan business: %w", err)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
 // isUniqueViolation checks whether an error is a PostgreSQL unique_violation (code 23505).
 func isUniqueViolation(err error) bool {
 	var pgErr *pgconn.PgError
 	return errors.As(err, &pgErr) && pgErr.Code == "23505"
 }
+
+// isForeignKeyViolation checks whether an error is a PostgreSQL foreign_key_violation (code 23503).
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
+
+// isInvalidText checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// as raised for a malformed UUID.
+func isInvalidText(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
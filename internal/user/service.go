@@ -4,16 +4,80 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/radif/service/internal/audit"
+	"github.com/radif/service/internal/cache"
+	"github.com/radif/service/internal/contentfilter"
+	"github.com/radif/service/internal/db"
+	"github.com/radif/service/internal/events"
 )
 
+// MaxGalleryImages is how many images a business profile gallery holds.
+const MaxGalleryImages = 8
+
+// usernameCacheTTL bounds how stale a cached availability answer may be. The
+// unique index still decides when a username is actually claimed.
+const usernameCacheTTL = 30 * time.Second
+
+// profileCacheTTL bounds how stale a cached profile may be. Writes through
+// this service evict it at once; the TTL covers the few places that update
+// users directly, such as business verification and avatar moderation.
+const profileCacheTTL = time.Minute
+
+// RestoreWindow is how long a deleted account can be restored.
+const RestoreWindow = 30 * 24 * time.Hour
+
+//go:generate mockgen -source=service.go -destination=mock_repo_test.go -package=user -self_package=github.com/radif/service/internal/user
+
+// Repo is the user persistence Service depends on. It is satisfied by
+// *Repository; tests use the generated MockRepo.
+type Repo interface {
+	Create(ctx context.Context, phone, accountType string) (*User, error)
+	GetByID(ctx context.Context, id string) (*User, error)
+	GetByPhone(ctx context.Context, phone string) (*User, error)
+	UpdateProfile(ctx context.Context, id string, p UpdateProfileParams) (*User, error)
+	UsernameExists(ctx context.Context, username string) (bool, error)
+	UpdateAvatarKey(ctx context.Context, id, key string, variants bool) (*User, error)
+	UpdateCoverKey(ctx context.Context, id string, key *string) (*User, error)
+	ListGallery(ctx context.Context, userID string) ([]*GalleryImage, error)
+	AddGalleryImage(ctx context.Context, userID, key string, limit int) (*GalleryImage, error)
+	DeleteGalleryImage(ctx context.Context, userID, id string) (string, error)
+	ListBusinesses(ctx context.Context, category string, limit, offset int) ([]*PublicProfile, error)
+	SoftDelete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string, since time.Time) (*User, error)
+	History(ctx context.Context, userID string, cur *db.Cursor, limit int) ([]*HistoryEntry, error)
+	WithTx(tx pgx.Tx) Repo
+}
+
 // Service contains business logic for user management.
 type Service struct {
-	repo *Repository
+	repo   Repo
+	txm    db.Transactor
+	cache  *cache.Cache
+	events *events.Outbox
+	audit  *audit.Log
+
+	// bound is set on copies made by WithTx, whose repo and events already
+	// run on the caller's transaction.
+	bound bool
+}
+
+// NewService creates a new user Service. Changes and the events announcing
+// them are written in one transaction of txm. c may be nil, in which case
+// nothing is cached; outbox may be nil, in which case no events are
+// published. Profile changes are recorded in auditLog.
+func NewService(repo Repo, txm db.Transactor, c *cache.Cache, outbox *events.Outbox, auditLog *audit.Log) *Service {
+	return &Service{repo: repo, txm: txm, cache: c, events: outbox, audit: auditLog}
 }
 
-// NewService creates a new user Service.
-func NewService(repo *Repository) *Service {
-	return &Service{repo: repo}
+// WithTx returns a copy of the service whose writes, and the events they
+// queue, run on tx. Cache evictions and audit entries are not transactional;
+// callers run them only after tx commits.
+func (s *Service) WithTx(tx pgx.Tx) *Service {
+	return &Service{repo: s.repo.WithTx(tx), txm: s.txm, cache: s.cache, events: s.events.WithTx(tx), audit: s.audit, bound: true}
 }
 
 // Create registers a new user account.
@@ -30,6 +94,61 @@ func (s *Service) GetByID(ctx context.Context, id string) (*User, error) {
 	return s.repo.GetByID(ctx, id)
 }
 
+// Role returns the user's current role, or "" when the user does not exist
+// or was deleted; it implements middleware.RoleLookup. It reads the database
+// rather than the profile cache, so a role change applies to the very next
+// request.
+func (s *Service) Role(ctx context.Context, id string) (string, error) {
+	u, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return u.Role, nil
+}
+
+// Active reports whether the user exists and has not been deleted; it
+// implements middleware.Accounts, which calls it on every authenticated
+// request. It shares GetProfile's cache, which Delete evicts, so a deleted
+// account is locked out at once.
+func (s *Service) Active(ctx context.Context, id string) (bool, error) {
+	_, err := s.GetProfile(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// GetProfile is GetByID served from the cache when possible. It backs
+// GET /users/me, which clients poll on every launch.
+func (s *Service) GetProfile(ctx context.Context, id string) (*User, error) {
+	var u User
+	if s.cache.Get(ctx, profileKey(id), &u) {
+		return &u, nil
+	}
+	got, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.Set(ctx, profileKey(id), got, profileCacheTTL)
+	return got, nil
+}
+
+// PublicProfile returns the profile of id as other users see it, sharing
+// GetProfile's cache.
+func (s *Service) PublicProfile(ctx context.Context, id string) (*PublicProfile, error) {
+	u, err := s.GetProfile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.Public(), nil
+}
+
 // GetByPhone returns a user by their phone number.
 func (s *Service) GetByPhone(ctx context.Context, phone string) (*User, error) {
 	return s.repo.GetByPhone(ctx, phone)
@@ -37,10 +156,31 @@ func (s *Service) GetByPhone(ctx context.Context, phone string) (*User, error) {
 
 // UpdateProfile applies partial updates to a user's profile.
 func (s *Service) UpdateProfile(ctx context.Context, id string, p UpdateProfileParams) (*User, error) {
-	u, err := s.repo.UpdateProfile(ctx, id, p)
-	if err != nil {
+	if err := sanitizeProfile(&p); err != nil {
+		return nil, err
+	}
+	// Read for the audit diff; the update itself still decides not-found.
+	before, err := s.repo.GetByID(ctx, id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
 		return nil, fmt.Errorf("update profile: %w", err)
 	}
+	var u *User
+	err = s.inTx(ctx, func(repo Repo, outbox *events.Outbox) error {
+		var err error
+		if u, err = repo.UpdateProfile(ctx, id, p); err != nil {
+			return fmt.Errorf("update profile: %w", err)
+		}
+		return profileUpdated(ctx, outbox, id, p.fields()...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, audit.ActionProfileUpdated, audit.TargetUser, id, before, u)
+	keys := []string{profileKey(id)}
+	if p.Username != nil {
+		keys = append(keys, usernameKey(*p.Username))
+	}
+	s.cache.Delete(ctx, keys...)
 	return u, nil
 }
 
@@ -53,15 +193,134 @@ func (s *Service) UsernameAvailable(ctx context.Context, username string) (bool,
 	return !exists, nil
 }
 
-// UpdateAvatarKey saves a new avatar object storage key for the user.
-func (s *Service) UpdateAvatarKey(ctx context.Context, id, key string) (*User, error) {
-	u, err := s.repo.UpdateAvatarKey(ctx, id, key)
+// UsernameAvailableCached is UsernameAvailable with answers cached for
+// usernameCacheTTL. It backs the public pre-registration check, where the same
+// handles are probed repeatedly while a user types.
+func (s *Service) UsernameAvailableCached(ctx context.Context, username string) (bool, error) {
+	var available bool
+	if s.cache.Get(ctx, usernameKey(username), &available) {
+		return available, nil
+	}
+
+	available, err := s.UsernameAvailable(ctx, username)
+	if err != nil {
+		return false, err
+	}
+	s.cache.Set(ctx, usernameKey(username), available, usernameCacheTTL)
+	return available, nil
+}
+
+// UpdateAvatarKey saves a new avatar object storage key for the user;
+// variants reports whether resized copies were stored alongside it.
+func (s *Service) UpdateAvatarKey(ctx context.Context, id, key string, variants bool) (*User, error) {
+	var u *User
+	err := s.inTx(ctx, func(repo Repo, outbox *events.Outbox) error {
+		var err error
+		if u, err = repo.UpdateAvatarKey(ctx, id, key, variants); err != nil {
+			return fmt.Errorf("update avatar key: %w", err)
+		}
+		return profileUpdated(ctx, outbox, id, "avatar")
+	})
 	if err != nil {
-		return nil, fmt.Errorf("update avatar key: %w", err)
+		return nil, err
 	}
+	s.audit.Record(ctx, audit.ActionAvatarUpdated, audit.TargetUser, id, nil, map[string]string{"avatarKey": key})
+	s.cache.Delete(ctx, profileKey(id))
 	return u, nil
 }
 
+// UpdateCoverKey saves the user's cover photo key, or removes the cover when key is nil.
+func (s *Service) UpdateCoverKey(ctx context.Context, id string, key *string) (*User, error) {
+	var u *User
+	err := s.inTx(ctx, func(repo Repo, outbox *events.Outbox) error {
+		var err error
+		if u, err = repo.UpdateCoverKey(ctx, id, key); err != nil {
+			return fmt.Errorf("update cover key: %w", err)
+		}
+		return profileUpdated(ctx, outbox, id, "cover")
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, audit.ActionCoverUpdated, audit.TargetUser, id, nil, map[string]*string{"coverKey": key})
+	s.cache.Delete(ctx, profileKey(id))
+	return u, nil
+}
+
+// Delete soft-deletes the account. It disappears everywhere at once, and its
+// phone and username are free to claim again, but an admin can Restore it
+// within RestoreWindow.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	u, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	err = s.inTx(ctx, func(repo Repo, outbox *events.Outbox) error {
+		if err := repo.SoftDelete(ctx, id); err != nil {
+			return fmt.Errorf("delete user: %w", err)
+		}
+		return outbox.Emit(ctx, events.UserDeletedV1{UserID: id, RestorableUntil: time.Now().Add(RestoreWindow)})
+	})
+	if err != nil {
+		return err
+	}
+	s.audit.Record(ctx, audit.ActionUserDeleted, audit.TargetUser, id, u, nil)
+	keys := []string{profileKey(id)}
+	if u.Username != nil {
+		keys = append(keys, usernameKey(*u.Username))
+	}
+	s.cache.Delete(ctx, keys...)
+	return nil
+}
+
+// Restore brings back an account deleted less than RestoreWindow ago. It
+// fails with ErrRestoreConflict when the phone or username was claimed in
+// the meantime.
+func (s *Service) Restore(ctx context.Context, id string) (*User, error) {
+	var u *User
+	err := s.inTx(ctx, func(repo Repo, outbox *events.Outbox) error {
+		var err error
+		if u, err = repo.Restore(ctx, id, time.Now().Add(-RestoreWindow)); err != nil {
+			return err
+		}
+		return outbox.Emit(ctx, events.UserRestoredV1{UserID: id})
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, audit.ActionUserRestored, audit.TargetUser, id, nil, u)
+	if u.Username != nil {
+		s.cache.Delete(ctx, usernameKey(*u.Username))
+	}
+	return u, nil
+}
+
+// History returns recorded changes to a user's row, newest first.
+func (s *Service) History(ctx context.Context, userID string, cur *db.Cursor, limit int) ([]*HistoryEntry, error) {
+	return s.repo.History(ctx, userID, cur, limit)
+}
+
+// Gallery returns the user's gallery images in upload order.
+func (s *Service) Gallery(ctx context.Context, userID string) ([]*GalleryImage, error) {
+	return s.repo.ListGallery(ctx, userID)
+}
+
+// AddGalleryImage appends the stored image at key to the user's gallery,
+// failing with ErrGalleryFull once it holds MaxGalleryImages.
+func (s *Service) AddGalleryImage(ctx context.Context, userID, key string) (*GalleryImage, error) {
+	return s.repo.AddGalleryImage(ctx, userID, key, MaxGalleryImages)
+}
+
+// DeleteGalleryImage removes a gallery image and returns its object key.
+func (s *Service) DeleteGalleryImage(ctx context.Context, userID, id string) (string, error) {
+	return s.repo.DeleteGalleryImage(ctx, userID, id)
+}
+
+// ListBusinesses returns business profiles for discovery, optionally filtered by category.
+func (s *Service) ListBusinesses(ctx context.Context, category string, limit, offset int) ([]*PublicProfile, error) {
+	return s.repo.ListBusinesses(ctx, category, limit, offset)
+}
+
 // IsNotFound returns true when the error indicates a user was not found.
 func (s *Service) IsNotFound(err error) bool {
 	return errors.Is(err, ErrNotFound)
@@ -71,3 +330,87 @@ func (s *Service) IsNotFound(err error) bool {
 func (s *Service) IsUsernameTaken(err error) bool {
 	return errors.Is(err, ErrUsernameTaken)
 }
+
+// IsUnknownCategory returns true when the error indicates a nonexistent business category.
+func (s *Service) IsUnknownCategory(err error) bool {
+	return errors.Is(err, ErrUnknownCategory)
+}
+
+// IsBannedContent returns the JSON name of the profile field rejected by the
+// content filter, when err reports one.
+func (s *Service) IsBannedContent(err error) (string, bool) {
+	var bc *BannedContentError
+	if errors.As(err, &bc) {
+		return bc.Field, true
+	}
+	return "", false
+}
+
+// BannedContentError is returned when a display name or bio contains a
+// term the content filter bans.
+type BannedContentError struct {
+	Field string
+}
+
+func (e *BannedContentError) Error() string {
+	return e.Field + ": " + contentfilter.ErrBannedContent.Error()
+}
+
+func (e *BannedContentError) Unwrap() error { return contentfilter.ErrBannedContent }
+
+// sanitizeProfile runs the display name and bio, which other users see,
+// through the content filter: banned terms are rejected, and links, phone
+// and card numbers are stripped.
+func sanitizeProfile(p *UpdateProfileParams) error {
+	for _, f := range []struct {
+		name  string
+		value **string
+	}{
+		{"fullName", &p.FullName},
+		{"bio", &p.Bio},
+	} {
+		if *f.value == nil {
+			continue
+		}
+		res, err := contentfilter.Sanitize(**f.value)
+		if errors.Is(err, contentfilter.ErrBannedContent) {
+			return &BannedContentError{Field: f.name}
+		}
+		if err != nil {
+			return fmt.Errorf("sanitize %s: %w", f.name, err)
+		}
+		*f.value = &res.Text
+	}
+	return nil
+}
+
+// inTx runs fn with the repository and outbox on one transaction, so the
+// events fn queues are published only if its writes commit, and a change is
+// never saved without its event. On a copy from WithTx it joins the caller's
+// transaction instead.
+func (s *Service) inTx(ctx context.Context, fn func(repo Repo, outbox *events.Outbox) error) error {
+	if s.bound {
+		return fn(s.repo, s.events)
+	}
+	return s.txm.WithTx(ctx, func(tx pgx.Tx) error {
+		return fn(s.repo.WithTx(tx), s.events.WithTx(tx))
+	})
+}
+
+// profileUpdated queues a profile change on outbox, if any fields changed.
+func profileUpdated(ctx context.Context, outbox *events.Outbox, id string, fields ...string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return outbox.Emit(ctx, events.UserProfileUpdatedV1{UserID: id, Fields: fields})
+}
+
+// profileKey is the cache key of a user's profile.
+func profileKey(id string) string {
+	return "user:" + id
+}
+
+// usernameKey is the cache key of a username's availability.
+func usernameKey(username string) string {
+	return "username:" + username
+}
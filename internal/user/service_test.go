@@ -0,0 +1,181 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeTx runs transactional work directly, without a database. Mocks
+// expect WithTx(nil) and return themselves.
+type fakeTx struct {
+	calls int
+}
+
+func (f *fakeTx) WithTx(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	f.calls++
+	return fn(nil)
+}
+
+// newTestService returns a Service on a mock repository, with no cache,
+// event bus or audit log.
+func newTestService(t *testing.T) (*Service, *MockRepo, *fakeTx) {
+	t.Helper()
+	repo := NewMockRepo(gomock.NewController(t))
+	txm := &fakeTx{}
+	return NewService(repo, txm, nil, nil, nil), repo, txm
+}
+
+func TestRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *User
+		err      error
+		wantRole string
+		wantErr  bool
+	}{
+		{name: "admin", user: &User{ID: "user-1", Role: "admin"}, wantRole: "admin"},
+		{name: "not found", err: ErrNotFound, wantRole: ""},
+		{name: "database down", err: errors.New("connection refused"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _ := newTestService(t)
+			ctx := context.Background()
+			repo.EXPECT().GetByID(ctx, "user-1").Return(tt.user, tt.err)
+
+			role, err := svc.Role(ctx, "user-1")
+			if (err != nil) != tt.wantErr || role != tt.wantRole {
+				t.Fatalf("Role = (%q, %v), want %q, error %v", role, err, tt.wantRole, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestActive(t *testing.T) {
+	svc, repo, _ := newTestService(t)
+	ctx := context.Background()
+
+	repo.EXPECT().GetByID(ctx, "user-1").Return(&User{ID: "user-1"}, nil)
+	repo.EXPECT().GetByID(ctx, "deleted").Return(nil, ErrNotFound)
+
+	if active, err := svc.Active(ctx, "user-1"); err != nil || !active {
+		t.Errorf("Active(user-1) = (%v, %v), want true", active, err)
+	}
+	if active, err := svc.Active(ctx, "deleted"); err != nil || active {
+		t.Errorf("Active(deleted) = (%v, %v), want false", active, err)
+	}
+}
+
+func TestUpdateProfileInTransaction(t *testing.T) {
+	svc, repo, txm := newTestService(t)
+	ctx := context.Background()
+	name := "Sara"
+	p := UpdateProfileParams{FullName: &name}
+
+	repo.EXPECT().GetByID(ctx, "user-1").Return(&User{ID: "user-1"}, nil)
+	repo.EXPECT().WithTx(gomock.Nil()).Return(repo)
+	repo.EXPECT().UpdateProfile(ctx, "user-1", p).Return(&User{ID: "user-1", FullName: &name}, nil)
+
+	u, err := svc.UpdateProfile(ctx, "user-1", p)
+	if err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+	if u.FullName == nil || *u.FullName != name {
+		t.Fatalf("FullName = %v, want %q", u.FullName, name)
+	}
+	if txm.calls != 1 {
+		t.Fatalf("transactions = %d, want 1", txm.calls)
+	}
+}
+
+func TestUpdateProfileUsernameTaken(t *testing.T) {
+	svc, repo, _ := newTestService(t)
+	ctx := context.Background()
+	username := "sara"
+	p := UpdateProfileParams{Username: &username}
+
+	repo.EXPECT().GetByID(ctx, "user-1").Return(&User{ID: "user-1"}, nil)
+	repo.EXPECT().WithTx(gomock.Nil()).Return(repo)
+	repo.EXPECT().UpdateProfile(ctx, "user-1", p).Return(nil, ErrUsernameTaken)
+
+	_, err := svc.UpdateProfile(ctx, "user-1", p)
+	if !svc.IsUsernameTaken(err) {
+		t.Fatalf("UpdateProfile error = %v, want ErrUsernameTaken", err)
+	}
+}
+
+func TestUpdateProfileSanitizesBio(t *testing.T) {
+	svc, repo, _ := newTestService(t)
+	ctx := context.Background()
+	bio := "Call 09121234567 or visit https://spam.example"
+	want := "Call *** or visit"
+
+	repo.EXPECT().GetByID(ctx, "user-1").Return(&User{ID: "user-1"}, nil)
+	repo.EXPECT().WithTx(gomock.Nil()).Return(repo)
+	repo.EXPECT().UpdateProfile(ctx, "user-1", UpdateProfileParams{Bio: &want}).Return(&User{ID: "user-1", Bio: &want}, nil)
+
+	if _, err := svc.UpdateProfile(ctx, "user-1", UpdateProfileParams{Bio: &bio}); err != nil {
+		t.Fatalf("UpdateProfile: %v", err)
+	}
+}
+
+func TestUpdateProfileBannedName(t *testing.T) {
+	svc, _, txm := newTestService(t)
+	name := "Casino Royale"
+
+	_, err := svc.UpdateProfile(context.Background(), "user-1", UpdateProfileParams{FullName: &name})
+	if field, ok := svc.IsBannedContent(err); !ok || field != "fullName" {
+		t.Fatalf("UpdateProfile error = %v, want banned content in fullName", err)
+	}
+	if txm.calls != 0 {
+		t.Fatalf("transactions = %d, want 0", txm.calls)
+	}
+}
+
+func TestDeleteUnknownUser(t *testing.T) {
+	svc, repo, txm := newTestService(t)
+	ctx := context.Background()
+
+	repo.EXPECT().GetByID(ctx, "missing").Return(nil, ErrNotFound)
+
+	if err := svc.Delete(ctx, "missing"); !svc.IsNotFound(err) {
+		t.Fatalf("Delete error = %v, want ErrNotFound", err)
+	}
+	if txm.calls != 0 {
+		t.Fatalf("transactions = %d, want 0", txm.calls)
+	}
+}
+
+func TestWithTxJoinsCallerTransaction(t *testing.T) {
+	svc, repo, txm := newTestService(t)
+	ctx := context.Background()
+
+	repo.EXPECT().WithTx(gomock.Nil()).Return(repo)
+	repo.EXPECT().UpdateAvatarKey(ctx, "user-1", "avatars/user-1.webp", true).Return(&User{ID: "user-1"}, nil)
+
+	if _, err := svc.WithTx(nil).UpdateAvatarKey(ctx, "user-1", "avatars/user-1.webp", true); err != nil {
+		t.Fatalf("UpdateAvatarKey: %v", err)
+	}
+	if txm.calls != 0 {
+		t.Fatalf("transactions = %d, want 0: a bound service must not begin its own", txm.calls)
+	}
+}
+
+func TestUsernameAvailable(t *testing.T) {
+	svc, repo, _ := newTestService(t)
+	ctx := context.Background()
+
+	repo.EXPECT().UsernameExists(ctx, "taken").Return(true, nil)
+	repo.EXPECT().UsernameExists(ctx, "free").Return(false, nil)
+
+	if ok, err := svc.UsernameAvailable(ctx, "taken"); err != nil || ok {
+		t.Errorf("UsernameAvailable(taken) = (%v, %v), want false", ok, err)
+	}
+	if ok, err := svc.UsernameAvailable(ctx, "free"); err != nil || !ok {
+		t.Errorf("UsernameAvailable(free) = (%v, %v), want true", ok, err)
+	}
+}
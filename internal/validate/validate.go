@@ -0,0 +1,119 @@
+// Package validate checks request DTOs against their `validate` struct tags
+// (github.com/go-playground/validator) and reports each failing field with
+// an i18n catalog code, for response.ValidationFailed.
+package validate
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/radif/service/internal/response"
+)
+
+var (
+	// iranPhoneRegex matches Iranian mobile numbers (09XXXXXXXXX).
+	iranPhoneRegex = regexp.MustCompile(`^09[0-9]{9}$`)
+	// usernameRegex matches usernames: letters, digits and underscores.
+	usernameRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+)
+
+var validate = func() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// Report fields by their JSON name, which is what clients sent.
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	_ = v.RegisterValidation("iranphone", func(fl validator.FieldLevel) bool {
+		return iranPhoneRegex.MatchString(fl.Field().String())
+	})
+	// An empty username clears it on a profile update; "required" rejects
+	// it where one must be given.
+	_ = v.RegisterValidation("username", func(fl validator.FieldLevel) bool {
+		s := fl.Field().String()
+		return s == "" || usernameRegex.MatchString(s)
+	})
+	return v
+}()
+
+// Struct validates s and returns its failing fields, or nil when s is valid.
+func Struct(s any) []response.FieldError {
+	return fieldErrors("", validate.Struct(s))
+}
+
+// Var validates a single value, such as a query parameter, against tag and
+// reports failures under field.
+func Var(field string, value any, tag string) []response.FieldError {
+	return fieldErrors(field, validate.Var(value, tag))
+}
+
+func fieldErrors(field string, err error) []response.FieldError {
+	verrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil
+	}
+	out := make([]response.FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		name := fe.Field()
+		if field != "" {
+			name = field
+		}
+		code, args := describe(name, fe)
+		out = append(out, response.FieldError{Field: name, Code: code, Args: args})
+	}
+	return out
+}
+
+// describe maps a failed tag to its catalog code and message arguments.
+func describe(field string, fe validator.FieldError) (string, []any) {
+	switch fe.Tag() {
+	case "required":
+		return "field_required", []any{field}
+	case "iranphone":
+		return "invalid_phone", nil
+	case "username":
+		return "username_invalid_chars", nil
+	case "numeric":
+		return "field_not_numeric", nil
+	case "oneof":
+		return "field_not_one_of", []any{strings.ReplaceAll(fe.Param(), " ", ", ")}
+	case "len":
+		if isNumber(fe.Kind()) {
+			return "field_invalid", nil
+		}
+		return "field_wrong_length", []any{fe.Param()}
+	case "max":
+		switch {
+		case isNumber(fe.Kind()):
+			return "field_too_large", []any{fe.Param()}
+		case fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map:
+			return "field_too_many", []any{fe.Param()}
+		}
+		return "field_too_long", []any{fe.Param()}
+	case "min":
+		switch {
+		case isNumber(fe.Kind()):
+			return "field_too_small", []any{fe.Param()}
+		case fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map:
+			return "field_too_few", []any{fe.Param()}
+		}
+		return "field_too_short", []any{fe.Param()}
+	}
+	return "field_invalid", nil
+}
+
+func isNumber(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
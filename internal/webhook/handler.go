@@ -0,0 +1,486 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/radif/service/internal/middleware"
+	"github.com/radif/service/internal/response"
+)
+
+// Handler holds HTTP handlers for merchant webhook endpoints.
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler creates a new webhook Handler.
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+type createEndpointRequest struct {
+	URL    string   `json:"url"    example:"https://shop.example.ir/radif/webhook"`
+	Events []string `json:"events" example:"payment.received"`
+}
+
+type createEndpointResponse struct {
+	Endpoint   *Endpoint   `json:"endpoint"`
+	SigningKey *CreatedKey `json:"signingKey"`
+}
+
+type rotateKeyRequest struct {
+	// OverlapSeconds is how long previously active keys stay valid. Omit for the
+	// 24h default; 0 revokes them immediately. Maximum 7 days.
+	OverlapSeconds *int64 `json:"overlapSeconds" example:"86400"`
+}
+
+type deliveryDetailResponse struct {
+	Delivery *Delivery  `json:"delivery"`
+	Attempts []*Attempt `json:"attempts"`
+}
+
+type testFireResponse struct {
+	Event    *Event      `json:"event"`
+	Delivery *SendResult `json:"delivery"`
+	Success  bool        `json:"success"`
+}
+
+// CreateEndpoint godoc
+//
+//	@Summary		Register webhook endpoint
+//	@Description	Register a URL to receive signed webhook events (business accounts only). Omit events to subscribe to all event types. The response includes the signing secret — it is shown only once.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			request	body		createEndpointRequest	true	"Endpoint URL"
+//	@Success		201		{object}	response.Envelope{data=createEndpointResponse}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/webhooks/endpoints [post]
+func (h *Handler) CreateEndpoint(w http.ResponseWriter, r *http.Request) {
+	userID, ok := merchantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req createEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+
+	e, k, err := h.svc.CreateEndpoint(r.Context(), userID, req.URL, req.Events)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInvalidURL):
+			response.BadRequest(w, "url must be a valid absolute https URL")
+		case errors.Is(err, ErrUnknownEventType):
+			response.BadRequest(w, "events contains an unknown event type")
+		case errors.Is(err, ErrLimitReached):
+			response.BadRequest(w, "maximum number of webhook endpoints reached")
+		case errors.Is(err, ErrNotBusiness):
+			response.Forbidden(w, "webhooks are available to business accounts only")
+		default:
+			response.InternalError(w)
+		}
+		return
+	}
+
+	response.Created(w, createEndpointResponse{Endpoint: e, SigningKey: k})
+}
+
+// ListEndpoints godoc
+//
+//	@Summary		List webhook endpoints
+//	@Description	Returns the merchant's registered webhook endpoints.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	response.Envelope{data=[]Endpoint}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/webhooks/endpoints [get]
+func (h *Handler) ListEndpoints(w http.ResponseWriter, r *http.Request) {
+	userID, ok := merchantID(w, r)
+	if !ok {
+		return
+	}
+
+	endpoints, err := h.svc.ListEndpoints(r.Context(), userID)
+	if err != nil {
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, endpoints)
+}
+
+// DeleteEndpoint godoc
+//
+//	@Summary		Delete webhook endpoint
+//	@Description	Remove a webhook endpoint and all of its signing keys.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Endpoint ID"
+//	@Success		200	{object}	response.Envelope
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/webhooks/endpoints/{id} [delete]
+func (h *Handler) DeleteEndpoint(w http.ResponseWriter, r *http.Request) {
+	userID, ok := merchantID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.DeleteEndpoint(r.Context(), userID, chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// ListKeys godoc
+//
+//	@Summary		List active signing keys
+//	@Description	Returns the endpoint's currently valid signing keys (newest first) with secrets hidden. During a rotation overlap more than one key is active and every delivery carries one signature per key.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Endpoint ID"
+//	@Success		200	{object}	response.Envelope{data=[]SigningKey}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/webhooks/endpoints/{id}/keys [get]
+func (h *Handler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := merchantID(w, r)
+	if !ok {
+		return
+	}
+
+	keys, err := h.svc.ListKeys(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, keys)
+}
+
+// RotateKey godoc
+//
+//	@Summary		Rotate signing key
+//	@Description	Generate a new signing secret. Previously active keys remain valid for the overlap window (default 24h, max 7 days) so deliveries verify with either secret while the receiver is updated. The new secret is shown only once.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"Endpoint ID"
+//	@Param			request	body		rotateKeyRequest	false	"Overlap window"
+//	@Success		201		{object}	response.Envelope{data=CreatedKey}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/webhooks/endpoints/{id}/keys/rotate [post]
+func (h *Handler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := merchantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req rotateKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "invalid request body")
+			return
+		}
+	}
+
+	var overlap time.Duration
+	immediate := false
+	if req.OverlapSeconds != nil {
+		overlap = time.Duration(*req.OverlapSeconds) * time.Second
+		immediate = *req.OverlapSeconds == 0
+	}
+
+	k, err := h.svc.RotateKey(r.Context(), userID, chi.URLParam(r, "id"), overlap, immediate)
+	if err != nil {
+		if errors.Is(err, ErrInvalidOverlap) {
+			response.BadRequest(w, "overlapSeconds must be between 0 and 604800")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	response.Created(w, k)
+}
+
+// ExpireKey godoc
+//
+//	@Summary		Expire signing key
+//	@Description	End an old key's overlap window immediately. The newest key cannot be expired; rotate instead.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Endpoint ID"
+//	@Param			keyId	path		string	true	"Signing key ID"
+//	@Success		200		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/webhooks/endpoints/{id}/keys/{keyId} [delete]
+func (h *Handler) ExpireKey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := merchantID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.svc.ExpireKey(r.Context(), userID, chi.URLParam(r, "id"), chi.URLParam(r, "keyId")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, map[string]bool{"success": true})
+}
+
+// TestFire godoc
+//
+//	@Summary		Send test event
+//	@Description	Synchronously deliver a signed "webhook.test" event to the endpoint and return the receiver's status code and response snippet.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Endpoint ID"
+//	@Success		200	{object}	response.Envelope{data=testFireResponse}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/webhooks/endpoints/{id}/test [post]
+func (h *Handler) TestFire(w http.ResponseWriter, r *http.Request) {
+	userID, ok := merchantID(w, r)
+	if !ok {
+		return
+	}
+
+	ev, res, err := h.svc.TestFire(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OK(w, testFireResponse{Event: ev, Delivery: res, Success: res.OK()})
+}
+
+// ListDeliveries godoc
+//
+//	@Summary		List webhook deliveries
+//	@Description	Returns the most recent deliveries to the endpoint (newest first) with their current status, attempt count, and last response — for debugging integrations.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string	true	"Endpoint ID"
+//	@Param			status	query		string	false	"Filter by status"	Enums(pending, succeeded, failed)
+//	@Param			limit	query		int		false	"Max results (1-100, default 100)"
+//	@Param			fields	query		string	false	"Comma-separated fields to include (e.g. id,eventType,status,attempts)"
+//	@Param			casing	query		string	false	"Key casing"	Enums(camel, snake)
+//	@Success		200		{object}	response.Envelope{data=[]Delivery}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/webhooks/endpoints/{id}/deliveries [get]
+func (h *Handler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := merchantID(w, r)
+	if !ok {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && status != StatusPending && status != StatusSucceeded && status != StatusFailed {
+		response.BadRequest(w, "status must be one of: pending, succeeded, failed")
+		return
+	}
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxDeliveryListLimit {
+			response.BadRequest(w, "limit must be between 1 and 100")
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.svc.ListDeliveries(r.Context(), userID, chi.URLParam(r, "id"), status, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.OKProjected(w, r, deliveries)
+}
+
+// GetDelivery godoc
+//
+//	@Summary		Get webhook delivery
+//	@Description	Returns a delivery with its full attempt log (status codes, errors, response snippets, durations).
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Delivery ID"
+//	@Success		200	{object}	response.Envelope{data=deliveryDetailResponse}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/webhooks/deliveries/{id} [get]
+func (h *Handler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	userID, ok := merchantID(w, r)
+	if !ok {
+		return
+	}
+
+	d, attempts, err := h.svc.GetDelivery(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			response.NotFound(w, "webhook delivery not found")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+
+	response.OK(w, deliveryDetailResponse{Delivery: d, Attempts: attempts})
+}
+
+// ReplayDelivery godoc
+//
+//	@Summary		Replay webhook delivery
+//	@Description	Queue a new delivery of a finished (succeeded or failed) delivery's event, with the same event ID. The new delivery has its own attempt log and references the original via replayOf.
+//	@Tags			webhooks
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id	path		string	true	"Delivery ID"
+//	@Success		201	{object}	response.Envelope{data=Delivery}
+//	@Failure		401	{object}	response.Envelope
+//	@Failure		403	{object}	response.Envelope
+//	@Failure		404	{object}	response.Envelope
+//	@Failure		500	{object}	response.Envelope
+//	@Router			/webhooks/deliveries/{id}/replay [post]
+func (h *Handler) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	userID, ok := merchantID(w, r)
+	if !ok {
+		return
+	}
+
+	d, err := h.svc.ReplayDelivery(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			response.NotFound(w, "webhook delivery not found or still pending")
+			return
+		}
+		response.InternalError(w)
+		return
+	}
+
+	response.Created(w, d)
+}
+
+type redeliverRequest struct {
+	From   time.Time `json:"from"   example:"2026-03-20T00:00:00Z"`
+	To     time.Time `json:"to"     example:"2026-03-21T00:00:00Z"`
+	Status string    `json:"status" example:"failed"`
+}
+
+type redeliverResponse struct {
+	Queued int64 `json:"queued" example:"42"`
+}
+
+// Redeliver godoc
+//
+//	@Summary		Bulk redeliver webhook events
+//	@Description	Queue new deliveries for the endpoint's finished original deliveries created in [from, to) — e.g. after an outage on your side. status narrows to succeeded or failed deliveries. The range may span at most 7 days and at most 1000 deliveries are queued per call; if queued is 1000, narrow the range and repeat.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Param			id		path		string				true	"Endpoint ID"
+//	@Param			request	body		redeliverRequest	true	"Time range"
+//	@Success		202		{object}	response.Envelope{data=redeliverResponse}
+//	@Failure		400		{object}	response.Envelope
+//	@Failure		401		{object}	response.Envelope
+//	@Failure		403		{object}	response.Envelope
+//	@Failure		404		{object}	response.Envelope
+//	@Failure		500		{object}	response.Envelope
+//	@Router			/webhooks/endpoints/{id}/redeliver [post]
+func (h *Handler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	userID, ok := merchantID(w, r)
+	if !ok {
+		return
+	}
+
+	var req redeliverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "invalid request body")
+		return
+	}
+	if req.Status != "" && req.Status != StatusSucceeded && req.Status != StatusFailed {
+		response.BadRequest(w, "status must be one of: succeeded, failed")
+		return
+	}
+
+	n, err := h.svc.Redeliver(r.Context(), userID, chi.URLParam(r, "id"), req.Status, req.From, req.To)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRange) {
+			response.BadRequest(w, "to must be after from and the range at most 7 days")
+			return
+		}
+		writeError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, response.Envelope{Success: true, Data: redeliverResponse{Queued: n}})
+}
+
+// merchantID returns the authenticated user ID, writing an error response when
+// the caller is not authenticated or not a business account.
+func merchantID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(string)
+	if !ok || userID == "" {
+		response.Unauthorized(w, "unauthorized")
+		return "", false
+	}
+	if accountType, _ := r.Context().Value(middleware.UserAccountTypeKey).(string); accountType != "business" {
+		response.Forbidden(w, "webhooks are available to business accounts only")
+		return "", false
+	}
+	return userID, true
+}
+
+// writeError maps service errors to responses.
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrNotFound) {
+		response.NotFound(w, "webhook endpoint not found")
+		return
+	}
+	response.InternalError(w)
+}
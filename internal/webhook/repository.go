@@ -0,0 +1,519 @@
+// Package webhook manages merchant webhook endpoints, their signing keys,
+// and signed outbound event delivery.
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Endpoint is a merchant-registered URL that receives webhook events.
+type Endpoint struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"-"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SigningKey is an HMAC secret used to sign deliveries to an endpoint.
+// Secret is only serialized once, in the response that creates the key.
+type SigningKey struct {
+	ID         string     `json:"id"`
+	EndpointID string     `json:"-"`
+	Secret     string     `json:"-"`
+	Hint       string     `json:"hint"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+}
+
+// Delivery status values.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Delivery is one event queued for one endpoint, retried until it succeeds or
+// exhausts its attempts.
+type Delivery struct {
+	ID             string          `json:"id"`
+	EndpointID     string          `json:"endpointId"`
+	EventID        string          `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastStatusCode *int            `json:"lastStatusCode,omitempty"`
+	LastError      *string         `json:"lastError,omitempty"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	ReplayOf       *string         `json:"replayOf,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+}
+
+// Attempt is the logged outcome of a single HTTP delivery attempt.
+type Attempt struct {
+	ID           string    `json:"id"`
+	Attempt      int       `json:"attempt"`
+	StatusCode   *int      `json:"statusCode,omitempty"`
+	Error        *string   `json:"error,omitempty"`
+	ResponseBody *string   `json:"responseBody,omitempty"`
+	DurationMs   int64     `json:"durationMs"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// ErrNotFound is returned when an endpoint or key does not exist for the merchant.
+var ErrNotFound = errors.New("webhook not found")
+
+// ErrNotBusiness is returned when a non-business account registers an endpoint.
+var ErrNotBusiness = errors.New("webhooks are available to business accounts only")
+
+// Repository handles webhook persistence.
+type Repository struct {
+	db *pgxpool.Pool
+}
+
+// NewRepository creates a new webhook Repository.
+func NewRepository(db *pgxpool.Pool) *Repository {
+	return &Repository{db: db}
+}
+
+const endpointCols = `id, user_id, url, events, created_at, updated_at`
+
+const keyCols = `id, endpoint_id, secret, created_at, expires_at`
+
+func scanEndpoint(row pgx.Row, e *Endpoint) error {
+	return row.Scan(&e.ID, &e.UserID, &e.URL, &e.Events, &e.CreatedAt, &e.UpdatedAt)
+}
+
+func scanKey(row pgx.Row, k *SigningKey) error {
+	return row.Scan(&k.ID, &k.EndpointID, &k.Secret, &k.CreatedAt, &k.ExpiresAt)
+}
+
+// CreateEndpoint inserts an endpoint together with its first signing key.
+func (r *Repository) CreateEndpoint(ctx context.Context, userID, url string, events []string, secret string) (*Endpoint, *SigningKey, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	e := &Endpoint{}
+	err = scanEndpoint(tx.QueryRow(ctx,
+		`INSERT INTO webhook_endpoints (user_id, url, events)
+		 SELECT id, $2, $3 FROM users
+		 WHERE id = $1 AND account_type = 'business' AND deleted_at IS NULL
+		 RETURNING `+endpointCols,
+		userID, url, events,
+	), e)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrNotBusiness
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("insert endpoint: %w", err)
+	}
+
+	k := &SigningKey{}
+	err = scanKey(tx.QueryRow(ctx,
+		`INSERT INTO webhook_signing_keys (endpoint_id, secret) VALUES ($1, $2) RETURNING `+keyCols,
+		e.ID, secret,
+	), k)
+	if err != nil {
+		return nil, nil, fmt.Errorf("insert signing key: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("commit: %w", err)
+	}
+	return e, k, nil
+}
+
+// ListEndpoints returns all endpoints owned by the merchant.
+func (r *Repository) ListEndpoints(ctx context.Context, userID string) ([]*Endpoint, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+endpointCols+` FROM webhook_endpoints
+		 WHERE user_id = $1
+		 ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	endpoints := []*Endpoint{}
+	for rows.Next() {
+		e := &Endpoint{}
+		if err := scanEndpoint(rows, e); err != nil {
+			return nil, fmt.Errorf("scan endpoint: %w", err)
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, rows.Err()
+}
+
+// GetEndpoint fetches an endpoint owned by the merchant.
+func (r *Repository) GetEndpoint(ctx context.Context, userID, id string) (*Endpoint, error) {
+	e := &Endpoint{}
+	err := scanEndpoint(r.db.QueryRow(ctx,
+		`SELECT `+endpointCols+` FROM webhook_endpoints WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	), e)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get endpoint: %w", err)
+	}
+	return e, nil
+}
+
+// DeleteEndpoint removes an endpoint and, by cascade, its keys.
+func (r *Repository) DeleteEndpoint(ctx context.Context, userID, id string) error {
+	tag, err := r.db.Exec(ctx,
+		`DELETE FROM webhook_endpoints WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if isInvalidID(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("delete endpoint: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ActiveKeys returns the endpoint's unexpired signing keys, newest first.
+func (r *Repository) ActiveKeys(ctx context.Context, endpointID string) ([]*SigningKey, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+keyCols+` FROM webhook_signing_keys
+		 WHERE endpoint_id = $1 AND (expires_at IS NULL OR expires_at > NOW())
+		 ORDER BY created_at DESC`,
+		endpointID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list signing keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []*SigningKey{}
+	for rows.Next() {
+		k := &SigningKey{}
+		if err := scanKey(rows, k); err != nil {
+			return nil, fmt.Errorf("scan signing key: %w", err)
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// RotateKey inserts a new signing key and caps the expiry of every other active
+// key at oldKeysExpireAt, so old and new keys verify simultaneously until then.
+func (r *Repository) RotateKey(ctx context.Context, endpointID, secret string, oldKeysExpireAt time.Time) (*SigningKey, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	_, err = tx.Exec(ctx,
+		`UPDATE webhook_signing_keys SET expires_at = $2
+		 WHERE endpoint_id = $1 AND (expires_at IS NULL OR expires_at > $2)`,
+		endpointID, oldKeysExpireAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("expire old keys: %w", err)
+	}
+
+	k := &SigningKey{}
+	err = scanKey(tx.QueryRow(ctx,
+		`INSERT INTO webhook_signing_keys (endpoint_id, secret) VALUES ($1, $2) RETURNING `+keyCols,
+		endpointID, secret,
+	), k)
+	if err != nil {
+		return nil, fmt.Errorf("insert signing key: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit: %w", err)
+	}
+	return k, nil
+}
+
+// ExpireKey ends a key's overlap window immediately. The newest key of an
+// endpoint cannot be expired this way; rotate instead.
+func (r *Repository) ExpireKey(ctx context.Context, endpointID, keyID string) error {
+	tag, err := r.db.Exec(ctx,
+		`UPDATE webhook_signing_keys SET expires_at = NOW()
+		 WHERE id = $2 AND endpoint_id = $1
+		   AND (expires_at IS NULL OR expires_at > NOW())
+		   AND id <> (
+		       SELECT id FROM webhook_signing_keys
+		       WHERE endpoint_id = $1
+		       ORDER BY created_at DESC LIMIT 1
+		   )`,
+		endpointID, keyID,
+	)
+	if isInvalidID(err) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("expire signing key: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const deliveryCols = `id, endpoint_id, event_id, event_type, payload, status, attempts,
+	next_attempt_at, last_status_code, last_error, delivered_at, replay_of, created_at, updated_at`
+
+func scanDelivery(row pgx.Row, d *Delivery) error {
+	return row.Scan(
+		&d.ID, &d.EndpointID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.DeliveredAt, &d.ReplayOf, &d.CreatedAt, &d.UpdatedAt,
+	)
+}
+
+// EnqueueForUser queues an event for every endpoint of the merchant subscribed
+// to eventType and returns the number of deliveries created. Endpoints that
+// already have a delivery of eventID are skipped, so a retried enqueue does
+// not deliver the event twice.
+func (r *Repository) EnqueueForUser(ctx context.Context, userID, eventID, eventType string, payload []byte) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload)
+		 SELECT e.id, $2, $3, $4 FROM webhook_endpoints e
+		 WHERE e.user_id = $1 AND (cardinality(e.events) = 0 OR $3 = ANY(e.events))
+		   AND NOT EXISTS (SELECT 1 FROM webhook_deliveries d WHERE d.endpoint_id = e.id AND d.event_id = $2)`,
+		userID, eventID, eventType, payload,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue deliveries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ClaimDue leases up to limit due deliveries by pushing their next_attempt_at
+// forward by lease. SKIP LOCKED lets several workers poll concurrently without
+// picking the same rows; an expired lease makes a crashed worker's rows due again.
+func (r *Repository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*Delivery, error) {
+	rows, err := r.db.Query(ctx,
+		`UPDATE webhook_deliveries SET next_attempt_at = NOW() + $2::interval
+		 WHERE id IN (
+		     SELECT id FROM webhook_deliveries
+		     WHERE status = 'pending' AND next_attempt_at <= NOW()
+		     ORDER BY next_attempt_at
+		     LIMIT $1
+		     FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING `+deliveryCols,
+		limit, lease,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim deliveries: %w", err)
+	}
+	return collectDeliveries(rows)
+}
+
+// GetEndpointByID fetches an endpoint without an ownership check (worker use only).
+func (r *Repository) GetEndpointByID(ctx context.Context, id string) (*Endpoint, error) {
+	e := &Endpoint{}
+	err := scanEndpoint(r.db.QueryRow(ctx,
+		`SELECT `+endpointCols+` FROM webhook_endpoints WHERE id = $1`, id,
+	), e)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get endpoint: %w", err)
+	}
+	return e, nil
+}
+
+// RecordAttempt logs an attempt and updates the delivery's status and schedule.
+func (r *Repository) RecordAttempt(ctx context.Context, d *Delivery, res *SendResult, status string, nextAttemptAt time.Time) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	statusCode := nullableInt(res.StatusCode)
+	errMsg := nullableString(res.Error)
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO webhook_delivery_attempts
+		     (delivery_id, attempt, status_code, error, response_body, duration_ms)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		d.ID, d.Attempts+1, statusCode, errMsg, nullableString(res.ResponseBody), res.DurationMs,
+	)
+	if err != nil {
+		return fmt.Errorf("insert attempt: %w", err)
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE webhook_deliveries SET
+		     attempts         = attempts + 1,
+		     status           = $2,
+		     next_attempt_at  = $3,
+		     last_status_code = $4,
+		     last_error       = $5,
+		     delivered_at     = CASE WHEN $2 = 'succeeded' THEN NOW() ELSE delivered_at END
+		 WHERE id = $1`,
+		d.ID, status, nextAttemptAt, statusCode, errMsg,
+	)
+	if err != nil {
+		return fmt.Errorf("update delivery: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ListDeliveries returns the most recent deliveries of an endpoint, optionally filtered by status.
+func (r *Repository) ListDeliveries(ctx context.Context, endpointID, status string, limit int) ([]*Delivery, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT `+deliveryCols+` FROM webhook_deliveries
+		 WHERE endpoint_id = $1 AND ($2 = '' OR status = $2)
+		 ORDER BY created_at DESC
+		 LIMIT $3`,
+		endpointID, status, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list deliveries: %w", err)
+	}
+	return collectDeliveries(rows)
+}
+
+// GetDelivery fetches a delivery belonging to one of the merchant's endpoints.
+func (r *Repository) GetDelivery(ctx context.Context, userID, id string) (*Delivery, error) {
+	d := &Delivery{}
+	err := scanDelivery(r.db.QueryRow(ctx,
+		`SELECT `+deliveryCols+` FROM webhook_deliveries
+		 WHERE id = $1
+		   AND endpoint_id IN (SELECT id FROM webhook_endpoints WHERE user_id = $2)`,
+		id, userID,
+	), d)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get delivery: %w", err)
+	}
+	return d, nil
+}
+
+// ReplayDelivery queues a fresh copy of a finished delivery belonging to one of
+// the merchant's endpoints. The copy keeps the event ID so receivers can
+// recognise it as the same event.
+func (r *Repository) ReplayDelivery(ctx context.Context, userID, id string) (*Delivery, error) {
+	d := &Delivery{}
+	err := scanDelivery(r.db.QueryRow(ctx,
+		`INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, replay_of)
+		 SELECT endpoint_id, event_id, event_type, payload, id FROM webhook_deliveries
+		 WHERE id = $1 AND status <> 'pending'
+		   AND endpoint_id IN (SELECT id FROM webhook_endpoints WHERE user_id = $2)
+		 RETURNING `+deliveryCols,
+		id, userID,
+	), d)
+	if errors.Is(err, pgx.ErrNoRows) || isInvalidID(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("replay delivery: %w", err)
+	}
+	return d, nil
+}
+
+// RedeliverRange queues fresh copies of up to limit finished original
+// deliveries of an endpoint created in [from, to), optionally filtered by
+// status, and returns how many were queued. Replays themselves are never
+// selected, so repeating a bulk redelivery does not compound.
+func (r *Repository) RedeliverRange(ctx context.Context, endpointID, status string, from, to time.Time, limit int) (int64, error) {
+	tag, err := r.db.Exec(ctx,
+		`INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload, replay_of)
+		 SELECT endpoint_id, event_id, event_type, payload, id FROM webhook_deliveries
+		 WHERE endpoint_id = $1 AND replay_of IS NULL AND status <> 'pending'
+		   AND ($2 = '' OR status = $2)
+		   AND created_at >= $3 AND created_at < $4
+		 ORDER BY created_at
+		 LIMIT $5`,
+		endpointID, status, from, to, limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("redeliver range: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListAttempts returns the attempt log of a delivery in order.
+func (r *Repository) ListAttempts(ctx context.Context, deliveryID string) ([]*Attempt, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, attempt, status_code, error, response_body, duration_ms, created_at
+		 FROM webhook_delivery_attempts
+		 WHERE delivery_id = $1
+		 ORDER BY attempt`,
+		deliveryID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list attempts: %w", err)
+	}
+	defer rows.Close()
+
+	attempts := []*Attempt{}
+	for rows.Next() {
+		a := &Attempt{}
+		if err := rows.Scan(&a.ID, &a.Attempt, &a.StatusCode, &a.Error, &a.ResponseBody, &a.DurationMs, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan attempt: %w", err)
+		}
+		attempts = append(attempts, a)
+	}
+	return attempts, rows.Err()
+}
+
+func collectDeliveries(rows pgx.Rows) ([]*Delivery, error) {
+	defer rows.Close()
+	deliveries := []*Delivery{}
+	for rows.Next() {
+		d := &Delivery{}
+		if err := scanDelivery(rows, d); err != nil {
+			return nil, fmt.Errorf("scan delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func nullableInt(v int) *int {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+func nullableString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+// isInvalidID checks whether an error is a PostgreSQL invalid_text_representation (code 22P02),
+// raised when a malformed UUID is passed from a URL parameter.
+func isInvalidID(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "22P02"
+}
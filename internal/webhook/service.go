@@ -0,0 +1,342 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	maxEndpointsPerUser = 5
+	defaultKeyOverlap   = 24 * time.Hour
+	maxKeyOverlap       = 7 * 24 * time.Hour
+	secretPrefix        = "whsec_"
+)
+
+// Event types merchants can subscribe to.
+const (
+	EventTypeTest            = "webhook.test"
+	EventTypePaymentReceived = "payment.received"
+)
+
+// knownEventTypes lists the event types accepted in endpoint subscriptions.
+var knownEventTypes = map[string]bool{
+	EventTypePaymentReceived: true,
+}
+
+const maxDeliveryListLimit = 100
+
+const (
+	maxRedeliverWindow = 7 * 24 * time.Hour
+	maxRedeliverBatch  = 1000
+)
+
+// ErrInvalidURL is returned when an endpoint URL is malformed or not allowed.
+var ErrInvalidURL = errors.New("invalid webhook URL")
+
+// ErrLimitReached is returned when the merchant already has the maximum number of endpoints.
+var ErrLimitReached = errors.New("webhook endpoint limit reached")
+
+// ErrUnknownEventType is returned when a subscription names an unsupported event type.
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// ErrInvalidRange is returned when a bulk redelivery time range is empty or too wide.
+var ErrInvalidRange = errors.New("invalid redelivery range")
+
+// ErrInvalidOverlap is returned when a rotation overlap window is out of range.
+var ErrInvalidOverlap = errors.New("invalid overlap window")
+
+// Event is the JSON body delivered to merchant endpoints.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// CreatedKey is returned when a signing key is created; it is the only time the
+// full secret is revealed.
+type CreatedKey struct {
+	*SigningKey
+	Secret string `json:"secret"`
+}
+
+// Service contains business logic for merchant webhooks.
+type Service struct {
+	repo         *Repository
+	sender       *Sender
+	requireHTTPS bool
+}
+
+// NewService creates a new webhook Service. When requireHTTPS is true, only
+// https:// endpoint URLs are accepted.
+func NewService(repo *Repository, sender *Sender, requireHTTPS bool) *Service {
+	return &Service{repo: repo, sender: sender, requireHTTPS: requireHTTPS}
+}
+
+// CreateEndpoint registers a new endpoint URL subscribed to events (all events
+// when empty) and generates its first signing key.
+func (s *Service) CreateEndpoint(ctx context.Context, userID, rawURL string, events []string) (*Endpoint, *CreatedKey, error) {
+	if err := s.validateURL(rawURL); err != nil {
+		return nil, nil, err
+	}
+	if events == nil {
+		events = []string{}
+	}
+	for _, ev := range events {
+		if !knownEventTypes[ev] {
+			return nil, nil, ErrUnknownEventType
+		}
+	}
+
+	existing, err := s.repo.ListEndpoints(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(existing) >= maxEndpointsPerUser {
+		return nil, nil, ErrLimitReached
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e, k, err := s.repo.CreateEndpoint(ctx, userID, rawURL, events, secret)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create endpoint: %w", err)
+	}
+	return e, revealKey(k), nil
+}
+
+// ListEndpoints returns the merchant's endpoints.
+func (s *Service) ListEndpoints(ctx context.Context, userID string) ([]*Endpoint, error) {
+	return s.repo.ListEndpoints(ctx, userID)
+}
+
+// DeleteEndpoint removes a merchant endpoint.
+func (s *Service) DeleteEndpoint(ctx context.Context, userID, id string) error {
+	return s.repo.DeleteEndpoint(ctx, userID, id)
+}
+
+// ListKeys returns the active signing keys of a merchant endpoint with secrets hidden.
+func (s *Service) ListKeys(ctx context.Context, userID, endpointID string) ([]*SigningKey, error) {
+	if _, err := s.repo.GetEndpoint(ctx, userID, endpointID); err != nil {
+		return nil, err
+	}
+	keys, err := s.repo.ActiveKeys(ctx, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range keys {
+		k.Hint = secretHint(k.Secret)
+	}
+	return keys, nil
+}
+
+// RotateKey creates a new signing key for the endpoint. Previously active keys
+// remain valid for overlap (default 24h, max 7 days; zero ends them immediately
+// when immediate is true) so receivers can deploy the new secret without
+// rejecting in-flight deliveries.
+func (s *Service) RotateKey(ctx context.Context, userID, endpointID string, overlap time.Duration, immediate bool) (*CreatedKey, error) {
+	if overlap < 0 || overlap > maxKeyOverlap {
+		return nil, ErrInvalidOverlap
+	}
+	if overlap == 0 && !immediate {
+		overlap = defaultKeyOverlap
+	}
+	if _, err := s.repo.GetEndpoint(ctx, userID, endpointID); err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := s.repo.RotateKey(ctx, endpointID, secret, time.Now().Add(overlap))
+	if err != nil {
+		return nil, fmt.Errorf("rotate key: %w", err)
+	}
+	return revealKey(k), nil
+}
+
+// ExpireKey ends the overlap window of an old key immediately.
+func (s *Service) ExpireKey(ctx context.Context, userID, endpointID, keyID string) error {
+	if _, err := s.repo.GetEndpoint(ctx, userID, endpointID); err != nil {
+		return err
+	}
+	return s.repo.ExpireKey(ctx, endpointID, keyID)
+}
+
+// TestFire synchronously delivers a signed sample event to the endpoint and
+// reports the receiver's response.
+func (s *Service) TestFire(ctx context.Context, userID, endpointID string) (*Event, *SendResult, error) {
+	e, err := s.repo.GetEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id, err := generateEventID()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, _ := json.Marshal(map[string]interface{}{
+		"message":    "This is a test event from Radif.",
+		"endpointId": e.ID,
+	})
+	ev := &Event{ID: id, Type: EventTypeTest, CreatedAt: time.Now().UTC(), Data: data}
+
+	res, err := s.deliver(ctx, e, ev)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ev, res, nil
+}
+
+// Publish queues event eventID for asynchronous delivery to every endpoint
+// of the merchant subscribed to eventType. The event relay calls it for
+// merchant events in the outbox (see events.Webhooks); the Worker performs
+// the actual HTTP deliveries with retries. Publishing the same eventID
+// again queues nothing new, as the relay retries until it succeeds.
+func (s *Service) Publish(ctx context.Context, eventID, merchantID, eventType string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal event data: %w", err)
+	}
+	id := "evt_" + strings.ReplaceAll(eventID, "-", "")
+	body, err := json.Marshal(&Event{ID: id, Type: eventType, CreatedAt: time.Now().UTC(), Data: raw})
+	if err != nil {
+		return fmt.Errorf("marshal event: %w", err)
+	}
+
+	if _, err := s.repo.EnqueueForUser(ctx, merchantID, id, eventType, body); err != nil {
+		return fmt.Errorf("publish %s: %w", eventType, err)
+	}
+	return nil
+}
+
+// ListDeliveries returns recent deliveries of a merchant endpoint for debugging.
+func (s *Service) ListDeliveries(ctx context.Context, userID, endpointID, status string, limit int) ([]*Delivery, error) {
+	if _, err := s.repo.GetEndpoint(ctx, userID, endpointID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxDeliveryListLimit {
+		limit = maxDeliveryListLimit
+	}
+	return s.repo.ListDeliveries(ctx, endpointID, status, limit)
+}
+
+// GetDelivery returns a merchant's delivery together with its attempt log.
+func (s *Service) GetDelivery(ctx context.Context, userID, id string) (*Delivery, []*Attempt, error) {
+	d, err := s.repo.GetDelivery(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	attempts, err := s.repo.ListAttempts(ctx, d.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, attempts, nil
+}
+
+// ReplayDelivery queues a new delivery of a finished delivery's event. The
+// worker picks it up like any other pending delivery.
+func (s *Service) ReplayDelivery(ctx context.Context, userID, id string) (*Delivery, error) {
+	return s.repo.ReplayDelivery(ctx, userID, id)
+}
+
+// Redeliver queues new deliveries for the endpoint's finished deliveries
+// created in [from, to), optionally only those with the given status.
+// At most maxRedeliverBatch are queued per call; the caller narrows the
+// range and repeats if more remain.
+func (s *Service) Redeliver(ctx context.Context, userID, endpointID, status string, from, to time.Time) (int64, error) {
+	if !to.After(from) || to.Sub(from) > maxRedeliverWindow {
+		return 0, ErrInvalidRange
+	}
+	if _, err := s.repo.GetEndpoint(ctx, userID, endpointID); err != nil {
+		return 0, err
+	}
+	return s.repo.RedeliverRange(ctx, endpointID, status, from, to, maxRedeliverBatch)
+}
+
+// deliver signs ev with every active key of the endpoint and sends it once.
+func (s *Service) deliver(ctx context.Context, e *Endpoint, ev *Event) (*SendResult, error) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return nil, fmt.Errorf("marshal event: %w", err)
+	}
+	return s.send(ctx, e, ev.ID, ev.Type, body)
+}
+
+// send signs body with every active key of the endpoint and POSTs it once.
+func (s *Service) send(ctx context.Context, e *Endpoint, eventID, eventType string, body []byte) (*SendResult, error) {
+	keys, err := s.repo.ActiveKeys(ctx, e.ID)
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]string, 0, len(keys))
+	for _, k := range keys {
+		secrets = append(secrets, k.Secret)
+	}
+	return s.sender.Send(ctx, e.URL, secrets, eventID, eventType, body), nil
+}
+
+// validateURL checks that rawURL is an absolute http(s) URL allowed in this environment.
+func (s *Service) validateURL(rawURL string) error {
+	if len(rawURL) > 2048 {
+		return ErrInvalidURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if s.requireHTTPS {
+			return ErrInvalidURL
+		}
+	default:
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// revealKey wraps a freshly created key so its secret is serialized once.
+func revealKey(k *SigningKey) *CreatedKey {
+	k.Hint = secretHint(k.Secret)
+	return &CreatedKey{SigningKey: k, Secret: k.Secret}
+}
+
+// secretHint returns a recognizable, non-sensitive suffix of the secret.
+func secretHint(secret string) string {
+	if len(secret) < 4 {
+		return secretPrefix + "…"
+	}
+	return secretPrefix + "…" + secret[len(secret)-4:]
+}
+
+// generateSecret creates a new random signing secret.
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate secret: %w", err)
+	}
+	return secretPrefix + hex.EncodeToString(b), nil
+}
+
+// generateEventID creates a unique event identifier.
+func generateEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate event id: %w", err)
+	}
+	return "evt_" + hex.EncodeToString(b), nil
+}
@@ -0,0 +1,141 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// SignatureHeader carries the delivery signature, formatted as
+// "t=<unix seconds>,v1=<hex hmac>[,v1=<hex hmac>...]". One v1 value is emitted
+// per active signing key so receivers can verify during a rotation overlap.
+const SignatureHeader = "Radif-Signature"
+
+const (
+	deliveryTimeout  = 10 * time.Second
+	maxResponseBytes = 4 << 10 // keep at most 4 KB of the receiver's response body
+)
+
+// Sign computes the signature header value for body at timestamp t using every secret.
+// The signed payload is "<unix seconds>.<body>".
+func Sign(secrets []string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	parts := []string{"t=" + ts}
+	for _, secret := range secrets {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(ts))
+		mac.Write([]byte{'.'})
+		mac.Write(body)
+		parts = append(parts, "v1="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	return strings.Join(parts, ",")
+}
+
+// SendResult describes the outcome of a single HTTP delivery attempt.
+type SendResult struct {
+	StatusCode   int    `json:"statusCode,omitempty"`
+	DurationMs   int64  `json:"durationMs"`
+	ResponseBody string `json:"responseBody,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// OK reports whether the receiver acknowledged the delivery with a 2xx status.
+func (r *SendResult) OK() bool {
+	return r.Error == "" && r.StatusCode >= 200 && r.StatusCode < 300
+}
+
+// Sender performs signed HTTP POSTs to merchant endpoints.
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a Sender. When allowPrivate is false, connections to
+// loopback, private, shared (CGNAT) and link-local addresses are refused so merchants cannot
+// use webhooks to probe internal infrastructure.
+func NewSender(allowPrivate bool) *Sender {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = denyPrivateAddresses
+	}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+		MaxIdleConns:        50,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &Sender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   deliveryTimeout,
+			// Never follow redirects: the signature is bound to the registered URL.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send POSTs body to url signed with secrets.
+func (s *Sender) Send(ctx context.Context, url string, secrets []string, eventID, eventType string, body []byte) *SendResult {
+	start := time.Now()
+	res := &SendResult{}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		res.Error = fmt.Sprintf("build request: %v", err)
+		return res
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Radif-Webhooks/1.0")
+	req.Header.Set("Radif-Event-Id", eventID)
+	req.Header.Set("Radif-Event-Type", eventType)
+	req.Header.Set(SignatureHeader, Sign(secrets, start, body))
+
+	resp, err := s.client.Do(req)
+	res.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	defer resp.Body.Close()
+
+	res.StatusCode = resp.StatusCode
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	res.ResponseBody = string(snippet)
+	return res
+}
+
+// sharedAddressSpace is 100.64.0.0/10, the carrier-grade NAT range (RFC
+// 6598). net.IP.IsPrivate does not cover it, but it is internal to the
+// network it is used in.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// errPrivateAddress is returned when a webhook URL resolves to a non-public address.
+var errPrivateAddress = errors.New("webhook destination resolves to a non-public address")
+
+// denyPrivateAddresses is a net.Dialer Control hook rejecting non-public destinations.
+// It runs after DNS resolution, so it also covers hostnames pointing at internal IPs.
+func denyPrivateAddresses(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() ||
+		sharedAddressSpace.Contains(ip) {
+		return errPrivateAddress
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	pollInterval     = 5 * time.Second
+	claimBatchSize   = 20
+	claimLease       = 2 * time.Minute
+	maxAttempts      = 8
+	baseRetryBackoff = 30 * time.Second
+	maxRetryBackoff  = 6 * time.Hour
+)
+
+// Worker delivers queued webhook events in the background, retrying failures
+// with exponential backoff until maxAttempts is reached.
+type Worker struct {
+	svc *Service
+}
+
+// NewWorker creates a new delivery Worker.
+func NewWorker(svc *Service) *Worker {
+	return &Worker{svc: svc}
+}
+
+// Run polls for due deliveries until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	slog.Info("webhook worker started")
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		w.drain(ctx)
+		select {
+		case <-ctx.Done():
+			slog.Info("webhook worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain processes batches until no due deliveries remain.
+func (w *Worker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		deliveries, err := w.svc.repo.ClaimDue(ctx, claimBatchSize, claimLease)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.ErrorContext(ctx, "webhook worker: claim failed", "err", err)
+			}
+			return
+		}
+		for _, d := range deliveries {
+			w.process(ctx, d)
+		}
+		if len(deliveries) < claimBatchSize {
+			return
+		}
+	}
+}
+
+// process performs one delivery attempt and schedules the next one on failure.
+func (w *Worker) process(ctx context.Context, d *Delivery) {
+	e, err := w.svc.repo.GetEndpointByID(ctx, d.EndpointID)
+	if errors.Is(err, ErrNotFound) {
+		return // endpoint deleted; the delivery row went with it
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "webhook worker: load endpoint failed", "endpoint_id", d.EndpointID, "err", err)
+		return
+	}
+
+	res, err := w.svc.send(ctx, e, d.EventID, d.EventType, d.Payload)
+	if err != nil {
+		slog.WarnContext(ctx, "webhook worker: send delivery failed", "delivery_id", d.ID, "err", err)
+		return
+	}
+
+	status := StatusPending
+	next := time.Now().Add(retryBackoff(d.Attempts + 1))
+	switch {
+	case res.OK():
+		status = StatusSucceeded
+		next = time.Now()
+	case d.Attempts+1 >= maxAttempts:
+		status = StatusFailed
+		next = time.Now()
+	}
+
+	if err := w.svc.repo.RecordAttempt(ctx, d, res, status, next); err != nil {
+		slog.ErrorContext(ctx, "webhook worker: record attempt failed", "delivery_id", d.ID, "err", err)
+	}
+}
+
+// retryBackoff returns the delay before the next attempt after attempt failures:
+// 30s, 1m, 2m, 4m, ... capped at 6h, with ±20% jitter so retries to a recovering
+// endpoint don't arrive in lockstep.
+func retryBackoff(attempt int) time.Duration {
+	d := baseRetryBackoff << (attempt - 1)
+	if d <= 0 || d > maxRetryBackoff {
+		d = maxRetryBackoff
+	}
+	jitter := 0.8 + rand.Float64()*0.4
+	return time.Duration(float64(d) * jitter)
+}